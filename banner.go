@@ -0,0 +1,161 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"fmt"
+	"slices"
+)
+
+// BannerPattern is a pattern layer of a banner or shield.
+type BannerPattern struct {
+	// Pattern is the namespaced pattern ID, such as "minecraft:stripe_bottom".
+	Pattern string
+	// Color is the dye colour name, such as "red".
+	Color string
+}
+
+// dyeColors are the dye colour names, indexed by their legacy colour ID.
+var dyeColors = []string{"white", "orange", "magenta", "light_blue", "yellow", "lime", "pink", "gray", "light_gray",
+	"cyan", "purple", "blue", "brown", "green", "red", "black"}
+
+// bannerPatternCodes maps pattern IDs without namespace to their codes before 1.20.5. Patterns added later have no
+// code.
+var bannerPatternCodes = map[string]string{
+	"base": "b", "square_bottom_left": "bl", "square_bottom_right": "br", "square_top_left": "tl",
+	"square_top_right": "tr", "stripe_bottom": "bs", "stripe_top": "ts", "stripe_left": "ls", "stripe_right": "rs",
+	"stripe_center": "cs", "stripe_middle": "ms", "stripe_downright": "drs", "stripe_downleft": "dls",
+	"small_stripes": "ss", "cross": "cr", "straight_cross": "sc", "triangle_bottom": "bt", "triangle_top": "tt",
+	"triangles_bottom": "bts", "triangles_top": "tts", "diagonal_left": "ld", "diagonal_up_right": "rd",
+	"diagonal_up_left": "lud", "diagonal_right": "rud", "circle": "mc", "rhombus": "mr", "half_vertical": "vh",
+	"half_horizontal": "hh", "half_vertical_right": "vhr", "half_horizontal_bottom": "hhb", "border": "bo",
+	"curly_border": "cbo", "gradient": "gra", "gradient_up": "gru", "bricks": "bri", "globe": "glb", "creeper": "cre",
+	"skull": "sku", "flower": "flo", "mojang": "moj", "piglin": "pig", "flow": "", "guster": "",
+}
+
+// Validate returns an error if the pattern ID or dye colour is not known.
+func (p BannerPattern) Validate() error {
+	if _, ok := bannerPatternCodes[trimNamespace(p.Pattern)]; !ok {
+		return fmt.Errorf("Unable to validate banner pattern: unknown pattern \"%v\"", p.Pattern)
+	}
+	if !slices.Contains(dyeColors, p.Color) {
+		return fmt.Errorf("Unable to validate banner pattern: unknown colour \"%v\"", p.Color)
+	}
+	return nil
+}
+
+// BannerPatterns reads the pattern layers of a banner block entity in either layout. It also reads the BlockEntityTag
+// of a banner or shield item before 1.20.5, and the components compound of one from 1.20.5. A compound without
+// patterns has none.
+func BannerPatterns(t Tag) (patterns []BannerPattern, err error) {
+	if t.id != tagCompound {
+		return nil, fmt.Errorf("Unable to read banner patterns: tag ID %v is not a tagCompound", t.id)
+	}
+
+	list, modern := compoundChild(t, "patterns")
+	if !modern {
+		list, modern = compoundChild(t, "minecraft:banner_patterns")
+	}
+	if !modern {
+		list, _ = compoundChild(t, "Patterns")
+	}
+	elements, _ := list.payload.([]any)
+	for i, element := range elements {
+		layer := Tag{id: tagCompound, payload: element}
+		var p BannerPattern
+		if modern {
+			p, err = bannerPatternFromTag(layer)
+		} else {
+			p, err = legacyBannerPatternFromTag(layer)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read banner pattern %v: %w", i, err)
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
+
+// bannerPatternFromTag reads a pattern layer from 1.20.5.
+func bannerPatternFromTag(t Tag) (p BannerPattern, err error) {
+	pattern, ok := compoundChild(t, "pattern")
+	if !ok {
+		return BannerPattern{}, fmt.Errorf("no \"pattern\"")
+	}
+	p.Pattern, ok = pattern.payload.(string)
+	if !ok {
+		return BannerPattern{}, fmt.Errorf("inline pattern definitions are not supported")
+	}
+	p.Color, ok = childPayload[string](t, "color")
+	if !ok {
+		return BannerPattern{}, fmt.Errorf("no tagString named \"color\"")
+	}
+	return p, nil
+}
+
+// legacyBannerPatternFromTag reads a pattern layer before 1.20.5, mapping the code and colour ID to their names.
+func legacyBannerPatternFromTag(t Tag) (p BannerPattern, err error) {
+	code, codeOK := childPayload[string](t, "Pattern")
+	color, colorOK := childPayload[int32](t, "Color")
+	if !codeOK || !colorOK {
+		return BannerPattern{}, fmt.Errorf("no tagString \"Pattern\" and tagInt \"Color\"")
+	}
+
+	for id, c := range bannerPatternCodes {
+		if c == code && c != "" {
+			p.Pattern = "minecraft:" + id
+		}
+	}
+	if p.Pattern == "" {
+		return BannerPattern{}, fmt.Errorf("unknown pattern code \"%v\"", code)
+	}
+	if color < 0 || int(color) >= len(dyeColors) {
+		return BannerPattern{}, fmt.Errorf("unknown colour ID %v", color)
+	}
+	p.Color = dyeColors[color]
+	return p, nil
+}
+
+// SetBannerPatterns returns a copy of the banner compound holding the pattern layers in the layout of the
+// DataVersion, see ItemComponentsDataVersion. Patterns of either layout are replaced, all other children are kept.
+// The given compound is not modified. Each pattern is validated, and patterns added after 1.20.5 cannot be written
+// in the earlier layout.
+func SetBannerPatterns(t Tag, patterns []BannerPattern, dataVersion int32) (Tag, error) {
+	if t.id != tagCompound {
+		return Tag{}, fmt.Errorf("Unable to set banner patterns: tag ID %v is not a tagCompound", t.id)
+	}
+
+	var elements []any
+	for i, p := range patterns {
+		if err := p.Validate(); err != nil {
+			return Tag{}, fmt.Errorf("Unable to set banner pattern %v: %w", i, err)
+		}
+
+		if dataVersion >= ItemComponentsDataVersion {
+			elements = append(elements, []Tag{
+				{id: tagString, name: "pattern", payload: p.Pattern},
+				{id: tagString, name: "color", payload: p.Color},
+			})
+			continue
+		}
+
+		code := bannerPatternCodes[trimNamespace(p.Pattern)]
+		if code == "" {
+			return Tag{}, fmt.Errorf("Unable to set banner pattern %v: \"%v\" has no code before DataVersion %v", i,
+				p.Pattern, ItemComponentsDataVersion)
+		}
+		elements = append(elements, []Tag{
+			{id: tagString, name: "Pattern", payload: code},
+			{id: tagInt, name: "Color", payload: int32(slices.Index(dyeColors, p.Color))}, // #nosec G115 -- below 16
+		})
+	}
+
+	name := "patterns"
+	if dataVersion < ItemComponentsDataVersion {
+		name = "Patterns"
+	}
+	children, _ := t.payload.([]Tag)
+	children, _ = withoutChild(children, "patterns")
+	children, _ = withoutChild(children, "Patterns")
+	t.payload = append(slices.Clip(children), Tag{id: tagList, elementID: tagCompound, name: name, payload: elements})
+	return t, nil
+}
@@ -0,0 +1,109 @@
+package nbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBannerPatterns(t *testing.T) {
+	patterns := []BannerPattern{
+		{Pattern: "minecraft:stripe_bottom", Color: "red"},
+		{Pattern: "minecraft:creeper", Color: "black"},
+	}
+	banner := Tag{id: tagCompound, payload: []Tag{{id: tagString, name: "id", payload: "minecraft:banner"}}}
+
+	tests := []struct {
+		name        string
+		patterns    []BannerPattern
+		dataVersion int32
+		wantList    string
+		wantErr     bool
+	}{
+		{
+			name:        "Test success case: 1.20.5 layout",
+			patterns:    append(patterns, BannerPattern{Pattern: "minecraft:flow", Color: "cyan"}),
+			dataVersion: ItemComponentsDataVersion,
+			wantList:    "patterns",
+		},
+		{
+			name:        "Test success case: legacy layout",
+			patterns:    patterns,
+			dataVersion: ItemComponentsDataVersion - 1,
+			wantList:    "Patterns",
+		},
+		{
+			name:        "Test failure case: no legacy code",
+			patterns:    []BannerPattern{{Pattern: "minecraft:guster", Color: "white"}},
+			dataVersion: ItemComponentsDataVersion - 1,
+			wantErr:     true,
+		},
+		{
+			name:        "Test failure case: unknown pattern",
+			patterns:    []BannerPattern{{Pattern: "minecraft:unknown", Color: "white"}},
+			dataVersion: ItemComponentsDataVersion,
+			wantErr:     true,
+		},
+		{
+			name:        "Test failure case: unknown colour",
+			patterns:    []BannerPattern{{Pattern: "minecraft:base", Color: "teal"}},
+			dataVersion: ItemComponentsDataVersion,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SetBannerPatterns(banner, tt.patterns, tt.dataVersion)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if _, ok := compoundChild(got, tt.wantList); !ok {
+				t.Errorf("got %+v, want a list named %v", got, tt.wantList)
+			}
+
+			reread, err := BannerPatterns(got)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(reread, tt.patterns) {
+				t.Errorf("got %+v, want %+v", reread, tt.patterns)
+			}
+		})
+	}
+
+	t.Run("Test success case: legacy codes", func(t *testing.T) {
+		got, _ := SetBannerPatterns(banner, patterns, ItemComponentsDataVersion-1)
+		list, _ := compoundChild(got, "Patterns")
+		want := []any{
+			[]Tag{{id: tagString, name: "Pattern", payload: "bs"}, {id: tagInt, name: "Color", payload: int32(14)}},
+			[]Tag{{id: tagString, name: "Pattern", payload: "cre"}, {id: tagInt, name: "Color", payload: int32(15)}},
+		}
+		if !reflect.DeepEqual(list.payload, want) {
+			t.Errorf("got %+v, want %+v", list.payload, want)
+		}
+	})
+
+	t.Run("Test success case: shield components", func(t *testing.T) {
+		shield := NewItemStack("minecraft:shield", 1).WithComponent(Tag{id: tagList, elementID: tagCompound,
+			name: "minecraft:banner_patterns", payload: []any{
+				[]Tag{{id: tagString, name: "pattern", payload: "minecraft:base"}, {id: tagString, name: "color", payload: "blue"}},
+			}})
+		components, _ := compoundChild(shield.Tag(ItemComponentsDataVersion), "components")
+		got, err := BannerPatterns(components)
+		if want := []BannerPattern{{Pattern: "minecraft:base", Color: "blue"}}; err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v %v, want %+v", got, err, want)
+		}
+	})
+
+	t.Run("Test failure case: unknown legacy code", func(t *testing.T) {
+		bad := Tag{id: tagCompound, payload: []Tag{{id: tagList, elementID: tagCompound, name: "Patterns", payload: []any{
+			[]Tag{{id: tagString, name: "Pattern", payload: "zz"}, {id: tagInt, name: "Color", payload: int32(1)}},
+		}}}}
+		if _, err := BannerPatterns(bad); err == nil {
+			t.Errorf("got no error, want error")
+		}
+	})
+}
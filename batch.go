@@ -0,0 +1,113 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// FileError is the error of one file of a batch run by RunBatch.
+type FileError struct {
+	Path string
+	Err  error
+}
+
+// Error returns the path and the error of the file.
+func (e FileError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the error of the file.
+func (e FileError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError is the errors of the files of a batch that failed, sorted by path. The files of the batch without an
+// error succeeded.
+type BatchError []FileError
+
+// Error returns the number of files that failed and the error of each, one per line.
+func (e BatchError) Error() string {
+	lines := []string{fmt.Sprintf("Unable to process %v files:", len(e))}
+	for _, fileErr := range e {
+		lines = append(lines, fileErr.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Unwrap returns the error of each file, so errors.Is and errors.As search them all.
+func (e BatchError) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, fileErr := range e {
+		errs[i] = fileErr
+	}
+	return errs
+}
+
+// RunBatch calls do for every path, on at most workers goroutines at a time, or one per CPU if workers is not positive.
+// A file that fails does not stop the others. The returned error is a BatchError of the files that failed, or nil if
+// none did.
+func RunBatch(paths []string, workers int, do func(path string) error) error {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	var mu sync.Mutex
+	var failed BatchError
+	var wg sync.WaitGroup
+	work := make(chan string)
+	for range min(workers, len(paths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range work {
+				if err := do(path); err != nil {
+					mu.Lock()
+					failed = append(failed, FileError{Path: path, Err: err})
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, path := range paths {
+		work <- path
+	}
+	close(work)
+	wg.Wait()
+
+	if failed == nil {
+		return nil
+	}
+	slices.SortFunc(failed, func(a, b FileError) int { return strings.Compare(a.Path, b.Path) })
+	return failed
+}
+
+// FileTransform returns the tag of the file at path transformed, and true if it changed and must be written back.
+type FileTransform func(path string, t Tag) (Tag, bool, error)
+
+// TransformFiles decodes every file matching the pattern, as by filepath.Glob, applies the transform to it and writes
+// back each file it changes, on at most workers goroutines at a time, or one per CPU if workers is not positive. Files
+// are read with ReadFile and written with WriteFile, keeping their compression, with the options. A file that fails to
+// decode, transform or write does not stop the others, and the returned error is a BatchError of the files that failed.
+func TransformFiles(pattern string, workers int, transform FileTransform, opts ...Option) error {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return fmt.Errorf("Unable to transform files: %w", err)
+	}
+
+	return RunBatch(paths, workers, func(path string) error {
+		t, err := ReadFile(path, opts...)
+		if err != nil {
+			return err
+		}
+		t, changed, err := transform(path, t)
+		if err != nil || !changed {
+			return err
+		}
+		return WriteFile(path, t, opts...)
+	})
+}
@@ -0,0 +1,100 @@
+package nbt
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunBatch(t *testing.T) {
+	paths := []string{"c", "a", "b", "d", "e", "f"}
+
+	t.Run("Test success case: bounded workers", func(t *testing.T) {
+		var running, most, calls atomic.Int32
+		err := RunBatch(paths, 2, func(string) error {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for m := most.Load(); n > m && !most.CompareAndSwap(m, n); m = most.Load() {
+			}
+			calls.Add(1)
+			time.Sleep(time.Millisecond)
+			return nil
+		})
+		if err != nil || calls.Load() != int32(len(paths)) || most.Load() > 2 {
+			t.Errorf("got %v, %v calls and %v at once, want %v calls and at most 2 at once", err, calls.Load(),
+				most.Load(), len(paths))
+		}
+	})
+
+	t.Run("Test failure case: errors of each file", func(t *testing.T) {
+		errOdd := errors.New("odd")
+		err := RunBatch(paths, 0, func(path string) error {
+			if path == "c" || path == "a" {
+				return errOdd
+			}
+			return nil
+		})
+		var batch BatchError
+		if !errors.As(err, &batch) || len(batch) != 2 || batch[0].Path != "a" || batch[1].Path != "c" {
+			t.Fatalf("got %v, want the errors of a and c", err)
+		}
+		if !errors.Is(err, errOdd) {
+			t.Errorf("got %v, want it to wrap %v", err, errOdd)
+		}
+	})
+}
+
+func TestTransformFiles(t *testing.T) {
+	dir := t.TempDir()
+	// Each file is a root compound holding an XpLevel int.
+	file := func(level byte) []byte {
+		b := []byte{tagCompound, 0, 0, tagInt, 0, 7}
+		b = append(b, "XpLevel"...)
+		return append(b, 0, 0, 0, level, tagEnd)
+	}
+	writeGzipFile(t, filepath.Join(dir, "a.dat"), file(1), time.Now())
+	writeGzipFile(t, filepath.Join(dir, "b.dat"), file(2), time.Now())
+	if err := os.WriteFile(filepath.Join(dir, "c.dat"), []byte("corrupt"), 0o600); err != nil {
+		t.Fatalf("Unable to write test file: %v", err)
+	}
+
+	levelUp := func(_ string, t Tag) (Tag, bool, error) {
+		level, err := Get[int32](&t, "XpLevel")
+		if err != nil || level != 1 {
+			return t, false, err
+		}
+		return t, true, t.SetPath(Path{"XpLevel"}, NewInt("XpLevel", level+1), false)
+	}
+	err := TransformFiles(filepath.Join(dir, "*.dat"), 2, levelUp)
+
+	t.Run("Test success case: changed files written", func(t *testing.T) {
+		for _, name := range []string{"a.dat", "b.dat"} {
+			got, err := ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if level, _ := Get[int32](&got, "XpLevel"); level != 2 {
+				t.Errorf("%v: got XpLevel %v, want 2", name, level)
+			}
+			if compression, _ := fileCompression(filepath.Join(dir, name)); compression != CompressionGzip {
+				t.Errorf("%v: got %v, want the compression kept", name, compression)
+			}
+		}
+	})
+
+	t.Run("Test failure case: corrupt file reported", func(t *testing.T) {
+		var batch BatchError
+		if !errors.As(err, &batch) || len(batch) != 1 || batch[0].Path != filepath.Join(dir, "c.dat") {
+			t.Errorf("got %v, want the error of c.dat", err)
+		}
+	})
+
+	t.Run("Test failure case: bad pattern", func(t *testing.T) {
+		if err := TransformFiles("[", 1, levelUp); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,262 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// bedrockLevelHeaderSize is the size of the header before the NBT of a Bedrock edition level.dat: the little-endian
+// int32 storage version and int32 length of the NBT that follows.
+const bedrockLevelHeaderSize = 8
+
+// bedrockGameRules are the Bedrock edition game rules, stored as individual keys of the level.dat root compound.
+var bedrockGameRules = []string{"commandblockoutput", "commandblocksenabled", "dodaylightcycle", "doentitydrops",
+	"dofiretick", "doimmediaterespawn", "doinsomnia", "domobloot", "domobspawning", "dotiledrops", "doweathercycle",
+	"drowningdamage", "falldamage", "firedamage", "freezedamage", "functioncommandlimit", "keepinventory",
+	"maxcommandchainlength", "mobgriefing", "naturalregeneration", "playerssleepingpercentage",
+	"projectilescanbreakblocks", "pvp", "randomtickspeed", "recipesunlock", "respawnblocksexplode",
+	"sendcommandfeedback", "showbordereffect", "showcoordinates", "showdaysplayed", "showdeathmessages",
+	"showrecipemessages", "showtags", "spawnradius", "tntexplodes", "tntexplosiondropdecay"}
+
+// BedrockLevelData is the root compound of a Bedrock edition level.dat. Keys not modelled here are kept, so a level
+// read with BedrockLevelDataFromTag is written back unchanged by Tag.
+type BedrockLevelData struct {
+	LevelName      string
+	StorageVersion int32
+	NetworkVersion int32
+	// LastOpenedWithVersion and MinimumCompatibleClientVersion are game versions as lists of their parts, such as
+	// [1 20 80 5 0].
+	LastOpenedWithVersion          []int32
+	MinimumCompatibleClientVersion []int32
+	GameType                       int32
+	Difficulty                     int32
+	Generator                      int32
+	RandomSeed                     int64
+	SpawnX, SpawnY, SpawnZ         int32
+	Time                           int64
+	LastPlayed                     int64
+
+	// GameRules are the game rule keys present in the level, such as keepinventory (tagByte) and randomtickspeed
+	// (tagInt), in the order they are stored.
+	GameRules []Tag
+	// Experiments are the experiment toggles of the experiments compound, nil if there is none.
+	Experiments map[string]bool
+	// Abilities are the default player abilities of the abilities compound, nil if there is none.
+	Abilities *BedrockAbilities
+
+	// extra holds any keys not modelled above.
+	extra []Tag
+}
+
+// BedrockAbilities are the default player abilities of a Bedrock edition level.dat.
+type BedrockAbilities struct {
+	// Flags are the tagByte abilities, such as build, mayfly and op.
+	Flags                  map[string]bool
+	FlySpeed, WalkSpeed    float32
+	PermissionsLevel       int32
+	PlayerPermissionsLevel int32
+
+	// extra holds any keys not modelled above.
+	extra []Tag
+}
+
+// BedrockLevelDataFromTag reads the root compound of a Bedrock edition level.dat.
+func BedrockLevelDataFromTag(t Tag) (d BedrockLevelData, err error) {
+	if t.id != tagCompound {
+		return BedrockLevelData{}, fmt.Errorf("Unable to read Bedrock level data: tag ID %v is not a tagCompound", t.id)
+	}
+
+	children, _ := t.payload.([]Tag)
+	for _, child := range children {
+		switch child.name {
+		case "LevelName":
+			d.LevelName, err = tradeField[string](child)
+		case "StorageVersion":
+			d.StorageVersion, err = tradeField[int32](child)
+		case "NetworkVersion":
+			d.NetworkVersion, err = tradeField[int32](child)
+		case "lastOpenedWithVersion":
+			d.LastOpenedWithVersion, err = intList(child)
+		case "MinimumCompatibleClientVersion":
+			d.MinimumCompatibleClientVersion, err = intList(child)
+		case "GameType":
+			d.GameType, err = tradeField[int32](child)
+		case "Difficulty":
+			d.Difficulty, err = tradeField[int32](child)
+		case "Generator":
+			d.Generator, err = tradeField[int32](child)
+		case "RandomSeed":
+			d.RandomSeed, err = tradeField[int64](child)
+		case "SpawnX":
+			d.SpawnX, err = tradeField[int32](child)
+		case "SpawnY":
+			d.SpawnY, err = tradeField[int32](child)
+		case "SpawnZ":
+			d.SpawnZ, err = tradeField[int32](child)
+		case "Time":
+			d.Time, err = tradeField[int64](child)
+		case "LastPlayed":
+			d.LastPlayed, err = tradeField[int64](child)
+		case "experiments":
+			d.Experiments, _, err = byteFlags(child)
+		case "abilities":
+			d.Abilities, err = bedrockAbilitiesFromTag(child)
+		default:
+			if slices.Contains(bedrockGameRules, child.name) {
+				d.GameRules = append(d.GameRules, child)
+			} else {
+				d.extra = append(d.extra, child)
+			}
+		}
+		if err != nil {
+			return BedrockLevelData{}, fmt.Errorf("Unable to read Bedrock level data: %w", err)
+		}
+	}
+	return d, nil
+}
+
+// bedrockAbilitiesFromTag reads the abilities compound of a Bedrock edition level.dat.
+func bedrockAbilitiesFromTag(t Tag) (a *BedrockAbilities, err error) {
+	a = &BedrockAbilities{}
+	a.Flags, a.extra, err = byteFlags(t)
+	if err != nil {
+		return nil, err
+	}
+
+	extra := a.extra
+	a.extra = nil
+	for _, child := range extra {
+		switch child.name {
+		case "flySpeed":
+			a.FlySpeed, err = tradeField[float32](child)
+		case "walkSpeed":
+			a.WalkSpeed, err = tradeField[float32](child)
+		case "permissionsLevel":
+			a.PermissionsLevel, err = tradeField[int32](child)
+		case "playerPermissionsLevel":
+			a.PlayerPermissionsLevel, err = tradeField[int32](child)
+		default:
+			a.extra = append(a.extra, child)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("abilities: %w", err)
+		}
+	}
+	return a, nil
+}
+
+// byteFlags returns the tagByte children of a tagCompound as flags, and its other children.
+func byteFlags(t Tag) (flags map[string]bool, other []Tag, err error) {
+	if t.id != tagCompound {
+		return nil, nil, fmt.Errorf("\"%v\" is tag ID %v, not a tagCompound", t.name, t.id)
+	}
+
+	flags = map[string]bool{}
+	children, _ := t.payload.([]Tag)
+	for _, child := range children {
+		if b, ok := child.payload.(byte); ok {
+			flags[child.name] = b != 0
+		} else {
+			other = append(other, child)
+		}
+	}
+	return flags, other, nil
+}
+
+// flagTags returns flags as tagByte tags, sorted by name.
+func flagTags(flags map[string]bool) (tags []Tag) {
+	for _, name := range slices.Sorted(maps.Keys(flags)) {
+		tags = append(tags, Tag{id: tagByte, name: name, payload: boolByte(flags[name])})
+	}
+	return tags
+}
+
+// intList returns the elements of a tagList of tagInt.
+func intList(t Tag) ([]int32, error) {
+	elements, ok := t.payload.([]any)
+	if t.id != tagList || (!ok && t.payload != nil) {
+		return nil, fmt.Errorf("\"%v\" is tag ID %v, not a tagList", t.name, t.id)
+	}
+
+	ints := make([]int32, len(elements))
+	for i, element := range elements {
+		ints[i], ok = element.(int32)
+		if !ok {
+			return nil, fmt.Errorf("\"%v\" element %v has payload type %T, not int32", t.name, i, element)
+		}
+	}
+	return ints, nil
+}
+
+// intElements returns ints as tagList elements, nil if there are none.
+func intElements(ints []int32) (elements []any) {
+	for _, i := range ints {
+		elements = append(elements, i)
+	}
+	return elements
+}
+
+// Tag returns the level data as an unnamed root compound, with the modelled keys first and any other keys after. The
+// version lists are written only if they are not empty.
+func (d BedrockLevelData) Tag() Tag {
+	children := []Tag{
+		{id: tagString, name: "LevelName", payload: d.LevelName},
+		{id: tagInt, name: "StorageVersion", payload: d.StorageVersion},
+		{id: tagInt, name: "NetworkVersion", payload: d.NetworkVersion},
+		{id: tagInt, name: "GameType", payload: d.GameType},
+		{id: tagInt, name: "Difficulty", payload: d.Difficulty},
+		{id: tagInt, name: "Generator", payload: d.Generator},
+		{id: tagLong, name: "RandomSeed", payload: d.RandomSeed},
+		{id: tagInt, name: "SpawnX", payload: d.SpawnX},
+		{id: tagInt, name: "SpawnY", payload: d.SpawnY},
+		{id: tagInt, name: "SpawnZ", payload: d.SpawnZ},
+		{id: tagLong, name: "Time", payload: d.Time},
+		{id: tagLong, name: "LastPlayed", payload: d.LastPlayed},
+	}
+	if len(d.LastOpenedWithVersion) > 0 {
+		children = append(children, Tag{id: tagList, elementID: tagInt, name: "lastOpenedWithVersion",
+			payload: intElements(d.LastOpenedWithVersion)})
+	}
+	if len(d.MinimumCompatibleClientVersion) > 0 {
+		children = append(children, Tag{id: tagList, elementID: tagInt, name: "MinimumCompatibleClientVersion",
+			payload: intElements(d.MinimumCompatibleClientVersion)})
+	}
+	children = append(children, d.GameRules...)
+	if d.Experiments != nil {
+		children = append(children, Tag{id: tagCompound, name: "experiments", payload: flagTags(d.Experiments)})
+	}
+	if a := d.Abilities; a != nil {
+		abilities := append(flagTags(a.Flags),
+			Tag{id: tagFloat, name: "flySpeed", payload: a.FlySpeed},
+			Tag{id: tagFloat, name: "walkSpeed", payload: a.WalkSpeed},
+			Tag{id: tagInt, name: "permissionsLevel", payload: a.PermissionsLevel},
+			Tag{id: tagInt, name: "playerPermissionsLevel", payload: a.PlayerPermissionsLevel})
+		children = append(children, Tag{id: tagCompound, name: "abilities", payload: append(abilities, a.extra...)})
+	}
+	return Tag{id: tagCompound, payload: append(children, d.extra...)}
+}
+
+// LoadBedrockLevel decodes the level.dat file of a Bedrock edition world directory, checking its header as
+// ReadBedrockLevel does. The options configure decoding, apart from the Bedrock edition conventions.
+func LoadBedrockLevel(dir string, opts ...Option) (d BedrockLevelData, err error) {
+	path := filepath.Join(dir, levelName)
+	data, err := os.ReadFile(path) // #nosec G304 -- the caller chooses which world to load
+	if err != nil {
+		return BedrockLevelData{}, fmt.Errorf("Unable to load Bedrock %v: %w", levelName, err)
+	}
+
+	r := bytes.NewReader(data)
+	t, _, err := ReadBedrockLevel(r, opts...)
+	if err != nil {
+		return BedrockLevelData{}, fmt.Errorf("Unable to load Bedrock %v: %w", levelName, err)
+	}
+	if r.Len() != 0 {
+		return BedrockLevelData{}, fmt.Errorf("Unable to load Bedrock %v: %v bytes follow the tag", levelName, r.Len())
+	}
+	return BedrockLevelDataFromTag(t)
+}
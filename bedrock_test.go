@@ -0,0 +1,141 @@
+package nbt
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testBedrockLevel returns a Bedrock level.dat root compound with every modelled key and some unknown ones.
+func testBedrockLevel() Tag {
+	return Tag{id: tagCompound, payload: []Tag{
+		{id: tagString, name: "LevelName", payload: "Bedrock world"},
+		{id: tagInt, name: "StorageVersion", payload: int32(10)},
+		{id: tagInt, name: "NetworkVersion", payload: int32(712)},
+		{id: tagInt, name: "GameType", payload: int32(1)},
+		{id: tagInt, name: "Difficulty", payload: int32(2)},
+		{id: tagInt, name: "Generator", payload: int32(1)},
+		{id: tagLong, name: "RandomSeed", payload: int64(-42)},
+		{id: tagInt, name: "SpawnX", payload: int32(8)},
+		{id: tagInt, name: "SpawnY", payload: int32(64)},
+		{id: tagInt, name: "SpawnZ", payload: int32(-8)},
+		{id: tagLong, name: "Time", payload: int64(1000)},
+		{id: tagLong, name: "LastPlayed", payload: int64(1700000000)},
+		{id: tagList, elementID: tagInt, name: "lastOpenedWithVersion", payload: []any{int32(1), int32(21), int32(0)}},
+		{id: tagByte, name: "keepinventory", payload: byte(1)},
+		{id: tagInt, name: "randomtickspeed", payload: int32(3)},
+		{id: tagCompound, name: "experiments", payload: []Tag{
+			{id: tagByte, name: "experiments_ever_used", payload: byte(1)},
+			{id: tagByte, name: "gametest", payload: byte(0)},
+		}},
+		{id: tagCompound, name: "abilities", payload: []Tag{
+			{id: tagByte, name: "build", payload: byte(1)},
+			{id: tagByte, name: "mayfly", payload: byte(0)},
+			{id: tagFloat, name: "flySpeed", payload: float32(0.05)},
+			{id: tagFloat, name: "walkSpeed", payload: float32(0.1)},
+			{id: tagInt, name: "permissionsLevel", payload: int32(0)},
+			{id: tagInt, name: "playerPermissionsLevel", payload: int32(1)},
+			{id: tagString, name: "customAbility", payload: "kept"},
+		}},
+		{id: tagString, name: "BiomeOverride", payload: ""},
+		{id: tagByte, name: "eduOffer", payload: byte(0)},
+	}}
+}
+
+func TestBedrockLevelData(t *testing.T) {
+	level := testBedrockLevel()
+	got, err := BedrockLevelDataFromTag(level)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got.LevelName != "Bedrock world" || got.RandomSeed != -42 || got.SpawnZ != -8 ||
+		!reflect.DeepEqual(got.LastOpenedWithVersion, []int32{1, 21, 0}) {
+		t.Errorf("got %+v, want the modelled keys", got)
+	}
+	if len(got.GameRules) != 2 || got.GameRules[1].name != "randomtickspeed" {
+		t.Errorf("got game rules %+v, want keepinventory and randomtickspeed", got.GameRules)
+	}
+	if want := map[string]bool{"experiments_ever_used": true, "gametest": false}; !reflect.DeepEqual(got.Experiments,
+		want) {
+		t.Errorf("got experiments %+v, want %+v", got.Experiments, want)
+	}
+	if a := got.Abilities; a == nil || !a.Flags["build"] || a.WalkSpeed != 0.1 || a.PlayerPermissionsLevel != 1 {
+		t.Errorf("got abilities %+v, want build at walk speed 0.1", a)
+	}
+
+	t.Run("Test success case: round trip keeps unknown keys", func(t *testing.T) {
+		if written := got.Tag(); !payloadsEqual(written.payload, level.payload) {
+			t.Errorf("got %+v, want %+v", written, level)
+		}
+	})
+
+	t.Run("Test success case: no experiments or abilities", func(t *testing.T) {
+		d, err := BedrockLevelDataFromTag(Tag{id: tagCompound, payload: []Tag{{id: tagInt, name: "GameType",
+			payload: int32(0)}}})
+		if err != nil || d.Experiments != nil || d.Abilities != nil {
+			t.Fatalf("got %+v %v, want no experiments or abilities", d, err)
+		}
+		if _, ok := compoundChild(d.Tag(), "abilities"); ok {
+			t.Errorf("got abilities written, want none")
+		}
+	})
+
+	failureCases := []struct {
+		name  string
+		input Tag
+	}{
+		{"not a compound", Tag{id: tagList}},
+		{"wrong type", Tag{id: tagCompound, payload: []Tag{{id: tagLong, name: "GameType", payload: int64(0)}}}},
+		{"version not a list", Tag{id: tagCompound, payload: []Tag{{id: tagInt, name: "lastOpenedWithVersion",
+			payload: int32(1)}}}},
+		{"abilities not a compound", Tag{id: tagCompound, payload: []Tag{{id: tagInt, name: "abilities",
+			payload: int32(1)}}}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			if _, err := BedrockLevelDataFromTag(failureCase.input); err == nil {
+				t.Errorf("got no error, want error")
+			}
+		})
+	}
+}
+
+func TestLoadBedrockLevel(t *testing.T) {
+	payload := []byte{tagCompound, 0, 0, tagString, 9, 0, 'L', 'e', 'v', 'e', 'l', 'N', 'a', 'm', 'e', 2, 0, 'h', 'i',
+		tagEnd}
+	header := binary.LittleEndian.AppendUint32(binary.LittleEndian.AppendUint32(nil, 10), uint32(len(payload)))
+
+	tests := []struct {
+		name    string
+		content []byte
+		want    string
+		wantErr bool
+	}{
+		{name: "Test success case: level name", content: append(header, payload...), want: "hi"},
+		{name: "Test failure case: short header", content: header[:4], wantErr: true},
+		{name: "Test failure case: length mismatch", content: append(header, payload[:5]...), wantErr: true},
+		{name: "Test failure case: missing file", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.content != nil {
+				if err := os.WriteFile(filepath.Join(dir, levelName), tt.content, 0o600); err != nil {
+					t.Fatalf("Unable to write test file: %v", err)
+				}
+			}
+
+			got, err := LoadBedrockLevel(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if got.LevelName != tt.want {
+				t.Errorf("got level name %q, want %q", got.LevelName, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"math"
+	"slices"
+	"strings"
+)
+
+// canonical NaN bit patterns, the quiet NaNs with no payload bits set.
+const (
+	canonicalNaN32 uint32 = 0x7FC00000
+	canonicalNaN64 uint64 = 0x7FF8000000000000
+)
+
+// CanonicalPolicy selects which floating point quirks Canonicalize leaves alone. The zero value normalises everything.
+type CanonicalPolicy struct {
+	// KeepNegativeZero leaves -0.0 as is, rather than replacing it with 0.0.
+	KeepNegativeZero bool
+	// KeepNaN leaves the bit pattern of each NaN as is, rather than replacing it with a single quiet NaN.
+	KeepNaN bool
+}
+
+// Canonicalize rewrites the tree in place to a canonical form, so semantically equal trees from different producers
+// compare equal. Compound children are sorted by name (byte-wise, stable for duplicate names), floats and doubles are
+// normalised per the policy, and empty compounds, lists and arrays are given nil payloads.
+func (t *Tag) Canonicalize(policy CanonicalPolicy) {
+	t.payload = canonicalPayload(t.payload, policy)
+}
+
+// canonicalPayload returns the canonical form of a payload. Slice payloads are modified in place.
+func canonicalPayload(payload any, policy CanonicalPolicy) any {
+	switch p := payload.(type) {
+	case float32:
+		return canonicalFloat32(p, policy)
+	case float64:
+		return canonicalFloat64(p, policy)
+	case []byte:
+		if len(p) == 0 {
+			return []byte(nil)
+		}
+	case []int32:
+		if len(p) == 0 {
+			return []int32(nil)
+		}
+	case []int64:
+		if len(p) == 0 {
+			return []int64(nil)
+		}
+	case []any:
+		if len(p) == 0 {
+			return []any(nil)
+		}
+		for i := range p {
+			p[i] = canonicalPayload(p[i], policy)
+		}
+	case []Tag:
+		if len(p) == 0 {
+			return []Tag(nil)
+		}
+		for i := range p {
+			p[i].Canonicalize(policy)
+		}
+		slices.SortStableFunc(p, func(a, b Tag) int {
+			return strings.Compare(a.name, b.name)
+		})
+	}
+	return payload
+}
+
+// canonicalFloat32 normalises -0.0 and NaN floats per the policy.
+func canonicalFloat32(f float32, policy CanonicalPolicy) float32 {
+	switch {
+	case math.IsNaN(float64(f)) && !policy.KeepNaN:
+		return math.Float32frombits(canonicalNaN32)
+	case f == 0 && math.Signbit(float64(f)) && !policy.KeepNegativeZero:
+		return 0
+	}
+	return f
+}
+
+// canonicalFloat64 normalises -0.0 and NaN doubles per the policy.
+func canonicalFloat64(f float64, policy CanonicalPolicy) float64 {
+	switch {
+	case math.IsNaN(f) && !policy.KeepNaN:
+		return math.Float64frombits(canonicalNaN64)
+	case f == 0 && math.Signbit(f) && !policy.KeepNegativeZero:
+		return 0
+	}
+	return f
+}
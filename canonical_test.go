@@ -0,0 +1,92 @@
+package nbt
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	signallingNaN32 := math.Float32frombits(0x7F800001)
+	signallingNaN64 := math.Float64frombits(0x7FF0000000000001)
+	negativeZero32 := float32(math.Copysign(0, -1))
+	negativeZero64 := math.Copysign(0, -1)
+
+	successCases := []struct {
+		name   string
+		want   Tag
+		policy CanonicalPolicy
+		t      Tag
+	}{
+		{"sorted compound children", Tag{id: tagCompound, payload: []Tag{
+			{id: tagInt, name: "a", payload: int32(1)},
+			{id: tagCompound, name: "b", payload: []Tag{
+				{id: tagByte, name: "c", payload: byte(0)}, {id: tagByte, name: "d", payload: byte(0)},
+			}},
+		}}, CanonicalPolicy{}, Tag{id: tagCompound, payload: []Tag{
+			{id: tagCompound, name: "b", payload: []Tag{
+				{id: tagByte, name: "d", payload: byte(0)}, {id: tagByte, name: "c", payload: byte(0)},
+			}},
+			{id: tagInt, name: "a", payload: int32(1)},
+		}}},
+		{"compounds in lists", Tag{id: tagList, payload: []any{[]Tag{
+			{id: tagInt, name: "x", payload: int32(1)}, {id: tagInt, name: "y", payload: int32(2)},
+		}}}, CanonicalPolicy{}, Tag{id: tagList, payload: []any{[]Tag{
+			{id: tagInt, name: "y", payload: int32(2)}, {id: tagInt, name: "x", payload: int32(1)},
+		}}}},
+		{"empty payloads", Tag{id: tagCompound, payload: []Tag{
+			{id: tagList, name: "a", payload: []any(nil)}, {id: tagByteArray, name: "b", payload: []byte(nil)},
+			{id: tagIntArray, name: "c", payload: []int32(nil)},
+			{id: tagLongArray, name: "d", payload: []int64(nil)}, {id: tagCompound, name: "e", payload: []Tag(nil)},
+		}}, CanonicalPolicy{}, Tag{id: tagCompound, payload: []Tag{
+			{id: tagList, name: "a", payload: []any{}}, {id: tagByteArray, name: "b", payload: []byte{}},
+			{id: tagIntArray, name: "c", payload: []int32{}},
+			{id: tagLongArray, name: "d", payload: []int64{}}, {id: tagCompound, name: "e", payload: []Tag{}},
+		}}},
+		{"negative zero", Tag{id: tagList, payload: []any{float64(0)}}, CanonicalPolicy{},
+			Tag{id: tagList, payload: []any{negativeZero64}}},
+		{"kept negative zero", Tag{id: tagFloat, payload: negativeZero32}, CanonicalPolicy{KeepNegativeZero: true},
+			Tag{id: tagFloat, payload: negativeZero32}},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			successCase.t.Canonicalize(successCase.policy)
+			if !reflect.DeepEqual(successCase.t, successCase.want) {
+				t.Errorf("got %v, want %v", successCase.t, successCase.want)
+			}
+		})
+	}
+
+	floatCases := []struct {
+		name     string
+		wantBits uint64
+		policy   CanonicalPolicy
+		payload  any
+	}{
+		{"float NaN", uint64(canonicalNaN32), CanonicalPolicy{}, signallingNaN32},
+		{"double NaN", canonicalNaN64, CanonicalPolicy{}, signallingNaN64},
+		{"kept float NaN", 0x7F800001, CanonicalPolicy{KeepNaN: true}, signallingNaN32},
+		{"kept double NaN", 0x7FF0000000000001, CanonicalPolicy{KeepNaN: true}, signallingNaN64},
+		{"float negative zero", 0, CanonicalPolicy{}, negativeZero32},
+		{"double negative zero", 0, CanonicalPolicy{KeepNaN: true}, negativeZero64},
+		{"ordinary double", math.Float64bits(-1.5), CanonicalPolicy{}, -1.5},
+	}
+	for _, floatCase := range floatCases {
+		t.Run("Test success case: "+floatCase.name, func(t *testing.T) {
+			id, _ := payloadID(floatCase.payload)
+			tag := Tag{id: id, payload: floatCase.payload}
+			tag.Canonicalize(floatCase.policy)
+
+			var gotBits uint64
+			switch p := tag.payload.(type) {
+			case float32:
+				gotBits = uint64(math.Float32bits(p))
+			case float64:
+				gotBits = math.Float64bits(p)
+			}
+			if gotBits != floatCase.wantBits {
+				t.Errorf("got %#x, want %#x", gotBits, floatCase.wantBits)
+			}
+		})
+	}
+}
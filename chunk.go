@@ -0,0 +1,258 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"fmt"
+	"iter"
+	"math/bits"
+	"slices"
+)
+
+// Block states in a chunk section are palette indices packed into a tagLongArray, as stored since Java edition 1.16:
+// each long holds as many whole indices as fit, lowest bits first, and no index spans two longs.
+const (
+	// sectionBlocks is the number of blocks in a 16x16x16 section.
+	sectionBlocks = 4096
+	// sectionBiomes is the number of biomes in a section, one per 4x4x4 blocks.
+	sectionBiomes = 64
+	// minBlockStateBits is the fewest bits per block state index, used even for small palettes of several entries.
+	minBlockStateBits = 4
+)
+
+// blockStateBits returns the bits per index of a block state palette of the given length. A single entry palette
+// needs no bits, as it is stored without data.
+func blockStateBits(paletteLen int) int {
+	if paletteLen <= 1 {
+		return 0
+	}
+	return max(minBlockStateBits, biomeBits(paletteLen))
+}
+
+// biomeBits returns the bits per index of a biome palette of the given length, which has no minimum.
+func biomeBits(paletteLen int) int {
+	if paletteLen <= 1 {
+		return 0
+	}
+	return bits.Len(uint(paletteLen - 1))
+}
+
+// packIndices packs palette indices of the given bit width into longs. A zero width returns nil, as a single entry
+// palette is stored without data.
+func packIndices(indices []int, width int) []int64 {
+	if width == 0 {
+		return nil
+	}
+
+	perLong := 64 / width
+	packed := make([]int64, (len(indices)+perLong-1)/perLong)
+	for i, index := range indices {
+		packed[i/perLong] |= int64(index) << (uint(i%perLong) * uint(width)) // #nosec G115 -- index fits the width
+	}
+	return packed
+}
+
+// unpackIndices unpacks count palette indices of the given bit width from longs. A zero width returns all zero
+// indices.
+func unpackIndices(packed []int64, width, count int) ([]int, error) {
+	indices := make([]int, count)
+	if width == 0 {
+		return indices, nil
+	}
+
+	perLong := 64 / width
+	if want := (count + perLong - 1) / perLong; len(packed) != want {
+		return nil, fmt.Errorf("packed data has %v longs, want %v for %v indices of %v bits", len(packed), want, count,
+			width)
+	}
+
+	mask := uint64(1)<<uint(width) - 1
+	for i := range indices {
+		word := uint64(packed[i/perLong])                                // #nosec G115 -- reinterpreting packed bits
+		indices[i] = int(word >> (uint(i%perLong) * uint(width)) & mask) // #nosec G115 -- masked to the width
+	}
+	return indices, nil
+}
+
+// Section is a 16x16x16 section of a chunk, with its block state palette and the packed palette indices of its
+// blocks, ordered by Y, then Z, then X.
+type Section struct {
+	// Y is the section Y coordinate, negative below block Y 0 since Java edition 1.18.
+	Y int
+	// Palette is the block state palette.
+	Palette []BlockState
+	// Data is the packed palette indices, Bits wide. A single entry palette has no data and zero Bits.
+	Data []int64
+	Bits int
+	// Biomes is the biome palette and BiomeData its packed indices, one per 4x4x4 blocks, since Java edition 1.18.
+	Biomes    []string
+	BiomeData []int64
+}
+
+// Sections returns an iterator over the sections of a chunk that hold blocks, in the order stored. It reads the
+// sections list of Java edition 1.18 and later, and the Level.Sections list of 1.16 and 1.17. Sections without a
+// palette, such as those holding only light data, are skipped. A section that fails to read is yielded with its
+// error, and iteration continues with the next section.
+func Sections(chunk Tag) iter.Seq2[Section, error] {
+	return func(yield func(Section, error) bool) {
+		sections, modern := childPayload[[]any](chunk, "sections")
+		if !modern {
+			level, _ := compoundChild(chunk, "Level")
+			sections, _ = childPayload[[]any](level, "Sections")
+		}
+
+		for i, element := range sections {
+			section, ok, err := readSection(Tag{id: tagCompound, payload: element}, modern)
+			if err != nil {
+				err = fmt.Errorf("Unable to read section %v: %w", i, err)
+			}
+			if (ok || err != nil) && !yield(section, err) {
+				return
+			}
+		}
+	}
+}
+
+// readSection reads a section in the layout of 1.18 and later if modern, or of 1.16 and 1.17. The boolean is false
+// if the section has no palette.
+func readSection(t Tag, modern bool) (s Section, ok bool, err error) {
+	y, _ := childPayload[byte](t, "Y")
+	s.Y = int(int8(y)) // #nosec G115 -- reinterpreting the signed byte
+
+	palette, ok := childPayload[[]any](t, "Palette")
+	s.Data, _ = childPayload[[]int64](t, "BlockStates")
+	if modern {
+		states, _ := compoundChild(t, "block_states")
+		palette, ok = childPayload[[]any](states, "palette")
+		s.Data, _ = childPayload[[]int64](states, "data")
+
+		biomes, _ := compoundChild(t, "biomes")
+		biomePalette, _ := childPayload[[]any](biomes, "palette")
+		s.BiomeData, _ = childPayload[[]int64](biomes, "data")
+		for _, biome := range biomePalette {
+			name, isString := biome.(string)
+			if !isString {
+				return Section{}, false, fmt.Errorf("biome palette entry has payload type %T, not string", biome)
+			}
+			s.Biomes = append(s.Biomes, name)
+		}
+	}
+	if !ok || len(palette) == 0 {
+		return Section{}, false, nil
+	}
+
+	for _, entry := range palette {
+		state, err := BlockStateFromTag(Tag{id: tagCompound, payload: entry})
+		if err != nil {
+			return Section{}, false, err
+		}
+		s.Palette = append(s.Palette, state)
+	}
+
+	s.Bits = blockStateBits(len(s.Palette))
+	if !modern {
+		// Before 1.18 a single entry palette is still stored with data at the minimum width.
+		s.Bits = max(minBlockStateBits, s.Bits)
+	}
+	return s, true, nil
+}
+
+// Indices returns the palette index of every block of the section.
+func (s Section) Indices() ([]int, error) {
+	indices, err := unpackIndices(s.Data, s.Bits, sectionBlocks)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to unpack section %v: %w", s.Y, err)
+	}
+	for i, index := range indices {
+		if index >= len(s.Palette) {
+			return nil, fmt.Errorf("Unable to unpack section %v: block %v has index %v beyond the palette of %v", s.Y,
+				i, index, len(s.Palette))
+		}
+	}
+	return indices, nil
+}
+
+// Block returns the block state at block coordinates within the section, each from 0 to 15.
+func (s Section) Block(x, y, z int) (BlockState, error) {
+	if s.Bits == 0 {
+		return s.Palette[0], nil
+	}
+
+	i := (y&15)<<8 | (z&15)<<4 | x&15
+	perLong := 64 / s.Bits
+	if i/perLong >= len(s.Data) {
+		return BlockState{}, fmt.Errorf("Unable to read block of section %v: data has %v longs, too few for %v bits",
+			s.Y, len(s.Data), s.Bits)
+	}
+	shift := uint(i%perLong) * uint(s.Bits)
+	index := int(uint64(s.Data[i/perLong]) >> shift & (1<<uint(s.Bits) - 1)) // #nosec G115 -- masked packed bits
+	if index >= len(s.Palette) {
+		return BlockState{}, fmt.Errorf("Unable to read block of section %v: index %v is beyond the palette of %v",
+			s.Y, index, len(s.Palette))
+	}
+	return s.Palette[index], nil
+}
+
+// Empty reports whether every entry of the palette is air, so the section holds no blocks.
+func (s Section) Empty() bool {
+	for _, state := range s.Palette {
+		switch state.Name {
+		case "minecraft:air", "minecraft:cave_air", "minecraft:void_air":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// FindBlocks returns an iterator over the world positions of the blocks of a chunk whose state matches, in section
+// order. Sections whose palette has no matching entry are skipped without unpacking their data, so searching for a
+// rare block is cheap. The chunk position is read from xPos and zPos, at the root or in the pre 1.18 Level compound.
+// A section that fails to read is yielded with its error, and the search continues with the next section.
+func FindBlocks(chunk Tag, match func(BlockState) bool) iter.Seq2[BlockPos, error] {
+	return func(yield func(BlockPos, error) bool) {
+		position := chunk
+		if level, ok := compoundChild(chunk, "Level"); ok {
+			position = level
+		}
+		chunkX, xOK := childPayload[int32](position, "xPos")
+		chunkZ, zOK := childPayload[int32](position, "zPos")
+		if !xOK || !zOK {
+			yield(BlockPos{}, fmt.Errorf("Unable to find blocks: chunk has no tagInt xPos and zPos"))
+			return
+		}
+
+		for section, err := range Sections(chunk) {
+			if err == nil && !slices.ContainsFunc(section.Palette, match) {
+				continue
+			}
+			var indices []int
+			if err == nil {
+				indices, err = section.Indices()
+			}
+			if err != nil {
+				if !yield(BlockPos{}, err) {
+					return
+				}
+				continue
+			}
+
+			matches := make([]bool, len(section.Palette))
+			for i, state := range section.Palette {
+				matches[i] = match(state)
+			}
+			for i, index := range indices {
+				if !matches[index] {
+					continue
+				}
+				pos := BlockPos{
+					X: chunkX*16 + int32(i&15),    // #nosec G115 -- within a section
+					Y: int32(section.Y*16 + i>>8), // #nosec G115 -- within the world height
+					Z: chunkZ*16 + int32(i>>4&15), // #nosec G115 -- within a section
+				}
+				if !yield(pos, nil) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package nbt
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestBlockStateBits(t *testing.T) {
+	successCases := []struct {
+		paletteLen    int
+		wantBlockBits int
+		wantBiomeBits int
+	}{
+		{1, 0, 0},
+		{2, 4, 1},
+		{16, 4, 4},
+		{17, 5, 5},
+		{64, 6, 6},
+		{65, 7, 7},
+	}
+	for _, successCase := range successCases {
+		if got := blockStateBits(successCase.paletteLen); got != successCase.wantBlockBits {
+			t.Errorf("got %v block bits for %v, want %v", got, successCase.paletteLen, successCase.wantBlockBits)
+		}
+		if got := biomeBits(successCase.paletteLen); got != successCase.wantBiomeBits {
+			t.Errorf("got %v biome bits for %v, want %v", got, successCase.paletteLen, successCase.wantBiomeBits)
+		}
+	}
+}
+
+func TestPackIndices(t *testing.T) {
+	t.Run("Test success case: known packing", func(t *testing.T) {
+		// With 5 bits, 12 indices fit a long and the top 4 bits are unused.
+		indices := make([]int, 13)
+		indices[0], indices[1], indices[11], indices[12] = 1, 31, 2, 3
+		got := packIndices(indices, 5)
+		want := []int64{1 | 31<<5 | 2<<55, 3}
+		if !slices.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Test success case: zero width", func(t *testing.T) {
+		if got := packIndices(make([]int, sectionBlocks), 0); got != nil {
+			t.Errorf("got %v, want nil", got)
+		}
+	})
+
+	r := rand.New(rand.NewSource(1)) // #nosec G404 -- deterministic test data
+	for _, width := range []int{1, 4, 5, 7, 8, 15, 16, 32} {
+		t.Run("Test success case: round trip", func(t *testing.T) {
+			indices := make([]int, sectionBlocks)
+			for i := range indices {
+				indices[i] = r.Intn(1 << width)
+			}
+			got, err := unpackIndices(packIndices(indices, width), width, len(indices))
+			if err != nil || !slices.Equal(got, indices) {
+				t.Errorf("got %v, want round trip of %v bits", err, width)
+			}
+		})
+	}
+}
+
+func TestUnpackIndices(t *testing.T) {
+	t.Run("Test success case: zero width", func(t *testing.T) {
+		got, err := unpackIndices(nil, 0, 3)
+		if err != nil || !slices.Equal(got, []int{0, 0, 0}) {
+			t.Errorf("got %v, %v, want [0 0 0]", got, err)
+		}
+	})
+
+	t.Run("Test failure case: wrong number of longs", func(t *testing.T) {
+		_, err := unpackIndices(make([]int64, 255), 4, sectionBlocks)
+		if err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+}
+
+func TestSections(t *testing.T) {
+	t.Run("Test success case: synthetic chunk", func(t *testing.T) {
+		chunk := ChunkGenerator{Fill: 0.5}.Chunk(rand.New(rand.NewSource(2)), 0, 0)
+		var sections []Section
+		for section, err := range Sections(chunk) {
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			sections = append(sections, section)
+		}
+
+		if len(sections) != syntheticSections || sections[0].Y != syntheticMinSection {
+			t.Fatalf("got %v sections from Y %v, want %v from %v", len(sections), sections[0].Y, syntheticSections,
+				syntheticMinSection)
+		}
+		if sections[0].Empty() || !sections[len(sections)-1].Empty() || len(sections[0].Biomes) != 1 {
+			t.Errorf("got bottom section empty %v and top empty %v, want filled bottom and empty top",
+				sections[0].Empty(), sections[len(sections)-1].Empty())
+		}
+
+		bottom := sections[0]
+		indices, err := bottom.Indices()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, i := range []int{0, 1, 17, 300, 4095} {
+			got, err := bottom.Block(i&15, i>>8, i>>4&15)
+			if err != nil || got.Name != bottom.Palette[indices[i]].Name {
+				t.Errorf("got block %v %v at %v, want %v", got, err, i, bottom.Palette[indices[i]])
+			}
+		}
+	})
+
+	t.Run("Test success case: 1.17 layout skipping light only sections", func(t *testing.T) {
+		stone := BlockState{Name: "minecraft:stone"}.Tag().payload
+		chunk := Tag{id: tagCompound, payload: []Tag{{id: tagCompound, name: "Level", payload: []Tag{
+			{id: tagList, elementID: tagCompound, name: "Sections", payload: []any{
+				[]Tag{{id: tagByte, name: "Y", payload: byte(0xFF)}},
+				[]Tag{
+					{id: tagByte, name: "Y", payload: byte(0)},
+					{id: tagList, elementID: tagCompound, name: "Palette", payload: []any{stone}},
+					{id: tagLongArray, name: "BlockStates", payload: make([]int64, 256)},
+				},
+			}},
+		}}}}
+
+		var got []Section
+		for section, err := range Sections(chunk) {
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			got = append(got, section)
+		}
+		if len(got) != 1 || got[0].Y != 0 || got[0].Bits != minBlockStateBits {
+			t.Fatalf("got %+v, want section 0 at %v bits", got, minBlockStateBits)
+		}
+		if block, err := got[0].Block(15, 15, 15); err != nil || block.Name != "minecraft:stone" {
+			t.Errorf("got %v %v, want stone", block, err)
+		}
+	})
+
+	t.Run("Test failure case: invalid sections", func(t *testing.T) {
+		chunk := Tag{id: tagCompound, payload: []Tag{{id: tagList, elementID: tagCompound, name: "sections",
+			payload: []any{
+				[]Tag{{id: tagCompound, name: "block_states", payload: []Tag{
+					{id: tagList, elementID: tagCompound, name: "palette", payload: []any{[]Tag{}}},
+				}}},
+				[]Tag{{id: tagCompound, name: "biomes", payload: []Tag{
+					{id: tagList, elementID: tagInt, name: "palette", payload: []any{int32(1)}},
+				}}},
+			}}}}
+
+		errs := 0
+		for _, err := range Sections(chunk) {
+			if err != nil {
+				errs++
+			}
+		}
+		if errs != 2 {
+			t.Errorf("got %v errors, want 2", errs)
+		}
+	})
+
+	t.Run("Test failure case: short data", func(t *testing.T) {
+		section := Section{Palette: make([]BlockState, 2), Bits: minBlockStateBits, Data: make([]int64, 10)}
+		if _, err := section.Indices(); err == nil {
+			t.Errorf("got no error from Indices, want error")
+		}
+		if _, err := section.Block(0, 15, 0); err == nil {
+			t.Errorf("got no error from Block, want error")
+		}
+	})
+}
+
+func TestFindBlocks(t *testing.T) {
+	ore := func(state BlockState) bool { return state.Name == "minecraft:iron_ore" }
+	chunk := ChunkGenerator{Fill: 1}.Chunk(rand.New(rand.NewSource(3)), -2, 5)
+
+	want := 0
+	for section, err := range Sections(chunk) {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		indices, _ := section.Indices()
+		for _, index := range indices {
+			if ore(section.Palette[index]) {
+				want++
+			}
+		}
+	}
+	if want == 0 {
+		t.Fatalf("got no iron ore in the test chunk, want some")
+	}
+
+	got := 0
+	for pos, err := range FindBlocks(chunk, ore) {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if pos.X < -32 || pos.X >= -16 || pos.Z < 80 || pos.Z >= 96 || pos.Y < -64 || pos.Y >= 320 {
+			t.Fatalf("got %+v, want a position within chunk -2,5", pos)
+		}
+		section := Section{}
+		for s := range Sections(chunk) {
+			if s.Y == int(pos.Y>>4) {
+				section = s
+			}
+		}
+		if state, _ := section.Block(int(pos.X), int(pos.Y), int(pos.Z)); !ore(state) {
+			t.Fatalf("got %v at %+v, want iron ore", state, pos)
+		}
+		got++
+	}
+	if got != want {
+		t.Errorf("got %v iron ore, want %v", got, want)
+	}
+
+	t.Run("Test success case: no match skips every section", func(t *testing.T) {
+		for pos, err := range FindBlocks(chunk, func(BlockState) bool { return false }) {
+			t.Errorf("got %+v %v, want nothing", pos, err)
+		}
+	})
+
+	t.Run("Test failure case: no position", func(t *testing.T) {
+		for _, err := range FindBlocks(Tag{id: tagCompound}, ore) {
+			if err == nil {
+				t.Errorf("got no error, want error")
+			}
+		}
+	})
+}
@@ -0,0 +1,50 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import "slices"
+
+// Clone returns a deep copy of the tag, copying the children of compounds, the elements of lists and the contents of
+// arrays, so the copy can be modified in place without modifying t. This suits stamping the same template tag into many
+// trees.
+func (t Tag) Clone() Tag {
+	t.payload = clonePayload(t.payload)
+	if t.source != nil {
+		source := *t.source
+		t.source = &source
+	}
+	return t
+}
+
+// clonePayload returns a deep copy of a payload. Scalar and string payloads are returned as is.
+func clonePayload(payload any) any {
+	switch p := payload.(type) {
+	case []Tag:
+		if p == nil {
+			return p
+		}
+		children := make([]Tag, len(p))
+		for i := range p {
+			children[i] = p[i].Clone()
+		}
+		return children
+	case []any:
+		if p == nil {
+			return p
+		}
+		elements := make([]any, len(p))
+		for i := range p {
+			elements[i] = clonePayload(p[i])
+		}
+		return elements
+	case []byte:
+		return slices.Clone(p)
+	case RawPayload:
+		return slices.Clone(p)
+	case []int32:
+		return slices.Clone(p)
+	case []int64:
+		return slices.Clone(p)
+	default:
+		return p
+	}
+}
@@ -0,0 +1,42 @@
+package nbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClone(t *testing.T) {
+	inner, _ := NewList("", IDIntArray, NewIntArray("", []int32{1, 2}))
+	lists, _ := NewList("Lists", IDList, inner)
+	sign, _ := NewCompound("", NewString("id", "minecraft:sign"), NewByteArray("ba", []byte{3}), lists,
+		NewLongArray("la", []int64{4}))
+
+	t.Run("Test success case: equal copy", func(t *testing.T) {
+		clone := sign.Clone()
+		if !reflect.DeepEqual(clone, sign) {
+			t.Errorf("got %v, want %v", clone, sign)
+		}
+	})
+
+	t.Run("Test success case: modifying the copy leaves the original", func(t *testing.T) {
+		clone := sign.Clone()
+		children := clone.payload.([]Tag)
+		children[0].payload = "minecraft:oak_sign"
+		children[1].payload.([]byte)[0] = 9
+		children[2].payload.([]any)[0].([]any)[0].([]int32)[0] = 9
+		children[3].payload.([]int64)[0] = 9
+
+		if got := sign.String(); got != `{id:"minecraft:sign",ba:[B;3b],Lists:[[[I;1,2]]],la:[L;4L]}` {
+			t.Errorf("original modified: %v", got)
+		}
+		if got := clone.String(); got != `{id:"minecraft:oak_sign",ba:[B;9b],Lists:[[[I;9,2]]],la:[L;9L]}` {
+			t.Errorf("got %v", got)
+		}
+	})
+
+	t.Run("Test success case: scalar", func(t *testing.T) {
+		if got := NewInt("i", 1).Clone(); !reflect.DeepEqual(got, NewInt("i", 1)) {
+			t.Errorf("got %v", got)
+		}
+	})
+}
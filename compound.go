@@ -0,0 +1,201 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import "fmt"
+
+// Child returns the named child of a tagCompound. The boolean is false if t is not a tagCompound or has no child with
+// that name.
+func (t Tag) Child(name string) (child Tag, ok bool) {
+	if t.id != tagCompound {
+		return Tag{}, false
+	}
+	return compoundChild(t, name)
+}
+
+// Has reports whether t is a tagCompound with a child of the name.
+func (t Tag) Has(name string) bool {
+	_, ok := t.Child(name)
+	return ok
+}
+
+// Keys returns the names of the children of a tagCompound, in order. A tag that is not a tagCompound has none.
+func (t Tag) Keys() (names []string) {
+	if t.id != tagCompound {
+		return nil
+	}
+	children, _ := t.payload.([]Tag)
+	for _, child := range children {
+		names = append(names, child.name)
+	}
+	return names
+}
+
+// Put adds the child to a tagCompound, replacing any child of the same name in place, so names stay unique. The
+// children are copied rather than modified, so trees sharing them are not changed.
+func (t *Tag) Put(child Tag) (err error) {
+	if t.id != tagCompound {
+		return fmt.Errorf("Unable to put \"%v\": tag \"%v\" is not a tagCompound", child.name, t.name)
+	}
+	if child.id == tagEnd {
+		return fmt.Errorf("Unable to put \"%v\": a tagEnd ends the compound", child.name)
+	}
+
+	children, _ := t.payload.([]Tag)
+	t.payload = withChild(children, child)
+	return nil
+}
+
+// PutByte puts a tagByte child, see Put.
+func (t *Tag) PutByte(name string, payload byte) error {
+	return t.Put(NewByte(name, payload))
+}
+
+// PutShort puts a tagShort child, see Put.
+func (t *Tag) PutShort(name string, payload int16) error {
+	return t.Put(NewShort(name, payload))
+}
+
+// PutInt puts a tagInt child, see Put.
+func (t *Tag) PutInt(name string, payload int32) error {
+	return t.Put(NewInt(name, payload))
+}
+
+// PutLong puts a tagLong child, see Put.
+func (t *Tag) PutLong(name string, payload int64) error {
+	return t.Put(NewLong(name, payload))
+}
+
+// PutFloat puts a tagFloat child, see Put.
+func (t *Tag) PutFloat(name string, payload float32) error {
+	return t.Put(NewFloat(name, payload))
+}
+
+// PutDouble puts a tagDouble child, see Put.
+func (t *Tag) PutDouble(name string, payload float64) error {
+	return t.Put(NewDouble(name, payload))
+}
+
+// PutString puts a tagString child, see Put.
+func (t *Tag) PutString(name string, payload string) error {
+	return t.Put(NewString(name, payload))
+}
+
+// PutByteArray puts a tagByteArray child holding a copy of the payload, see Put.
+func (t *Tag) PutByteArray(name string, payload []byte) error {
+	return t.Put(NewByteArray(name, payload))
+}
+
+// PutIntArray puts a tagIntArray child holding a copy of the payload, see Put.
+func (t *Tag) PutIntArray(name string, payload []int32) error {
+	return t.Put(NewIntArray(name, payload))
+}
+
+// PutLongArray puts a tagLongArray child holding a copy of the payload, see Put.
+func (t *Tag) PutLongArray(name string, payload []int64) error {
+	return t.Put(NewLongArray(name, payload))
+}
+
+// Remove removes the named child of a tagCompound, reporting whether there was one. The children are copied rather
+// than modified, so trees sharing them are not changed.
+func (t *Tag) Remove(name string) (removed bool) {
+	if t.id != tagCompound {
+		return false
+	}
+	children, _ := t.payload.([]Tag)
+	t.payload, removed = withoutChild(children, name)
+	return removed
+}
+
+// GetBool returns the named tagByte child of a compound as a boolean, true if it is non-zero. The boolean ok is false
+// if there is no tagByte child of the name.
+func (t Tag) GetBool(name string) (v bool, ok bool) {
+	b, ok := childPayload[byte](t, name)
+	return b != 0, ok
+}
+
+// GetByte returns the payload of the named tagByte child of a compound. The boolean is false if there is no tagByte
+// child of the name.
+func (t Tag) GetByte(name string) (v byte, ok bool) {
+	return childPayload[byte](t, name)
+}
+
+// GetInt returns the payload of the named tagByte, tagShort or tagInt child of a compound, widened to an int. Bytes are
+// signed, as in Minecraft. The boolean is false if there is no child of the name of those types, so a tagLong, which
+// may not fit, is not returned; see GetInt64.
+func (t Tag) GetInt(name string) (v int, ok bool) {
+	child, ok := t.Child(name)
+	if !ok || child.id == tagLong {
+		return 0, false
+	}
+	i, ok := integerPayload(child.payload)
+	return int(i), ok // #nosec G115 -- a tagByte, tagShort or tagInt fits an int
+}
+
+// GetInt64 returns the payload of the named tagByte, tagShort, tagInt or tagLong child of a compound, widened to an
+// int64. Bytes are signed, as in Minecraft. The boolean is false if there is no child of the name of those types.
+func (t Tag) GetInt64(name string) (v int64, ok bool) {
+	child, ok := t.Child(name)
+	if !ok {
+		return 0, false
+	}
+	return integerPayload(child.payload)
+}
+
+// GetFloat64 returns the payload of the named tagFloat or tagDouble child of a compound, widened to a float64. The
+// boolean is false if there is no child of the name of those types.
+func (t Tag) GetFloat64(name string) (v float64, ok bool) {
+	child, ok := t.Child(name)
+	if !ok {
+		return 0, false
+	}
+	return float64Payload(child.payload)
+}
+
+// GetString returns the payload of the named tagString child of a compound. The boolean is false if there is no
+// tagString child of the name.
+func (t Tag) GetString(name string) (v string, ok bool) {
+	return childPayload[string](t, name)
+}
+
+// GetByteArray returns the payload of the named tagByteArray child of a compound, shared with the tree. The boolean is
+// false if there is no tagByteArray child of the name.
+func (t Tag) GetByteArray(name string) (v []byte, ok bool) {
+	return childPayload[[]byte](t, name)
+}
+
+// GetIntArray returns the payload of the named tagIntArray child of a compound, shared with the tree. The boolean is
+// false if there is no tagIntArray child of the name.
+func (t Tag) GetIntArray(name string) (v []int32, ok bool) {
+	return childPayload[[]int32](t, name)
+}
+
+// GetLongArray returns the payload of the named tagLongArray child of a compound, shared with the tree. The boolean is
+// false if there is no tagLongArray child of the name.
+func (t Tag) GetLongArray(name string) (v []int64, ok bool) {
+	return childPayload[[]int64](t, name)
+}
+
+// GetCompound returns the named tagCompound child of a compound. The boolean is false if there is no tagCompound child
+// of the name.
+func (t Tag) GetCompound(name string) (child Tag, ok bool) {
+	child, ok = t.Child(name)
+	return child, ok && child.id == tagCompound
+}
+
+// GetList returns the named tagList child of a compound. The boolean is false if there is no tagList child of the name.
+func (t Tag) GetList(name string) (child Tag, ok bool) {
+	child, ok = t.Child(name)
+	return child, ok && child.id == tagList
+}
+
+// float64Payload returns a tagFloat or tagDouble payload widened to a float64.
+func float64Payload(payload any) (v float64, ok bool) {
+	switch p := payload.(type) {
+	case float32:
+		return float64(p), true
+	case float64:
+		return p, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,161 @@
+package nbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompoundAccessors(t *testing.T) {
+	level, _ := NewCompound("Data", NewString("LevelName", "World"), NewInt("version", 19133))
+
+	t.Run("Test success case: child, has and keys", func(t *testing.T) {
+		child, ok := level.Child("LevelName")
+		if !ok || child.payload != "World" {
+			t.Errorf("Child() = %v %v, want World", child, ok)
+		}
+		if !level.Has("version") || level.Has("Missing") {
+			t.Errorf("Has() wrong for version or Missing")
+		}
+		if got := level.Keys(); !reflect.DeepEqual(got, []string{"LevelName", "version"}) {
+			t.Errorf("Keys() = %v", got)
+		}
+	})
+
+	t.Run("Test success case: put replaces in place and adds", func(t *testing.T) {
+		edited := level
+		if err := edited.PutString("LevelName", "My World"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := edited.PutByte("hardcore", 1); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := edited.PutLongArray("la", []int64{1}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := edited.String(); got != `{LevelName:"My World",version:19133,hardcore:1b,la:[L;1L]}` {
+			t.Errorf("got %v", got)
+		}
+		if got := level.String(); got != `{LevelName:"World",version:19133}` {
+			t.Errorf("original modified: %v", got)
+		}
+	})
+
+	t.Run("Test success case: every typed put", func(t *testing.T) {
+		c := Tag{id: tagCompound}
+		puts := []error{c.PutShort("s", 1), c.PutInt("i", 2), c.PutLong("l", 3), c.PutFloat("f", 4),
+			c.PutDouble("d", 5), c.PutByteArray("ba", []byte{6}), c.PutIntArray("ia", []int32{7})}
+		for _, err := range puts {
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		if got := c.String(); got != "{s:1s,i:2,l:3L,f:4f,d:5d,ba:[B;6b],ia:[I;7]}" {
+			t.Errorf("got %v", got)
+		}
+	})
+
+	t.Run("Test success case: remove", func(t *testing.T) {
+		edited := level
+		if !edited.Remove("LevelName") || edited.Remove("Missing") {
+			t.Errorf("Remove() wrong for LevelName or Missing")
+		}
+		if got := edited.Keys(); !reflect.DeepEqual(got, []string{"version"}) {
+			t.Errorf("Keys() = %v", got)
+		}
+		if !level.Has("LevelName") {
+			t.Errorf("original modified")
+		}
+	})
+
+	t.Run("Test failure case: not a compound", func(t *testing.T) {
+		s := NewString("s", "x")
+		if _, ok := s.Child("x"); ok || s.Keys() != nil || s.Remove("x") {
+			t.Errorf("accessors of a tagString found children")
+		}
+		if err := s.PutInt("x", 1); err == nil {
+			t.Errorf("got nil, want non-nil")
+		}
+	})
+
+	t.Run("Test failure case: put a tagEnd", func(t *testing.T) {
+		c := level
+		if err := c.Put(Tag{name: "end"}); err == nil {
+			t.Errorf("got nil, want non-nil")
+		}
+	})
+}
+
+func TestCompoundGetters(t *testing.T) {
+	c, _ := NewCompound("", NewByte("b", 0xFF), NewShort("s", -2), NewInt("i", 3), NewLong("l", 1<<40),
+		NewFloat("f", 1.5), NewDouble("d", 2.5), NewString("str", "x"), NewByteArray("ba", []byte{1}),
+		NewIntArray("ia", []int32{2}), NewLongArray("la", []int64{3}), Tag{id: tagCompound, name: "c"},
+		Tag{id: tagList, name: "list", payload: []any(nil)})
+
+	t.Run("Test success case: widened numbers", func(t *testing.T) {
+		for name, want := range map[string]int{"b": -1, "s": -2, "i": 3} {
+			if got, ok := c.GetInt(name); !ok || got != want {
+				t.Errorf("GetInt(%v) = %v %v, want %v", name, got, ok, want)
+			}
+		}
+		if got, ok := c.GetInt64("l"); !ok || got != 1<<40 {
+			t.Errorf("GetInt64(l) = %v %v", got, ok)
+		}
+		if got, ok := c.GetInt64("b"); !ok || got != -1 {
+			t.Errorf("GetInt64(b) = %v %v", got, ok)
+		}
+		if got, ok := c.GetFloat64("f"); !ok || got != 1.5 {
+			t.Errorf("GetFloat64(f) = %v %v", got, ok)
+		}
+		if got, ok := c.GetFloat64("d"); !ok || got != 2.5 {
+			t.Errorf("GetFloat64(d) = %v %v", got, ok)
+		}
+		if got, ok := c.GetBool("b"); !ok || !got {
+			t.Errorf("GetBool(b) = %v %v", got, ok)
+		}
+		if got, ok := c.GetByte("b"); !ok || got != 0xFF {
+			t.Errorf("GetByte(b) = %v %v", got, ok)
+		}
+	})
+
+	t.Run("Test success case: strings, arrays, compounds and lists", func(t *testing.T) {
+		if got, ok := c.GetString("str"); !ok || got != "x" {
+			t.Errorf("GetString() = %v %v", got, ok)
+		}
+		ba, baOK := c.GetByteArray("ba")
+		ia, iaOK := c.GetIntArray("ia")
+		la, laOK := c.GetLongArray("la")
+		if !baOK || !iaOK || !laOK || ba[0] != 1 || ia[0] != 2 || la[0] != 3 {
+			t.Errorf("array getters = %v %v %v", ba, ia, la)
+		}
+		if _, ok := c.GetCompound("c"); !ok {
+			t.Errorf("GetCompound() not found")
+		}
+		if _, ok := c.GetList("list"); !ok {
+			t.Errorf("GetList() not found")
+		}
+	})
+
+	t.Run("Test failure case: missing or mistyped children", func(t *testing.T) {
+		if _, ok := c.GetInt("l"); ok {
+			t.Errorf("GetInt() returned a tagLong")
+		}
+		if _, ok := c.GetInt("missing"); ok {
+			t.Errorf("GetInt() returned a missing child")
+		}
+		if _, ok := c.GetFloat64("i"); ok {
+			t.Errorf("GetFloat64() returned a tagInt")
+		}
+		if _, ok := c.GetString("b"); ok {
+			t.Errorf("GetString() returned a tagByte")
+		}
+		if _, ok := c.GetCompound("list"); ok {
+			t.Errorf("GetCompound() returned a tagList")
+		}
+		if _, ok := c.GetList("c"); ok {
+			t.Errorf("GetList() returned a tagCompound")
+		}
+		if _, ok := NewInt("", 1).GetInt("x"); ok {
+			t.Errorf("GetInt() of a tagInt found a child")
+		}
+	})
+}
@@ -0,0 +1,190 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// Compression is the compression wrapping an NBT stream. Java edition gzips level.dat and player data, and zlib, or
+// optionally LZ4 since 1.20.5, compresses region file chunks.
+type Compression int
+
+// detectHeaderSize is the number of bytes DetectCompression needs to tell every compression apart.
+const detectHeaderSize = len(lz4Magic)
+
+// Compression values.
+const (
+	// CompressionNone is an uncompressed stream.
+	CompressionNone Compression = iota
+	// CompressionGzip is a gzip (RFC 1952) stream.
+	CompressionGzip
+	// CompressionZlib is a zlib (RFC 1950) stream.
+	CompressionZlib
+	// CompressionLZ4 is an LZ4 block stream, in the format of lz4-java's LZ4BlockOutputStream. The CompressionLevel
+	// option does not apply to it.
+	CompressionLZ4
+)
+
+// String returns the name of the compression.
+func (c Compression) String() string {
+	switch c {
+	case CompressionNone:
+		return "none"
+	case CompressionGzip:
+		return "gzip"
+	case CompressionZlib:
+		return "zlib"
+	case CompressionLZ4:
+		return "lz4"
+	default:
+		return fmt.Sprintf("Compression(%d)", int(c))
+	}
+}
+
+// DetectCompression returns the compression of a stream starting with the header bytes, from its magic bytes: 0x1F 0x8B
+// for gzip, a zlib header using deflate with a window of 512 bytes or more and a valid check for zlib, and "LZ4Block"
+// for LZ4, so up to 8 header bytes are needed. Any other start, including a short header, is taken to be uncompressed,
+// as NBT starts with a tag ID of at most 12.
+func DetectCompression(header []byte) Compression {
+	switch {
+	case len(header) < 2:
+		return CompressionNone
+	case bytes.HasPrefix(header, []byte(lz4Magic)):
+		return CompressionLZ4
+	case header[0] == 0x1F && header[1] == 0x8B:
+		return CompressionGzip
+	case header[0]&0x0F == 8 && header[0]>>4 >= 1 && header[0]>>4 <= 7 &&
+		(uint16(header[0])<<8|uint16(header[1]))%31 == 0:
+		return CompressionZlib
+	default:
+		return CompressionNone
+	}
+}
+
+// ReadCompressed reads a tag as ReadTag does, detecting the compression of the buffer with DetectCompression in place
+// of the Compression option, and returns the compression found.
+func ReadCompressed(buffer io.Reader, opts ...Option) (t Tag, compression Compression, err error) {
+	// Limiting the reader to the size of a sized buffer lets an uncompressed tag be checked against the bytes remaining.
+	buffered := bufio.NewReader(buffer)
+	var r io.Reader = buffered
+	if s, ok := buffer.(interface{ Len() int }); ok {
+		r = &io.LimitedReader{R: buffered, N: int64(s.Len())}
+	}
+
+	header, err := buffered.Peek(detectHeaderSize)
+	if err != nil && err != io.EOF {
+		return Tag{}, CompressionNone, fmt.Errorf("Unable to read tag: %w", err)
+	}
+	compression = DetectCompression(header)
+
+	t, err = ReadTag(r, append(slices.Clip(opts), WithCompression(compression))...)
+	if err != nil {
+		return Tag{}, compression, err
+	}
+	return t, compression, nil
+}
+
+// decompress returns a reader of the decompressed bytes of r.
+func decompress(r io.Reader, c Compression) (io.Reader, error) {
+	switch c {
+	case CompressionNone:
+		return r, nil
+	case CompressionGzip:
+		return newGzipMembersReader(r)
+	case CompressionZlib:
+		return zlib.NewReader(r)
+	case CompressionLZ4:
+		return newLZ4Reader(r), nil
+	default:
+		return nil, fmt.Errorf("unknown compression %v", c)
+	}
+}
+
+// compress returns a writer compressing to w at the compress/flate level. Closing it flushes the compressed stream, but
+// does not close w.
+func compress(w io.Writer, c Compression, level int) (io.WriteCloser, error) {
+	switch c {
+	case CompressionNone:
+		return nopWriteCloser{w}, nil
+	case CompressionGzip:
+		return gzip.NewWriterLevel(w, level)
+	case CompressionZlib:
+		return zlib.NewWriterLevel(w, level)
+	case CompressionLZ4:
+		return newLZ4Writer(w), nil
+	default:
+		return nil, fmt.Errorf("unknown compression %v", c)
+	}
+}
+
+// nopWriteCloser is an uncompressed writer, with nothing to flush on Close.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+// Close does nothing.
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// gzipMembersReader decompresses a gzip stream of one or more members, as some tools write, ending at the first member
+// followed only by zero bytes so that benign trailing padding is ignored.
+type gzipMembersReader struct {
+	r *bufio.Reader
+	z *gzip.Reader
+}
+
+// newGzipMembersReader returns a gzipMembersReader reading the first member of r.
+func newGzipMembersReader(r io.Reader) (*gzipMembersReader, error) {
+	buffered := bufio.NewReader(r)
+	z, err := gzip.NewReader(buffered)
+	if err != nil {
+		return nil, err
+	}
+	z.Multistream(false)
+
+	return &gzipMembersReader{r: buffered, z: z}, nil
+}
+
+// Read reads decompressed bytes, moving on to the next member when one ends.
+func (g *gzipMembersReader) Read(p []byte) (n int, err error) {
+	for {
+		n, err = g.z.Read(p)
+		if err != io.EOF {
+			return n, err
+		}
+		if n > 0 {
+			// The next Read finds the member at io.EOF again and moves on.
+			return n, nil
+		}
+
+		err = skipZeroPadding(g.r)
+		if err != nil {
+			return 0, err
+		}
+		err = g.z.Reset(g.r)
+		if err != nil {
+			return 0, err
+		}
+		g.z.Multistream(false)
+	}
+}
+
+// skipZeroPadding skips zero bytes up to the next non-zero byte, returning io.EOF if only zero bytes remain.
+func skipZeroPadding(r *bufio.Reader) error {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if b != 0 {
+			return r.UnreadByte()
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package nbt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestDecompress(t *testing.T) {
+	input := []byte{tagByte, 0, 1, 'a', 1}
+	var gzipped, zlibbed bytes.Buffer
+	gzipWriter := gzip.NewWriter(&gzipped)
+	_, _ = gzipWriter.Write(input)
+	_ = gzipWriter.Close()
+	zlibWriter := zlib.NewWriter(&zlibbed)
+	_, _ = zlibWriter.Write(input)
+	_ = zlibWriter.Close()
+
+	successCases := []struct {
+		name        string
+		compression Compression
+		input       []byte
+	}{
+		{"none", CompressionNone, input},
+		{"gzip", CompressionGzip, gzipped.Bytes()},
+		{"zlib", CompressionZlib, zlibbed.Bytes()},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			r, err := decompress(bytes.NewReader(successCase.input), successCase.compression)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil || !bytes.Equal(got, input) {
+				t.Errorf("got %v, %v, want %v", got, err, input)
+			}
+
+			tag, err := ReadTag(bytes.NewReader(successCase.input), WithCompression(successCase.compression))
+			if err != nil || tag.payload != byte(1) {
+				t.Errorf("got %v, %v, want payload 1", tag, err)
+			}
+		})
+	}
+
+	failureCases := []struct {
+		name        string
+		compression Compression
+		input       []byte
+	}{
+		{"gzip of zlib", CompressionGzip, zlibbed.Bytes()},
+		{"zlib of gzip", CompressionZlib, gzipped.Bytes()},
+		{"unknown compression", Compression(-1), input},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			_, err := decompress(bytes.NewReader(failureCase.input), failureCase.compression)
+			if err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestGzipMembersReader(t *testing.T) {
+	member := func(input []byte) []byte {
+		var b bytes.Buffer
+		w := gzip.NewWriter(&b)
+		_, _ = w.Write(input)
+		_ = w.Close()
+		return b.Bytes()
+	}
+	first, second := member([]byte{tagCompound, 0, 0, tagByte}), member([]byte{0, 1, 'a', 1, tagEnd})
+
+	successCases := []struct {
+		name  string
+		input []byte
+	}{
+		{"single member", member([]byte{tagCompound, 0, 0, tagByte, 0, 1, 'a', 1, tagEnd})},
+		{"tag spanning members", slices.Concat(first, second)},
+		{"trailing zero padding", slices.Concat(first, second, make([]byte, 512))},
+		{"zero padding between members", slices.Concat(first, make([]byte, 3), second)},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			r, err := decompress(bytes.NewReader(successCase.input), CompressionGzip)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			got, err := io.ReadAll(r)
+			want := []byte{tagCompound, 0, 0, tagByte, 0, 1, 'a', 1, tagEnd}
+			if err != nil || !bytes.Equal(got, want) {
+				t.Errorf("got %v, %v, want %v", got, err, want)
+			}
+		})
+	}
+
+	failureCases := []struct {
+		name  string
+		input []byte
+	}{
+		{"trailing garbage", slices.Concat(first, second, []byte{1, 2, 3})},
+		{"truncated second member", slices.Concat(first, second[:len(second)-4])},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			r, err := decompress(bytes.NewReader(failureCase.input), CompressionGzip)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			_, err = io.ReadAll(r)
+			if err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestCompressionString(t *testing.T) {
+	successCases := []struct {
+		compression Compression
+		want        string
+	}{
+		{CompressionNone, "none"},
+		{CompressionGzip, "gzip"},
+		{CompressionZlib, "zlib"},
+		{CompressionLZ4, "lz4"},
+		{Compression(7), "Compression(7)"},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.want, func(t *testing.T) {
+			if got := successCase.compression.String(); got != successCase.want {
+				t.Errorf("got %v, want %v", got, successCase.want)
+			}
+		})
+	}
+}
+
+func TestReadCompressed(t *testing.T) {
+	input := []byte{tagByte, 0, 1, 'a', 1}
+	var gzipped, zlibbed, bestZlib bytes.Buffer
+	gzipWriter := gzip.NewWriter(&gzipped)
+	_, _ = gzipWriter.Write(input)
+	_ = gzipWriter.Close()
+	zlibWriter := zlib.NewWriter(&zlibbed)
+	_, _ = zlibWriter.Write(input)
+	_ = zlibWriter.Close()
+	bestWriter, _ := zlib.NewWriterLevel(&bestZlib, zlib.BestCompression)
+	_, _ = bestWriter.Write(input)
+	_ = bestWriter.Close()
+	var lz4ed bytes.Buffer
+	lz4Writer := newLZ4Writer(&lz4ed)
+	_, _ = lz4Writer.Write(input)
+	_ = lz4Writer.Close()
+
+	successCases := []struct {
+		name  string
+		want  Compression
+		input []byte
+	}{
+		{"gzip", CompressionGzip, gzipped.Bytes()},
+		{"zlib", CompressionZlib, zlibbed.Bytes()},
+		{"zlib best compression", CompressionZlib, bestZlib.Bytes()},
+		{"lz4", CompressionLZ4, lz4ed.Bytes()},
+		{"uncompressed", CompressionNone, input},
+		{"uncompressed string root with little-endian name length", CompressionNone,
+			[]byte{tagString, 29, 0}},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			if got := DetectCompression(successCase.input); got != successCase.want {
+				t.Errorf("got %v, want %v", got, successCase.want)
+			}
+			if successCase.input[0] == tagString {
+				return
+			}
+
+			got, gotCompression, err := ReadCompressed(bytes.NewReader(successCase.input))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if gotCompression != successCase.want || got.payload != byte(1) {
+				t.Errorf("got %v %v, want %v and payload 1", gotCompression, got.payload, successCase.want)
+			}
+		})
+	}
+
+	failureCases := []struct {
+		name  string
+		input []byte
+	}{
+		{"empty", nil},
+		{"truncated gzip", gzipped.Bytes()[:5]},
+		{"uncompressed length beyond input", []byte{tagString, 0, 0, 0, 200, 'a'}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			if _, _, err := ReadCompressed(bytes.NewReader(failureCase.input)); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
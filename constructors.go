@@ -0,0 +1,121 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Tag IDs, as returned by View.ID and taken by NewList as the type of its elements.
+const (
+	IDEnd       = tagEnd
+	IDByte      = tagByte
+	IDShort     = tagShort
+	IDInt       = tagInt
+	IDLong      = tagLong
+	IDFloat     = tagFloat
+	IDDouble    = tagDouble
+	IDByteArray = tagByteArray
+	IDString    = tagString
+	IDList      = tagList
+	IDCompound  = tagCompound
+	IDIntArray  = tagIntArray
+	IDLongArray = tagLongArray
+)
+
+// NewByte returns a tagByte.
+func NewByte(name string, payload byte) Tag {
+	return Tag{id: tagByte, name: name, payload: payload}
+}
+
+// NewShort returns a tagShort.
+func NewShort(name string, payload int16) Tag {
+	return Tag{id: tagShort, name: name, payload: payload}
+}
+
+// NewInt returns a tagInt.
+func NewInt(name string, payload int32) Tag {
+	return Tag{id: tagInt, name: name, payload: payload}
+}
+
+// NewLong returns a tagLong.
+func NewLong(name string, payload int64) Tag {
+	return Tag{id: tagLong, name: name, payload: payload}
+}
+
+// NewFloat returns a tagFloat.
+func NewFloat(name string, payload float32) Tag {
+	return Tag{id: tagFloat, name: name, payload: payload}
+}
+
+// NewDouble returns a tagDouble.
+func NewDouble(name string, payload float64) Tag {
+	return Tag{id: tagDouble, name: name, payload: payload}
+}
+
+// NewString returns a tagString.
+func NewString(name string, payload string) Tag {
+	return Tag{id: tagString, name: name, payload: payload}
+}
+
+// NewByteArray returns a tagByteArray holding a copy of the payload.
+func NewByteArray(name string, payload []byte) Tag {
+	return Tag{id: tagByteArray, name: name, payload: cloneArray(payload)}
+}
+
+// NewIntArray returns a tagIntArray holding a copy of the payload.
+func NewIntArray(name string, payload []int32) Tag {
+	return Tag{id: tagIntArray, name: name, payload: cloneArray(payload)}
+}
+
+// NewLongArray returns a tagLongArray holding a copy of the payload.
+func NewLongArray(name string, payload []int64) Tag {
+	return Tag{id: tagLongArray, name: name, payload: cloneArray(payload)}
+}
+
+// cloneArray returns a copy of an array payload, or nil if it is empty, as ReadTag holds empty payloads.
+func cloneArray[P byte | int32 | int64](payload []P) []P {
+	if len(payload) == 0 {
+		return nil
+	}
+	return slices.Clone(payload)
+}
+
+// NewList returns a tagList of the elements, which must all have the element ID, such as IDCompound. The names of the
+// elements are dropped, as list elements are unnamed. The element ID is kept for an empty list.
+func NewList(name string, elementID uint8, elements ...Tag) (t Tag, err error) {
+	if elementID > tagLongArray || (elementID == tagEnd && len(elements) > 0) {
+		return Tag{}, fmt.Errorf("Unable to create tagList \"%v\": invalid element ID %v", name, elementID)
+	}
+
+	t = Tag{id: tagList, elementID: elementID, name: name, payload: []any(nil)}
+	for i, element := range elements {
+		if element.id != elementID {
+			return Tag{}, fmt.Errorf("Unable to create tagList \"%v\": element %v has tag ID %v, not %v", name, i,
+				element.id, elementID)
+		}
+		t.payload = append(t.payload.([]any), element.payload)
+	}
+	return t, nil
+}
+
+// NewCompound returns a tagCompound of the children, which must have unique names and not be tagEnd.
+func NewCompound(name string, children ...Tag) (t Tag, err error) {
+	names := make(map[string]bool, len(children))
+	for i, child := range children {
+		if child.id == tagEnd {
+			return Tag{}, fmt.Errorf("Unable to create tagCompound \"%v\": child %v is a tagEnd", name, i)
+		}
+		if names[child.name] {
+			return Tag{}, fmt.Errorf("Unable to create tagCompound \"%v\": duplicate child name \"%v\"", name,
+				child.name)
+		}
+		names[child.name] = true
+	}
+
+	var payload []Tag
+	if len(children) > 0 {
+		payload = slices.Clone(children)
+	}
+	return Tag{id: tagCompound, name: name, payload: payload}, nil
+}
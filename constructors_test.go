@@ -0,0 +1,73 @@
+package nbt
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestConstructors(t *testing.T) {
+	t.Run("Test success case: constructed tree matches the tree read", func(t *testing.T) {
+		pos, err := NewList("Pos", IDDouble, NewDouble("", 1.5), NewDouble("", -2), NewDouble("", 64))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		empty, err := NewList("Items", IDCompound)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		item, err := NewCompound("", NewString("id", "minecraft:stone"), NewByte("Count", 1))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		items, err := NewList("Inventory", IDCompound, item)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		root, err := NewCompound("", pos, empty, items, NewShort("Air", 300), NewInt("XpLevel", 5),
+			NewLong("Seed", -1), NewFloat("Health", 20), NewByteArray("Bytes", []byte{1}),
+			NewIntArray("UUID", []int32{1, 2, 3, 4}), NewLongArray("Longs", nil))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var b bytes.Buffer
+		if err = WriteTag(&b, root); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got, err := ReadTag(&b)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(got, root) {
+			t.Errorf("got %+v, want %+v", got, root)
+		}
+	})
+
+	t.Run("Test success case: arrays are copied", func(t *testing.T) {
+		ints := []int32{1, 2}
+		array := NewIntArray("a", ints)
+		ints[0] = 9
+		if got := NewView(array).Payload().([]int32); got[0] != 1 {
+			t.Errorf("got %v, want the payload unaffected by the caller", got)
+		}
+	})
+
+	failureCases := []struct {
+		name string
+		new  func() (Tag, error)
+	}{
+		{"list element ID mismatch", func() (Tag, error) { return NewList("l", IDInt, NewInt("", 1), NewByte("", 1)) }},
+		{"list of tagEnd elements", func() (Tag, error) { return NewList("l", IDEnd, Tag{}) }},
+		{"list of unknown ID", func() (Tag, error) { return NewList("l", 13) }},
+		{"duplicate compound child", func() (Tag, error) { return NewCompound("", NewInt("a", 1), NewByte("a", 1)) }},
+		{"tagEnd compound child", func() (Tag, error) { return NewCompound("", Tag{}) }},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			if _, err := failureCase.new(); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,332 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"slices"
+	"unicode/utf8"
+)
+
+// Edition is a Minecraft edition, whose conventions an NBT document follows.
+type Edition int
+
+// Edition values.
+const (
+	// EditionJava documents are big-endian with Modified UTF-8 strings.
+	EditionJava Edition = iota
+	// EditionBedrock documents are little-endian with UTF-8 strings, and level.dat has an 8 byte header.
+	EditionBedrock
+)
+
+// String returns the name of the edition.
+func (e Edition) String() string {
+	switch e {
+	case EditionJava:
+		return "Java"
+	case EditionBedrock:
+		return "Bedrock"
+	default:
+		return fmt.Sprintf("Edition(%d)", int(e))
+	}
+}
+
+// byteOrder returns the byte order of numbers, lengths and sizes in documents of the edition.
+func (e Edition) byteOrder() (binary.ByteOrder, error) {
+	switch e {
+	case EditionJava:
+		return binary.BigEndian, nil
+	case EditionBedrock:
+		return binary.LittleEndian, nil
+	default:
+		return nil, fmt.Errorf("unknown edition %v", e)
+	}
+}
+
+// Converter translates uncompressed NBT documents between editions without decoding them into a tree, so every tag is
+// carried across exactly apart from its byte order, string encoding and any renamed keys.
+type Converter struct {
+	// From and To are the editions of the input and output documents.
+	From, To Edition
+	// InputHeader is set if the input is a Bedrock level.dat, starting with the header, which is checked and removed.
+	InputHeader bool
+	// OutputHeader adds the header of a Bedrock level.dat to the output, with the storage version HeaderVersion, or
+	// that of the input header if HeaderVersion is zero.
+	OutputHeader  bool
+	HeaderVersion int32
+	// RenameKey, if set, returns the name to write for each compound child, given the path of the compound in the input
+	// document and the child's name. It is not called for the root tag.
+	RenameKey func(path Path, name string) string
+	// Limits bound the size of the tags converted, to guard against corrupt or malicious input.
+	Limits Limits
+}
+
+// conversion is the state of one Convert call.
+type conversion struct {
+	Converter
+	r        io.Reader
+	w        io.Writer
+	from, to binary.ByteOrder
+	buffer   []byte
+}
+
+// conversionBufferSize is the size of the buffer arrays are copied through, a multiple of every element size.
+const conversionBufferSize = 4096
+
+// Convert reads a document in the From edition and writes it in the To edition.
+func (c Converter) Convert(w io.Writer, r io.Reader) (err error) {
+	v := conversion{Converter: c, r: r, w: w, buffer: make([]byte, conversionBufferSize)}
+	if v.from, err = c.From.byteOrder(); err != nil {
+		return fmt.Errorf("Unable to convert document: %w", err)
+	}
+	if v.to, err = c.To.byteOrder(); err != nil {
+		return fmt.Errorf("Unable to convert document: %w", err)
+	}
+	if (c.InputHeader && c.From != EditionBedrock) || (c.OutputHeader && c.To != EditionBedrock) {
+		return fmt.Errorf("Unable to convert document: only %v edition documents have a header", EditionBedrock)
+	}
+
+	version := c.HeaderVersion
+	var input *io.LimitedReader
+	if c.InputHeader {
+		header := make([]byte, bedrockLevelHeaderSize)
+		if _, err = io.ReadFull(r, header); err != nil {
+			return fmt.Errorf("Unable to convert document: unable to read header: %w", err)
+		}
+		if version == 0 {
+			version = int32(binary.LittleEndian.Uint32(header)) // #nosec G115 -- the version is stored as an int32
+		}
+		input = &io.LimitedReader{R: r, N: int64(binary.LittleEndian.Uint32(header[4:]))}
+		v.r = input
+	}
+
+	var output bytes.Buffer
+	if c.OutputHeader {
+		v.w = &output
+	}
+
+	if err = v.tag(); err != nil {
+		return fmt.Errorf("Unable to convert document: %w", err)
+	}
+	if input != nil && input.N != 0 {
+		return fmt.Errorf("Unable to convert document: header length is %v bytes longer than the document", input.N)
+	}
+
+	if c.OutputHeader {
+		header := binary.LittleEndian.AppendUint32(nil, uint32(version))        // #nosec G115 -- stored as an int32
+		header = binary.LittleEndian.AppendUint32(header, uint32(output.Len())) // #nosec G115 -- bounded by memory
+		if _, err = w.Write(append(header, output.Bytes()...)); err != nil {
+			return fmt.Errorf("Unable to convert document: %w", err)
+		}
+	}
+	return nil
+}
+
+// tag converts the root tag, its ID, name and payload.
+func (v *conversion) tag() error {
+	id, err := v.copyID()
+	if err != nil || id == tagEnd {
+		return err
+	}
+
+	name, err := v.readString()
+	if err != nil {
+		return fmt.Errorf("unable to convert root name: %w", err)
+	}
+	if err = v.writeString(name); err != nil {
+		return fmt.Errorf("unable to convert root name: %w", err)
+	}
+	return v.payload(id, Path{}, 1)
+}
+
+// payload converts the payload of a tag of the ID at the path, at the depth of nesting of the compound or list
+// holding it.
+func (v *conversion) payload(id uint8, path Path, depth int) (err error) {
+	switch id {
+	case tagByte:
+		err = v.copyNumbers(1, 1)
+	case tagShort:
+		err = v.copyNumbers(1, 2)
+	case tagInt, tagFloat:
+		err = v.copyNumbers(1, 4)
+	case tagLong, tagDouble:
+		err = v.copyNumbers(1, 8)
+	case tagByteArray, tagIntArray, tagLongArray:
+		err = v.array(id)
+	case tagString:
+		var s string
+		if s, err = v.readString(); err == nil {
+			err = v.writeString(s)
+		}
+	case tagList:
+		err = v.list(path, depth)
+	case tagCompound:
+		err = v.compound(path, depth)
+	default:
+		err = fmt.Errorf("unknown tag ID %v", id)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to convert %v: %w", displayPath(path), err)
+	}
+	return nil
+}
+
+// displayPath returns the text form of the path, or "root" for the empty path.
+func displayPath(path Path) string {
+	if len(path) == 0 {
+		return "root"
+	}
+	return path.String()
+}
+
+// compound converts the children of a compound up to and including its tagEnd, renaming them with RenameKey.
+func (v *conversion) compound(path Path, depth int) error {
+	if v.Limits.MaxDepth > 0 && depth+1 > v.Limits.MaxDepth {
+		return fmt.Errorf("depth exceeds limit of %v", v.Limits.MaxDepth)
+	}
+
+	for {
+		id, err := v.copyID()
+		if err != nil || id == tagEnd {
+			return err
+		}
+
+		name, err := v.readString()
+		if err != nil {
+			return fmt.Errorf("unable to convert child name: %w", err)
+		}
+		childPath := appendPath(path, name)
+		if v.RenameKey != nil {
+			name = v.RenameKey(path, name)
+		}
+		if err = v.writeString(name); err != nil {
+			return fmt.Errorf("unable to convert child name: %w", err)
+		}
+
+		if err = v.payload(id, childPath, depth+1); err != nil {
+			return err
+		}
+	}
+}
+
+// list converts the element ID, length and elements of a list.
+func (v *conversion) list(path Path, depth int) error {
+	if v.Limits.MaxDepth > 0 && depth+1 > v.Limits.MaxDepth {
+		return fmt.Errorf("depth exceeds limit of %v", v.Limits.MaxDepth)
+	}
+
+	id, err := v.copyID()
+	if err != nil {
+		return err
+	}
+	length, err := v.copyLength()
+	if err != nil {
+		return err
+	}
+
+	for i := range length {
+		if err = v.payload(id, appendPath(path, int(i)), depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// array converts the length and elements of a tagByteArray, tagIntArray or tagLongArray.
+func (v *conversion) array(id uint8) error {
+	length, err := v.copyLength()
+	if err != nil {
+		return err
+	}
+
+	size := map[uint8]int{tagByteArray: 1, tagIntArray: 4, tagLongArray: 8}[id]
+	for remaining := int(length); remaining > 0; {
+		count := min(remaining, conversionBufferSize/size)
+		if err = v.copyNumbers(count, size); err != nil {
+			return err
+		}
+		remaining -= count
+	}
+	return nil
+}
+
+// copyID copies a tag ID byte.
+func (v *conversion) copyID() (id uint8, err error) {
+	if err = v.copyNumbers(1, 1); err != nil {
+		return 0, fmt.Errorf("unable to convert tag ID: %w", err)
+	}
+	return v.buffer[0], nil
+}
+
+// copyLength copies the int32 length of a list or array, checking it against the limits.
+func (v *conversion) copyLength() (length int32, err error) {
+	if err = v.copyNumbers(1, 4); err != nil {
+		return 0, fmt.Errorf("unable to convert length: %w", err)
+	}
+	length = int32(v.to.Uint32(v.buffer)) // #nosec G115 -- lengths are stored as an int32
+	if err = checkLength(int(length), v.Limits.MaxArrayLength); err != nil {
+		return 0, fmt.Errorf("unable to convert length: %w", err)
+	}
+	return max(length, 0), nil
+}
+
+// copyNumbers copies count numbers of size bytes through the buffer, reversing the bytes of each if the byte orders
+// differ. The converted numbers are left at the start of the buffer.
+func (v *conversion) copyNumbers(count, size int) error {
+	b := v.buffer[:count*size]
+	if _, err := io.ReadFull(v.r, b); err != nil {
+		return err
+	}
+	if size > 1 && v.from != v.to {
+		for i := 0; i < len(b); i += size {
+			slices.Reverse(b[i : i+size])
+		}
+	}
+	_, err := v.w.Write(b)
+	return err
+}
+
+// readString reads a tag name or tagString payload, decoding it from the From edition's string encoding.
+func (v *conversion) readString() (s string, err error) {
+	b := v.buffer[:2]
+	if _, err = io.ReadFull(v.r, b); err != nil {
+		return "", err
+	}
+	length := int(v.from.Uint16(b))
+	if err = checkLength(length, v.Limits.MaxStringLength); err != nil {
+		return "", err
+	}
+
+	b = make([]byte, length)
+	if _, err = io.ReadFull(v.r, b); err != nil {
+		return "", err
+	}
+	if v.From == EditionJava {
+		return decodeMUTF8(b)
+	}
+	if !utf8.Valid(b) {
+		return "", fmt.Errorf("%q is not valid UTF-8", b)
+	}
+	return string(b), nil
+}
+
+// writeString writes a tag name or tagString payload in the To edition's string encoding.
+func (v *conversion) writeString(s string) error {
+	b := []byte(s)
+	if v.To == EditionJava {
+		b = encodeMUTF8(s)
+	}
+	if len(b) > 0xFFFF {
+		return fmt.Errorf("%v bytes is too long for a string", len(b))
+	}
+
+	length := make([]byte, 2)
+	v.to.PutUint16(length, uint16(len(b)))
+	if _, err := v.w.Write(length); err != nil {
+		return err
+	}
+	_, err := v.w.Write(b)
+	return err
+}
@@ -0,0 +1,98 @@
+package nbt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestConverter(t *testing.T) {
+	java := []byte{tagCompound, 0, 0,
+		tagShort, 0, 1, 's', 1, 2,
+		tagString, 0, 1, 't', 0, 9, 'a', 0xC0, 0x80, 0xED, 0xA0, 0xBD, 0xED, 0xB8, 0x80,
+		tagIntArray, 0, 1, 'i', 0, 0, 0, 2, 0, 0, 0, 1, 0, 0, 0, 2,
+		tagList, 0, 1, 'l', tagLong, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 3,
+		tagEnd}
+	bedrock := []byte{tagCompound, 0, 0,
+		tagShort, 1, 0, 's', 2, 1,
+		tagString, 1, 0, 't', 6, 0, 'a', 0, 0xF0, 0x9F, 0x98, 0x80,
+		tagIntArray, 1, 0, 'i', 2, 0, 0, 0, 1, 0, 0, 0, 2, 0, 0, 0,
+		tagList, 1, 0, 'l', tagLong, 1, 0, 0, 0, 3, 0, 0, 0, 0, 0, 0, 0,
+		tagEnd}
+	header := binary.LittleEndian.AppendUint32([]byte{10, 0, 0, 0}, uint32(len(bedrock)))
+	renamed := bytes.Replace(java, []byte{tagShort, 0, 1, 's'}, []byte{tagShort, 0, 1, 'S'}, 1)
+
+	successCases := []struct {
+		name      string
+		want      []byte
+		converter Converter
+		input     []byte
+	}{
+		{"Java to Bedrock", bedrock, Converter{From: EditionJava, To: EditionBedrock}, java},
+		{"Bedrock to Java", java, Converter{From: EditionBedrock, To: EditionJava}, bedrock},
+		{"Java to Java", java, Converter{}, java},
+		{"header added", append(header, bedrock...), Converter{To: EditionBedrock, OutputHeader: true,
+			HeaderVersion: 10}, java},
+		{"header removed", java, Converter{From: EditionBedrock, InputHeader: true}, append(header, bedrock...)},
+		{"header version kept", append(header, bedrock...), Converter{From: EditionBedrock, To: EditionBedrock,
+			InputHeader: true, OutputHeader: true}, append(header, bedrock...)},
+		{"keys renamed", renamed, Converter{RenameKey: func(path Path, name string) string {
+			if len(path) == 0 && name == "s" {
+				return "S"
+			}
+			return name
+		}}, java},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			var got bytes.Buffer
+			if err := successCase.converter.Convert(&got, bytes.NewReader(successCase.input)); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !bytes.Equal(got.Bytes(), successCase.want) {
+				t.Errorf("got % X, want % X", got.Bytes(), successCase.want)
+			}
+		})
+	}
+
+	t.Run("Test success case: output read as Bedrock", func(t *testing.T) {
+		var b bytes.Buffer
+		if err := (Converter{To: EditionBedrock}).Convert(&b, bytes.NewReader(java)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got, err := ReadTag(&b, BedrockEdition)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if s, _ := compoundChild(got, "t"); s.payload != "a\x00😀" {
+			t.Errorf("got %q, want %q", s.payload, "a\x00😀")
+		}
+	})
+
+	failureCases := []struct {
+		name      string
+		converter Converter
+		input     []byte
+	}{
+		{"unknown edition", Converter{To: Edition(2)}, java},
+		{"header on a Java document", Converter{InputHeader: true}, java},
+		{"truncated", Converter{To: EditionBedrock}, java[:len(java)-4]},
+		{"header length too long", Converter{From: EditionBedrock, InputHeader: true},
+			append(binary.LittleEndian.AppendUint32([]byte{10, 0, 0, 0}, uint32(len(bedrock)+1)), bedrock...)},
+		{"invalid Modified UTF-8", Converter{To: EditionBedrock},
+			[]byte{tagString, 0, 0, 0, 3, 0xED, 0xA0, 0xBD}},
+		{"invalid UTF-8", Converter{From: EditionBedrock}, []byte{tagString, 0, 0, 1, 0, 0xFF}},
+		{"unknown tag ID", Converter{}, []byte{tagCompound, 0, 0, 13, 0, 0}},
+		{"depth limit", Converter{Limits: Limits{MaxDepth: 1}}, java},
+		{"array length limit", Converter{Limits: Limits{MaxArrayLength: 1}}, java},
+		{"string length limit", Converter{Limits: Limits{MaxStringLength: 1}}, java},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := failureCase.converter.Convert(&b, bytes.NewReader(failureCase.input)); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
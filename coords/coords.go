@@ -0,0 +1,90 @@
+// Package coords converts between the block, chunk, section and region coordinates of a Minecraft world. Conversions
+// floor towards negative infinity, so negative coordinates land in the correct chunk or region rather than being
+// truncated towards zero, a common source of off-by-one bugs.
+package coords
+
+import "fmt"
+
+// Sizes of the coordinate spaces, in the units of the next smaller space.
+const (
+	// ChunkSize is the width of a chunk, and the width and height of a section, in blocks.
+	ChunkSize = 16
+	// RegionSize is the width of a region in chunks.
+	RegionSize = 32
+	// SectionVolume is the number of blocks in a 16x16x16 section.
+	SectionVolume = ChunkSize * ChunkSize * ChunkSize
+)
+
+// BlockToChunk returns the chunk coordinate holding a block coordinate, on the X or Z axis.
+func BlockToChunk(block int) int {
+	return block >> 4
+}
+
+// BlockToSection returns the section Y coordinate holding a block Y coordinate. Sections below Y 0 are negative.
+func BlockToSection(blockY int) int {
+	return blockY >> 4
+}
+
+// BlockToRegion returns the region coordinate holding a block coordinate, on the X or Z axis.
+func BlockToRegion(block int) int {
+	return block >> 9
+}
+
+// ChunkToRegion returns the region coordinate holding a chunk coordinate, on the X or Z axis.
+func ChunkToRegion(chunk int) int {
+	return chunk >> 5
+}
+
+// ChunkToBlock returns the lowest block coordinate of a chunk coordinate, on the X or Z axis.
+func ChunkToBlock(chunk int) int {
+	return chunk * ChunkSize
+}
+
+// RegionToChunk returns the lowest chunk coordinate of a region coordinate, on the X or Z axis.
+func RegionToChunk(region int) int {
+	return region * RegionSize
+}
+
+// BlockInChunk returns the offset of a block coordinate within its chunk or section, from 0 to 15.
+func BlockInChunk(block int) int {
+	return block & (ChunkSize - 1)
+}
+
+// ChunkInRegion returns the offset of a chunk coordinate within its region, from 0 to 31.
+func ChunkInRegion(chunk int) int {
+	return chunk & (RegionSize - 1)
+}
+
+// RegionIndex returns the index of a chunk in its region file's location and timestamp tables, from 0 to 1023.
+func RegionIndex(chunkX, chunkZ int) int {
+	return ChunkInRegion(chunkX) + ChunkInRegion(chunkZ)*RegionSize
+}
+
+// SectionIndex returns the index of a block within its section's block states, from 0 to 4095. Blocks are ordered
+// by Y, then Z, then X.
+func SectionIndex(blockX, blockY, blockZ int) int {
+	return BlockInChunk(blockY)<<8 | BlockInChunk(blockZ)<<4 | BlockInChunk(blockX)
+}
+
+// RegionFileName returns the name of the Anvil region file holding a region, such as "r.-1.0.mca".
+func RegionFileName(regionX, regionZ int) string {
+	return fmt.Sprintf("r.%v.%v.mca", regionX, regionZ)
+}
+
+// ExternalChunkFileName returns the name of the file holding a chunk too large for its region file, stored alongside
+// it, such as "c.-1.0.mcc".
+func ExternalChunkFileName(chunkX, chunkZ int) string {
+	return fmt.Sprintf("c.%v.%v.mcc", chunkX, chunkZ)
+}
+
+// ParseRegionFileName returns the region coordinates of an Anvil region file name, such as "r.-1.0.mca". The boolean is
+// false if the name is not a region file name.
+func ParseRegionFileName(name string) (regionX, regionZ int, ok bool) {
+	if _, err := fmt.Sscanf(name, "r.%d.%d.mca", &regionX, &regionZ); err != nil {
+		return 0, 0, false
+	}
+	if RegionFileName(regionX, regionZ) != name {
+		return 0, 0, false
+	}
+	return regionX, regionZ, true
+}
@@ -0,0 +1,141 @@
+package coords
+
+import "testing"
+
+func TestBlockConversions(t *testing.T) {
+	successCases := []struct {
+		name        string
+		block       int
+		wantChunk   int
+		wantRegion  int
+		wantInChunk int
+	}{
+		{"origin", 0, 0, 0, 0},
+		{"last block of first chunk", 15, 0, 0, 15},
+		{"first block of second chunk", 16, 1, 0, 0},
+		{"last block of first region", 511, 31, 0, 15},
+		{"first block of second region", 512, 32, 1, 0},
+		{"first negative block", -1, -1, -1, 15},
+		{"lowest block of first negative chunk", -16, -1, -1, 0},
+		{"highest block of second negative chunk", -17, -2, -1, 15},
+		{"lowest block of first negative region", -512, -32, -1, 0},
+		{"highest block of second negative region", -513, -33, -2, 15},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			if got := BlockToChunk(successCase.block); got != successCase.wantChunk {
+				t.Errorf("got chunk %v, want %v", got, successCase.wantChunk)
+			}
+			if got := BlockToRegion(successCase.block); got != successCase.wantRegion {
+				t.Errorf("got region %v, want %v", got, successCase.wantRegion)
+			}
+			if got := ChunkToRegion(BlockToChunk(successCase.block)); got != successCase.wantRegion {
+				t.Errorf("got region %v via chunk, want %v", got, successCase.wantRegion)
+			}
+			if got := BlockInChunk(successCase.block); got != successCase.wantInChunk {
+				t.Errorf("got offset %v, want %v", got, successCase.wantInChunk)
+			}
+			if got := ChunkToBlock(successCase.wantChunk) + successCase.wantInChunk; got != successCase.block {
+				t.Errorf("got block %v back, want %v", got, successCase.block)
+			}
+		})
+	}
+}
+
+func TestChunkConversions(t *testing.T) {
+	successCases := []struct {
+		name         string
+		chunkX       int
+		chunkZ       int
+		wantIndex    int
+		wantRegionXZ [2]int
+	}{
+		{"origin", 0, 0, 0, [2]int{0, 0}},
+		{"last chunk of first region", 31, 31, 1023, [2]int{0, 0}},
+		{"second row", 0, 1, 32, [2]int{0, 0}},
+		{"negative chunk", -1, -1, 1023, [2]int{-1, -1}},
+		{"mixed", -33, 40, 31 + 8*32, [2]int{-2, 1}},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			if got := RegionIndex(successCase.chunkX, successCase.chunkZ); got != successCase.wantIndex {
+				t.Errorf("got index %v, want %v", got, successCase.wantIndex)
+			}
+			gotRegion := [2]int{ChunkToRegion(successCase.chunkX), ChunkToRegion(successCase.chunkZ)}
+			if gotRegion != successCase.wantRegionXZ {
+				t.Errorf("got region %v, want %v", gotRegion, successCase.wantRegionXZ)
+			}
+			lowest := RegionToChunk(gotRegion[0])
+			if successCase.chunkX < lowest || successCase.chunkX >= lowest+RegionSize {
+				t.Errorf("got chunk %v outside region starting at %v", successCase.chunkX, lowest)
+			}
+		})
+	}
+}
+
+func TestSectionIndex(t *testing.T) {
+	successCases := []struct {
+		name        string
+		x, y, z     int
+		wantSection int
+		wantIndex   int
+	}{
+		{"origin", 0, 0, 0, 0, 0},
+		{"x axis", 1, 0, 0, 0, 1},
+		{"z axis", 0, 0, 1, 0, 16},
+		{"y axis", 0, 1, 0, 0, 256},
+		{"last block", 15, 15, 15, 0, 4095},
+		{"below zero", -1, -64, -1, -4, 255},
+		{"top of world", 31, 319, 17, 19, 3871},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			if got := BlockToSection(successCase.y); got != successCase.wantSection {
+				t.Errorf("got section %v, want %v", got, successCase.wantSection)
+			}
+			if got := SectionIndex(successCase.x, successCase.y, successCase.z); got != successCase.wantIndex {
+				t.Errorf("got index %v, want %v", got, successCase.wantIndex)
+			}
+		})
+	}
+}
+
+func TestRegionFileName(t *testing.T) {
+	got := RegionFileName(-1, 0)
+	if got != "r.-1.0.mca" {
+		t.Errorf("got %v, want r.-1.0.mca", got)
+	}
+}
+
+func TestExternalChunkFileName(t *testing.T) {
+	got := ExternalChunkFileName(-33, 5)
+	if got != "c.-33.5.mcc" {
+		t.Errorf("got %v, want c.-33.5.mcc", got)
+	}
+}
+
+func TestParseRegionFileName(t *testing.T) {
+	successCases := []struct {
+		name string
+		x, z int
+	}{
+		{"r.-1.0.mca", -1, 0},
+		{"r.12.-345.mca", 12, -345},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			x, z, ok := ParseRegionFileName(successCase.name)
+			if !ok || x != successCase.x || z != successCase.z {
+				t.Errorf("got %v,%v %v, want %v,%v", x, z, ok, successCase.x, successCase.z)
+			}
+		})
+	}
+
+	for _, name := range []string{"r.0.0.mca.tmp", "r.0.mca", "c.0.0.mcc", "r.+1.0.mca", "r.01.0.mca", ""} {
+		t.Run("Test failure case: "+name, func(t *testing.T) {
+			if _, _, ok := ParseRegionFileName(name); ok {
+				t.Errorf("got ok for %q, want not a region file name", name)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import "fmt"
+
+// CopyOnWrite holds a tagCompound tree with copy-on-write semantics. Clone is O(1) as clones share the whole tree, and
+// a modification copies only the compounds on the path to the change, leaving every other branch shared. This makes it
+// cheap to branch a large tree (such as world state) many times for what-if edits.
+//
+// Tags are addressed by the names of the compounds leading to them, lists are not traversed. The tree given to
+// NewCopyOnWrite, and any tag returned by Get or Tag, is shared and must not be modified directly.
+type CopyOnWrite struct {
+	root Tag
+}
+
+// NewCopyOnWrite takes ownership of a tagCompound tree, returning a CopyOnWrite holding it.
+func NewCopyOnWrite(t Tag) (c *CopyOnWrite, err error) {
+	if t.id != tagCompound {
+		return nil, fmt.Errorf("Unable to create copy-on-write tree: tag ID %v is not a tagCompound", t.id)
+	}
+
+	return &CopyOnWrite{root: t}, nil
+}
+
+// Clone returns a new CopyOnWrite sharing the tree. Modifying either the original or the clone does not affect the
+// other.
+func (c *CopyOnWrite) Clone() *CopyOnWrite {
+	return &CopyOnWrite{root: c.root}
+}
+
+// Tag returns the root of the tree. The returned tree is shared and must not be modified.
+func (c *CopyOnWrite) Tag() Tag {
+	return c.root
+}
+
+// Get returns the tag at the path of compound names. An empty path returns the root.
+func (c *CopyOnWrite) Get(path ...string) (t Tag, ok bool) {
+	t = c.root
+	for _, name := range path {
+		t, ok = compoundChild(t, name)
+		if !ok {
+			return Tag{}, false
+		}
+	}
+
+	return t, true
+}
+
+// Set adds t to the compound at the parents path, replacing any existing child of the same name. Each compound from
+// the root to the parent is copied, all other branches stay shared.
+func (c *CopyOnWrite) Set(t Tag, parents ...string) (err error) {
+	root, err := editPath(c.root, namePath(parents), compoundEdit(func(children []Tag) ([]Tag, error) {
+		return withChild(children, t), nil
+	}))
+	if err != nil {
+		return fmt.Errorf("Unable to set tag \"%v\": %w", t.name, err)
+	}
+
+	c.root = root
+	return nil
+}
+
+// Delete removes the named child from the compound at the parents path. Each compound from the root to the parent is
+// copied, all other branches stay shared.
+func (c *CopyOnWrite) Delete(name string, parents ...string) (err error) {
+	root, err := editPath(c.root, namePath(parents), compoundEdit(func(children []Tag) ([]Tag, error) {
+		children, ok := withoutChild(children, name)
+		if !ok {
+			return nil, fmt.Errorf("no child named \"%v\"", name)
+		}
+		return children, nil
+	}))
+	if err != nil {
+		return fmt.Errorf("Unable to delete tag \"%v\": %w", name, err)
+	}
+
+	c.root = root
+	return nil
+}
+
+// namePath returns a Path of compound child names.
+func namePath(names []string) Path {
+	path := make(Path, len(names))
+	for i, name := range names {
+		path[i] = name
+	}
+	return path
+}
+
+// compoundEdit wraps an edit of a tagCompound's children for use with editPath, rejecting tags that are not a
+// tagCompound.
+func compoundEdit(edit func([]Tag) ([]Tag, error)) func(Tag) (Tag, error) {
+	return func(parent Tag) (Tag, error) {
+		if parent.id != tagCompound {
+			return Tag{}, fmt.Errorf("tag \"%v\" is not a tagCompound", parent.name)
+		}
+		children, _ := parent.payload.([]Tag)
+		children, err := edit(children)
+		if err != nil {
+			return Tag{}, err
+		}
+		parent.payload = children
+		return parent, nil
+	}
+}
+
+// compoundChild returns the named child of a tagCompound. The boolean is false if t is not a tagCompound or has no
+// child with that name.
+func compoundChild(t Tag, name string) (Tag, bool) {
+	children, ok := t.payload.([]Tag)
+	if !ok {
+		return Tag{}, false
+	}
+
+	for _, child := range children {
+		if child.name == name {
+			return child, true
+		}
+	}
+
+	return Tag{}, false
+}
+
+// childPayload returns the payload of the named child of a tagCompound. The boolean is false if there is no child with
+// that name or its payload is not of type P.
+func childPayload[P any](t Tag, name string) (p P, ok bool) {
+	child, ok := compoundChild(t, name)
+	if !ok {
+		return p, false
+	}
+	p, ok = child.payload.(P)
+	return p, ok
+}
+
+// withChild returns a copy of the children with t replacing the child of the same name, or appended if there is none.
+// The given children are not modified.
+func withChild(children []Tag, t Tag) []Tag {
+	copied := make([]Tag, len(children), len(children)+1)
+	copy(copied, children)
+
+	for i := range copied {
+		if copied[i].name == t.name {
+			copied[i] = t
+			return copied
+		}
+	}
+
+	return append(copied, t)
+}
+
+// withoutChild returns a copy of the children without the named child. The boolean is false if there was no child with
+// that name. The given children are not modified.
+func withoutChild(children []Tag, name string) ([]Tag, bool) {
+	for i := range children {
+		if children[i].name == name {
+			copied := make([]Tag, 0, len(children)-1)
+			copied = append(copied, children[:i]...)
+			return append(copied, children[i+1:]...), true
+		}
+	}
+
+	return children, false
+}
@@ -0,0 +1,146 @@
+package nbt
+
+import "testing"
+
+func TestCopyOnWrite(t *testing.T) {
+	newLevel := func() Tag {
+		return Tag{id: tagCompound, payload: []Tag{
+			{id: tagCompound, name: "Data", payload: []Tag{
+				{id: tagString, name: "LevelName", payload: "My World"},
+				{id: tagCompound, name: "GameRules", payload: []Tag{
+					{id: tagString, name: "doDaylightCycle", payload: "true"},
+				}},
+			}},
+			{id: tagInt, name: "Version", payload: int32(1)},
+		}}
+	}
+
+	t.Run("Test success case: get nested tag", func(t *testing.T) {
+		c, _ := NewCopyOnWrite(newLevel())
+		got, ok := c.Get("Data", "LevelName")
+		if !ok {
+			t.Fatalf("got false, want true")
+		}
+		if got.payload != "My World" {
+			t.Errorf("got %v, want My World", got.payload)
+		}
+	})
+
+	t.Run("Test success case: set on clone leaves original untouched", func(t *testing.T) {
+		original, _ := NewCopyOnWrite(newLevel())
+		clone := original.Clone()
+
+		gotErr := clone.Set(Tag{id: tagString, name: "doDaylightCycle", payload: "false"}, "Data", "GameRules")
+		if gotErr != nil {
+			t.Fatalf("got %v, want nil", gotErr)
+		}
+
+		gotClone, _ := clone.Get("Data", "GameRules", "doDaylightCycle")
+		if gotClone.payload != "false" {
+			t.Errorf("got %v, want false", gotClone.payload)
+		}
+		gotOriginal, _ := original.Get("Data", "GameRules", "doDaylightCycle")
+		if gotOriginal.payload != "true" {
+			t.Errorf("got %v, want true", gotOriginal.payload)
+		}
+	})
+
+	t.Run("Test success case: untouched branches stay shared", func(t *testing.T) {
+		original, _ := NewCopyOnWrite(newLevel())
+		clone := original.Clone()
+		_ = clone.Set(Tag{id: tagInt, name: "Version", payload: int32(2)})
+
+		originalData, _ := original.Get("Data")
+		cloneData, _ := clone.Get("Data")
+		if &originalData.payload.([]Tag)[0] != &cloneData.payload.([]Tag)[0] {
+			t.Errorf("got copied branch, want shared branch")
+		}
+	})
+
+	t.Run("Test success case: set appends new child", func(t *testing.T) {
+		c, _ := NewCopyOnWrite(newLevel())
+		_ = c.Set(Tag{id: tagByte, name: "hardcore", payload: byte(1)}, "Data")
+		got, ok := c.Get("Data", "hardcore")
+		if !ok || got.payload != byte(1) {
+			t.Errorf("got %v %v, want 1 true", got.payload, ok)
+		}
+	})
+
+	t.Run("Test success case: delete on clone leaves original untouched", func(t *testing.T) {
+		original, _ := NewCopyOnWrite(newLevel())
+		clone := original.Clone()
+
+		gotErr := clone.Delete("LevelName", "Data")
+		if gotErr != nil {
+			t.Fatalf("got %v, want nil", gotErr)
+		}
+
+		if _, ok := clone.Get("Data", "LevelName"); ok {
+			t.Errorf("got true, want false")
+		}
+		if _, ok := original.Get("Data", "LevelName"); !ok {
+			t.Errorf("got false, want true")
+		}
+	})
+
+	t.Run("Test failure case: new from non-compound", func(t *testing.T) {
+		_, gotErr := NewCopyOnWrite(Tag{id: tagInt, payload: int32(1)})
+		if gotErr == nil {
+			t.Errorf("got nil, want non-nil")
+		}
+	})
+
+	failureCases := []struct {
+		name    string
+		parents []string
+	}{
+		{"missing parent", []string{"Missing"}},
+		{"parent is not a compound", []string{"Version"}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: set with "+failureCase.name, func(t *testing.T) {
+			c, _ := NewCopyOnWrite(newLevel())
+			gotErr := c.Set(Tag{id: tagInt, name: "x", payload: int32(1)}, failureCase.parents...)
+			if gotErr == nil {
+				t.Errorf("got nil, want non-nil")
+			}
+		})
+		t.Run("Test failure case: delete with "+failureCase.name, func(t *testing.T) {
+			c, _ := NewCopyOnWrite(newLevel())
+			gotErr := c.Delete("x", failureCase.parents...)
+			if gotErr == nil {
+				t.Errorf("got nil, want non-nil")
+			}
+		})
+	}
+
+	t.Run("Test failure case: delete missing child", func(t *testing.T) {
+		c, _ := NewCopyOnWrite(newLevel())
+		gotErr := c.Delete("Missing", "Data")
+		if gotErr == nil {
+			t.Errorf("got nil, want non-nil")
+		}
+	})
+
+	t.Run("Test failure case: get missing path", func(t *testing.T) {
+		c, _ := NewCopyOnWrite(newLevel())
+		_, ok := c.Get("Data", "Missing")
+		if ok {
+			t.Errorf("got true, want false")
+		}
+	})
+}
+
+func TestChildPayload(t *testing.T) {
+	compound := Tag{id: tagCompound, payload: []Tag{{id: tagInt, name: "a", payload: int32(1)}}}
+
+	if got, ok := childPayload[int32](compound, "a"); !ok || got != 1 {
+		t.Errorf("got %v %v, want 1 true", got, ok)
+	}
+	if _, ok := childPayload[string](compound, "a"); ok {
+		t.Errorf("got ok for the wrong type, want false")
+	}
+	if _, ok := childPayload[int32](compound, "b"); ok {
+		t.Errorf("got ok for a missing child, want false")
+	}
+}
@@ -0,0 +1,90 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// WithReadTimeout bounds how long each read of the input may take, so a peer sending a tag slowly cannot stall the
+// reader mid-tag. See Options.ReadTimeout.
+func WithReadTimeout(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.ReadTimeout = timeout
+	}
+}
+
+// WithTimeout bounds how long reading the whole tag may take. See Options.Timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.Timeout = timeout
+	}
+}
+
+// readDeadliner is an input whose reads can be interrupted at a deadline, such as a net.Conn or os.File pipe.
+type readDeadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+// deadlineReader enforces the ReadTimeout and Timeout of the Options on the input of ReadTag. Reads of a readDeadliner
+// are interrupted by setting its read deadline. Other inputs cannot be interrupted, so a read that overruns fails once
+// it returns.
+type deadlineReader struct {
+	r           io.Reader
+	readTimeout time.Duration
+	// deadline is when the whole tag must be read by, or the zero time for no deadline.
+	deadline time.Time
+	// size is the number of bytes remaining in the input when wrapped, or -1 if it is not known, so declared lengths
+	// are still checked against it.
+	size int64
+}
+
+// newDeadlineReader wraps r in a deadlineReader, starting the Timeout now.
+func newDeadlineReader(r io.Reader, o Options) *deadlineReader {
+	d := &deadlineReader{r: r, readTimeout: o.ReadTimeout, size: remainingSize(r)}
+	d.restart(o.Timeout)
+	return d
+}
+
+// restart starts the timeout for reading a whole tag now, as the Decoder does before each tag it reads.
+func (d *deadlineReader) restart(timeout time.Duration) {
+	d.deadline = time.Time{}
+	if timeout > 0 {
+		d.deadline = time.Now().Add(timeout)
+	}
+}
+
+// Read reads from the input, returning an error wrapping os.ErrDeadlineExceeded if a deadline passes.
+func (d *deadlineReader) Read(p []byte) (n int, err error) {
+	start := time.Now()
+	if !d.deadline.IsZero() && !start.Before(d.deadline) {
+		return 0, fmt.Errorf("timeout reading tag: %w", os.ErrDeadlineExceeded)
+	}
+	deadline := d.deadline
+	if d.readTimeout > 0 && (deadline.IsZero() || start.Add(d.readTimeout).Before(deadline)) {
+		deadline = start.Add(d.readTimeout)
+	}
+
+	if conn, ok := d.r.(readDeadliner); ok {
+		if err = conn.SetReadDeadline(deadline); err != nil {
+			return 0, fmt.Errorf("unable to set read deadline: %w", err)
+		}
+		return d.r.Read(p)
+	}
+
+	n, err = d.r.Read(p)
+	if err == nil && !deadline.IsZero() && time.Now().After(deadline) {
+		// The bytes read are discarded, as io.ReadFull ignores an error once it has read enough.
+		return 0, fmt.Errorf("timeout reading tag: %w", os.ErrDeadlineExceeded)
+	}
+	return n, err
+}
+
+// clear removes the read deadline set on a readDeadliner input, so later reads by the caller are not cut short.
+func (d *deadlineReader) clear() {
+	if conn, ok := d.r.(readDeadliner); ok {
+		_ = conn.SetReadDeadline(time.Time{})
+	}
+}
@@ -0,0 +1,212 @@
+package nbt
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// slowReader sleeps before each read of r.
+type slowReader struct {
+	r     io.Reader
+	delay time.Duration
+}
+
+func (s slowReader) Read(p []byte) (int, error) {
+	time.Sleep(s.delay)
+	return s.r.Read(p)
+}
+
+func TestReadTagDeadlines(t *testing.T) {
+	input := []byte{tagCompound, 0, 0, tagByte, 0, 1, 'a', 1, tagEnd}
+
+	t.Run("Test success case: connection sends in time", func(t *testing.T) {
+		client, server := net.Pipe()
+		defer client.Close()
+		defer server.Close()
+		go func() { _, _ = server.Write(input) }()
+
+		if _, err := ReadTag(client, WithReadTimeout(time.Second), WithTimeout(time.Second)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		// The deadline is cleared, so a later read waits for data rather than failing.
+		go func() { _, _ = server.Write([]byte{1}) }()
+		if _, err := client.Read(make([]byte, 1)); err != nil {
+			t.Errorf("got %v reading after the tag, want no deadline", err)
+		}
+	})
+
+	t.Run("Test success case: slow reader without timeouts", func(t *testing.T) {
+		if _, err := ReadTag(slowReader{bytes.NewReader(input), time.Millisecond}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Test failure case: declared length exceeds input with a timeout", func(t *testing.T) {
+		oversized := []byte{tagByteArray, 0, 0, 0x7F, 0xFF, 0xFF, 0xFF, 1}
+		_, err := ReadTag(bytes.NewReader(oversized), WithReadTimeout(time.Second), WithTimeout(time.Second))
+		if err == nil || !strings.Contains(err.Error(), "exceeds the") {
+			t.Errorf("got %v, want declared length error", err)
+		}
+	})
+
+	failureCases := []struct {
+		name string
+		opts []Option
+	}{
+		{"read timeout", []Option{WithReadTimeout(20 * time.Millisecond)}},
+		{"overall timeout", []Option{WithTimeout(20 * time.Millisecond)}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: stalled connection, "+failureCase.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer client.Close()
+			defer server.Close()
+			go func() { _, _ = server.Write(input[:4]) }()
+
+			_, err := ReadTag(client, failureCase.opts...)
+			if !errors.Is(err, os.ErrDeadlineExceeded) {
+				t.Errorf("got %v, want os.ErrDeadlineExceeded", err)
+			}
+		})
+
+		t.Run("Test failure case: slow reader, "+failureCase.name, func(t *testing.T) {
+			_, err := ReadTag(slowReader{bytes.NewReader(input), 30 * time.Millisecond}, failureCase.opts...)
+			if !errors.Is(err, os.ErrDeadlineExceeded) {
+				t.Errorf("got %v, want os.ErrDeadlineExceeded", err)
+			}
+		})
+	}
+}
+
+func TestDecoderDeadlines(t *testing.T) {
+	input := []byte{tagCompound, 0, 0, tagByte, 0, 1, 'a', 1, tagEnd}
+
+	t.Run("Test success case: timeout restarts for each tag", func(t *testing.T) {
+		client, server := net.Pipe()
+		defer client.Close()
+		defer server.Close()
+		go func() {
+			_, _ = server.Write(input)
+			time.Sleep(60 * time.Millisecond)
+			_, _ = server.Write(input)
+		}()
+
+		d := NewDecoder(client, WithTimeout(40*time.Millisecond))
+		for i := range 2 {
+			// The second tag arrives after the timeout of the first has passed, but soon after the second Decode.
+			if i == 1 {
+				time.Sleep(50 * time.Millisecond)
+			}
+			var tag Tag
+			if err := d.Decode(&tag); err != nil {
+				t.Fatalf("tag %v: Unexpected error: %v", i, err)
+			}
+		}
+
+		// The deadline is cleared, so a later read waits for data rather than failing.
+		go func() { _, _ = server.Write([]byte{1}) }()
+		if _, err := client.Read(make([]byte, 1)); err != nil {
+			t.Errorf("got %v reading after the tag, want no deadline", err)
+		}
+	})
+
+	failureCases := []struct {
+		name string
+		opts []Option
+		sent []byte
+	}{
+		{"read timeout", []Option{WithReadTimeout(20 * time.Millisecond)}, input[:4]},
+		{"overall timeout", []Option{WithTimeout(20 * time.Millisecond)}, input[:4]},
+		{"stalled gzip header", []Option{WithReadTimeout(20 * time.Millisecond), WithCompression(CompressionGzip)},
+			[]byte{0x1F}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: stalled connection, "+failureCase.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer client.Close()
+			defer server.Close()
+			go func() { _, _ = server.Write(failureCase.sent) }()
+
+			var tag Tag
+			err := NewDecoder(client, failureCase.opts...).Decode(&tag)
+			if !errors.Is(err, os.ErrDeadlineExceeded) {
+				t.Errorf("got %v, want os.ErrDeadlineExceeded", err)
+			}
+		})
+	}
+}
+
+func TestDecoderTokenDeadlines(t *testing.T) {
+	input := []byte{tagCompound, 0, 0, tagByte, 0, 1, 'a', 1, tagEnd}
+
+	// stalled returns a decoder reading from a connection that sends the bytes then stalls.
+	stalled := func(t *testing.T, sent []byte, opts ...Option) *Decoder {
+		client, server := net.Pipe()
+		t.Cleanup(func() {
+			_ = client.Close()
+			_ = server.Close()
+		})
+		go func() { _, _ = server.Write(sent) }()
+		return NewDecoder(client, opts...)
+	}
+
+	t.Run("Test success case: tokens read in time", func(t *testing.T) {
+		d := stalled(t, input, WithReadTimeout(time.Second), WithTimeout(time.Second))
+		for range 4 {
+			if _, err := d.Token(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+	})
+
+	failureCases := []struct {
+		name string
+		read func(d *Decoder) error
+	}{
+		{"Token", func(d *Decoder) error {
+			for {
+				if _, err := d.Token(); err != nil {
+					return err
+				}
+			}
+		}},
+		{"Skip", func(d *Decoder) error {
+			if _, err := d.Token(); err != nil {
+				return err
+			}
+			return d.Skip()
+		}},
+		{"DecodePath", func(d *Decoder) error {
+			_, _, err := d.DecodePath("a")
+			return err
+		}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: stalled connection, "+failureCase.name, func(t *testing.T) {
+			err := failureCase.read(stalled(t, input[:4], WithReadTimeout(20*time.Millisecond)))
+			if !errors.Is(err, os.ErrDeadlineExceeded) {
+				t.Errorf("got %v, want os.ErrDeadlineExceeded", err)
+			}
+		})
+	}
+
+	t.Run("Test failure case: tokens of a tag read past the overall timeout", func(t *testing.T) {
+		d := stalled(t, input, WithTimeout(20*time.Millisecond))
+		if _, err := d.Token(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		time.Sleep(30 * time.Millisecond)
+		// The CompoundStart reads no input, the header of the child does.
+		_, _ = d.Token()
+		if _, err := d.Token(); !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Errorf("got %v, want os.ErrDeadlineExceeded", err)
+		}
+	})
+}
@@ -0,0 +1,141 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"fmt"
+	"math"
+)
+
+// CompareOptions configure how Equal, Diff and GeneratePatch compare tag trees. The zero value compares exactly.
+type CompareOptions struct {
+	// FloatTolerance is the largest difference between two tagFloat or two tagDouble payloads that are still equal, so
+	// trees reserialised by tools that round doubles compare equal. Payloads of the same bits are always equal, and NaN
+	// and ±Inf equal only payloads of the same bits.
+	FloatTolerance float64
+}
+
+// CompareOption sets a field of the CompareOptions.
+type CompareOption func(*CompareOptions)
+
+// WithFloatTolerance sets the largest difference between two float or double payloads that are still equal.
+func WithFloatTolerance(tolerance float64) CompareOption {
+	return func(c *CompareOptions) {
+		c.FloatTolerance = tolerance
+	}
+}
+
+// newCompareOptions returns the default CompareOptions with each CompareOption applied in order.
+func newCompareOptions(opts []CompareOption) (c CompareOptions) {
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// floatsWithin reports whether two floats have the same bits, or differ by no more than a FloatTolerance set.
+func (c CompareOptions) floatsWithin(a, b float64, sameBits bool) bool {
+	return sameBits || c.FloatTolerance > 0 && math.Abs(a-b) <= c.FloatTolerance
+}
+
+// Equal reports whether two tag trees are deeply equal: the same tag IDs, names and payloads throughout, and the same
+// element type for each tagList, so an empty list of strings does not equal an empty list of ints. Compound children
+// are matched by name regardless of order, and floating point payloads are compared by bit pattern, so NaN equals NaN
+// of the same bits and -0.0 does not equal 0.0, unless the options give a FloatTolerance.
+func Equal(a, b *Tag, opts ...CompareOption) bool {
+	return a.name == b.name && tagsEqual(*a, *b, newCompareOptions(opts))
+}
+
+// tagsEqual reports whether two tags have the same IDs and payloads throughout, and their lists the same element IDs.
+func tagsEqual(a, b Tag, c CompareOptions) bool {
+	if a.id != b.id {
+		return false
+	}
+
+	switch a.id {
+	case tagCompound:
+		aChildren, _ := a.payload.([]Tag)
+		bChildren, _ := b.payload.([]Tag)
+		if len(aChildren) != len(bChildren) {
+			return false
+		}
+		for _, aChild := range aChildren {
+			bChild, ok := compoundChild(b, aChild.name)
+			if !ok || !tagsEqual(aChild, bChild, c) {
+				return false
+			}
+		}
+		return true
+	case tagList:
+		aElements, _ := a.payload.([]any)
+		bElements, _ := b.payload.([]any)
+		elementID := a.ElementID()
+		if elementID != b.ElementID() || len(aElements) != len(bElements) {
+			return false
+		}
+		for i := range aElements {
+			aElement, bElement := Tag{id: elementID, payload: aElements[i]}, Tag{id: elementID, payload: bElements[i]}
+			if !tagsEqual(aElement, bElement, c) {
+				return false
+			}
+		}
+		return true
+	default:
+		return payloadsWithin(a.payload, b.payload, c)
+	}
+}
+
+// ChangeKind is the kind of difference recorded by a Change.
+type ChangeKind string
+
+// ChangeKind values.
+const (
+	// ChangeAdded is a compound child or list element present only in the new tree.
+	ChangeAdded ChangeKind = "added"
+	// ChangeRemoved is a compound child or list element present only in the old tree.
+	ChangeRemoved ChangeKind = "removed"
+	// ChangeModified is a tag whose type or payload differs between the trees.
+	ChangeModified ChangeKind = "modified"
+)
+
+// Change is a difference between two tag trees at Path. Old is the tag in the old tree, for removed and modified tags,
+// and New the tag in the new tree, for added and modified tags.
+type Change struct {
+	Kind ChangeKind
+	Path Path
+	Old  Tag
+	New  Tag
+}
+
+// String returns the change as its path, kind and SNBT values, such as "Data.Pos[1]: modified 2d -> 3d".
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("%v: added %v", c.Path, c.New)
+	case ChangeRemoved:
+		return fmt.Sprintf("%v: removed %v", c.Path, c.Old)
+	default:
+		return fmt.Sprintf("%v: %v %v -> %v", c.Path, c.Kind, c.Old, c.New)
+	}
+}
+
+// Diff returns every difference between the old tree a and the new tree b, nil if they are equal. Compound children
+// are matched by name and list elements by index. A tag whose type or name changes, or a list whose element type
+// changes, is modified whole rather than compared below. The changes are in the order of GeneratePatch, so the paths of
+// removed tags address the old tree and those of added tags the new. The options are those of Equal.
+func Diff(a, b *Tag, opts ...CompareOption) (changes []Change) {
+	for _, operation := range GeneratePatch(*a, *b, opts...) {
+		change := Change{Path: operation.Path}
+		switch operation.Op {
+		case PatchAdd:
+			change.Kind, change.New = ChangeAdded, operation.Value
+		case PatchRemove:
+			change.Kind = ChangeRemoved
+			change.Old, _ = lookup(*a, operation.Path)
+		default:
+			change.Kind, change.New = ChangeModified, operation.Value
+			change.Old, _ = lookup(*a, operation.Path)
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
@@ -0,0 +1,118 @@
+package nbt
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	a, _ := NewCompound("", NewString("id", "minecraft:cow"), NewDouble("Health", 10))
+	reordered, _ := NewCompound("", NewDouble("Health", 10), NewString("id", "minecraft:cow"))
+	renamed, _ := NewCompound("Entity", NewString("id", "minecraft:cow"), NewDouble("Health", 10))
+	changed, _ := NewCompound("", NewString("id", "minecraft:cow"), NewDouble("Health", 9))
+	strings, _ := NewList("Tags", IDString)
+	ints, _ := NewList("Tags", IDInt)
+	withStrings, _ := NewCompound("", strings)
+	withInts, _ := NewCompound("", ints)
+
+	successCases := []struct {
+		name string
+		want bool
+		a, b Tag
+	}{
+		{"same tree", true, a, a.Clone()},
+		{"children in another order", true, a, reordered},
+		{"NaN of the same bits", true, NewDouble("", math.NaN()), NewDouble("", math.NaN())},
+		{"other name", false, a, renamed},
+		{"other payload", false, a, changed},
+		{"other ID", false, NewInt("", 1), NewLong("", 1)},
+		{"negative zero", false, NewDouble("", math.Copysign(0, -1)), NewDouble("", 0)},
+		{"empty lists of the same element type", true, withStrings, withStrings.Clone()},
+		{"empty lists of other element types", false, withStrings, withInts},
+		{"empty list and undeclared empty list", false, strings, Tag{id: tagList, name: "Tags"}},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			if got := Equal(&successCase.a, &successCase.b); got != successCase.want {
+				t.Errorf("got %v, want %v", got, successCase.want)
+			}
+		})
+	}
+
+	pos, _ := NewList("Pos", IDDouble, NewDouble("", 0.30000000000000004), NewDouble("", 64))
+	rounded, _ := NewList("Pos", IDDouble, NewDouble("", 0.3), NewDouble("", 64))
+	toleranceCases := []struct {
+		name      string
+		want      bool
+		a, b      Tag
+		tolerance float64
+	}{
+		{"doubles within tolerance", true, pos, rounded, 1e-9},
+		{"doubles outside tolerance", false, pos, rounded, 1e-20},
+		{"floats within tolerance", true, NewFloat("", 1.5), NewFloat("", 1.5001), 1e-3},
+		{"zero tolerance is exact", false, NewDouble("", math.Copysign(0, -1)), NewDouble("", 0), 0},
+		{"NaN of other bits", false, NewDouble("", math.NaN()), NewDouble("", math.Float64frombits(0x7FF8000000000002)),
+			1},
+		{"infinity and a large double", false, NewDouble("", math.Inf(1)), NewDouble("", math.MaxFloat64), 1},
+		{"integers are exact", false, NewInt("", 1), NewInt("", 2), 5},
+	}
+	for _, toleranceCase := range toleranceCases {
+		t.Run("Test success case: "+toleranceCase.name, func(t *testing.T) {
+			got := Equal(&toleranceCase.a, &toleranceCase.b, WithFloatTolerance(toleranceCase.tolerance))
+			if got != toleranceCase.want {
+				t.Errorf("got %v, want %v", got, toleranceCase.want)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	oldPos, _ := NewList("Pos", IDDouble, NewDouble("", 1), NewDouble("", 2))
+	newPos, _ := NewList("Pos", IDDouble, NewDouble("", 1), NewDouble("", 3), NewDouble("", 4))
+	a, _ := NewCompound("", NewString("id", "minecraft:cow"), NewFloat("Health", 10), oldPos)
+	b, _ := NewCompound("", NewString("id", "minecraft:cow"), NewInt("Health", 10), newPos, NewByte("Sheared", 1))
+
+	t.Run("Test success case: added, removed and modified", func(t *testing.T) {
+		var got []string
+		for _, change := range Diff(&a, &b) {
+			got = append(got, change.String())
+		}
+		want := []string{"Health: modified 10f -> 10", "Pos[1]: modified 2d -> 3d", "Pos[2]: added 4d",
+			"Sheared: added 1b"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Test success case: removed with old value", func(t *testing.T) {
+		got := Diff(&b, &a)
+		want := Change{Kind: ChangeRemoved, Path: Path{"Pos", 2}, Old: Tag{id: tagDouble, payload: 4.0}}
+		if len(got) != 4 || !reflect.DeepEqual(got[3], want) {
+			t.Errorf("got %v, want %v last", got, want)
+		}
+	})
+
+	t.Run("Test success case: empty list of another element type", func(t *testing.T) {
+		strings, _ := NewList("Tags", IDString)
+		ints, _ := NewList("Tags", IDInt)
+		got := Diff(&strings, &ints)
+		if len(got) != 1 || got[0].Kind != ChangeModified || got[0].New.ElementID() != tagInt {
+			t.Errorf("got %v, want the list modified to a list of tagInt", got)
+		}
+	})
+
+	t.Run("Test success case: doubles within tolerance", func(t *testing.T) {
+		rounded, _ := NewList("Pos", IDDouble, NewDouble("", 1.0000001), NewDouble("", 3))
+		got := Diff(&newPos, &rounded, WithFloatTolerance(1e-6))
+		if len(got) != 1 || got[0].String() != "[2]: removed 4d" {
+			t.Errorf("got %v, want only [2] removed", got)
+		}
+	})
+
+	t.Run("Test success case: equal trees", func(t *testing.T) {
+		if got := Diff(&a, &a); got != nil {
+			t.Errorf("got %v, want nil", got)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+)
+
+// ReadJava reads a tag in the conventions of Java edition: big-endian with Modified UTF-8 strings. The JavaEdition
+// preset is applied after the options, so they cannot mix in the conventions of another edition.
+func ReadJava(buffer io.Reader, opts ...Option) (t Tag, err error) {
+	return ReadTag(buffer, append(slices.Clone(opts), JavaEdition)...)
+}
+
+// WriteJava writes a tag in the conventions of Java edition, applying the JavaEdition preset after the options.
+func WriteJava(buffer io.Writer, t Tag, opts ...Option) (err error) {
+	return WriteTag(buffer, t, append(slices.Clone(opts), JavaEdition)...)
+}
+
+// ReadBedrock reads a tag in the conventions of Bedrock edition: little-endian with UTF-8 strings and no header. The
+// BedrockEdition preset is applied after the options, so they cannot mix in the conventions of another edition.
+func ReadBedrock(buffer io.Reader, opts ...Option) (t Tag, err error) {
+	return ReadTag(buffer, append(slices.Clone(opts), BedrockEdition)...)
+}
+
+// WriteBedrock writes a tag in the conventions of Bedrock edition, applying the BedrockEdition preset after the
+// options.
+func WriteBedrock(buffer io.Writer, t Tag, opts ...Option) (err error) {
+	return WriteTag(buffer, t, append(slices.Clone(opts), BedrockEdition)...)
+}
+
+// ReadBedrockLevel reads a Bedrock edition level.dat document: the header of the storage version and the length of the
+// tag, then the tag itself, read as by ReadBedrock. The length must match the tag exactly.
+func ReadBedrockLevel(buffer io.Reader, opts ...Option) (t Tag, version int32, err error) {
+	header := make([]byte, bedrockLevelHeaderSize)
+	_, err = io.ReadFull(buffer, header)
+	if err != nil {
+		return Tag{}, 0, fmt.Errorf("Unable to read Bedrock level header: %w", err)
+	}
+	version = int32(binary.LittleEndian.Uint32(header)) // #nosec G115 -- the version is stored as an int32
+
+	document := &io.LimitedReader{R: buffer, N: int64(binary.LittleEndian.Uint32(header[4:]))}
+	t, err = ReadBedrock(document, opts...)
+	if err != nil {
+		return Tag{}, 0, fmt.Errorf("Unable to read Bedrock level: %w", err)
+	}
+	if document.N != 0 {
+		return Tag{}, 0, fmt.Errorf("Unable to read Bedrock level: header length is %v bytes longer than the tag",
+			document.N)
+	}
+
+	return t, version, nil
+}
+
+// WriteBedrockLevel writes a Bedrock edition level.dat document: the header of the storage version and the length of
+// the tag, then the tag itself, written as by WriteBedrock.
+func WriteBedrockLevel(buffer io.Writer, t Tag, version int32, opts ...Option) (err error) {
+	var document bytes.Buffer
+	err = WriteBedrock(&document, t, opts...)
+	if err != nil {
+		return fmt.Errorf("Unable to write Bedrock level: %w", err)
+	}
+	if int64(document.Len()) > math.MaxUint32 {
+		return fmt.Errorf("Unable to write Bedrock level: %v bytes is too long for the header", document.Len())
+	}
+
+	header := binary.LittleEndian.AppendUint32(nil, uint32(version))          // #nosec G115 -- stored as an int32
+	header = binary.LittleEndian.AppendUint32(header, uint32(document.Len())) // #nosec G115 -- checked above
+	_, err = buffer.Write(append(header, document.Bytes()...))
+	if err != nil {
+		return fmt.Errorf("Unable to write Bedrock level: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package nbt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestEditionReadWrite(t *testing.T) {
+	tag, _ := NewCompound("", NewString("a", "\x00😀"), NewInt("b", 1))
+
+	successCases := []struct {
+		name  string
+		write func(*bytes.Buffer, Tag) error
+		read  func(*bytes.Buffer) (Tag, error)
+		order binary.ByteOrder
+		mutf8 bool
+	}{
+		{"Java", func(b *bytes.Buffer, t Tag) error { return WriteJava(b, t) },
+			func(b *bytes.Buffer) (Tag, error) { return ReadJava(b) }, binary.BigEndian, true},
+		{"Java overrides mixed options", func(b *bytes.Buffer, t Tag) error { return WriteJava(b, t, BedrockEdition) },
+			func(b *bytes.Buffer) (Tag, error) { return ReadJava(b, BedrockEdition) }, binary.BigEndian, true},
+		{"Bedrock", func(b *bytes.Buffer, t Tag) error { return WriteBedrock(b, t) },
+			func(b *bytes.Buffer) (Tag, error) { return ReadBedrock(b) }, binary.LittleEndian, false},
+		{"Bedrock overrides mixed options", func(b *bytes.Buffer, t Tag) error { return WriteBedrock(b, t, JavaEdition) },
+			func(b *bytes.Buffer) (Tag, error) { return ReadBedrock(b, JavaEdition) }, binary.LittleEndian, false},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			if err := successCase.write(&buffer, tag); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			// The root compound has an empty name, so its first child's name length follows the root tag ID and
+			// name length.
+			if got := successCase.order.Uint16(buffer.Bytes()[4:]); got != 1 {
+				t.Errorf("got child name length %v, want 1 in the edition's byte order", got)
+			}
+			if got := bytes.Contains(buffer.Bytes(), []byte{0xC0, 0x80}); got != successCase.mutf8 {
+				t.Errorf("got Modified UTF-8 NUL %v, want %v", got, successCase.mutf8)
+			}
+
+			got, err := successCase.read(&buffer)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !payloadsEqual(got.payload, tag.payload) {
+				t.Errorf("got %v, want %v", got, tag)
+			}
+		})
+	}
+
+	t.Run("Test failure case: Java read of Bedrock tag", func(t *testing.T) {
+		var buffer bytes.Buffer
+		if err := WriteBedrock(&buffer, tag); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := ReadJava(&buffer); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+}
+
+func TestBedrockLevel(t *testing.T) {
+	tag, _ := NewCompound("", NewString("LevelName", "hi"))
+
+	t.Run("Test success case: round trip", func(t *testing.T) {
+		var buffer bytes.Buffer
+		if err := WriteBedrockLevel(&buffer, tag, 10); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := binary.LittleEndian.Uint32(buffer.Bytes()[4:]); int(got) != buffer.Len()-bedrockLevelHeaderSize {
+			t.Errorf("got header length %v, want %v", got, buffer.Len()-bedrockLevelHeaderSize)
+		}
+
+		got, version, err := ReadBedrockLevel(&buffer)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if version != 10 || !payloadsEqual(got.payload, tag.payload) {
+			t.Errorf("got %v %v, want 10 %v", version, got, tag)
+		}
+	})
+
+	var document bytes.Buffer
+	if err := WriteBedrock(&document, tag); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	header := func(length int) []byte {
+		return binary.LittleEndian.AppendUint32(binary.LittleEndian.AppendUint32(nil, 10), uint32(length))
+	}
+
+	failureCases := []struct {
+		name  string
+		input []byte
+	}{
+		{"short header", header(document.Len())[:4]},
+		{"header length too short", append(header(document.Len()-1), document.Bytes()...)},
+		{"header length too long", append(header(document.Len()+1), append(document.Bytes(), 0)...)},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			if _, _, err := ReadBedrockLevel(bytes.NewReader(failureCase.input)); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// EffectIDsDataVersion is the DataVersion of Java edition 1.20.2, from which status effects are stored with
+// namespaced IDs and snake case names ("active_effects", "custom_potion_effects") rather than numeric IDs and
+// "ActiveEffects" and "CustomPotionEffects".
+const EffectIDsDataVersion = 3578
+
+// effectIDs are the namespaced status effect IDs without namespace, indexed by their numeric ID before 1.20.2.
+var effectIDs = []string{"", "speed", "slowness", "haste", "mining_fatigue", "strength", "instant_health",
+	"instant_damage", "jump_boost", "nausea", "regeneration", "resistance", "fire_resistance", "water_breathing",
+	"invisibility", "blindness", "night_vision", "hunger", "weakness", "poison", "wither", "health_boost", "absorption",
+	"saturation", "glowing", "levitation", "luck", "unluck", "slow_falling", "conduit_power", "dolphins_grace",
+	"bad_omen", "hero_of_the_village", "darkness"}
+
+// Effect is a status effect, active on an entity or held by a potion.
+type Effect struct {
+	// ID is the namespaced effect ID, such as "minecraft:speed".
+	ID string
+	// Amplifier is the level of the effect less one.
+	Amplifier byte
+	// Duration is the number of ticks remaining, or -1 for an infinite effect.
+	Duration      int32
+	Ambient       bool
+	ShowParticles bool
+	ShowIcon      bool
+}
+
+// PotionContents are the contents of a potion, splash potion, lingering potion or tipped arrow item.
+type PotionContents struct {
+	// Potion is the namespaced potion ID, such as "minecraft:swiftness". It is empty for only custom effects.
+	Potion string
+	// CustomColor is the RGB colour of the liquid, used only if HasCustomColor is true.
+	CustomColor    int32
+	HasCustomColor bool
+	// Effects are the custom effects added to those of the potion.
+	Effects []Effect
+}
+
+// ActiveEffects reads the status effects active on an entity in any layout.
+func ActiveEffects(entity Tag) (effects []Effect, err error) {
+	if entity.id != tagCompound {
+		return nil, fmt.Errorf("Unable to read active effects: tag ID %v is not a tagCompound", entity.id)
+	}
+
+	effects, err = effectsFromTag(entity, "active_effects", "ActiveEffects")
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read active effects: %w", err)
+	}
+	return effects, nil
+}
+
+// SetActiveEffects returns a copy of the entity compound with the status effects active in the layout of the
+// DataVersion, see EffectIDsDataVersion. Effects of either layout are replaced, all other children are kept. The
+// given entity is not modified.
+func SetActiveEffects(entity Tag, effects []Effect, dataVersion int32) (Tag, error) {
+	if entity.id != tagCompound {
+		return Tag{}, fmt.Errorf("Unable to set active effects: tag ID %v is not a tagCompound", entity.id)
+	}
+
+	children, _ := entity.payload.([]Tag)
+	children, err := withEffects(children, effects, dataVersion, "active_effects", "ActiveEffects")
+	if err != nil {
+		return Tag{}, fmt.Errorf("Unable to set active effects: %w", err)
+	}
+	entity.payload = children
+	return entity, nil
+}
+
+// PotionContentsFromTag reads the potion contents of an item stack compound of any DataVersion.
+func PotionContentsFromTag(item Tag) (p PotionContents, err error) {
+	if item.id != tagCompound {
+		return PotionContents{}, fmt.Errorf("Unable to read potion contents: tag ID %v is not a tagCompound", item.id)
+	}
+
+	if components, ok := compoundChild(item, "components"); ok {
+		contents, _ := compoundChild(components, "minecraft:potion_contents")
+		p.Potion, _ = childPayload[string](contents, "potion")
+		p.CustomColor, p.HasCustomColor = childPayload[int32](contents, "custom_color")
+		p.Effects, err = effectsFromTag(contents, "custom_effects", "")
+	} else {
+		tag, _ := compoundChild(item, "tag")
+		p.Potion, _ = childPayload[string](tag, "Potion")
+		p.CustomColor, p.HasCustomColor = childPayload[int32](tag, "CustomPotionColor")
+		p.Effects, err = effectsFromTag(tag, "custom_potion_effects", "CustomPotionEffects")
+	}
+	if err != nil {
+		return PotionContents{}, fmt.Errorf("Unable to read potion contents: %w", err)
+	}
+	return p, nil
+}
+
+// SetPotionContents returns a copy of the item stack compound holding the potion contents in the layout of the
+// DataVersion, see ItemComponentsDataVersion and EffectIDsDataVersion. Potion contents of every layout are replaced,
+// all other children are kept. The given item is not modified.
+func SetPotionContents(item Tag, p PotionContents, dataVersion int32) (Tag, error) {
+	if item.id != tagCompound {
+		return Tag{}, fmt.Errorf("Unable to set potion contents: tag ID %v is not a tagCompound", item.id)
+	}
+
+	var err error
+	if dataVersion >= ItemComponentsDataVersion {
+		var contents []Tag
+		if p.Potion != "" {
+			contents = append(contents, Tag{id: tagString, name: "potion", payload: p.Potion})
+		}
+		if p.HasCustomColor {
+			contents = append(contents, Tag{id: tagInt, name: "custom_color", payload: p.CustomColor})
+		}
+		contents, err = withEffects(contents, p.Effects, dataVersion, "custom_effects", "")
+		if err == nil {
+			item, err = editChildren(item, "components", func(children []Tag) ([]Tag, error) {
+				return withChild(children, Tag{id: tagCompound, name: "minecraft:potion_contents", payload: contents}), nil
+			})
+		}
+	} else {
+		item, err = editChildren(item, "tag", func(children []Tag) ([]Tag, error) {
+			for _, name := range []string{"Potion", "CustomPotionColor"} {
+				children, _ = withoutChild(children, name)
+			}
+			if p.Potion != "" {
+				children = withChild(children, Tag{id: tagString, name: "Potion", payload: p.Potion})
+			}
+			if p.HasCustomColor {
+				children = withChild(children, Tag{id: tagInt, name: "CustomPotionColor", payload: p.CustomColor})
+			}
+			return withEffects(children, p.Effects, dataVersion, "custom_potion_effects", "CustomPotionEffects")
+		})
+	}
+	if err != nil {
+		return Tag{}, fmt.Errorf("Unable to set potion contents: %w", err)
+	}
+	return item, nil
+}
+
+// editChildren returns a copy of the compound with edit applied to the children of its named tagCompound child,
+// which is added if missing.
+func editChildren(t Tag, name string, edit func([]Tag) ([]Tag, error)) (Tag, error) {
+	child, ok := compoundChild(t, name)
+	if ok && child.id != tagCompound {
+		return Tag{}, fmt.Errorf("\"%v\" is tag ID %v, not a tagCompound", name, child.id)
+	}
+
+	children, _ := child.payload.([]Tag)
+	children, err := edit(slices.Clone(children))
+	if err != nil {
+		return Tag{}, err
+	}
+	parent, _ := t.payload.([]Tag)
+	t.payload = withChild(parent, Tag{id: tagCompound, name: name, payload: children})
+	return t, nil
+}
+
+// effectsFromTag reads a list of effects from the compound, named modern from 1.20.2 or legacy before.
+func effectsFromTag(t Tag, modern, legacy string) (effects []Effect, err error) {
+	list, isModern := compoundChild(t, modern)
+	if !isModern && legacy != "" {
+		list, _ = compoundChild(t, legacy)
+	}
+
+	elements, _ := list.payload.([]any)
+	for i, element := range elements {
+		effect, err := effectFromTag(Tag{id: tagCompound, payload: element}, isModern)
+		if err != nil {
+			return nil, fmt.Errorf("effect %v: %w", i, err)
+		}
+		effects = append(effects, effect)
+	}
+	return effects, nil
+}
+
+// effectFromTag reads an effect compound in the modern layout or the legacy layout with a numeric ID.
+func effectFromTag(t Tag, modern bool) (e Effect, err error) {
+	names := []string{"id", "amplifier", "duration", "ambient", "show_particles", "show_icon"}
+	if modern {
+		var ok bool
+		e.ID, ok = childPayload[string](t, "id")
+		if !ok {
+			return Effect{}, fmt.Errorf("no tagString named \"id\"")
+		}
+	} else {
+		names = []string{"Id", "Amplifier", "Duration", "Ambient", "ShowParticles", "ShowIcon"}
+		id, ok := childPayload[int32](t, "Id")
+		if b, isByte := childPayload[byte](t, "Id"); isByte {
+			id, ok = int32(b), true
+		}
+		if !ok || id <= 0 || int(id) >= len(effectIDs) {
+			return Effect{}, fmt.Errorf("unknown effect \"Id\" %v", id)
+		}
+		e.ID = "minecraft:" + effectIDs[id]
+	}
+
+	e.Amplifier, _ = childPayload[byte](t, names[1])
+	e.Duration, _ = childPayload[int32](t, names[2])
+	flags := []*bool{&e.Ambient, &e.ShowParticles, &e.ShowIcon}
+	for i, flag := range flags {
+		b, ok := childPayload[byte](t, names[3+i])
+		*flag = b != 0 || (!ok && i > 0)
+	}
+	return e, nil
+}
+
+// withEffects returns a copy of the children with the effects as a list named modern from 1.20.2 or legacy before,
+// replacing any effects of either name.
+func withEffects(children []Tag, effects []Effect, dataVersion int32, modern, legacy string) ([]Tag, error) {
+	children, _ = withoutChild(children, modern)
+	children, _ = withoutChild(children, legacy)
+	if len(effects) == 0 {
+		return children, nil
+	}
+
+	isModern := dataVersion >= EffectIDsDataVersion || legacy == ""
+	names := []string{"id", "amplifier", "duration", "ambient", "show_particles", "show_icon"}
+	if !isModern {
+		names = []string{"Id", "Amplifier", "Duration", "Ambient", "ShowParticles", "ShowIcon"}
+	}
+
+	elements := make([]any, len(effects))
+	for i, e := range effects {
+		id := Tag{id: tagString, name: names[0], payload: e.ID}
+		if !isModern {
+			numeric := slices.Index(effectIDs, trimNamespace(e.ID))
+			if numeric <= 0 {
+				return nil, fmt.Errorf("effect %v \"%v\" has no numeric ID", i, e.ID)
+			}
+			id = Tag{id: tagInt, name: names[0], payload: int32(numeric)} // #nosec G115 -- a small index
+		}
+		elements[i] = []Tag{
+			id,
+			{id: tagByte, name: names[1], payload: e.Amplifier},
+			{id: tagInt, name: names[2], payload: e.Duration},
+			{id: tagByte, name: names[3], payload: boolByte(e.Ambient)},
+			{id: tagByte, name: names[4], payload: boolByte(e.ShowParticles)},
+			{id: tagByte, name: names[5], payload: boolByte(e.ShowIcon)},
+		}
+	}
+
+	name := modern
+	if !isModern {
+		name = legacy
+	}
+	return append(slices.Clip(children), Tag{id: tagList, elementID: tagCompound, name: name, payload: elements}), nil
+}
+
+// trimNamespace returns the ID without the "minecraft:" namespace.
+func trimNamespace(id string) string {
+	return strings.TrimPrefix(id, "minecraft:")
+}
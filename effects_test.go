@@ -0,0 +1,150 @@
+package nbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestActiveEffects(t *testing.T) {
+	effects := []Effect{
+		{ID: "minecraft:speed", Amplifier: 1, Duration: 600, ShowParticles: true, ShowIcon: true},
+		{ID: "minecraft:night_vision", Duration: -1, Ambient: true},
+	}
+	entity := Tag{id: tagCompound, payload: []Tag{{id: tagString, name: "id", payload: "minecraft:zombie"}}}
+
+	tests := []struct {
+		name        string
+		effects     []Effect
+		dataVersion int32
+		wantList    string
+		wantErr     bool
+	}{
+		{
+			name:        "Test success case: namespaced IDs",
+			effects:     effects,
+			dataVersion: EffectIDsDataVersion,
+			wantList:    "active_effects",
+		},
+		{
+			name:        "Test success case: numeric IDs",
+			effects:     effects,
+			dataVersion: EffectIDsDataVersion - 1,
+			wantList:    "ActiveEffects",
+		},
+		{
+			name:        "Test failure case: no numeric ID",
+			effects:     []Effect{{ID: "minecraft:infested"}},
+			dataVersion: EffectIDsDataVersion - 1,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SetActiveEffects(entity, tt.effects, tt.dataVersion)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if _, ok := compoundChild(got, tt.wantList); !ok {
+				t.Errorf("got %+v, want a list named %v", got, tt.wantList)
+			}
+
+			reread, err := ActiveEffects(got)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(reread, tt.effects) {
+				t.Errorf("got %+v, want %+v", reread, tt.effects)
+			}
+		})
+	}
+
+	t.Run("Test success case: byte ID and default flags", func(t *testing.T) {
+		legacy := Tag{id: tagCompound, payload: []Tag{{id: tagList, elementID: tagCompound, name: "ActiveEffects",
+			payload: []any{[]Tag{{id: tagByte, name: "Id", payload: byte(19)}, {id: tagInt, name: "Duration",
+				payload: int32(100)}}}}}}
+		want := []Effect{{ID: "minecraft:poison", Duration: 100, ShowParticles: true, ShowIcon: true}}
+		if got, err := ActiveEffects(legacy); err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v %v, want %+v", got, err, want)
+		}
+	})
+
+	t.Run("Test failure case: unknown numeric ID", func(t *testing.T) {
+		legacy := Tag{id: tagCompound, payload: []Tag{{id: tagList, elementID: tagCompound, name: "ActiveEffects",
+			payload: []any{[]Tag{{id: tagInt, name: "Id", payload: int32(99)}}}}}}
+		if _, err := ActiveEffects(legacy); err == nil {
+			t.Errorf("got no error, want error")
+		}
+	})
+}
+
+func TestPotionContents(t *testing.T) {
+	contents := PotionContents{
+		Potion:         "minecraft:swiftness",
+		CustomColor:    0xFF0000,
+		HasCustomColor: true,
+		Effects:        []Effect{{ID: "minecraft:luck", Duration: 200, ShowParticles: true, ShowIcon: true}},
+	}
+	potion := NewItemStack("minecraft:potion", 1).WithName("Quick")
+
+	tests := []struct {
+		name        string
+		dataVersion int32
+		wantPath    Path
+	}{
+		{
+			name:        "Test success case: components",
+			dataVersion: ItemComponentsDataVersion,
+			wantPath:    Path{"components", "minecraft:potion_contents", "custom_effects"},
+		},
+		{
+			name:        "Test success case: tag with namespaced IDs",
+			dataVersion: EffectIDsDataVersion,
+			wantPath:    Path{"tag", "custom_potion_effects"},
+		},
+		{
+			name:        "Test success case: tag with numeric IDs",
+			dataVersion: EffectIDsDataVersion - 1,
+			wantPath:    Path{"tag", "CustomPotionEffects"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := potion.Tag(tt.dataVersion)
+			got, err := SetPotionContents(item, contents, tt.dataVersion)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if _, err := lookup(got, tt.wantPath); err != nil {
+				t.Errorf("got %+v, want %v: %v", got, tt.wantPath, err)
+			}
+
+			reread, err := PotionContentsFromTag(got)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(reread, contents) {
+				t.Errorf("got %+v, want %+v", reread, contents)
+			}
+			if stack, _ := ItemStackFromTag(got); stack.Name != potion.Name {
+				t.Errorf("got name %v, want %v kept", stack.Name, potion.Name)
+			}
+			if _, ok := compoundChild(item, "components"); ok && tt.dataVersion < ItemComponentsDataVersion {
+				t.Errorf("got components added to the original item")
+			}
+		})
+	}
+
+	t.Run("Test failure case: not a compound", func(t *testing.T) {
+		if _, err := SetPotionContents(Tag{id: tagList}, contents, ItemComponentsDataVersion); err == nil {
+			t.Errorf("got no error, want error")
+		}
+		if _, err := PotionContentsFromTag(Tag{id: tagList}); err == nil {
+			t.Errorf("got no error, want error")
+		}
+	})
+}
@@ -0,0 +1,110 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// entitiesParent returns the path of the compound holding the Entities list of a chunk: the root of an entity chunk
+// (entities region files since Java edition 1.17), or the Level compound of a chunk before.
+func entitiesParent(chunk Tag) Path {
+	if _, ok := compoundChild(chunk, "Level"); ok {
+		return Path{"Level"}
+	}
+	return Path{}
+}
+
+// Entities returns the entity compounds of an entity chunk, or of a chunk before Java edition 1.17. A chunk without an
+// Entities list has no entities.
+func Entities(chunk Tag) (entities []Tag, err error) {
+	parent, err := lookup(chunk, entitiesParent(chunk))
+	if err != nil || parent.id != tagCompound {
+		return nil, fmt.Errorf("Unable to read entities: chunk is not a tagCompound")
+	}
+	list, ok := compoundChild(parent, "Entities")
+	if !ok {
+		return nil, nil
+	}
+	elements, ok := list.payload.([]any)
+	if list.id != tagList || (!ok && list.payload != nil) {
+		return nil, fmt.Errorf("Unable to read entities: \"Entities\" is tag ID %v, not a tagList", list.id)
+	}
+
+	for i, element := range elements {
+		children, ok := element.([]Tag)
+		if !ok && element != nil {
+			return nil, fmt.Errorf("Unable to read entity %v: not a tagCompound", i)
+		}
+		entities = append(entities, Tag{id: tagCompound, payload: children})
+	}
+	return entities, nil
+}
+
+// EditEntities returns a copy of the chunk with edit applied to each of its entities in turn. The edit returns the
+// entity to keep, modified or not, and false to remove the entity. The given chunk is not modified.
+func EditEntities(chunk Tag, edit func(entity Tag) (Tag, bool, error)) (Tag, error) {
+	entities, err := Entities(chunk)
+	if err != nil {
+		return Tag{}, err
+	}
+
+	var elements []any
+	for i, entity := range entities {
+		edited, keep, err := edit(entity)
+		if err != nil {
+			return Tag{}, fmt.Errorf("Unable to edit entity %v: %w", i, err)
+		}
+		if !keep {
+			continue
+		}
+		if edited.id != tagCompound {
+			return Tag{}, fmt.Errorf("Unable to edit entity %v: tag ID %v is not a tagCompound", i, edited.id)
+		}
+		elements = append(elements, edited.payload)
+	}
+
+	chunk, err = editPath(chunk, entitiesParent(chunk), compoundEdit(func(children []Tag) ([]Tag, error) {
+		return withChild(children, Tag{id: tagList, elementID: tagCompound, name: "Entities", payload: elements}), nil
+	}))
+	if err != nil {
+		return Tag{}, fmt.Errorf("Unable to edit entities: %w", err)
+	}
+	return chunk, nil
+}
+
+// EntityUUID returns the UUID of an entity, stored as a tagIntArray "UUID" since Java edition 1.16, or as the tagLong
+// pair UUIDMost and UUIDLeast before. The boolean is false if the entity has neither.
+func EntityUUID(entity Tag) (uuid [16]byte, ok bool) {
+	if ints, ok := childPayload[[]int32](entity, "UUID"); ok && len(ints) == 4 {
+		for i, v := range ints {
+			binary.BigEndian.PutUint32(uuid[i*4:], uint32(v)) // #nosec G115 -- reinterpreting the bits
+		}
+		return uuid, true
+	}
+
+	most, mostOK := childPayload[int64](entity, "UUIDMost")
+	least, leastOK := childPayload[int64](entity, "UUIDLeast")
+	if !mostOK || !leastOK {
+		return uuid, false
+	}
+	binary.BigEndian.PutUint64(uuid[:8], uint64(most))  // #nosec G115 -- reinterpreting the bits
+	binary.BigEndian.PutUint64(uuid[8:], uint64(least)) // #nosec G115 -- reinterpreting the bits
+	return uuid, true
+}
+
+// MatchEntityID returns a filter matching entities of the namespaced entity ID, such as "minecraft:zombie".
+func MatchEntityID(id string) func(entity Tag) bool {
+	return func(entity Tag) bool {
+		entityID, _ := childPayload[string](entity, "id")
+		return entityID == id
+	}
+}
+
+// MatchEntityUUID returns a filter matching the entity with the UUID.
+func MatchEntityUUID(uuid [16]byte) func(entity Tag) bool {
+	return func(entity Tag) bool {
+		entityUUID, ok := EntityUUID(entity)
+		return ok && entityUUID == uuid
+	}
+}
@@ -0,0 +1,100 @@
+package nbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEntities(t *testing.T) {
+	zombie := []Tag{
+		{id: tagString, name: "id", payload: "minecraft:zombie"},
+		{id: tagIntArray, name: "UUID", payload: []int32{1, 2, 3, -1}},
+	}
+	cow := []Tag{
+		{id: tagString, name: "id", payload: "minecraft:cow"},
+		{id: tagLong, name: "UUIDMost", payload: int64(0x0000000100000002)},
+		{id: tagLong, name: "UUIDLeast", payload: int64(0x00000003FFFFFFFF)},
+	}
+	uuid := [16]byte{0, 0, 0, 1, 0, 0, 0, 2, 0, 0, 0, 3, 0xFF, 0xFF, 0xFF, 0xFF}
+
+	tests := []struct {
+		name  string
+		chunk Tag
+	}{
+		{
+			name: "Test success case: entity chunk",
+			chunk: Tag{id: tagCompound, payload: []Tag{
+				{id: tagIntArray, name: "Position", payload: []int32{0, 0}},
+				{id: tagList, elementID: tagCompound, name: "Entities", payload: []any{zombie, cow}},
+			}},
+		},
+		{
+			name: "Test success case: legacy chunk",
+			chunk: Tag{id: tagCompound, payload: []Tag{{id: tagCompound, name: "Level", payload: []Tag{
+				{id: tagList, elementID: tagCompound, name: "Entities", payload: []any{zombie, cow}},
+			}}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entities, err := Entities(tt.chunk)
+			if err != nil || len(entities) != 2 {
+				t.Fatalf("got %+v %v, want 2 entities", entities, err)
+			}
+			if !MatchEntityID("minecraft:zombie")(entities[0]) || MatchEntityID("minecraft:zombie")(entities[1]) {
+				t.Errorf("got the zombie filter wrong")
+			}
+			if !MatchEntityUUID(uuid)(entities[0]) || !MatchEntityUUID(uuid)(entities[1]) {
+				t.Errorf("got the UUID filter wrong, want both UUID forms to match")
+			}
+
+			edited, err := EditEntities(tt.chunk, func(entity Tag) (Tag, bool, error) {
+				if MatchEntityID("minecraft:zombie")(entity) {
+					return Tag{}, false, nil
+				}
+				entity.payload = withChild(entity.payload.([]Tag), Tag{id: tagByte, name: "NoAI", payload: byte(1)})
+				return entity, true, nil
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			remaining, _ := Entities(edited)
+			if len(remaining) != 1 || len(remaining[0].payload.([]Tag)) != 4 {
+				t.Errorf("got %+v, want the cow with NoAI", remaining)
+			}
+			if original, _ := Entities(tt.chunk); !reflect.DeepEqual(original, entities) {
+				t.Errorf("got %+v in the original, want it unchanged", original)
+			}
+		})
+	}
+
+	t.Run("Test success case: no entities", func(t *testing.T) {
+		if entities, err := Entities(Tag{id: tagCompound}); err != nil || entities != nil {
+			t.Errorf("got %+v %v, want none", entities, err)
+		}
+		if _, ok := EntityUUID(Tag{id: tagCompound}); ok {
+			t.Errorf("got a UUID for an entity without one")
+		}
+	})
+
+	failureCases := []struct {
+		name  string
+		input Tag
+	}{
+		{"not a compound", Tag{id: tagList}},
+		{"not a list", Tag{id: tagCompound, payload: []Tag{{id: tagInt, name: "Entities", payload: int32(1)}}}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			if _, err := Entities(failureCase.input); err == nil {
+				t.Errorf("got no error, want error")
+			}
+			if _, err := EditEntities(failureCase.input, func(entity Tag) (Tag, bool, error) {
+				return entity, true, nil
+			}); err == nil {
+				t.Errorf("got no error from EditEntities, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// ReadFile reads a single tag from the file at path, decompressing it first if it is gzip or zlib compressed. A
+// compression set by the options overrides the detected one, and the path names the source of tags unless the options
+// name one.
+func ReadFile(path string, opts ...Option) (t Tag, err error) {
+	file, err := os.Open(path) // #nosec G304 -- the caller chooses which files to read
+	if err != nil {
+		return Tag{}, fmt.Errorf("Unable to read file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return Tag{}, fmt.Errorf("Unable to read file: %w", err)
+	}
+
+	// Limiting the reader to the file size lets uncompressed files be checked against the bytes remaining.
+	buffered := bufio.NewReader(file)
+	magic, err := buffered.Peek(detectHeaderSize)
+	if err == nil || err == io.EOF {
+		opts = append([]Option{WithCompression(DetectCompression(magic))}, opts...)
+	}
+
+	opts = append(slices.Clip(opts), func(o *Options) {
+		if o.SourceFile == "" {
+			o.SourceFile = path
+		}
+	})
+
+	t, err = ReadTag(&io.LimitedReader{R: buffered, N: info.Size()}, opts...)
+	if err != nil {
+		return Tag{}, fmt.Errorf("Unable to read file %v: %w", path, err)
+	}
+	return t, nil
+}
+
+// WriteFile writes the tag to the file at path with the options, as WriteTag does. The tag is written to a temporary
+// file in the same directory, synced, then renamed over path, so a crash leaves either the old or the new file whole,
+// never a partial one. Without a WithCompression option, the compression of an existing file is kept, and a new file
+// is gzip compressed, as Java edition writes level.dat and player data. An existing file's permissions are kept.
+func WriteFile(path string, t Tag, opts ...Option) (err error) {
+	mode := os.FileMode(0o644)
+	compression := CompressionGzip
+	if info, statErr := os.Stat(path); statErr == nil {
+		mode = info.Mode().Perm()
+		compression, err = fileCompression(path)
+		if err != nil {
+			return fmt.Errorf("Unable to write file %v: %w", path, err)
+		}
+	}
+	opts = append([]Option{WithCompression(compression)}, opts...)
+
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("Unable to write file %v: %w", path, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = temp.Close()
+			_ = os.Remove(temp.Name())
+		}
+	}()
+
+	buffered := bufio.NewWriter(temp)
+	err = WriteTag(buffered, t, opts...)
+	if err != nil {
+		return fmt.Errorf("Unable to write file %v: %w", path, err)
+	}
+	err = buffered.Flush()
+	if err == nil {
+		err = temp.Chmod(mode)
+	}
+	if err == nil {
+		err = temp.Sync()
+	}
+	if err == nil {
+		err = temp.Close()
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), path)
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to write file %v: %w", path, err)
+	}
+	return nil
+}
+
+// fileCompression returns the compression of the file at path, detected from its first bytes.
+func fileCompression(path string) (Compression, error) {
+	file, err := os.Open(path) // #nosec G304 -- the caller chooses which file to write
+	if err != nil {
+		return CompressionNone, err
+	}
+	defer file.Close()
+
+	header := make([]byte, detectHeaderSize)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return CompressionNone, err
+	}
+	return DetectCompression(header[:n]), nil
+}
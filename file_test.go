@@ -0,0 +1,93 @@
+package nbt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	tag, _ := NewCompound("", NewInt("a", 1))
+	for _, compression := range []Compression{CompressionNone, CompressionGzip, CompressionZlib} {
+		t.Run("Test success case: "+compression.String(), func(t *testing.T) {
+			path := filepath.Join(dir, compression.String()+".dat")
+			if err := WriteFile(path, tag, WithCompression(compression)); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			got, err := ReadFile(path)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !payloadsEqual(got.payload, tag.payload) {
+				t.Errorf("got %v, want %v", got, tag)
+			}
+		})
+	}
+
+	t.Run("Test failure case: missing file", func(t *testing.T) {
+		if _, err := ReadFile(filepath.Join(dir, "missing.dat")); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+}
+
+func TestWriteFile(t *testing.T) {
+	tag, _ := NewCompound("", NewInt("a", 1))
+
+	t.Run("Test success case: new file is gzip compressed", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "level.dat")
+		if err := WriteFile(path, tag); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got, _ := fileCompression(path); got != CompressionGzip {
+			t.Errorf("got %v, want gzip", got)
+		}
+	})
+
+	t.Run("Test success case: existing compression and permissions kept", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "raw.dat")
+		if err := WriteFile(path, tag, WithCompression(CompressionZlib)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := os.Chmod(path, 0o600); err != nil {
+			t.Fatalf("Unable to set test file mode: %v", err)
+		}
+		if err := WriteFile(path, tag); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if got, _ := fileCompression(path); got != CompressionZlib {
+			t.Errorf("got %v, want zlib", got)
+		}
+		if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+			t.Errorf("got mode %v, want 0600", info.Mode().Perm())
+		}
+		if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+			t.Errorf("got %v entries, want only the file", len(entries))
+		}
+	})
+
+	t.Run("Test failure case: invalid tag leaves the file whole", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "level.dat")
+		if err := WriteFile(path, tag); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := WriteFile(path, Tag{id: tagInt, payload: "not an int"}); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+
+		if got, err := ReadFile(path); err != nil || !payloadsEqual(got.payload, tag.payload) {
+			t.Errorf("got %v %v, want the original tag", got, err)
+		}
+		if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+			t.Errorf("got %v entries, want the temporary file removed", len(entries))
+		}
+	})
+
+	t.Run("Test failure case: missing directory", func(t *testing.T) {
+		if err := WriteFile(filepath.Join(t.TempDir(), "missing", "level.dat"), tag); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+}
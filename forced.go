@@ -0,0 +1,81 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+)
+
+// forcedName is the path of the forced chunks file within a Java edition world directory.
+var forcedName = filepath.Join("data", "chunks.dat")
+
+// ChunkPos is the position of a chunk in chunk coordinates.
+type ChunkPos struct {
+	X, Z int32
+}
+
+// Pack returns the position packed into a long as the game stores it, X in the low 32 bits and Z in the high 32 bits.
+func (p ChunkPos) Pack() int64 {
+	return int64(uint32(p.X)) | int64(p.Z)<<32 // #nosec G115 -- reinterpreting the bits of X
+}
+
+// UnpackChunkPos returns the position packed into a long by ChunkPos.Pack.
+func UnpackChunkPos(packed int64) ChunkPos {
+	return ChunkPos{X: int32(packed), Z: int32(packed >> 32)} // #nosec G115 -- splitting the long into halves
+}
+
+// ForcedChunks reads the force-loaded chunk positions from the root compound of a chunks.dat file, held in the Forced
+// tagLongArray of its data compound. A file without a Forced array has no forced chunks.
+func ForcedChunks(t Tag) (chunks []ChunkPos, err error) {
+	data, ok := compoundChild(t, "data")
+	if t.id != tagCompound || !ok || data.id != tagCompound {
+		return nil, fmt.Errorf("Unable to read forced chunks: no tagCompound named \"data\"")
+	}
+
+	forced, ok := compoundChild(data, "Forced")
+	packed, isArray := forced.payload.([]int64)
+	if ok && (forced.id != tagLongArray || !isArray && forced.payload != nil) {
+		return nil, fmt.Errorf("Unable to read forced chunks: \"Forced\" is tag ID %v, not a tagLongArray", forced.id)
+	}
+
+	for _, p := range packed {
+		chunks = append(chunks, UnpackChunkPos(p))
+	}
+	return chunks, nil
+}
+
+// SetForcedChunks returns a copy of the chunks.dat root compound with the chunk positions as its Forced array,
+// creating the data compound if missing. All other children are kept. The given tree is not modified.
+func SetForcedChunks(t Tag, chunks []ChunkPos) (Tag, error) {
+	if t.id != tagCompound {
+		return Tag{}, fmt.Errorf("Unable to set forced chunks: tag ID %v is not a tagCompound", t.id)
+	}
+
+	var packed []int64
+	for _, chunk := range chunks {
+		packed = append(packed, chunk.Pack())
+	}
+
+	t, err := editChildren(t, "data", func(children []Tag) ([]Tag, error) {
+		return withChild(children, Tag{id: tagLongArray, name: "Forced", payload: packed}), nil
+	})
+	if err != nil {
+		return Tag{}, fmt.Errorf("Unable to set forced chunks: %w", err)
+	}
+	return t, nil
+}
+
+// LoadForcedChunks decodes the data/chunks.dat file of the world directory, which is gzip compressed, returning the
+// force-loaded chunk positions. A world without the file has no forced chunks. The options configure decoding.
+func LoadForcedChunks(dir string, opts ...Option) (chunks []ChunkPos, err error) {
+	t, err := ReadFile(filepath.Join(dir, forcedName), opts...)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to load %v: %w", forcedName, err)
+	}
+	return ForcedChunks(t)
+}
@@ -0,0 +1,119 @@
+package nbt
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestChunkPosPack(t *testing.T) {
+	tests := []struct {
+		name  string
+		input ChunkPos
+		want  int64
+	}{
+		{name: "Test success case: origin", input: ChunkPos{}, want: 0},
+		{name: "Test success case: positive", input: ChunkPos{X: 1, Z: 2}, want: 2<<32 | 1},
+		{name: "Test success case: negative x", input: ChunkPos{X: -1, Z: 0}, want: 0xFFFFFFFF},
+		{name: "Test success case: negative z", input: ChunkPos{X: 3, Z: -1}, want: -1<<32 | 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.input.Pack(); got != tt.want {
+				t.Errorf("got %#x, want %#x", got, tt.want)
+			}
+			if got := UnpackChunkPos(tt.want); got != tt.input {
+				t.Errorf("got %+v, want %+v", got, tt.input)
+			}
+		})
+	}
+}
+
+func TestForcedChunks(t *testing.T) {
+	chunks := []ChunkPos{{X: 1, Z: 2}, {X: -5, Z: -7}}
+	root := Tag{id: tagCompound, payload: []Tag{{id: tagInt, name: "DataVersion", payload: int32(3465)}}}
+
+	set, err := SetForcedChunks(root, chunks)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	got, err := ForcedChunks(set)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, chunks) {
+		t.Errorf("got %+v, want %+v", got, chunks)
+	}
+	if _, ok := compoundChild(root, "data"); ok {
+		t.Errorf("got data added to the original")
+	}
+
+	tests := []struct {
+		name    string
+		input   Tag
+		wantErr bool
+	}{
+		{
+			name:  "Test success case: no forced chunks",
+			input: Tag{id: tagCompound, payload: []Tag{{id: tagCompound, name: "data"}}},
+		},
+		{
+			name:    "Test failure case: no data",
+			input:   root,
+			wantErr: true,
+		},
+		{
+			name: "Test failure case: forced not a long array",
+			input: Tag{id: tagCompound, payload: []Tag{{id: tagCompound, name: "data", payload: []Tag{
+				{id: tagIntArray, name: "Forced", payload: []int32{1}},
+			}}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ForcedChunks(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if len(got) != 0 {
+				t.Errorf("got %+v, want none", got)
+			}
+		})
+	}
+}
+
+func TestLoadForcedChunks(t *testing.T) {
+	dir := t.TempDir()
+	if got, err := LoadForcedChunks(dir); err != nil || got != nil {
+		t.Fatalf("got %+v %v, want no chunks and no error", got, err)
+	}
+
+	if err := os.Mkdir(filepath.Join(dir, "data"), 0o700); err != nil {
+		t.Fatalf("Unable to create test directory: %v", err)
+	}
+	writeGzipFile(t, filepath.Join(dir, forcedName), []byte{
+		0x0A, 0x00, 0x00,
+		0x0A, 0x00, 0x04, 'd', 'a', 't', 'a',
+		0x0C, 0x00, 0x06, 'F', 'o', 'r', 'c', 'e', 'd', 0x00, 0x00, 0x00, 0x01,
+		0xFF, 0xFF, 0xFF, 0xFF, 0x00, 0x00, 0x00, 0x02,
+		0x00,
+		0x00,
+	}, time.Now())
+
+	got, err := LoadForcedChunks(dir)
+	if want := []ChunkPos{{X: 2, Z: -1}}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v %v, want %+v", got, err, want)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, forcedName), []byte{0x0A}, 0o600); err != nil {
+		t.Fatalf("Unable to write test file: %v", err)
+	}
+	if _, err := LoadForcedChunks(dir); err == nil {
+		t.Errorf("got no error, want error")
+	}
+}
@@ -0,0 +1,218 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewFS presents a tag tree as a read-only fs.FS, so fs based tooling (fs.WalkDir, fstest, http.FileServer) can browse
+// NBT data. The root tag is the "." directory. A tagCompound is a directory of its children, and a tagList is a
+// directory of its elements named by index ("0", "1", ...). Every other tag is a file holding its payload as text
+// followed by a newline, with array payloads, and the RawPayload of a tag read with WithUnknownTags, holding one
+// element per line.
+//
+// A child name that is not a valid path element is escaped: "%" becomes "%25", "/" becomes "%2F", an empty name becomes
+// "%" and the names "." and ".." become "%2E" and "%2E%2E". The Sys method of each fs.FileInfo returns the Tag.
+func NewFS(t Tag) fs.FS {
+	return tagFS{root: t}
+}
+
+// tagFS is the fs.FS returned by NewFS.
+type tagFS struct {
+	root Tag
+}
+
+// Open opens the named file or directory, walking down from the root tag one path element at a time.
+func (f tagFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	t, entry := f.root, "."
+	if name != "." {
+		for _, element := range strings.Split(name, "/") {
+			children, err := fsChildren(t)
+			if err != nil {
+				return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+			}
+
+			found := false
+			for _, child := range children {
+				if child.name == element {
+					t, entry, found = child.tag, element, true
+					break
+				}
+			}
+			if !found {
+				return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+			}
+		}
+	}
+
+	if t.id == tagCompound || t.id == tagList {
+		children, err := fsChildren(t)
+		if err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		return &tagDir{info: tagFileInfo{name: entry, tag: t}, children: children}, nil
+	}
+
+	content, err := fsContent(t)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	info := tagFileInfo{name: entry, tag: t, size: int64(len(content))}
+	return &tagFile{info: info, Reader: bytes.NewReader(content)}, nil
+}
+
+// fsChild is a child tag of a tagCompound or tagList, named as it appears in the directory listing.
+type fsChild struct {
+	name string
+	tag  Tag
+}
+
+// fsChildren returns the children of a tagCompound or the elements of a tagList, named as they appear in a directory.
+func fsChildren(t Tag) (children []fsChild, err error) {
+	switch p := t.payload.(type) {
+	case []Tag:
+		for _, child := range p {
+			children = append(children, fsChild{name: escapeFSName(child.name), tag: child})
+		}
+	case []any:
+		for i, element := range p {
+			id, err := payloadID(element)
+			if _, raw := element.(RawPayload); raw {
+				id, err = t.elementID, nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("Unable to list tagList element %v: %w", i, err)
+			}
+			children = append(children, fsChild{name: strconv.Itoa(i), tag: Tag{id: id, payload: element}})
+		}
+	default:
+		return nil, fmt.Errorf("tag ID %v is not a tagCompound or tagList", t.id)
+	}
+	return children, nil
+}
+
+// escapeFSName escapes a tag name so it is always a single valid fs path element.
+func escapeFSName(name string) string {
+	switch name {
+	case "":
+		return "%"
+	case ".":
+		return "%2E"
+	case "..":
+		return "%2E%2E"
+	}
+
+	return strings.NewReplacer("%", "%25", "/", "%2F").Replace(name)
+}
+
+// fsContent returns the file content of a tag that is not a tagCompound or tagList.
+func fsContent(t Tag) (content []byte, err error) {
+	var b strings.Builder
+	switch p := t.payload.(type) {
+	case byte, int16, int32, int64, string:
+		fmt.Fprintf(&b, "%v\n", p)
+	case float32:
+		b.WriteString(strconv.FormatFloat(float64(p), 'g', -1, 32) + "\n")
+	case float64:
+		b.WriteString(strconv.FormatFloat(p, 'g', -1, 64) + "\n")
+	case []byte:
+		for _, element := range p {
+			fmt.Fprintf(&b, "%v\n", element)
+		}
+	case RawPayload:
+		for _, element := range p {
+			fmt.Fprintf(&b, "%v\n", element)
+		}
+	case []int32:
+		for _, element := range p {
+			fmt.Fprintf(&b, "%v\n", element)
+		}
+	case []int64:
+		for _, element := range p {
+			fmt.Fprintf(&b, "%v\n", element)
+		}
+	default:
+		return nil, fmt.Errorf("tag ID %v has no file content", t.id)
+	}
+	return []byte(b.String()), nil
+}
+
+// tagFileInfo describes a tag as a fs.FileInfo and fs.DirEntry.
+type tagFileInfo struct {
+	name string
+	tag  Tag
+	size int64
+}
+
+func (i tagFileInfo) Name() string               { return i.name }
+func (i tagFileInfo) Size() int64                { return i.size }
+func (i tagFileInfo) ModTime() time.Time         { return time.Time{} }
+func (i tagFileInfo) IsDir() bool                { return i.Mode().IsDir() }
+func (i tagFileInfo) Sys() any                   { return i.tag }
+func (i tagFileInfo) Type() fs.FileMode          { return i.Mode().Type() }
+func (i tagFileInfo) Info() (fs.FileInfo, error) { return i, nil }
+
+func (i tagFileInfo) Mode() fs.FileMode {
+	if i.tag.id == tagCompound || i.tag.id == tagList {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+
+// tagFile is an open file holding the text content of a tag.
+type tagFile struct {
+	info tagFileInfo
+	*bytes.Reader
+}
+
+func (f *tagFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *tagFile) Close() error               { return nil }
+
+// tagDir is an open directory listing the children of a tagCompound or tagList.
+type tagDir struct {
+	info     tagFileInfo
+	children []fsChild
+	offset   int
+}
+
+func (d *tagDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *tagDir) Close() error               { return nil }
+
+func (d *tagDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: errors.New("is a directory")}
+}
+
+// ReadDir returns the next n directory entries, or all remaining entries if n <= 0, following fs.ReadDirFile.
+func (d *tagDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := len(d.children) - d.offset
+	if n > 0 && remaining == 0 {
+		return nil, io.EOF
+	}
+	if n <= 0 || n > remaining {
+		n = remaining
+	}
+
+	entries := make([]fs.DirEntry, n)
+	for i := range entries {
+		child := d.children[d.offset+i]
+		info := tagFileInfo{name: child.name, tag: child.tag}
+		if content, err := fsContent(child.tag); err == nil {
+			info.size = int64(len(content))
+		}
+		entries[i] = info
+	}
+	d.offset += n
+
+	return entries, nil
+}
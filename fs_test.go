@@ -0,0 +1,133 @@
+package nbt
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestNewFS(t *testing.T) {
+	level := Tag{id: tagCompound, payload: []Tag{
+		{id: tagCompound, name: "Data", payload: []Tag{
+			{id: tagString, name: "LevelName", payload: "My World"},
+			{id: tagLong, name: "RandomSeed", payload: int64(-4530634556500121041)},
+			{id: tagFloat, name: "BorderSize", payload: float32(1.5)},
+			{id: tagList, name: "Pos", payload: []any{float64(1.5), float64(64)}},
+			{id: tagList, name: "Players", payload: []any{[]Tag{{id: tagString, name: "Name", payload: "Steve"}}}},
+			{id: tagIntArray, name: "UUID", payload: []int32{1, -2, 3, 4}},
+		}},
+		{id: tagString, name: "a/b", payload: "slash"},
+		{id: tagString, name: "..", payload: "dots"},
+		{id: tagByte, payload: byte(1)},
+	}}
+
+	t.Run("Test success case: fstest", func(t *testing.T) {
+		gotErr := fstest.TestFS(NewFS(level), "Data/LevelName", "Data/Pos/0", "Data/Players/0/Name", "a%2Fb",
+			"%2E%2E", "%")
+		if gotErr != nil {
+			t.Errorf("got %v, want nil", gotErr)
+		}
+	})
+
+	contentCases := []struct {
+		name        string
+		path        string
+		wantContent string
+	}{
+		{"tagString", "Data/LevelName", "My World\n"},
+		{"tagLong", "Data/RandomSeed", "-4530634556500121041\n"},
+		{"tagFloat", "Data/BorderSize", "1.5\n"},
+		{"tagList element", "Data/Pos/1", "64\n"},
+		{"tagIntArray", "Data/UUID", "1\n-2\n3\n4\n"},
+		{"escaped name", "a%2Fb", "slash\n"},
+		{"empty name", "%", "1\n"},
+	}
+	for _, contentCase := range contentCases {
+		t.Run("Test success case: content of "+contentCase.name, func(t *testing.T) {
+			gotContent, gotErr := fs.ReadFile(NewFS(level), contentCase.path)
+			if string(gotContent) != contentCase.wantContent {
+				t.Errorf("got %q, want %q", gotContent, contentCase.wantContent)
+			}
+			if gotErr != nil {
+				t.Errorf("got %v, want nil", gotErr)
+			}
+		})
+	}
+
+	t.Run("Test success case: walk", func(t *testing.T) {
+		count := 0
+		gotErr := fs.WalkDir(NewFS(level), ".", func(_ string, _ fs.DirEntry, err error) error {
+			count++
+			return err
+		})
+		if gotErr != nil {
+			t.Errorf("got %v, want nil", gotErr)
+		}
+		if count != 15 {
+			t.Errorf("got %v entries, want 15", count)
+		}
+	})
+
+	t.Run("Test success case: stat returns tag", func(t *testing.T) {
+		info, gotErr := fs.Stat(NewFS(level), "Data/Pos")
+		if gotErr != nil {
+			t.Fatalf("got %v, want nil", gotErr)
+		}
+		if !info.IsDir() {
+			t.Errorf("got file, want directory")
+		}
+		if info.Sys().(Tag).id != tagList {
+			t.Errorf("got %v, want %v", info.Sys().(Tag).id, tagList)
+		}
+	})
+
+	t.Run("Test success case: unknown tags", func(t *testing.T) {
+		// A compound holding tag ID 13 named "u" and a list of two ID 13 elements, read with WithUnknownTags.
+		input := []byte{tagCompound, 0, 0, 13, 0, 1, 'u', 1, 2, 3, tagList, 0, 1, 'l', 13, 0, 0, 0, 2, 4, 5, 6, 7, 8, 9,
+			tagEnd}
+		unknown, err := ReadTag(bytes.NewReader(input), WithUnknownTags(FixedSizeTags(map[uint8]int{13: 3})))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if gotErr := fstest.TestFS(NewFS(unknown), "u", "l/0", "l/1"); gotErr != nil {
+			t.Errorf("got %v, want nil", gotErr)
+		}
+		gotContent, gotErr := fs.ReadFile(NewFS(unknown), "l/1")
+		if string(gotContent) != "7\n8\n9\n" || gotErr != nil {
+			t.Errorf("got %q %v, want %q", gotContent, gotErr, "7\n8\n9\n")
+		}
+		info, gotErr := fs.Stat(NewFS(unknown), "l/0")
+		if gotErr != nil || info.Sys().(Tag).id != 13 {
+			t.Errorf("got %v %v, want tag ID 13", info, gotErr)
+		}
+	})
+
+	failureCases := []struct {
+		name string
+		path string
+	}{
+		{"invalid path", "/Data"},
+		{"missing child", "Data/Missing"},
+		{"child of a file", "Data/LevelName/x"},
+		{"list index out of range", "Data/Pos/2"},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			_, gotErr := NewFS(level).Open(failureCase.path)
+			if gotErr == nil {
+				t.Errorf("got nil, want non-nil")
+			}
+		})
+	}
+
+	t.Run("Test failure case: read a directory", func(t *testing.T) {
+		dir, _ := NewFS(level).Open("Data")
+		_, gotErr := io.ReadAll(dir)
+		if gotErr == nil {
+			t.Errorf("got nil, want non-nil")
+		}
+	})
+}
@@ -0,0 +1,150 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// Generator produces random but valid tag trees for property-based testing and fuzzing. Every tree it generates is a
+// root tagCompound with unique child names, lists hold elements of a single type, and strings are valid UTF-8. Float
+// and double payloads are random bit patterns, so NaN, infinities and -0.0 all occur. Empty compounds, lists and
+// arrays have nil payloads, matching ReadTag.
+type Generator struct {
+	// MaxDepth is the deepest nesting of compounds and lists below the root. At the maximum depth only tags without
+	// children are generated.
+	MaxDepth int
+	// MaxElements is the largest number of children of a compound, or elements of a list or array.
+	MaxElements int
+	// MaxStringLength is the largest number of characters in a tag name or tagString payload. It is capped so the
+	// encoded string always fits its 2 byte length.
+	MaxStringLength int
+	// IDs are the tag IDs that may be generated below the root. An empty IDs allows every tag ID except tagEnd.
+	IDs []uint8
+}
+
+// Generate implements testing/quick.Generator, so Tag can be used directly as a quick.Check argument. The size scales
+// the number of elements and string lengths, the depth is fixed at 3.
+func (Tag) Generate(r *rand.Rand, size int) reflect.Value {
+	g := Generator{MaxDepth: 3, MaxElements: max(size/4, 1), MaxStringLength: size}
+	return reflect.ValueOf(g.Tag(r))
+}
+
+// Tag generates a random tree with an unnamed tagCompound root.
+func (g Generator) Tag(r *rand.Rand) Tag {
+	return Tag{id: tagCompound, payload: g.compound(r, 0)}
+}
+
+// ids returns the tag IDs that may be generated at the given depth.
+func (g Generator) ids(depth int) []uint8 {
+	ids := g.IDs
+	if len(ids) == 0 {
+		ids = []uint8{tagByte, tagShort, tagInt, tagLong, tagFloat, tagDouble, tagByteArray, tagString, tagList,
+			tagCompound, tagIntArray, tagLongArray}
+	}
+
+	return slices.DeleteFunc(slices.Clone(ids), func(id uint8) bool {
+		return id == tagEnd || id > tagLongArray || (depth >= g.MaxDepth && (id == tagList || id == tagCompound))
+	})
+}
+
+// payload generates a random payload for the tag ID at the given depth.
+func (g Generator) payload(r *rand.Rand, id uint8, depth int) any {
+	switch id {
+	case tagByte:
+		return byte(r.Intn(math.MaxUint8 + 1))
+	case tagShort:
+		return int16(r.Intn(math.MaxUint16+1) + math.MinInt16) // #nosec G115 -- within int16 range
+	case tagInt:
+		return int32(r.Uint32()) // #nosec G115 -- reinterpreting random bits
+	case tagLong:
+		return int64(r.Uint64()) // #nosec G115 -- reinterpreting random bits
+	case tagFloat:
+		return math.Float32frombits(r.Uint32())
+	case tagDouble:
+		return math.Float64frombits(r.Uint64())
+	case tagByteArray:
+		var payload []byte
+		for range r.Intn(g.MaxElements + 1) {
+			payload = append(payload, byte(r.Intn(math.MaxUint8+1)))
+		}
+		return payload
+	case tagString:
+		return g.string(r)
+	case tagList:
+		return g.list(r, depth+1)
+	case tagCompound:
+		return g.compound(r, depth+1)
+	case tagIntArray:
+		var payload []int32
+		for range r.Intn(g.MaxElements + 1) {
+			payload = append(payload, int32(r.Uint32())) // #nosec G115 -- reinterpreting random bits
+		}
+		return payload
+	default:
+		var payload []int64
+		for range r.Intn(g.MaxElements + 1) {
+			payload = append(payload, int64(r.Uint64())) // #nosec G115 -- reinterpreting random bits
+		}
+		return payload
+	}
+}
+
+// compound generates the children of a tagCompound, each with a unique name.
+func (g Generator) compound(r *rand.Rand, depth int) []Tag {
+	ids := g.ids(depth)
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var children []Tag
+	names := map[string]bool{}
+	for i := range r.Intn(g.MaxElements + 1) {
+		name := g.string(r)
+		for names[name] {
+			name += strconv.Itoa(i)
+		}
+		names[name] = true
+
+		id := ids[r.Intn(len(ids))]
+		children = append(children, Tag{id: id, name: name, payload: g.payload(r, id, depth)})
+	}
+
+	return children
+}
+
+// list generates the elements of a tagList, all of a single random tag type.
+func (g Generator) list(r *rand.Rand, depth int) []any {
+	ids := g.ids(depth)
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var elements []any
+	id := ids[r.Intn(len(ids))]
+	for range r.Intn(g.MaxElements + 1) {
+		elements = append(elements, g.payload(r, id, depth))
+	}
+
+	return elements
+}
+
+// string generates a random valid UTF-8 string, mixing single and multi-byte characters.
+func (g Generator) string(r *rand.Rand) string {
+	runes := make([]rune, r.Intn(min(g.MaxStringLength, math.MaxUint16/3)+1))
+	for i := range runes {
+		switch r.Intn(4) {
+		case 0:
+			runes[i] = rune(0x4E00 + r.Intn(0x5000)) // CJK unified ideographs
+		case 1:
+			runes[i] = rune(0x00A0 + r.Intn(0x0700)) // Latin supplements through Cyrillic
+		default:
+			runes[i] = rune(0x20 + r.Intn(0x5F)) // printable ASCII
+		}
+	}
+
+	return string(runes)
+}
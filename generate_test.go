@@ -0,0 +1,112 @@
+package nbt
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"testing/quick"
+	"unicode/utf8"
+)
+
+// checkGenerated returns an error describing the first way in which a generated tag is not valid, and the nesting
+// depth of the tag.
+func checkGenerated(t Tag) (depth int, err error) {
+	id, err := payloadID(t.payload)
+	if err != nil {
+		return 0, err
+	}
+	if id != t.id {
+		return 0, fmt.Errorf("tag \"%v\" has ID %v but a payload of ID %v", t.name, t.id, id)
+	}
+	if !utf8.ValidString(t.name) {
+		return 0, fmt.Errorf("tag name %q is not valid UTF-8", t.name)
+	}
+
+	switch p := t.payload.(type) {
+	case string:
+		if !utf8.ValidString(p) {
+			return 0, fmt.Errorf("tag \"%v\" payload is not valid UTF-8", t.name)
+		}
+	case []Tag:
+		names := map[string]bool{}
+		for _, child := range p {
+			if names[child.name] {
+				return 0, fmt.Errorf("tag \"%v\" has duplicate child \"%v\"", t.name, child.name)
+			}
+			names[child.name] = true
+			childDepth, err := checkGenerated(child)
+			if err != nil {
+				return 0, err
+			}
+			depth = max(depth, childDepth+1)
+		}
+	case []any:
+		var listID uint8
+		for i, element := range p {
+			elementID, err := payloadID(element)
+			if err != nil {
+				return 0, err
+			}
+			if i > 0 && elementID != listID {
+				return 0, fmt.Errorf("tag \"%v\" has mixed element IDs %v and %v", t.name, listID, elementID)
+			}
+			listID = elementID
+			elementDepth, err := checkGenerated(Tag{id: elementID, payload: element})
+			if err != nil {
+				return 0, err
+			}
+			depth = max(depth, elementDepth+1)
+		}
+	}
+	return depth, nil
+}
+
+func TestGenerate(t *testing.T) {
+	t.Run("Test success case: quick.Check generated trees are valid", func(t *testing.T) {
+		property := func(root Tag) bool {
+			_, err := checkGenerated(root)
+			return err == nil && root.id == tagCompound
+		}
+		gotErr := quick.Check(property, &quick.Config{MaxCount: 50})
+		if gotErr != nil {
+			t.Errorf("got %v, want nil", gotErr)
+		}
+	})
+
+	successCases := []struct {
+		name      string
+		generator Generator
+	}{
+		{"no nesting", Generator{MaxDepth: 0, MaxElements: 10, MaxStringLength: 10}},
+		{"deep nesting", Generator{MaxDepth: 6, MaxElements: 3, MaxStringLength: 3}},
+		{"empty strings", Generator{MaxDepth: 2, MaxElements: 10, MaxStringLength: 0}},
+		{"only lists of ints", Generator{MaxDepth: 2, MaxElements: 5, MaxStringLength: 5, IDs: []uint8{tagList,
+			tagInt}}},
+		{"only containers", Generator{MaxDepth: 2, MaxElements: 3, IDs: []uint8{tagCompound, tagList}}},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1)) // #nosec G404 -- deterministic test input
+			for range 20 {
+				root := successCase.generator.Tag(r)
+				gotDepth, gotErr := checkGenerated(root)
+				if gotErr != nil {
+					t.Fatalf("got %v, want nil", gotErr)
+				}
+				if gotDepth > successCase.generator.MaxDepth+1 {
+					t.Errorf("got depth %v, want at most %v", gotDepth, successCase.generator.MaxDepth+1)
+				}
+			}
+		})
+	}
+
+	t.Run("Test success case: restricted IDs", func(t *testing.T) {
+		g := Generator{MaxDepth: 1, MaxElements: 10, MaxStringLength: 5, IDs: []uint8{tagString}}
+		r := rand.New(rand.NewSource(2)) // #nosec G404 -- deterministic test input
+		for _, child := range g.Tag(r).payload.([]Tag) {
+			if child.id != tagString {
+				t.Errorf("got %v, want %v", child.id, tagString)
+			}
+		}
+	})
+}
@@ -0,0 +1,95 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrNotInPlace is returned when a tag cannot be rewritten in place, and the whole file must be written instead.
+var ErrNotInPlace = errors.New("tag cannot be rewritten in place")
+
+// WriteInPlace replaces the encoding of the tag original with the encoding of updated in the file original was read
+// from, at the offset recorded by Tag.Source, leaving the rest of the file as it is. It is much faster than writing a
+// large file again to change one value. The original must have been read from an uncompressed file with
+// WithProvenance naming its path, as ReadFile does, and with the same byte order and format as the options.
+//
+// The encoding of original is checked against the file before anything is written. If updated encodes to the same
+// size it overwrites original. Otherwise only tagEnd bytes may follow original, so it is the last tag of every
+// compound holding it, and the file is rewritten from the offset then truncated. The file is synced once written. The
+// returned error wraps ErrNotInPlace if none of this holds, in which case the file is not modified.
+func WriteInPlace(original, updated Tag, opts ...Option) (err error) {
+	source, ok := original.Source()
+	if !ok {
+		return fmt.Errorf("Unable to write tag in place: %w: no source was recorded", ErrNotInPlace)
+	}
+
+	file, err := os.OpenFile(source.File, os.O_RDWR, 0) // #nosec G304 -- the path was recorded when the tag was read
+	if err != nil {
+		return fmt.Errorf("Unable to write tag in place: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, file.Close())
+	}()
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("Unable to write tag in place: %w", err)
+	}
+	if compression, err := fileCompression(source.File); err != nil || compression != CompressionNone {
+		return fmt.Errorf("Unable to write tag in place: %w: %v is compressed", ErrNotInPlace, source)
+	}
+
+	o := newOptions(opts)
+	if source.Offset > 0 {
+		// A tag after the start of the file is a compound child, named even in the network format.
+		o.depth = 1
+	}
+	var old, encoded bytes.Buffer
+	if err = writeTag(&old, original, o); err != nil {
+		return fmt.Errorf("Unable to write tag in place: %w", err)
+	}
+	if err = writeTag(&encoded, updated, o); err != nil {
+		return fmt.Errorf("Unable to write tag in place: %w", err)
+	}
+
+	current := make([]byte, old.Len())
+	n, err := file.ReadAt(current, source.Offset)
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("Unable to write tag in place: %w", err)
+	}
+	if !bytes.Equal(current[:n], old.Bytes()) {
+		return fmt.Errorf("Unable to write tag in place: %w: %v does not hold the tag's encoding", ErrNotInPlace,
+			source)
+	}
+
+	resized := encoded.Len() != old.Len()
+	if resized {
+		end := source.Offset + int64(old.Len())
+		tail := make([]byte, max(info.Size()-end, 0))
+		if _, err = file.ReadAt(tail, end); err != nil && err != io.EOF {
+			return fmt.Errorf("Unable to write tag in place: %w", err)
+		}
+		if len(bytes.TrimLeft(tail, "\x00")) > 0 {
+			return fmt.Errorf("Unable to write tag in place: %w: the encoded size changes and tags follow %v",
+				ErrNotInPlace, source)
+		}
+		encoded.Write(tail)
+	}
+
+	if _, err = file.WriteAt(encoded.Bytes(), source.Offset); err != nil {
+		return fmt.Errorf("Unable to write tag in place: %w", err)
+	}
+	if resized {
+		err = file.Truncate(source.Offset + int64(encoded.Len()))
+		if err != nil {
+			return fmt.Errorf("Unable to write tag in place: %w", err)
+		}
+	}
+	if err = file.Sync(); err != nil {
+		return fmt.Errorf("Unable to write tag in place: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package nbt
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteInPlace(t *testing.T) {
+	root, _ := NewCompound("", NewInt("a", 1), NewString("b", "x"))
+
+	// readChild writes the root to a file with the compression and returns the path and the named child, read with
+	// provenance.
+	readChild := func(t *testing.T, compression Compression, name string) (path string, child Tag) {
+		t.Helper()
+		path = filepath.Join(t.TempDir(), "test.dat")
+		if err := WriteFile(path, root, WithCompression(compression)); err != nil {
+			t.Fatalf("Unable to write test file: %v", err)
+		}
+		read, err := ReadFile(path, WithProvenance(""))
+		if err != nil {
+			t.Fatalf("Unable to read test file: %v", err)
+		}
+		child, err = Get[Tag](&read, name)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return path, child
+	}
+
+	successCases := []struct {
+		name    string
+		child   string
+		updated Tag
+		want    Tag
+	}{
+		{"same size", "a", NewInt("a", 2), NewInt("a", 2)},
+		{"last tag resized", "b", NewString("b", "longer"), NewString("b", "longer")},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			path, child := readChild(t, CompressionNone, successCase.child)
+			if err := WriteInPlace(child, successCase.updated); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			got, err := ReadFile(path)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			want := root.Clone()
+			if err = want.SetPath(Path{successCase.child}, successCase.want, false); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !Equal(&got, &want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+
+	failureCases := []struct {
+		name        string
+		compression Compression
+		child       string
+		updated     Tag
+		prepare     func(t *testing.T, path string, child *Tag)
+	}{
+		{name: "resized with tags after", child: "a", updated: NewLong("a", 1)},
+		{name: "compressed file", compression: CompressionGzip, child: "b", updated: NewString("b", "y")},
+		{name: "no source", child: "a", updated: NewInt("a", 2), prepare: func(_ *testing.T, _ string, child *Tag) {
+			*child = NewInt("a", 1)
+		}},
+		{name: "file changed since read", child: "a", updated: NewInt("a", 2), prepare: func(t *testing.T,
+			path string, _ *Tag) {
+			if err := WriteFile(path, NewInt("", 7), WithCompression(CompressionNone)); err != nil {
+				t.Fatalf("Unable to write test file: %v", err)
+			}
+		}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			path, child := readChild(t, failureCase.compression, failureCase.child)
+			if failureCase.prepare != nil {
+				failureCase.prepare(t, path, &child)
+			}
+			before, _ := os.ReadFile(path) // #nosec G304 -- test file
+
+			if err := WriteInPlace(child, failureCase.updated); !errors.Is(err, ErrNotInPlace) {
+				t.Errorf("got %v, want %v", err, ErrNotInPlace)
+			}
+			if after, _ := os.ReadFile(path); !bytes.Equal(before, after) { // #nosec G304 -- test file
+				t.Errorf("got the file modified, want it unchanged")
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// inputReader counts the bytes read from the input of ReadTag, giving the offset of each tag for its Source and, when
+// the size of the input is known, the bytes remaining so declared lengths can be checked before allocating or looping.
+type inputReader struct {
+	r    io.Reader
+	read int64
+	// size is the number of bytes in the input, or -1 if it is not known.
+	size int64
+}
+
+// Read reads from the input, counting the bytes read.
+func (i *inputReader) Read(p []byte) (n int, err error) {
+	n, err = i.r.Read(p)
+	i.read += int64(n)
+	return n, err
+}
+
+// newInputReader wraps r in an inputReader. If sized is true and the number of bytes remaining in r is known, see
+// remainingSize, it is the size of the input.
+func newInputReader(r io.Reader, sized bool) *inputReader {
+	i := &inputReader{r: r, size: -1}
+	if sized {
+		i.size = remainingSize(r)
+	}
+	return i
+}
+
+// remainingSize returns the number of bytes remaining in r, or -1 if it is not known. It is known for an
+// io.LimitedReader, a reader with a Len method (such as bytes.Buffer, bytes.Reader and strings.Reader), a regular
+// os.File, and a deadlineReader or inputReader over any of these.
+func remainingSize(r io.Reader) int64 {
+	switch s := r.(type) {
+	case *io.LimitedReader:
+		return s.N
+	case *inputReader:
+		if s.size < 0 {
+			return -1
+		}
+		return s.size - s.read
+	case interface{ Len() int }:
+		return int64(s.Len())
+	case *deadlineReader:
+		return s.size
+	case *os.File:
+		info, err := s.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		offset, err := s.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return info.Size() - offset
+	}
+	return -1
+}
+
+// inputOffset returns the number of bytes read from the buffer so far, or 0 if it is not an inputReader.
+func inputOffset(buffer io.Reader) int64 {
+	if i, ok := buffer.(*inputReader); ok {
+		return i.read
+	}
+	return 0
+}
+
+// checkRemaining returns an error if count elements of at least size bytes each cannot fit in the bytes remaining in
+// the buffer. Buffers of unknown size always pass.
+func checkRemaining(buffer io.Reader, count, size int64) error {
+	i, ok := buffer.(*inputReader)
+	if !ok || i.size < 0 || count*size <= i.size-i.read {
+		return nil
+	}
+	return fmt.Errorf("declared length %v exceeds the %v bytes remaining in the input", count, i.size-i.read)
+}
+
+// minPayloadSize returns the fewest bytes a payload of the tag ID can be encoded in, used to check list lengths.
+func minPayloadSize(id uint8) int64 {
+	switch id {
+	case tagByte, tagCompound:
+		return 1
+	case tagShort, tagString:
+		return 2
+	case tagInt, tagFloat, tagByteArray, tagIntArray, tagLongArray:
+		return 4
+	case tagLong, tagDouble:
+		return 8
+	case tagList:
+		return 5
+	default:
+		return 0
+	}
+}
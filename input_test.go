@@ -0,0 +1,142 @@
+package nbt
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewInputReader(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "level.dat"))
+	if err != nil {
+		t.Fatalf("Unable to create test file: %v", err)
+	}
+	defer file.Close()
+	_, _ = file.WriteString("0123456789")
+	_, _ = file.Seek(4, io.SeekStart)
+	partRead := newInputReader(strings.NewReader("abcd"), true)
+	_, _ = partRead.Read(make([]byte, 1))
+
+	successCases := []struct {
+		name     string
+		r        io.Reader
+		sized    bool
+		wantSize int64
+	}{
+		{"bytes.Buffer", bytes.NewBuffer([]byte{1, 2, 3}), true, 3},
+		{"bytes.Reader", bytes.NewReader([]byte{1, 2}), true, 2},
+		{"strings.Reader", strings.NewReader("abcd"), true, 4},
+		{"io.LimitedReader", &io.LimitedReader{R: strings.NewReader("abcd"), N: 2}, true, 2},
+		{"os.File", file, true, 6},
+		{"bufio.Reader", bufio.NewReader(strings.NewReader("abcd")), true, -1},
+		{"inputReader part read", partRead, true, 3},
+		{"unsized inputReader", newInputReader(strings.NewReader("abcd"), false), true, -1},
+		{"not sized", strings.NewReader("abcd"), false, -1},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			got := newInputReader(successCase.r, successCase.sized)
+			if got.size != successCase.wantSize {
+				t.Errorf("got %v, want %v", got.size, successCase.wantSize)
+			}
+		})
+	}
+
+	t.Run("Test success case: reading counts", func(t *testing.T) {
+		r := newInputReader(strings.NewReader("abcd"), true)
+		_, _ = r.Read(make([]byte, 3))
+		if r.read != 3 || inputOffset(r) != 3 {
+			t.Errorf("got %v, want 3", r.read)
+		}
+		if inputOffset(strings.NewReader("abcd")) != 0 {
+			t.Errorf("got %v for a plain reader, want 0", inputOffset(strings.NewReader("abcd")))
+		}
+	})
+}
+
+func TestCheckRemaining(t *testing.T) {
+	sized := &inputReader{r: strings.NewReader("abcdef"), read: 2, size: 6}
+
+	successCases := []struct {
+		name   string
+		buffer io.Reader
+		count  int64
+		size   int64
+	}{
+		{"fits", sized, 2, 2},
+		{"plain reader", strings.NewReader(""), 1 << 30, 8},
+		{"unknown size", &inputReader{r: strings.NewReader(""), size: -1}, 1 << 30, 8},
+		{"zero size elements", sized, 1 << 30, 0},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			if err := checkRemaining(successCase.buffer, successCase.count, successCase.size); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+
+	t.Run("Test failure case: exceeds remaining", func(t *testing.T) {
+		if err := checkRemaining(sized, 3, 2); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+}
+
+func TestReadTagRemaining(t *testing.T) {
+	failureCases := []struct {
+		name  string
+		input []byte
+	}{
+		{"name", []byte{tagByte, 0x7F, 0xFF, 'a'}},
+		{"string", []byte{tagString, 0, 0, 0xFF, 0xFF, 'a'}},
+		{"byte array", []byte{tagByteArray, 0, 0, 0x7F, 0xFF, 0xFF, 0xFF, 1}},
+		{"int array", []byte{tagIntArray, 0, 0, 0, 0, 0, 2, 1, 2, 3, 4, 5, 6, 7}},
+		{"long array", []byte{tagLongArray, 0, 0, 0, 0, 0, 1, 1, 2, 3, 4, 5, 6, 7}},
+		{"list", []byte{tagList, 0, 0, tagCompound, 0x7F, 0xFF, 0xFF, 0xFF, tagEnd}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			_, err := ReadTag(bytes.NewReader(failureCase.input))
+			if err == nil || !strings.Contains(err.Error(), "exceeds the") {
+				t.Errorf("got %v, want declared length error", err)
+			}
+		})
+
+		t.Run("Test failure case: Decoder "+failureCase.name, func(t *testing.T) {
+			var tag Tag
+			err := NewDecoder(bytes.NewReader(failureCase.input)).Decode(&tag)
+			if err == nil || !strings.Contains(err.Error(), "exceeds the") {
+				t.Errorf("got %v, want declared length error", err)
+			}
+		})
+	}
+
+	t.Run("Test failure case: unsized reader still fails at EOF", func(t *testing.T) {
+		input := []byte{tagByteArray, 0, 0, 0, 0, 0, 2, 1}
+		_, err := ReadTag(bufio.NewReader(bytes.NewReader(input)))
+		if !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+			t.Errorf("got %v, want EOF", err)
+		}
+	})
+}
+
+func TestMinPayloadSize(t *testing.T) {
+	successCases := []struct {
+		id   uint8
+		want int64
+	}{
+		{tagEnd, 0}, {tagByte, 1}, {tagShort, 2}, {tagInt, 4}, {tagLong, 8}, {tagFloat, 4}, {tagDouble, 8},
+		{tagByteArray, 4}, {tagString, 2}, {tagList, 5}, {tagCompound, 1}, {tagIntArray, 4}, {tagLongArray, 4},
+	}
+	for _, successCase := range successCases {
+		if got := minPayloadSize(successCase.id); got != successCase.want {
+			t.Errorf("got %v for tag ID %v, want %v", got, successCase.id, successCase.want)
+		}
+	}
+}
@@ -0,0 +1,383 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// ItemComponentsDataVersion is the DataVersion of Java edition 1.20.5, from which item stacks store an int "count"
+// and a "components" compound rather than a byte "Count" and a "tag" compound.
+const ItemComponentsDataVersion = 3837
+
+// Enchantment is an enchantment of an item stack.
+type Enchantment struct {
+	// ID is the namespaced enchantment ID, such as "minecraft:sharpness".
+	ID    string
+	Level int32
+}
+
+// ItemStack is an item stack, as found in inventories, containers and trades. It is built with NewItemStack and the
+// With methods, and converted to NBT for a target DataVersion with Tag.
+type ItemStack struct {
+	// ID is the namespaced item ID, such as "minecraft:diamond_sword".
+	ID    string
+	Count int32
+	// Slot is the inventory slot of the stack, stored only when HasSlot is true.
+	Slot    int8
+	HasSlot bool
+	// Name is the custom name as a JSON text component, and Lore the lines of lore as JSON text components. An empty
+	// Name is no custom name.
+	Name string
+	Lore []string
+	// Enchantments are the enchantments in the order they are stored.
+	Enchantments []Enchantment
+	// Components are any other children of the "components" compound (1.20.5 and later) or "tag" compound (earlier).
+	Components []Tag
+
+	// display holds the children of a legacy "display" compound other than Name and Lore, such as a leather colour.
+	display []Tag
+}
+
+// NewItemStack returns an item stack of count items with the namespaced item ID.
+func NewItemStack(id string, count int32) *ItemStack {
+	return &ItemStack{ID: id, Count: count}
+}
+
+// WithSlot sets the inventory slot of the stack.
+func (s *ItemStack) WithSlot(slot int8) *ItemStack {
+	s.Slot, s.HasSlot = slot, true
+	return s
+}
+
+// WithName sets the custom name of the stack to plain text.
+func (s *ItemStack) WithName(text string) *ItemStack {
+	s.Name = textComponent(text)
+	return s
+}
+
+// WithLore appends lines of plain text lore to the stack.
+func (s *ItemStack) WithLore(lines ...string) *ItemStack {
+	for _, line := range lines {
+		s.Lore = append(s.Lore, textComponent(line))
+	}
+	return s
+}
+
+// WithEnchantment adds an enchantment to the stack, replacing any existing level of the same enchantment.
+func (s *ItemStack) WithEnchantment(id string, level int32) *ItemStack {
+	i := slices.IndexFunc(s.Enchantments, func(e Enchantment) bool { return e.ID == id })
+	if i < 0 {
+		s.Enchantments = append(s.Enchantments, Enchantment{ID: id, Level: level})
+	} else {
+		s.Enchantments[i].Level = level
+	}
+	return s
+}
+
+// WithComponent adds a custom component to the stack, replacing any existing component of the same name.
+func (s *ItemStack) WithComponent(t Tag) *ItemStack {
+	s.Components = withChild(s.Components, t)
+	return s
+}
+
+// textComponent returns plain text as a JSON text component.
+func textComponent(text string) string {
+	encoded, _ := json.Marshal(map[string]string{"text": text}) // #nosec G104 -- a map of strings always marshals
+	return string(encoded)
+}
+
+// Tag returns the stack as an unnamed item stack compound for the DataVersion, see ItemComponentsDataVersion.
+func (s *ItemStack) Tag(dataVersion int32) Tag {
+	children := []Tag{{id: tagString, name: "id", payload: s.ID}}
+	if dataVersion >= ItemComponentsDataVersion {
+		children = append(children, Tag{id: tagInt, name: "count", payload: s.Count})
+	} else {
+		count := byte(min(max(s.Count, 0), 255)) // #nosec G115 -- clamped to a byte
+		children = append(children, Tag{id: tagByte, name: "Count", payload: count})
+	}
+	if s.HasSlot {
+		children = append(children, Tag{id: tagByte, name: "Slot", payload: byte(s.Slot)}) // #nosec G115 -- signed byte
+	}
+
+	var extra []Tag
+	if dataVersion >= ItemComponentsDataVersion {
+		extra = s.components()
+		if len(extra) > 0 {
+			children = append(children, Tag{id: tagCompound, name: "components", payload: extra})
+		}
+	} else {
+		extra = s.legacyTag()
+		if len(extra) > 0 {
+			children = append(children, Tag{id: tagCompound, name: "tag", payload: extra})
+		}
+	}
+	return Tag{id: tagCompound, payload: children}
+}
+
+// components returns the children of the "components" compound of 1.20.5 and later.
+func (s *ItemStack) components() (components []Tag) {
+	if s.Name != "" {
+		components = append(components, Tag{id: tagString, name: "minecraft:custom_name", payload: s.Name})
+	}
+	if len(s.Lore) > 0 {
+		components = append(components, Tag{id: tagList, elementID: tagString, name: "minecraft:lore",
+			payload: stringElements(s.Lore)})
+	}
+	if len(s.Enchantments) > 0 {
+		levels := make([]Tag, len(s.Enchantments))
+		for i, e := range s.Enchantments {
+			levels[i] = Tag{id: tagInt, name: e.ID, payload: e.Level}
+		}
+		components = append(components, Tag{id: tagCompound, name: "minecraft:enchantments", payload: []Tag{
+			{id: tagCompound, name: "levels", payload: levels},
+		}})
+	}
+	for _, component := range s.Components {
+		components = withChild(components, component)
+	}
+	return components
+}
+
+// legacyTag returns the children of the "tag" compound before 1.20.5.
+func (s *ItemStack) legacyTag() (tag []Tag) {
+	display := slices.Clone(s.display)
+	if s.Name != "" {
+		display = append(display, Tag{id: tagString, name: "Name", payload: s.Name})
+	}
+	if len(s.Lore) > 0 {
+		display = append(display, Tag{id: tagList, elementID: tagString, name: "Lore", payload: stringElements(s.Lore)})
+	}
+	if len(display) > 0 {
+		tag = append(tag, Tag{id: tagCompound, name: "display", payload: display})
+	}
+	if len(s.Enchantments) > 0 {
+		enchantments := make([]any, len(s.Enchantments))
+		for i, e := range s.Enchantments {
+			level := int16(min(max(e.Level, -32768), 32767)) // #nosec G115 -- clamped to a short
+			enchantments[i] = []Tag{{id: tagString, name: "id", payload: e.ID}, {id: tagShort, name: "lvl",
+				payload: level}}
+		}
+		tag = append(tag, Tag{id: tagList, elementID: tagCompound, name: "Enchantments", payload: enchantments})
+	}
+	for _, component := range s.Components {
+		tag = withChild(tag, component)
+	}
+	return tag
+}
+
+// stringElements returns strings as tagList elements.
+func stringElements(strings []string) []any {
+	elements := make([]any, len(strings))
+	for i, s := range strings {
+		elements[i] = s
+	}
+	return elements
+}
+
+// ItemStackFromTag reads an item stack compound of any DataVersion, as stored in inventories, containers and trades.
+func ItemStackFromTag(t Tag) (s ItemStack, err error) {
+	if t.id != tagCompound {
+		return ItemStack{}, fmt.Errorf("Unable to read item stack: tag ID %v is not a tagCompound", t.id)
+	}
+
+	var ok bool
+	s.ID, ok = childPayload[string](t, "id")
+	if !ok {
+		return ItemStack{}, fmt.Errorf("Unable to read item stack: no tagString named \"id\"")
+	}
+	if count, ok := childPayload[int32](t, "count"); ok {
+		s.Count = count
+	} else if count, ok := childPayload[byte](t, "Count"); ok {
+		s.Count = int32(int8(count)) // #nosec G115 -- reinterpreting the signed byte
+	} else {
+		s.Count = 1
+	}
+	if slot, ok := childPayload[byte](t, "Slot"); ok {
+		s.Slot, s.HasSlot = int8(slot), true // #nosec G115 -- reinterpreting the signed byte
+	}
+
+	if components, ok := compoundChild(t, "components"); ok {
+		err = s.readComponents(components)
+	} else if tag, ok := compoundChild(t, "tag"); ok {
+		err = s.readLegacyTag(tag)
+	}
+	if err != nil {
+		return ItemStack{}, fmt.Errorf("Unable to read item stack %v: %w", s.ID, err)
+	}
+	return s, nil
+}
+
+// readComponents reads the "components" compound of 1.20.5 and later.
+func (s *ItemStack) readComponents(components Tag) (err error) {
+	children, _ := components.payload.([]Tag)
+	for _, child := range children {
+		switch child.name {
+		case "minecraft:custom_name":
+			s.Name, err = tradeField[string](child)
+		case "minecraft:lore":
+			s.Lore, err = stringList(child)
+		case "minecraft:enchantments":
+			levels, _ := compoundChild(child, "levels")
+			enchantments, _ := levels.payload.([]Tag)
+			for _, e := range enchantments {
+				level, ok := e.payload.(int32)
+				if !ok {
+					return fmt.Errorf("enchantment \"%v\" level has payload type %T, not int32", e.name, e.payload)
+				}
+				s.Enchantments = append(s.Enchantments, Enchantment{ID: e.name, Level: level})
+			}
+		default:
+			s.Components = append(s.Components, child)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readLegacyTag reads the "tag" compound before 1.20.5.
+func (s *ItemStack) readLegacyTag(tag Tag) (err error) {
+	children, _ := tag.payload.([]Tag)
+	for _, child := range children {
+		switch child.name {
+		case "display":
+			if name, ok := compoundChild(child, "Name"); ok {
+				s.Name, err = tradeField[string](name)
+			}
+			if lore, ok := compoundChild(child, "Lore"); ok && err == nil {
+				s.Lore, err = stringList(lore)
+			}
+			display, _ := child.payload.([]Tag)
+			for _, other := range display {
+				if other.name != "Name" && other.name != "Lore" {
+					s.display = append(s.display, other)
+				}
+			}
+		case "Enchantments":
+			elements, _ := child.payload.([]any)
+			for i, element := range elements {
+				e := Tag{id: tagCompound, payload: element}
+				id, idOK := childPayload[string](e, "id")
+				level, levelOK := childPayload[int16](e, "lvl")
+				if !idOK || !levelOK {
+					return fmt.Errorf("enchantment %v has no tagString \"id\" and tagShort \"lvl\"", i)
+				}
+				s.Enchantments = append(s.Enchantments, Enchantment{ID: id, Level: int32(level)})
+			}
+		default:
+			s.Components = append(s.Components, child)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stringList returns the elements of a tagList of tagString.
+func stringList(t Tag) ([]string, error) {
+	elements, ok := t.payload.([]any)
+	if t.id != tagList || (!ok && t.payload != nil) {
+		return nil, fmt.Errorf("\"%v\" is tag ID %v, not a tagList", t.name, t.id)
+	}
+
+	strings := make([]string, len(elements))
+	for i, element := range elements {
+		strings[i], ok = element.(string)
+		if !ok {
+			return nil, fmt.Errorf("\"%v\" element %v has payload type %T, not string", t.name, i, element)
+		}
+	}
+	return strings, nil
+}
+
+// Stackable reports whether the stacks hold the same kind of item, so they may be merged. Count and slot are ignored.
+func (s *ItemStack) Stackable(other ItemStack) bool {
+	if s.ID != other.ID || s.Name != other.Name || !slices.Equal(s.Lore, other.Lore) ||
+		!slices.Equal(s.Enchantments, other.Enchantments) {
+		return false
+	}
+	return payloadsEqual(s.legacyTag(), other.legacyTag())
+}
+
+// Inventory is a list of item stacks, such as the Inventory of a player or the Items of a container.
+type Inventory []ItemStack
+
+// InventoryFromTag reads a tagList of item stack compounds.
+func InventoryFromTag(list Tag) (inv Inventory, err error) {
+	elements, ok := list.payload.([]any)
+	if list.id != tagList || (!ok && list.payload != nil) {
+		return nil, fmt.Errorf("Unable to read inventory: tag ID %v is not a tagList", list.id)
+	}
+
+	for i, element := range elements {
+		s, err := ItemStackFromTag(Tag{id: tagCompound, payload: element})
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read inventory element %v: %w", i, err)
+		}
+		inv = append(inv, s)
+	}
+	return inv, nil
+}
+
+// Tag returns the inventory as a named tagList of item stack compounds for the DataVersion.
+func (inv Inventory) Tag(name string, dataVersion int32) Tag {
+	var elements []any
+	for i := range inv {
+		elements = append(elements, inv[i].Tag(dataVersion).payload)
+	}
+	return Tag{id: tagList, elementID: tagCompound, name: name, payload: elements}
+}
+
+// Find returns the index of the stack in the slot. The boolean is false if the slot is empty.
+func (inv Inventory) Find(slot int8) (i int, ok bool) {
+	i = slices.IndexFunc(inv, func(s ItemStack) bool { return s.HasSlot && s.Slot == slot })
+	return i, i >= 0
+}
+
+// FreeSlot returns the lowest empty slot of an inventory with size slots. The boolean is false if every slot is used.
+func (inv Inventory) FreeSlot(size int) (slot int8, ok bool) {
+	for i := range min(size, 128) {
+		slot = int8(i) // #nosec G115 -- capped to the int8 range
+		if _, used := inv.Find(slot); !used {
+			return slot, true
+		}
+	}
+	return 0, false
+}
+
+// Merge returns a copy of the inventory with stackable stacks combined, filling earlier stacks up to maxStack items
+// before later ones. Stacks emptied by merging are removed. The given inventory is not modified.
+func (inv Inventory) Merge(maxStack int32) Inventory {
+	merged := make(Inventory, 0, len(inv))
+	for _, s := range inv {
+		for i := range merged {
+			if s.Count <= 0 {
+				break
+			}
+			if merged[i].Count < maxStack && merged[i].Stackable(s) {
+				moved := min(maxStack-merged[i].Count, s.Count)
+				merged[i].Count += moved
+				s.Count -= moved
+			}
+		}
+		if s.Count > 0 {
+			merged = append(merged, s)
+		}
+	}
+	return merged
+}
+
+// Clear returns a copy of the inventory without the stacks in the slots, or without any stacks if no slots are given.
+// The given inventory is not modified.
+func (inv Inventory) Clear(slots ...int8) Inventory {
+	if len(slots) == 0 {
+		return nil
+	}
+	return slices.DeleteFunc(slices.Clone(inv), func(s ItemStack) bool {
+		return s.HasSlot && slices.Contains(slots, s.Slot)
+	})
+}
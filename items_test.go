@@ -0,0 +1,205 @@
+package nbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestItemStackTag(t *testing.T) {
+	stack := NewItemStack("minecraft:diamond_sword", 1).WithSlot(3).WithName("Edge").WithLore("Sharp").
+		WithEnchantment("minecraft:sharpness", 4).WithEnchantment("minecraft:sharpness", 5).
+		WithComponent(Tag{id: tagInt, name: "Damage", payload: int32(7)})
+
+	tests := []struct {
+		name        string
+		dataVersion int32
+		want        Tag
+	}{
+		{
+			name:        "Test success case: before 1.20.5",
+			dataVersion: 3700,
+			want: Tag{id: tagCompound, payload: []Tag{
+				{id: tagString, name: "id", payload: "minecraft:diamond_sword"},
+				{id: tagByte, name: "Count", payload: byte(1)},
+				{id: tagByte, name: "Slot", payload: byte(3)},
+				{id: tagCompound, name: "tag", payload: []Tag{
+					{id: tagCompound, name: "display", payload: []Tag{
+						{id: tagString, name: "Name", payload: `{"text":"Edge"}`},
+						{id: tagList, elementID: tagString, name: "Lore", payload: []any{`{"text":"Sharp"}`}},
+					}},
+					{id: tagList, elementID: tagCompound, name: "Enchantments", payload: []any{[]Tag{
+						{id: tagString, name: "id", payload: "minecraft:sharpness"},
+						{id: tagShort, name: "lvl", payload: int16(5)},
+					}}},
+					{id: tagInt, name: "Damage", payload: int32(7)},
+				}},
+			}},
+		},
+		{
+			name:        "Test success case: 1.20.5 components",
+			dataVersion: ItemComponentsDataVersion,
+			want: Tag{id: tagCompound, payload: []Tag{
+				{id: tagString, name: "id", payload: "minecraft:diamond_sword"},
+				{id: tagInt, name: "count", payload: int32(1)},
+				{id: tagByte, name: "Slot", payload: byte(3)},
+				{id: tagCompound, name: "components", payload: []Tag{
+					{id: tagString, name: "minecraft:custom_name", payload: `{"text":"Edge"}`},
+					{id: tagList, elementID: tagString, name: "minecraft:lore", payload: []any{`{"text":"Sharp"}`}},
+					{id: tagCompound, name: "minecraft:enchantments", payload: []Tag{
+						{id: tagCompound, name: "levels", payload: []Tag{
+							{id: tagInt, name: "minecraft:sharpness", payload: int32(5)},
+						}},
+					}},
+					{id: tagInt, name: "Damage", payload: int32(7)},
+				}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := stack.Tag(tt.dataVersion)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+
+			reread, err := ItemStackFromTag(got)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(reread, *stack) {
+				t.Errorf("got %+v, want %+v", reread, *stack)
+			}
+		})
+	}
+}
+
+func TestItemStackFromTag(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   Tag
+		want    ItemStack
+		wantErr bool
+	}{
+		{
+			name:  "Test success case: trade item",
+			input: testItem("buy", "minecraft:emerald", 10),
+			want:  ItemStack{ID: "minecraft:emerald", Count: 10},
+		},
+		{
+			name: "Test success case: leather colour kept",
+			input: Tag{id: tagCompound, payload: []Tag{
+				{id: tagString, name: "id", payload: "minecraft:leather_helmet"},
+				{id: tagCompound, name: "tag", payload: []Tag{
+					{id: tagCompound, name: "display", payload: []Tag{{id: tagInt, name: "color", payload: int32(255)}}},
+				}},
+			}},
+			want: ItemStack{ID: "minecraft:leather_helmet", Count: 1,
+				display: []Tag{{id: tagInt, name: "color", payload: int32(255)}}},
+		},
+		{
+			name:    "Test failure case: no id",
+			input:   Tag{id: tagCompound, payload: []Tag{{id: tagInt, name: "count", payload: int32(1)}}},
+			wantErr: true,
+		},
+		{
+			name:    "Test failure case: not a compound",
+			input:   Tag{id: tagString, payload: "minecraft:stone"},
+			wantErr: true,
+		},
+		{
+			name: "Test failure case: invalid lore",
+			input: Tag{id: tagCompound, payload: []Tag{
+				{id: tagString, name: "id", payload: "minecraft:stone"},
+				{id: tagCompound, name: "components", payload: []Tag{
+					{id: tagList, elementID: tagInt, name: "minecraft:lore", payload: []any{int32(1)}},
+				}},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ItemStackFromTag(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("Test success case: leather colour written back", func(t *testing.T) {
+		s, _ := ItemStackFromTag(tests[1].input)
+		got := s.WithName("Cap").Tag(3700)
+		tag, _ := compoundChild(got, "tag")
+		display, _ := childPayload[[]Tag](tag, "display")
+		if len(display) != 2 || display[0].name != "color" || display[1].name != "Name" {
+			t.Errorf("got display %+v, want the colour and name", display)
+		}
+	})
+}
+
+func TestInventory(t *testing.T) {
+	inv := Inventory{
+		*NewItemStack("minecraft:stone", 40).WithSlot(0),
+		*NewItemStack("minecraft:dirt", 10).WithSlot(1),
+		*NewItemStack("minecraft:stone", 40).WithSlot(2),
+		*NewItemStack("minecraft:stone", 10).WithSlot(4).WithName("Named"),
+	}
+
+	t.Run("Test success case: round trip", func(t *testing.T) {
+		got, err := InventoryFromTag(inv.Tag("Items", ItemComponentsDataVersion))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(got, inv) {
+			t.Errorf("got %+v, want %+v", got, inv)
+		}
+	})
+
+	t.Run("Test success case: find and free slot", func(t *testing.T) {
+		if i, ok := inv.Find(2); !ok || i != 2 {
+			t.Errorf("got %v %v, want index 2", i, ok)
+		}
+		if _, ok := inv.Find(3); ok {
+			t.Errorf("found slot 3, want empty")
+		}
+		if slot, ok := inv.FreeSlot(27); !ok || slot != 3 {
+			t.Errorf("got %v %v, want slot 3", slot, ok)
+		}
+		if _, ok := inv.FreeSlot(3); ok {
+			t.Errorf("found a free slot in 3 full slots")
+		}
+	})
+
+	t.Run("Test success case: merge", func(t *testing.T) {
+		got := inv.Merge(64)
+		if len(got) != 4 || got[0].Count != 64 || got[2].Count != 16 || got[3].Count != 10 {
+			t.Errorf("got %+v, want stone 64 and 16 with the named stone apart", got)
+		}
+		if inv[0].Count != 40 {
+			t.Errorf("got %v in the original, want 40", inv[0].Count)
+		}
+		if got := (Inventory{inv[0], inv[2]}).Merge(80); len(got) != 1 || got[0].Count != 80 {
+			t.Errorf("got %+v, want one stack of 80", got)
+		}
+	})
+
+	t.Run("Test success case: clear", func(t *testing.T) {
+		if got := inv.Clear(1, 4); len(got) != 2 || got[1].Slot != 2 {
+			t.Errorf("got %+v, want slots 0 and 2", got)
+		}
+		if got := inv.Clear(); len(got) != 0 {
+			t.Errorf("got %+v, want empty", got)
+		}
+	})
+
+	t.Run("Test failure case: not a list", func(t *testing.T) {
+		if _, err := InventoryFromTag(Tag{id: tagCompound}); err == nil {
+			t.Errorf("got no error, want error")
+		}
+	})
+}
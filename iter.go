@@ -0,0 +1,66 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import "iter"
+
+// All returns an iterator over the names and children of a tagCompound, in order, for use with range. A tag that is
+// not a tagCompound has none. The children are shared with the tree, so must not be modified in place.
+func (t Tag) All() iter.Seq2[string, Tag] {
+	return func(yield func(string, Tag) bool) {
+		if t.id != tagCompound {
+			return
+		}
+		children, _ := t.payload.([]Tag)
+		for _, child := range children {
+			if !yield(child.name, child) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over the elements of a tagList or array tag, in order, each an unnamed tag of the listed
+// type. Any other tag has none.
+func (t Tag) Values() iter.Seq[Tag] {
+	return func(yield func(Tag) bool) {
+		for i := range collectionLen(t) {
+			if !yield(collectionElement(t, i)) {
+				return
+			}
+		}
+	}
+}
+
+// Walk returns an iterator over t and every tag below it, depth first with each compound or list before its children,
+// and the Path to each from t. The children of compounds and the elements of lists are visited, not those of arrays.
+// Each Path yielded is a new slice the caller may keep.
+func (t Tag) Walk() iter.Seq2[Path, Tag] {
+	return func(yield func(Path, Tag) bool) {
+		walkTags(Path{}, t, yield)
+	}
+}
+
+// walkTags yields t at the path then the tags below it, returning false once yield stops the iteration.
+func walkTags(path Path, t Tag, yield func(Path, Tag) bool) bool {
+	if !yield(path, t) {
+		return false
+	}
+
+	switch t.id {
+	case tagCompound:
+		for name, child := range t.All() {
+			if !walkTags(appendPath(path, name), child, yield) {
+				return false
+			}
+		}
+	case tagList:
+		i := 0
+		for element := range t.Values() {
+			if !walkTags(appendPath(path, i), element, yield) {
+				return false
+			}
+			i++
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package nbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIterators(t *testing.T) {
+	pos, _ := NewList("Pos", IDDouble, NewDouble("", 1), NewDouble("", 2))
+	data, _ := NewCompound("Data", NewString("LevelName", "World"), pos)
+	root, _ := NewCompound("", data, NewIntArray("ia", []int32{7, 8}))
+
+	t.Run("Test success case: all children of a compound", func(t *testing.T) {
+		var names []string
+		for name, child := range data.All() {
+			names = append(names, name+"="+child.String())
+		}
+		if want := []string{`LevelName="World"`, "Pos=[1d,2d]"}; !reflect.DeepEqual(names, want) {
+			t.Errorf("got %v, want %v", names, want)
+		}
+	})
+
+	t.Run("Test success case: values of a list and an array", func(t *testing.T) {
+		var got []string
+		for element := range pos.Values() {
+			got = append(got, element.String())
+		}
+		ia, _ := root.Child("ia")
+		for element := range ia.Values() {
+			got = append(got, element.String())
+		}
+		if want := []string{"1d", "2d", "7", "8"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Test success case: walk depth first", func(t *testing.T) {
+		var got []string
+		for path, tag := range root.Walk() {
+			tagType, _ := tag.tagType()
+			got = append(got, path.String()+":"+tagType)
+		}
+		want := []string{":tagCompound", "Data:tagCompound", "Data.LevelName:tagString", "Data.Pos:tagList",
+			"Data.Pos[0]:tagDouble", "Data.Pos[1]:tagDouble", "ia:tagIntArray"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Test success case: break stops early", func(t *testing.T) {
+		count := 0
+		for range root.Walk() {
+			count++
+			if count == 3 {
+				break
+			}
+		}
+		for range data.All() {
+			count++
+			break
+		}
+		for range pos.Values() {
+			count++
+			break
+		}
+		if count != 5 {
+			t.Errorf("got %v iterations, want 5", count)
+		}
+	})
+
+	t.Run("Test failure case: scalars have no children", func(t *testing.T) {
+		s := NewString("s", "x")
+		for range s.All() {
+			t.Errorf("All() yielded for a tagString")
+		}
+		for range s.Values() {
+			t.Errorf("Values() yielded for a tagString")
+		}
+	})
+}
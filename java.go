@@ -0,0 +1,111 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// JavaLevelData is the Data compound of a Java edition level.dat. The larger subtrees are kept as tags, and keys not
+// modelled here are kept, so a level read with JavaLevelDataFromTag is written back unchanged by Tag.
+type JavaLevelData struct {
+	LevelName   string
+	DataVersion int32
+	// GameRules are the game rules by name. Java edition stores every game rule value as a string, such as "true" or
+	// "3".
+	GameRules map[string]string
+	// WorldGenSettings, Player, DragonFight and CustomBossEvents are the tagCompound subtrees of the same names, zero
+	// Tags if missing. Player is present only in single player worlds.
+	WorldGenSettings Tag
+	Player           Tag
+	DragonFight      Tag
+	CustomBossEvents Tag
+
+	// extra holds any keys of Data not modelled above, and rootExtra any siblings of Data.
+	extra     []Tag
+	rootExtra []Tag
+}
+
+// JavaLevelDataFromTag reads the root compound of a Java edition level.dat, as returned by LoadLevel.
+func JavaLevelDataFromTag(t Tag) (d JavaLevelData, err error) {
+	data, ok := compoundChild(t, "Data")
+	if t.id != tagCompound || !ok || data.id != tagCompound {
+		return JavaLevelData{}, fmt.Errorf("Unable to read Java level data: no tagCompound named \"Data\"")
+	}
+
+	for _, child := range t.payload.([]Tag) {
+		if child.name != "Data" {
+			d.rootExtra = append(d.rootExtra, child)
+		}
+	}
+
+	children, _ := data.payload.([]Tag)
+	for _, child := range children {
+		switch child.name {
+		case "LevelName":
+			d.LevelName, err = tradeField[string](child)
+		case "DataVersion":
+			d.DataVersion, err = tradeField[int32](child)
+		case "GameRules":
+			d.GameRules, err = stringMap(child)
+		case "WorldGenSettings":
+			d.WorldGenSettings, err = tradeItem(child)
+		case "Player":
+			d.Player, err = tradeItem(child)
+		case "DragonFight":
+			d.DragonFight, err = tradeItem(child)
+		case "CustomBossEvents":
+			d.CustomBossEvents, err = tradeItem(child)
+		default:
+			d.extra = append(d.extra, child)
+		}
+		if err != nil {
+			return JavaLevelData{}, fmt.Errorf("Unable to read Java level data: %w", err)
+		}
+	}
+	return d, nil
+}
+
+// stringMap returns the tagString children of a tagCompound by name.
+func stringMap(t Tag) (m map[string]string, err error) {
+	if t.id != tagCompound {
+		return nil, fmt.Errorf("\"%v\" is tag ID %v, not a tagCompound", t.name, t.id)
+	}
+
+	m = map[string]string{}
+	children, _ := t.payload.([]Tag)
+	for _, child := range children {
+		m[child.name], err = tradeField[string](child)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Tag returns the level data as an unnamed root compound holding the Data compound, with the modelled keys first and
+// any other keys after. Missing subtrees and game rules are not written.
+func (d JavaLevelData) Tag() Tag {
+	children := []Tag{
+		{id: tagString, name: "LevelName", payload: d.LevelName},
+		{id: tagInt, name: "DataVersion", payload: d.DataVersion},
+	}
+	if d.GameRules != nil {
+		var rules []Tag
+		for _, name := range slices.Sorted(maps.Keys(d.GameRules)) {
+			rules = append(rules, Tag{id: tagString, name: name, payload: d.GameRules[name]})
+		}
+		children = append(children, Tag{id: tagCompound, name: "GameRules", payload: rules})
+	}
+	subtrees := []Tag{withName(d.WorldGenSettings, "WorldGenSettings"), withName(d.Player, "Player"),
+		withName(d.DragonFight, "DragonFight"), withName(d.CustomBossEvents, "CustomBossEvents")}
+	for _, subtree := range subtrees {
+		if subtree.id != tagEnd {
+			children = append(children, subtree)
+		}
+	}
+
+	data := Tag{id: tagCompound, name: "Data", payload: append(children, d.extra...)}
+	return Tag{id: tagCompound, payload: append([]Tag{data}, d.rootExtra...)}
+}
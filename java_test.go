@@ -0,0 +1,64 @@
+package nbt
+
+import "testing"
+
+func TestJavaLevelData(t *testing.T) {
+	level := Tag{id: tagCompound, payload: []Tag{{id: tagCompound, name: "Data", payload: []Tag{
+		{id: tagString, name: "LevelName", payload: "New World"},
+		{id: tagInt, name: "DataVersion", payload: int32(3465)},
+		{id: tagCompound, name: "GameRules", payload: []Tag{
+			{id: tagString, name: "doDaylightCycle", payload: "false"},
+			{id: tagString, name: "randomTickSpeed", payload: "3"},
+		}},
+		{id: tagCompound, name: "WorldGenSettings", payload: []Tag{{id: tagLong, name: "seed", payload: int64(7)}}},
+		{id: tagCompound, name: "Player", payload: []Tag{{id: tagFloat, name: "Health", payload: float32(20)}}},
+		{id: tagCompound, name: "DragonFight", payload: []Tag{{id: tagByte, name: "DragonKilled", payload: byte(1)}}},
+		{id: tagLong, name: "Time", payload: int64(24000)},
+	}}}}
+
+	got, err := JavaLevelDataFromTag(level)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got.LevelName != "New World" || got.DataVersion != 3465 || got.GameRules["randomTickSpeed"] != "3" {
+		t.Errorf("got %+v, want the modelled keys", got)
+	}
+	if seed, _ := childPayload[int64](got.WorldGenSettings, "seed"); seed != 7 || got.CustomBossEvents.id != tagEnd {
+		t.Errorf("got %+v, want seed 7 and no custom boss events", got)
+	}
+
+	t.Run("Test success case: round trip keeps unknown keys", func(t *testing.T) {
+		if written := got.Tag(); !payloadsEqual(written.payload, level.payload) {
+			t.Errorf("got %+v, want %+v", written, level)
+		}
+	})
+
+	t.Run("Test success case: edit game rule", func(t *testing.T) {
+		edited := got
+		edited.GameRules = map[string]string{"keepInventory": "true"}
+		if rule, err := lookup(edited.Tag(), Path{"Data", "GameRules", "keepInventory"}); err != nil ||
+			rule.payload != "true" {
+			t.Errorf("got %+v %v, want keepInventory true", rule, err)
+		}
+	})
+
+	failureCases := []struct {
+		name  string
+		input Tag
+	}{
+		{"no Data", Tag{id: tagCompound}},
+		{"game rule not a string", Tag{id: tagCompound, payload: []Tag{{id: tagCompound, name: "Data", payload: []Tag{
+			{id: tagCompound, name: "GameRules", payload: []Tag{{id: tagByte, name: "pvp", payload: byte(1)}}},
+		}}}}},
+		{"player not a compound", Tag{id: tagCompound, payload: []Tag{{id: tagCompound, name: "Data", payload: []Tag{
+			{id: tagString, name: "Player", payload: "Steve"},
+		}}}}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			if _, err := JavaLevelDataFromTag(failureCase.input); err == nil {
+				t.Errorf("got no error, want error")
+			}
+		})
+	}
+}
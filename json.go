@@ -0,0 +1,524 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// JSONMode selects the form of JSON written by ToJSON and read by FromJSON.
+type JSONMode int
+
+// JSONMode values.
+const (
+	// JSONPlain is JSON of the payloads alone, for casual inspection: compounds are objects, lists and arrays are
+	// arrays, bytes are numbers from -128 to 127, and NaN and infinite floats are the strings "NaN", "Infinity" and
+	// "-Infinity". The root's name and the types of numbers and arrays are lost, so FromJSON can only infer them: a
+	// whole number is a tagInt, or a tagLong if it overflows 32 bits, any other number is a tagDouble, a boolean is a
+	// tagByte, and an array is a tagList whose numeric elements are widened to the widest of their types.
+	JSONPlain JSONMode = iota
+	// JSONTyped is JSON recording the type of every tag, which round-trips to identical NBT. Each tag is an object
+	// {"type":"tagInt","value":1}, typed by the names of the tag types, with the elements of a tagList as tag objects
+	// and its element type as "elementType", and the children of a tagCompound as members named by their names. The
+	// root also records its "name". The value forms are those of JSONPlain, except a NaN float is lost to the quiet NaN.
+	JSONTyped
+)
+
+// ToJSON returns the tag as JSON of the mode. Compound children are written in order. Tags with IDs above
+// tagLongArray, having no JSON form, are an error.
+func ToJSON(t Tag, mode JSONMode) (data []byte, err error) {
+	var b bytes.Buffer
+	if mode == JSONTyped {
+		err = writeTypedJSON(&b, t, true)
+	} else {
+		err = writeJSONPayload(&b, payloadOrEmpty(t), false)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to convert tag \"%v\" to JSON: %w", t.name, err)
+	}
+	return b.Bytes(), nil
+}
+
+// FromJSON returns the tag held by the JSON of the mode. Object members are read in order, so compound children keep
+// the order written. A JSONPlain tag is unnamed. JSON null has no NBT form and is an error.
+func FromJSON(data []byte, mode JSONMode) (t Tag, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	value, err := readJSONValue(decoder)
+	if err == nil {
+		if _, extraErr := decoder.Token(); extraErr != io.EOF {
+			err = fmt.Errorf("data after the top-level value")
+		}
+	}
+	if err != nil {
+		return Tag{}, fmt.Errorf("Unable to parse JSON: %w", err)
+	}
+
+	if mode == JSONTyped {
+		t, err = fromTypedJSON(value, true)
+	} else {
+		t, err = fromPlainJSON(value)
+	}
+	if err != nil {
+		return Tag{}, fmt.Errorf("Unable to convert JSON to a tag: %w", err)
+	}
+	return t, nil
+}
+
+// MarshalJSON implements json.Marshaler, returning the tag as JSONTyped JSON so it can be embedded in larger JSON
+// documents and read back by UnmarshalJSON unchanged.
+func (t Tag) MarshalJSON() ([]byte, error) {
+	return ToJSON(t, JSONTyped)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, setting the tag from JSONTyped JSON as written by MarshalJSON.
+func (t *Tag) UnmarshalJSON(data []byte) (err error) {
+	parsed, err := FromJSON(data, JSONTyped)
+	if err != nil {
+		return err
+	}
+
+	*t = parsed
+	return nil
+}
+
+// writeTypedJSON writes the tag as a JSON tag object, with its name if it is the root.
+func writeTypedJSON(b *bytes.Buffer, t Tag, root bool) (err error) {
+	tagType, err := t.tagType()
+	if err != nil || t.id == tagEnd {
+		return fmt.Errorf("tag \"%v\" has no JSON form: tag ID %v", t.name, t.id)
+	}
+
+	b.WriteByte('{')
+	if root {
+		b.WriteString(`"name":`)
+		writeJSONString(b, t.name)
+		b.WriteByte(',')
+	}
+	b.WriteString(`"type":`)
+	writeJSONString(b, tagType)
+	if t.id == tagList {
+		elementType, _ := (&Tag{id: t.ElementID()}).tagType()
+		b.WriteString(`,"elementType":`)
+		writeJSONString(b, elementType)
+	}
+	b.WriteString(`,"value":`)
+	if err = writeJSONPayload(b, payloadOrEmpty(t), true); err != nil {
+		return err
+	}
+	b.WriteByte('}')
+	return nil
+}
+
+// writeJSONPayload writes a payload in its JSON value form, compound children and list elements as tag objects if
+// typed.
+func writeJSONPayload(b *bytes.Buffer, payload any, typed bool) (err error) {
+	switch p := payload.(type) {
+	case byte:
+		b.WriteString(strconv.Itoa(int(int8(p)))) // #nosec G115 -- tagByte is signed
+	case int16:
+		b.WriteString(strconv.Itoa(int(p)))
+	case int32:
+		b.WriteString(strconv.Itoa(int(p)))
+	case int64:
+		b.WriteString(strconv.FormatInt(p, 10))
+	case float32:
+		writeJSONFloat(b, float64(p), 32)
+	case float64:
+		writeJSONFloat(b, p, 64)
+	case string:
+		writeJSONString(b, p)
+	case []byte:
+		writeJSONArray(b, p, func(e byte) string { return strconv.Itoa(int(int8(e))) }) // #nosec G115 -- signed
+	case []int32:
+		writeJSONArray(b, p, func(e int32) string { return strconv.Itoa(int(e)) })
+	case []int64:
+		writeJSONArray(b, p, func(e int64) string { return strconv.FormatInt(e, 10) })
+	case []any:
+		b.WriteByte('[')
+		for i, element := range p {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if typed {
+				id, _ := payloadID(element)
+				err = writeTypedJSON(b, Tag{id: id, payload: element}, false)
+			} else {
+				err = writeJSONPayload(b, element, false)
+			}
+			if err != nil {
+				return fmt.Errorf("element %v: %w", i, err)
+			}
+		}
+		b.WriteByte(']')
+	case []Tag:
+		b.WriteByte('{')
+		for i, child := range p {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeJSONString(b, child.name)
+			b.WriteByte(':')
+			if typed {
+				err = writeTypedJSON(b, child, false)
+			} else {
+				err = writeJSONPayload(b, payloadOrEmpty(child), false)
+			}
+			if err != nil {
+				return fmt.Errorf("child \"%v\": %w", child.name, err)
+			}
+		}
+		b.WriteByte('}')
+	default:
+		return fmt.Errorf("payload type %T has no JSON form", payload)
+	}
+	return nil
+}
+
+// writeJSONFloat writes a float of the bit size as a JSON number, or as a string for NaN and infinities.
+func writeJSONFloat(b *bytes.Buffer, f float64, bitSize int) {
+	switch {
+	case math.IsNaN(f):
+		b.WriteString(`"NaN"`)
+	case math.IsInf(f, 1):
+		b.WriteString(`"Infinity"`)
+	case math.IsInf(f, -1):
+		b.WriteString(`"-Infinity"`)
+	default:
+		b.WriteString(strconv.FormatFloat(f, 'g', -1, bitSize))
+	}
+}
+
+// writeJSONString writes a JSON string, leaving the HTML characters <, > and & unescaped.
+func writeJSONString(b *bytes.Buffer, s string) {
+	encoder := json.NewEncoder(b)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)   // #nosec G104 -- a string always encodes
+	b.Truncate(b.Len() - 1) // Encode ends the value with a newline
+}
+
+// writeJSONArray writes the elements of an array as a JSON array, formatting each element.
+func writeJSONArray[E byte | int32 | int64](b *bytes.Buffer, elements []E, format func(E) string) {
+	b.WriteByte('[')
+	for i, element := range elements {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(format(element))
+	}
+	b.WriteByte(']')
+}
+
+// jsonMember is a member of a JSON object, kept in order as read.
+type jsonMember struct {
+	name  string
+	value any
+}
+
+// readJSONValue reads the next JSON value from the decoder: a json.Number, string, bool, nil, []any of values, or
+// []jsonMember for an object.
+func readJSONValue(decoder *json.Decoder) (value any, err error) {
+	token, err := decoder.Token()
+	if err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('['):
+		elements := []any{}
+		for decoder.More() {
+			element, err := readJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, element)
+		}
+		_, err = decoder.Token()
+		return elements, err
+	case json.Delim('{'):
+		members := []jsonMember{}
+		for decoder.More() {
+			name, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := readJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, jsonMember{name: name.(string), value: value})
+		}
+		_, err = decoder.Token()
+		return members, err
+	default:
+		return token, nil
+	}
+}
+
+// fromPlainJSON returns the unnamed tag inferred from a JSONPlain value.
+func fromPlainJSON(value any) (t Tag, err error) {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			if i >= math.MinInt32 && i <= math.MaxInt32 {
+				return Tag{id: tagInt, payload: int32(i)}, nil
+			}
+			return Tag{id: tagLong, payload: i}, nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return Tag{}, fmt.Errorf("number %v out of range", v)
+		}
+		return Tag{id: tagDouble, payload: f}, nil
+	case string:
+		return Tag{id: tagString, payload: v}, nil
+	case bool:
+		var b byte
+		if v {
+			b = 1
+		}
+		return Tag{id: tagByte, payload: b}, nil
+	case []any:
+		elements := make([]Tag, len(v))
+		for i, element := range v {
+			if elements[i], err = fromPlainJSON(element); err != nil {
+				return Tag{}, fmt.Errorf("element %v: %w", i, err)
+			}
+		}
+		return inferList(elements)
+	case []jsonMember:
+		children := make([]Tag, len(v))
+		for i, member := range v {
+			if children[i], err = fromPlainJSON(member.value); err != nil {
+				return Tag{}, fmt.Errorf("member \"%v\": %w", member.name, err)
+			}
+			children[i].name = member.name
+		}
+		return Tag{id: tagCompound, payload: children}, nil
+	default:
+		return Tag{}, fmt.Errorf("null has no NBT form")
+	}
+}
+
+// inferList returns a tagList of elements whose types were inferred, widening numeric elements of mixed types to the
+// widest of tagInt, tagLong and tagDouble.
+func inferList(elements []Tag) (t Tag, err error) {
+	var id uint8
+	for i, element := range elements {
+		switch {
+		case i == 0 || element.id == id:
+			id = element.id
+		case isInferredNumber(id) && isInferredNumber(element.id):
+			id = max(id, element.id)
+		default:
+			return Tag{}, fmt.Errorf("list mixes tag ID %v and %v elements", id, element.id)
+		}
+	}
+
+	payload := make([]any, len(elements))
+	for i, element := range elements {
+		payload[i] = element.payload
+		switch p := element.payload.(type) {
+		case int32:
+			if id == tagLong {
+				payload[i] = int64(p)
+			} else if id == tagDouble {
+				payload[i] = float64(p)
+			}
+		case int64:
+			if id == tagDouble {
+				payload[i] = float64(p)
+			}
+		}
+	}
+	return Tag{id: tagList, elementID: id, payload: payload}, nil
+}
+
+// isInferredNumber reports whether the tag ID is one inferred for an untyped number, ordered from narrowest to
+// widest.
+func isInferredNumber(id uint8) bool {
+	return id == tagInt || id == tagLong || id == tagDouble
+}
+
+// fromTypedJSON returns the tag held by a JSONTyped tag object, named by its "name" member if it is the root.
+func fromTypedJSON(value any, root bool) (t Tag, err error) {
+	members, ok := value.([]jsonMember)
+	if !ok {
+		return Tag{}, fmt.Errorf("tag is not an object")
+	}
+
+	var typeName, elementTypeName, name any
+	var payload any
+	for _, member := range members {
+		switch member.name {
+		case "type":
+			typeName = member.value
+		case "elementType":
+			elementTypeName = member.value
+		case "name":
+			name = member.value
+		case "value":
+			payload = member.value
+		default:
+			return Tag{}, fmt.Errorf("unknown member \"%v\"", member.name)
+		}
+	}
+
+	if t.id, err = jsonTagID(typeName); err != nil {
+		return Tag{}, err
+	}
+	if t.id == tagEnd {
+		return Tag{}, fmt.Errorf("tagEnd has no JSON form")
+	}
+	if root && name != nil {
+		if t.name, ok = name.(string); !ok {
+			return Tag{}, fmt.Errorf("name is not a string")
+		}
+	}
+	if t.id == tagList {
+		if t.elementID, err = jsonTagID(elementTypeName); err != nil {
+			return Tag{}, fmt.Errorf("element type: %w", err)
+		}
+	}
+
+	if t.payload, err = typedJSONPayload(t.id, t.elementID, payload); err != nil {
+		tagType, _ := t.tagType()
+		return Tag{}, fmt.Errorf("%v value: %w", tagType, err)
+	}
+	return t, nil
+}
+
+// jsonTagID returns the tag ID of a tag type name, such as "tagInt".
+func jsonTagID(typeName any) (id uint8, err error) {
+	name, ok := typeName.(string)
+	if !ok {
+		return 0, fmt.Errorf("type is missing or not a string")
+	}
+	for id = tagEnd; id <= tagLongArray; id++ {
+		if tagType, _ := (&Tag{id: id}).tagType(); tagType == name {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown type \"%v\"", name)
+}
+
+// typedJSONPayload returns the payload of the tag ID, and for a tagList the element ID, from its JSONTyped value.
+func typedJSONPayload(id, elementID uint8, value any) (payload any, err error) {
+	switch id {
+	case tagByte:
+		i, err := jsonInteger(value, math.MinInt8, math.MaxInt8)
+		return byte(i), err // #nosec G115 -- checked by jsonInteger
+	case tagShort:
+		i, err := jsonInteger(value, math.MinInt16, math.MaxInt16)
+		return int16(i), err // #nosec G115 -- checked by jsonInteger
+	case tagInt:
+		i, err := jsonInteger(value, math.MinInt32, math.MaxInt32)
+		return int32(i), err // #nosec G115 -- checked by jsonInteger
+	case tagLong:
+		return jsonInteger(value, math.MinInt64, math.MaxInt64)
+	case tagFloat:
+		f, err := jsonFloat(value, 32)
+		return float32(f), err
+	case tagDouble:
+		return jsonFloat(value, 64)
+	case tagString:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("not a string")
+		}
+		return s, nil
+	case tagByteArray:
+		return jsonArray(value, func(e any) (byte, error) {
+			i, err := jsonInteger(e, math.MinInt8, math.MaxInt8)
+			return byte(i), err // #nosec G115 -- checked by jsonInteger
+		})
+	case tagIntArray:
+		return jsonArray(value, func(e any) (int32, error) {
+			i, err := jsonInteger(e, math.MinInt32, math.MaxInt32)
+			return int32(i), err // #nosec G115 -- checked by jsonInteger
+		})
+	case tagLongArray:
+		return jsonArray(value, func(e any) (int64, error) {
+			return jsonInteger(e, math.MinInt64, math.MaxInt64)
+		})
+	case tagList:
+		return jsonArray(value, func(e any) (any, error) {
+			element, err := fromTypedJSON(e, false)
+			if err == nil && element.id != elementID {
+				err = fmt.Errorf("element tag ID %v is not the listed %v", element.id, elementID)
+			}
+			return element.payload, err
+		})
+	default:
+		members, ok := value.([]jsonMember)
+		if !ok {
+			return nil, fmt.Errorf("not an object")
+		}
+		children := make([]Tag, len(members))
+		for i, member := range members {
+			if children[i], err = fromTypedJSON(member.value, false); err != nil {
+				return nil, fmt.Errorf("member \"%v\": %w", member.name, err)
+			}
+			children[i].name = member.name
+		}
+		return children, nil
+	}
+}
+
+// jsonInteger returns a JSON number as an integer between low and high inclusive.
+func jsonInteger(value any, low, high int64) (i int64, err error) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("%v is not a number", value)
+	}
+	i, err = number.Int64()
+	if err != nil || i < low || i > high {
+		return 0, fmt.Errorf("%v is not an integer between %v and %v", number, low, high)
+	}
+	return i, nil
+}
+
+// jsonFloat returns a JSON number, or the string "NaN", "Infinity" or "-Infinity", as a float of the bit size.
+func jsonFloat(value any, bitSize int) (f float64, err error) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err = strconv.ParseFloat(string(v), bitSize)
+		if err != nil {
+			return 0, fmt.Errorf("%v is out of range", v)
+		}
+		return f, nil
+	case string:
+		switch v {
+		case "NaN":
+			return math.NaN(), nil
+		case "Infinity":
+			return math.Inf(1), nil
+		case "-Infinity":
+			return math.Inf(-1), nil
+		}
+	}
+	return 0, fmt.Errorf("%v is not a number", value)
+}
+
+// jsonArray returns the elements of a JSON array, each converted.
+func jsonArray[E any](value any, convert func(any) (E, error)) (elements []E, err error) {
+	values, ok := value.([]any)
+	if !ok {
+		return nil, errors.New("not an array")
+	}
+	elements = make([]E, len(values))
+	for i, v := range values {
+		if elements[i], err = convert(v); err != nil {
+			return nil, fmt.Errorf("element %v: %w", i, err)
+		}
+	}
+	return elements, nil
+}
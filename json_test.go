@@ -0,0 +1,164 @@
+package nbt
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestToJSON(t *testing.T) {
+	pos, _ := NewList("Pos", IDDouble, NewDouble("", 1.5), NewDouble("", math.Inf(-1)))
+	empty, _ := NewList("Empty", IDInt)
+	level, _ := NewCompound("Data", NewByte("hardcore", 0xFF), NewShort("s", 2), NewLong("Seed", math.MaxInt64),
+		NewFloat("f", 0.1), NewString("Name", `say "<hi>"`), NewByteArray("ba", []byte{1, 0x80}),
+		NewIntArray("ia", []int32{3}), NewLongArray("la", nil), pos, empty)
+
+	successCases := []struct {
+		name string
+		want string
+		tag  Tag
+		mode JSONMode
+	}{
+		{"plain", `{"hardcore":-1,"s":2,"Seed":9223372036854775807,"f":0.1,"Name":"say \"<hi>\"",` +
+			`"ba":[1,-128],"ia":[3],"la":[],"Pos":[1.5,"-Infinity"],"Empty":[]}`, level, JSONPlain},
+		{"typed scalar", `{"name":"i","type":"tagInt","value":7}`, NewInt("i", 7), JSONTyped},
+		{"typed list", `{"name":"Pos","type":"tagList","elementType":"tagDouble","value":[` +
+			`{"type":"tagDouble","value":1.5},{"type":"tagDouble","value":"-Infinity"}]}`, pos, JSONTyped},
+		{"typed compound", `{"name":"","type":"tagCompound","value":{"b":{"type":"tagByte","value":1}}}`,
+			Tag{id: tagCompound, payload: []Tag{NewByte("b", 1)}}, JSONTyped},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			got, gotErr := ToJSON(successCase.tag, successCase.mode)
+			if string(got) != successCase.want || gotErr != nil {
+				t.Errorf("got %s %v, want %v", got, gotErr, successCase.want)
+			}
+		})
+	}
+
+	t.Run("Test success case: typed round trip is identical", func(t *testing.T) {
+		data, err := ToJSON(level, JSONTyped)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got, err := FromJSON(data, JSONTyped)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var want, gotBytes bytes.Buffer
+		if err = WriteTag(&want, level); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err = WriteTag(&gotBytes, got); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !bytes.Equal(gotBytes.Bytes(), want.Bytes()) {
+			t.Errorf("got %v, want %v", got, level)
+		}
+	})
+
+	t.Run("Test failure case: unknown tag", func(t *testing.T) {
+		if _, err := ToJSON(Tag{id: 20, payload: RawPayload{1}}, JSONTyped); err == nil {
+			t.Errorf("got nil, want non-nil")
+		}
+	})
+}
+
+func TestFromJSON(t *testing.T) {
+	successCases := []struct {
+		name string
+		want string
+		data string
+		mode JSONMode
+	}{
+		{"plain numbers", "[1,2,3]", `[1, 2, 3]`, JSONPlain},
+		{"plain widened numbers", "[1L,4294967296L]", `[1, 4294967296]`, JSONPlain},
+		{"plain doubles", "[1d,2.5d]", `[1, 2.5]`, JSONPlain},
+		{"plain compound in order", `{z:1b,a:"x",l:[],c:{}}`, `{"z": true, "a": "x", "l": [], "c": {}}`, JSONPlain},
+		{"typed", `{b:-1b,s:2s,f:0.5f,ia:[I;1,2],l:[]}`, `{"type": "tagCompound", "value": {` +
+			`"b": {"type": "tagByte", "value": -1}, "s": {"type": "tagShort", "value": 2},` +
+			`"f": {"type": "tagFloat", "value": 0.5}, "ia": {"type": "tagIntArray", "value": [1, 2]},` +
+			`"l": {"type": "tagList", "elementType": "tagString", "value": []}}}`, JSONTyped},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			got, gotErr := FromJSON([]byte(successCase.data), successCase.mode)
+			if got.String() != successCase.want || gotErr != nil {
+				t.Errorf("got %v %v, want %v", got, gotErr, successCase.want)
+			}
+		})
+	}
+
+	t.Run("Test success case: typed name and empty list element type", func(t *testing.T) {
+		got, err := FromJSON([]byte(`{"name":"Tags","type":"tagList","elementType":"tagString","value":[]}`),
+			JSONTyped)
+		if err != nil || got.name != "Tags" || got.ElementID() != tagString {
+			t.Errorf("got %#v %v", got, err)
+		}
+	})
+
+	failureCases := []struct {
+		name string
+		data string
+		mode JSONMode
+	}{
+		{"invalid JSON", `{"a":`, JSONPlain},
+		{"trailing data", `1 2`, JSONPlain},
+		{"null", `{"a":null}`, JSONPlain},
+		{"plain mixed array", `[1, "a"]`, JSONPlain},
+		{"typed missing type", `{"value":1}`, JSONTyped},
+		{"typed unknown type", `{"type":"tagNumber","value":1}`, JSONTyped},
+		{"typed byte out of range", `{"type":"tagByte","value":128}`, JSONTyped},
+		{"typed fractional int", `{"type":"tagInt","value":1.5}`, JSONTyped},
+		{"typed element of another type", `{"type":"tagList","elementType":"tagInt","value":[` +
+			`{"type":"tagLong","value":1}]}`, JSONTyped},
+		{"typed unknown member", `{"type":"tagInt","value":1,"extra":2}`, JSONTyped},
+		{"typed end", `{"type":"tagEnd"}`, JSONTyped},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			if _, gotErr := FromJSON([]byte(failureCase.data), failureCase.mode); gotErr == nil {
+				t.Errorf("got nil, want non-nil")
+			}
+		})
+	}
+}
+
+func TestTagJSON(t *testing.T) {
+	type response struct {
+		Player Tag   `json:"player"`
+		Items  []Tag `json:"items"`
+	}
+	player, _ := NewCompound("", NewString("Name", "Steve"), NewFloat("Health", 20))
+	want := response{Player: player, Items: []Tag{NewByte("Count", 1), NewLongArray("la", []int64{1})}}
+
+	t.Run("Test success case: embedded in a struct", func(t *testing.T) {
+		data, err := json.Marshal(want)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		wantJSON := `{"player":{"name":"","type":"tagCompound","value":{"Name":{"type":"tagString","value":"Steve"},` +
+			`"Health":{"type":"tagFloat","value":20}}},"items":[{"name":"Count","type":"tagByte","value":1},` +
+			`{"name":"la","type":"tagLongArray","value":[1]}]}`
+		if string(data) != wantJSON {
+			t.Errorf("got %s, want %v", data, wantJSON)
+		}
+
+		var got response
+		if err = json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Test failure case: plain JSON", func(t *testing.T) {
+		var got Tag
+		if err := json.Unmarshal([]byte(`{"Name":"Steve"}`), &got); err == nil {
+			t.Errorf("got nil, want non-nil")
+		}
+	})
+}
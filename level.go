@@ -0,0 +1,57 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+)
+
+// Names of the level files in a Java edition world directory. The game keeps the previous level.dat as level.dat_old
+// each time it saves.
+const (
+	levelName    = "level.dat"
+	levelOldName = "level.dat_old"
+)
+
+// LoadLevel decodes the level.dat file of the world directory, which may be gzip compressed. If it fails to decode and
+// fallback is true, level.dat_old is decoded instead, as the game does, and usedFallback reports that it was. The
+// returned error wraps the errors of both files when neither decodes. The options configure decoding.
+func LoadLevel(dir string, fallback bool, opts ...Option) (t Tag, usedFallback bool, err error) {
+	return loadLevel(func(name string) (Tag, error) {
+		return ReadFile(filepath.Join(dir, name), opts...)
+	}, fallback)
+}
+
+// LoadLevelFS decodes the level.dat file at the root of the file system holding a world, as LoadLevel does for a
+// directory, such as to read a world from a zip archive.
+func LoadLevelFS(fsys fs.FS, fallback bool, opts ...Option) (t Tag, usedFallback bool, err error) {
+	return loadLevel(func(name string) (Tag, error) {
+		file, err := fsys.Open(name)
+		if err != nil {
+			return Tag{}, err
+		}
+		defer file.Close()
+
+		t, _, err := ReadCompressed(file, opts...)
+		return t, err
+	}, fallback)
+}
+
+// loadLevel decodes level.dat, or level.dat_old if it fails to decode and fallback is true, with read.
+func loadLevel(read func(name string) (Tag, error), fallback bool) (t Tag, usedFallback bool, err error) {
+	t, err = read(levelName)
+	if err == nil {
+		return t, false, nil
+	}
+	if !fallback {
+		return Tag{}, false, fmt.Errorf("Unable to load %v: %w", levelName, err)
+	}
+
+	t, oldErr := read(levelOldName)
+	if oldErr != nil {
+		return Tag{}, false, fmt.Errorf("Unable to load %v or %v: %w", levelName, levelOldName, errors.Join(err, oldErr))
+	}
+	return t, true, nil
+}
@@ -0,0 +1,100 @@
+package nbt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadLevel(t *testing.T) {
+	level := []byte{tagCompound, 0, 0, tagByte, 0, 1, 'a', 1, tagEnd}
+	levelOld := []byte{tagCompound, 0, 0, tagByte, 0, 1, 'a', 2, tagEnd}
+	corrupt := []byte{0x1F, 0x8B, 0x08}
+	loaders := []struct {
+		name string
+		load func(dir string, fallback bool) (Tag, bool, error)
+	}{
+		{"LoadLevel", func(dir string, fallback bool) (Tag, bool, error) { return LoadLevel(dir, fallback) }},
+		{"LoadLevelFS", func(dir string, fallback bool) (Tag, bool, error) {
+			return LoadLevelFS(os.DirFS(dir), fallback)
+		}},
+	}
+
+	successCases := []struct {
+		name         string
+		level        []byte
+		levelOld     []byte
+		fallback     bool
+		wantPayload  byte
+		wantFallback bool
+	}{
+		{"level.dat decodes", level, levelOld, true, 1, false},
+		{"level.dat decodes without fallback", level, nil, false, 1, false},
+		{"corrupt level.dat falls back", corrupt, levelOld, true, 2, true},
+		{"missing level.dat falls back", nil, levelOld, true, 2, true},
+	}
+	for _, loader := range loaders {
+		for _, successCase := range successCases {
+			t.Run("Test success case: "+loader.name+" "+successCase.name, func(t *testing.T) {
+				dir := t.TempDir()
+				writeLevelFiles(t, dir, successCase.level, successCase.levelOld)
+
+				got, gotFallback, err := loader.load(dir, successCase.fallback)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if gotFallback != successCase.wantFallback {
+					t.Errorf("got fallback %v, want %v", gotFallback, successCase.wantFallback)
+				}
+				if child, _ := compoundChild(got, "a"); child.payload != successCase.wantPayload {
+					t.Errorf("got %v, want %v", child.payload, successCase.wantPayload)
+				}
+			})
+		}
+	}
+
+	failureCases := []struct {
+		name     string
+		level    []byte
+		levelOld []byte
+		fallback bool
+	}{
+		{"corrupt level.dat without fallback", corrupt, levelOld, false},
+		{"both corrupt", corrupt, corrupt, true},
+		{"both missing", nil, nil, true},
+	}
+	for _, loader := range loaders {
+		for _, failureCase := range failureCases {
+			t.Run("Test failure case: "+loader.name+" "+failureCase.name, func(t *testing.T) {
+				dir := t.TempDir()
+				writeLevelFiles(t, dir, failureCase.level, failureCase.levelOld)
+
+				_, gotFallback, err := loader.load(dir, failureCase.fallback)
+				if err == nil {
+					t.Errorf("Expected error, got nil")
+				}
+				if gotFallback {
+					t.Errorf("got fallback true, want false")
+				}
+			})
+		}
+	}
+}
+
+// writeLevelFiles writes the level files to the directory, gzipping those that are not already corrupt gzip data and
+// skipping any that are nil.
+func writeLevelFiles(t *testing.T, dir string, level, levelOld []byte) {
+	t.Helper()
+	for name, content := range map[string][]byte{levelName: level, levelOldName: levelOld} {
+		switch {
+		case content == nil:
+		case content[0] == 0x1F:
+			if err := os.WriteFile(filepath.Join(dir, name), content, 0o600); err != nil {
+				t.Fatalf("Unable to write test file: %v", err)
+			}
+		default:
+			writeGzipFile(t, filepath.Join(dir, name), content, time.Now())
+		}
+	}
+}
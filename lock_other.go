@@ -0,0 +1,16 @@
+//go:build !unix
+
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import "os"
+
+// lockFile does not lock the file. Without POSIX record locks, LockWorld relies on a running game refusing the write.
+func lockFile(*os.File) error {
+	return nil
+}
+
+// unlockFile does nothing, as lockFile takes no lock.
+func unlockFile(*os.File) error {
+	return nil
+}
@@ -0,0 +1,64 @@
+package nbt
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLockWorld(t *testing.T) {
+	t.Run("Test success case: lock creates session.lock", func(t *testing.T) {
+		dir := t.TempDir()
+		l, gotErr := LockWorld(dir)
+		if gotErr != nil {
+			t.Fatalf("got %v, want nil", gotErr)
+		}
+
+		content, _ := os.ReadFile(filepath.Join(dir, "session.lock"))
+		if string(content) != "☃" {
+			t.Errorf("got %q, want %q", content, "☃")
+		}
+
+		gotErr = l.Unlock()
+		if gotErr != nil {
+			t.Errorf("got %v, want nil", gotErr)
+		}
+	})
+
+	t.Run("Test success case: check unlocked world", func(t *testing.T) {
+		gotErr := CheckWorldLock(t.TempDir())
+		if gotErr != nil {
+			t.Errorf("got %v, want nil", gotErr)
+		}
+	})
+
+	t.Run("Test failure case: world locked by this process", func(t *testing.T) {
+		dir := t.TempDir()
+		l, err := LockWorld(dir)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		// The same directory by another path is the same world.
+		if _, gotErr := LockWorld(filepath.Join(dir, ".")); !errors.Is(gotErr, ErrWorldLocked) {
+			t.Errorf("got %v, want %v", gotErr, ErrWorldLocked)
+		}
+		if gotErr := CheckWorldLock(dir); !errors.Is(gotErr, ErrWorldLocked) {
+			t.Errorf("got %v, want %v", gotErr, ErrWorldLocked)
+		}
+
+		if err = l.Unlock(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if gotErr := CheckWorldLock(dir); gotErr != nil {
+			t.Errorf("got %v, want nil once unlocked", gotErr)
+		}
+	})
+
+	t.Run("Test failure case: missing world directory", func(t *testing.T) {
+		_, gotErr := LockWorld(filepath.Join(t.TempDir(), "missing"))
+		if gotErr == nil {
+			t.Errorf("got nil, want non-nil")
+		}
+	})
+}
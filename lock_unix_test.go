@@ -0,0 +1,131 @@
+//go:build unix
+
+package nbt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+// TestLockWorldHelperProcess holds a world lock in a separate process for TestLockWorldOtherProcess, as POSIX record
+// locks never conflict within a single process.
+func TestLockWorldHelperProcess(t *testing.T) {
+	dir := os.Getenv("NBT_LOCK_WORLD_HELPER")
+	if dir == "" {
+		t.Skip("helper process only")
+	}
+
+	l, err := LockWorld(dir)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Println("locked")
+
+	_, _ = bufio.NewReader(os.Stdin).ReadString('\n')
+	_ = l.Unlock()
+	os.Exit(0)
+}
+
+func TestLockWorldOtherProcess(t *testing.T) {
+	dir := t.TempDir()
+
+	helper := exec.Command(os.Args[0], "-test.run=^TestLockWorldHelperProcess$") // #nosec G204 -- the test binary
+	helper.Env = append(os.Environ(), "NBT_LOCK_WORLD_HELPER="+dir)
+	stdin, _ := helper.StdinPipe()
+	stdout, _ := helper.StdoutPipe()
+	if err := helper.Start(); err != nil {
+		t.Fatalf("Unable to start helper process: %v", err)
+	}
+
+	line, _ := bufio.NewReader(stdout).ReadString('\n')
+	if line != "locked\n" {
+		t.Fatalf("got %q from helper process, want locked", line)
+	}
+
+	t.Run("Test failure case: world locked by another process", func(t *testing.T) {
+		_, gotErr := LockWorld(dir)
+		if !errors.Is(gotErr, ErrWorldLocked) {
+			t.Errorf("got %v, want %v", gotErr, ErrWorldLocked)
+		}
+		gotErr = CheckWorldLock(dir)
+		if !errors.Is(gotErr, ErrWorldLocked) {
+			t.Errorf("got %v, want %v", gotErr, ErrWorldLocked)
+		}
+	})
+
+	_ = stdin.Close()
+	_ = helper.Wait()
+
+	t.Run("Test success case: world unlocked by other process exiting", func(t *testing.T) {
+		gotErr := CheckWorldLock(dir)
+		if gotErr != nil {
+			t.Errorf("got %v, want nil", gotErr)
+		}
+	})
+}
+
+// TestLockWorldProbeProcess reports from a separate process whether the world is locked, for
+// TestLockWorldSameProcess.
+func TestLockWorldProbeProcess(t *testing.T) {
+	dir := os.Getenv("NBT_LOCK_WORLD_PROBE")
+	if dir == "" {
+		t.Skip("probe process only")
+	}
+
+	if errors.Is(CheckWorldLock(dir), ErrWorldLocked) {
+		fmt.Println("locked")
+	} else {
+		fmt.Println("free")
+	}
+	os.Exit(0)
+}
+
+func TestLockWorldSameProcess(t *testing.T) {
+	dir := t.TempDir()
+	// probe reports whether another process finds the world locked.
+	probe := func(t *testing.T) string {
+		t.Helper()
+		cmd := exec.Command(os.Args[0], "-test.run=^TestLockWorldProbeProcess$") // #nosec G204 -- the test binary
+		cmd.Env = append(os.Environ(), "NBT_LOCK_WORLD_PROBE="+dir)
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("Unable to run probe process: %v", err)
+		}
+		return string(out)
+	}
+
+	l, err := LockWorld(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Run("Test failure case: second lock and check in the same process", func(t *testing.T) {
+		if _, gotErr := LockWorld(dir); !errors.Is(gotErr, ErrWorldLocked) {
+			t.Errorf("got %v, want %v", gotErr, ErrWorldLocked)
+		}
+		if gotErr := CheckWorldLock(dir); !errors.Is(gotErr, ErrWorldLocked) {
+			t.Errorf("got %v, want %v", gotErr, ErrWorldLocked)
+		}
+	})
+
+	t.Run("Test success case: lock still held for other processes", func(t *testing.T) {
+		if got := probe(t); got != "locked\n" {
+			t.Errorf("got %q, want locked", got)
+		}
+	})
+
+	if err = l.Unlock(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Run("Test success case: unlocked for other processes", func(t *testing.T) {
+		if got := probe(t); got != "free\n" {
+			t.Errorf("got %q, want free", got)
+		}
+	})
+}
@@ -0,0 +1,367 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+// Java edition compresses region file chunks with LZ4 since 1.20.5 in the block stream format of lz4-java's
+// LZ4BlockOutputStream: a series of blocks, each a 21 byte header then the block's data, ended by an empty block. The
+// header is the magic "LZ4Block", a token of the compression method and the block size, the little-endian int32
+// compressed and decompressed lengths, and the int32 XXH32 checksum of the decompressed bytes. The data is an LZ4
+// compressed block, or the decompressed bytes as they are where compression would not shrink them.
+const (
+	lz4Magic       = "LZ4Block"
+	lz4HeaderSize  = len(lz4Magic) + 13
+	lz4MethodRaw   = 0x10
+	lz4MethodLZ4   = 0x20
+	lz4BlockLevel  = 6 // blocks of 64 KiB, the lz4-java default
+	lz4BlockSize   = 1 << (10 + lz4BlockLevel)
+	lz4MaxLevel    = 15
+	lz4Seed        = 0x9747B28C
+	lz4ChecksumMax = 0x0FFFFFFF
+)
+
+// LZ4 block format limits: a match is at least lz4MinMatch bytes, found through a hash table of lz4HashLog bits, the
+// last lz4LastLiterals bytes of a block are literals, and no match starts within lz4MatchLimit bytes of its end.
+const (
+	lz4MinMatch     = 4
+	lz4HashLog      = 16
+	lz4LastLiterals = 5
+	lz4MatchLimit   = 12
+	lz4MaxOffset    = 0xFFFF
+)
+
+// lz4Reader decompresses an LZ4 block stream.
+type lz4Reader struct {
+	r      io.Reader
+	header []byte
+	// block is the decompressed block being read, and unread the part of it not yet returned.
+	block  []byte
+	unread []byte
+	done   bool
+}
+
+// newLZ4Reader returns an lz4Reader reading the block stream from r.
+func newLZ4Reader(r io.Reader) *lz4Reader {
+	return &lz4Reader{r: r, header: make([]byte, lz4HeaderSize)}
+}
+
+// Read reads decompressed bytes, reading and checking the next block when the last is used up, and returns io.EOF after
+// the empty block ending the stream.
+func (l *lz4Reader) Read(p []byte) (n int, err error) {
+	for len(l.unread) == 0 {
+		if l.done {
+			return 0, io.EOF
+		}
+		if err = l.readBlock(); err != nil {
+			return 0, err
+		}
+	}
+	n = copy(p, l.unread)
+	l.unread = l.unread[n:]
+	return n, nil
+}
+
+// readBlock reads, decompresses and checks the next block.
+func (l *lz4Reader) readBlock() (err error) {
+	if _, err = io.ReadFull(l.r, l.header); err != nil {
+		return fmt.Errorf("unable to read LZ4 block header: %w", err)
+	}
+	if string(l.header[:len(lz4Magic)]) != lz4Magic {
+		return fmt.Errorf("LZ4 block header does not start with %q", lz4Magic)
+	}
+
+	token := l.header[len(lz4Magic)]
+	method, level := token&0xF0, int(token&0x0F)
+	compressedLength := int32(binary.LittleEndian.Uint32(l.header[9:]))    // #nosec G115 -- stored as an int32
+	decompressedLength := int32(binary.LittleEndian.Uint32(l.header[13:])) // #nosec G115 -- stored as an int32
+	checksum := binary.LittleEndian.Uint32(l.header[17:])
+
+	blockSize := int32(1) << (10 + level)
+	switch {
+	case level > lz4MaxLevel || (method != lz4MethodRaw && method != lz4MethodLZ4):
+		return fmt.Errorf("unknown LZ4 block token %#x", token)
+	case decompressedLength < 0 || decompressedLength > blockSize || compressedLength < 0 ||
+		(method == lz4MethodRaw && compressedLength != decompressedLength) ||
+		(method == lz4MethodLZ4 && compressedLength > blockSize+blockSize/255+16):
+		return fmt.Errorf("LZ4 block lengths %v and %v do not fit a %v byte block", compressedLength,
+			decompressedLength, blockSize)
+	case decompressedLength == 0:
+		if compressedLength != 0 || checksum != 0 {
+			return fmt.Errorf("LZ4 end block is not empty")
+		}
+		l.done = true
+		return nil
+	}
+
+	compressed := make([]byte, compressedLength)
+	if _, err = io.ReadFull(l.r, compressed); err != nil {
+		return fmt.Errorf("unable to read LZ4 block: %w", err)
+	}
+	if method == lz4MethodRaw {
+		l.block = compressed
+	} else {
+		l.block, err = lz4DecompressBlock(l.block[:0], compressed, int(decompressedLength))
+		if err != nil {
+			return err
+		}
+	}
+
+	if sum := xxhash32(l.block, lz4Seed) & lz4ChecksumMax; sum != checksum {
+		return fmt.Errorf("LZ4 block checksum %#x does not match %#x", sum, checksum)
+	}
+	l.unread = l.block
+	return nil
+}
+
+// lz4Writer compresses to an LZ4 block stream, buffering a block at a time.
+type lz4Writer struct {
+	w     io.Writer
+	block []byte
+}
+
+// newLZ4Writer returns an lz4Writer writing the block stream to w.
+func newLZ4Writer(w io.Writer) *lz4Writer {
+	return &lz4Writer{w: w, block: make([]byte, 0, lz4BlockSize)}
+}
+
+// Write buffers the bytes, writing each block as it fills.
+func (l *lz4Writer) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		m := copy(l.block[len(l.block):cap(l.block)], p)
+		l.block = l.block[:len(l.block)+m]
+		p, n = p[m:], n+m
+		if len(l.block) == cap(l.block) {
+			if err = l.writeBlock(); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Close writes the last block and the empty block ending the stream. It does not close the underlying writer.
+func (l *lz4Writer) Close() (err error) {
+	if len(l.block) > 0 {
+		if err = l.writeBlock(); err != nil {
+			return err
+		}
+	}
+	return l.writeHeader(lz4MethodRaw, 0, 0, 0)
+}
+
+// writeBlock compresses and writes the buffered block, storing it as it is if compression does not shrink it.
+func (l *lz4Writer) writeBlock() (err error) {
+	checksum := xxhash32(l.block, lz4Seed) & lz4ChecksumMax
+	method, data := byte(lz4MethodLZ4), lz4CompressBlock(l.block)
+	if len(data) >= len(l.block) {
+		method, data = lz4MethodRaw, l.block
+	}
+
+	if err = l.writeHeader(method, len(data), len(l.block), checksum); err != nil {
+		return err
+	}
+	if _, err = l.w.Write(data); err != nil {
+		return err
+	}
+	l.block = l.block[:0]
+	return nil
+}
+
+// writeHeader writes a block header, for blocks of at most lz4BlockSize bytes.
+func (l *lz4Writer) writeHeader(method byte, compressedLength, decompressedLength int, checksum uint32) (err error) {
+	header := append([]byte(lz4Magic), method|lz4BlockLevel)
+	header = binary.LittleEndian.AppendUint32(header, uint32(compressedLength))   // #nosec G115 -- a block or less
+	header = binary.LittleEndian.AppendUint32(header, uint32(decompressedLength)) // #nosec G115 -- a block or less
+	header = binary.LittleEndian.AppendUint32(header, checksum)
+	_, err = l.w.Write(header)
+	return err
+}
+
+// lz4DecompressBlock appends the decompression of the LZ4 compressed block src, which must be exactly size bytes, to
+// dst.
+func lz4DecompressBlock(dst, src []byte, size int) (out []byte, err error) {
+	start := len(dst)
+	for i := 0; ; {
+		if i >= len(src) {
+			return nil, fmt.Errorf("LZ4 block ends without literals")
+		}
+		token := src[i]
+		i++
+
+		literals := int(token >> 4)
+		if literals == 15 {
+			var extra int
+			extra, i, err = lz4Length(src, i)
+			if err != nil {
+				return nil, err
+			}
+			literals += extra
+		}
+		if literals > len(src)-i || len(dst)-start+literals > size {
+			return nil, fmt.Errorf("LZ4 block literals overrun the block")
+		}
+		dst = append(dst, src[i:i+literals]...)
+		i += literals
+		if i == len(src) {
+			break
+		}
+
+		if i+2 > len(src) {
+			return nil, fmt.Errorf("LZ4 block ends within a match offset")
+		}
+		offset := int(binary.LittleEndian.Uint16(src[i:]))
+		i += 2
+		if offset == 0 || offset > len(dst)-start {
+			return nil, fmt.Errorf("LZ4 block match offset %v is outside the block", offset)
+		}
+
+		match := int(token&0x0F) + lz4MinMatch
+		if token&0x0F == 15 {
+			var extra int
+			extra, i, err = lz4Length(src, i)
+			if err != nil {
+				return nil, err
+			}
+			match += extra
+		}
+		if len(dst)-start+match > size {
+			return nil, fmt.Errorf("LZ4 block match overruns the block")
+		}
+		// Matches may overlap the bytes they copy, repeating them, so are copied a byte at a time.
+		from := len(dst) - offset
+		for j := range match {
+			dst = append(dst, dst[from+j])
+		}
+	}
+
+	if len(dst)-start != size {
+		return nil, fmt.Errorf("LZ4 block decompresses to %v bytes, not %v", len(dst)-start, size)
+	}
+	return dst, nil
+}
+
+// lz4Length reads the extra bytes of a literal or match length at src[i], returning their sum and the index after.
+func lz4Length(src []byte, i int) (length, next int, err error) {
+	for {
+		if i >= len(src) {
+			return 0, 0, fmt.Errorf("LZ4 block ends within a length")
+		}
+		length += int(src[i])
+		i++
+		if src[i-1] != 0xFF {
+			return length, i, nil
+		}
+	}
+}
+
+// lz4CompressBlock returns src compressed as an LZ4 block, finding matches greedily through a hash table of the last
+// position of each four bytes.
+func lz4CompressBlock(src []byte) (dst []byte) {
+	dst = make([]byte, 0, len(src)+len(src)/255+16)
+	var table [1 << lz4HashLog]int32 // position+1 of each hash, zero if none
+
+	anchor := 0
+	for i := 0; i < len(src)-lz4MatchLimit; {
+		sequence := binary.LittleEndian.Uint32(src[i:])
+		h := (sequence * 2654435761) >> (32 - lz4HashLog)
+		candidate := int(table[h]) - 1
+		table[h] = int32(i + 1) // #nosec G115 -- bounded by the block size
+
+		if candidate < 0 || i-candidate > lz4MaxOffset || binary.LittleEndian.Uint32(src[candidate:]) != sequence {
+			i++
+			continue
+		}
+
+		match := lz4MinMatch
+		for i+match < len(src)-lz4LastLiterals && src[candidate+match] == src[i+match] {
+			match++
+		}
+		dst = lz4AppendSequence(dst, src[anchor:i], i-candidate, match)
+		i += match
+		anchor = i
+	}
+	return lz4AppendSequence(dst, src[anchor:], 0, 0)
+}
+
+// lz4AppendSequence appends a sequence of the literals then a match of the length at the offset back, or the literals
+// alone, ending the block, if the match is zero.
+func lz4AppendSequence(dst, literals []byte, offset, match int) []byte {
+	token := byte(min(len(literals), 15)) << 4
+	if match > 0 {
+		token |= byte(min(match-lz4MinMatch, 15))
+	}
+	dst = append(dst, token)
+	if len(literals) >= 15 {
+		dst = lz4AppendLength(dst, len(literals)-15)
+	}
+	dst = append(dst, literals...)
+	if match == 0 {
+		return dst
+	}
+
+	dst = binary.LittleEndian.AppendUint16(dst, uint16(offset)) // #nosec G115 -- at most lz4MaxOffset
+	if match-lz4MinMatch >= 15 {
+		dst = lz4AppendLength(dst, match-lz4MinMatch-15)
+	}
+	return dst
+}
+
+// lz4AppendLength appends the extra bytes of a literal or match length.
+func lz4AppendLength(dst []byte, length int) []byte {
+	for ; length >= 0xFF; length -= 0xFF {
+		dst = append(dst, 0xFF)
+	}
+	return append(dst, byte(length))
+}
+
+// XXH32 primes.
+const (
+	xxPrime1 uint32 = 2654435761
+	xxPrime2 uint32 = 2246822519
+	xxPrime3 uint32 = 3266489917
+	xxPrime4 uint32 = 668265263
+	xxPrime5 uint32 = 374761393
+)
+
+// xxhash32 returns the XXH32 hash of b with the seed.
+func xxhash32(b []byte, seed uint32) uint32 {
+	n := len(b)
+	var h uint32
+	if n >= 16 {
+		v1, v2, v3, v4 := seed+xxPrime1+xxPrime2, seed+xxPrime2, seed, seed-xxPrime1
+		for ; len(b) >= 16; b = b[16:] {
+			v1 = xxRound(v1, binary.LittleEndian.Uint32(b))
+			v2 = xxRound(v2, binary.LittleEndian.Uint32(b[4:]))
+			v3 = xxRound(v3, binary.LittleEndian.Uint32(b[8:]))
+			v4 = xxRound(v4, binary.LittleEndian.Uint32(b[12:]))
+		}
+		h = bits.RotateLeft32(v1, 1) + bits.RotateLeft32(v2, 7) + bits.RotateLeft32(v3, 12) + bits.RotateLeft32(v4, 18)
+	} else {
+		h = seed + xxPrime5
+	}
+
+	h += uint32(n) // #nosec G115 -- XXH32 adds the length modulo 2^32
+	for ; len(b) >= 4; b = b[4:] {
+		h = bits.RotateLeft32(h+binary.LittleEndian.Uint32(b)*xxPrime3, 17) * xxPrime4
+	}
+	for _, c := range b {
+		h = bits.RotateLeft32(h+uint32(c)*xxPrime5, 11) * xxPrime1
+	}
+
+	h ^= h >> 15
+	h *= xxPrime2
+	h ^= h >> 13
+	h *= xxPrime3
+	h ^= h >> 16
+	return h
+}
+
+// xxRound mixes four bytes of input into an XXH32 accumulator.
+func xxRound(acc, input uint32) uint32 {
+	return bits.RotateLeft32(acc+input*xxPrime2, 13) * xxPrime1
+}
@@ -0,0 +1,147 @@
+package nbt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+func TestXXHash32(t *testing.T) {
+	successCases := []struct {
+		input string
+		seed  uint32
+		want  uint32
+	}{
+		{"", 0, 0x02CC5D05},
+		{"abc", 0, 0x32D153FF},
+		{"Nobody inspects the spammish repetition", 0, 0xE2293B2F},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.input, func(t *testing.T) {
+			if got := xxhash32([]byte(successCase.input), successCase.seed); got != successCase.want {
+				t.Errorf("got %#x, want %#x", got, successCase.want)
+			}
+		})
+	}
+}
+
+func TestLZ4DecompressBlock(t *testing.T) {
+	// Literals "abc", a match 3 back of 8 bytes, then the last literals "xyzxy".
+	block := []byte{0x34, 'a', 'b', 'c', 3, 0, 0x50, 'x', 'y', 'z', 'x', 'y'}
+	want := "abcabcabcabxyzxy"
+
+	t.Run("Test success case: overlapping match", func(t *testing.T) {
+		got, err := lz4DecompressBlock(nil, block, len(want))
+		if err != nil || string(got) != want {
+			t.Errorf("got %q %v, want %q", got, err, want)
+		}
+	})
+
+	failureCases := []struct {
+		name  string
+		block []byte
+		size  int
+	}{
+		{"wrong size", block, len(want) + 1},
+		{"offset outside block", []byte{0x34, 'a', 'b', 'c', 4, 0, 0x50, 'x', 'y', 'z', 'x', 'y'}, len(want)},
+		{"zero offset", []byte{0x34, 'a', 'b', 'c', 0, 0, 0x50, 'x', 'y', 'z', 'x', 'y'}, len(want)},
+		{"truncated literals", block[:3], len(want)},
+		{"truncated offset", block[:5], len(want)},
+		{"truncated length", []byte{0xF0, 0xFF}, 300},
+		{"empty", nil, 0},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			if _, err := lz4DecompressBlock(nil, failureCase.block, failureCase.size); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestLZ4Stream(t *testing.T) {
+	random := make([]byte, 3*lz4BlockSize/2)
+	rand.New(rand.NewSource(1)).Read(random) // #nosec G404 -- test data
+
+	successCases := []struct {
+		name  string
+		input []byte
+	}{
+		{"empty", nil},
+		{"short", []byte("abc")},
+		{"repetitive", bytes.Repeat([]byte("minecraft:stone "), 1000)},
+		{"long run", make([]byte, 5000)},
+		{"incompressible over two blocks", random},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			var compressed bytes.Buffer
+			w := newLZ4Writer(&compressed)
+			if _, err := w.Write(successCase.input); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(successCase.input) > 1000 && successCase.name != "incompressible over two blocks" &&
+				compressed.Len() >= len(successCase.input)/4 {
+				t.Errorf("got %v compressed bytes of %v, want compression", compressed.Len(), len(successCase.input))
+			}
+
+			got, err := io.ReadAll(newLZ4Reader(&compressed))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !bytes.Equal(got, successCase.input) {
+				t.Errorf("got %v bytes, want the %v bytes written", len(got), len(successCase.input))
+			}
+		})
+	}
+
+	t.Run("Test success case: tag round trip", func(t *testing.T) {
+		tag, _ := NewCompound("", NewString("id", "minecraft:stone"), NewLongArray("data", make([]int64, 4096)))
+		var compressed bytes.Buffer
+		if err := WriteTag(&compressed, tag, WithCompression(CompressionLZ4)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got, compression, err := ReadCompressed(&compressed)
+		if err != nil || compression != CompressionLZ4 || !payloadsEqual(got.payload, tag.payload) {
+			t.Errorf("got %v %v %v, want the tag in lz4", got, compression, err)
+		}
+	})
+
+	var stream bytes.Buffer
+	w := newLZ4Writer(&stream)
+	_, _ = w.Write(bytes.Repeat([]byte("abcd"), 100))
+	_ = w.Close()
+	valid := stream.Bytes()
+	corrupt := func(offset int, b byte) []byte {
+		c := bytes.Clone(valid)
+		c[offset] = b
+		return c
+	}
+	oversized := bytes.Clone(valid)
+	binary.LittleEndian.PutUint32(oversized[13:], lz4BlockSize+1)
+
+	failureCases := []struct {
+		name  string
+		input []byte
+	}{
+		{"bad magic", corrupt(0, 'X')},
+		{"unknown method", corrupt(8, 0x30|lz4BlockLevel)},
+		{"checksum mismatch", corrupt(17, valid[17]^1)},
+		{"corrupt block", corrupt(lz4HeaderSize+2, 0xFF)},
+		{"decompressed length beyond block", oversized},
+		{"missing end block", valid[:len(valid)-lz4HeaderSize]},
+		{"truncated block", valid[:lz4HeaderSize+2]},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			if _, err := io.ReadAll(newLZ4Reader(bytes.NewReader(failureCase.input))); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// IntNarrowing selects the tag type FromMap gives Go int and uint values, whose size does not say which NBT integer
+// type they stand for.
+type IntNarrowing int
+
+// IntNarrowing values.
+const (
+	// NarrowLong makes every int and uint a tagLong, as MarshalTag does.
+	NarrowLong IntNarrowing = iota
+	// NarrowInt makes an int or uint a tagInt if it fits in 32 bits, and a tagLong otherwise.
+	NarrowInt
+	// NarrowSmallest makes an int or uint the smallest of tagByte, tagShort, tagInt and tagLong that holds it.
+	NarrowSmallest
+)
+
+// ToMap returns the children of a tagCompound as a map from their names to Go values, for quick scripting. Payloads
+// map to the Go types the Tag holds them as: tagByte to byte, tagShort to int16, tagInt to int32, tagLong to int64,
+// tagFloat to float32, tagDouble to float64, tagString to string, and the arrays to copies of []byte, []int32 and
+// []int64. A tagList maps to []any of its mapped elements and a tagCompound to map[string]any. The order of compound
+// children, the element type of empty lists, and all but the last of duplicate names are lost.
+func (t Tag) ToMap() (m map[string]any, err error) {
+	if t.id != tagCompound {
+		return nil, fmt.Errorf("Unable to convert tag \"%v\" to a map: tag ID %v is not a tagCompound", t.name, t.id)
+	}
+	return mapValue(payloadOrEmpty(t)).(map[string]any), nil
+}
+
+// mapValue returns a payload as the Go value ToMap maps it to.
+func mapValue(payload any) any {
+	switch p := payload.(type) {
+	case []Tag:
+		m := make(map[string]any, len(p))
+		for _, child := range p {
+			m[child.name] = mapValue(payloadOrEmpty(child))
+		}
+		return m
+	case []any:
+		elements := make([]any, len(p))
+		for i, element := range p {
+			elements[i] = mapValue(element)
+		}
+		return elements
+	default:
+		return clonePayload(p)
+	}
+}
+
+// FromMap returns an unnamed tagCompound of the map, the reverse of ToMap, with children in key order. Values of the
+// Go types ToMap returns map back to their tag types, as do int8 to tagByte, uint16 to tagShort, uint32 to tagInt,
+// uint64 to tagLong and bool to a tagByte of 0 or 1. Go int and uint values are narrowed per the narrowing. A Tag
+// value is used as is, under its key. A []any becomes a tagList, whose integer elements are widened to the widest of
+// their types. Any other value, such as a struct or []string, is converted by MarshalTag, and is an error when built
+// with the nbt_noreflect tag.
+func FromMap(m map[string]any, narrowing IntNarrowing) (t Tag, err error) {
+	t, err = fromMapValue(m, narrowing)
+	if err != nil {
+		return Tag{}, fmt.Errorf("Unable to convert map to a tag: %w", err)
+	}
+	return t, nil
+}
+
+// fromMapValue returns the unnamed tag of a Go value.
+func fromMapValue(value any, narrowing IntNarrowing) (t Tag, err error) {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return Tag{id: tagByte, payload: byte(1)}, nil
+		}
+		return Tag{id: tagByte, payload: byte(0)}, nil
+	case int8:
+		return Tag{id: tagByte, payload: byte(v)}, nil // #nosec G115 -- tagByte is signed
+	case uint16:
+		return Tag{id: tagShort, payload: int16(v)}, nil // #nosec G115 -- stored as is
+	case uint32:
+		return Tag{id: tagInt, payload: int32(v)}, nil // #nosec G115 -- stored as is
+	case uint64:
+		return Tag{id: tagLong, payload: int64(v)}, nil // #nosec G115 -- stored as is
+	case int:
+		return narrowInt(int64(v), narrowing), nil
+	case uint:
+		if uint64(v) > math.MaxInt64 {
+			return Tag{}, fmt.Errorf("uint %v overflows a tagLong", v)
+		}
+		return narrowInt(int64(v), narrowing), nil
+	case Tag:
+		return v, nil
+	case map[string]any:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		slices.SortFunc(names, strings.Compare)
+
+		children := make([]Tag, len(names))
+		for i, name := range names {
+			if children[i], err = fromMapValue(v[name], narrowing); err != nil {
+				return Tag{}, fmt.Errorf("key \"%v\": %w", name, err)
+			}
+			children[i].name = name
+		}
+		return Tag{id: tagCompound, payload: children}, nil
+	case []any:
+		elements := make([]Tag, len(v))
+		for i, element := range v {
+			if elements[i], err = fromMapValue(element, narrowing); err != nil {
+				return Tag{}, fmt.Errorf("element %v: %w", i, err)
+			}
+		}
+		return mapList(elements)
+	}
+
+	if id, err := payloadID(value); err == nil {
+		return Tag{id: id, payload: clonePayload(value)}, nil
+	}
+	return marshalAny(value)
+}
+
+// narrowInt returns the integer tag of a Go int or uint per the narrowing.
+func narrowInt(i int64, narrowing IntNarrowing) Tag {
+	switch {
+	case narrowing == NarrowSmallest && i >= math.MinInt8 && i <= math.MaxInt8:
+		return Tag{id: tagByte, payload: byte(i)} // #nosec G115 -- checked above
+	case narrowing == NarrowSmallest && i >= math.MinInt16 && i <= math.MaxInt16:
+		return Tag{id: tagShort, payload: int16(i)} // #nosec G115 -- checked above
+	case narrowing != NarrowLong && i >= math.MinInt32 && i <= math.MaxInt32:
+		return Tag{id: tagInt, payload: int32(i)} // #nosec G115 -- checked above
+	default:
+		return Tag{id: tagLong, payload: i}
+	}
+}
+
+// mapList returns a tagList of the elements, widening integer elements of mixed types to the widest of them.
+func mapList(elements []Tag) (t Tag, err error) {
+	isInteger := func(id uint8) bool { return id >= tagByte && id <= tagLong }
+	var id uint8
+	for i, element := range elements {
+		switch {
+		case i == 0 || element.id == id:
+			id = element.id
+		case isInteger(id) && isInteger(element.id):
+			id = max(id, element.id)
+		default:
+			return Tag{}, fmt.Errorf("list mixes tag ID %v and %v elements", id, element.id)
+		}
+	}
+
+	payload := make([]any, len(elements))
+	for i, element := range elements {
+		payload[i] = element.payload
+		if i64, ok := integerPayload(element.payload); ok && element.id != id {
+			payload[i] = widenInteger(i64, id)
+		}
+	}
+	return Tag{id: tagList, elementID: id, payload: payload}, nil
+}
+
+// widenInteger returns an integer as the payload of the wider integer tag ID.
+func widenInteger(i int64, id uint8) any {
+	switch id {
+	case tagShort:
+		return int16(i) // #nosec G115 -- widened from a narrower payload
+	case tagInt:
+		return int32(i) // #nosec G115 -- widened from a narrower payload
+	default:
+		return i
+	}
+}
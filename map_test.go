@@ -0,0 +1,90 @@
+//go:build !nbt_noreflect
+
+package nbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestToMap(t *testing.T) {
+	pos, _ := NewList("Pos", IDDouble, NewDouble("", 1), NewDouble("", 2))
+	item, _ := NewCompound("", NewString("id", "minecraft:stone"))
+	items, _ := NewList("Items", IDCompound, item)
+	player, _ := NewCompound("", NewByte("OnGround", 1), NewShort("Air", 300), NewInt("Score", 5),
+		NewLong("Seed", 6), NewFloat("Health", 20), NewString("Name", "Steve"), NewIntArray("UUID", []int32{1}),
+		pos, items, Tag{id: tagCompound, name: "Empty"})
+
+	t.Run("Test success case: typed Go values", func(t *testing.T) {
+		got, gotErr := player.ToMap()
+		want := map[string]any{"OnGround": byte(1), "Air": int16(300), "Score": int32(5), "Seed": int64(6),
+			"Health": float32(20), "Name": "Steve", "UUID": []int32{1}, "Pos": []any{1.0, 2.0},
+			"Items": []any{map[string]any{"id": "minecraft:stone"}}, "Empty": map[string]any{}}
+		if !reflect.DeepEqual(got, want) || gotErr != nil {
+			t.Errorf("got %v %v, want %v", got, gotErr, want)
+		}
+
+		got["UUID"].([]int32)[0] = 9
+		if uuid, _ := player.GetIntArray("UUID"); uuid[0] != 1 {
+			t.Errorf("map aliases the tag's array")
+		}
+	})
+
+	t.Run("Test success case: round trip", func(t *testing.T) {
+		m, _ := player.ToMap()
+		got, gotErr := FromMap(m, NarrowLong)
+		want := `{Air:300s,Empty:{},Health:20f,Items:[{id:"minecraft:stone"}],Name:"Steve",OnGround:1b,` +
+			`Pos:[1d,2d],Score:5,Seed:6L,UUID:[I;1]}`
+		if got.String() != want || gotErr != nil {
+			t.Errorf("got %v %v, want %v", got, gotErr, want)
+		}
+	})
+
+	t.Run("Test failure case: not a compound", func(t *testing.T) {
+		if _, err := NewInt("i", 1).ToMap(); err == nil {
+			t.Errorf("got nil, want non-nil")
+		}
+	})
+}
+
+func TestFromMap(t *testing.T) {
+	m := map[string]any{"a": 1, "b": 300, "c": 1 << 40, "d": []any{1, 300}, "e": true, "f": int8(-1),
+		"g": []string{"x"}, "h": NewString("ignored", "tag")}
+
+	successCases := []struct {
+		name      string
+		want      string
+		narrowing IntNarrowing
+	}{
+		{"long", `{a:1L,b:300L,c:1099511627776L,d:[1L,300L],e:1b,f:-1b,g:["x"],h:"tag"}`, NarrowLong},
+		{"int", `{a:1,b:300,c:1099511627776L,d:[1,300],e:1b,f:-1b,g:["x"],h:"tag"}`, NarrowInt},
+		{"smallest", `{a:1b,b:300s,c:1099511627776L,d:[1s,300s],e:1b,f:-1b,g:["x"],h:"tag"}`, NarrowSmallest},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			got, gotErr := FromMap(m, successCase.narrowing)
+			if got.String() != successCase.want || gotErr != nil {
+				t.Errorf("got %v %v, want %v", got, gotErr, successCase.want)
+			}
+			if h, _ := got.Child("h"); h.name != "h" {
+				t.Errorf("Tag value named %v, want h", h.name)
+			}
+		})
+	}
+
+	failureCases := []struct {
+		name string
+		m    map[string]any
+	}{
+		{"mixed list", map[string]any{"l": []any{1, "a"}}},
+		{"uint overflow", map[string]any{"u": uint(1 << 63)}},
+		{"unsupported value", map[string]any{"c": make(chan int)}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			if _, gotErr := FromMap(failureCase.m, NarrowInt); gotErr == nil {
+				t.Errorf("got nil, want non-nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,309 @@
+//go:build !nbt_noreflect
+
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// tagReflectType is the reflect.Type of Tag, which is marshalled and unmarshalled as is.
+var tagReflectType = reflect.TypeFor[Tag]()
+
+// Marshal returns the encoding of v as an unnamed root tag, see MarshalTag, written with the options as by WriteTag.
+func Marshal(v any, opts ...Option) ([]byte, error) {
+	t, err := MarshalTag(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	err = WriteTag(&b, t, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to marshal %T: %w", v, err)
+	}
+	return b.Bytes(), nil
+}
+
+// MarshalTag returns v as an unnamed tag. Go values map to tags as:
+//
+//   - bool: tagByte of 0 or 1
+//   - int8, uint8: tagByte; int16, uint16: tagShort; int32, uint32: tagInt; int64, uint64, int, uint: tagLong, with
+//     unsigned integers keeping their bits
+//   - float32: tagFloat; float64: tagDouble
+//   - string: tagString
+//   - []byte, []int32 and []int64, or arrays of them: tagByteArray, tagIntArray and tagLongArray
+//   - other slices and arrays: tagList
+//   - structs and maps with string keys: tagCompound, with map keys in sorted order
+//   - pointers and interfaces: the value they hold
+//   - Tag: the tag as is, renamed
+//
+// Struct fields are named by the field name, or the name given by an `nbt:"name"` struct tag. The tag options
+// "omitempty" leaves out a zero field and "list" marshals a byte, int32 or int64 slice as a tagList rather than an
+// array, as in `nbt:"Pos,omitempty"`. A field tagged "-" is skipped, as are unexported fields and nil pointers and
+// interfaces. The fields of an untagged embedded struct are marshalled as if they were fields of the outer struct.
+func MarshalTag(v any) (t Tag, err error) {
+	t, err = marshalValue(reflect.ValueOf(v), false)
+	if err != nil {
+		return Tag{}, fmt.Errorf("Unable to marshal %T: %w", v, err)
+	}
+	return t, nil
+}
+
+// marshalAny returns v as an unnamed tag, by MarshalTag. It is how the rest of the package converts Go values, as
+// builds with the nbt_noreflect tag have no MarshalTag.
+func marshalAny(v any) (Tag, error) {
+	return MarshalTag(v)
+}
+
+// marshalValue returns the unnamed tag of the value. If list is set, byte, int32 and int64 slices are tagLists.
+func marshalValue(v reflect.Value, list bool) (t Tag, err error) {
+	if !v.IsValid() {
+		return Tag{}, fmt.Errorf("nil value")
+	}
+	if v.Type() == tagReflectType {
+		t = v.Interface().(Tag)
+		t.name = ""
+		return t, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return Tag{id: tagByte, payload: boolByte(v.Bool())}, nil
+	case reflect.Int8:
+		return Tag{id: tagByte, payload: byte(v.Int())}, nil // #nosec G115 -- tagByte holds the bits of an int8
+	case reflect.Uint8:
+		return Tag{id: tagByte, payload: byte(v.Uint())}, nil
+	case reflect.Int16:
+		return Tag{id: tagShort, payload: int16(v.Int())}, nil
+	case reflect.Uint16:
+		return Tag{id: tagShort, payload: int16(v.Uint())}, nil // #nosec G115 -- tagShort holds the bits
+	case reflect.Int32:
+		return Tag{id: tagInt, payload: int32(v.Int())}, nil
+	case reflect.Uint32:
+		return Tag{id: tagInt, payload: int32(v.Uint())}, nil // #nosec G115 -- tagInt holds the bits
+	case reflect.Int64, reflect.Int:
+		return Tag{id: tagLong, payload: v.Int()}, nil
+	case reflect.Uint64, reflect.Uint:
+		return Tag{id: tagLong, payload: int64(v.Uint())}, nil // #nosec G115 -- tagLong holds the bits
+	case reflect.Float32:
+		return Tag{id: tagFloat, payload: float32(v.Float())}, nil
+	case reflect.Float64:
+		return Tag{id: tagDouble, payload: v.Float()}, nil
+	case reflect.String:
+		return Tag{id: tagString, payload: v.String()}, nil
+	case reflect.Slice, reflect.Array:
+		return marshalSequence(v, list)
+	case reflect.Map:
+		return marshalMap(v)
+	case reflect.Struct:
+		return marshalStruct(v)
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return Tag{}, fmt.Errorf("nil %v", v.Type())
+		}
+		return marshalValue(v.Elem(), list)
+	default:
+		return Tag{}, fmt.Errorf("unsupported type %v", v.Type())
+	}
+}
+
+// marshalSequence returns the tag of a slice or array: an array tag for byte, int32 and int64 elements unless list is
+// set, otherwise a tagList.
+func marshalSequence(v reflect.Value, list bool) (t Tag, err error) {
+	if !list {
+		switch id := arrayID(v.Type()); id {
+		case tagByteArray:
+			payload := make([]byte, v.Len())
+			for i := range payload {
+				payload[i] = byte(v.Index(i).Uint())
+			}
+			return Tag{id: id, payload: cloneArray(payload)}, nil
+		case tagIntArray:
+			payload := make([]int32, v.Len())
+			for i := range payload {
+				payload[i] = int32(v.Index(i).Int()) // #nosec G115 -- the elements are int32
+			}
+			return Tag{id: id, payload: cloneArray(payload)}, nil
+		case tagLongArray:
+			payload := make([]int64, v.Len())
+			for i := range payload {
+				payload[i] = v.Index(i).Int()
+			}
+			return Tag{id: id, payload: cloneArray(payload)}, nil
+		}
+	}
+
+	t = Tag{id: tagList, elementID: typeID(v.Type().Elem()), payload: []any(nil)}
+	for i := range v.Len() {
+		element, err := marshalValue(v.Index(i), false)
+		if err != nil {
+			return Tag{}, fmt.Errorf("element %v: %w", i, err)
+		}
+		if i == 0 {
+			t.elementID = element.id
+		} else if element.id != t.elementID {
+			return Tag{}, fmt.Errorf("element %v: tag ID %v differs from the list's tag ID %v", i, element.id,
+				t.elementID)
+		}
+		t.payload = append(t.payload.([]any), element.payload)
+	}
+	return t, nil
+}
+
+// arrayID returns the array tag ID of a slice or array type of byte, int32 or int64, or tagEnd for any other type.
+func arrayID(sequence reflect.Type) uint8 {
+	switch sequence.Elem().Kind() {
+	case reflect.Uint8:
+		return tagByteArray
+	case reflect.Int32:
+		return tagIntArray
+	case reflect.Int64:
+		return tagLongArray
+	default:
+		return tagEnd
+	}
+}
+
+// typeID returns the tag ID values of the type marshal to, used as the element ID of empty lists, or tagEnd if it
+// depends on the value, as for interfaces and Tag.
+func typeID(t reflect.Type) uint8 {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == tagReflectType {
+		return tagEnd
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int8, reflect.Uint8:
+		return tagByte
+	case reflect.Int16, reflect.Uint16:
+		return tagShort
+	case reflect.Int32, reflect.Uint32:
+		return tagInt
+	case reflect.Int64, reflect.Uint64, reflect.Int, reflect.Uint:
+		return tagLong
+	case reflect.Float32:
+		return tagFloat
+	case reflect.Float64:
+		return tagDouble
+	case reflect.String:
+		return tagString
+	case reflect.Slice, reflect.Array:
+		if id := arrayID(t); id != tagEnd {
+			return id
+		}
+		return tagList
+	case reflect.Map, reflect.Struct:
+		return tagCompound
+	default:
+		return tagEnd
+	}
+}
+
+// marshalMap returns the tagCompound of a map with string keys, with children in key order.
+func marshalMap(v reflect.Value) (t Tag, err error) {
+	if v.Type().Key().Kind() != reflect.String {
+		return Tag{}, fmt.Errorf("unsupported map key type %v", v.Type().Key())
+	}
+
+	keys := v.MapKeys()
+	slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) })
+
+	var children []Tag
+	for _, key := range keys {
+		child, err := marshalValue(v.MapIndex(key), false)
+		if err != nil {
+			return Tag{}, fmt.Errorf("key \"%v\": %w", key.String(), err)
+		}
+		child.name = key.String()
+		children = append(children, child)
+	}
+	return Tag{id: tagCompound, payload: children}, nil
+}
+
+// marshalStruct returns the tagCompound of a struct, with children in field order.
+func marshalStruct(v reflect.Value) (t Tag, err error) {
+	var children []Tag
+	for _, f := range structFields(v.Type()) {
+		fieldValue, err := v.FieldByIndexErr(f.index)
+		if err != nil {
+			// A nil embedded struct pointer has no fields to marshal.
+			continue
+		}
+		if (f.omitEmpty && fieldValue.IsZero()) || (fieldValue.Kind() == reflect.Pointer ||
+			fieldValue.Kind() == reflect.Interface) && fieldValue.IsNil() {
+			continue
+		}
+
+		child, err := marshalValue(fieldValue, f.list)
+		if err != nil {
+			return Tag{}, fmt.Errorf("field %v: %w", f.name, err)
+		}
+		child.name = f.name
+		children = append(children, child)
+	}
+	return Tag{id: tagCompound, payload: children}, nil
+}
+
+// structField is an exported field of a struct, or of a struct embedded in it, and its tag name and options.
+type structField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	list      bool
+}
+
+// structFields returns the fields of the struct type to marshal, in order, with the fields of untagged embedded structs
+// in place of the embedded struct. A field of the outer struct hides an embedded field of the same name.
+func structFields(t reflect.Type) (fields []structField) {
+	names := map[string]bool{}
+	var embedded []structField
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag, tagged := f.Tag.Lookup("nbt")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		fieldType := f.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if f.Anonymous && name == "" && fieldType.Kind() == reflect.Struct && fieldType != tagReflectType {
+			for _, inner := range structFields(fieldType) {
+				inner.index = append([]int{i}, inner.index...)
+				embedded = append(embedded, inner)
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		if !tagged || name == "" {
+			name = f.Name
+		}
+		names[name] = true
+		fields = append(fields, structField{
+			name:      name,
+			index:     []int{i},
+			omitEmpty: slices.Contains(strings.Split(options, ","), "omitempty"),
+			list:      slices.Contains(strings.Split(options, ","), "list"),
+		})
+	}
+
+	for _, f := range embedded {
+		if !names[f.name] {
+			names[f.name] = true
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
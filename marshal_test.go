@@ -0,0 +1,112 @@
+//go:build !nbt_noreflect
+
+package nbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+// marshalItem and marshalPlayer exercise struct tags, embedding and the supported field types.
+type marshalItem struct {
+	ID    string `nbt:"id"`
+	Count byte
+	Slot  int8 `nbt:",omitempty"`
+}
+
+type marshalVersioned struct {
+	DataVersion int32
+}
+
+type marshalPlayer struct {
+	marshalVersioned
+	Pos       []float64
+	UUID      [4]int32
+	Inventory []marshalItem
+	Flying    bool `nbt:"flying"`
+	Seed      uint64
+	Tags      map[string]string
+	Raw       Tag
+	Skipped   string `nbt:"-"`
+	Air       *int16
+	Missing   *int16
+	Levels    []int32 `nbt:",list"`
+	Empty     []marshalItem
+	hidden    int
+}
+
+func TestMarshalTag(t *testing.T) {
+	air := int16(300)
+	player := marshalPlayer{
+		marshalVersioned: marshalVersioned{DataVersion: 3953},
+		Pos:              []float64{1, 2, 3},
+		UUID:             [4]int32{1, 2, 3, -4},
+		Inventory:        []marshalItem{{ID: "minecraft:stone", Count: 64}, {ID: "minecraft:dirt", Count: 1, Slot: 3}},
+		Flying:           true,
+		Seed:             1<<64 - 1,
+		Tags:             map[string]string{"b": "2", "a": "1"},
+		Raw:              NewString("ignored", "raw"),
+		Skipped:          "skipped",
+		Air:              &air,
+		Levels:           []int32{5},
+		hidden:           1,
+	}
+
+	pos, _ := NewList("Pos", IDDouble, NewDouble("", 1), NewDouble("", 2), NewDouble("", 3))
+	stone, _ := NewCompound("", NewString("id", "minecraft:stone"), NewByte("Count", 64))
+	dirt, _ := NewCompound("", NewString("id", "minecraft:dirt"), NewByte("Count", 1), NewByte("Slot", 3))
+	inventory, _ := NewList("Inventory", IDCompound, stone, dirt)
+	tags, _ := NewCompound("Tags", NewString("a", "1"), NewString("b", "2"))
+	levels, _ := NewList("Levels", IDInt, NewInt("", 5))
+	empty, _ := NewList("Empty", IDCompound)
+	want, _ := NewCompound("", pos, NewIntArray("UUID", []int32{1, 2, 3, -4}), inventory, NewByte("flying", 1),
+		NewLong("Seed", -1), tags, NewString("Raw", "raw"), NewShort("Air", 300), levels, empty,
+		NewInt("DataVersion", 3953))
+
+	t.Run("Test success case: struct", func(t *testing.T) {
+		got, err := MarshalTag(player)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("Test success case: round trip through bytes", func(t *testing.T) {
+		data, err := Marshal(&player, BedrockEdition)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var got marshalPlayer
+		if err = Unmarshal(data, &got, BedrockEdition); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		wantPlayer := player
+		wantPlayer.Skipped, wantPlayer.hidden = "", 0
+		wantPlayer.Raw.name = "Raw"
+		wantPlayer.Empty = []marshalItem{}
+		if !reflect.DeepEqual(got, wantPlayer) {
+			t.Errorf("got %+v, want %+v", got, wantPlayer)
+		}
+	})
+
+	failureCases := []struct {
+		name string
+		v    any
+	}{
+		{"nil", nil},
+		{"unsupported type", make(chan int)},
+		{"non-string map keys", map[int]string{1: "a"}},
+		{"mixed list elements", []any{int32(1), "a"}},
+		{"unsupported field", struct{ F func() }{func() {}}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			if _, err := MarshalTag(failureCase.v); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
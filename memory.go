@@ -0,0 +1,65 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import "unsafe"
+
+// Go runtime sizes used by MemoryEstimate, for the platform the package is compiled for.
+const (
+	tagSize         = int64(unsafe.Sizeof(Tag{}))
+	interfaceSize   = int64(unsafe.Sizeof(any(nil)))
+	stringHeaderLen = int64(unsafe.Sizeof(""))
+	sliceHeaderLen  = int64(unsafe.Sizeof([]byte(nil)))
+)
+
+// MemoryEstimate approximates the heap bytes held by the tag and everything below it: the Tag struct itself, the tag
+// name, the payload boxed in its interface, and the full capacity of every slice. Caching layers can use it to keep
+// loaded chunks within a memory budget.
+//
+// It is an estimate: allocations are rounded up to whole words rather than the runtime's size classes, single byte
+// payloads are assumed not to allocate (the runtime shares them), and memory shared between trees is counted once per
+// tree.
+func (t *Tag) MemoryEstimate() int64 {
+	return tagSize + roundAllocation(int64(len(t.name))) + payloadMemory(t.payload)
+}
+
+// payloadMemory approximates the heap bytes held by a payload boxed in an interface, excluding the interface itself.
+func payloadMemory(payload any) int64 {
+	switch p := payload.(type) {
+	case byte:
+		return 0
+	case int16:
+		return roundAllocation(2)
+	case int32, float32:
+		return roundAllocation(4)
+	case int64, float64:
+		return roundAllocation(8)
+	case string:
+		return roundAllocation(stringHeaderLen) + roundAllocation(int64(len(p)))
+	case []byte:
+		return roundAllocation(sliceHeaderLen) + roundAllocation(int64(cap(p)))
+	case []int32:
+		return roundAllocation(sliceHeaderLen) + roundAllocation(4*int64(cap(p)))
+	case []int64:
+		return roundAllocation(sliceHeaderLen) + roundAllocation(8*int64(cap(p)))
+	case []any:
+		size := roundAllocation(sliceHeaderLen) + roundAllocation(interfaceSize*int64(cap(p)))
+		for _, element := range p {
+			size += payloadMemory(element)
+		}
+		return size
+	case []Tag:
+		size := roundAllocation(sliceHeaderLen) + roundAllocation(tagSize*int64(cap(p)))
+		for i := range p {
+			size += p[i].MemoryEstimate() - tagSize
+		}
+		return size
+	default:
+		return 0
+	}
+}
+
+// roundAllocation rounds an allocation of n bytes up to a whole number of machine words. Empty allocations are free.
+func roundAllocation(n int64) int64 {
+	word := int64(unsafe.Sizeof(uintptr(0)))
+	return (n + word - 1) / word * word
+}
@@ -0,0 +1,58 @@
+package nbt
+
+import (
+	"math/rand"
+	"runtime"
+	"testing"
+	"unsafe"
+)
+
+func TestMemoryEstimate(t *testing.T) {
+	word := int64(unsafe.Sizeof(uintptr(0)))
+
+	successCases := []struct {
+		name         string
+		wantEstimate int64
+		t            Tag
+	}{
+		{"unnamed byte", tagSize, Tag{id: tagByte, payload: byte(1)}},
+		{"named int", tagSize + word + word, Tag{id: tagInt, name: "abc", payload: int32(1)}},
+		{"string", tagSize + stringHeaderLen + 2*word, Tag{id: tagString, payload: "0123456789"}},
+		{"int array capacity", tagSize + sliceHeaderLen + 16, Tag{id: tagIntArray, payload: make([]int32, 1, 4)}},
+		{"list of longs", tagSize + sliceHeaderLen + 2*interfaceSize + 2*word, Tag{id: tagList, payload: []any{int64(1),
+			int64(2)}}},
+		{"compound", tagSize + sliceHeaderLen + 2*tagSize + word + word, Tag{id: tagCompound, payload: []Tag{
+			{id: tagByte, name: "a", payload: byte(1)}, {id: tagShort, payload: int16(1)}}}},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			gotEstimate := successCase.t.MemoryEstimate()
+			if gotEstimate != successCase.wantEstimate {
+				t.Errorf("got %v, want %v", gotEstimate, successCase.wantEstimate)
+			}
+		})
+	}
+
+	t.Run("Test success case: estimate is close to measured heap", func(t *testing.T) {
+		g := Generator{MaxDepth: 3, MaxElements: 30, MaxStringLength: 10, IDs: []uint8{tagCompound, tagList, tagInt,
+			tagString, tagIntArray}}
+
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		tags := make([]Tag, 20)
+		for i := range tags {
+			tags[i] = g.Tag(rand.New(rand.NewSource(int64(i)))) // #nosec G404 -- deterministic test input
+		}
+		runtime.ReadMemStats(&after)
+
+		var estimate int64
+		for i := range tags {
+			estimate += tags[i].MemoryEstimate()
+		}
+		measured := int64(after.TotalAlloc - before.TotalAlloc)
+		if estimate > measured || estimate < measured/4 {
+			t.Errorf("got estimate %v, want close to measured %v", estimate, measured)
+		}
+	})
+}
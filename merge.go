@@ -0,0 +1,102 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"fmt"
+	"slices"
+)
+
+// MergeStrategy selects how Merge combines the children of two compounds.
+type MergeStrategy int
+
+// MergeStrategy values.
+const (
+	// MergeDeep merges compounds present in both recursively, and otherwise replaces each child of dst with the child
+	// of src of the same name.
+	MergeDeep MergeStrategy = iota
+	// MergeOverwrite replaces each child of dst with the child of src of the same name, compounds included, as when
+	// applying a preset of whole values.
+	MergeOverwrite
+	// MergeAppendLists merges as MergeDeep, but appends the elements of lists present in both to those of dst. The lists
+	// must have the same element type unless either is empty.
+	MergeAppendLists
+)
+
+// String returns the name of the strategy.
+func (s MergeStrategy) String() string {
+	switch s {
+	case MergeDeep:
+		return "deep"
+	case MergeOverwrite:
+		return "overwrite"
+	case MergeAppendLists:
+		return "append lists"
+	default:
+		return fmt.Sprintf("MergeStrategy(%d)", int(s))
+	}
+}
+
+// Merge merges the children of the tagCompound src into the tagCompound dst per the strategy. Children of src not in
+// dst are added after those of dst, and a child of a different type replaces that of dst whole. The compounds and
+// lists of dst are copied as they are merged, so trees sharing them are not modified, while the tags of src are shared
+// with dst. On error dst is left as it was.
+func Merge(dst, src *Tag, strategy MergeStrategy) (err error) {
+	if strategy < MergeDeep || strategy > MergeAppendLists {
+		return fmt.Errorf("Unable to merge tag \"%v\": unknown strategy %v", src.name, strategy)
+	}
+	if dst.id != tagCompound || src.id != tagCompound {
+		return fmt.Errorf("Unable to merge tag \"%v\" into \"%v\": both tags must be a tagCompound", src.name, dst.name)
+	}
+	merged, err := mergeTags(Path{}, *dst, *src, strategy)
+	if err != nil {
+		return fmt.Errorf("Unable to merge tag \"%v\" into \"%v\": %w", src.name, dst.name, err)
+	}
+
+	*dst = merged
+	return nil
+}
+
+// mergeTags returns the tagCompound dst at the path with the children of the tagCompound src merged in.
+func mergeTags(path Path, dst, src Tag, strategy MergeStrategy) (Tag, error) {
+	children, _ := dst.payload.([]Tag)
+	srcChildren, _ := src.payload.([]Tag)
+	for _, srcChild := range srcChildren {
+		child, ok := compoundChild(Tag{payload: children}, srcChild.name)
+		if ok && child.id == srcChild.id && strategy != MergeOverwrite {
+			var err error
+			switch {
+			case child.id == tagCompound:
+				srcChild, err = mergeTags(appendPath(path, child.name), child, srcChild, strategy)
+			case child.id == tagList && strategy == MergeAppendLists:
+				srcChild, err = appendList(appendPath(path, child.name), child, srcChild)
+			}
+			if err != nil {
+				return Tag{}, err
+			}
+		}
+		children = withChild(children, srcChild)
+	}
+
+	dst.payload = children
+	return dst, nil
+}
+
+// appendList returns the tagList dst at the path with the elements of the tagList src appended.
+func appendList(path Path, dst, src Tag) (Tag, error) {
+	elements, _ := dst.payload.([]any)
+	srcElements, _ := src.payload.([]any)
+	if len(srcElements) == 0 {
+		return dst, nil
+	}
+	if len(elements) == 0 {
+		src.name = dst.name
+		return src, nil
+	}
+	if dst.ElementID() != src.ElementID() {
+		return Tag{}, fmt.Errorf("%v: unable to append tag ID %v elements to a list of tag ID %v", path,
+			src.ElementID(), dst.ElementID())
+	}
+
+	dst.payload = append(slices.Clip(elements), srcElements...)
+	return dst, nil
+}
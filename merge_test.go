@@ -0,0 +1,76 @@
+package nbt
+
+import "testing"
+
+func TestMerge(t *testing.T) {
+	parse := func(s string) Tag {
+		tag, err := ParseSNBT(s)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return tag
+	}
+	dst := `{Data:{GameRules:{doDaylightCycle:"true",keepInventory:"false"},Tags:["a"],Version:1}}`
+	src := `{Data:{GameRules:{keepInventory:"true"},Tags:["b"],Version:2b},Extra:1}`
+
+	successCases := []struct {
+		name     string
+		want     string
+		strategy MergeStrategy
+	}{
+		{"deep", `{Data:{GameRules:{doDaylightCycle:"true",keepInventory:"true"},Tags:["b"],Version:2b},Extra:1}`,
+			MergeDeep},
+		{"overwrite", `{Data:{GameRules:{keepInventory:"true"},Tags:["b"],Version:2b},Extra:1}`, MergeOverwrite},
+		{"append lists",
+			`{Data:{GameRules:{doDaylightCycle:"true",keepInventory:"true"},Tags:["a","b"],Version:2b},Extra:1}`,
+			MergeAppendLists},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			gotTag, srcTag := parse(dst), parse(src)
+			original := gotTag.Clone()
+			gotErr := Merge(&gotTag, &srcTag, successCase.strategy)
+			if gotErr != nil {
+				t.Fatalf("Unexpected error: %v", gotErr)
+			}
+			if got := gotTag.String(); got != successCase.want {
+				t.Errorf("got %v, want %v", got, successCase.want)
+			}
+			if tree := parse(dst); !Equal(&original, &tree) {
+				t.Errorf("merge modified a shared tree")
+			}
+		})
+	}
+
+	t.Run("Test success case: append to an empty list", func(t *testing.T) {
+		gotTag, srcTag := parse(`{Tags:[]}`), parse(`{Tags:[1,2]}`)
+		if err := Merge(&gotTag, &srcTag, MergeAppendLists); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := gotTag.String(); got != "{Tags:[1,2]}" {
+			t.Errorf("got %v", got)
+		}
+	})
+
+	failureCases := []struct {
+		name     string
+		dst, src string
+		strategy MergeStrategy
+	}{
+		{"not compounds", `"a"`, `{}`, MergeDeep},
+		{"list element types differ", `{Tags:[1]}`, `{Tags:["a"]}`, MergeAppendLists},
+		{"unknown strategy", `{}`, `{}`, MergeStrategy(9)},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			gotTag, srcTag := parse(failureCase.dst), parse(failureCase.src)
+			gotErr := Merge(&gotTag, &srcTag, failureCase.strategy)
+			if gotErr == nil {
+				t.Errorf("got nil, want non-nil")
+			}
+			if got := gotTag.String(); got != parse(failureCase.dst).String() {
+				t.Errorf("dst modified on error: %v", got)
+			}
+		})
+	}
+}
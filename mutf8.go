@@ -0,0 +1,120 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Java edition encodes strings in Modified UTF-8 (MUTF-8), which differs from UTF-8 in two ways: the NUL character is
+// the two bytes 0xC0 0x80, so no encoded string contains a zero byte, and characters beyond the Basic Multilingual
+// Plane are encoded as a UTF-16 surrogate pair, each surrogate as three bytes (CESU-8), rather than as four bytes.
+
+// StringEncoding is the encoding of tag names and tagString payloads.
+type StringEncoding uint8
+
+const (
+	// StringEncodingAuto is Modified UTF-8 when the byte order is big-endian (Java edition) and UTF-8 otherwise.
+	StringEncodingAuto StringEncoding = iota
+	// StringEncodingUTF8 is standard UTF-8, as used by Bedrock edition.
+	StringEncodingUTF8
+	// StringEncodingMUTF8 is Modified UTF-8, as used by Java edition.
+	StringEncodingMUTF8
+)
+
+// modifiedUTF8 reports whether the Options select Modified UTF-8 strings.
+func (o Options) modifiedUTF8() bool {
+	if o.StringEncoding == StringEncodingAuto {
+		return o.ByteOrder == binary.BigEndian
+	}
+	return o.StringEncoding == StringEncodingMUTF8
+}
+
+// decodeString decodes the bytes of a tag name or tagString payload in the string encoding of the Options. Invalid
+// bytes are an error, or replaced with the Unicode replacement character if LenientUTF8 is set.
+func decodeString(b []byte, o Options) (s string, err error) {
+	if o.modifiedUTF8() {
+		s, err = decodeMUTF8(b)
+	} else if s = string(b); !utf8.ValidString(s) {
+		err = fmt.Errorf("%q contains non UTF-8 characters", s)
+	}
+	if err != nil {
+		if !o.LenientUTF8 {
+			return "", err
+		}
+		return strings.ToValidUTF8(string(b), string(utf8.RuneError)), nil
+	}
+	return s, nil
+}
+
+// encodeString encodes a tag name or tagString payload in the string encoding of the Options.
+func encodeString(s string, o Options) (b []byte) {
+	if o.modifiedUTF8() {
+		return encodeMUTF8(s)
+	}
+	return []byte(s)
+}
+
+// decodeMUTF8 decodes Modified UTF-8 bytes to a string. Four byte UTF-8 sequences are accepted too, as some tools write
+// them, but unpaired surrogates and other invalid bytes are an error.
+func decodeMUTF8(b []byte) (s string, err error) {
+	var sb strings.Builder
+	sb.Grow(len(b))
+	for i := 0; i < len(b); {
+		switch {
+		case b[i] == 0xC0 && i+1 < len(b) && b[i+1] == 0x80:
+			sb.WriteByte(0)
+			i += 2
+		case b[i] == 0xED && i+1 < len(b) && b[i+1] >= 0xA0:
+			high, ok := mutf8Surrogate(b[i:])
+			if !ok || !utf16.IsSurrogate(high) || high >= 0xDC00 {
+				return "", fmt.Errorf("unpaired surrogate at byte %v", i)
+			}
+			low, ok := mutf8Surrogate(b[i+3:])
+			if !ok || low < 0xDC00 || low > 0xDFFF {
+				return "", fmt.Errorf("unpaired surrogate at byte %v", i)
+			}
+			sb.WriteRune(utf16.DecodeRune(high, low))
+			i += 6
+		default:
+			r, size := utf8.DecodeRune(b[i:])
+			if r == utf8.RuneError && size <= 1 {
+				return "", fmt.Errorf("invalid byte %#x at byte %v", b[i], i)
+			}
+			sb.WriteRune(r)
+			i += size
+		}
+	}
+	return sb.String(), nil
+}
+
+// mutf8Surrogate decodes the three byte encoding of a surrogate at the start of b, returning false if b does not start
+// with one.
+func mutf8Surrogate(b []byte) (r rune, ok bool) {
+	if len(b) < 3 || b[0] != 0xED || b[1]&0xE0 != 0xA0 || b[2]&0xC0 != 0x80 {
+		return 0, false
+	}
+	return rune(b[0]&0x0F)<<12 | rune(b[1]&0x3F)<<6 | rune(b[2]&0x3F), true
+}
+
+// encodeMUTF8 encodes a string as Modified UTF-8.
+func encodeMUTF8(s string) (b []byte) {
+	b = make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r == 0:
+			b = append(b, 0xC0, 0x80)
+		case r > 0xFFFF:
+			high, low := utf16.EncodeRune(r)
+			for _, surrogate := range []rune{high, low} {
+				b = append(b, 0xE0|byte(surrogate>>12), 0x80|byte(surrogate>>6)&0x3F, 0x80|byte(surrogate)&0x3F)
+			}
+		default:
+			b = utf8.AppendRune(b, r)
+		}
+	}
+	return b
+}
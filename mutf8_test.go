@@ -0,0 +1,117 @@
+package nbt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestMUTF8(t *testing.T) {
+	successCases := []struct {
+		name    string
+		want    string
+		encoded []byte
+	}{
+		{"ASCII", "abc", []byte("abc")},
+		{"empty", "", []byte{}},
+		{"NUL as two bytes", "a\x00b", []byte{'a', 0xC0, 0x80, 'b'}},
+		{"two byte character", "é", []byte{0xC3, 0xA9}},
+		{"supplementary character as a surrogate pair", "😀", []byte{0xED, 0xA0, 0xBD, 0xED, 0xB8, 0x80}},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			got, err := decodeMUTF8(successCase.encoded)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != successCase.want {
+				t.Errorf("got %q, want %q", got, successCase.want)
+			}
+			if encoded := encodeMUTF8(successCase.want); !bytes.Equal(encoded, successCase.encoded) {
+				t.Errorf("got % X, want % X", encoded, successCase.encoded)
+			}
+		})
+	}
+
+	t.Run("Test success case: four byte UTF-8 accepted", func(t *testing.T) {
+		if got, err := decodeMUTF8([]byte("😀")); err != nil || got != "😀" {
+			t.Errorf("got %q %v, want 😀", got, err)
+		}
+	})
+
+	failureCases := []struct {
+		name    string
+		encoded []byte
+	}{
+		{"lone high surrogate", []byte{0xED, 0xA0, 0xBD, 'a'}},
+		{"lone low surrogate", []byte{0xED, 0xB8, 0x80}},
+		{"truncated surrogate pair", []byte{0xED, 0xA0, 0xBD, 0xED, 0xB8}},
+		{"invalid byte", []byte{'a', 0xFF}},
+		{"truncated sequence", []byte{0xC3}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			if _, err := decodeMUTF8(failureCase.encoded); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestStringEncoding(t *testing.T) {
+	// A tagString named "a" holding "\x00😀", in Modified UTF-8 and in UTF-8.
+	mutf8 := []byte{tagString, 0, 1, 'a', 0, 8, 0xC0, 0x80, 0xED, 0xA0, 0xBD, 0xED, 0xB8, 0x80}
+	utf8 := []byte{tagString, 0, 1, 'a', 0, 5, 0x00, 0xF0, 0x9F, 0x98, 0x80}
+
+	successCases := []struct {
+		name  string
+		opts  []Option
+		input []byte
+	}{
+		{"big-endian defaults to Modified UTF-8", nil, mutf8},
+		{"Java edition preset", []Option{JavaEdition}, mutf8},
+		{"explicit UTF-8", []Option{WithStringEncoding(StringEncodingUTF8)}, utf8},
+		{"explicit Modified UTF-8 little-endian", []Option{WithByteOrder(binary.LittleEndian),
+			WithStringEncoding(StringEncodingMUTF8)}, []byte{tagString, 1, 0, 'a', 8, 0, 0xC0, 0x80, 0xED, 0xA0, 0xBD,
+			0xED, 0xB8, 0x80}},
+		{"little-endian defaults to UTF-8", []Option{WithByteOrder(binary.LittleEndian)},
+			[]byte{tagString, 1, 0, 'a', 5, 0, 0x00, 0xF0, 0x9F, 0x98, 0x80}},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			got, err := ReadTag(bytes.NewBuffer(successCase.input), successCase.opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got.payload != "\x00😀" {
+				t.Errorf("got %q, want %q", got.payload, "\x00😀")
+			}
+
+			var buffer bytes.Buffer
+			if err = WriteTag(&buffer, got, successCase.opts...); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !bytes.Equal(buffer.Bytes(), successCase.input) {
+				t.Errorf("got % X, want % X", buffer.Bytes(), successCase.input)
+			}
+		})
+	}
+
+	t.Run("Test success case: lenient replaces an unpaired surrogate", func(t *testing.T) {
+		input := []byte{tagString, 0, 1, 'a', 0, 3, 0xED, 0xA0, 0xBD}
+		got, err := ReadTag(bytes.NewBuffer(input), WithLenientUTF8(true))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got.payload != "\uFFFD" {
+			t.Errorf("got %q, want a replacement character", got.payload)
+		}
+	})
+
+	t.Run("Test failure case: unpaired surrogate", func(t *testing.T) {
+		input := []byte{tagString, 0, 1, 'a', 0, 3, 0xED, 0xA0, 0xBD}
+		if _, err := ReadTag(bytes.NewBuffer(input)); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+}
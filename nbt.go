@@ -31,14 +31,63 @@ const (
 // tagDouble: float64
 // tagByteArray: []byte
 // tagString: string
-// tagList: []any, assumes the type of listed tags
+// tagList: []any, assumes the type of listed tags, with the element tag ID recorded as read (see ElementID)
 // tagCompound: []*Tag, representing child tags an omitting the tagEnd
 // tagIntArray: []int32
 // tagLongArray: []int64
+//
+// Float and double payloads hold the exact bits read, so -0.0 and NaN payloads (signalling or quiet) are kept as is.
+//
+// Tags are built with the constructors, such as NewInt, NewList and NewCompound, which ensure each payload has the
+// type of its tag ID.
 type Tag struct {
-	id      uint8
-	name    string
-	payload any
+	id        uint8
+	elementID uint8
+	name      string
+	payload   any
+	source    *Source
+}
+
+// Source is where a decoded tag was read from, recorded when decoding with WithProvenance so tools can point users to
+// the exact on-disk location of a tag.
+type Source struct {
+	// File names the input, such as the path of the file decoded.
+	File string
+	// Offset is the byte offset of the tag's ID within the input, after any decompression.
+	Offset int64
+}
+
+// String returns the source as "file at byte offset".
+func (s Source) String() string {
+	return fmt.Sprintf("%v at byte %v", s.File, s.Offset)
+}
+
+// Source returns where the tag was read from. The boolean is false if provenance was not recorded, as for tags that
+// were not decoded with WithProvenance, and for list elements which are not decoded as tags.
+func (t *Tag) Source() (Source, bool) {
+	if t.source == nil {
+		return Source{}, false
+	}
+	return *t.source, true
+}
+
+// ElementID returns the tag ID of the elements of a tagList, as declared when it was read, so an empty list keeps its
+// element type. For a list that was not read, such as a list nested in another list, the ID is taken from the first
+// element, and is tagEnd if the list is empty. Tags other than a tagList return tagEnd.
+func (t *Tag) ElementID() uint8 {
+	if t.id != tagList {
+		return tagEnd
+	}
+	if t.elementID != tagEnd {
+		return t.elementID
+	}
+
+	elements, _ := t.payload.([]any)
+	id, err := listElementID(elements)
+	if err != nil {
+		return tagEnd
+	}
+	return id
 }
 
 // tagType returns the name associated with the tag ID
@@ -75,3 +124,53 @@ func (t *Tag) tagType() (tagType string, err error) {
 	}
 	return tagType, err
 }
+
+// payloadID returns the tag ID matching the Go type of a payload, as held by a Tag or a tagList element. A nil payload
+// has no type and is treated as an error, as is any type not listed against the Tag type.
+func payloadID(payload any) (id uint8, err error) {
+	switch payload.(type) {
+	case byte:
+		id = tagByte
+	case int16:
+		id = tagShort
+	case int32:
+		id = tagInt
+	case int64:
+		id = tagLong
+	case float32:
+		id = tagFloat
+	case float64:
+		id = tagDouble
+	case []byte:
+		id = tagByteArray
+	case string:
+		id = tagString
+	case []any:
+		id = tagList
+	case []Tag:
+		id = tagCompound
+	case []int32:
+		id = tagIntArray
+	case []int64:
+		id = tagLongArray
+	default:
+		err = fmt.Errorf("payload type %T does not match any tag type", payload)
+	}
+	return id, err
+}
+
+// integerPayload returns an integer payload as an int64, with a tagByte as signed.
+func integerPayload(payload any) (i int64, ok bool) {
+	switch p := payload.(type) {
+	case byte:
+		return int64(int8(p)), true // #nosec G115 -- tagByte is signed
+	case int16:
+		return int64(p), true
+	case int32:
+		return int64(p), true
+	case int64:
+		return p, true
+	default:
+		return 0, false
+	}
+}
@@ -9,19 +9,19 @@ func TestTagType(t *testing.T) {
 		wantTagType string
 		t           Tag
 	}{
-		{"tagEnd (0)", "tagEnd", Tag{0, "", nil}},
-		{"tagByte (1)", "tagByte", Tag{1, "", nil}},
-		{"tagShort (2)", "tagShort", Tag{2, "", nil}},
-		{"tagInt (3)", "tagInt", Tag{3, "", nil}},
-		{"tagLong (4)", "tagLong", Tag{4, "", nil}},
-		{"tagFloat (5)", "tagFloat", Tag{5, "", nil}},
-		{"tagDouble (6)", "tagDouble", Tag{6, "", nil}},
-		{"tagByteArray (7)", "tagByteArray", Tag{7, "", nil}},
-		{"tagString (8)", "tagString", Tag{8, "", nil}},
-		{"tagList (9)", "tagList", Tag{9, "", nil}},
-		{"tagCompound (10)", "tagCompound", Tag{10, "", nil}},
-		{"tagIntArray (11)", "tagIntArray", Tag{11, "", nil}},
-		{"tagLongArray (12)", "tagLongArray", Tag{12, "", nil}},
+		{"tagEnd (0)", "tagEnd", Tag{id: 0, payload: nil}},
+		{"tagByte (1)", "tagByte", Tag{id: 1, payload: nil}},
+		{"tagShort (2)", "tagShort", Tag{id: 2, payload: nil}},
+		{"tagInt (3)", "tagInt", Tag{id: 3, payload: nil}},
+		{"tagLong (4)", "tagLong", Tag{id: 4, payload: nil}},
+		{"tagFloat (5)", "tagFloat", Tag{id: 5, payload: nil}},
+		{"tagDouble (6)", "tagDouble", Tag{id: 6, payload: nil}},
+		{"tagByteArray (7)", "tagByteArray", Tag{id: 7, payload: nil}},
+		{"tagString (8)", "tagString", Tag{id: 8, payload: nil}},
+		{"tagList (9)", "tagList", Tag{id: 9, payload: nil}},
+		{"tagCompound (10)", "tagCompound", Tag{id: 10, payload: nil}},
+		{"tagIntArray (11)", "tagIntArray", Tag{id: 11, payload: nil}},
+		{"tagLongArray (12)", "tagLongArray", Tag{id: 12, payload: nil}},
 	}
 	for _, successCase := range successCases {
 		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
@@ -36,10 +36,91 @@ func TestTagType(t *testing.T) {
 	}
 
 	t.Run("Test failure case: tag id out of range", func(t *testing.T) {
-		failTag := Tag{13, "", nil}
+		failTag := Tag{id: 13, payload: nil}
 		_, gotErr := failTag.tagType()
 		if gotErr == nil {
 			t.Errorf("got nil, want non-nil")
 		}
 	})
 }
+
+func TestPayloadID(t *testing.T) {
+	successCases := []struct {
+		name    string
+		wantID  uint8
+		payload any
+	}{
+		{"tagByte", tagByte, byte(1)},
+		{"tagShort", tagShort, int16(1)},
+		{"tagInt", tagInt, int32(1)},
+		{"tagLong", tagLong, int64(1)},
+		{"tagFloat", tagFloat, float32(1)},
+		{"tagDouble", tagDouble, float64(1)},
+		{"tagByteArray", tagByteArray, []byte{1}},
+		{"tagString", tagString, "1"},
+		{"tagList", tagList, []any{int32(1)}},
+		{"tagCompound", tagCompound, []Tag{{id: tagByte, name: "1", payload: byte(1)}}},
+		{"tagIntArray", tagIntArray, []int32{1}},
+		{"tagLongArray", tagLongArray, []int64{1}},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			gotID, gotErr := payloadID(successCase.payload)
+			if gotID != successCase.wantID {
+				t.Errorf("got %v, want %v", gotID, successCase.wantID)
+			}
+			if gotErr != nil {
+				t.Errorf("got %v, want nil", gotErr)
+			}
+		})
+	}
+
+	failureCases := []struct {
+		name    string
+		payload any
+	}{
+		{"nil payload", nil},
+		{"unsigned int", uint32(1)},
+		{"int", 1},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			_, gotErr := payloadID(failureCase.payload)
+			if gotErr == nil {
+				t.Errorf("got nil, want non-nil")
+			}
+		})
+	}
+}
+
+func TestElementID(t *testing.T) {
+	successCases := []struct {
+		name string
+		want uint8
+		t    Tag
+	}{
+		{"declared empty list", tagString, Tag{id: tagList, elementID: tagString}},
+		{"declared list", tagShort, Tag{id: tagList, elementID: tagShort, payload: []any{int16(1)}}},
+		{"undeclared list", tagInt, Tag{id: tagList, payload: []any{int32(1)}}},
+		{"undeclared empty list", tagEnd, Tag{id: tagList}},
+		{"undeclared list of unknown payloads", tagEnd, Tag{id: tagList, payload: []any{uint(1)}}},
+		{"not a list", tagEnd, Tag{id: tagByteArray, payload: []byte{1}}},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			if got := successCase.t.ElementID(); got != successCase.want {
+				t.Errorf("got %v, want %v", got, successCase.want)
+			}
+			if got := NewView(successCase.t).ElementID(); got != successCase.want {
+				t.Errorf("got %v from view, want %v", got, successCase.want)
+			}
+		})
+	}
+}
+
+func TestSourceString(t *testing.T) {
+	got := Source{File: "r.0.0.mca", Offset: 8192}.String()
+	if got != "r.0.0.mca at byte 8192" {
+		t.Errorf("got %v, want r.0.0.mca at byte 8192", got)
+	}
+}
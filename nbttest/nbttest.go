@@ -0,0 +1,197 @@
+// Package nbttest provides helpers for testing code that produces or consumes NBT tag trees: path-level comparison of
+// trees, golden file comparison and builders of fixture trees.
+package nbttest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"PudFish/nbt"
+)
+
+// update rewrites golden files with the trees under test instead of comparing against them. Run the tests with
+// -nbttest.update to accept new output.
+var update = flag.Bool("nbttest.update", false, "update nbttest golden files")
+
+// AssertEqual reports a test error for every path at which the got tree differs from the want tree. The options are
+// those of nbt.Equal, such as nbt.WithFloatTolerance.
+func AssertEqual(t testing.TB, want, got nbt.Tag, opts ...nbt.CompareOption) {
+	t.Helper()
+	for _, difference := range Diff(want, got, opts...) {
+		t.Errorf("%v", difference)
+	}
+}
+
+// Diff returns a description of every difference between the want and got trees found by nbt.Diff, each prefixed by
+// the path at which it was found, in the text form of nbt.Path, such as "Data.Player.Pos[1]". The root tag has the path
+// "(root)". An empty result means the trees are equal. The options are those of nbt.Equal, such as
+// nbt.WithFloatTolerance.
+func Diff(want, got nbt.Tag, opts ...nbt.CompareOption) (differences []string) {
+	for _, change := range nbt.Diff(&want, &got, opts...) {
+		differences = append(differences, describeChange(change))
+	}
+	return differences
+}
+
+// describeChange describes a change from the want tree to the got tree, as returned by nbt.Diff.
+func describeChange(change nbt.Change) string {
+	path := displayPath(change.Path.String())
+	want, got := nbt.NewView(change.Old), nbt.NewView(change.New)
+	switch {
+	case change.Kind == nbt.ChangeAdded:
+		return fmt.Sprintf("%v: unexpected %v", path, describe(got))
+	case change.Kind == nbt.ChangeRemoved:
+		return fmt.Sprintf("%v: missing, want %v", path, describe(want))
+	case want.ID() != got.ID():
+		wantType, _ := want.Type()
+		gotType, _ := got.Type()
+		return fmt.Sprintf("%v: got %v, want %v", path, gotType, wantType)
+	case want.Name() != got.Name():
+		return fmt.Sprintf("%v: got name %q, want name %q", path, got.Name(), want.Name())
+	case want.ElementID() != got.ElementID():
+		return fmt.Sprintf("%v: got elements of tag ID %v, want tag ID %v", path, got.ElementID(), want.ElementID())
+	default:
+		return fmt.Sprintf("%v: got %v, want %v", path, got.Payload(), want.Payload())
+	}
+}
+
+// Golden compares the text dump of got against the golden file at path, reporting a test error if they differ. When
+// the tests are run with -nbttest.update the golden file is written instead.
+func Golden(t testing.TB, path string, got nbt.Tag) {
+	t.Helper()
+	dump := Dump(got)
+
+	if *update {
+		err := os.WriteFile(path, []byte(dump), 0o600)
+		if err != nil {
+			t.Fatalf("Unable to update golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path) // #nosec G304 -- golden file paths are chosen by the test author
+	if err != nil {
+		t.Fatalf("Unable to read golden file: %v", err)
+	}
+
+	if string(want) != dump {
+		t.Errorf("%v: tree does not match golden file, got:\n%v", path, dump)
+	}
+}
+
+// Dump returns a line-per-tag text representation of a tree, suited to golden files and diffing tools. Each line holds
+// the path, tag type and, for tags other than compounds and lists, the payload.
+func Dump(t nbt.Tag) string {
+	var b strings.Builder
+	dumpView(&b, "", nbt.NewView(t))
+	return b.String()
+}
+
+// dumpView writes a view and all of its descendants to b.
+func dumpView(b *strings.Builder, path string, v nbt.View) {
+	fmt.Fprintf(b, "%v %v\n", displayPath(path), describe(v))
+
+	switch tagType, _ := v.Type(); tagType {
+	case "tagCompound":
+		for _, child := range v.Children() {
+			dumpView(b, joinPath(path, child.Name()), child)
+		}
+	case "tagList":
+		for i := range v.Len() {
+			element, _ := v.Element(i)
+			dumpView(b, fmt.Sprintf("%v[%v]", path, i), element)
+		}
+	}
+}
+
+// describe returns the type and payload of a view.
+func describe(v nbt.View) string {
+	tagType, _ := v.Type()
+	switch p := v.Payload().(type) {
+	case nil:
+		return tagType
+	case string:
+		return tagType + " " + strconv.Quote(p)
+	case float32:
+		return tagType + " " + strconv.FormatFloat(float64(p), 'g', -1, 32)
+	case float64:
+		return tagType + " " + strconv.FormatFloat(p, 'g', -1, 64)
+	default:
+		return fmt.Sprintf("%v %v", tagType, p)
+	}
+}
+
+// joinPath appends a compound child name to a path.
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// displayPath shows the root path as "(root)" rather than an empty string.
+func displayPath(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
+
+// Compound returns a tagCompound of the children, failing the test if they are not valid children, see
+// nbt.NewCompound.
+func Compound(t testing.TB, name string, children ...nbt.Tag) nbt.Tag {
+	t.Helper()
+	compound, err := nbt.NewCompound(name, children...)
+	if err != nil {
+		t.Fatalf("Unable to build fixture: %v", err)
+	}
+	return compound
+}
+
+// List returns a tagList of the elements, whose element ID is that of the first element, or nbt.IDEnd if there are
+// none. It fails the test if the elements do not all have the same tag ID.
+func List(t testing.TB, name string, elements ...nbt.Tag) nbt.Tag {
+	t.Helper()
+	elementID := uint8(nbt.IDEnd)
+	if len(elements) > 0 {
+		elementID = nbt.NewView(elements[0]).ID()
+	}
+	list, err := nbt.NewList(name, elementID, elements...)
+	if err != nil {
+		t.Fatalf("Unable to build fixture: %v", err)
+	}
+	return list
+}
+
+// Section returns a chunk section at section Y y in the layout of Java edition 1.18 and later, filled with the block.
+func Section(t testing.TB, y int8, block nbt.BlockState) nbt.Tag {
+	t.Helper()
+	states := Compound(t, "block_states", List(t, "palette", block.Tag()))
+	return Compound(t, "", nbt.NewByte("Y", byte(y)), states) // #nosec G115 -- stored as a signed byte
+}
+
+// Chunk returns a chunk at chunk coordinates x and z in the layout of Java edition 1.18 and later, holding the
+// sections, such as those built by Section.
+func Chunk(t testing.TB, chunkX, chunkZ int32, sections ...nbt.Tag) nbt.Tag {
+	t.Helper()
+	return Compound(t, "", nbt.NewInt("xPos", chunkX), nbt.NewInt("zPos", chunkZ), nbt.NewString("Status",
+		"minecraft:full"), List(t, "sections", sections...))
+}
+
+// Entity returns an entity of the namespaced ID, such as "minecraft:zombie", at the position.
+func Entity(t testing.TB, id string, x, y, z float64) nbt.Tag {
+	t.Helper()
+	pos := List(t, "Pos", nbt.NewDouble("", x), nbt.NewDouble("", y), nbt.NewDouble("", z))
+	return Compound(t, "", nbt.NewString("id", id), pos)
+}
+
+// Level returns the root compound of a level.dat file of a world with the name, saved at the data version.
+func Level(t testing.TB, name string, dataVersion int32) nbt.Tag {
+	t.Helper()
+	data := Compound(t, "Data", nbt.NewString("LevelName", name), nbt.NewInt("DataVersion", dataVersion))
+	return Compound(t, "", data)
+}
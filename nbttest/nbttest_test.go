@@ -0,0 +1,195 @@
+package nbttest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"PudFish/nbt"
+)
+
+// recorder captures the errors reported by the helpers under test rather than failing the test.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// readTag decodes a little endian tag for use as a fixture.
+func readTag(t *testing.T, input []byte) nbt.Tag {
+	t.Helper()
+	tag, err := nbt.ReadTag(bytes.NewBuffer(input), nbt.WithByteOrder(binary.LittleEndian))
+	if err != nil {
+		t.Fatalf("Unable to read fixture: %v", err)
+	}
+	return tag
+}
+
+// level is a compound holding a string "Name", an int "Score" and a list "Pos" of two floats.
+var level = []byte{0x0A, 0x00, 0x00,
+	0x08, 0x04, 0x00, 0x4E, 0x61, 0x6D, 0x65, 0x05, 0x00, 0x53, 0x74, 0x65, 0x76, 0x65,
+	0x03, 0x05, 0x00, 0x53, 0x63, 0x6F, 0x72, 0x65, 0x0A, 0x00, 0x00, 0x00,
+	0x09, 0x03, 0x00, 0x50, 0x6F, 0x73, 0x05, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0xC0, 0x3F, 0x00, 0x00, 0x80, 0x42,
+	0x00}
+
+func TestDiff(t *testing.T) {
+	changedScore := bytes.Replace(level, []byte{0x0A, 0x00, 0x00, 0x00, 0x09}, []byte{0x0B, 0x00, 0x00, 0x00, 0x09},
+		1)
+	renamedName := bytes.Replace(level, []byte("Name"), []byte("Nick"), 1)
+	changedPos := bytes.Replace(level, []byte{0x80, 0x42}, []byte{0x82, 0x42}, 1)
+	shortPos := bytes.Replace(level, []byte{0x05, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0xC0, 0x3F},
+		[]byte{0x05, 0x01, 0x00, 0x00, 0x00}, 1)
+
+	successCases := []struct {
+		name      string
+		wantDiffs []string
+		got       []byte
+	}{
+		{"equal trees", nil, level},
+		{"changed payload", []string{"Score: got 11, want 10"}, changedScore},
+		{"renamed child", []string{"Name: missing, want tagString \"Steve\"", "Nick: unexpected tagString \"Steve\""},
+			renamedName},
+		{"changed list element", []string{"Pos[1]: got 65, want 64"}, changedPos},
+		{"shorter list", []string{"Pos[0]: got 64, want 1.5", "Pos[1]: missing, want tagFloat 64"}, shortPos},
+		{"different type", []string{"(root): got tagInt, want tagCompound"}, []byte{0x03, 0x00, 0x00, 0x01, 0x00,
+			0x00, 0x00}},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			gotDiffs := Diff(readTag(t, level), readTag(t, successCase.got))
+			if fmt.Sprint(gotDiffs) != fmt.Sprint(successCase.wantDiffs) {
+				t.Errorf("got %q, want %q", gotDiffs, successCase.wantDiffs)
+			}
+		})
+	}
+
+	t.Run("Test success case: empty lists of different element types", func(t *testing.T) {
+		stringList, _ := nbt.NewList("l", nbt.IDString)
+		intList, _ := nbt.NewList("l", nbt.IDInt)
+		want := []string{"l: got elements of tag ID 3, want tag ID 8"}
+		if got := Diff(Compound(t, "", stringList), Compound(t, "", intList)); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("Test success case: floats within the tolerance", func(t *testing.T) {
+		changed := readTag(t, changedPos)
+		if got := Diff(readTag(t, level), changed, nbt.WithFloatTolerance(1)); len(got) != 0 {
+			t.Errorf("got %q, want no differences", got)
+		}
+	})
+}
+
+func TestAssertEqual(t *testing.T) {
+	t.Run("Test success case: equal trees report nothing", func(t *testing.T) {
+		r := &recorder{TB: t}
+		AssertEqual(r, readTag(t, level), readTag(t, level))
+		if len(r.errors) != 0 {
+			t.Errorf("got %v, want no errors", r.errors)
+		}
+	})
+
+	t.Run("Test success case: each difference is reported", func(t *testing.T) {
+		r := &recorder{TB: t}
+		AssertEqual(r, readTag(t, level), readTag(t, bytes.Replace(level, []byte("Name"), []byte("Nick"), 1)))
+		if len(r.errors) != 2 {
+			t.Errorf("got %v, want 2 errors", r.errors)
+		}
+	})
+}
+
+func TestGolden(t *testing.T) {
+	t.Run("Test success case: matching golden file", func(t *testing.T) {
+		r := &recorder{TB: t}
+		Golden(r, filepath.Join("testdata", "level.golden"), readTag(t, level))
+		if len(r.errors) != 0 {
+			t.Errorf("got %v, want no errors", r.errors)
+		}
+	})
+
+	t.Run("Test failure case: mismatched golden file", func(t *testing.T) {
+		r := &recorder{TB: t}
+		Golden(r, filepath.Join("testdata", "level.golden"), readTag(t, []byte{0x03, 0x00, 0x00, 0x01, 0x00, 0x00,
+			0x00}))
+		if len(r.errors) != 1 {
+			t.Errorf("got %v, want 1 error", r.errors)
+		}
+	})
+}
+
+func TestBuilders(t *testing.T) {
+	t.Run("Test success case: compound and list", func(t *testing.T) {
+		got := Compound(t, "", nbt.NewString("Name", "Steve"), nbt.NewInt("Score", 10),
+			List(t, "Pos", nbt.NewFloat("", 1.5), nbt.NewFloat("", 64)))
+		AssertEqual(t, readTag(t, level), got)
+	})
+
+	t.Run("Test success case: empty list", func(t *testing.T) {
+		if got := nbt.NewView(List(t, "empty")); got.Len() != 0 {
+			t.Errorf("got length %v, want 0", got.Len())
+		}
+	})
+
+	t.Run("Test success case: chunk of sections", func(t *testing.T) {
+		stone := nbt.BlockState{Name: "minecraft:stone"}
+		chunk := Chunk(t, 1, 2, Section(t, -1, stone), Section(t, 0, nbt.BlockState{Name: "minecraft:air"}))
+		found := 0
+		for pos, err := range nbt.FindBlocks(chunk, func(state nbt.BlockState) bool { return state.Name == stone.Name }) {
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if found == 0 && pos != (nbt.BlockPos{X: 16, Y: -16, Z: 32}) {
+				t.Errorf("got first block %v, want 16,-16,32", pos)
+			}
+			found++
+		}
+		if found != 16*16*16 {
+			t.Errorf("got %v blocks, want %v", found, 16*16*16)
+		}
+	})
+
+	t.Run("Test success case: entity", func(t *testing.T) {
+		entity := Entity(t, "minecraft:zombie", 1, 2, 3)
+		entities, err := nbt.Entities(Compound(t, "", List(t, "Entities", entity)))
+		if err != nil || len(entities) != 1 {
+			t.Fatalf("got %v, %v, want one entity", entities, err)
+		}
+		AssertEqual(t, entity, entities[0])
+	})
+
+	t.Run("Test success case: level", func(t *testing.T) {
+		root := Level(t, "World", 3953)
+		name, err := nbt.Get[string](&root, "Data.LevelName")
+		if err != nil || name != "World" {
+			t.Errorf("got %v, %v, want World", name, err)
+		}
+	})
+
+	failureCases := []struct {
+		name  string
+		build func(t testing.TB)
+	}{
+		{"list of mixed tags", func(t testing.TB) { List(t, "mixed", nbt.NewInt("", 1), nbt.NewByte("", 1)) }},
+		{"duplicate children", func(t testing.TB) { Compound(t, "", nbt.NewInt("a", 1), nbt.NewInt("a", 2)) }},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			r := &recorder{TB: t}
+			failureCase.build(r)
+			if len(r.errors) != 1 {
+				t.Errorf("got %v, want 1 error", r.errors)
+			}
+		})
+	}
+}
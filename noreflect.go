@@ -0,0 +1,36 @@
+//go:build nbt_noreflect
+
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import "fmt"
+
+// Building with the nbt_noreflect tag leaves out the reflection based Marshal, MarshalTag, Unmarshal and UnmarshalTag,
+// for targets such as TinyGo and WASM where reflection is limited or costly. Tags are then built with the constructors
+// and read with the accessors, and the Encoder and Decoder only take a Tag.
+
+// marshalAny returns v as an unnamed tag, as MarshalTag does, if it is a Tag or *Tag, as nothing else can be converted
+// without reflection.
+func marshalAny(v any) (Tag, error) {
+	switch p := v.(type) {
+	case Tag:
+		p.name = ""
+		return p, nil
+	case *Tag:
+		t := *p
+		t.name = ""
+		return t, nil
+	default:
+		return Tag{}, fmt.Errorf("Unable to marshal %T: built with nbt_noreflect, only a Tag can be marshalled", v)
+	}
+}
+
+// unmarshalAny stores the tag in v if it is a non-nil *Tag, as nothing else can be stored in without reflection.
+func unmarshalAny(t Tag, v any) error {
+	p, ok := v.(*Tag)
+	if !ok || p == nil {
+		return fmt.Errorf("Unable to unmarshal into %T: built with nbt_noreflect, only a *Tag can be unmarshalled into", v)
+	}
+	*p = t
+	return nil
+}
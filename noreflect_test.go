@@ -0,0 +1,50 @@
+//go:build nbt_noreflect
+
+package nbt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNoReflect(t *testing.T) {
+	t.Run("Test success case: Encoder and Decoder take tags", func(t *testing.T) {
+		var b bytes.Buffer
+		if err := NewEncoder(&b).Encode(NewInt("a", 1)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var got Tag
+		if err := NewDecoder(&b).Decode(&got); err != nil || got.name != "a" || got.payload != int32(1) {
+			t.Errorf("got %v %v, want a:1", got, err)
+		}
+	})
+
+	t.Run("Test success case: FromMap without Go values needing reflection", func(t *testing.T) {
+		got, err := FromMap(map[string]any{"a": int32(1), "t": NewString("", "x")}, NarrowInt)
+		if err != nil || got.String() != `{a:1,t:"x"}` {
+			t.Errorf("got %v %v", got, err)
+		}
+	})
+
+	t.Run("Test failure case: encode a struct", func(t *testing.T) {
+		if err := NewEncoder(&bytes.Buffer{}).Encode(struct{ A int32 }{1}); err == nil {
+			t.Errorf("got nil, want non-nil")
+		}
+	})
+
+	t.Run("Test failure case: decode into a struct", func(t *testing.T) {
+		var b bytes.Buffer
+		_ = NewEncoder(&b).Encode(NewInt("a", 1))
+		var got struct{ A int32 }
+		if err := NewDecoder(&b).Decode(&got); err == nil {
+			t.Errorf("got nil, want non-nil")
+		}
+	})
+
+	t.Run("Test failure case: FromMap of a struct", func(t *testing.T) {
+		if _, err := FromMap(map[string]any{"s": struct{ A int32 }{1}}, NarrowInt); err == nil {
+			t.Errorf("got nil, want non-nil")
+		}
+	})
+}
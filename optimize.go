@@ -0,0 +1,158 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"fmt"
+	"slices"
+)
+
+// OptimizeChunk returns a copy of a chunk, in the layout of Java edition 1.18 and later, with every section optimised
+// by OptimizeSection. The given chunk is not modified.
+func OptimizeChunk(chunk Tag) (Tag, error) {
+	t, err := editPath(chunk, Path{"sections"}, func(sections Tag) (Tag, error) {
+		elements, ok := sections.payload.([]any)
+		if sections.id != tagList || (!ok && sections.payload != nil) {
+			return Tag{}, fmt.Errorf("\"sections\" is not a tagList")
+		}
+
+		optimized := make([]any, len(elements))
+		for i, element := range elements {
+			section, err := OptimizeSection(Tag{id: tagCompound, payload: element})
+			if err != nil {
+				return Tag{}, fmt.Errorf("section %v: %w", i, err)
+			}
+			optimized[i] = section.payload
+		}
+		sections.payload = optimized
+		return sections, nil
+	})
+	if err != nil {
+		return Tag{}, fmt.Errorf("Unable to optimise chunk: %w", err)
+	}
+	return t, nil
+}
+
+// OptimizeSection returns a copy of a chunk section, in the layout of Java edition 1.18 and later, with unused
+// entries removed from and duplicate entries merged in its block_states and biomes palettes. The data is repacked at
+// the fewest bits per entry for the smaller palette, and dropped for a single entry palette, as the game does. The
+// given section is not modified.
+func OptimizeSection(section Tag) (Tag, error) {
+	containers := []struct {
+		name  string
+		count int
+		bits  func(int) int
+	}{
+		{"block_states", sectionBlocks, blockStateBits},
+		{"biomes", sectionBiomes, biomeBits},
+	}
+
+	for _, container := range containers {
+		if _, ok := compoundChild(section, container.name); !ok {
+			continue
+		}
+
+		var err error
+		section, err = editPath(section, Path{container.name}, func(states Tag) (Tag, error) {
+			return optimizeContainer(states, container.count, container.bits)
+		})
+		if err != nil {
+			return Tag{}, fmt.Errorf("Unable to optimise section %v: %w", container.name, err)
+		}
+	}
+	return section, nil
+}
+
+// optimizeContainer optimises the palette and data of a block_states or biomes compound holding count entries packed
+// at the bits per entry for the palette length.
+func optimizeContainer(container Tag, count int, bits func(int) int) (Tag, error) {
+	palette, ok := childPayload[[]any](container, "palette")
+	if !ok || len(palette) == 0 {
+		return Tag{}, fmt.Errorf("no tagList named \"palette\"")
+	}
+	data, _ := childPayload[[]int64](container, "data")
+
+	indices, err := unpackIndices(data, bits(len(palette)), count)
+	if err != nil {
+		return Tag{}, err
+	}
+	palette, err = optimizePalette(palette, indices)
+	if err != nil {
+		return Tag{}, err
+	}
+
+	id, _ := listElementID(palette)
+	children, _ := container.payload.([]Tag)
+	children = withChild(children, Tag{id: tagList, elementID: id, name: "palette", payload: palette})
+	if packed := packIndices(indices, bits(len(palette))); packed != nil {
+		children = withChild(children, Tag{id: tagLongArray, name: "data", payload: packed})
+	} else {
+		children, _ = withoutChild(children, "data")
+	}
+	container.payload = children
+	return container, nil
+}
+
+// optimizePalette returns the palette without unused or duplicate entries, kept in the order first used, and remaps
+// the indices into it in place.
+func optimizePalette(palette []any, indices []int) ([]any, error) {
+	var optimized []any
+	remap := make([]int, len(palette))
+	for i := range remap {
+		remap[i] = -1
+	}
+
+	for i, index := range indices {
+		if index >= len(palette) {
+			return nil, fmt.Errorf("index %v of entry %v is beyond the palette of %v", index, i, len(palette))
+		}
+		if remap[index] < 0 {
+			remap[index] = slices.IndexFunc(optimized, func(entry any) bool {
+				return payloadsEqual(entry, palette[index])
+			})
+			if remap[index] < 0 {
+				remap[index] = len(optimized)
+				optimized = append(optimized, palette[index])
+			}
+		}
+		indices[i] = remap[index]
+	}
+	return optimized, nil
+}
+
+// OptimizeStructure returns a copy of the root compound of a structure template with unused entries removed from and
+// duplicate entries merged in its palette, remapping the state of each block. Templates with several palettes, as
+// used for shipwrecks, are not supported. The given template is not modified.
+func OptimizeStructure(t Tag) (Tag, error) {
+	if _, ok := compoundChild(t, "palettes"); ok {
+		return Tag{}, fmt.Errorf("Unable to optimise structure: templates with several palettes are not supported")
+	}
+	palette, ok := childPayload[[]any](t, "palette")
+	blocks, _ := childPayload[[]any](t, "blocks")
+	if !ok && len(blocks) > 0 {
+		return Tag{}, fmt.Errorf("Unable to optimise structure: no tagList named \"palette\"")
+	}
+
+	indices := make([]int, len(blocks))
+	for i, block := range blocks {
+		state, ok := childPayload[int32](Tag{id: tagCompound, payload: block}, "state")
+		if !ok || state < 0 {
+			return Tag{}, fmt.Errorf("Unable to optimise structure: block %v has no valid tagInt \"state\"", i)
+		}
+		indices[i] = int(state)
+	}
+
+	palette, err := optimizePalette(palette, indices)
+	if err != nil {
+		return Tag{}, fmt.Errorf("Unable to optimise structure: %w", err)
+	}
+
+	optimized := make([]any, len(blocks))
+	for i, block := range blocks {
+		state := int32(indices[i]) // #nosec G115 -- a palette index read from an int32
+		optimized[i] = withChild(block.([]Tag), Tag{id: tagInt, name: "state", payload: state})
+	}
+	children, _ := t.payload.([]Tag)
+	children = withChild(children, Tag{id: tagList, elementID: tagCompound, name: "palette", payload: palette})
+	t.payload = withChild(children, Tag{id: tagList, elementID: tagCompound, name: "blocks", payload: optimized})
+	return t, nil
+}
@@ -0,0 +1,172 @@
+package nbt
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// testSection returns a chunk section whose block states use the palette entries at the indices.
+func testSection(palette []any, indices []int, biomes []any) Tag {
+	return Tag{id: tagCompound, payload: []Tag{
+		{id: tagByte, name: "Y", payload: byte(0)},
+		{id: tagCompound, name: "block_states", payload: []Tag{
+			{id: tagList, elementID: tagCompound, name: "palette", payload: palette},
+			{id: tagLongArray, name: "data", payload: packIndices(indices, blockStateBits(len(palette)))},
+		}},
+		{id: tagCompound, name: "biomes", payload: []Tag{
+			{id: tagList, elementID: tagString, name: "palette", payload: biomes},
+			{id: tagLongArray, name: "data", payload: packIndices(make([]int, sectionBiomes), biomeBits(len(biomes)))},
+		}},
+	}}
+}
+
+func TestOptimizeSection(t *testing.T) {
+	stone := BlockState{Name: "minecraft:stone"}.Tag().payload
+	dirt := BlockState{Name: "minecraft:dirt"}.Tag().payload
+	gravel := BlockState{Name: "minecraft:gravel"}.Tag().payload
+
+	mixed, merged := make([]int, sectionBlocks), make([]int, sectionBlocks)
+	for i := range mixed {
+		mixed[i] = []int{0, 2, 3}[i%3]
+		merged[i] = []int{0, 0, 1}[i%3]
+	}
+	duplicates := make([]int, sectionBlocks)
+	for i := range duplicates {
+		duplicates[i] = i % 2 * 2
+	}
+
+	tests := []struct {
+		name        string
+		input       Tag
+		wantPalette []any
+		wantIndices []int
+		wantErr     bool
+	}{
+		{
+			name:        "Test success case: unused and duplicate entries",
+			input:       testSection([]any{stone, gravel, stone, dirt}, mixed, []any{"minecraft:plains"}),
+			wantPalette: []any{stone, dirt},
+			wantIndices: merged,
+		},
+		{
+			name:        "Test success case: single entry drops data",
+			input:       testSection([]any{stone, dirt, stone}, duplicates, []any{"minecraft:plains", "minecraft:desert"}),
+			wantPalette: []any{stone},
+		},
+		{
+			name:    "Test failure case: index beyond palette",
+			input:   testSection([]any{stone, dirt}, mixed, []any{"minecraft:plains"}),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := OptimizeSection(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			states, _ := compoundChild(got, "block_states")
+			palette, _ := compoundChild(states, "palette")
+			if !reflect.DeepEqual(palette.payload, tt.wantPalette) || palette.elementID != tagCompound {
+				t.Errorf("got palette %+v, want %+v", palette, tt.wantPalette)
+			}
+			data, hasData := childPayload[[]int64](states, "data")
+			if hasData != (tt.wantIndices != nil) {
+				t.Fatalf("got data %v, want data %v", hasData, tt.wantIndices != nil)
+			}
+			if hasData {
+				indices, err := unpackIndices(data, blockStateBits(len(tt.wantPalette)), sectionBlocks)
+				if err != nil || !reflect.DeepEqual(indices, tt.wantIndices) {
+					t.Errorf("got indices %v %v, want %v", indices[:6], err, tt.wantIndices[:6])
+				}
+			}
+
+			biomes, _ := compoundChild(got, "biomes")
+			if palette, _ := childPayload[[]any](biomes, "palette"); len(palette) != 1 {
+				t.Errorf("got biome palette %v, want plains only", palette)
+			}
+			if _, ok := compoundChild(biomes, "data"); ok {
+				t.Errorf("got biome data, want none for a single biome")
+			}
+		})
+	}
+}
+
+func TestOptimizeChunk(t *testing.T) {
+	chunk := ChunkGenerator{Fill: 0.5}.Chunk(rand.New(rand.NewSource(1)), 0, 0)
+	got, err := OptimizeChunk(chunk)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	before, _ := childPayload[[]any](chunk, "sections")
+	after, _ := childPayload[[]any](got, "sections")
+	if len(after) != len(before) {
+		t.Fatalf("got %v sections, want %v", len(after), len(before))
+	}
+	for i := range after {
+		if _, err := OptimizeSection(Tag{id: tagCompound, payload: after[i]}); err != nil {
+			t.Errorf("got section %v that does not decode: %v", i, err)
+		}
+	}
+
+	if _, err := OptimizeChunk(Tag{id: tagCompound}); err == nil {
+		t.Errorf("got no error for a chunk without sections, want error")
+	}
+}
+
+func TestOptimizeStructure(t *testing.T) {
+	stone := BlockState{Name: "minecraft:stone"}
+	template, err := StructureTemplate([][][]BlockState{{{stone, stone}}}, nil, 3465)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	bloated := template
+	bloated.payload = withChild(template.payload.([]Tag), Tag{id: tagList, elementID: tagCompound, name: "palette",
+		payload: []any{BlockState{Name: "minecraft:dirt"}.Tag().payload, stone.Tag().payload, stone.Tag().payload}})
+	bloated.payload = withChild(bloated.payload.([]Tag), Tag{id: tagList, elementID: tagCompound, name: "blocks",
+		payload: []any{
+			[]Tag{{id: tagInt, name: "state", payload: int32(1)}},
+			[]Tag{{id: tagInt, name: "state", payload: int32(2)}},
+		}})
+
+	got, err := OptimizeStructure(bloated)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	palette, _ := childPayload[[]any](got, "palette")
+	blocks, _ := childPayload[[]any](got, "blocks")
+	if len(palette) != 1 || blocks[0].([]Tag)[0].payload != int32(0) || blocks[1].([]Tag)[0].payload != int32(0) {
+		t.Errorf("got palette %+v and blocks %+v, want stone only", palette, blocks)
+	}
+
+	failureCases := []struct {
+		name  string
+		input Tag
+	}{
+		{"several palettes", Tag{id: tagCompound, payload: []Tag{{id: tagList, name: "palettes"}}}},
+		{"state beyond palette", withChildTag(template, Tag{id: tagList, elementID: tagCompound, name: "blocks",
+			payload: []any{[]Tag{{id: tagInt, name: "state", payload: int32(5)}}}})},
+		{"no state", withChildTag(template, Tag{id: tagList, elementID: tagCompound, name: "blocks",
+			payload: []any{[]Tag{}}})},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			if _, err := OptimizeStructure(failureCase.input); err == nil {
+				t.Errorf("got no error, want error")
+			}
+		})
+	}
+}
+
+// withChildTag returns a copy of the compound with the child added or replaced.
+func withChildTag(t Tag, child Tag) Tag {
+	t.payload = withChild(t.payload.([]Tag), child)
+	return t
+}
@@ -0,0 +1,302 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"compress/flate"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"time"
+)
+
+// Options configure how tags are read and written. The zero value of each field is its default, except ByteOrder which
+// defaults to binary.BigEndian (Java edition) and CompressionLevel which defaults to flate.DefaultCompression. Options
+// are set with the Option functions passed to ReadTag, WriteTag and the file loaders, so new settings can be added
+// without breaking their signatures.
+type Options struct {
+	// ByteOrder is the byte order of numbers, lengths and sizes: binary.BigEndian for Java edition and
+	// binary.LittleEndian for Bedrock edition.
+	ByteOrder binary.ByteOrder
+	// Limits bound the size of the tags read, to guard against corrupt or malicious input.
+	Limits Limits
+	// Compression is the compression the tags are wrapped in.
+	Compression Compression
+	// CompressionLevel is the level tags are compressed at when written with gzip or zlib, a compress/flate level from
+	// flate.NoCompression, which stores the data uncompressed, to flate.BestCompression, or flate.DefaultCompression or
+	// flate.HuffmanOnly.
+	CompressionLevel int
+	// LenientUTF8 replaces invalid UTF-8 in tag names and tagString payloads with the Unicode replacement character,
+	// rather than failing to read the tag.
+	LenientUTF8 bool
+	// StringEncoding is the encoding of tag names and tagString payloads. The default, StringEncodingAuto, is Modified
+	// UTF-8 when the ByteOrder is big-endian (Java edition) and UTF-8 otherwise.
+	StringEncoding StringEncoding
+	// VarInt encodes the payloads of tagInt and tagLong, the elements of tagIntArray and tagLongArray, and the sizes of
+	// arrays and lists as ZigZag VarInts, and the lengths of names and strings as unsigned VarInts, as in Bedrock
+	// edition network NBT. See BedrockNetwork.
+	VarInt bool
+	// NetworkFormat reads and writes the root tag without a name, as in the Java edition network protocol since 1.20.2
+	// (protocol 764). The root tag is read with an empty name, and the name of a root tag written is dropped.
+	NetworkFormat bool
+	// Provenance records the Source of each decoded tag, retrieved with Tag.Source. SourceFile names the input in each
+	// Source, and is set to the path by the file loaders unless already set.
+	Provenance bool
+	SourceFile string
+	// ReadTimeout bounds how long each read of the input may take, and Timeout how long reading the whole tag may take.
+	// Reads past either fail with an error wrapping os.ErrDeadlineExceeded. Inputs with a SetReadDeadline method, such
+	// as a net.Conn, are interrupted at the deadline; other inputs fail once the overrunning read returns. Zero is no
+	// timeout.
+	ReadTimeout time.Duration
+	Timeout     time.Duration
+	// UnknownTags, if set, reads the payloads of tags with IDs above tagLongArray as RawPayload, rather than failing.
+	UnknownTags UnknownTagReader
+	// DuplicateKeys is what is done with a child of a tagCompound read whose name repeats that of an earlier child. The
+	// default, DuplicateKeepAll, keeps every child in the order read. It applies to tags read whole, not to the tokens
+	// read by Decoder.Token.
+	DuplicateKeys DuplicateKeyPolicy
+	// RejectTrailingData fails ReadTag and the file loaders if any bytes follow the root tag, once decompressed, rather
+	// than ignoring them. The input is read one byte past the tag, so it must end rather than block, as files do.
+	RejectTrailingData bool
+	// Checksum, if set, is written every byte of the output of WriteTag, WriteFile and an Encoder, after compression,
+	// so its Sum is the digest of the output without a second pass over it, as backup and sync tools need.
+	Checksum hash.Hash
+	// NonFinite is what is done with a NaN or ±Inf tagFloat or tagDouble payload written. The default, NonFiniteKeep,
+	// writes the bits held.
+	NonFinite NonFinitePolicy
+
+	// depth is the nesting of the compound or list whose payload is being read or written. It is incremented on the
+	// copy of the Options passed down to its children, so it is zero only for the root tag.
+	depth int
+	// allocated counts the bytes allocated by the tag being read whole, shared by the copies of the Options passed down
+	// to its children. It is nil when MaxAllocation is unlimited.
+	allocated *int64
+}
+
+// DuplicateKeyPolicy is what is done with a child of a tagCompound read whose name repeats that of an earlier child.
+// Names must be unique, but files from buggy tools repeat them.
+type DuplicateKeyPolicy int
+
+// DuplicateKeyPolicy values.
+const (
+	// DuplicateKeepAll keeps every child in the order read.
+	DuplicateKeepAll DuplicateKeyPolicy = iota
+	// DuplicateError fails to read the tag.
+	DuplicateError
+	// DuplicateFirstWins keeps the earlier child, dropping the later one.
+	DuplicateFirstWins
+	// DuplicateLastWins replaces the earlier child with the later one, in the earlier child's place, as the game does.
+	DuplicateLastWins
+)
+
+// String returns the name of the policy.
+func (p DuplicateKeyPolicy) String() string {
+	switch p {
+	case DuplicateKeepAll:
+		return "keep all"
+	case DuplicateError:
+		return "error"
+	case DuplicateFirstWins:
+		return "first wins"
+	case DuplicateLastWins:
+		return "last wins"
+	default:
+		return fmt.Sprintf("DuplicateKeyPolicy(%d)", int(p))
+	}
+}
+
+// NonFinitePolicy is what is done with a NaN or ±Inf tagFloat or tagDouble payload written. Some consumers of level
+// data fail on non-finite values, or compare NaN payloads by their bits.
+type NonFinitePolicy int
+
+// NonFinitePolicy values.
+const (
+	// NonFiniteKeep writes the bits held, so NaN payloads round trip.
+	NonFiniteKeep NonFinitePolicy = iota
+	// NonFiniteCanonical writes every NaN as the quiet NaN Canonicalize uses, 0x7FC00000 for a tagFloat and
+	// 0x7FF8000000000000 for a tagDouble, and ±Inf as held.
+	NonFiniteCanonical
+	// NonFiniteError fails to write the tag.
+	NonFiniteError
+)
+
+// String returns the name of the policy.
+func (p NonFinitePolicy) String() string {
+	switch p {
+	case NonFiniteKeep:
+		return "keep"
+	case NonFiniteCanonical:
+		return "canonical"
+	case NonFiniteError:
+		return "error"
+	default:
+		return fmt.Sprintf("NonFinitePolicy(%d)", int(p))
+	}
+}
+
+// Limits bound the size of the tags read. A zero limit is unlimited.
+type Limits struct {
+	// MaxDepth is the deepest nesting of compounds and lists, where the root compound is at depth 1.
+	MaxDepth int
+	// MaxStringLength is the largest length in bytes of a tag name or tagString payload.
+	MaxStringLength int
+	// MaxArrayLength is the largest number of elements in an array or tagList.
+	MaxArrayLength int
+	// MaxAllocation is the most bytes of memory a tag read whole, as by ReadTag, Decoder.Decode and the file loaders,
+	// may allocate, estimated as MemoryEstimate does before each allocation is made. It bounds the total held by many
+	// tags that are each within the other limits. Tokens read by Decoder.Token are not held, so are not counted.
+	MaxAllocation int64
+}
+
+// Option sets a field of the Options.
+type Option func(*Options)
+
+// WithByteOrder sets the byte order of numbers, lengths and sizes.
+func WithByteOrder(order binary.ByteOrder) Option {
+	return func(o *Options) {
+		o.ByteOrder = order
+	}
+}
+
+// WithLimits sets the limits on the size of the tags read.
+func WithLimits(limits Limits) Option {
+	return func(o *Options) {
+		o.Limits = limits
+	}
+}
+
+// WithCompression sets the compression the tags are wrapped in.
+func WithCompression(compression Compression) Option {
+	return func(o *Options) {
+		o.Compression = compression
+	}
+}
+
+// WithCompressionLevel sets the compress/flate level tags are compressed at when written.
+func WithCompressionLevel(level int) Option {
+	return func(o *Options) {
+		o.CompressionLevel = level
+	}
+}
+
+// WithLenientUTF8 sets whether invalid UTF-8 is replaced rather than failing to read the tag.
+func WithLenientUTF8(lenient bool) Option {
+	return func(o *Options) {
+		o.LenientUTF8 = lenient
+	}
+}
+
+// WithDuplicateKeys sets what is done with a child of a tagCompound read whose name repeats that of an earlier child.
+func WithDuplicateKeys(policy DuplicateKeyPolicy) Option {
+	return func(o *Options) {
+		o.DuplicateKeys = policy
+	}
+}
+
+// WithChecksum sets the hash every byte of the output written is written to, such as a crc32 or sha256 hash.
+func WithChecksum(checksum hash.Hash) Option {
+	return func(o *Options) {
+		o.Checksum = checksum
+	}
+}
+
+// WithNonFinite sets what is done with a NaN or ±Inf tagFloat or tagDouble payload written.
+func WithNonFinite(policy NonFinitePolicy) Option {
+	return func(o *Options) {
+		o.NonFinite = policy
+	}
+}
+
+// WithStringEncoding sets the encoding of tag names and tagString payloads.
+func WithStringEncoding(encoding StringEncoding) Option {
+	return func(o *Options) {
+		o.StringEncoding = encoding
+	}
+}
+
+// WithNetworkFormat sets whether the root tag has no name, as in the Java edition network protocol since 1.20.2.
+func WithNetworkFormat(network bool) Option {
+	return func(o *Options) {
+		o.NetworkFormat = network
+	}
+}
+
+// WithProvenance records the Source of each decoded tag, naming the input file. An empty file is replaced by the path
+// when decoding with the file loaders.
+func WithProvenance(file string) Option {
+	return func(o *Options) {
+		o.Provenance = true
+		o.SourceFile = file
+	}
+}
+
+// JavaEdition is an Option preset selecting the conventions of Java edition files: big-endian with Modified UTF-8
+// strings. It is passed as is, as in ReadTag(r, JavaEdition), and later options override it.
+func JavaEdition(o *Options) {
+	o.ByteOrder = binary.BigEndian
+	o.StringEncoding = StringEncodingMUTF8
+}
+
+// BedrockEdition is an Option preset selecting the conventions of Bedrock edition files: little-endian with UTF-8
+// strings. It is passed as is, as in ReadTag(r, BedrockEdition), and later options override it.
+func BedrockEdition(o *Options) {
+	o.ByteOrder = binary.LittleEndian
+	o.StringEncoding = StringEncodingUTF8
+}
+
+// Strict is an Option preset for validators, failing to read input that breaks the format: invalid UTF-8, children of
+// a tagCompound with the same name, and bytes after the root tag. A binary tagList is always of one element type, and
+// a tagList of tagEnd elements always fails. It is passed as is, as in ReadTag(r, Strict), and later options override
+// it.
+func Strict(o *Options) {
+	o.LenientUTF8 = false
+	o.DuplicateKeys = DuplicateError
+	o.RejectTrailingData = true
+}
+
+// Lenient is an Option preset for real-world files, such as those written by mods: invalid UTF-8 is replaced, the last
+// of children with the same name is kept, and bytes after the root tag are ignored. It is passed as is, as in
+// ReadTag(r, Lenient), and later options override it.
+func Lenient(o *Options) {
+	o.LenientUTF8 = true
+	o.DuplicateKeys = DuplicateLastWins
+	o.RejectTrailingData = false
+}
+
+// newOptions returns the default Options with each Option applied in order, so later options win.
+func newOptions(opts []Option) Options {
+	o := Options{ByteOrder: binary.BigEndian, CompressionLevel: flate.DefaultCompression}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// withAllocation returns the options with a new count of allocations, if MaxAllocation is limited, for reading a tag
+// whole.
+func (o Options) withAllocation() Options {
+	if o.Limits.MaxAllocation > 0 {
+		o.allocated = new(int64)
+	}
+	return o
+}
+
+// allocate counts an allocation of size bytes against the MaxAllocation limit of the tag being read, returning an error
+// if the tag's allocations would exceed it.
+func (o Options) allocate(size int64) error {
+	if o.allocated == nil {
+		return nil
+	}
+	*o.allocated += size
+	if *o.allocated > o.Limits.MaxAllocation {
+		return fmt.Errorf("allocating %v bytes exceeds limit of %v", *o.allocated, o.Limits.MaxAllocation)
+	}
+	return nil
+}
+
+// checkLength returns an error if the length exceeds the limit, where a zero limit is unlimited.
+func checkLength(length, limit int) error {
+	if limit > 0 && length > limit {
+		return fmt.Errorf("%v exceeds limit of %v", length, limit)
+	}
+	return nil
+}
@@ -0,0 +1,296 @@
+package nbt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestNewOptions(t *testing.T) {
+	t.Run("Test success case: defaults", func(t *testing.T) {
+		got := newOptions(nil)
+		if got.ByteOrder != binary.BigEndian || got.Compression != CompressionNone || got.LenientUTF8 ||
+			got.Limits != (Limits{}) {
+			t.Errorf("got %+v, want big-endian, uncompressed, strict and unlimited", got)
+		}
+	})
+
+	t.Run("Test success case: later options win", func(t *testing.T) {
+		got := newOptions([]Option{WithByteOrder(binary.BigEndian), WithLenientUTF8(true),
+			WithByteOrder(binary.LittleEndian), WithCompression(CompressionZlib), WithLimits(Limits{MaxDepth: 2})})
+		if got.ByteOrder != binary.LittleEndian || got.Compression != CompressionZlib || !got.LenientUTF8 ||
+			got.Limits.MaxDepth != 2 {
+			t.Errorf("got %+v, want little-endian, zlib, lenient and depth 2", got)
+		}
+	})
+}
+
+func TestEditionPresets(t *testing.T) {
+	successCases := []struct {
+		name  string
+		opts  []Option
+		input []byte
+	}{
+		{"Java edition", []Option{JavaEdition}, []byte{tagInt, 0, 1, 'a', 0, 0, 0, 1}},
+		{"Bedrock edition", []Option{BedrockEdition}, []byte{tagInt, 1, 0, 'a', 1, 0, 0, 0}},
+		{"later option overrides preset", []Option{BedrockEdition, WithByteOrder(binary.BigEndian)},
+			[]byte{tagInt, 0, 1, 'a', 0, 0, 0, 1}},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			got, err := ReadTag(bytes.NewBuffer(successCase.input), successCase.opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got.name != "a" || got.payload != int32(1) {
+				t.Errorf("got %v %v, want a 1", got.name, got.payload)
+			}
+		})
+	}
+}
+
+func TestReadModes(t *testing.T) {
+	str := []byte{tagString, 0, 1, 'a', 0, 3, 'a', 0xFF, 'c'}
+	duplicates := []byte{tagCompound, 0, 0, tagByte, 0, 1, 'a', 1, tagByte, 0, 1, 'b', 2, tagByte, 0, 1, 'a', 3, tagEnd}
+	trailing := []byte{tagByte, 0, 1, 'a', 1, 0xFF}
+
+	successCases := []struct {
+		name  string
+		input []byte
+		opts  []Option
+		want  any
+	}{
+		{"lenient replaces invalid UTF-8", str, []Option{Lenient}, "a�c"},
+		{"lenient keeps last duplicate", duplicates, []Option{Lenient},
+			[]Tag{NewByte("a", 3), NewByte("b", 2)}},
+		{"lenient ignores trailing data", trailing, []Option{Lenient}, byte(1)},
+		{"default keeps every duplicate", duplicates, nil, []Tag{NewByte("a", 1), NewByte("b", 2), NewByte("a", 3)}},
+		{"default ignores trailing data", trailing, nil, byte(1)},
+		{"strict without trailing data", trailing[:len(trailing)-1], []Option{Strict}, byte(1)},
+		{"later option overrides strict", str, []Option{Strict, WithLenientUTF8(true)}, "a�c"},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			got, err := ReadTag(bytes.NewBuffer(successCase.input), successCase.opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			// Compound children are compared in order, as payloadsEqual matches them by name.
+			equal := payloadsEqual(got.payload, successCase.want)
+			if children, ok := successCase.want.([]Tag); ok {
+				gotChildren, _ := got.payload.([]Tag)
+				equal = slices.EqualFunc(gotChildren, children, func(a, b Tag) bool {
+					return a.name == b.name && payloadsEqual(a.payload, b.payload)
+				})
+			}
+			if !equal {
+				t.Errorf("got %v, want %v", got.payload, successCase.want)
+			}
+		})
+	}
+
+	failureCases := []struct {
+		name  string
+		input []byte
+	}{
+		{"strict invalid UTF-8", str},
+		{"strict duplicate", duplicates},
+		{"strict trailing data", trailing},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			_, err := ReadTag(bytes.NewBuffer(failureCase.input), Strict)
+			if err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+
+	t.Run("Test success case: strict decoder reads consecutive tags", func(t *testing.T) {
+		d := NewDecoder(bytes.NewBuffer(append(slices.Clone(trailing[:5]), trailing[:5]...)), Strict)
+		for i := 0; i < 2; i++ {
+			var got Tag
+			if err := d.Decode(&got); err != nil {
+				t.Fatalf("Unexpected error decoding tag %v: %v", i, err)
+			}
+		}
+	})
+}
+
+func TestDuplicateKeyPolicyString(t *testing.T) {
+	successCases := []struct {
+		policy DuplicateKeyPolicy
+		want   string
+	}{
+		{DuplicateKeepAll, "keep all"},
+		{DuplicateError, "error"},
+		{DuplicateFirstWins, "first wins"},
+		{DuplicateLastWins, "last wins"},
+		{DuplicateKeyPolicy(9), "DuplicateKeyPolicy(9)"},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.want, func(t *testing.T) {
+			if got := successCase.policy.String(); got != successCase.want {
+				t.Errorf("got %v, want %v", got, successCase.want)
+			}
+		})
+	}
+}
+
+func TestNonFinitePolicyString(t *testing.T) {
+	successCases := []struct {
+		policy NonFinitePolicy
+		want   string
+	}{
+		{NonFiniteKeep, "keep"},
+		{NonFiniteCanonical, "canonical"},
+		{NonFiniteError, "error"},
+		{NonFinitePolicy(9), "NonFinitePolicy(9)"},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.want, func(t *testing.T) {
+			if got := successCase.policy.String(); got != successCase.want {
+				t.Errorf("got %v, want %v", got, successCase.want)
+			}
+		})
+	}
+}
+
+func TestReadTagOptions(t *testing.T) {
+	nested := []byte{tagCompound, 0, 0, tagList, 0, 1, 'l', tagCompound, 0, 0, 0, 1, tagEnd, tagEnd}
+	str := []byte{tagString, 0, 1, 'a', 0, 3, 'a', 0xFF, 'c'}
+	array := []byte{tagIntArray, 0, 1, 'a', 0, 0, 0, 2, 0, 0, 0, 1, 0, 0, 0, 2}
+	// Three strings, each within every other limit, with the bytes allocated reading them.
+	strings := []byte{tagList, 0, 1, 'l', tagString, 0, 0, 0, 3, 0, 1, 'a', 0, 1, 'b', 0, 1, 'c'}
+	stringsAllocation := tagSize + 1 + sliceHeaderLen + 3*interfaceSize + 3*(stringHeaderLen+1)
+
+	successCases := []struct {
+		name  string
+		input []byte
+		opts  []Option
+		want  any
+	}{
+		{"depth within limit", nested, []Option{WithLimits(Limits{MaxDepth: 3})}, nil},
+		{"string within limit", []byte{tagString, 0, 1, 'a', 0, 2, 'b', 'c'},
+			[]Option{WithLimits(Limits{MaxStringLength: 2})}, "bc"},
+		{"array within limit", array, []Option{WithLimits(Limits{MaxArrayLength: 2})}, []int32{1, 2}},
+		{"allocation within limit", strings, []Option{WithLimits(Limits{MaxAllocation: stringsAllocation + 1})},
+			[]any{"a", "b", "c"}},
+		{"lenient UTF-8", str, []Option{WithLenientUTF8(true)}, "a�c"},
+		{"little-endian", []byte{tagInt, 1, 0, 'a', 1, 0, 0, 0}, []Option{WithByteOrder(binary.LittleEndian)},
+			int32(1)},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			got, err := ReadTag(bytes.NewBuffer(successCase.input), successCase.opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if successCase.want != nil && !payloadsEqual(got.payload, successCase.want) {
+				t.Errorf("got %v, want %v", got.payload, successCase.want)
+			}
+		})
+	}
+
+	failureCases := []struct {
+		name  string
+		input []byte
+		opts  []Option
+	}{
+		{"depth exceeds limit", nested, []Option{WithLimits(Limits{MaxDepth: 2})}},
+		{"name exceeds limit", []byte{tagByte, 0, 2, 'a', 'b', 1}, []Option{WithLimits(Limits{MaxStringLength: 1})}},
+		{"string exceeds limit", str, []Option{WithLimits(Limits{MaxStringLength: 2}), WithLenientUTF8(true)}},
+		{"array exceeds limit", array, []Option{WithLimits(Limits{MaxArrayLength: 1})}},
+		{"list exceeds limit", []byte{tagList, 0, 1, 'l', tagByte, 0, 0, 0, 2, 1, 2},
+			[]Option{WithLimits(Limits{MaxArrayLength: 1})}},
+		{"allocation exceeds limit", strings, []Option{WithLimits(Limits{MaxAllocation: stringsAllocation - 1})}},
+		{"allocation of array exceeds limit", array, []Option{WithLimits(Limits{MaxAllocation: tagSize + 1})}},
+		{"strict UTF-8", str, nil},
+		{"gzip of uncompressed input", array, []Option{WithCompression(CompressionGzip)}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			_, err := ReadTag(bytes.NewBuffer(failureCase.input), failureCase.opts...)
+			if err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestCheckLength(t *testing.T) {
+	successCases := []struct {
+		name   string
+		length int
+		limit  int
+	}{
+		{"unlimited", 1 << 30, 0},
+		{"below limit", 1, 2},
+		{"at limit", 2, 2},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			if err := checkLength(successCase.length, successCase.limit); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+
+	t.Run("Test failure case: above limit", func(t *testing.T) {
+		if err := checkLength(3, 2); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+}
+
+func TestProvenance(t *testing.T) {
+	input := []byte{tagCompound, 0, 0, tagByte, 0, 1, 'a', 1, tagList, 0, 1, 'b', tagCompound, 0, 0, 0, 1,
+		tagShort, 0, 1, 'c', 0, 2, tagEnd, tagEnd}
+
+	t.Run("Test success case: offsets", func(t *testing.T) {
+		got, err := ReadTag(bytes.NewBuffer(input), WithProvenance("level.dat"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		a, _ := compoundChild(got, "a")
+		b, _ := compoundChild(got, "b")
+		c, _ := compoundChild(Tag{id: tagCompound, payload: b.payload.([]any)[0]}, "c")
+		for _, want := range []struct {
+			tag    Tag
+			offset int64
+		}{{got, 0}, {a, 3}, {b, 8}, {c, 17}} {
+			source, ok := want.tag.Source()
+			if !ok || source != (Source{File: "level.dat", Offset: want.offset}) {
+				t.Errorf("got %v %v for %q, want level.dat at byte %v", source, ok, want.tag.name, want.offset)
+			}
+		}
+	})
+
+	t.Run("Test success case: not recorded by default", func(t *testing.T) {
+		got, err := ReadTag(bytes.NewBuffer(input))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, ok := got.Source(); ok {
+			t.Errorf("got a source, want none")
+		}
+	})
+
+	t.Run("Test success case: file loader names the source", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "level.dat")
+		writeGzipFile(t, path, input, time.Now())
+
+		got, err := ReadFile(path, WithProvenance(""))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		a, _ := compoundChild(got, "a")
+		if source, _ := a.Source(); source != (Source{File: path, Offset: 3}) {
+			t.Errorf("got %v, want %v at byte 3", source, path)
+		}
+	})
+}
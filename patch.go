@@ -0,0 +1,301 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// PatchOp is the kind of change made by a PatchOperation.
+type PatchOp string
+
+// PatchOp values, named after their JSON Patch (RFC 6902) counterparts.
+const (
+	// PatchAdd adds Value as a new compound child, or inserts it as a list element before the indexed element (an
+	// index equal to the list length appends).
+	PatchAdd PatchOp = "add"
+	// PatchRemove removes the compound child or list element.
+	PatchRemove PatchOp = "remove"
+	// PatchReplace replaces the tag with Value. A replaced list element must keep the listed type.
+	PatchReplace PatchOp = "replace"
+)
+
+// PatchOperation is a single change to a tag tree, at the tag addressed by Path. Value is the new tag for add and
+// replace operations. The name of Value is ignored unless it replaces the root, compound children take the name at
+// the end of the Path and list elements are unnamed.
+type PatchOperation struct {
+	Op    PatchOp
+	Path  Path
+	Value Tag
+}
+
+// Patch is an ordered list of changes that turns one tag tree into another, so changes to a world can be shipped and
+// replayed rather than copying whole files.
+type Patch []PatchOperation
+
+// GeneratePatch returns a patch that turns tree a into tree b. Compound children are matched by name, list elements by
+// index. A list whose element type changes is replaced whole, including an empty list whose declared element type
+// changes, so the patched tree is Equal to b. The options are those of Equal, so payloads within a FloatTolerance are
+// left unchanged.
+func GeneratePatch(a, b Tag, opts ...CompareOption) Patch {
+	if a.id != b.id || a.name != b.name {
+		return Patch{{Op: PatchReplace, Path: Path{}, Value: b}}
+	}
+	return generatePatch(Path{}, a, b, newCompareOptions(opts))
+}
+
+// generatePatch returns the operations turning a into b, where both are tags of the same type at the path.
+func generatePatch(path Path, a, b Tag, c CompareOptions) (p Patch) {
+	switch a.id {
+	case tagCompound:
+		bPayload, _ := b.payload.([]Tag)
+		aPayload, _ := a.payload.([]Tag)
+		for _, aChild := range aPayload {
+			if _, ok := compoundChild(b, aChild.name); !ok {
+				p = append(p, PatchOperation{Op: PatchRemove, Path: appendPath(path, aChild.name)})
+			}
+		}
+		for _, bChild := range bPayload {
+			childPath := appendPath(path, bChild.name)
+			aChild, ok := compoundChild(a, bChild.name)
+			switch {
+			case !ok:
+				p = append(p, PatchOperation{Op: PatchAdd, Path: childPath, Value: bChild})
+			case aChild.id != bChild.id:
+				p = append(p, PatchOperation{Op: PatchReplace, Path: childPath, Value: bChild})
+			default:
+				p = append(p, generatePatch(childPath, aChild, bChild, c)...)
+			}
+		}
+	case tagList:
+		aPayload, _ := a.payload.([]any)
+		bPayload, _ := b.payload.([]any)
+		_, aErr := listElementID(aPayload)
+		_, bErr := listElementID(bPayload)
+		aID, bID := a.ElementID(), b.ElementID()
+		// Elements added to an empty list of tagEnd give it their type, so it need not be replaced.
+		if aErr != nil || bErr != nil || (aID != bID && (aID != tagEnd || len(bPayload) == 0)) {
+			return Patch{{Op: PatchReplace, Path: path, Value: b}}
+		}
+		for i := range min(len(aPayload), len(bPayload)) {
+			aElement, bElement := Tag{id: aID, payload: aPayload[i]}, Tag{id: bID, payload: bPayload[i]}
+			p = append(p, generatePatch(appendPath(path, i), aElement, bElement, c)...)
+		}
+		for i := len(aPayload) - 1; i >= len(bPayload); i-- {
+			p = append(p, PatchOperation{Op: PatchRemove, Path: appendPath(path, i)})
+		}
+		for i := len(aPayload); i < len(bPayload); i++ {
+			p = append(p, PatchOperation{Op: PatchAdd, Path: appendPath(path, i), Value: Tag{id: bID,
+				payload: bPayload[i]}})
+		}
+	default:
+		if !payloadsWithin(a.payload, b.payload, c) {
+			p = append(p, PatchOperation{Op: PatchReplace, Path: path, Value: b})
+		}
+	}
+	return p
+}
+
+// ApplyPatch applies each operation of the patch in order, returning the patched tree. The given tree is not
+// modified, only the compounds and lists along the path of each operation are copied.
+func ApplyPatch(t Tag, p Patch) (Tag, error) {
+	for i, operation := range p {
+		var err error
+		t, err = applyPatchOperation(t, operation)
+		if err != nil {
+			return Tag{}, fmt.Errorf("Unable to apply patch operation %v (%v %v): %w", i, operation.Op, operation.Path,
+				err)
+		}
+	}
+	return t, nil
+}
+
+// applyPatchOperation applies a single operation to t.
+func applyPatchOperation(t Tag, operation PatchOperation) (Tag, error) {
+	if operation.Op == PatchReplace {
+		return editPath(t, operation.Path, func(Tag) (Tag, error) {
+			return operation.Value, nil
+		})
+	}
+
+	if len(operation.Path) == 0 {
+		return Tag{}, fmt.Errorf("the root can only be replaced")
+	}
+	parent, last := operation.Path[:len(operation.Path)-1], operation.Path[len(operation.Path)-1]
+
+	return editPath(t, parent, func(parent Tag) (Tag, error) {
+		switch operation.Op {
+		case PatchAdd:
+			return patchAdd(parent, last, operation.Value)
+		case PatchRemove:
+			return patchRemove(parent, last)
+		default:
+			return Tag{}, fmt.Errorf("unknown operation %q", operation.Op)
+		}
+	})
+}
+
+// patchAdd adds value to the parent as the named compound child or at the list index.
+func patchAdd(parent Tag, last any, value Tag) (Tag, error) {
+	switch e := last.(type) {
+	case string:
+		if parent.id != tagCompound {
+			return Tag{}, fmt.Errorf("tag \"%v\" is not a tagCompound", parent.name)
+		}
+		if _, ok := compoundChild(parent, e); ok {
+			return Tag{}, fmt.Errorf("child \"%v\" already exists", e)
+		}
+		value.name = e
+		parent.payload = withChild(parent.payload.([]Tag), value)
+	case int:
+		if parent.id != tagList {
+			return Tag{}, fmt.Errorf("tag \"%v\" is not a tagList", parent.name)
+		}
+		elements, _ := parent.payload.([]any)
+		if e < 0 || e > len(elements) {
+			return Tag{}, fmt.Errorf("index %v out of range of length %v", e, len(elements))
+		}
+		if id, _ := listElementID(elements); len(elements) > 0 && id != value.id {
+			return Tag{}, fmt.Errorf("tag \"%v\" lists tag ID %v, not %v", parent.name, id, value.id)
+		}
+		if len(elements) == 0 {
+			parent.elementID = value.id
+		}
+		parent.payload = slices.Insert(slices.Clone(elements), e, value.payload)
+	default:
+		return Tag{}, fmt.Errorf("path element has type %T, not string or int", last)
+	}
+	return parent, nil
+}
+
+// patchRemove removes the named compound child or the list element at the index from the parent.
+func patchRemove(parent Tag, last any) (Tag, error) {
+	if _, err := pathChild(parent, last); err != nil {
+		return Tag{}, err
+	}
+
+	switch e := last.(type) {
+	case string:
+		parent.payload, _ = withoutChild(parent.payload.([]Tag), e)
+	case int:
+		parent.payload = slices.Delete(slices.Clone(parent.payload.([]any)), e, e+1)
+	}
+	return parent, nil
+}
+
+// Tag returns the patch as a tag tree, so it can be stored or sent as NBT. The root compound holds a tagList named
+// "operations" of compounds, each with tagString "op" and "path" children and, for add and replace, a tagCompound
+// "value" holding the value tag as its only child.
+func (p Patch) Tag() Tag {
+	operations := make([]any, len(p))
+	for i, operation := range p {
+		children := []Tag{
+			{id: tagString, name: "op", payload: string(operation.Op)},
+			{id: tagString, name: "path", payload: operation.Path.String()},
+		}
+		if operation.Op != PatchRemove {
+			children = append(children, Tag{id: tagCompound, name: "value", payload: []Tag{operation.Value}})
+		}
+		operations[i] = children
+	}
+
+	return Tag{id: tagCompound, payload: []Tag{{id: tagList, name: "operations", payload: operations}}}
+}
+
+// PatchFromTag reads a patch from the tag tree form returned by Patch.Tag.
+func PatchFromTag(t Tag) (p Patch, err error) {
+	list, ok := compoundChild(t, "operations")
+	operations, isList := list.payload.([]any)
+	if !ok || !isList {
+		return nil, fmt.Errorf("Unable to read patch: no tagList named \"operations\"")
+	}
+
+	for i, element := range operations {
+		operation := Tag{id: tagCompound, payload: element}
+		op, opOK := compoundChild(operation, "op")
+		path, pathOK := compoundChild(operation, "path")
+		if !opOK || !pathOK || op.id != tagString || path.id != tagString {
+			return nil, fmt.Errorf("Unable to read patch operation %v: missing tagString \"op\" or \"path\"", i)
+		}
+
+		parsed, err := ParsePath(path.payload.(string))
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read patch operation %v: %w", i, err)
+		}
+
+		patchOperation := PatchOperation{Op: PatchOp(op.payload.(string)), Path: parsed}
+		if patchOperation.Op != PatchRemove {
+			value, _ := compoundChild(operation, "value")
+			children, _ := value.payload.([]Tag)
+			if len(children) != 1 {
+				return nil, fmt.Errorf("Unable to read patch operation %v: \"value\" must hold exactly one tag", i)
+			}
+			patchOperation.Value = children[0]
+		}
+		p = append(p, patchOperation)
+	}
+	return p, nil
+}
+
+// appendPath returns a copy of the path with the element appended, so sibling paths never share a backing array.
+func appendPath(path Path, element any) Path {
+	return append(slices.Clip(path), element)
+}
+
+// listElementID returns the tag ID of the elements of a tagList payload, or tagEnd for an empty list.
+func listElementID(elements []any) (uint8, error) {
+	if len(elements) == 0 {
+		return tagEnd, nil
+	}
+	return payloadID(elements[0])
+}
+
+// payloadsEqual reports whether two payloads are deeply equal. Floating point payloads are compared by bit pattern,
+// compound children are matched by name regardless of order, and nil and empty slices are equal.
+func payloadsEqual(a, b any) bool {
+	return payloadsWithin(a, b, CompareOptions{})
+}
+
+// payloadsWithin reports whether two payloads are deeply equal as payloadsEqual does, but for floating point payloads
+// within the FloatTolerance of the options.
+func payloadsWithin(a, b any, c CompareOptions) bool {
+	switch aPayload := a.(type) {
+	case float32:
+		bPayload, ok := b.(float32)
+		return ok && c.floatsWithin(float64(aPayload), float64(bPayload),
+			math.Float32bits(aPayload) == math.Float32bits(bPayload))
+	case float64:
+		bPayload, ok := b.(float64)
+		return ok && c.floatsWithin(aPayload, bPayload, math.Float64bits(aPayload) == math.Float64bits(bPayload))
+	case []byte:
+		bPayload, ok := b.([]byte)
+		return ok && slices.Equal(aPayload, bPayload)
+	case RawPayload:
+		bPayload, ok := b.(RawPayload)
+		return ok && slices.Equal(aPayload, bPayload)
+	case []int32:
+		bPayload, ok := b.([]int32)
+		return ok && slices.Equal(aPayload, bPayload)
+	case []int64:
+		bPayload, ok := b.([]int64)
+		return ok && slices.Equal(aPayload, bPayload)
+	case []any:
+		bPayload, ok := b.([]any)
+		return ok && slices.EqualFunc(aPayload, bPayload, func(a, b any) bool { return payloadsWithin(a, b, c) })
+	case []Tag:
+		bPayload, ok := b.([]Tag)
+		if !ok || len(aPayload) != len(bPayload) {
+			return false
+		}
+		for _, aChild := range aPayload {
+			bChild, ok := compoundChild(Tag{payload: bPayload}, aChild.name)
+			if !ok || aChild.id != bChild.id || !payloadsWithin(aChild.payload, bChild.payload, c) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
@@ -0,0 +1,199 @@
+package nbt
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestGeneratePatch(t *testing.T) {
+	a := Tag{id: tagCompound, payload: []Tag{
+		{id: tagString, name: "LevelName", payload: "My World"},
+		{id: tagInt, name: "Version", payload: int32(1)},
+		{id: tagList, name: "Pos", payload: []any{float64(1), float64(2), float64(3)}},
+		{id: tagList, name: "Tags", payload: []any{"a"}},
+	}}
+	b := Tag{id: tagCompound, payload: []Tag{
+		{id: tagString, name: "LevelName", payload: "New World"},
+		{id: tagLong, name: "Version", payload: int64(1)},
+		{id: tagList, name: "Pos", payload: []any{float64(1), float64(5)}},
+		{id: tagList, name: "Tags", payload: []any{int32(1)}},
+		{id: tagByte, name: "hardcore", payload: byte(1)},
+	}}
+
+	t.Run("Test success case: operations", func(t *testing.T) {
+		want := Patch{
+			{PatchReplace, Path{"LevelName"}, Tag{id: tagString, name: "LevelName", payload: "New World"}},
+			{PatchReplace, Path{"Version"}, Tag{id: tagLong, name: "Version", payload: int64(1)}},
+			{PatchReplace, Path{"Pos", 1}, Tag{id: tagDouble, payload: float64(5)}},
+			{PatchRemove, Path{"Pos", 2}, Tag{}},
+			{PatchReplace, Path{"Tags"}, Tag{id: tagList, name: "Tags", payload: []any{int32(1)}}},
+			{PatchAdd, Path{"hardcore"}, Tag{id: tagByte, name: "hardcore", payload: byte(1)}},
+		}
+		got := GeneratePatch(a, b)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Test success case: equal trees", func(t *testing.T) {
+		got := GeneratePatch(a, a)
+		if len(got) != 0 {
+			t.Errorf("got %v, want empty patch", got)
+		}
+	})
+
+	t.Run("Test success case: root type change", func(t *testing.T) {
+		got := GeneratePatch(a, Tag{id: tagInt, payload: int32(1)})
+		if len(got) != 1 || got[0].Op != PatchReplace || len(got[0].Path) != 0 {
+			t.Errorf("got %v, want root replacement", got)
+		}
+	})
+
+	t.Run("Test success case: random trees round trip", func(t *testing.T) {
+		r := rand.New(rand.NewSource(1)) // #nosec G404 -- deterministic test input
+		g := Generator{MaxDepth: 3, MaxElements: 4, MaxStringLength: 1}
+		for range 200 {
+			a, b := g.Tag(r), g.Tag(r)
+			got, gotErr := ApplyPatch(a, GeneratePatch(a, b))
+			if gotErr != nil {
+				t.Fatalf("got %v, want nil", gotErr)
+			}
+			if !payloadsEqual(got.payload, b.payload) {
+				t.Fatalf("got %v, want %v", got, b)
+			}
+		}
+	})
+}
+
+func TestApplyPatch(t *testing.T) {
+	newLevel := func() Tag {
+		return Tag{id: tagCompound, payload: []Tag{
+			{id: tagList, name: "Pos", payload: []any{float64(1), float64(2)}},
+			{id: tagInt, name: "Version", payload: int32(1)},
+		}}
+	}
+
+	successCases := []struct {
+		name  string
+		want  Tag
+		patch Patch
+	}{
+		{"insert list element", Tag{id: tagCompound, payload: []Tag{
+			{id: tagList, name: "Pos", payload: []any{float64(0), float64(1), float64(2)}},
+			{id: tagInt, name: "Version", payload: int32(1)},
+		}}, Patch{{PatchAdd, Path{"Pos", 0}, Tag{id: tagDouble, payload: float64(0)}}}},
+		{"append list element", Tag{id: tagCompound, payload: []Tag{
+			{id: tagList, name: "Pos", payload: []any{float64(1), float64(2), float64(3)}},
+			{id: tagInt, name: "Version", payload: int32(1)},
+		}}, Patch{{PatchAdd, Path{"Pos", 2}, Tag{id: tagDouble, payload: float64(3)}}}},
+		{"remove and add child", Tag{id: tagCompound, payload: []Tag{
+			{id: tagList, name: "Pos", payload: []any{float64(1), float64(2)}}, {id: tagInt, name: "Build", payload: int32(7)},
+		}}, Patch{{PatchRemove, Path{"Version"}, Tag{}},
+			{PatchAdd, Path{"Build"}, Tag{id: tagInt, name: "ignored", payload: int32(7)}}}},
+		{"replace root", Tag{id: tagString, name: "root", payload: "x"},
+			Patch{{PatchReplace, Path{}, Tag{id: tagString, name: "root", payload: "x"}}}},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			original := newLevel()
+			got, gotErr := ApplyPatch(original, successCase.patch)
+			if !reflect.DeepEqual(got, successCase.want) {
+				t.Errorf("got %v, want %v", got, successCase.want)
+			}
+			if gotErr != nil {
+				t.Errorf("got %v, want nil", gotErr)
+			}
+			if !reflect.DeepEqual(original, newLevel()) {
+				t.Errorf("got modified original %v, want unmodified", original)
+			}
+		})
+	}
+
+	failureCases := []struct {
+		name  string
+		patch Patch
+	}{
+		{"add existing child", Patch{{PatchAdd, Path{"Version"}, Tag{id: tagInt, payload: int32(2)}}}},
+		{"add wrong list type", Patch{{PatchAdd, Path{"Pos", 0}, Tag{id: tagInt, payload: int32(2)}}}},
+		{"add past end of list", Patch{{PatchAdd, Path{"Pos", 3}, Tag{id: tagDouble, payload: float64(2)}}}},
+		{"add into non-container", Patch{{PatchAdd, Path{"Version", "x"}, Tag{id: tagInt, payload: int32(2)}}}},
+		{"remove missing child", Patch{{PatchRemove, Path{"Missing"}, Tag{}}}},
+		{"remove root", Patch{{PatchRemove, Path{}, Tag{}}}},
+		{"replace list element with wrong type", Patch{{PatchReplace, Path{"Pos", 0}, Tag{id: tagInt, payload: int32(2)}}}},
+		{"unknown operation", Patch{{"move", Path{"Version"}, Tag{}}}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			_, gotErr := ApplyPatch(newLevel(), failureCase.patch)
+			if gotErr == nil {
+				t.Errorf("got nil, want non-nil")
+			}
+		})
+	}
+}
+
+func TestPatchTag(t *testing.T) {
+	patch := Patch{
+		{PatchReplace, Path{"Data", "LevelName"}, Tag{id: tagString, name: "LevelName", payload: "New World"}},
+		{PatchRemove, Path{"Data", "Pos", 2}, Tag{}},
+		{PatchAdd, Path{"Data", "a.b"}, Tag{id: tagIntArray, name: "a.b", payload: []int32{1, 2}}},
+	}
+
+	t.Run("Test success case: round trip", func(t *testing.T) {
+		got, gotErr := PatchFromTag(patch.Tag())
+		if !reflect.DeepEqual(got, patch) {
+			t.Errorf("got %v, want %v", got, patch)
+		}
+		if gotErr != nil {
+			t.Errorf("got %v, want nil", gotErr)
+		}
+	})
+
+	failureCases := []struct {
+		name string
+		t    Tag
+	}{
+		{"missing operations", Tag{id: tagCompound, payload: []Tag{}}},
+		{"missing op", Tag{id: tagCompound, payload: []Tag{{id: tagList, name: "operations", payload: []any{[]Tag{
+			{id: tagString, name: "path", payload: "Data"}}}}}}},
+		{"invalid path", Tag{id: tagCompound, payload: []Tag{{id: tagList, name: "operations", payload: []any{[]Tag{
+			{id: tagString, name: "op", payload: "remove"}, {id: tagString, name: "path", payload: "Data..x"}}}}}}},
+		{"missing value", Tag{id: tagCompound, payload: []Tag{{id: tagList, name: "operations", payload: []any{[]Tag{
+			{id: tagString, name: "op", payload: "add"}, {id: tagString, name: "path", payload: "Data"}}}}}}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			_, gotErr := PatchFromTag(failureCase.t)
+			if gotErr == nil {
+				t.Errorf("got nil, want non-nil")
+			}
+		})
+	}
+}
+
+func TestApplyPatchElementID(t *testing.T) {
+	tree := Tag{id: tagCompound, payload: []Tag{{id: tagList, elementID: tagString, name: "Names"}}}
+
+	got, err := ApplyPatch(tree, Patch{{PatchAdd, Path{"Names", 0}, Tag{id: tagInt, payload: int32(1)}}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	list, _ := compoundChild(got, "Names")
+	if list.ElementID() != tagInt {
+		t.Errorf("got %v, want %v", list.ElementID(), tagInt)
+	}
+}
+
+func TestGeneratePatchEmptyListElementID(t *testing.T) {
+	a := Tag{id: tagCompound, payload: []Tag{{id: tagList, elementID: tagString, name: "Names"}}}
+	b := Tag{id: tagCompound, payload: []Tag{{id: tagList, elementID: tagInt, name: "Names"}}}
+
+	got, err := ApplyPatch(a, GeneratePatch(a, b))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !Equal(&got, &b) {
+		t.Errorf("got %v, want the empty list of tagInt", got)
+	}
+}
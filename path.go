@@ -0,0 +1,371 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Path addresses a tag within a tree by the compound child names (string) and list element indices (int) leading to it
+// from the root, such as Path{"Data", "Player", "Pos", 1}. An empty Path addresses the root.
+//
+// The text form of a Path follows the NBT path syntax of Minecraft commands: names are separated by "." and indices are
+// in brackets, as in Data.Player.Pos[1]. Names that are empty or contain any of ` .[]{}"'` are double quoted, with "\"
+// escaping quotes and backslashes.
+type Path []any
+
+// pathSpecialCharacters are the characters that may not appear in an unquoted path name.
+const pathSpecialCharacters = " .[]{}\"'"
+
+// String returns the text form of the path.
+func (p Path) String() string {
+	var b strings.Builder
+	for i, element := range p {
+		switch e := element.(type) {
+		case int:
+			fmt.Fprintf(&b, "[%v]", e)
+		case string:
+			if i > 0 {
+				b.WriteByte('.')
+			}
+			if e == "" || strings.ContainsAny(e, pathSpecialCharacters) {
+				b.WriteString(`"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(e) + `"`)
+			} else {
+				b.WriteString(e)
+			}
+		default:
+			fmt.Fprintf(&b, "<%T>", e)
+		}
+	}
+	return b.String()
+}
+
+// MarshalText implements encoding.TextMarshaler using the text form of the path.
+func (p Path) MarshalText() ([]byte, error) {
+	for i, element := range p {
+		switch element.(type) {
+		case int, string:
+		default:
+			return nil, fmt.Errorf("Unable to marshal path: element %v has type %T, not string or int", i, element)
+		}
+	}
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler using the text form of the path.
+func (p *Path) UnmarshalText(text []byte) (err error) {
+	*p, err = ParsePath(string(text))
+	return err
+}
+
+// ParsePath parses the text form of a path, as returned by Path.String.
+func ParsePath(s string) (p Path, err error) {
+	p = Path{}
+	for i := 0; i < len(s); {
+		if s[i] == '[' {
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("Unable to parse path %q: unterminated index at %v", s, i)
+			}
+			index, err := strconv.Atoi(s[i+1 : i+end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("Unable to parse path %q: invalid index %q", s, s[i+1:i+end])
+			}
+			p = append(p, index)
+			i += end + 1
+			continue
+		}
+
+		if len(p) > 0 {
+			if s[i] != '.' {
+				return nil, fmt.Errorf("Unable to parse path %q: unexpected %q at %v", s, s[i], i)
+			}
+			i++
+		}
+
+		name, n, err := parsePathName(s[i:])
+		if err != nil {
+			return nil, fmt.Errorf("Unable to parse path %q: %w", s, err)
+		}
+		p = append(p, name)
+		i += n
+	}
+	return p, nil
+}
+
+// parsePathName parses a quoted or unquoted name at the start of s, returning the name and the number of bytes of s it
+// used.
+func parsePathName(s string) (name string, n int, err error) {
+	if s == "" {
+		return "", 0, fmt.Errorf("missing name")
+	}
+
+	if s[0] != '"' && s[0] != '\'' {
+		n = strings.IndexAny(s, pathSpecialCharacters)
+		if n < 0 {
+			n = len(s)
+		}
+		if n == 0 {
+			return "", 0, fmt.Errorf("unexpected %q", s[0])
+		}
+		return s[:n], n, nil
+	}
+
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 == len(s) {
+				return "", 0, fmt.Errorf("unterminated escape")
+			}
+			i++
+			b.WriteByte(s[i])
+		case s[0]:
+			return b.String(), i + 1, nil
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated quoted name")
+}
+
+// Get returns the payload of the tag at the path below t, in the text form of a Path, as a T: the Go type of the
+// payload (see Tag), or int, int64 or float64 for numbers widened as by Tag.GetInt, Tag.GetInt64 and Tag.GetFloat64,
+// or Tag for the tag itself. The error names the path and, for a payload of another type, the type found.
+func Get[T any](t *Tag, path string) (v T, err error) {
+	p, err := ParsePath(path)
+	if err != nil {
+		return v, fmt.Errorf("Unable to get %q: %w", path, err)
+	}
+	found, err := lookup(*t, p)
+	if err != nil {
+		return v, fmt.Errorf("Unable to get %v: %w", p, err)
+	}
+
+	var converted any
+	var ok bool
+	switch any(v).(type) {
+	case Tag:
+		converted, ok = found, true
+	case int:
+		var i int64
+		i, ok = integerPayload(found.payload)
+		converted, ok = int(i), ok && found.id != tagLong // #nosec G115 -- a tagByte, tagShort or tagInt fits an int
+	case int64:
+		converted, ok = integerPayload(found.payload)
+	case float64:
+		converted, ok = float64Payload(found.payload)
+	default:
+		converted, ok = found.payload, true
+	}
+	if v, isT := converted.(T); ok && isT {
+		return v, nil
+	}
+
+	tagType, err := found.tagType()
+	if err != nil {
+		tagType = fmt.Sprintf("tag ID %v", found.id)
+	}
+	return v, fmt.Errorf("Unable to get %v: found %v (%T), not %T", p, tagType, found.payload, v)
+}
+
+// lookup returns the tag at the path below t. List elements are returned as unnamed tags of the listed type.
+func lookup(t Tag, path Path) (Tag, error) {
+	for i, element := range path {
+		child, err := pathChild(t, element)
+		if err != nil {
+			return Tag{}, fmt.Errorf("Unable to look up %v: %w", path[:i+1], err)
+		}
+		t = child
+	}
+	return t, nil
+}
+
+// pathChild returns the child of a tagCompound named by a string element, or the element of a tagList indexed by an
+// int element.
+func pathChild(t Tag, element any) (Tag, error) {
+	switch e := element.(type) {
+	case string:
+		if t.id != tagCompound {
+			return Tag{}, fmt.Errorf("tag \"%v\" is not a tagCompound", t.name)
+		}
+		child, ok := compoundChild(t, e)
+		if !ok {
+			return Tag{}, fmt.Errorf("no child named \"%v\"", e)
+		}
+		return child, nil
+	case int:
+		elements, ok := t.payload.([]any)
+		if !ok {
+			return Tag{}, fmt.Errorf("tag \"%v\" is not a tagList", t.name)
+		}
+		if e < 0 || e >= len(elements) {
+			return Tag{}, fmt.Errorf("index %v out of range of length %v", e, len(elements))
+		}
+		id, err := payloadID(elements[e])
+		if err != nil {
+			return Tag{}, err
+		}
+		return Tag{id: id, payload: elements[e]}, nil
+	default:
+		return Tag{}, fmt.Errorf("path element has type %T, not string or int", element)
+	}
+}
+
+// editPath applies edit to the tag at the path below t, returning a copy of t in which every compound and list along
+// the path is copied to hold the edited result. The original tree is not modified. An edited list element must keep
+// the listed type.
+func editPath(t Tag, path Path, edit func(Tag) (Tag, error)) (Tag, error) {
+	if len(path) == 0 {
+		return edit(t)
+	}
+
+	child, err := pathChild(t, path[0])
+	if err != nil {
+		return Tag{}, err
+	}
+
+	child, err = editPath(child, path[1:], edit)
+	if err != nil {
+		return Tag{}, err
+	}
+
+	switch e := path[0].(type) {
+	case string:
+		child.name = e
+		t.payload = withChild(t.payload.([]Tag), child)
+	case int:
+		elements := t.payload.([]any)
+		if id, _ := payloadID(elements[e]); id != child.id {
+			return Tag{}, fmt.Errorf("element %v of tag \"%v\" must be tag ID %v, not %v", e, t.name, id, child.id)
+		}
+		copied := make([]any, len(elements))
+		copy(copied, elements)
+		copied[e] = child.payload
+		t.payload = copied
+	}
+	return t, nil
+}
+
+// SetPath sets the tag at the path to value, adding it if the path ends in a name the compound lacks. A list element
+// set must have the tag ID of the other elements of the list. With create, missing compounds and lists along the path
+// are created, see InsertPath, and an index equal to the length of a list appends. Only the compounds and lists along
+// the path are copied, so trees sharing the rest of t are not modified. An empty path replaces t.
+func (t *Tag) SetPath(path Path, value Tag, create bool) (err error) {
+	if len(path) == 0 {
+		*t = value
+		return nil
+	}
+
+	root, err := t.editParent(path, create, func(parent Tag, last any) (Tag, error) {
+		index, isIndex := last.(int)
+		if !isIndex {
+			return compoundEdit(func(children []Tag) ([]Tag, error) {
+				value.name = last.(string)
+				return withChild(children, value), nil
+			})(parent)
+		}
+
+		elements, ok := parent.payload.([]any)
+		if parent.id != tagList || (!ok && parent.payload != nil) {
+			return Tag{}, fmt.Errorf("tag \"%v\" is not a tagList", parent.name)
+		}
+		if create && index == len(elements) {
+			return patchAdd(parent, index, value)
+		}
+		if index < 0 || index >= len(elements) {
+			return Tag{}, fmt.Errorf("index %v out of range of length %v", index, len(elements))
+		}
+		siblings := slices.Delete(slices.Clone(elements), index, index+1)
+		if id, _ := listElementID(siblings); len(siblings) > 0 && id != value.id {
+			return Tag{}, fmt.Errorf("tag \"%v\" lists tag ID %v, not %v", parent.name, id, value.id)
+		}
+		copied := slices.Clone(elements)
+		copied[index] = value.payload
+		parent.elementID, parent.payload = value.id, copied
+		return parent, nil
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to set %v: %w", path, err)
+	}
+	*t = root
+	return nil
+}
+
+// InsertPath adds value at the path: as a new child if the path ends in a name, which the compound must lack, or as a
+// list element before the indexed element if it ends in an index, where an index equal to the length of the list
+// appends. A list element inserted must have the tag ID of the elements of the list. With create, missing compounds
+// and lists along the path are created, each an empty tagCompound, or an empty tagList where the path next indexes
+// it, and an element missing at the end of a list is appended in the same way. Only the compounds and lists along the
+// path are copied.
+func (t *Tag) InsertPath(path Path, value Tag, create bool) (err error) {
+	if len(path) == 0 {
+		return fmt.Errorf("Unable to insert at %v: the root can only be set", path)
+	}
+
+	root, err := t.editParent(path, create, func(parent Tag, last any) (Tag, error) {
+		return patchAdd(parent, last, value)
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to insert at %v: %w", path, err)
+	}
+	*t = root
+	return nil
+}
+
+// DeletePath removes the compound child or list element at the path. Only the compounds and lists along the path are
+// copied.
+func (t *Tag) DeletePath(path Path) (err error) {
+	if len(path) == 0 {
+		return fmt.Errorf("Unable to delete %v: the root can only be set", path)
+	}
+
+	root, err := t.editParent(path, false, patchRemove)
+	if err != nil {
+		return fmt.Errorf("Unable to delete %v: %w", path, err)
+	}
+	*t = root
+	return nil
+}
+
+// editParent applies edit to the parent of the tag at the non-empty path, passing the last element of the path, and
+// returns the edited copy of t. With create, the parents along the path are created first.
+func (t *Tag) editParent(path Path, create bool, edit func(parent Tag, last any) (Tag, error)) (Tag, error) {
+	parent, last := path[:len(path)-1], path[len(path)-1]
+	root := *t
+	if create {
+		var err error
+		root, err = createPath(root, path)
+		if err != nil {
+			return Tag{}, err
+		}
+	}
+
+	return editPath(root, parent, func(parent Tag) (Tag, error) {
+		return edit(parent, last)
+	})
+}
+
+// createPath returns a copy of t in which each compound child and list element along the path exists, up to the parent
+// of the last, creating each one missing as an empty tagCompound, or an empty tagList where the path next indexes it.
+// A list element is only created at the end of its list.
+func createPath(t Tag, path Path) (Tag, error) {
+	for i := range len(path) - 1 {
+		if _, err := lookup(t, path[:i+1]); err == nil {
+			continue
+		}
+
+		empty := Tag{id: tagCompound, payload: []Tag(nil)}
+		if _, isIndex := path[i+1].(int); isIndex {
+			empty = Tag{id: tagList, payload: []any(nil)}
+		}
+		var err error
+		t, err = applyPatchOperation(t, PatchOperation{Op: PatchAdd, Path: path[:i+1], Value: empty})
+		if err != nil {
+			return Tag{}, err
+		}
+	}
+	return t, nil
+}
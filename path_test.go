@@ -0,0 +1,355 @@
+package nbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPathString(t *testing.T) {
+	successCases := []struct {
+		name       string
+		wantString string
+		path       Path
+	}{
+		{"root", "", Path{}},
+		{"names", "Data.Player.Inventory", Path{"Data", "Player", "Inventory"}},
+		{"names and indices", "Data.Player.Pos[1]", Path{"Data", "Player", "Pos", 1}},
+		{"nested indices", "Lists[0][2].Name", Path{"Lists", 0, 2, "Name"}},
+		{"root list", "[3]", Path{3}},
+		{"quoted names", `"a.b".""."'x'"."say \"hi\""`, Path{"a.b", "", "'x'", `say "hi"`}},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			gotString := successCase.path.String()
+			if gotString != successCase.wantString {
+				t.Errorf("got %v, want %v", gotString, successCase.wantString)
+			}
+		})
+	}
+
+	t.Run("Test failure case: marshal invalid element", func(t *testing.T) {
+		_, gotErr := Path{"Data", 1.5}.MarshalText()
+		if gotErr == nil {
+			t.Errorf("got nil, want non-nil")
+		}
+	})
+}
+
+func TestParsePath(t *testing.T) {
+	successCases := []struct {
+		name     string
+		wantPath Path
+		input    string
+	}{
+		{"root", Path{}, ""},
+		{"names", Path{"Data", "Player", "Inventory"}, "Data.Player.Inventory"},
+		{"names and indices", Path{"Data", "Player", "Pos", 1}, "Data.Player.Pos[1]"},
+		{"nested indices", Path{"Lists", 0, 2, "Name"}, "Lists[0][2].Name"},
+		{"root list", Path{3}, "[3]"},
+		{"quoted names", Path{"a.b", "", "'x'", `say "hi"`}, `"a.b"."".'\'x\''."say \"hi\""`},
+		{"multi-byte names", Path{"你好", "世界"}, "你好.\"世界\""},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			var gotPath Path
+			gotErr := gotPath.UnmarshalText([]byte(successCase.input))
+			if !reflect.DeepEqual(gotPath, successCase.wantPath) {
+				t.Errorf("got %#v, want %#v", gotPath, successCase.wantPath)
+			}
+			if gotErr != nil {
+				t.Errorf("got %v, want nil", gotErr)
+			}
+		})
+	}
+
+	failureCases := []struct {
+		name  string
+		input string
+	}{
+		{"leading dot", ".Data"},
+		{"trailing dot", "Data."},
+		{"double dot", "Data..Player"},
+		{"unterminated index", "Pos[1"},
+		{"negative index", "Pos[-1]"},
+		{"non-numeric index", "Pos[x]"},
+		{"name after index without dot", "Pos[1]x"},
+		{"unterminated quote", `"Data`},
+		{"unterminated escape", `"Data\`},
+		{"unexpected character", "Data{}"},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			_, gotErr := ParsePath(failureCase.input)
+			if gotErr == nil {
+				t.Errorf("got nil, want non-nil")
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	level := Tag{id: tagCompound, payload: []Tag{
+		{id: tagCompound, name: "Data", payload: []Tag{
+			{id: tagList, name: "Pos", payload: []any{float64(1), float64(2)}},
+			{id: tagList, name: "Players", payload: []any{[]Tag{{id: tagString, name: "Name", payload: "Steve"}}}},
+		}},
+	}}
+
+	successCases := []struct {
+		name    string
+		wantTag Tag
+		path    Path
+	}{
+		{"root", level, Path{}},
+		{"list element", Tag{id: tagDouble, payload: float64(2)}, Path{"Data", "Pos", 1}},
+		{"child of list element", Tag{id: tagString, name: "Name", payload: "Steve"}, Path{"Data", "Players", 0, "Name"}},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			gotTag, gotErr := lookup(level, successCase.path)
+			if !reflect.DeepEqual(gotTag, successCase.wantTag) {
+				t.Errorf("got %v, want %v", gotTag, successCase.wantTag)
+			}
+			if gotErr != nil {
+				t.Errorf("got %v, want nil", gotErr)
+			}
+		})
+	}
+
+	failureCases := []struct {
+		name string
+		path Path
+	}{
+		{"missing child", Path{"Data", "Missing"}},
+		{"index into compound", Path{0}},
+		{"name into list", Path{"Data", "Pos", "x"}},
+		{"index out of range", Path{"Data", "Pos", 2}},
+		{"invalid element type", Path{"Data", 1.5}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			_, gotErr := lookup(level, failureCase.path)
+			if gotErr == nil {
+				t.Errorf("got nil, want non-nil")
+			}
+		})
+	}
+}
+
+// editTestTree returns a tree to edit by path.
+func editTestTree() Tag {
+	return Tag{id: tagCompound, payload: []Tag{
+		{id: tagCompound, name: "Data", payload: []Tag{
+			{id: tagList, name: "Pos", elementID: tagDouble, payload: []any{float64(1), float64(2)}},
+			{id: tagList, name: "Single", elementID: tagInt, payload: []any{int32(1)}},
+			{id: tagString, name: "Name", payload: "Steve"},
+		}},
+	}}
+}
+
+func TestSetPath(t *testing.T) {
+	successCases := []struct {
+		name   string
+		path   Path
+		value  Tag
+		create bool
+		want   string
+	}{
+		{"replace child", Path{"Data", "Name"}, NewString("ignored", "Alex"), false,
+			`{Data:{Pos:[1d,2d],Single:[1],Name:"Alex"}}`},
+		{"add child", Path{"Data", "Health"}, NewFloat("", 20), false,
+			`{Data:{Pos:[1d,2d],Single:[1],Name:"Steve",Health:20f}}`},
+		{"replace element", Path{"Data", "Pos", 1}, NewDouble("", 3), false,
+			`{Data:{Pos:[1d,3d],Single:[1],Name:"Steve"}}`},
+		{"retype only element", Path{"Data", "Single", 0}, NewString("", "one"), false,
+			`{Data:{Pos:[1d,2d],Single:["one"],Name:"Steve"}}`},
+		{"append element", Path{"Data", "Pos", 2}, NewDouble("", 3), true,
+			`{Data:{Pos:[1d,2d,3d],Single:[1],Name:"Steve"}}`},
+		{"create compounds and lists", Path{"Data", "Items", 0, "id"}, NewString("", "minecraft:stone"), true,
+			`{Data:{Pos:[1d,2d],Single:[1],Name:"Steve",Items:[{id:"minecraft:stone"}]}}`},
+		{"replace root", Path{}, NewInt("", 1), false, "1"},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			tree := editTestTree()
+			original := tree
+			if err := tree.SetPath(successCase.path, successCase.value, successCase.create); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := tree.String(); got != successCase.want {
+				t.Errorf("got %v, want %v", got, successCase.want)
+			}
+			if !reflect.DeepEqual(original, editTestTree()) {
+				t.Errorf("original tree modified: %v", original)
+			}
+		})
+	}
+
+	failureCases := []struct {
+		name   string
+		path   Path
+		value  Tag
+		create bool
+	}{
+		{"element of another type", Path{"Data", "Pos", 0}, NewInt("", 1), false},
+		{"index out of range", Path{"Data", "Pos", 2}, NewDouble("", 3), false},
+		{"missing parent", Path{"Data", "Missing", "x"}, NewInt("", 1), false},
+		{"index past the end when creating", Path{"Data", "Items", 1, "id"}, NewInt("", 1), true},
+		{"name into a list", Path{"Data", "Pos", "x"}, NewInt("", 1), true},
+		{"child of a string", Path{"Data", "Name", "x"}, NewInt("", 1), true},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			tree := editTestTree()
+			if err := tree.SetPath(failureCase.path, failureCase.value, failureCase.create); err == nil {
+				t.Errorf("got nil, want non-nil")
+			}
+			if !reflect.DeepEqual(tree, editTestTree()) {
+				t.Errorf("tree modified on error: %v", tree)
+			}
+		})
+	}
+}
+
+func TestInsertPath(t *testing.T) {
+	successCases := []struct {
+		name   string
+		path   Path
+		value  Tag
+		create bool
+		want   string
+	}{
+		{"insert element", Path{"Data", "Pos", 0}, NewDouble("", 0), false,
+			`{Data:{Pos:[0d,1d,2d],Single:[1],Name:"Steve"}}`},
+		{"append element", Path{"Data", "Pos", 2}, NewDouble("", 3), false,
+			`{Data:{Pos:[1d,2d,3d],Single:[1],Name:"Steve"}}`},
+		{"add child", Path{"Data", "Health"}, NewFloat("", 20), false,
+			`{Data:{Pos:[1d,2d],Single:[1],Name:"Steve",Health:20f}}`},
+		{"create list", Path{"Data", "Tags", 0}, NewString("", "a"), true,
+			`{Data:{Pos:[1d,2d],Single:[1],Name:"Steve",Tags:["a"]}}`},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			tree := editTestTree()
+			if err := tree.InsertPath(successCase.path, successCase.value, successCase.create); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := tree.String(); got != successCase.want {
+				t.Errorf("got %v, want %v", got, successCase.want)
+			}
+		})
+	}
+
+	failureCases := []struct {
+		name   string
+		path   Path
+		value  Tag
+		create bool
+	}{
+		{"existing child", Path{"Data", "Name"}, NewString("", "Alex"), false},
+		{"element of another type", Path{"Data", "Pos", 0}, NewInt("", 1), false},
+		{"missing list", Path{"Data", "Tags", 0}, NewString("", "a"), false},
+		{"root", Path{}, NewInt("", 1), false},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			tree := editTestTree()
+			if err := tree.InsertPath(failureCase.path, failureCase.value, failureCase.create); err == nil {
+				t.Errorf("got nil, want non-nil")
+			}
+		})
+	}
+}
+
+func TestDeletePath(t *testing.T) {
+	successCases := []struct {
+		name string
+		path Path
+		want string
+	}{
+		{"child", Path{"Data", "Name"}, `{Data:{Pos:[1d,2d],Single:[1]}}`},
+		{"element", Path{"Data", "Pos", 0}, `{Data:{Pos:[2d],Single:[1],Name:"Steve"}}`},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			tree := editTestTree()
+			if err := tree.DeletePath(successCase.path); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := tree.String(); got != successCase.want {
+				t.Errorf("got %v, want %v", got, successCase.want)
+			}
+		})
+	}
+
+	failureCases := []struct {
+		name string
+		path Path
+	}{
+		{"missing child", Path{"Data", "Missing"}},
+		{"index out of range", Path{"Data", "Pos", 2}},
+		{"root", Path{}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			tree := editTestTree()
+			if err := tree.DeletePath(failureCase.path); err == nil {
+				t.Errorf("got nil, want non-nil")
+			}
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	tree := editTestTree()
+
+	t.Run("Test success case: payload types", func(t *testing.T) {
+		if got, err := Get[string](&tree, "Data.Name"); err != nil || got != "Steve" {
+			t.Errorf("got %v %v, want Steve", got, err)
+		}
+		if got, err := Get[float64](&tree, "Data.Pos[1]"); err != nil || got != 2 {
+			t.Errorf("got %v %v, want 2", got, err)
+		}
+		if got, err := Get[[]any](&tree, "Data.Single"); err != nil || len(got) != 1 {
+			t.Errorf("got %v %v, want one element", got, err)
+		}
+	})
+
+	t.Run("Test success case: widened numbers and tags", func(t *testing.T) {
+		if got, err := Get[int](&tree, "Data.Single[0]"); err != nil || got != 1 {
+			t.Errorf("got %v %v, want 1", got, err)
+		}
+		if got, err := Get[int64](&tree, "Data.Single[0]"); err != nil || got != 1 {
+			t.Errorf("got %v %v, want 1", got, err)
+		}
+		got, err := Get[Tag](&tree, "Data.Name")
+		if err != nil || got.name != "Name" || got.id != tagString {
+			t.Errorf("got %v %v, want the Name tag", got, err)
+		}
+	})
+
+	failureCases := []struct {
+		name    string
+		get     func() error
+		wantErr string
+	}{
+		{"mistyped payload", func() error { _, err := Get[int32](&tree, "Data.Name"); return err },
+			"Unable to get Data.Name: found tagString (string), not int32"},
+		{"number that does not widen", func() error { _, err := Get[int](&tree, "Data.Pos[0]"); return err },
+			"Unable to get Data.Pos[0]: found tagDouble (float64), not int"},
+		{"missing child", func() error { _, err := Get[string](&tree, "Data.Missing"); return err },
+			`Unable to get Data.Missing: Unable to look up Data.Missing: no child named "Missing"`},
+		{"invalid path", func() error { _, err := Get[string](&tree, "Data["); return err }, ""},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			err := failureCase.get()
+			if err == nil {
+				t.Fatalf("got nil, want non-nil")
+			}
+			if failureCase.wantErr != "" && err.Error() != failureCase.wantErr {
+				t.Errorf("got %v, want %v", err, failureCase.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// playerDataDir is the directory of a Java edition world holding a <uuid>.dat file for each player.
+const playerDataDir = "playerdata"
+
+// PlayerColumn is a field exported from each player's data by ExportPlayers.
+type PlayerColumn struct {
+	// Name is the column heading in CSV output and the key in JSON output.
+	Name string
+	// Value returns the field from the root compound of a player's data, or nil if the player has none.
+	Value func(player Tag) any
+}
+
+// PathColumn returns a column of the value at the path, named by the path's text form, such as Pos or
+// abilities.flying. A tagCompound is exported as a map of its children and a tagList as a slice of its elements.
+func PathColumn(path Path) PlayerColumn {
+	return PlayerColumn{Name: path.String(), Value: func(player Tag) any {
+		t, err := lookup(player, path)
+		if err != nil {
+			return nil
+		}
+		return exportValue(t.payload)
+	}}
+}
+
+// InventoryColumn returns a column named "inventory_items" of the total number of items in a player's inventory,
+// summing the counts of every stack.
+func InventoryColumn() PlayerColumn {
+	return PlayerColumn{Name: "inventory_items", Value: func(player Tag) any {
+		list, ok := compoundChild(player, "Inventory")
+		if !ok {
+			return nil
+		}
+		inventory, err := InventoryFromTag(list)
+		if err != nil {
+			return nil
+		}
+
+		var total int64
+		for _, stack := range inventory {
+			total += int64(stack.Count)
+		}
+		return total
+	}}
+}
+
+// exportValue returns a payload in a form encoding/json and fmt can present: compounds as maps and lists as slices
+// of their converted elements.
+func exportValue(payload any) any {
+	switch p := payload.(type) {
+	case []Tag:
+		m := make(map[string]any, len(p))
+		for _, child := range p {
+			m[child.name] = exportValue(child.payload)
+		}
+		return m
+	case []any:
+		elements := make([]any, len(p))
+		for i, element := range p {
+			elements[i] = exportValue(element)
+		}
+		return elements
+	default:
+		return p
+	}
+}
+
+// PlayerRow is the exported fields of one player, in column order.
+type PlayerRow struct {
+	// UUID is the player's UUID, taken from the name of their data file.
+	UUID   string
+	Values []any
+}
+
+// ExportPlayers decodes every playerdata/<uuid>.dat file of the world directory concurrently, returning a row of the
+// columns for each player, sorted by UUID. Files that fail to decode are left out, and their errors are returned
+// joined alongside the rows of the rest, so one corrupt file does not stop an audit. The options configure decoding.
+func ExportPlayers(dir string, columns []PlayerColumn, opts ...Option) (rows []PlayerRow, err error) {
+	paths, err := filepath.Glob(filepath.Join(dir, playerDataDir, "*.dat"))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to export players: %w", err)
+	}
+
+	var mu sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	work := make(chan string)
+	for range min(runtime.GOMAXPROCS(0), len(paths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range work {
+				row, err := exportPlayer(path, columns, opts)
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, err)
+				} else {
+					rows = append(rows, row)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, path := range paths {
+		work <- path
+	}
+	close(work)
+	wg.Wait()
+
+	slices.SortFunc(rows, func(a, b PlayerRow) int { return strings.Compare(a.UUID, b.UUID) })
+	return rows, errors.Join(errs...)
+}
+
+// exportPlayer decodes one player data file, returning its row of the columns.
+func exportPlayer(path string, columns []PlayerColumn, opts []Option) (row PlayerRow, err error) {
+	player, err := ReadFile(path, opts...)
+	if err != nil {
+		return PlayerRow{}, fmt.Errorf("Unable to export player %v: %w", filepath.Base(path), err)
+	}
+
+	row.UUID = strings.TrimSuffix(filepath.Base(path), ".dat")
+	for _, column := range columns {
+		row.Values = append(row.Values, column.Value(player))
+	}
+	return row, nil
+}
+
+// WritePlayersCSV writes the rows as CSV with a heading row of "uuid" and the column names. Missing values are empty.
+func WritePlayersCSV(w io.Writer, columns []PlayerColumn, rows []PlayerRow) error {
+	c := csv.NewWriter(w)
+	heading := []string{"uuid"}
+	for _, column := range columns {
+		heading = append(heading, column.Name)
+	}
+	records := [][]string{heading}
+
+	for _, row := range rows {
+		record := []string{row.UUID}
+		for _, value := range row.Values {
+			if value == nil {
+				record = append(record, "")
+			} else {
+				record = append(record, fmt.Sprint(value))
+			}
+		}
+		records = append(records, record)
+	}
+
+	if err := c.WriteAll(records); err != nil {
+		return fmt.Errorf("Unable to write players CSV: %w", err)
+	}
+	return nil
+}
+
+// WritePlayersJSON writes the rows as a JSON array of objects, each holding "uuid" and the columns by name. Missing
+// values are null.
+func WritePlayersJSON(w io.Writer, columns []PlayerColumn, rows []PlayerRow) error {
+	objects := make([]map[string]any, len(rows))
+	for i, row := range rows {
+		objects[i] = map[string]any{"uuid": row.UUID}
+		for j, column := range columns {
+			objects[i][column.Name] = row.Values[j]
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(objects); err != nil {
+		return fmt.Errorf("Unable to write players JSON: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package nbt
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExportPlayers(t *testing.T) {
+	// Each player has an XpLevel int and an Inventory list holding a stack of stone with a legacy Count byte.
+	player := func(level, count byte) []byte {
+		b := []byte{tagCompound, 0, 0, tagInt, 0, 7}
+		b = append(b, "XpLevel"...)
+		b = append(b, 0, 0, 0, level, tagList, 0, 9)
+		b = append(b, "Inventory"...)
+		b = append(b, tagCompound, 0, 0, 0, 1, tagString, 0, 2, 'i', 'd', 0, 15)
+		b = append(b, "minecraft:stone"...)
+		b = append(b, tagByte, 0, 5)
+		b = append(b, "Count"...)
+		return append(b, count, tagEnd, tagEnd)
+	}
+	xpLevel, err := ParsePath("XpLevel")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	columns := []PlayerColumn{PathColumn(xpLevel), InventoryColumn()}
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, playerDataDir), 0o700); err != nil {
+		t.Fatalf("Unable to make test directory: %v", err)
+	}
+	writeGzipFile(t, filepath.Join(dir, playerDataDir, "b.dat"), player(2, 5), time.Now())
+	writeGzipFile(t, filepath.Join(dir, playerDataDir, "a.dat"), player(1, 3), time.Now())
+	writeGzipFile(t, filepath.Join(dir, playerDataDir, "a.dat_old"), player(9, 9), time.Now())
+
+	wantRows := []PlayerRow{{"a", []any{int32(1), int64(3)}}, {"b", []any{int32(2), int64(5)}}}
+
+	t.Run("Test success case: rows sorted by UUID", func(t *testing.T) {
+		got, err := ExportPlayers(dir, columns)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(got, wantRows) {
+			t.Errorf("got %v, want %v", got, wantRows)
+		}
+	})
+
+	t.Run("Test success case: CSV", func(t *testing.T) {
+		var b bytes.Buffer
+		rows := append(wantRows, PlayerRow{"c", []any{nil, nil}})
+		if err := WritePlayersCSV(&b, columns, rows); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want := "uuid,XpLevel,inventory_items\na,1,3\nb,2,5\nc,,\n"
+		if b.String() != want {
+			t.Errorf("got %q, want %q", b.String(), want)
+		}
+	})
+
+	t.Run("Test success case: JSON", func(t *testing.T) {
+		var b bytes.Buffer
+		if err := WritePlayersJSON(&b, columns, wantRows); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want := `[{"XpLevel":1,"inventory_items":3,"uuid":"a"},{"XpLevel":2,"inventory_items":5,"uuid":"b"}]` + "\n"
+		if b.String() != want {
+			t.Errorf("got %q, want %q", b.String(), want)
+		}
+	})
+
+	t.Run("Test success case: compound path as a map", func(t *testing.T) {
+		root, err := ParsePath("Inventory[0]")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got, err := ExportPlayers(dir, []PlayerColumn{PathColumn(root)})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want := map[string]any{"id": "minecraft:stone", "Count": byte(3)}
+		if !reflect.DeepEqual(got[0].Values[0], want) {
+			t.Errorf("got %v, want %v", got[0].Values[0], want)
+		}
+	})
+
+	t.Run("Test failure case: corrupt file is left out", func(t *testing.T) {
+		corrupt := t.TempDir()
+		if err := os.Mkdir(filepath.Join(corrupt, playerDataDir), 0o700); err != nil {
+			t.Fatalf("Unable to make test directory: %v", err)
+		}
+		writeGzipFile(t, filepath.Join(corrupt, playerDataDir, "a.dat"), player(1, 3), time.Now())
+		if err := os.WriteFile(filepath.Join(corrupt, playerDataDir, "b.dat"), []byte{0x1F, 0x8B}, 0o600); err != nil {
+			t.Fatalf("Unable to write test file: %v", err)
+		}
+
+		got, err := ExportPlayers(corrupt, columns)
+		if err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+		if !reflect.DeepEqual(got, wantRows[:1]) {
+			t.Errorf("got %v, want %v", got, wantRows[:1])
+		}
+	})
+}
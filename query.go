@@ -0,0 +1,272 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Query is a compiled NBT path of the syntax used by Minecraft's /data and /execute commands, which selects any number
+// of tags within a tree:
+//
+//	foo.bar          the child bar of the child foo
+//	foo{a:1b}        the child foo, if it is a compound holding at least a:1b
+//	{a:1b}           the root, if it is a compound holding at least a:1b
+//	list[0]          the first element of a list or array, [-1] the last
+//	list[]           every element of a list or array
+//	list[{id:"x"}]   every compound element of a list holding at least id:"x"
+//
+// Names are quoted as in Path when they contain any of ` .[]{}"'`. A compound filter matches a compound holding each
+// of its keys with a matching value, where compounds match as filters in turn, a non-empty list matches a list with a
+// matching element for each of its elements, an empty list matches only an empty list, and any other value must be
+// equal. Unlike Path, a Query may match many tags, or none.
+type Query struct {
+	text  string
+	nodes []queryNode
+}
+
+// queryNodeKind is the kind of a step of a Query.
+type queryNodeKind int
+
+// Kinds of Query steps.
+const (
+	queryRootFilter queryNodeKind = iota
+	queryChild
+	queryIndex
+	queryElements
+)
+
+// queryNode is a step of a Query: a child by name, an element by index, or every element, each kept only if it matches
+// the filter, if any.
+type queryNode struct {
+	kind   queryNodeKind
+	name   string
+	index  int
+	filter *Tag
+}
+
+// queryMatch is a tag selected by a Query, with the Path to it.
+type queryMatch struct {
+	tag  Tag
+	path Path
+}
+
+// ParseQuery compiles the text of an NBT path.
+func ParseQuery(s string) (q Query, err error) {
+	q.text = s
+	p := snbtParser{s: s}
+	for p.i < len(s) {
+		first := len(q.nodes) == 0
+		node, err := parseQueryNode(&p, first)
+		if err != nil {
+			return Query{}, fmt.Errorf("Unable to parse query %q: %w", s, err)
+		}
+		q.nodes = append(q.nodes, node)
+
+		if p.i < len(s) && s[p.i] != '[' {
+			if s[p.i] != '.' || p.i+1 == len(s) {
+				return Query{}, fmt.Errorf("Unable to parse query %q: %w", s, p.errorf("unexpected %q", s[p.i]))
+			}
+			p.i++
+		}
+	}
+	return q, nil
+}
+
+// parseQueryNode parses the step of a query at the parser's offset. A root filter may only be the first step.
+func parseQueryNode(p *snbtParser, first bool) (node queryNode, err error) {
+	switch p.s[p.i] {
+	case '{':
+		if !first {
+			return queryNode{}, p.errorf("compound filter not following a name")
+		}
+		filter, err := p.compound()
+		return queryNode{kind: queryRootFilter, filter: &filter}, err
+	case '[':
+		p.i++
+		switch {
+		case p.i < len(p.s) && p.s[p.i] == ']':
+			p.i++
+			return queryNode{kind: queryElements}, nil
+		case p.i < len(p.s) && p.s[p.i] == '{':
+			filter, err := p.compound()
+			if err == nil {
+				err = p.expect(']')
+			}
+			return queryNode{kind: queryElements, filter: &filter}, err
+		}
+		end := strings.IndexByte(p.s[p.i:], ']')
+		if end < 0 {
+			return queryNode{}, p.errorf("unterminated index")
+		}
+		index, err := strconv.Atoi(p.s[p.i : p.i+end])
+		if err != nil {
+			return queryNode{}, p.errorf("invalid index %q", p.s[p.i:p.i+end])
+		}
+		p.i += end + 1
+		return queryNode{kind: queryIndex, index: index}, nil
+	default:
+		name, n, err := parsePathName(p.s[p.i:])
+		if err != nil {
+			return queryNode{}, p.errorf("%v", err)
+		}
+		p.i += n
+		node = queryNode{kind: queryChild, name: name}
+		if p.i < len(p.s) && p.s[p.i] == '{' {
+			filter, err := p.compound()
+			if err != nil {
+				return queryNode{}, err
+			}
+			node.filter = &filter
+		}
+		return node, nil
+	}
+}
+
+// String returns the text the query was parsed from.
+func (q Query) String() string {
+	return q.text
+}
+
+// Get returns the first tag the query selects within t, in the order of GetAll. The boolean is false if it selects
+// none.
+func (q Query) Get(t Tag) (match Tag, ok bool) {
+	matches := q.resolve(t)
+	if len(matches) == 0 {
+		return Tag{}, false
+	}
+	return matches[0].tag, true
+}
+
+// GetAll returns every tag the query selects within t, in tree order. List and array elements are returned as unnamed
+// tags of the listed type.
+func (q Query) GetAll(t Tag) (matches []Tag) {
+	for _, m := range q.resolve(t) {
+		matches = append(matches, m.tag)
+	}
+	return matches
+}
+
+// resolve returns the tags the query selects within t, with their paths.
+func (q Query) resolve(t Tag) []queryMatch {
+	matches := []queryMatch{{tag: t, path: Path{}}}
+	for _, node := range q.nodes {
+		var next []queryMatch
+		for _, m := range matches {
+			next = node.apply(m, next)
+		}
+		matches = next
+	}
+	return matches
+}
+
+// apply appends the tags the step selects from the match.
+func (node queryNode) apply(m queryMatch, matches []queryMatch) []queryMatch {
+	keep := func(t Tag, element any) {
+		if node.filter == nil || (t.id == tagCompound && filterMatches(node.filter.payload, t.payload)) {
+			matches = append(matches, queryMatch{tag: t, path: appendPath(m.path, element)})
+		}
+	}
+
+	switch node.kind {
+	case queryRootFilter:
+		if m.tag.id == tagCompound && filterMatches(node.filter.payload, m.tag.payload) {
+			matches = append(matches, m)
+		}
+	case queryChild:
+		if child, ok := compoundChild(m.tag, node.name); ok {
+			keep(child, node.name)
+		}
+	case queryIndex:
+		n := collectionLen(m.tag)
+		i := node.index
+		if i < 0 {
+			i += n
+		}
+		if i >= 0 && i < n {
+			keep(collectionElement(m.tag, i), i)
+		}
+	case queryElements:
+		for i := range collectionLen(m.tag) {
+			keep(collectionElement(m.tag, i), i)
+		}
+	}
+	return matches
+}
+
+// collectionLen returns the number of elements of a list or array, or 0 for any other tag.
+func collectionLen(t Tag) int {
+	switch p := t.payload.(type) {
+	case []any:
+		return len(p)
+	case []byte:
+		return len(p)
+	case []int32:
+		return len(p)
+	case []int64:
+		return len(p)
+	default:
+		return 0
+	}
+}
+
+// collectionElement returns the element at the index of a list or array as an unnamed tag of its type.
+func collectionElement(t Tag, i int) Tag {
+	switch p := t.payload.(type) {
+	case []any:
+		id, err := payloadID(p[i])
+		if err != nil {
+			// A RawPayload element takes the listed ID.
+			id = t.elementID
+		}
+		return Tag{id: id, payload: p[i]}
+	case []byte:
+		return NewByte("", p[i])
+	case []int32:
+		return NewInt("", p[i])
+	default:
+		return NewLong("", t.payload.([]int64)[i])
+	}
+}
+
+// filterMatches reports whether the payload matches the filter payload, as described by Query.
+func filterMatches(filter, payload any) bool {
+	switch f := filter.(type) {
+	case []Tag:
+		children, ok := payload.([]Tag)
+		if !ok {
+			return false
+		}
+		for _, want := range f {
+			child, ok := compoundChild(Tag{payload: children}, want.name)
+			if !ok || child.id != want.id || !filterMatches(want.payload, child.payload) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		elements, ok := payload.([]any)
+		if !ok {
+			return false
+		}
+		if len(f) == 0 {
+			return len(elements) == 0
+		}
+		for _, want := range f {
+			found := false
+			for _, element := range elements {
+				if filterMatches(want, element) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	default:
+		return payloadsEqual(filter, payload)
+	}
+}
@@ -0,0 +1,94 @@
+package nbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	lore, _ := NewList("Lore", IDString, NewString("", "a"), NewString("", "b"))
+	tag, _ := NewCompound("tag", lore)
+	stone, _ := NewCompound("", NewByte("Count", 1), NewString("id", "minecraft:stone"), tag)
+	dirt, _ := NewCompound("", NewByte("Count", 2), NewString("id", "minecraft:dirt"))
+	inventory, _ := NewList("Inventory", IDCompound, stone, dirt)
+	empty, _ := NewList("Empty", IDInt)
+	player, _ := NewCompound("Player", inventory, empty, NewIntArray("Pos", []int32{1, 64, -2}),
+		NewString("a.b", "dotted"))
+	root, _ := NewCompound("", player, NewByte("hardcore", 1))
+
+	successCases := []struct {
+		name      string
+		query     string
+		want      []Tag
+		wantPaths []Path
+	}{
+		{"root", "", []Tag{root}, []Path{{}}},
+		{"child", "Player.Inventory[1].id", []Tag{NewString("id", "minecraft:dirt")},
+			[]Path{{"Player", "Inventory", 1, "id"}}},
+		{"negative index", "Player.Pos[-1]", []Tag{NewInt("", -2)}, []Path{{"Player", "Pos", 2}}},
+		{"all elements", "Player.Inventory[].Count", []Tag{NewByte("Count", 1), NewByte("Count", 2)},
+			[]Path{{"Player", "Inventory", 0, "Count"}, {"Player", "Inventory", 1, "Count"}}},
+		{"filtered elements", `Player.Inventory[{id:"minecraft:stone"}].Count`, []Tag{NewByte("Count", 1)},
+			[]Path{{"Player", "Inventory", 0, "Count"}}},
+		{"filter on a list", `Player.Inventory[{tag:{Lore:["b"]}}].id`, []Tag{NewString("id", "minecraft:stone")},
+			[]Path{{"Player", "Inventory", 0, "id"}}},
+		{"filtered child", "Player{Empty:[]}.Pos[0]", []Tag{NewInt("", 1)}, []Path{{"Player", "Pos", 0}}},
+		{"root filter", "{hardcore:1b}.hardcore", []Tag{NewByte("hardcore", 1)}, []Path{{"hardcore"}}},
+		{"quoted name", `Player."a.b"`, []Tag{NewString("a.b", "dotted")}, []Path{{"Player", "a.b"}}},
+		{"elements of elements", "Player.Inventory[].tag.Lore[]", []Tag{NewString("", "a"), NewString("", "b")},
+			[]Path{{"Player", "Inventory", 0, "tag", "Lore", 0}, {"Player", "Inventory", 0, "tag", "Lore", 1}}},
+		{"no match", `Player.Inventory[{id:"minecraft:air"}]`, nil, nil},
+		{"failing filter", "{hardcore:0b}", nil, nil},
+		{"empty list filter", "Player{Inventory:[]}", nil, nil},
+		{"index out of range", "Player.Pos[3]", nil, nil},
+		{"name below a list", "Player.Inventory.id", nil, nil},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			q, err := ParseQuery(successCase.query)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if q.String() != successCase.query {
+				t.Errorf("String() = %v, want %v", q.String(), successCase.query)
+			}
+
+			got := q.GetAll(root)
+			if !reflect.DeepEqual(got, successCase.want) {
+				t.Errorf("GetAll() = %v, want %v", got, successCase.want)
+			}
+			var paths []Path
+			for _, m := range q.resolve(root) {
+				paths = append(paths, m.path)
+			}
+			if !reflect.DeepEqual(paths, successCase.wantPaths) {
+				t.Errorf("paths %v, want %v", paths, successCase.wantPaths)
+			}
+
+			first, ok := q.Get(root)
+			if ok != (len(successCase.want) > 0) || (ok && !reflect.DeepEqual(first, successCase.want[0])) {
+				t.Errorf("Get() = %v %v, want the first of %v", first, ok, successCase.want)
+			}
+		})
+	}
+
+	failureCases := []struct {
+		name  string
+		query string
+	}{
+		{"leading dot", ".Player"},
+		{"trailing dot", "Player."},
+		{"unterminated index", "Player.Pos[0"},
+		{"invalid index", "Player.Pos[x]"},
+		{"filter after an index", "Player.Pos[0]{a:1}"},
+		{"invalid filter", "Player{a:}"},
+		{"missing separator", `Player"x"`},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			if _, err := ParseQuery(failureCase.query); err == nil {
+				t.Errorf("got nil, want non-nil")
+			}
+		})
+	}
+}
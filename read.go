@@ -3,14 +3,59 @@ package nbt
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
-	"unicode/utf8"
+	"math"
 )
 
-// ReadTag reads the next tags worth of bytes on the buffer, undertakes basic structure checks,
-func ReadTag(buffer io.Reader, order binary.ByteOrder) (t Tag, err error) {
-	t.id, err = readTagID(buffer, order)
+// ReadTag reads the next tags worth of bytes on the buffer, undertakes basic structure checks, and returns the tag. By
+// default the tag is read as big-endian (Java edition), uncompressed, with strict UTF-8 and no limits, see Option.
+func ReadTag(buffer io.Reader, opts ...Option) (t Tag, err error) {
+	o := newOptions(opts)
+	if o.ReadTimeout > 0 || o.Timeout > 0 {
+		d := newDeadlineReader(buffer, o)
+		defer d.clear()
+		buffer = d
+	}
+
+	decompressed, err := decompress(buffer, o.Compression)
+	if err != nil {
+		return Tag{}, fmt.Errorf("Unable to read tag: %w", err)
+	}
+
+	input := newInputReader(decompressed, o.Compression == CompressionNone)
+	t, err = readTag(input, o)
+	if err != nil {
+		return Tag{}, err
+	}
+
+	if o.RejectTrailingData {
+		err = checkTrailingData(input)
+		if err != nil {
+			return Tag{}, fmt.Errorf("Unable to read tag: %w", err)
+		}
+	}
+	return t, nil
+}
+
+// checkTrailingData returns an error if the input holds any bytes past the root tag read from it.
+func checkTrailingData(input *inputReader) error {
+	offset := input.read
+	n, err := io.ReadFull(input, make([]byte, 1))
+	if n > 0 {
+		return fmt.Errorf("trailing data after the root tag at offset %v", offset)
+	}
+	if !errors.Is(err, io.EOF) {
+		return fmt.Errorf("unable to check for trailing data: %w", err)
+	}
+	return nil
+}
+
+// readTag reads a whole tag, its ID, name and payload, from the uncompressed buffer.
+func readTag(buffer io.Reader, o Options) (t Tag, err error) {
+	offset := inputOffset(buffer)
+	t.id, err = readTagID(buffer, o)
 	if err != nil {
 		return Tag{}, fmt.Errorf("Unable to read tag: %w", err)
 	}
@@ -21,29 +66,47 @@ func ReadTag(buffer io.Reader, order binary.ByteOrder) (t Tag, err error) {
 		return t, nil
 	}
 
-	t.name, err = readTagName(buffer, order)
+	if o.allocated == nil {
+		o = o.withAllocation()
+	}
+	err = o.allocate(tagSize)
 	if err != nil {
 		return Tag{}, fmt.Errorf("Unable to read tag: %w", err)
 	}
 
-	t.payload, err = readTagPayload(buffer, order, t.id)
+	// The root tag of the network format has no name.
+	if o.depth > 0 || !o.NetworkFormat {
+		t.name, err = readTagName(buffer, o)
+		if err != nil {
+			return Tag{}, fmt.Errorf("Unable to read tag: %w", err)
+		}
+	}
+
+	if t.id == tagList {
+		t.elementID, t.payload, err = readTagListPayload(buffer, o)
+	} else {
+		t.payload, err = readTagPayload(buffer, o, t.id)
+	}
 	if err != nil {
 		return Tag{}, fmt.Errorf("Unable to read tag: %w", err)
 	}
 
+	if o.Provenance {
+		t.source = &Source{File: o.SourceFile, Offset: offset}
+	}
 	return t, nil
 }
 
 // readTagID is intended to read the ID of a tag. The ID is the first byte in a tag. The tag ID is also known as the tag
 // type. In this implementation, tag ID refers to the uint8 number (0 -> 12), and tag Type refers to the type name
 // associated with that ID (ID 0 == type tagEnd, ID 12 == type tagLongArray).
-func readTagID(buffer io.Reader, order binary.ByteOrder) (id uint8, err error) {
-	err = binary.Read(buffer, order, &id)
+func readTagID(buffer io.Reader, o Options) (id uint8, err error) {
+	err = binary.Read(buffer, o.ByteOrder, &id)
 	if err != nil {
 		return 0, fmt.Errorf("Unable to read tag ID: %w", err)
 	}
 
-	if id > tagLongArray {
+	if id > tagLongArray && o.UnknownTags == nil {
 		return 0, fmt.Errorf("ID %v not between 0 (tagEnd) and 12 (tagLongArray)", id)
 	}
 
@@ -54,23 +117,39 @@ func readTagID(buffer io.Reader, order binary.ByteOrder) (id uint8, err error) {
 // of the tag name. The following 'length' amount of bytes is the name as a string in UTF-8 format. TagEnd is an
 // exception, as it never has a name, therefore is only one byte. That is, tagEnd does not have a second and third byte
 // for name length nor a series of bytes for the name.
-func readTagName(buffer io.Reader, order binary.ByteOrder) (name string, err error) {
-	var length int16
-	err = binary.Read(buffer, order, &length)
+func readTagName(buffer io.Reader, o Options) (name string, err error) {
+	length, err := readStringLength(buffer, o)
 	if err != nil {
 		return "", fmt.Errorf("Unable to read tag name length for: %w", err)
 	}
+	if length > math.MaxInt16 {
+		return "", fmt.Errorf("Unable to read tag name: length %v exceeds the maximum of %v", length, math.MaxInt16)
+	}
 
-	nameBytes := make([]byte, length)
-	err = binary.Read(buffer, order, nameBytes)
+	err = checkLength(length, o.Limits.MaxStringLength)
+	if err != nil {
+		return "", fmt.Errorf("Unable to read tag name: %w", err)
+	}
+
+	err = checkRemaining(buffer, int64(length), 1)
+	if err != nil {
+		return "", fmt.Errorf("Unable to read tag name: %w", err)
+	}
+
+	err = o.allocate(int64(length))
 	if err != nil {
 		return "", fmt.Errorf("Unable to read tag name: %w", err)
 	}
 
-	name = string(nameBytes)
+	nameBytes := make([]byte, length)
+	err = binary.Read(buffer, o.ByteOrder, nameBytes)
+	if err != nil {
+		return "", fmt.Errorf("Unable to read tag name: %w", err)
+	}
 
-	if !utf8.ValidString(name) {
-		return "", fmt.Errorf("Unable to read tag name: \"%v\" contains non UTF-8 charters", name)
+	name, err = decodeString(nameBytes, o)
+	if err != nil {
+		return "", fmt.Errorf("Unable to read tag name: %w", err)
 	}
 
 	return name, nil
@@ -79,44 +158,50 @@ func readTagName(buffer io.Reader, order binary.ByteOrder) (name string, err err
 // readTagPayload is intended to read the variable number of subsequent bytes after the tag ID and tag Name. The number
 // of bytes in the payload is dependant on the type of tag. A tagEnd does not have a payload, so expect an error if a
 // tagEnd is passed as the ID.
-func readTagPayload(buffer io.Reader, order binary.ByteOrder, tagID uint8) (payload any, err error) {
+func readTagPayload(buffer io.Reader, o Options, tagID uint8) (payload any, err error) {
 	switch tagID {
 	case tagEnd:
 		err = fmt.Errorf("Not expecting to read a tagEnd in the payload")
 	case tagByte:
-		payload, err = readTagBytePayload(buffer, order)
+		payload, err = readTagBytePayload(buffer, o)
 	case tagShort:
-		payload, err = readTagShortPayload(buffer, order)
+		payload, err = readTagShortPayload(buffer, o)
 	case tagInt:
-		payload, err = readTagIntPayload(buffer, order)
+		payload, err = readTagIntPayload(buffer, o)
 	case tagLong:
-		payload, err = readTagLongPayload(buffer, order)
+		payload, err = readTagLongPayload(buffer, o)
 	case tagFloat:
-		payload, err = readTagFloatPayload(buffer, order)
+		payload, err = readTagFloatPayload(buffer, o)
 	case tagDouble:
-		payload, err = readTagDoublePayload(buffer, order)
+		payload, err = readTagDoublePayload(buffer, o)
 	case tagByteArray:
-		payload, err = readTagByteArrayPayload(buffer, order)
+		payload, err = readTagByteArrayPayload(buffer, o)
 	case tagString:
-		payload, err = readTagStringPayload(buffer, order)
+		payload, err = readTagStringPayload(buffer, o)
 	case tagList:
-		payload, err = readTagListPayload(buffer, order)
+		_, payload, err = readTagListPayload(buffer, o)
 	case tagCompound:
-		payload, err = readTagCompoundPayload(buffer, order)
+		payload, err = readTagCompoundPayload(buffer, o)
 	case tagIntArray:
-		payload, err = readTagIntArrayPayload(buffer, order)
+		payload, err = readTagIntArrayPayload(buffer, o)
 	case tagLongArray:
-		payload, err = readTagLongArrayPayload(buffer, order)
+		payload, err = readTagLongArrayPayload(buffer, o)
 	default:
-		err = fmt.Errorf("tag ID %v not between 0 (tagEnd) and 12 (tagLongArray)", tagID)
+		if o.UnknownTags == nil {
+			err = fmt.Errorf("tag ID %v not between 0 (tagEnd) and 12 (tagLongArray)", tagID)
+			break
+		}
+		var raw []byte
+		raw, err = o.UnknownTags(tagID, buffer, o.ByteOrder)
+		payload = RawPayload(raw)
 	}
 	return payload, err
 }
 
 // readTagBytePayload reads a tag payload defined as: "1 byte / 8 bits, signed. A signed integral type. Sometimes used
 // for booleans." While the definition says signed, it is just a byte, use it as you will.
-func readTagBytePayload(buffer io.Reader, order binary.ByteOrder) (payload byte, err error) {
-	err = binary.Read(buffer, order, &payload)
+func readTagBytePayload(buffer io.Reader, o Options) (payload byte, err error) {
+	err = binary.Read(buffer, o.ByteOrder, &payload)
 	if err != nil {
 		return 0, fmt.Errorf("Unable to read tagByte payload: %w", err)
 	}
@@ -125,8 +210,8 @@ func readTagBytePayload(buffer io.Reader, order binary.ByteOrder) (payload byte,
 }
 
 // readTagShortPayload reads a tag payload defined as: "2 bytes / 16 bits, signed. A signed integral type."
-func readTagShortPayload(buffer io.Reader, order binary.ByteOrder) (payload int16, err error) {
-	err = binary.Read(buffer, order, &payload)
+func readTagShortPayload(buffer io.Reader, o Options) (payload int16, err error) {
+	err = binary.Read(buffer, o.ByteOrder, &payload)
 	if err != nil {
 		return 0, fmt.Errorf("Unable to read tagShort payload: %w", err)
 	}
@@ -135,8 +220,8 @@ func readTagShortPayload(buffer io.Reader, order binary.ByteOrder) (payload int1
 }
 
 // readTagIntPayload reads a tag payload defined as: "4 bytes / 32 bits, signed. A signed integral type."
-func readTagIntPayload(buffer io.Reader, order binary.ByteOrder) (payload int32, err error) {
-	err = binary.Read(buffer, order, &payload)
+func readTagIntPayload(buffer io.Reader, o Options) (payload int32, err error) {
+	payload, err = readInt32(buffer, o)
 	if err != nil {
 		return 0, fmt.Errorf("Unable to read tagInt payload: %w", err)
 	}
@@ -145,8 +230,8 @@ func readTagIntPayload(buffer io.Reader, order binary.ByteOrder) (payload int32,
 }
 
 // readTagLongPayload reads a tag payload defined as: "8 bytes / 64 bits, signed. A signed integral type."
-func readTagLongPayload(buffer io.Reader, order binary.ByteOrder) (payload int64, err error) {
-	err = binary.Read(buffer, order, &payload)
+func readTagLongPayload(buffer io.Reader, o Options) (payload int64, err error) {
+	payload, err = readInt64(buffer, o)
 	if err != nil {
 		return 0, fmt.Errorf("Unable to read tagLong payload: %w", err)
 	}
@@ -156,8 +241,8 @@ func readTagLongPayload(buffer io.Reader, order binary.ByteOrder) (payload int64
 
 // readTagFloatPayload reads a tag payload defined as: "4 bytes / 32 bits, signed, IEEE 754-2008, binary32. A signed
 // floating point type."
-func readTagFloatPayload(buffer io.Reader, order binary.ByteOrder) (payload float32, err error) {
-	err = binary.Read(buffer, order, &payload)
+func readTagFloatPayload(buffer io.Reader, o Options) (payload float32, err error) {
+	err = binary.Read(buffer, o.ByteOrder, &payload)
 	if err != nil {
 		return 0, fmt.Errorf("Unable to read tagFloat payload: %w", err)
 	}
@@ -167,8 +252,8 @@ func readTagFloatPayload(buffer io.Reader, order binary.ByteOrder) (payload floa
 
 // readTagDoublePayload reads a tag payload defined as: "8 bytes / 64 bits, signed, IEEE 754-2008, binary64. A signed
 // floating point type."
-func readTagDoublePayload(buffer io.Reader, order binary.ByteOrder) (payload float64, err error) {
-	err = binary.Read(buffer, order, &payload)
+func readTagDoublePayload(buffer io.Reader, o Options) (payload float64, err error) {
+	err = binary.Read(buffer, o.ByteOrder, &payload)
 	if err != nil {
 		return 0, fmt.Errorf("Unable to read tagDouble payload: %w", err)
 	}
@@ -179,9 +264,8 @@ func readTagDoublePayload(buffer io.Reader, order binary.ByteOrder) (payload flo
 // readTagByteArrayPayload reads a tag payload defined as: "A signed integer (4 bytes) size, then the bytes comprising
 // an array of length size. An array of bytes." While the definition says the size is signed, that makes no sense,
 // going to keep with the definition to maintain compatibility, but throw an error on negative size.
-func readTagByteArrayPayload(buffer io.Reader, order binary.ByteOrder) (payload []byte, err error) {
-	var size int32
-	err = binary.Read(buffer, order, &size)
+func readTagByteArrayPayload(buffer io.Reader, o Options) (payload []byte, err error) {
+	size, err := readInt32(buffer, o)
 	if err != nil {
 		return nil, fmt.Errorf("Unable to read tagByteArray payload size: %w", err)
 	}
@@ -190,9 +274,24 @@ func readTagByteArrayPayload(buffer io.Reader, order binary.ByteOrder) (payload
 		return nil, fmt.Errorf("Unable to read tagByteArray payload size: size %v is negative", size)
 	}
 
+	err = checkLength(int(size), o.Limits.MaxArrayLength)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read tagByteArray payload size: %w", err)
+	}
+
+	err = checkRemaining(buffer, int64(size), 1)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read tagByteArray payload size: %w", err)
+	}
+
+	err = o.allocate(sliceHeaderLen + int64(size))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read tagByteArray payload: %w", err)
+	}
+
 	for i := 0; i < int(size); i++ {
 		var p byte
-		err = binary.Read(buffer, order, &p)
+		err = binary.Read(buffer, o.ByteOrder, &p)
 		if err != nil {
 			return nil, fmt.Errorf("Unable to read tagByteArray payload element %v: %w", i, err)
 		}
@@ -204,22 +303,36 @@ func readTagByteArrayPayload(buffer io.Reader, order binary.ByteOrder) (payload
 
 // readTagStringPayload reads a tag payload defined as: "An unsigned short (2 bytes) payload length, then a UTF-8 string
 // resembled by length bytes. A UTF-8 string. It has a size, rather than being null terminated."
-func readTagStringPayload(buffer io.Reader, order binary.ByteOrder) (payload string, err error) {
-	var length uint16
-	err = binary.Read(buffer, order, &length)
+func readTagStringPayload(buffer io.Reader, o Options) (payload string, err error) {
+	length, err := readStringLength(buffer, o)
 	if err != nil {
 		return "", fmt.Errorf("Unable to read tagString payload length: %w", err)
 	}
 
+	err = checkLength(length, o.Limits.MaxStringLength)
+	if err != nil {
+		return "", fmt.Errorf("Unable to read tagString payload: %w", err)
+	}
+
+	err = checkRemaining(buffer, int64(length), 1)
+	if err != nil {
+		return "", fmt.Errorf("Unable to read tagString payload: %w", err)
+	}
+
+	err = o.allocate(stringHeaderLen + int64(length))
+	if err != nil {
+		return "", fmt.Errorf("Unable to read tagString payload: %w", err)
+	}
+
 	stringPayloadBytes := make([]byte, length)
-	err = binary.Read(buffer, order, stringPayloadBytes)
+	err = binary.Read(buffer, o.ByteOrder, stringPayloadBytes)
 	if err != nil {
 		return "", fmt.Errorf("Unable to read tagString payload: %w", err)
 	}
-	payload = string(stringPayloadBytes)
 
-	if !utf8.ValidString(payload) {
-		return "", fmt.Errorf("Unable to read tagString payload: \"%v\" contains non UTF-8 charters", payload)
+	payload, err = decodeString(stringPayloadBytes, o)
+	if err != nil {
+		return "", fmt.Errorf("Unable to read tagString payload: %w", err)
 	}
 
 	return payload, nil
@@ -229,37 +342,94 @@ func readTagStringPayload(buffer io.Reader, order binary.ByteOrder) (payload str
 // the list's length as a signed integer (4 bytes), then length number of payloads that correspond to the given tag
 // type. A list of tag payloads, without tag types or names, apart from the one before the length." While the definition
 // says the size is signed, that makes no sense, keeping with the definition in case people use negative size values to
-// indicate zero length or other novel meanings.
-func readTagListPayload(buffer io.Reader, order binary.ByteOrder) (payload []any, err error) {
-	var tagID uint8
-	err = binary.Read(buffer, order, &tagID)
+// indicate zero length or other novel meanings. The element tag ID is returned with the payload.
+func readTagListPayload(buffer io.Reader, o Options) (elementID uint8, payload []any, err error) {
+	elementID, length, err := readTagListHeader(buffer, o)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	o.depth++
+	err = checkLength(o.depth, o.Limits.MaxDepth)
 	if err != nil {
-		return nil, fmt.Errorf("Unable to read tagList type: %w", err)
+		return 0, nil, fmt.Errorf("Unable to read tagList depth: %w", err)
 	}
 
-	var length int32
-	err = binary.Read(buffer, order, &length)
+	err = o.allocate(sliceHeaderLen + interfaceSize*int64(max(length, 0)))
 	if err != nil {
-		return nil, fmt.Errorf("Unable to read tagList length: %w", err)
+		return 0, nil, fmt.Errorf("Unable to read tagList payload: %w", err)
 	}
 
 	for i := 0; i < int(length); i++ {
-		p, err := readTagPayload(buffer, order, tagID)
+		p, err := readTagPayload(buffer, o, elementID)
 		if err != nil {
-			return nil, fmt.Errorf("Unable to read tagList payload element %v: %w", i, err)
+			return 0, nil, fmt.Errorf("Unable to read tagList payload element %v: %w", i, err)
 		}
 		payload = append(payload, p)
 	}
 
-	return payload, nil
+	return elementID, payload, nil
+}
+
+// readTagListHeader reads the element tag ID and length at the start of a tagList payload, checking the length against
+// the limits and the bytes remaining, and that only an empty list declares tagEnd elements.
+func readTagListHeader(buffer io.Reader, o Options) (elementID uint8, length int32, err error) {
+	err = binary.Read(buffer, o.ByteOrder, &elementID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Unable to read tagList type: %w", err)
+	}
+
+	length, err = readInt32(buffer, o)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Unable to read tagList length: %w", err)
+	}
+
+	err = checkLength(int(length), o.Limits.MaxArrayLength)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Unable to read tagList length: %w", err)
+	}
+
+	// Empty lists may declare tagEnd, as the game writes them, but tagEnd has no payload to make elements of.
+	if elementID == tagEnd && length > 0 {
+		return 0, 0, fmt.Errorf("Unable to read tagList type: %v elements of tagEnd", length)
+	}
+
+	size := minPayloadSize(elementID)
+	if o.VarInt {
+		size = minVarIntPayloadSize(elementID)
+	}
+	err = checkRemaining(buffer, int64(length), size)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Unable to read tagList length: %w", err)
+	}
+
+	return elementID, length, nil
 }
 
 // readTagCompoundPayload reads a tag payload defined as: "Fully formed tags, followed by a tagEnd. A list of fully
 // formed tags, including their IDs, names, and payloads. No two tags may have the same name." The payload for a
-// compound is an array of pointers to child tags.
-func readTagCompoundPayload(buffer io.Reader, order binary.ByteOrder) (payload []Tag, err error) {
+// compound is an array of pointers to child tags. Children with the same name are kept, dropped or fail the read per
+// the DuplicateKeys policy.
+func readTagCompoundPayload(buffer io.Reader, o Options) (payload []Tag, err error) {
+	o.depth++
+	err = checkLength(o.depth, o.Limits.MaxDepth)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read tagCompound depth: %w", err)
+	}
+
+	err = o.allocate(sliceHeaderLen)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read tagCompound payload: %w", err)
+	}
+
+	// index holds the position in the payload of each name read, if duplicate names are not all kept.
+	var index map[string]int
+	if o.DuplicateKeys != DuplicateKeepAll {
+		index = make(map[string]int)
+	}
+
 	for i := 0; ; i++ {
-		t, err := ReadTag(buffer, order)
+		t, err := readTag(buffer, o)
 		if err != nil {
 			return nil, fmt.Errorf("Unable to read tagCompound payload element %v: %w", i, err)
 		}
@@ -267,6 +437,22 @@ func readTagCompoundPayload(buffer io.Reader, order binary.ByteOrder) (payload [
 		if t.id == tagEnd {
 			break
 		}
+
+		if earlier, ok := index[t.name]; ok {
+			switch o.DuplicateKeys {
+			case DuplicateError:
+				return nil, fmt.Errorf("Unable to read tagCompound payload element %v: name \"%v\" is a duplicate", i,
+					t.name)
+			case DuplicateFirstWins:
+				continue
+			case DuplicateLastWins:
+				payload[earlier] = t
+				continue
+			}
+		}
+		if index != nil {
+			index[t.name] = len(payload)
+		}
 		payload = append(payload, t)
 	}
 	return payload, nil
@@ -275,9 +461,8 @@ func readTagCompoundPayload(buffer io.Reader, order binary.ByteOrder) (payload [
 // readTagIntArrayPayload reads a tag payload defined as: "A signed integer size, then size number of tagInt's payloads.
 // An array of tagInt's payloads." While the definition says the size is signed, that makes no sense, keeping with the
 // definition in case people use negative size values to indicate zero length or other novel meanings.
-func readTagIntArrayPayload(buffer io.Reader, order binary.ByteOrder) (payload []int32, err error) {
-	var size int32
-	err = binary.Read(buffer, order, &size)
+func readTagIntArrayPayload(buffer io.Reader, o Options) (payload []int32, err error) {
+	size, err := readInt32(buffer, o)
 	if err != nil {
 		return nil, fmt.Errorf("Unable to read tagIntArray payload size: %w", err)
 	}
@@ -286,9 +471,23 @@ func readTagIntArrayPayload(buffer io.Reader, order binary.ByteOrder) (payload [
 		return nil, fmt.Errorf("Unable to read tagIntArray payload size: size %v is negative", size)
 	}
 
+	err = checkLength(int(size), o.Limits.MaxArrayLength)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read tagIntArray payload size: %w", err)
+	}
+
+	err = checkRemaining(buffer, int64(size), minArrayElementSize(o, 4))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read tagIntArray payload size: %w", err)
+	}
+
+	err = o.allocate(sliceHeaderLen + 4*int64(size))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read tagIntArray payload: %w", err)
+	}
+
 	for i := 0; i < int(size); i++ {
-		var p int32
-		err = binary.Read(buffer, order, &p)
+		p, err := readInt32(buffer, o)
 		if err != nil {
 			return nil, fmt.Errorf("Unable to read tagIntArray payload element %v: %w", i, err)
 		}
@@ -301,9 +500,8 @@ func readTagIntArrayPayload(buffer io.Reader, order binary.ByteOrder) (payload [
 // readTagLongArrayPayload reads a tag payload defined as: "A signed integer size, then size number of tagLong's
 // payloads. An array of tagLong's payloads." While the definition says the size is signed, that makes no sense, keeping
 // with the definition in case people use negative size values to indicate zero length or other novel meanings.
-func readTagLongArrayPayload(buffer io.Reader, order binary.ByteOrder) (payload []int64, err error) {
-	var size int32
-	err = binary.Read(buffer, order, &size)
+func readTagLongArrayPayload(buffer io.Reader, o Options) (payload []int64, err error) {
+	size, err := readInt32(buffer, o)
 	if err != nil {
 		return nil, fmt.Errorf("Unable to read tagLongArray payload size: %w", err)
 	}
@@ -312,9 +510,23 @@ func readTagLongArrayPayload(buffer io.Reader, order binary.ByteOrder) (payload
 		return nil, fmt.Errorf("Unable to read tagLongArray payload size: size %v is negative", size)
 	}
 
+	err = checkLength(int(size), o.Limits.MaxArrayLength)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read tagLongArray payload size: %w", err)
+	}
+
+	err = checkRemaining(buffer, int64(size), minArrayElementSize(o, 8))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read tagLongArray payload size: %w", err)
+	}
+
+	err = o.allocate(sliceHeaderLen + 8*int64(size))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read tagLongArray payload: %w", err)
+	}
+
 	for i := 0; i < int(size); i++ {
-		var l int64
-		err = binary.Read(buffer, order, &l)
+		l, err := readInt64(buffer, o)
 		if err != nil {
 			return nil, fmt.Errorf("Unable to read tagLongArray payload element %v: %w", i, err)
 		}
@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
+	"slices"
 	"testing"
 	"testing/iotest"
 )
@@ -181,7 +182,7 @@ func TestReadTag(t *testing.T) {
 	for _, successCase := range successCases {
 		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(successCase.input)
-			_, gotErr := ReadTag(buffer, successCase.order)
+			_, gotErr := ReadTag(buffer, WithByteOrder(successCase.order))
 			if gotErr != nil {
 				t.Errorf("got %v, want nil", gotErr)
 			}
@@ -201,7 +202,7 @@ func TestReadTag(t *testing.T) {
 	for _, failureCase := range failureCases {
 		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(failureCase.input)
-			_, gotErr := ReadTag(buffer, failureCase.order)
+			_, gotErr := ReadTag(buffer, WithByteOrder(failureCase.order))
 			if gotErr == nil {
 				t.Errorf("got nil, want non-nil")
 			}
@@ -210,7 +211,7 @@ func TestReadTag(t *testing.T) {
 
 	t.Run("Test failure case: broken io.Reader", func(t *testing.T) {
 		errBuffer := iotest.ErrReader(fmt.Errorf("mock broken io.reader"))
-		_, gotErr := ReadTag(errBuffer, binary.LittleEndian)
+		_, gotErr := ReadTag(errBuffer, WithByteOrder(binary.LittleEndian))
 		if gotErr == nil {
 			t.Errorf("got nil, want non-nil")
 		}
@@ -231,7 +232,7 @@ func TestReadTagID(t *testing.T) {
 	for _, successCase := range successCases {
 		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(successCase.input)
-			gotID, gotErr := readTagID(buffer, successCase.order)
+			gotID, gotErr := readTagID(buffer, Options{ByteOrder: successCase.order})
 			if gotID != successCase.wantID {
 				t.Errorf("got %v, want %v", gotID, successCase.wantID)
 			}
@@ -252,7 +253,7 @@ func TestReadTagID(t *testing.T) {
 	for _, failureCase := range failureCases {
 		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(failureCase.input)
-			_, gotErr := readTagID(buffer, failureCase.order)
+			_, gotErr := readTagID(buffer, Options{ByteOrder: failureCase.order})
 			if gotErr == nil {
 				t.Errorf("got nil, want non-nil")
 			}
@@ -261,7 +262,7 @@ func TestReadTagID(t *testing.T) {
 
 	t.Run("Test failure case: broken io.Reader", func(t *testing.T) {
 		errBuffer := iotest.ErrReader(fmt.Errorf("mock broken io.reader"))
-		_, gotErr := readTagID(errBuffer, binary.LittleEndian)
+		_, gotErr := readTagID(errBuffer, Options{ByteOrder: binary.LittleEndian})
 		if gotErr == nil {
 			t.Errorf("got nil, want non-nil")
 		}
@@ -292,7 +293,7 @@ func TestReadTagName(t *testing.T) {
 	for _, successCase := range successCases {
 		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(successCase.input)
-			gotName, gotErr := readTagName(buffer, successCase.order)
+			gotName, gotErr := readTagName(buffer, Options{ByteOrder: successCase.order})
 			if gotName != successCase.wantName {
 				t.Errorf("got %v, want %v", gotName, successCase.wantName)
 			}
@@ -317,7 +318,7 @@ func TestReadTagName(t *testing.T) {
 	for _, failureCase := range failureCases {
 		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(failureCase.input)
-			_, gotErr := readTagName(buffer, failureCase.order)
+			_, gotErr := readTagName(buffer, Options{ByteOrder: failureCase.order})
 			if gotErr == nil {
 				t.Errorf("got nil, want non-nil")
 			}
@@ -326,7 +327,7 @@ func TestReadTagName(t *testing.T) {
 
 	t.Run("Test failure case: broken io.Reader", func(t *testing.T) {
 		errBuffer := iotest.ErrReader(fmt.Errorf("mock broken io.reader"))
-		_, gotErr := readTagName(errBuffer, binary.LittleEndian)
+		_, gotErr := readTagName(errBuffer, Options{ByteOrder: binary.LittleEndian})
 		if gotErr == nil {
 			t.Errorf("got nil, want non-nil")
 		}
@@ -358,7 +359,7 @@ func TestReadTagPayload(t *testing.T) {
 	for _, successCase := range successCases {
 		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(successCase.input)
-			_, gotErr := readTagPayload(buffer, successCase.order, successCase.tagID)
+			_, gotErr := readTagPayload(buffer, Options{ByteOrder: successCase.order}, successCase.tagID)
 			if gotErr != nil {
 				t.Errorf("got %v, want nil", gotErr)
 			}
@@ -377,7 +378,7 @@ func TestReadTagPayload(t *testing.T) {
 	for _, failureCase := range failureCases {
 		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(failureCase.input)
-			_, gotErr := readTagPayload(buffer, failureCase.order, failureCase.tagID)
+			_, gotErr := readTagPayload(buffer, Options{ByteOrder: failureCase.order}, failureCase.tagID)
 			if gotErr == nil {
 				t.Errorf("got nil, want non-nil")
 			}
@@ -399,7 +400,7 @@ func TestReadTagBytePayload(t *testing.T) {
 	for _, successCase := range successCases {
 		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(successCase.input)
-			gotByte, gotErr := readTagBytePayload(buffer, successCase.order)
+			gotByte, gotErr := readTagBytePayload(buffer, Options{ByteOrder: successCase.order})
 			if gotByte != successCase.wantByte {
 				t.Errorf("got %v, want %v", gotByte, successCase.wantByte)
 			}
@@ -419,7 +420,7 @@ func TestReadTagBytePayload(t *testing.T) {
 	for _, failureCase := range failureCases {
 		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(failureCase.input)
-			_, gotErr := readTagBytePayload(buffer, failureCase.order)
+			_, gotErr := readTagBytePayload(buffer, Options{ByteOrder: failureCase.order})
 			if gotErr == nil {
 				t.Errorf("got nil, want non-nil")
 			}
@@ -428,7 +429,7 @@ func TestReadTagBytePayload(t *testing.T) {
 
 	t.Run("Test failure case: broken io.Reader", func(t *testing.T) {
 		errBuffer := iotest.ErrReader(fmt.Errorf("mock broken io.reader"))
-		_, gotErr := readTagBytePayload(errBuffer, binary.LittleEndian)
+		_, gotErr := readTagBytePayload(errBuffer, Options{ByteOrder: binary.LittleEndian})
 		if gotErr == nil {
 			t.Errorf("got nil, want non-nil")
 		}
@@ -452,7 +453,7 @@ func TestReadTagShortPayload(t *testing.T) {
 	for _, successCase := range successCases {
 		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(successCase.input)
-			gotShort, gotErr := readTagShortPayload(buffer, successCase.order)
+			gotShort, gotErr := readTagShortPayload(buffer, Options{ByteOrder: successCase.order})
 			if gotShort != successCase.wantShort {
 				t.Errorf("got %v, want %v", gotShort, successCase.wantShort)
 			}
@@ -473,7 +474,7 @@ func TestReadTagShortPayload(t *testing.T) {
 	for _, failureCase := range failureCases {
 		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(failureCase.input)
-			_, gotErr := readTagShortPayload(buffer, failureCase.order)
+			_, gotErr := readTagShortPayload(buffer, Options{ByteOrder: failureCase.order})
 			if gotErr == nil {
 				t.Errorf("got nil, want non-nil")
 			}
@@ -482,7 +483,7 @@ func TestReadTagShortPayload(t *testing.T) {
 
 	t.Run("Test failure case: broken io.Reader", func(t *testing.T) {
 		errBuffer := iotest.ErrReader(fmt.Errorf("mock broken io.reader"))
-		_, gotErr := readTagShortPayload(errBuffer, binary.LittleEndian)
+		_, gotErr := readTagShortPayload(errBuffer, Options{ByteOrder: binary.LittleEndian})
 		if gotErr == nil {
 			t.Errorf("got nil, want non-nil")
 		}
@@ -506,7 +507,7 @@ func TestReadTagIntPayload(t *testing.T) {
 	for _, successCase := range successCases {
 		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(successCase.input)
-			gotInt, gotErr := readTagIntPayload(buffer, successCase.order)
+			gotInt, gotErr := readTagIntPayload(buffer, Options{ByteOrder: successCase.order})
 			if gotInt != successCase.wantInt {
 				t.Errorf("got %v, want %v", gotInt, successCase.wantInt)
 			}
@@ -527,7 +528,7 @@ func TestReadTagIntPayload(t *testing.T) {
 	for _, failureCase := range failureCases {
 		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(failureCase.input)
-			_, gotErr := readTagIntPayload(buffer, failureCase.order)
+			_, gotErr := readTagIntPayload(buffer, Options{ByteOrder: failureCase.order})
 			if gotErr == nil {
 				t.Errorf("got nil, want non-nil")
 			}
@@ -536,7 +537,7 @@ func TestReadTagIntPayload(t *testing.T) {
 
 	t.Run("Test failure case: broken io.Reader", func(t *testing.T) {
 		errBuffer := iotest.ErrReader(fmt.Errorf("mock broken io.reader"))
-		_, gotErr := readTagIntPayload(errBuffer, binary.LittleEndian)
+		_, gotErr := readTagIntPayload(errBuffer, Options{ByteOrder: binary.LittleEndian})
 		if gotErr == nil {
 			t.Errorf("got nil, want non-nil")
 		}
@@ -561,7 +562,7 @@ func TestReadTagLongPayload(t *testing.T) {
 	for _, successCase := range successCases {
 		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(successCase.input)
-			gotLong, gotErr := readTagLongPayload(buffer, successCase.order)
+			gotLong, gotErr := readTagLongPayload(buffer, Options{ByteOrder: successCase.order})
 			if gotLong != successCase.wantLong {
 				t.Errorf("got %v, want %v", gotLong, successCase.wantLong)
 			}
@@ -582,7 +583,7 @@ func TestReadTagLongPayload(t *testing.T) {
 	for _, failureCase := range failureCases {
 		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(failureCase.input)
-			_, gotErr := readTagLongPayload(buffer, failureCase.order)
+			_, gotErr := readTagLongPayload(buffer, Options{ByteOrder: failureCase.order})
 			if gotErr == nil {
 				t.Errorf("got nil, want non-nil")
 			}
@@ -591,7 +592,7 @@ func TestReadTagLongPayload(t *testing.T) {
 
 	t.Run("Test failure case: broken io.Reader", func(t *testing.T) {
 		errBuffer := iotest.ErrReader(fmt.Errorf("mock broken io.reader"))
-		_, gotErr := readTagLongPayload(errBuffer, binary.LittleEndian)
+		_, gotErr := readTagLongPayload(errBuffer, Options{ByteOrder: binary.LittleEndian})
 		if gotErr == nil {
 			t.Errorf("got nil, want non-nil")
 		}
@@ -619,7 +620,7 @@ func TestReadTagFloatPayload(t *testing.T) {
 	for _, successCase := range successCases {
 		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(successCase.input)
-			gotFloat, gotErr := readTagFloatPayload(buffer, successCase.order)
+			gotFloat, gotErr := readTagFloatPayload(buffer, Options{ByteOrder: successCase.order})
 			if gotFloat != successCase.wantFloat {
 				t.Errorf("got %v, want %v", gotFloat, successCase.wantFloat)
 			}
@@ -631,7 +632,7 @@ func TestReadTagFloatPayload(t *testing.T) {
 
 	t.Run("Test success case: NaN", func(t *testing.T) {
 		buffer := bytes.NewBuffer([]byte{0x00, 0x00, 0xC0, 0x7F})
-		gotFloat, gotErr := readTagFloatPayload(buffer, binary.LittleEndian)
+		gotFloat, gotErr := readTagFloatPayload(buffer, Options{ByteOrder: binary.LittleEndian})
 		if !math.IsNaN(float64(gotFloat)) {
 			t.Errorf("got %v, want NaN", gotFloat)
 		}
@@ -640,6 +641,21 @@ func TestReadTagFloatPayload(t *testing.T) {
 		}
 	})
 
+	bitPatterns := []uint32{0x00000000, 0x80000000, 0x7FC00000, 0xFFC00000, 0x7FA00000, 0x7F800001, 0xFFBFFFFF,
+		0x7FC12345, 0x00000001, 0x807FFFFF}
+	for _, bits := range bitPatterns {
+		t.Run(fmt.Sprintf("Test success case: bit pattern %08X", bits), func(t *testing.T) {
+			input := binary.BigEndian.AppendUint32(nil, bits)
+			gotFloat, gotErr := readTagFloatPayload(bytes.NewBuffer(input), Options{ByteOrder: binary.BigEndian})
+			if math.Float32bits(gotFloat) != bits {
+				t.Errorf("got %08X, want %08X", math.Float32bits(gotFloat), bits)
+			}
+			if gotErr != nil {
+				t.Errorf("got %v, want nil", gotErr)
+			}
+		})
+	}
+
 	failureCases := []struct {
 		name  string
 		order binary.ByteOrder
@@ -651,7 +667,7 @@ func TestReadTagFloatPayload(t *testing.T) {
 	for _, failureCase := range failureCases {
 		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(failureCase.input)
-			_, gotErr := readTagFloatPayload(buffer, failureCase.order)
+			_, gotErr := readTagFloatPayload(buffer, Options{ByteOrder: failureCase.order})
 			if gotErr == nil {
 				t.Errorf("got nil, want non-nil")
 			}
@@ -660,7 +676,7 @@ func TestReadTagFloatPayload(t *testing.T) {
 
 	t.Run("Test failure case: broken io.Reader", func(t *testing.T) {
 		errBuffer := iotest.ErrReader(fmt.Errorf("mock broken io.reader"))
-		_, gotErr := readTagFloatPayload(errBuffer, binary.LittleEndian)
+		_, gotErr := readTagFloatPayload(errBuffer, Options{ByteOrder: binary.LittleEndian})
 		if gotErr == nil {
 			t.Errorf("got nil, want non-nil")
 		}
@@ -691,7 +707,7 @@ func TestReadTagDoublePayload(t *testing.T) {
 	for _, successCase := range successCases {
 		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(successCase.input)
-			gotDouble, gotErr := readTagDoublePayload(buffer, successCase.order)
+			gotDouble, gotErr := readTagDoublePayload(buffer, Options{ByteOrder: successCase.order})
 			if gotDouble != successCase.wantDouble {
 				t.Errorf("got %v, want %v", gotDouble, successCase.wantDouble)
 			}
@@ -703,7 +719,7 @@ func TestReadTagDoublePayload(t *testing.T) {
 
 	t.Run("Test success case: NaN", func(t *testing.T) {
 		buffer := bytes.NewBuffer([]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xF8, 0xFF})
-		gotDouble, gotErr := readTagDoublePayload(buffer, binary.LittleEndian)
+		gotDouble, gotErr := readTagDoublePayload(buffer, Options{ByteOrder: binary.LittleEndian})
 		if !math.IsNaN(gotDouble) {
 			t.Errorf("got %v, want NaN", gotDouble)
 		}
@@ -712,6 +728,22 @@ func TestReadTagDoublePayload(t *testing.T) {
 		}
 	})
 
+	bitPatterns := []uint64{0x0000000000000000, 0x8000000000000000, 0x7FF8000000000000, 0xFFF8000000000000,
+		0x7FF4000000000000, 0x7FF0000000000001, 0xFFF7FFFFFFFFFFFF, 0x7FF8123456789ABC, 0x0000000000000001,
+		0x800FFFFFFFFFFFFF}
+	for _, bits := range bitPatterns {
+		t.Run(fmt.Sprintf("Test success case: bit pattern %016X", bits), func(t *testing.T) {
+			input := binary.BigEndian.AppendUint64(nil, bits)
+			gotDouble, gotErr := readTagDoublePayload(bytes.NewBuffer(input), Options{ByteOrder: binary.BigEndian})
+			if math.Float64bits(gotDouble) != bits {
+				t.Errorf("got %016X, want %016X", math.Float64bits(gotDouble), bits)
+			}
+			if gotErr != nil {
+				t.Errorf("got %v, want nil", gotErr)
+			}
+		})
+	}
+
 	failureCases := []struct {
 		name  string
 		order binary.ByteOrder
@@ -723,7 +755,7 @@ func TestReadTagDoublePayload(t *testing.T) {
 	for _, failureCase := range failureCases {
 		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(failureCase.input)
-			_, gotErr := readTagDoublePayload(buffer, failureCase.order)
+			_, gotErr := readTagDoublePayload(buffer, Options{ByteOrder: failureCase.order})
 			if gotErr == nil {
 				t.Errorf("got nil, want non-nil")
 			}
@@ -732,7 +764,7 @@ func TestReadTagDoublePayload(t *testing.T) {
 
 	t.Run("Test failure case: broken io.Reader", func(t *testing.T) {
 		errBuffer := iotest.ErrReader(fmt.Errorf("mock broken io.reader"))
-		_, gotErr := readTagDoublePayload(errBuffer, binary.LittleEndian)
+		_, gotErr := readTagDoublePayload(errBuffer, Options{ByteOrder: binary.LittleEndian})
 		if gotErr == nil {
 			t.Errorf("got nil, want non-nil")
 		}
@@ -756,7 +788,7 @@ func TestReadTagByteArrayPayload(t *testing.T) {
 	for _, successCase := range successCases {
 		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(successCase.input)
-			gotByteArray, gotErr := readTagByteArrayPayload(buffer, successCase.order)
+			gotByteArray, gotErr := readTagByteArrayPayload(buffer, Options{ByteOrder: successCase.order})
 
 			gotLength := len(gotByteArray)
 			wantLength := len(successCase.input) - 4
@@ -790,7 +822,7 @@ func TestReadTagByteArrayPayload(t *testing.T) {
 	for _, failureCase := range failureCases {
 		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(failureCase.input)
-			_, gotErr := readTagByteArrayPayload(buffer, failureCase.order)
+			_, gotErr := readTagByteArrayPayload(buffer, Options{ByteOrder: failureCase.order})
 			if gotErr == nil {
 				t.Errorf("got nil, want non-nil")
 			}
@@ -799,7 +831,7 @@ func TestReadTagByteArrayPayload(t *testing.T) {
 
 	t.Run("Test failure case: broken io.Reader", func(t *testing.T) {
 		errBuffer := iotest.ErrReader(fmt.Errorf("mock broken io.reader"))
-		_, gotErr := readTagByteArrayPayload(errBuffer, binary.LittleEndian)
+		_, gotErr := readTagByteArrayPayload(errBuffer, Options{ByteOrder: binary.LittleEndian})
 		if gotErr == nil {
 			t.Errorf("got nil, want non-nil")
 		}
@@ -830,7 +862,7 @@ func TestReadTagStringPayload(t *testing.T) {
 	for _, successCase := range successCases {
 		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(successCase.input)
-			gotString, gotErr := readTagStringPayload(buffer, successCase.order)
+			gotString, gotErr := readTagStringPayload(buffer, Options{ByteOrder: successCase.order})
 			if gotString != successCase.wantString {
 				t.Errorf("got %v, want %v", gotString, successCase.wantString)
 			}
@@ -855,7 +887,7 @@ func TestReadTagStringPayload(t *testing.T) {
 	for _, failureCase := range failureCases {
 		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(failureCase.input)
-			_, gotErr := readTagStringPayload(buffer, failureCase.order)
+			_, gotErr := readTagStringPayload(buffer, Options{ByteOrder: failureCase.order})
 			if gotErr == nil {
 				t.Errorf("got nil, want non-nil")
 			}
@@ -864,7 +896,7 @@ func TestReadTagStringPayload(t *testing.T) {
 
 	t.Run("Test failure case: broken io.Reader", func(t *testing.T) {
 		errBuffer := iotest.ErrReader(fmt.Errorf("mock broken io.reader"))
-		_, gotErr := readTagStringPayload(errBuffer, binary.LittleEndian)
+		_, gotErr := readTagStringPayload(errBuffer, Options{ByteOrder: binary.LittleEndian})
 		if gotErr == nil {
 			t.Errorf("got nil, want non-nil")
 		}
@@ -872,11 +904,126 @@ func TestReadTagStringPayload(t *testing.T) {
 }
 
 func TestReadTagListPayload(t *testing.T) {
-	_ = t
+	successCases := []struct {
+		name          string
+		wantElementID uint8
+		wantLength    int
+		order         binary.ByteOrder
+		input         []byte
+	}{
+		{"empty list of ends", tagEnd, 0, binary.BigEndian, []byte{tagEnd, 0x00, 0x00, 0x00, 0x00}},
+		{"empty list of strings", tagString, 0, binary.BigEndian, []byte{tagString, 0x00, 0x00, 0x00, 0x00}},
+		{"empty list of shorts", tagShort, 0, binary.BigEndian, []byte{tagShort, 0x00, 0x00, 0x00, 0x00}},
+		{"list of bytes", tagByte, 2, binary.BigEndian, []byte{tagByte, 0x00, 0x00, 0x00, 0x02, 0x01, 0x02}},
+		{"little endian list of shorts", tagShort, 1, binary.LittleEndian, []byte{tagShort, 0x01, 0x00, 0x00, 0x00,
+			0x01, 0x02}},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			buffer := bytes.NewBuffer(successCase.input)
+			gotElementID, gotList, gotErr := readTagListPayload(buffer, Options{ByteOrder: successCase.order})
+			if gotElementID != successCase.wantElementID {
+				t.Errorf("got element ID %v, want %v", gotElementID, successCase.wantElementID)
+			}
+			if len(gotList) != successCase.wantLength {
+				t.Errorf("got length=%v, want length=%v", len(gotList), successCase.wantLength)
+			}
+			if gotErr != nil {
+				t.Errorf("got %v, want nil", gotErr)
+			}
+		})
+	}
+
+	failureCases := []struct {
+		name  string
+		order binary.ByteOrder
+		input []byte
+	}{
+		{"empty buffer", binary.BigEndian, []byte{}},
+		{"partial length", binary.BigEndian, []byte{tagByte, 0x00, 0x00}},
+		{"missing element", binary.BigEndian, []byte{tagByte, 0x00, 0x00, 0x00, 0x02, 0x01}},
+		{"non-empty list of ends", binary.BigEndian, []byte{tagEnd, 0x00, 0x00, 0x00, 0x01, 0x00}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			buffer := bytes.NewBuffer(failureCase.input)
+			_, _, gotErr := readTagListPayload(buffer, Options{ByteOrder: failureCase.order})
+			if gotErr == nil {
+				t.Errorf("got nil, want non-nil")
+			}
+		})
+	}
+
+	t.Run("Test success case: element ID kept on the tag", func(t *testing.T) {
+		buffer := bytes.NewBuffer([]byte{tagList, 0x00, 0x01, 'l', tagString, 0x00, 0x00, 0x00, 0x00})
+		gotTag, gotErr := ReadTag(buffer)
+		if gotTag.ElementID() != tagString {
+			t.Errorf("got %v, want %v", gotTag.ElementID(), tagString)
+		}
+		if gotErr != nil {
+			t.Errorf("got %v, want nil", gotErr)
+		}
+	})
+
+	t.Run("Test failure case: broken io.Reader", func(t *testing.T) {
+		errBuffer := iotest.ErrReader(fmt.Errorf("mock broken io.reader"))
+		_, _, gotErr := readTagListPayload(errBuffer, Options{ByteOrder: binary.LittleEndian})
+		if gotErr == nil {
+			t.Errorf("got nil, want non-nil")
+		}
+	})
 }
 
 func TestReadTagCompoundPayload(t *testing.T) {
-	_ = t
+	duplicates := []byte{tagByte, 0x00, 0x01, 'a', 0x01, tagByte, 0x00, 0x01, 'b', 0x02, tagShort, 0x00, 0x01, 'a',
+		0x00, 0x03, tagEnd}
+
+	successCases := []struct {
+		name   string
+		policy DuplicateKeyPolicy
+		input  []byte
+		want   []Tag
+	}{
+		{"empty compound", DuplicateError, []byte{tagEnd}, nil},
+		{"unique names", DuplicateError, []byte{tagByte, 0x00, 0x01, 'a', 0x01, tagByte, 0x00, 0x01, 'b', 0x02, tagEnd},
+			[]Tag{NewByte("a", 1), NewByte("b", 2)}},
+		{"keep all duplicates", DuplicateKeepAll, duplicates, []Tag{NewByte("a", 1), NewByte("b", 2), NewShort("a", 3)}},
+		{"first duplicate wins", DuplicateFirstWins, duplicates, []Tag{NewByte("a", 1), NewByte("b", 2)}},
+		{"last duplicate wins", DuplicateLastWins, duplicates, []Tag{NewShort("a", 3), NewByte("b", 2)}},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			buffer := bytes.NewBuffer(successCase.input)
+			got, gotErr := readTagCompoundPayload(buffer, newOptions([]Option{WithDuplicateKeys(successCase.policy)}))
+			if gotErr != nil {
+				t.Fatalf("got %v, want nil", gotErr)
+			}
+			if !slices.EqualFunc(got, successCase.want, func(a, b Tag) bool {
+				return a.id == b.id && a.name == b.name && payloadsEqual(a.payload, b.payload)
+			}) {
+				t.Errorf("got %v, want %v", got, successCase.want)
+			}
+		})
+	}
+
+	failureCases := []struct {
+		name   string
+		policy DuplicateKeyPolicy
+		input  []byte
+	}{
+		{"duplicate name", DuplicateError, duplicates},
+		{"missing end", DuplicateKeepAll, []byte{tagByte, 0x00, 0x01, 'a', 0x01}},
+		{"broken child", DuplicateLastWins, []byte{tagByte, 0x00, 0x01, 'a'}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			buffer := bytes.NewBuffer(failureCase.input)
+			_, gotErr := readTagCompoundPayload(buffer, newOptions([]Option{WithDuplicateKeys(failureCase.policy)}))
+			if gotErr == nil {
+				t.Errorf("got nil, want non-nil")
+			}
+		})
+	}
 }
 
 func TestReadTagIntArrayPayload(t *testing.T) {
@@ -898,7 +1045,7 @@ func TestReadTagIntArrayPayload(t *testing.T) {
 	for _, successCase := range successCases {
 		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(successCase.input)
-			gotIntArray, gotErr := readTagIntArrayPayload(buffer, successCase.order)
+			gotIntArray, gotErr := readTagIntArrayPayload(buffer, Options{ByteOrder: successCase.order})
 
 			gotLength := len(gotIntArray)
 			wantLength := (len(successCase.input) - 4) / 4
@@ -934,7 +1081,7 @@ func TestReadTagIntArrayPayload(t *testing.T) {
 	for _, failureCase := range failureCases {
 		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(failureCase.input)
-			_, gotErr := readTagIntArrayPayload(buffer, failureCase.order)
+			_, gotErr := readTagIntArrayPayload(buffer, Options{ByteOrder: failureCase.order})
 			if gotErr == nil {
 				t.Errorf("got nil, want non-nil")
 			}
@@ -943,7 +1090,7 @@ func TestReadTagIntArrayPayload(t *testing.T) {
 
 	t.Run("Test failure case: broken io.Reader", func(t *testing.T) {
 		errBuffer := iotest.ErrReader(fmt.Errorf("mock broken io.reader"))
-		_, gotErr := readTagIntArrayPayload(errBuffer, binary.LittleEndian)
+		_, gotErr := readTagIntArrayPayload(errBuffer, Options{ByteOrder: binary.LittleEndian})
 		if gotErr == nil {
 			t.Errorf("got nil, want non-nil")
 		}
@@ -972,7 +1119,7 @@ func TestReadTagLongArrayPayload(t *testing.T) {
 	for _, successCase := range successCases {
 		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(successCase.input)
-			gotLongArray, gotErr := readTagLongArrayPayload(buffer, successCase.order)
+			gotLongArray, gotErr := readTagLongArrayPayload(buffer, Options{ByteOrder: successCase.order})
 
 			gotLength := len(gotLongArray)
 			wantLength := (len(successCase.input) - 4) / 8
@@ -1010,7 +1157,7 @@ func TestReadTagLongArrayPayload(t *testing.T) {
 	for _, failureCase := range failureCases {
 		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
 			buffer := bytes.NewBuffer(failureCase.input)
-			_, gotErr := readTagLongArrayPayload(buffer, failureCase.order)
+			_, gotErr := readTagLongArrayPayload(buffer, Options{ByteOrder: failureCase.order})
 			if gotErr == nil {
 				t.Errorf("got nil, want non-nil")
 			}
@@ -1019,9 +1166,34 @@ func TestReadTagLongArrayPayload(t *testing.T) {
 
 	t.Run("Test failure case: broken io.Reader", func(t *testing.T) {
 		errBuffer := iotest.ErrReader(fmt.Errorf("mock broken io.reader"))
-		_, gotErr := readTagLongArrayPayload(errBuffer, binary.LittleEndian)
+		_, gotErr := readTagLongArrayPayload(errBuffer, Options{ByteOrder: binary.LittleEndian})
 		if gotErr == nil {
 			t.Errorf("got nil, want non-nil")
 		}
 	})
 }
+
+func TestReadTagFloatBits(t *testing.T) {
+	input := []byte{tagCompound, 0, 0,
+		tagList, 0, 1, 'f', tagFloat, 0, 0, 0, 2, 0x7F, 0xA0, 0x00, 0x01, 0x80, 0x00, 0x00, 0x00,
+		tagDouble, 0, 1, 'd', 0xFF, 0xF4, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+		tagEnd}
+
+	got, err := ReadTag(bytes.NewBuffer(input))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	list, _ := compoundChild(got, "f")
+	elements := list.payload.([]any)
+	if bits := math.Float32bits(elements[0].(float32)); bits != 0x7FA00001 {
+		t.Errorf("got %08X, want 7FA00001", bits)
+	}
+	if bits := math.Float32bits(elements[1].(float32)); bits != 0x80000000 {
+		t.Errorf("got %08X, want 80000000", bits)
+	}
+	double, _ := compoundChild(got, "d")
+	if bits := math.Float64bits(double.payload.(float64)); bits != 0xFFF4000000000001 {
+		t.Errorf("got %016X, want FFF4000000000001", bits)
+	}
+}
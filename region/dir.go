@@ -0,0 +1,86 @@
+package region
+
+import (
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+
+	"PudFish/nbt"
+	"PudFish/nbt/coords"
+)
+
+// ChunkPos returns the world chunk coordinates of the chunk, given the region coordinates of its region file.
+func (h ChunkHeader) ChunkPos(regionX, regionZ int) (chunkX, chunkZ int) {
+	return coords.RegionToChunk(regionX) + h.X, coords.RegionToChunk(regionZ) + h.Z
+}
+
+// BlockPos returns the world block coordinates of the chunk's north west corner, the block with the least X and Z,
+// given the region coordinates of its region file.
+func (h ChunkHeader) BlockPos(regionX, regionZ int) (blockX, blockZ int) {
+	chunkX, chunkZ := h.ChunkPos(regionX, regionZ)
+	return coords.ChunkToBlock(chunkX), coords.ChunkToBlock(chunkZ)
+}
+
+// WorldChunk is a chunk found by ScanDir, with the coordinates of its region and its world chunk coordinates.
+type WorldChunk struct {
+	Chunk
+	RegionX, RegionZ int
+	ChunkX, ChunkZ   int
+}
+
+// ScanDir returns an iterator over the chunks of every region file in the directory, such as the region directory of
+// a dimension, in file name then header order. Region files are opened one at a time, and their chunks read one at a
+// time as by Region.Chunks, so a whole dimension is scanned in constant memory. Files not named as region files are
+// skipped, and chunks stored in external .mcc files in the directory are read. A region file or chunk that fails to
+// read is yielded with its error, and the scan continues. The options configure decoding.
+func ScanDir(dir string, opts ...nbt.Option) iter.Seq2[WorldChunk, error] {
+	return func(yield func(WorldChunk, error) bool) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			yield(WorldChunk{}, fmt.Errorf("Unable to scan region directory: %w", err))
+			return
+		}
+
+		for _, entry := range entries {
+			regionX, regionZ, ok := coords.ParseRegionFileName(entry.Name())
+			if entry.IsDir() || !ok {
+				continue
+			}
+			if !scanFile(filepath.Join(dir, entry.Name()), regionX, regionZ, opts, yield) {
+				return
+			}
+		}
+	}
+}
+
+// scanFile yields the chunks of the region file at the path, returning false if the caller stopped the iteration.
+func scanFile(path string, regionX, regionZ int, opts []nbt.Option, yield func(WorldChunk, error) bool) bool {
+	failed := WorldChunk{RegionX: regionX, RegionZ: regionZ}
+	f, err := os.Open(path) // #nosec G304 -- the caller chooses which directory to scan
+	if err != nil {
+		return yield(failed, fmt.Errorf("Unable to scan region %v,%v: %w", regionX, regionZ, err))
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return yield(failed, fmt.Errorf("Unable to scan region %v,%v: %w", regionX, regionZ, err))
+	}
+	region, err := Open(f, info.Size())
+	if err != nil {
+		return yield(failed, fmt.Errorf("Unable to scan region %v,%v: %w", regionX, regionZ, err))
+	}
+	region.SetExternalDir(filepath.Dir(path), regionX, regionZ)
+
+	for chunk, err := range region.Chunks(opts...) {
+		chunkX, chunkZ := chunk.ChunkPos(regionX, regionZ)
+		if err != nil {
+			err = fmt.Errorf("Unable to scan region %v,%v: %w", regionX, regionZ, err)
+		}
+		if !yield(WorldChunk{Chunk: chunk, RegionX: regionX, RegionZ: regionZ, ChunkX: chunkX, ChunkZ: chunkZ}, err) {
+			return false
+		}
+	}
+	return true
+}
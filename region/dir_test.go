@@ -0,0 +1,145 @@
+package region
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"PudFish/nbt"
+)
+
+func TestChunkHeaderPos(t *testing.T) {
+	tests := []struct {
+		name                   string
+		header                 ChunkHeader
+		regionX, regionZ       int
+		wantChunkX, wantChunkZ int
+		wantBlockX, wantBlockZ int
+	}{
+		{name: "Test success case: origin region", header: ChunkHeader{X: 3, Z: 5}, wantChunkX: 3, wantChunkZ: 5,
+			wantBlockX: 48, wantBlockZ: 80},
+		{name: "Test success case: negative region", header: ChunkHeader{X: 31, Z: 0}, regionX: -1, regionZ: 2,
+			wantChunkX: -1, wantChunkZ: 64, wantBlockX: -16, wantBlockZ: 1024},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunkX, chunkZ := tt.header.ChunkPos(tt.regionX, tt.regionZ)
+			if chunkX != tt.wantChunkX || chunkZ != tt.wantChunkZ {
+				t.Errorf("ChunkPos() = %v,%v, want %v,%v", chunkX, chunkZ, tt.wantChunkX, tt.wantChunkZ)
+			}
+			blockX, blockZ := tt.header.BlockPos(tt.regionX, tt.regionZ)
+			if blockX != tt.wantBlockX || blockZ != tt.wantBlockZ {
+				t.Errorf("BlockPos() = %v,%v, want %v,%v", blockX, blockZ, tt.wantBlockX, tt.wantBlockZ)
+			}
+		})
+	}
+}
+
+// createDirRegion creates the region file of the name in the directory holding a test chunk at each of the chunk
+// coordinates within the region.
+func createDirRegion(t *testing.T, dir, name string, saved time.Time, chunks ...[2]int) {
+	t.Helper()
+	f, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatalf("Unable to create test region file: %v", err)
+	}
+	defer f.Close()
+
+	region, err := Create(f)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, c := range chunks {
+		chunk := Chunk{ChunkHeader: ChunkHeader{X: c[0], Z: c[1], Timestamp: saved},
+			Tag: decodeTestChunk(t, int32(c[0]), int32(c[1])), Compression: nbt.CompressionZlib} // #nosec G115 -- test
+		if err = region.WriteChunk(chunk); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+}
+
+func TestScanDir(t *testing.T) {
+	saved := time.Unix(1700000000, 0)
+
+	t.Run("Test success case: chunks of every region", func(t *testing.T) {
+		dir := t.TempDir()
+		createDirRegion(t, dir, "r.-1.0.mca", saved, [2]int{31, 0}, [2]int{0, 1})
+		createDirRegion(t, dir, "r.0.0.mca", saved, [2]int{2, 3})
+		if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a region"), 0o600); err != nil {
+			t.Fatalf("Unable to write test file: %v", err)
+		}
+
+		var got [][4]int
+		for chunk, err := range ScanDir(dir) {
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !chunk.Timestamp.Equal(saved) {
+				t.Errorf("Timestamp = %v, want %v", chunk.Timestamp, saved)
+			}
+			got = append(got, [4]int{chunk.RegionX, chunk.RegionZ, chunk.ChunkX, chunk.ChunkZ})
+		}
+		want := [][4]int{{-1, 0, -1, 0}, {-1, 0, -32, 1}, {0, 0, 2, 3}}
+		if len(got) != len(want) {
+			t.Fatalf("ScanDir() yielded %v, want %v", got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("ScanDir() yielded %v, want %v", got, want)
+				break
+			}
+		}
+	})
+
+	t.Run("Test success case: stops when the loop breaks", func(t *testing.T) {
+		dir := t.TempDir()
+		createDirRegion(t, dir, "r.0.0.mca", saved, [2]int{0, 0}, [2]int{1, 0})
+		createDirRegion(t, dir, "r.1.0.mca", saved, [2]int{0, 0})
+
+		count := 0
+		for range ScanDir(dir) {
+			count++
+			break
+		}
+		if count != 1 {
+			t.Errorf("ScanDir() yielded %v chunks after a break, want 1", count)
+		}
+	})
+
+	t.Run("Test failure case: corrupt region file", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "r.0.0.mca"), []byte("short"), 0o600); err != nil {
+			t.Fatalf("Unable to write test file: %v", err)
+		}
+		createDirRegion(t, dir, "r.1.0.mca", saved, [2]int{4, 5})
+
+		var errs, chunks int
+		for chunk, err := range ScanDir(dir) {
+			if err != nil {
+				errs++
+				continue
+			}
+			chunks++
+			if chunk.ChunkX != 36 || chunk.ChunkZ != 5 {
+				t.Errorf("ChunkX, ChunkZ = %v,%v, want 36,5", chunk.ChunkX, chunk.ChunkZ)
+			}
+		}
+		if errs != 1 || chunks != 1 {
+			t.Errorf("ScanDir() yielded %v errors and %v chunks, want 1 and 1", errs, chunks)
+		}
+	})
+
+	t.Run("Test failure case: missing directory", func(t *testing.T) {
+		var errs int
+		for _, err := range ScanDir(filepath.Join(t.TempDir(), "missing")) {
+			if err != nil {
+				errs++
+			}
+		}
+		if errs != 1 {
+			t.Errorf("ScanDir() yielded %v errors, want 1", errs)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package region
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"PudFish/nbt/coords"
+)
+
+// external is the directory of a region file and the coordinates of its region, locating the external .mcc files of
+// its oversized chunks.
+type external struct {
+	dir              string
+	regionX, regionZ int
+}
+
+// SetExternalDir sets the directory holding the region file and the region coordinates of the file, so chunks too large
+// for the region file are read from and written to external .mcc files in the directory, named by
+// coords.ExternalChunkFileName. Without it, reading an external chunk or writing a chunk of more than 255 sectors is an
+// error.
+func (region *Region) SetExternalDir(dir string, regionX, regionZ int) {
+	region.external = &external{dir: dir, regionX: regionX, regionZ: regionZ}
+}
+
+// externalPath returns the path of the external .mcc file of the chunk at chunk coordinates x and z, which may be
+// world coordinates as only their offset within the region is used.
+func (region *Region) externalPath(x, z int) (path string, err error) {
+	e := region.external
+	if e == nil {
+		return "", fmt.Errorf("external .mcc chunks need the region file's directory, see SetExternalDir")
+	}
+	chunkX := coords.RegionToChunk(e.regionX) + coords.ChunkInRegion(x)
+	chunkZ := coords.RegionToChunk(e.regionZ) + coords.ChunkInRegion(z)
+	return filepath.Join(e.dir, coords.ExternalChunkFileName(chunkX, chunkZ)), nil
+}
+
+// readExternal reads the compressed chunk data of the external .mcc file of the chunk with the header.
+func (region *Region) readExternal(h ChunkHeader) (data []byte, err error) {
+	path, err := region.externalPath(h.X, h.Z)
+	if err != nil {
+		return nil, err
+	}
+	data, err = os.ReadFile(path) // #nosec G304 -- the path is built from the region's directory
+	if err != nil {
+		return nil, fmt.Errorf("unable to read external chunk: %w", err)
+	}
+	return data, nil
+}
+
+// writeExternal writes the compressed chunk data to the external .mcc file of the chunk at chunk coordinates x and z,
+// through a temporary file renamed over it, so a failed write leaves any previous file whole.
+func (region *Region) writeExternal(x, z int, data []byte) (err error) {
+	path, err := region.externalPath(x, z)
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("unable to write external chunk: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = temp.Close()
+			_ = os.Remove(temp.Name())
+		}
+	}()
+
+	if _, err = temp.Write(data); err != nil {
+		return fmt.Errorf("unable to write external chunk: %w", err)
+	}
+	if err = temp.Sync(); err != nil {
+		return fmt.Errorf("unable to write external chunk: %w", err)
+	}
+	if err = temp.Close(); err != nil {
+		return fmt.Errorf("unable to write external chunk: %w", err)
+	}
+	if err = os.Rename(temp.Name(), path); err != nil {
+		return fmt.Errorf("unable to write external chunk: %w", err)
+	}
+	return nil
+}
+
+// removeExternal removes any external .mcc file of the chunk at chunk coordinates x and z, once the region file no
+// longer refers to it. Regions without an external directory have none to remove.
+func (region *Region) removeExternal(x, z int) (err error) {
+	if region.external == nil {
+		return nil
+	}
+	path, err := region.externalPath(x, z)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("unable to remove external chunk: %w", err)
+	}
+	return nil
+}
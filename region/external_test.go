@@ -0,0 +1,87 @@
+package region
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"PudFish/nbt"
+)
+
+func TestRegionExternalChunks(t *testing.T) {
+	huge, _ := nbt.NewCompound("", nbt.NewByteArray("data", make([]byte, maxSectors*SectorSize)))
+	hugeChunk := Chunk{ChunkHeader: ChunkHeader{X: 3, Z: 4}, Tag: huge}
+
+	// createExternal creates a region at region coordinates -1,2 holding the huge chunk, returning the region file, its
+	// directory and the path of the huge chunk's external file.
+	createExternal := func(t *testing.T) (f *os.File, region *Region, dir, mcc string) {
+		f, region = createTestRegion(t)
+		dir = filepath.Dir(f.Name())
+		region.SetExternalDir(dir, -1, 2)
+		if err := region.WriteChunk(hugeChunk); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return f, region, dir, filepath.Join(dir, "c.-29.68.mcc")
+	}
+
+	t.Run("Test success case: oversized chunk written externally", func(t *testing.T) {
+		f, _, dir, mcc := createExternal(t)
+		if _, err := os.Stat(mcc); err != nil {
+			t.Fatalf("got %v, want the external file", err)
+		}
+
+		got := reopen(t, f)
+		if h, _ := got.Header(3, 4); h.Sectors != 1 {
+			t.Errorf("got header %+v, want 1 sector", h)
+		}
+		got.SetExternalDir(dir, -1, 2)
+		tag, ok, err := got.Chunk(3, 4)
+		if err != nil || !ok {
+			t.Fatalf("got %v %v, want the chunk", ok, err)
+		}
+		data, _ := nbt.NewView(tag).Child("data")
+		if data.Len() != maxSectors*SectorSize {
+			t.Errorf("got %v bytes of data, want %v", data.Len(), maxSectors*SectorSize)
+		}
+	})
+
+	t.Run("Test success case: chunk that fits again removes its external file", func(t *testing.T) {
+		_, region, _, mcc := createExternal(t)
+		small := Chunk{ChunkHeader: hugeChunk.ChunkHeader, Tag: decodeTestChunk(t, 3, 4)}
+		if err := region.WriteChunk(small); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := os.Stat(mcc); !os.IsNotExist(err) {
+			t.Errorf("got %v, want the external file removed", err)
+		}
+	})
+
+	t.Run("Test success case: delete removes the external file", func(t *testing.T) {
+		_, region, _, mcc := createExternal(t)
+		if err := region.DeleteChunk(3, 4); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := os.Stat(mcc); !os.IsNotExist(err) {
+			t.Errorf("got %v, want the external file removed", err)
+		}
+	})
+
+	t.Run("Test failure case: external chunk without a directory", func(t *testing.T) {
+		f, _, _, _ := createExternal(t)
+		if _, _, err := reopen(t, f).Chunk(3, 4); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+
+	t.Run("Test failure case: missing external file", func(t *testing.T) {
+		f, _, dir, mcc := createExternal(t)
+		if err := os.Remove(mcc); err != nil {
+			t.Fatalf("Unable to remove external file: %v", err)
+		}
+		got := reopen(t, f)
+		got.SetExternalDir(dir, -1, 2)
+		if _, _, err := got.Chunk(3, 4); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,131 @@
+package region
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"PudFish/nbt"
+	"PudFish/nbt/coords"
+)
+
+// RecoveredChunk is a chunk salvaged from a region file by Scan. Its X and Z are its offset within the region, from
+// the coordinates it stores, and its Sector and Sectors where it was found. Its Timestamp is zero, as the header is not
+// trusted.
+type RecoveredChunk struct {
+	Chunk
+	// ChunkX and ChunkZ are the chunk coordinates stored in the chunk.
+	ChunkX, ChunkZ int
+}
+
+// Scan salvages chunks from a region file of size bytes whose header is destroyed or untrustworthy, by walking its
+// sectors looking for plausible chunk headers and keeping those that decode. The header itself is ignored. Chunks are
+// located by the coordinates they store (xPos and zPos, at the root or in the Level compound before Java edition 1.18),
+// so chunks without them are skipped. Where two chunks store the same coordinates both are returned, in sector order.
+// Chunks stored in external .mcc files are skipped. The options configure decoding.
+//
+// The chunks are written to a new region file with Rebuild.
+func Scan(r io.ReaderAt, size int64, opts ...nbt.Option) (chunks []RecoveredChunk, err error) {
+	region := &Region{r: r, size: size}
+	sectors := int((size + SectorSize - 1) / SectorSize)
+	header := make([]byte, chunkHeaderSize)
+	var buffer []byte
+
+	for sector := headerSectors; sector < sectors; {
+		n, err := r.ReadAt(header, int64(sector)*SectorSize)
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("Unable to scan region sector %v: %w", sector, err)
+		}
+		if n < chunkHeaderSize {
+			break
+		}
+
+		chunk, ok := region.salvage(sector, header, &buffer, opts)
+		if !ok {
+			sector++
+			continue
+		}
+		chunks = append(chunks, chunk)
+		sector += chunk.Sectors
+	}
+
+	return chunks, nil
+}
+
+// salvage decodes the chunk at the sector if its chunk header is plausible. The boolean is false if it is not, or the
+// chunk does not decode to a compound storing its coordinates.
+func (region *Region) salvage(sector int, header []byte, buffer *[]byte, opts []nbt.Option) (chunk RecoveredChunk,
+	ok bool) {
+	length := int64(binary.BigEndian.Uint32(header))
+	if length <= 1 || int64(sector)*SectorSize+chunkHeaderSize-1+length > region.size {
+		return RecoveredChunk{}, false
+	}
+	sectors := int((chunkHeaderSize - 1 + length + SectorSize - 1) / SectorSize)
+	if sectors > maxSectors {
+		return RecoveredChunk{}, false
+	}
+
+	h := ChunkHeader{Sector: sector, Sectors: sectors}
+	t, compression, err := region.read(h, buffer, opts)
+	if err != nil {
+		return RecoveredChunk{}, false
+	}
+
+	v := nbt.NewView(t)
+	if level, ok := v.Child("Level"); ok {
+		v = level
+	}
+	x, xOK := v.Child("xPos")
+	z, zOK := v.Child("zPos")
+	xPos, xInt := x.Payload().(int32)
+	zPos, zInt := z.Payload().(int32)
+	if !xOK || !zOK || !xInt || !zInt {
+		return RecoveredChunk{}, false
+	}
+
+	h.X, h.Z = coords.ChunkInRegion(int(xPos)), coords.ChunkInRegion(int(zPos))
+	return RecoveredChunk{Chunk: Chunk{ChunkHeader: h, Tag: t, Compression: compression}, ChunkX: int(xPos),
+		ChunkZ: int(zPos)}, true
+}
+
+// Rebuild creates a region file in f holding the chunks, as returned by Scan, each written with WriteChunk at the
+// coordinates it stores, keeping its compression and timestamped now. Where chunks share an offset within the region,
+// the one with the greatest LastUpdate is kept, or the last of them if their LastUpdate is equal. The options configure
+// encoding.
+func Rebuild(f File, recovered []RecoveredChunk, opts ...nbt.Option) (region *Region, err error) {
+	var kept [chunks]*RecoveredChunk
+	for i := range recovered {
+		chunk := &recovered[i]
+		index := coords.RegionIndex(chunk.ChunkX, chunk.ChunkZ)
+		if kept[index] == nil || lastUpdate(chunk.Tag) >= lastUpdate(kept[index].Tag) {
+			kept[index] = chunk
+		}
+	}
+
+	region, err = Create(f)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to rebuild region: %w", err)
+	}
+	for _, chunk := range kept {
+		if chunk == nil {
+			continue
+		}
+		c := chunk.Chunk
+		c.X, c.Z = chunk.ChunkX, chunk.ChunkZ
+		if err = region.WriteChunk(c, opts...); err != nil {
+			return nil, fmt.Errorf("Unable to rebuild region: %w", err)
+		}
+	}
+	return region, nil
+}
+
+// lastUpdate returns the LastUpdate game tick of a chunk, at the root or in its Level compound, or 0 if it has none.
+func lastUpdate(t nbt.Tag) int64 {
+	v := nbt.NewView(t)
+	if level, ok := v.Child("Level"); ok {
+		v = level
+	}
+	tick, _ := v.Child("LastUpdate")
+	update, _ := tick.Payload().(int64)
+	return update
+}
@@ -0,0 +1,136 @@
+package region
+
+import (
+	"bytes"
+	"encoding/binary"
+	"slices"
+	"testing"
+
+	"PudFish/nbt"
+)
+
+// chunkSectors returns the sectors holding a chunk, compressed as given, as buildRegion lays them out.
+func chunkSectors(data []byte, compression byte) []byte {
+	return buildRegion(testChunk{data: data, compression: compression})[headerSectors*SectorSize:]
+}
+
+// paddedTestChunk returns an uncompressed chunk compound holding xPos and zPos, and a byte array of the given length
+// to pad it, optionally wrapped in a Level compound as before Java edition 1.18.
+func paddedTestChunk(x, z int32, padding int32, level bool) []byte {
+	b := []byte{10, 0, 0}
+	if level {
+		b = append(b, 10, 0, 5, 'L', 'e', 'v', 'e', 'l')
+	}
+	b = append(b, encodeTestChunk(x, z)[3:]...)
+	b = b[:len(b)-1]
+	b = append(b, 7, 0, 1, 'p')
+	b = binary.BigEndian.AppendUint32(b, uint32(padding)) // #nosec G115 -- test data
+	b = append(b, make([]byte, padding)...)
+	if level {
+		b = append(b, 0)
+	}
+	return append(b, 0)
+}
+
+func TestScan(t *testing.T) {
+	garbage := bytes.Repeat([]byte{0xAB}, SectorSize)
+	undecodable := slices.Concat([]byte{0, 0, 0, 10, compressionZlib}, make([]byte, SectorSize-chunkHeaderSize))
+	// An uncompressed chunk padded with 5000 bytes spans two sectors, the second holding a plausible chunk header.
+	large := paddedTestChunk(3, 4, 5000, false)
+	copy(large[SectorSize-chunkHeaderSize:], chunkSectors(encodeTestChunk(7, 8), compressionNone)[:100])
+
+	file := slices.Concat(
+		bytes.Repeat([]byte{0xFF}, headerSectors*SectorSize),
+		chunkSectors(paddedTestChunk(33, 2, 10, false), compressionZlib), // sector 2
+		garbage,     // sector 3
+		undecodable, // sector 4
+		chunkSectors(paddedTestChunk(-1, -2, 10, true), compressionNone), // sector 5
+		chunkSectors(large, compressionNone),                             // sectors 6 and 7
+		chunkSectors([]byte{10, 0, 0, 0}, compressionNone),               // sector 8, no coordinates
+		chunkSectors(encodeTestChunk(5, 6), compressionZlib)[:20],        // sector 9, truncated
+	)
+
+	got, err := Scan(bytes.NewReader(file), int64(len(file)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []RecoveredChunk{
+		{Chunk{ChunkHeader: ChunkHeader{X: 1, Z: 2, Sector: 2, Sectors: 1}, Compression: nbt.CompressionZlib}, 33, 2},
+		{Chunk{ChunkHeader: ChunkHeader{X: 31, Z: 30, Sector: 5, Sectors: 1}, Compression: nbt.CompressionNone}, -1, -2},
+		{Chunk{ChunkHeader: ChunkHeader{X: 3, Z: 4, Sector: 6, Sectors: 2}, Compression: nbt.CompressionNone}, 3, 4},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v chunks, want %v", len(got), len(want))
+	}
+	for i := range want {
+		gotChunk := got[i]
+		gotChunk.Tag = nbt.Tag{}
+		if gotChunk.ChunkHeader != want[i].ChunkHeader || gotChunk.ChunkX != want[i].ChunkX ||
+			gotChunk.ChunkZ != want[i].ChunkZ || gotChunk.Compression != want[i].Compression {
+			t.Errorf("got %+v, want %+v", gotChunk, want[i])
+		}
+	}
+
+	t.Run("Test success case: empty region", func(t *testing.T) {
+		got, err := Scan(bytes.NewReader(nil), 0)
+		if err != nil || len(got) != 0 {
+			t.Errorf("got %v, %v, want no chunks", got, err)
+		}
+	})
+}
+
+func TestRebuild(t *testing.T) {
+	// recovered returns a recovered chunk at chunk coordinates x and z last updated at the tick.
+	recovered := func(x, z int32, tick int64) RecoveredChunk {
+		chunk, err := nbt.NewCompound("", nbt.NewInt("xPos", x), nbt.NewInt("zPos", z),
+			nbt.NewLong("LastUpdate", tick))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return RecoveredChunk{Chunk: Chunk{Tag: chunk, Compression: nbt.CompressionZlib}, ChunkX: int(x),
+			ChunkZ: int(z)}
+	}
+
+	f, _ := createTestRegion(t)
+	_, err := Rebuild(f, []RecoveredChunk{recovered(1, 2, 5), recovered(33, 2, 9), recovered(1, 2, 7),
+		recovered(-1, -1, 0)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	region := reopen(t, f)
+
+	successCases := []struct {
+		name       string
+		x, z       int
+		wantX      int32
+		wantUpdate int64
+	}{
+		{"newest duplicate kept", 1, 2, 33, 9},
+		{"chunk at negative coordinates", 31, 31, -1, 0},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			chunk, ok, err := region.ReadChunk(successCase.x, successCase.z)
+			if err != nil || !ok {
+				t.Fatalf("got %v, %v, want the chunk", ok, err)
+			}
+			x, _ := chunkPosition(chunk.Tag)
+			if x != successCase.wantX || lastUpdate(chunk.Tag) != successCase.wantUpdate ||
+				chunk.Compression != nbt.CompressionZlib {
+				t.Errorf("got xPos %v, LastUpdate %v and %v, want %v, %v and %v", x, lastUpdate(chunk.Tag),
+					chunk.Compression, successCase.wantX, successCase.wantUpdate, nbt.CompressionZlib)
+			}
+		})
+	}
+
+	t.Run("Test success case: only recovered chunks present", func(t *testing.T) {
+		count := 0
+		for range region.Headers() {
+			count++
+		}
+		if count != 2 {
+			t.Errorf("got %v chunks, want 2", count)
+		}
+	})
+}
@@ -0,0 +1,284 @@
+// Package region reads and writes Anvil region files (.mca), which hold the chunks of a 32x32 chunk region of a Java
+// edition world, each compressed and stored at a 4 KiB sector aligned offset given by the file's header.
+package region
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"iter"
+	"time"
+
+	"PudFish/nbt"
+	"PudFish/nbt/coords"
+)
+
+// Layout of a region file: a header of chunk locations and timestamps, then chunks aligned to sectors. Each chunk
+// starts with its length in bytes (counting the compression byte) and a compression byte.
+const (
+	// SectorSize is the size in bytes of a region file sector.
+	SectorSize = 4096
+	// headerSectors is the number of sectors of the location and timestamp tables.
+	headerSectors = 2
+	// chunkHeaderSize is the size of the length and compression byte before each chunk.
+	chunkHeaderSize = 5
+	// chunks is the number of chunks in a region.
+	chunks = coords.RegionSize * coords.RegionSize
+)
+
+// Compression byte values of region file chunks.
+const (
+	compressionGzip = 1
+	compressionZlib = 2
+	compressionNone = 3
+	compressionLZ4  = 4
+	// compressionExternal is set on the compression byte of a chunk stored in an external .mcc file.
+	compressionExternal = 0x80
+)
+
+// Region is an open region file. It reads only the header when opened, chunks are read on demand.
+type Region struct {
+	r          io.ReaderAt
+	size       int64
+	locations  [chunks]uint32
+	timestamps [chunks]uint32
+	// external locates the external .mcc files of oversized chunks, nil until set with SetExternalDir.
+	external *external
+}
+
+// ChunkHeader describes a chunk present in a region file, from the file's header.
+type ChunkHeader struct {
+	// X and Z are the chunk coordinates within the region, from 0 to 31.
+	X, Z int
+	// Sector is the first sector of the chunk, and Sectors the number of sectors allocated to it.
+	Sector, Sectors int
+	// Timestamp is when the chunk was last saved.
+	Timestamp time.Time
+}
+
+// Chunk is a chunk read from a region file.
+type Chunk struct {
+	ChunkHeader
+	Tag nbt.Tag
+	// Compression is the compression the chunk is stored with, so a rewritten chunk can keep it.
+	Compression nbt.Compression
+}
+
+// Open reads the header of the region file of size bytes, checking every chunk location is allocated sectors lying
+// within the file. The bytes missing from a file shorter than the header, such as one left empty by the game, are read
+// as zero, so it holds no chunks. Chunks can be written to the Region if r is a File.
+func Open(r io.ReaderAt, size int64) (region *Region, err error) {
+	header := make([]byte, headerSectors*SectorSize)
+	if _, err = r.ReadAt(header, 0); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("Unable to read region header: %w", err)
+	}
+
+	region = &Region{r: r, size: max(size, headerSectors*SectorSize)}
+	for i := range chunks {
+		region.locations[i] = binary.BigEndian.Uint32(header[i*4:])
+		region.timestamps[i] = binary.BigEndian.Uint32(header[SectorSize+i*4:])
+
+		h, ok := region.header(i)
+		if ok && h.Sectors == 0 {
+			return nil, fmt.Errorf("Unable to read region header: chunk %v,%v at sector %v is allocated no sectors",
+				h.X, h.Z, h.Sector)
+		}
+		if ok && (h.Sector < headerSectors || int64(h.Sector+h.Sectors)*SectorSize > size) {
+			return nil, fmt.Errorf("Unable to read region header: chunk %v,%v at sectors %v to %v is outside the "+
+				"header and the %v byte file", h.X, h.Z, h.Sector, h.Sector+h.Sectors, size)
+		}
+	}
+	return region, nil
+}
+
+// header returns the header of the chunk at the index. The boolean is false if the chunk is not present.
+func (region *Region) header(i int) (h ChunkHeader, ok bool) {
+	location := region.locations[i]
+	if location == 0 {
+		return ChunkHeader{}, false
+	}
+	return ChunkHeader{
+		X:         i % coords.RegionSize,
+		Z:         i / coords.RegionSize,
+		Sector:    int(location >> 8),
+		Sectors:   int(location & 0xFF),
+		Timestamp: time.Unix(int64(region.timestamps[i]), 0),
+	}, true
+}
+
+// Header returns the header of the chunk at chunk coordinates x and z, which may be world coordinates as only their
+// offset within the region is used. The boolean is false if the chunk is not present.
+func (region *Region) Header(x, z int) (ChunkHeader, bool) {
+	return region.header(coords.RegionIndex(x, z))
+}
+
+// Headers returns an iterator over the headers of the chunks present, in header order, without reading any chunk.
+func (region *Region) Headers() iter.Seq[ChunkHeader] {
+	return func(yield func(ChunkHeader) bool) {
+		for i := range chunks {
+			if h, ok := region.header(i); ok && !yield(h) {
+				return
+			}
+		}
+	}
+}
+
+// Chunk reads and decodes the chunk at chunk coordinates x and z, which may be world coordinates as only their offset
+// within the region is used. The boolean is false if the chunk is not present. The options configure decoding.
+func (region *Region) Chunk(x, z int, opts ...nbt.Option) (t nbt.Tag, ok bool, err error) {
+	h, ok := region.Header(x, z)
+	if !ok {
+		return nbt.Tag{}, false, nil
+	}
+	t, _, err = region.read(h, nil, opts)
+	if err != nil {
+		return nbt.Tag{}, true, err
+	}
+	return t, true, nil
+}
+
+// ReadChunk reads and decodes the chunk at chunk coordinates x and z as Chunk does, returning it with its header and
+// compression so it can be written back, or to another region, as it was stored. The boolean is false if the chunk is
+// not present.
+func (region *Region) ReadChunk(x, z int, opts ...nbt.Option) (chunk Chunk, ok bool, err error) {
+	h, ok := region.Header(x, z)
+	if !ok {
+		return Chunk{}, false, nil
+	}
+	t, compression, err := region.read(h, nil, opts)
+	if err != nil {
+		return Chunk{}, true, err
+	}
+	return Chunk{ChunkHeader: h, Tag: t, Compression: compression}, true, nil
+}
+
+// Chunks returns an iterator over the chunks present, in header order, reading and decoding one chunk at a time into
+// a reused buffer, so a whole region is scanned in constant memory. A chunk that fails to read or decode is yielded
+// with its error, and iteration continues with the next chunk. The options configure decoding.
+func (region *Region) Chunks(opts ...nbt.Option) iter.Seq2[Chunk, error] {
+	return func(yield func(Chunk, error) bool) {
+		var buffer []byte
+		for h := range region.Headers() {
+			t, compression, err := region.read(h, &buffer, opts)
+			if !yield(Chunk{ChunkHeader: h, Tag: t, Compression: compression}, err) {
+				return
+			}
+		}
+	}
+}
+
+// read reads and decodes the chunk with the header, returning the compression it is stored with. The buffer, if not
+// nil, is reused for the compressed bytes and grown as needed.
+func (region *Region) read(h ChunkHeader, buffer *[]byte, opts []nbt.Option) (t nbt.Tag, compression nbt.Compression,
+	err error) {
+	if buffer == nil {
+		buffer = new([]byte)
+	}
+	allocated := h.Sectors * SectorSize
+	if cap(*buffer) < allocated {
+		*buffer = make([]byte, allocated)
+	}
+	data := (*buffer)[:allocated]
+
+	if _, err = region.r.ReadAt(data, int64(h.Sector)*SectorSize); err != nil && err != io.EOF {
+		return nbt.Tag{}, 0, fmt.Errorf("Unable to read chunk %v,%v: %w", h.X, h.Z, err)
+	}
+
+	length := int(binary.BigEndian.Uint32(data))
+	if length < 1 || chunkHeaderSize-1+length > allocated {
+		return nbt.Tag{}, 0, fmt.Errorf("Unable to read chunk %v,%v: length %v does not fit its %v sectors", h.X, h.Z,
+			length, h.Sectors)
+	}
+
+	compression, err = chunkCompression(data[4] &^ compressionExternal)
+	if err != nil {
+		return nbt.Tag{}, 0, fmt.Errorf("Unable to read chunk %v,%v: %w", h.X, h.Z, err)
+	}
+	payload := data[chunkHeaderSize : chunkHeaderSize-1+length]
+	if data[4]&compressionExternal != 0 {
+		payload, err = region.readExternal(h)
+		if err != nil {
+			return nbt.Tag{}, 0, fmt.Errorf("Unable to read chunk %v,%v: %w", h.X, h.Z, err)
+		}
+	}
+
+	opts = append([]nbt.Option{nbt.WithCompression(compression)}, opts...)
+	t, err = nbt.ReadTag(bytes.NewReader(payload), opts...)
+	if err != nil {
+		return nbt.Tag{}, 0, fmt.Errorf("Unable to read chunk %v,%v: %w", h.X, h.Z, err)
+	}
+	return t, compression, nil
+}
+
+// chunkCompression returns the compression of a chunk's compression byte, without the external flag.
+func chunkCompression(b byte) (compression nbt.Compression, err error) {
+	switch b {
+	case compressionGzip:
+		return nbt.CompressionGzip, nil
+	case compressionZlib:
+		return nbt.CompressionZlib, nil
+	case compressionNone:
+		return nbt.CompressionNone, nil
+	case compressionLZ4:
+		return nbt.CompressionLZ4, nil
+	default:
+		return 0, fmt.Errorf("unknown compression %v", b)
+	}
+}
+
+// FindBlocks returns an iterator over the world positions of the blocks of every chunk in the region whose state
+// matches, reading one chunk at a time, see nbt.FindBlocks. A chunk or section that fails to read is yielded with its
+// error, and the search continues. The options configure decoding.
+func (region *Region) FindBlocks(match func(nbt.BlockState) bool, opts ...nbt.Option) iter.Seq2[nbt.BlockPos, error] {
+	return func(yield func(nbt.BlockPos, error) bool) {
+		for chunk, err := range region.Chunks(opts...) {
+			if err != nil {
+				if !yield(nbt.BlockPos{}, err) {
+					return
+				}
+				continue
+			}
+			for pos, err := range nbt.FindBlocks(chunk.Tag, match) {
+				if !yield(pos, err) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// EntityMatch is an entity found by Region.FindEntities, with the header of the chunk holding it and its index in the
+// chunk's Entities list.
+type EntityMatch struct {
+	ChunkHeader
+	Index  int
+	Entity nbt.Tag
+}
+
+// FindEntities returns an iterator over the entities of every chunk in the region that match, reading one chunk at a
+// time. The region is an entity region file since Java edition 1.17, or a region file before, see nbt.Entities.
+// Matches can be removed or modified with nbt.EditEntities. A chunk that fails to read is yielded with its error, and
+// the search continues. The options configure decoding.
+func (region *Region) FindEntities(match func(entity nbt.Tag) bool, opts ...nbt.Option) iter.Seq2[EntityMatch, error] {
+	return func(yield func(EntityMatch, error) bool) {
+		for chunk, err := range region.Chunks(opts...) {
+			var entities []nbt.Tag
+			if err == nil {
+				entities, err = nbt.Entities(chunk.Tag)
+			}
+			if err != nil {
+				if !yield(EntityMatch{ChunkHeader: chunk.ChunkHeader}, err) {
+					return
+				}
+				continue
+			}
+
+			for i, entity := range entities {
+				if match(entity) && !yield(EntityMatch{ChunkHeader: chunk.ChunkHeader, Index: i, Entity: entity}, nil) {
+					return
+				}
+			}
+		}
+	}
+}
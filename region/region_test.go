@@ -0,0 +1,269 @@
+package region
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"slices"
+	"testing"
+	"time"
+
+	"PudFish/nbt"
+)
+
+// encodeTestChunk returns an uncompressed chunk compound holding xPos and zPos.
+func encodeTestChunk(x, z int32) []byte {
+	b := []byte{10, 0, 0}
+	b = append(b, 3, 0, 4, 'x', 'P', 'o', 's')
+	b = binary.BigEndian.AppendUint32(b, uint32(x)) // #nosec G115 -- test data
+	b = append(b, 3, 0, 4, 'z', 'P', 'o', 's')
+	b = binary.BigEndian.AppendUint32(b, uint32(z)) // #nosec G115 -- test data
+	return append(b, 0)
+}
+
+// testChunk is a chunk placed in a test region file.
+type testChunk struct {
+	x, z        int
+	data        []byte
+	compression byte
+	timestamp   uint32
+}
+
+// buildRegion returns a region file holding the chunks, each at the next free sector.
+func buildRegion(chunks ...testChunk) []byte {
+	file := make([]byte, headerSectors*SectorSize)
+	for _, chunk := range chunks {
+		payload := chunk.data
+		if chunk.compression == compressionZlib {
+			var compressed bytes.Buffer
+			w := zlib.NewWriter(&compressed)
+			_, _ = w.Write(chunk.data)
+			_ = w.Close()
+			payload = compressed.Bytes()
+		}
+
+		b := binary.BigEndian.AppendUint32(nil, uint32(len(payload)+1)) // #nosec G115 -- test data
+		b = append(append(b, chunk.compression), payload...)
+		sectors := (len(b) + SectorSize - 1) / SectorSize
+		b = append(b, make([]byte, sectors*SectorSize-len(b))...)
+
+		i := chunk.x + chunk.z*32
+		location := uint32(len(file)/SectorSize<<8 | sectors) // #nosec G115 -- test data
+		binary.BigEndian.PutUint32(file[i*4:], location)
+		binary.BigEndian.PutUint32(file[SectorSize+i*4:], chunk.timestamp)
+		file = append(file, b...)
+	}
+	return file
+}
+
+// chunkPosition returns the xPos and zPos of a decoded chunk.
+func chunkPosition(t nbt.Tag) (x, z any) {
+	v := nbt.NewView(t)
+	xPos, _ := v.Child("xPos")
+	zPos, _ := v.Child("zPos")
+	return xPos.Payload(), zPos.Payload()
+}
+
+func TestOpen(t *testing.T) {
+	valid := buildRegion(testChunk{x: 1, z: 2, data: encodeTestChunk(1, 2), compression: compressionNone})
+	outside := bytes.Clone(valid)
+	binary.BigEndian.PutUint32(outside, 9<<8|1)
+	noSectors := bytes.Clone(valid)
+	binary.BigEndian.PutUint32(noSectors[(1+2*32)*4:], 2<<8)
+
+	tests := []struct {
+		name    string
+		input   []byte
+		wantErr bool
+	}{
+		{name: "Test success case: empty region", input: make([]byte, headerSectors*SectorSize)},
+		{name: "Test success case: one chunk", input: valid},
+		{name: "Test success case: empty file", input: nil},
+		{name: "Test success case: truncated header", input: make([]byte, SectorSize)},
+		{name: "Test failure case: chunk outside file", input: outside, wantErr: true},
+		{name: "Test failure case: chunk allocated no sectors", input: noSectors, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Open(bytes.NewReader(tt.input), int64(len(tt.input)))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegionChunk(t *testing.T) {
+	file := buildRegion(
+		testChunk{x: 0, z: 0, data: encodeTestChunk(32, -32), compression: compressionZlib, timestamp: 1700000000},
+		testChunk{x: 31, z: 1, data: encodeTestChunk(63, -31), compression: compressionNone},
+		testChunk{x: 2, z: 2, data: []byte{1, 2, 3}, compression: compressionNone},
+		testChunk{x: 3, z: 3, data: encodeTestChunk(3, 3), compression: compressionExternal | compressionZlib},
+	)
+	region, err := Open(bytes.NewReader(file), int64(len(file)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		x, z    int
+		wantX   any
+		wantOK  bool
+		wantErr bool
+	}{
+		{name: "Test success case: zlib", x: 32, z: -32, wantX: int32(32), wantOK: true},
+		{name: "Test success case: uncompressed", x: 63, z: -31, wantX: int32(63), wantOK: true},
+		{name: "Test success case: absent", x: 5, z: 5},
+		{name: "Test failure case: undecodable", x: 2, z: 2, wantOK: true, wantErr: true},
+		{name: "Test failure case: external", x: 3, z: 3, wantOK: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := region.Chunk(tt.x, tt.z)
+			if ok != tt.wantOK || (err != nil) != tt.wantErr {
+				t.Fatalf("got %v %v, want %v and error %v", ok, err, tt.wantOK, tt.wantErr)
+			}
+			if x, _ := chunkPosition(got); tt.wantX != nil && x != tt.wantX {
+				t.Errorf("got xPos %v, want %v", x, tt.wantX)
+			}
+		})
+	}
+
+	if h, ok := region.Header(0, 0); !ok || h.Sector != 2 || h.Sectors != 1 || !h.Timestamp.Equal(time.Unix(1700000000,
+		0)) {
+		t.Errorf("got header %+v, want sector 2 saved at 1700000000", h)
+	}
+
+	if chunk, ok, err := region.ReadChunk(63, -31); err != nil || !ok || chunk.X != 31 || chunk.Z != 1 ||
+		chunk.Compression != nbt.CompressionNone {
+		t.Errorf("got %+v %v %v, want chunk 31,1 uncompressed", chunk.ChunkHeader, ok, err)
+	}
+}
+
+func TestRegionChunks(t *testing.T) {
+	file := buildRegion(
+		testChunk{x: 4, z: 0, data: encodeTestChunk(4, 0), compression: compressionZlib},
+		testChunk{x: 0, z: 1, data: []byte{0xFF}, compression: compressionNone},
+		testChunk{x: 1, z: 1, data: encodeTestChunk(1, 1), compression: compressionNone},
+	)
+	region, err := Open(bytes.NewReader(file), int64(len(file)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var positions []ChunkHeader
+	var compressions []nbt.Compression
+	var errs int
+	for chunk, err := range region.Chunks() {
+		if err != nil {
+			errs++
+			continue
+		}
+		x, z := chunkPosition(chunk.Tag)
+		if x != int32(chunk.X) || z != int32(chunk.Z) {
+			t.Errorf("got chunk %v,%v at %v,%v", x, z, chunk.X, chunk.Z)
+		}
+		positions = append(positions, chunk.ChunkHeader)
+		compressions = append(compressions, chunk.Compression)
+	}
+	if len(positions) != 2 || errs != 1 || positions[0].X != 4 || positions[1].Z != 1 {
+		t.Errorf("got %+v and %v errors, want chunks 4,0 and 1,1 and one error", positions, errs)
+	}
+	if want := []nbt.Compression{nbt.CompressionZlib, nbt.CompressionNone}; !slices.Equal(compressions, want) {
+		t.Errorf("got compressions %v, want %v", compressions, want)
+	}
+
+	var headers int
+	for range region.Headers() {
+		headers++
+		break
+	}
+	if headers != 1 {
+		t.Errorf("got %v headers after break, want 1", headers)
+	}
+	for range region.Chunks() {
+		break
+	}
+}
+
+func TestRegionFindBlocks(t *testing.T) {
+	// A 1.17 layout chunk with one section of stone and one block of diamond ore at the section origin.
+	section := []byte{10, 0, 0, 1, 0, 1, 'Y', 1}
+	section = append(section, 9, 0, 7, 'P', 'a', 'l', 'e', 't', 't', 'e', 10, 0, 0, 0, 2)
+	for _, name := range []string{"minecraft:stone", "minecraft:diamond_ore"} {
+		section = append(section, 8, 0, 4, 'N', 'a', 'm', 'e', 0, byte(len(name)))
+		section = append(append(section, name...), 0)
+	}
+	section = append(section, 12, 0, 11, 'B', 'l', 'o', 'c', 'k', 'S', 't', 'a', 't', 'e', 's', 0, 0, 1, 0)
+	// The first long holds index 1 for the first block, the final zero byte ends the section compound.
+	section = append(append(section, 0, 0, 0, 0, 0, 0, 0, 1), make([]byte, 255*8+1)...)
+
+	chunk := []byte{10, 0, 0, 10, 0, 5, 'L', 'e', 'v', 'e', 'l'}
+	chunk = append(chunk, 3, 0, 4, 'x', 'P', 'o', 's', 0, 0, 0, 1, 3, 0, 4, 'z', 'P', 'o', 's', 0, 0, 0, 2)
+	chunk = append(chunk, 9, 0, 8, 'S', 'e', 'c', 't', 'i', 'o', 'n', 's', 10, 0, 0, 0, 1)
+	chunk = append(append(chunk, section[3:]...), 0, 0)
+
+	file := buildRegion(
+		testChunk{x: 1, z: 2, data: chunk, compression: compressionZlib},
+		testChunk{x: 3, z: 3, data: []byte{0xFF}, compression: compressionNone},
+	)
+	region, err := Open(bytes.NewReader(file), int64(len(file)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var found []nbt.BlockPos
+	errs := 0
+	for pos, err := range region.FindBlocks(func(state nbt.BlockState) bool {
+		return state.Name == "minecraft:diamond_ore"
+	}) {
+		if err != nil {
+			errs++
+			continue
+		}
+		found = append(found, pos)
+	}
+	if want := (nbt.BlockPos{X: 16, Y: 16, Z: 32}); len(found) != 1 || found[0] != want || errs != 1 {
+		t.Errorf("got %+v and %v errors, want %+v and one error", found, errs, want)
+	}
+}
+
+func TestRegionFindEntities(t *testing.T) {
+	entity := func(id string) []byte {
+		b := append([]byte{8, 0, 2, 'i', 'd', 0, byte(len(id))}, id...)
+		return append(b, 0)
+	}
+	chunk := []byte{10, 0, 0, 9, 0, 8, 'E', 'n', 't', 'i', 't', 'i', 'e', 's', 10, 0, 0, 0, 3}
+	for _, id := range []string{"minecraft:cow", "minecraft:zombie", "minecraft:zombie"} {
+		chunk = append(chunk, entity(id)...)
+	}
+	chunk = append(chunk, 0)
+
+	file := buildRegion(
+		testChunk{x: 0, z: 0, data: []byte{0xFF}, compression: compressionNone},
+		testChunk{x: 6, z: 7, data: chunk, compression: compressionZlib},
+	)
+	region, err := Open(bytes.NewReader(file), int64(len(file)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var found []int
+	errs := 0
+	for match, err := range region.FindEntities(nbt.MatchEntityID("minecraft:zombie")) {
+		if err != nil {
+			errs++
+			continue
+		}
+		if match.X != 6 || match.Z != 7 {
+			t.Errorf("got chunk %v,%v, want 6,7", match.X, match.Z)
+		}
+		found = append(found, match.Index)
+	}
+	if len(found) != 2 || found[0] != 1 || found[1] != 2 || errs != 1 {
+		t.Errorf("got indices %v and %v errors, want 1 and 2 and one error", found, errs)
+	}
+}
@@ -0,0 +1,80 @@
+package region
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// SectorMap describes how the sectors of a region file are used, to decide when compacting the file is worthwhile and
+// to diagnose chunks whose sectors overlap.
+type SectorMap struct {
+	// Sectors is the number of sectors in the file, including the header and a partial last sector.
+	Sectors int
+	// Chunks are the headers of the chunks present, in sector order.
+	Chunks []ChunkHeader
+	// Free are the runs of sectors after the header used by no chunk, in sector order.
+	Free []SectorRange
+	// Overlaps are the pairs of chunks allocated one or more of the same sectors, a sign of corruption.
+	Overlaps []Overlap
+	// Slack is the number of bytes after the header holding no chunk data: the free sectors, and the padding of each
+	// chunk's sectors after its data.
+	Slack int64
+}
+
+// SectorRange is a run of Length sectors starting at sector Start.
+type SectorRange struct {
+	Start, Length int
+}
+
+// Overlap is a pair of chunks allocated one or more of the same sectors, where First starts at or before Second.
+type Overlap struct {
+	First, Second ChunkHeader
+}
+
+// SectorMap reads the length of every chunk present and returns the sector usage of the region file. A chunk whose
+// length does not fit its sectors is counted as filling them.
+func (region *Region) SectorMap() (m SectorMap, err error) {
+	m.Sectors = int((region.size + SectorSize - 1) / SectorSize)
+	m.Chunks = slices.SortedFunc(region.Headers(), func(a, b ChunkHeader) int { return a.Sector - b.Sector })
+
+	used := make([]bool, m.Sectors)
+	length := make([]byte, 4)
+	for i, h := range m.Chunks {
+		for _, other := range m.Chunks[i+1:] {
+			if other.Sector >= h.Sector+h.Sectors {
+				break
+			}
+			m.Overlaps = append(m.Overlaps, Overlap{First: h, Second: other})
+		}
+
+		if _, err = region.r.ReadAt(length, int64(h.Sector)*SectorSize); err != nil && err != io.EOF {
+			return SectorMap{}, fmt.Errorf("Unable to map sectors: unable to read chunk %v,%v length: %w", h.X, h.Z,
+				err)
+		}
+		allocated := int64(h.Sectors) * SectorSize
+		m.Slack += max(allocated-4-int64(binary.BigEndian.Uint32(length)), 0)
+
+		for sector := h.Sector; sector < h.Sector+h.Sectors; sector++ {
+			used[sector] = true
+		}
+	}
+
+	for sector := headerSectors; sector < m.Sectors; sector++ {
+		if used[sector] {
+			continue
+		}
+		if n := len(m.Free); n > 0 && m.Free[n-1].Start+m.Free[n-1].Length == sector {
+			m.Free[n-1].Length++
+		} else {
+			m.Free = append(m.Free, SectorRange{Start: sector, Length: 1})
+		}
+		m.Slack += SectorSize
+	}
+	if m.Sectors > headerSectors && !used[m.Sectors-1] {
+		// The partial last sector only holds the bytes the file has.
+		m.Slack -= int64(m.Sectors)*SectorSize - region.size
+	}
+	return m, nil
+}
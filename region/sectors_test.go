@@ -0,0 +1,62 @@
+package region
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func TestRegionSectorMap(t *testing.T) {
+	file := buildRegion(
+		testChunk{x: 0, z: 0, data: encodeTestChunk(0, 0), compression: compressionNone},
+		testChunk{x: 1, z: 0, data: encodeTestChunk(1, 0), compression: compressionNone},
+		testChunk{x: 2, z: 0, data: encodeTestChunk(2, 0), compression: compressionNone},
+	)
+	// Free the middle chunk's sector 3 and point chunk 3,0 at chunk 0,0's sector 2.
+	binary.BigEndian.PutUint32(file[4:], 0)
+	binary.BigEndian.PutUint32(file[12:], 2<<8|1)
+	file = append(file, make([]byte, 100)...)
+	chunkSlack := int64(SectorSize - 4 - len(encodeTestChunk(0, 0)) - 1)
+
+	region, err := Open(bytes.NewReader(file), int64(len(file)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Run("Test success case: free sectors, overlaps and slack", func(t *testing.T) {
+		got, err := region.SectorMap()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got.Sectors != 6 {
+			t.Errorf("got %v sectors, want 6", got.Sectors)
+		}
+		if len(got.Chunks) != 3 || got.Chunks[2].Sector != 4 {
+			t.Errorf("got chunks %+v, want 3 in sector order", got.Chunks)
+		}
+		if want := []SectorRange{{Start: 3, Length: 1}, {Start: 5, Length: 1}}; !reflect.DeepEqual(got.Free, want) {
+			t.Errorf("got free %+v, want %+v", got.Free, want)
+		}
+		if len(got.Overlaps) != 1 || got.Overlaps[0].First.Sector != 2 || got.Overlaps[0].Second.Sector != 2 {
+			t.Errorf("got overlaps %+v, want the two chunks at sector 2", got.Overlaps)
+		}
+		if want := 3*chunkSlack + SectorSize + 100; got.Slack != want {
+			t.Errorf("got slack %v, want %v", got.Slack, want)
+		}
+	})
+
+	t.Run("Test success case: empty region", func(t *testing.T) {
+		empty, err := Open(bytes.NewReader(make([]byte, headerSectors*SectorSize)), headerSectors*SectorSize)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got, err := empty.SectorMap()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got.Sectors != headerSectors || got.Free != nil || got.Slack != 0 {
+			t.Errorf("got %+v, want only the header", got)
+		}
+	})
+}
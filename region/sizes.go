@@ -0,0 +1,121 @@
+package region
+
+import (
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"PudFish/nbt"
+)
+
+// MaxChunkSize is the most compressed bytes a chunk can hold within a region file, filling the 255 sectors the location
+// table can allocate it. Since Java edition 1.15 a larger chunk is stored in an external .mcc file, and before it the
+// chunk fails to save.
+const MaxChunkSize = maxSectors*SectorSize - chunkHeaderSize
+
+// ChunkSize is the size of a chunk of a region file, stored and decoded.
+type ChunkSize struct {
+	ChunkHeader
+	// Compressed is the length in bytes of the chunk's compressed data, in the region file or its external .mcc file.
+	Compressed int64
+	// Uncompressed is the encoded size in bytes of the chunk's tag, zero if the chunk failed to decode.
+	Uncompressed int64
+	// External reports that the chunk is stored in an external .mcc file.
+	External bool
+	// Waste is the number of bytes of the chunk's sectors after its data.
+	Waste int64
+}
+
+// Ratio returns the compression ratio of the chunk, its uncompressed size over its compressed size.
+func (s ChunkSize) Ratio() float64 {
+	if s.Compressed == 0 {
+		return 0
+	}
+	return float64(s.Uncompressed) / float64(s.Compressed)
+}
+
+// SizeReport is the size of every chunk of a region file, to find the chunks nearing MaxChunkSize before the game fails
+// to save them, and the chunks bloating a region.
+type SizeReport struct {
+	// Chunks are the sizes of the chunks present, in header order.
+	Chunks []ChunkSize
+	// Compressed, Uncompressed and Waste are the totals of the chunks' sizes. The free sectors of the file are not
+	// waste of any chunk, see SectorMap.
+	Compressed, Uncompressed, Waste int64
+	// Histogram is the number of chunks allocated each number of sectors.
+	Histogram map[int]int
+}
+
+// Ratio returns the compression ratio of the region, the total uncompressed size over the total compressed size.
+func (r SizeReport) Ratio() float64 {
+	if r.Compressed == 0 {
+		return 0
+	}
+	return float64(r.Uncompressed) / float64(r.Compressed)
+}
+
+// NearLimit returns the chunks whose compressed size is at least the fraction of MaxChunkSize, largest first. Chunks
+// already stored in external .mcc files are included, as they are past the limit.
+func (r SizeReport) NearLimit(fraction float64) (chunks []ChunkSize) {
+	for _, s := range r.Chunks {
+		if float64(s.Compressed) >= fraction*MaxChunkSize {
+			chunks = append(chunks, s)
+		}
+	}
+	slices.SortStableFunc(chunks, func(a, b ChunkSize) int { return cmp.Compare(b.Compressed, a.Compressed) })
+	return chunks
+}
+
+// SizeReport reads and decodes every chunk present, one at a time, and returns their sizes. The uncompressed size of a
+// chunk is the encoded size of its decoded tag. A chunk that fails to decode is reported with no uncompressed size, and
+// the returned error joins the errors of every such chunk. The options configure decoding.
+func (region *Region) SizeReport(opts ...nbt.Option) (r SizeReport, err error) {
+	r.Histogram = map[int]int{}
+	prefix := make([]byte, chunkHeaderSize)
+	var errs []error
+	for chunk, err := range region.Chunks(opts...) {
+		h := chunk.ChunkHeader
+		s := ChunkSize{ChunkHeader: h}
+		if _, readErr := region.r.ReadAt(prefix, int64(h.Sector)*SectorSize); readErr != nil && readErr != io.EOF {
+			return SizeReport{}, fmt.Errorf("Unable to report sizes: unable to read chunk %v,%v length: %w", h.X, h.Z,
+				readErr)
+		}
+		length := int64(binary.BigEndian.Uint32(prefix))
+		s.Compressed = max(length-1, 0)
+		s.Waste = max(int64(h.Sectors)*SectorSize-4-length, 0)
+		s.External = prefix[4]&compressionExternal != 0
+		if s.External {
+			s.Compressed = region.externalSize(h)
+		}
+
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			s.Uncompressed = nbt.Analyze(chunk.Tag, 0, 0).Bytes[""]
+		}
+
+		r.Chunks = append(r.Chunks, s)
+		r.Compressed += s.Compressed
+		r.Uncompressed += s.Uncompressed
+		r.Waste += s.Waste
+		r.Histogram[h.Sectors]++
+	}
+	return r, errors.Join(errs...)
+}
+
+// externalSize returns the size of the external .mcc file of the chunk with the header, or zero if it cannot be found.
+func (region *Region) externalSize(h ChunkHeader) int64 {
+	path, err := region.externalPath(h.X, h.Z)
+	if err != nil {
+		return 0
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
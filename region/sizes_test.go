@@ -0,0 +1,82 @@
+package region
+
+import (
+	"bytes"
+	"testing"
+
+	"PudFish/nbt"
+)
+
+func TestRegionSizeReport(t *testing.T) {
+	chunk := encodeTestChunk(0, 0)
+	file := buildRegion(
+		testChunk{x: 0, z: 0, data: chunk, compression: compressionNone},
+		testChunk{x: 1, z: 0, data: chunk, compression: compressionZlib},
+		testChunk{x: 2, z: 0, data: chunk, compression: 9},
+	)
+	region, err := Open(bytes.NewReader(file), int64(len(file)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got, err := region.SizeReport()
+
+	t.Run("Test failure case: undecodable chunk reported", func(t *testing.T) {
+		if err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+		if len(got.Chunks) != 3 || got.Chunks[2].Compressed != int64(len(chunk)) || got.Chunks[2].Uncompressed != 0 {
+			t.Errorf("got %+v, want the compressed size only", got.Chunks)
+		}
+	})
+
+	t.Run("Test success case: chunk sizes", func(t *testing.T) {
+		uncompressed := got.Chunks[0]
+		if uncompressed.Compressed != int64(len(chunk)) || uncompressed.Uncompressed != int64(len(chunk)) ||
+			uncompressed.Ratio() != 1 {
+			t.Errorf("got %+v, want %v bytes stored and decoded", uncompressed, len(chunk))
+		}
+		if want := int64(SectorSize - chunkHeaderSize - len(chunk)); uncompressed.Waste != want {
+			t.Errorf("got waste %v, want %v", uncompressed.Waste, want)
+		}
+		zlibbed := got.Chunks[1]
+		if zlibbed.Uncompressed != int64(len(chunk)) || zlibbed.Compressed == zlibbed.Uncompressed {
+			t.Errorf("got %+v, want the compressed and decoded sizes", zlibbed)
+		}
+	})
+
+	t.Run("Test success case: totals and histogram", func(t *testing.T) {
+		var compressed, waste int64
+		for _, s := range got.Chunks {
+			compressed, waste = compressed+s.Compressed, waste+s.Waste
+		}
+		if got.Compressed != compressed || got.Uncompressed != 2*int64(len(chunk)) || got.Waste != waste {
+			t.Errorf("got %+v, want the totals of the chunks", got)
+		}
+		if got.Histogram[1] != 3 || len(got.Histogram) != 1 {
+			t.Errorf("got histogram %v, want 3 chunks of 1 sector", got.Histogram)
+		}
+		if near := got.NearLimit(0.5); near != nil {
+			t.Errorf("got %+v, want no chunks near the limit", near)
+		}
+	})
+
+	t.Run("Test success case: external chunk near the limit", func(t *testing.T) {
+		_, written := createTestRegion(t)
+		huge, _ := nbt.NewCompound("", nbt.NewByteArray("data", make([]byte, maxSectors*SectorSize)))
+		written.SetExternalDir(t.TempDir(), 0, 0)
+		for _, c := range []Chunk{{ChunkHeader: ChunkHeader{X: 1}, Tag: huge}, {Tag: decodeTestChunk(t, 0, 0)}} {
+			if err := written.WriteChunk(c); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		report, err := written.SizeReport()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		near := report.NearLimit(0.9)
+		if len(near) != 1 || !near[0].External || near[0].X != 1 || near[0].Compressed <= MaxChunkSize {
+			t.Errorf("got %+v, want the external chunk", near)
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package region
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"PudFish/nbt"
+	"PudFish/nbt/coords"
+)
+
+// File is a region file open for reading and writing, such as an *os.File opened with os.O_RDWR.
+type File interface {
+	io.ReaderAt
+	io.WriterAt
+}
+
+// maxSectors is the most sectors a chunk can be allocated, as the location table holds the count in one byte, and
+// maxSector the first sector past those the location table can address.
+const (
+	maxSectors = 0xFF
+	maxSector  = 1 << 24
+)
+
+// Create writes an empty header to the start of the file and returns it as a Region holding no chunks, to which chunks
+// are written with WriteChunk.
+func Create(f File) (region *Region, err error) {
+	if _, err = f.WriteAt(make([]byte, headerSectors*SectorSize), 0); err != nil {
+		return nil, fmt.Errorf("Unable to create region: %w", err)
+	}
+	return &Region{r: f, size: headerSectors * SectorSize}, nil
+}
+
+// WriteChunk encodes the chunk's tag with its compression and writes it at the chunk coordinates X and Z, which may be
+// world coordinates as only their offset within the region is used. The region must have been opened from a File. A
+// zero Timestamp is replaced by the current time, and the Sector and Sectors of the chunk are ignored. The options
+// configure encoding. A chunk needing more than 255 sectors is written to an external .mcc file, see SetExternalDir,
+// and any external file of a chunk that now fits the region file is removed.
+//
+// The chunk is written to free sectors, first fit, or appended to the file, padded to a whole number of sectors, and
+// only then is the header updated to point at it. The sectors of any previous copy of the chunk are freed only by that
+// update, so a write that fails part way leaves the previous copy readable.
+func (region *Region) WriteChunk(chunk Chunk, opts ...nbt.Option) (err error) {
+	f, ok := region.r.(File)
+	if !ok {
+		return fmt.Errorf("Unable to write chunk %v,%v: region is not open for writing", chunk.X, chunk.Z)
+	}
+
+	var compression byte
+	switch chunk.Compression {
+	case nbt.CompressionGzip:
+		compression = compressionGzip
+	case nbt.CompressionZlib:
+		compression = compressionZlib
+	case nbt.CompressionNone:
+		compression = compressionNone
+	case nbt.CompressionLZ4:
+		compression = compressionLZ4
+	default:
+		return fmt.Errorf("Unable to write chunk %v,%v: unsupported compression %v", chunk.X, chunk.Z,
+			chunk.Compression)
+	}
+
+	var data bytes.Buffer
+	data.Write(make([]byte, chunkHeaderSize))
+	opts = append(opts, nbt.WithCompression(chunk.Compression))
+	if err = nbt.WriteTag(&data, chunk.Tag, opts...); err != nil {
+		return fmt.Errorf("Unable to write chunk %v,%v: %w", chunk.X, chunk.Z, err)
+	}
+
+	b := data.Bytes()
+	sectors := (len(b) + SectorSize - 1) / SectorSize
+	external := sectors > maxSectors
+	if external {
+		// The compressed data goes to an external .mcc file, and the region file holds only the chunk header.
+		if err = region.writeExternal(chunk.X, chunk.Z, b[chunkHeaderSize:]); err != nil {
+			return fmt.Errorf("Unable to write chunk %v,%v: %v bytes needs %v sectors, more than the %v a chunk can "+
+				"be allocated: %w", chunk.X, chunk.Z, len(b), sectors, maxSectors, err)
+		}
+		b, sectors = b[:chunkHeaderSize], 1
+		compression |= compressionExternal
+	}
+	binary.BigEndian.PutUint32(b, uint32(len(b)-chunkHeaderSize+1)) // #nosec G115 -- bounded by maxSectors
+	b[4] = compression
+	b = append(b, make([]byte, sectors*SectorSize-len(b))...)
+
+	sector := region.allocate(sectors)
+	if sector+sectors > maxSector {
+		return fmt.Errorf("Unable to write chunk %v,%v: sector %v is past the end of the location table", chunk.X,
+			chunk.Z, sector)
+	}
+	if _, err = f.WriteAt(b, int64(sector)*SectorSize); err != nil {
+		return fmt.Errorf("Unable to write chunk %v,%v: %w", chunk.X, chunk.Z, err)
+	}
+	region.size = max(region.size, int64(sector+sectors)*SectorSize)
+
+	timestamp := chunk.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	location := uint32(sector<<8 | sectors) // #nosec G115 -- checked above
+	seconds := uint32(timestamp.Unix())     // #nosec G115 -- timestamps are stored as 32 bit seconds
+	if err = region.writeHeader(f, coords.RegionIndex(chunk.X, chunk.Z), location, seconds); err != nil {
+		return fmt.Errorf("Unable to write chunk %v,%v: %w", chunk.X, chunk.Z, err)
+	}
+
+	if !external {
+		if err = region.removeExternal(chunk.X, chunk.Z); err != nil {
+			return fmt.Errorf("Unable to write chunk %v,%v: %w", chunk.X, chunk.Z, err)
+		}
+	}
+	return nil
+}
+
+// DeleteChunk removes the chunk at chunk coordinates x and z, which may be world coordinates as only their offset
+// within the region is used, by clearing its location and timestamp, and removes any external .mcc file of the chunk.
+// Its sectors are left as they are, to be reused by later writes. The region must have been opened from a File.
+func (region *Region) DeleteChunk(x, z int) (err error) {
+	f, ok := region.r.(File)
+	if !ok {
+		return fmt.Errorf("Unable to delete chunk %v,%v: region is not open for writing", x, z)
+	}
+	if err = region.writeHeader(f, coords.RegionIndex(x, z), 0, 0); err != nil {
+		return fmt.Errorf("Unable to delete chunk %v,%v: %w", x, z, err)
+	}
+	if err = region.removeExternal(x, z); err != nil {
+		return fmt.Errorf("Unable to delete chunk %v,%v: %w", x, z, err)
+	}
+	return nil
+}
+
+// writeHeader writes the location and timestamp of the chunk at the index to the file's header, then to the region.
+func (region *Region) writeHeader(f File, i int, location, timestamp uint32) (err error) {
+	b := binary.BigEndian.AppendUint32(nil, location)
+	if _, err = f.WriteAt(b, int64(i)*4); err != nil {
+		return fmt.Errorf("Unable to write region header: %w", err)
+	}
+	b = binary.BigEndian.AppendUint32(nil, timestamp)
+	if _, err = f.WriteAt(b, SectorSize+int64(i)*4); err != nil {
+		return fmt.Errorf("Unable to write region header: %w", err)
+	}
+
+	region.locations[i] = location
+	region.timestamps[i] = timestamp
+	return nil
+}
+
+// allocate returns the first sector of the first run of free sectors long enough for the count, or of the free
+// sectors at the end of the file, which the chunk extends. Every chunk present, including any previous copy of the
+// chunk being written, holds its sectors.
+func (region *Region) allocate(count int) (sector int) {
+	used := make([]bool, (region.size+SectorSize-1)/SectorSize)
+	for h := range region.Headers() {
+		for s := h.Sector; s < h.Sector+h.Sectors; s++ {
+			used[s] = true
+		}
+	}
+
+	run := 0
+	for s := headerSectors; s < len(used); s++ {
+		if used[s] {
+			run = 0
+			continue
+		}
+		run++
+		if run == count {
+			return s - count + 1
+		}
+	}
+	return len(used) - run
+}
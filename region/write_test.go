@@ -0,0 +1,234 @@
+package region
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"PudFish/nbt"
+)
+
+// createTestRegion creates an empty region file in a temporary directory.
+func createTestRegion(t *testing.T) (f *os.File, region *Region) {
+	t.Helper()
+	f, err := os.Create(filepath.Join(t.TempDir(), "r.0.0.mca"))
+	if err != nil {
+		t.Fatalf("Unable to create test region file: %v", err)
+	}
+	t.Cleanup(func() { _ = f.Close() })
+
+	region, err = Create(f)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return f, region
+}
+
+// decodeTestChunk decodes a chunk compound made by encodeTestChunk.
+func decodeTestChunk(t *testing.T, x, z int32) nbt.Tag {
+	t.Helper()
+	tag, err := nbt.ReadTag(bytes.NewReader(encodeTestChunk(x, z)))
+	if err != nil {
+		t.Fatalf("Unable to decode test chunk: %v", err)
+	}
+	return tag
+}
+
+// reopen opens the region file again, as a reader would.
+func reopen(t *testing.T, f *os.File) *Region {
+	t.Helper()
+	info, err := f.Stat()
+	if err != nil {
+		t.Fatalf("Unable to stat test region file: %v", err)
+	}
+	region, err := Open(f, info.Size())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return region
+}
+
+func TestRegionWriteChunk(t *testing.T) {
+	saved := time.Unix(1700000000, 0)
+
+	t.Run("Test success case: chunks read back", func(t *testing.T) {
+		f, region := createTestRegion(t)
+		compressions := []nbt.Compression{nbt.CompressionZlib, nbt.CompressionGzip, nbt.CompressionNone,
+			nbt.CompressionLZ4}
+		for i, compression := range compressions {
+			chunk := Chunk{ChunkHeader: ChunkHeader{X: 32 + i, Z: -32, Timestamp: saved},
+				Tag: decodeTestChunk(t, int32(32+i), -32), Compression: compression}
+			if err := region.WriteChunk(chunk); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+
+		i := 0
+		for chunk, err := range reopen(t, f).Chunks() {
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if x, z := chunkPosition(chunk.Tag); x != int32(32+i) || z != int32(-32) {
+				t.Errorf("got chunk at %v,%v, want %v,-32", x, z, 32+i)
+			}
+			if chunk.Compression != compressions[i] {
+				t.Errorf("got compression %v, want %v", chunk.Compression, compressions[i])
+			}
+			if chunk.Sector != headerSectors+i || chunk.Sectors != 1 || !chunk.Timestamp.Equal(saved) {
+				t.Errorf("got header %+v, want sector %v saved at %v", chunk.ChunkHeader, headerSectors+i, saved)
+			}
+			i++
+		}
+		if i != len(compressions) {
+			t.Errorf("got %v chunks, want %v", i, len(compressions))
+		}
+
+		if info, _ := f.Stat(); info.Size()%SectorSize != 0 {
+			t.Errorf("got file size %v, want a whole number of sectors", info.Size())
+		}
+	})
+
+	for _, size := range []int64{0, SectorSize} {
+		t.Run(fmt.Sprintf("Test success case: %v byte file", size), func(t *testing.T) {
+			f, _ := createTestRegion(t)
+			if err := f.Truncate(size); err != nil {
+				t.Fatalf("Unable to truncate test region file: %v", err)
+			}
+			region := reopen(t, f)
+			chunk := Chunk{ChunkHeader: ChunkHeader{X: 1, Z: 2}, Tag: decodeTestChunk(t, 1, 2)}
+			if err := region.WriteChunk(chunk); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			chunk, ok, err := reopen(t, f).ReadChunk(1, 2)
+			if err != nil || !ok || chunk.Sector != headerSectors {
+				t.Errorf("got %+v %v %v, want the chunk at sector %v", chunk.ChunkHeader, ok, err, headerSectors)
+			}
+		})
+	}
+
+	t.Run("Test success case: grown chunk moves and frees its sectors", func(t *testing.T) {
+		f, region := createTestRegion(t)
+		small := Chunk{ChunkHeader: ChunkHeader{X: 0, Z: 0}, Tag: decodeTestChunk(t, 0, 0)}
+		other := Chunk{ChunkHeader: ChunkHeader{X: 1, Z: 0}, Tag: decodeTestChunk(t, 1, 0)}
+		large, _ := nbt.NewCompound("", nbt.NewByteArray("data", make([]byte, 2*SectorSize)))
+		for _, chunk := range []Chunk{small, other, {ChunkHeader: small.ChunkHeader, Tag: large}, small} {
+			if err := region.WriteChunk(chunk); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+
+		// The large chunk is appended after the other chunk, freeing sector 2, which the small chunk then reuses.
+		got := reopen(t, f)
+		if h, _ := got.Header(0, 0); h.Sector != 2 || h.Sectors != 1 {
+			t.Errorf("got header %+v, want sector 2", h)
+		}
+		if h, _ := got.Header(1, 0); h.Sector != 3 {
+			t.Errorf("got header %+v, want sector 3", h)
+		}
+		if h, _ := got.Header(0, 0); time.Since(h.Timestamp) > time.Minute {
+			t.Errorf("got timestamp %v, want now", h.Timestamp)
+		}
+		m, err := got.SectorMap()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(m.Free) != 1 || m.Free[0] != (SectorRange{Start: 4, Length: 3}) {
+			t.Errorf("got free sectors %v, want the large chunk's 4 to 6", m.Free)
+		}
+	})
+
+	t.Run("Test success case: existing region file", func(t *testing.T) {
+		file := buildRegion(testChunk{x: 0, z: 0, data: encodeTestChunk(0, 0), compression: compressionNone})
+		path := filepath.Join(t.TempDir(), "r.0.0.mca")
+		if err := os.WriteFile(path, file, 0o600); err != nil {
+			t.Fatalf("Unable to write test region file: %v", err)
+		}
+		f, err := os.OpenFile(path, os.O_RDWR, 0) // #nosec G304 -- test file
+		if err != nil {
+			t.Fatalf("Unable to open test region file: %v", err)
+		}
+		defer f.Close()
+
+		region := reopen(t, f)
+		chunk := Chunk{ChunkHeader: ChunkHeader{X: 5, Z: 5}, Tag: decodeTestChunk(t, 5, 5)}
+		if err = region.WriteChunk(chunk); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got := reopen(t, f)
+		for _, xz := range [][2]int{{0, 0}, {5, 5}} {
+			tag, ok, err := got.Chunk(xz[0], xz[1])
+			if x, _ := chunkPosition(tag); !ok || err != nil || x != int32(xz[0]) {
+				t.Errorf("got %v %v %v, want chunk %v", x, ok, err, xz)
+			}
+		}
+	})
+
+	t.Run("Test success case: delete chunk", func(t *testing.T) {
+		f, region := createTestRegion(t)
+		chunk := Chunk{ChunkHeader: ChunkHeader{X: 3, Z: 4}, Tag: decodeTestChunk(t, 3, 4)}
+		if err := region.WriteChunk(chunk); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := region.DeleteChunk(3, 4); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, ok := reopen(t, f).Header(3, 4); ok {
+			t.Errorf("got chunk 3,4 present, want deleted")
+		}
+	})
+
+	t.Run("Test failure case: read only region", func(t *testing.T) {
+		file := buildRegion()
+		region, err := Open(bytes.NewReader(file), int64(len(file)))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err = region.WriteChunk(Chunk{Tag: decodeTestChunk(t, 0, 0)}); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+		if err = region.DeleteChunk(0, 0); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+
+	t.Run("Test failure case: chunk too large", func(t *testing.T) {
+		f, region := createTestRegion(t)
+		huge, _ := nbt.NewCompound("", nbt.NewByteArray("data", make([]byte, maxSectors*SectorSize)))
+		if err := region.WriteChunk(Chunk{Tag: huge}); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+		if info, _ := f.Stat(); info.Size() != headerSectors*SectorSize {
+			t.Errorf("got file size %v, want the header alone", info.Size())
+		}
+	})
+
+	t.Run("Test failure case: unsupported compression", func(t *testing.T) {
+		_, region := createTestRegion(t)
+		chunk := Chunk{Tag: decodeTestChunk(t, 0, 0), Compression: nbt.Compression(99)}
+		if err := region.WriteChunk(chunk); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+
+	t.Run("Test failure case: invalid tag leaves header unchanged", func(t *testing.T) {
+		f, region := createTestRegion(t)
+		if err := region.WriteChunk(Chunk{Tag: nbt.NewInt("", 1)}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		before := make([]byte, 8)
+		_, _ = f.ReadAt(before[:4], 0)
+		if err := region.WriteChunk(Chunk{Tag: nbt.NewInt(strings.Repeat("a", 1<<15), 1)}); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+		after := make([]byte, 8)
+		_, _ = f.ReadAt(after[:4], 0)
+		if binary.BigEndian.Uint32(before) != binary.BigEndian.Uint32(after) {
+			t.Errorf("got location % X, want % X", after[:4], before[:4])
+		}
+	})
+}
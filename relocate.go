@@ -0,0 +1,142 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import "fmt"
+
+// RelocateChunk returns a copy of the chunk moved by dx and dz chunks, with the absolute coordinates stored in it
+// rewritten for its new position, so it can be written at another position of a region file of the same kind. The
+// chunk is a terrain chunk in the layout since Java edition 1.18 or in the Level compound before, an entity chunk, or a
+// point of interest chunk. Rewritten are:
+//
+//   - the chunk position: xPos and zPos, and the Position of an entity chunk
+//   - entities: Pos, the TileX and TileZ of hanging entities, and the same of their passengers
+//   - block entities: x and z, in block_entities or Level.TileEntities
+//   - scheduled block and fluid ticks: x and z
+//   - points of interest: the pos of each record
+//
+// Other positions, such as the home of a bee or the target of a compass, are kept, as are entity UUIDs, so a chunk
+// copied within a world duplicates its entities' UUIDs. The given chunk is not modified.
+func RelocateChunk(chunk Tag, dx, dz int32) (Tag, error) {
+	if chunk.id != tagCompound {
+		return Tag{}, fmt.Errorf("Unable to relocate chunk: tag ID %v is not a tagCompound", chunk.id)
+	}
+	bx, bz := dx*16, dz*16
+
+	parent := Path{}
+	if _, ok := compoundChild(chunk, "Level"); ok {
+		parent = Path{"Level"}
+	}
+	chunk, err := editPath(chunk, parent, compoundEdit(func(children []Tag) ([]Tag, error) {
+		children = shiftInts(children, "xPos", "zPos", dx, dz)
+		children = shiftArray(children, "Position", dx, dz)
+		children = editElements(children, "Entities", func(entity []Tag) []Tag {
+			return shiftEntity(entity, bx, bz)
+		})
+		for _, name := range []string{"block_entities", "TileEntities"} {
+			children = editElements(children, name, func(blockEntity []Tag) []Tag {
+				return shiftInts(blockEntity, "x", "z", bx, bz)
+			})
+		}
+		return shiftRecords(children, bx, bz), nil
+	}))
+	if err != nil {
+		return Tag{}, fmt.Errorf("Unable to relocate chunk: %w", err)
+	}
+
+	chunk, err = editTicks(chunk, func(ticks []Tick) []Tick {
+		for i := range ticks {
+			ticks[i].X += bx
+			ticks[i].Z += bz
+		}
+		return ticks
+	})
+	if err != nil {
+		return Tag{}, fmt.Errorf("Unable to relocate chunk: %w", err)
+	}
+	return chunk, nil
+}
+
+// shiftEntity returns a copy of the children of an entity moved by bx and bz blocks, with its passengers.
+func shiftEntity(entity []Tag, bx, bz int32) []Tag {
+	if pos, ok := childPayload[[]any](Tag{payload: entity}, "Pos"); ok && len(pos) == 3 {
+		x, xOK := pos[0].(float64)
+		z, zOK := pos[2].(float64)
+		if xOK && zOK {
+			entity = withChild(entity, Tag{id: tagList, elementID: tagDouble, name: "Pos",
+				payload: []any{x + float64(bx), pos[1], z + float64(bz)}})
+		}
+	}
+	entity = shiftInts(entity, "TileX", "TileZ", bx, bz)
+	return editElements(entity, "Passengers", func(passenger []Tag) []Tag {
+		return shiftEntity(passenger, bx, bz)
+	})
+}
+
+// shiftRecords returns a copy of the children of a point of interest chunk with the pos of every record of its
+// Sections compound moved by bx and bz blocks. The Sections list of a terrain chunk before 1.18 is left as is.
+func shiftRecords(children []Tag, bx, bz int32) []Tag {
+	sections, ok := compoundChild(Tag{payload: children}, "Sections")
+	sectionChildren, isCompound := sections.payload.([]Tag)
+	if !ok || !isCompound {
+		return children
+	}
+
+	shifted := make([]Tag, len(sectionChildren))
+	for i, section := range sectionChildren {
+		if records, ok := section.payload.([]Tag); ok && section.id == tagCompound {
+			section.payload = editElements(records, "Records", func(record []Tag) []Tag {
+				return shiftArray(record, "pos", bx, 0, bz)
+			})
+		}
+		shifted[i] = section
+	}
+	sections.payload = shifted
+	return withChild(children, sections)
+}
+
+// shiftInts returns a copy of the children with the tagInt children named x and z, if present, increased by dx and dz.
+func shiftInts(children []Tag, x, z string, dx, dz int32) []Tag {
+	for _, shift := range []struct {
+		name string
+		d    int32
+	}{{x, dx}, {z, dz}} {
+		if v, ok := childPayload[int32](Tag{payload: children}, shift.name); ok {
+			children = withChild(children, Tag{id: tagInt, name: shift.name, payload: v + shift.d})
+		}
+	}
+	return children
+}
+
+// shiftArray returns a copy of the children with each element of the tagIntArray child of the name, if it has as many
+// elements as offsets, increased by the offset of the same index.
+func shiftArray(children []Tag, name string, offsets ...int32) []Tag {
+	array, ok := childPayload[[]int32](Tag{payload: children}, name)
+	if !ok || len(array) != len(offsets) {
+		return children
+	}
+	shifted := make([]int32, len(array))
+	for i, v := range array {
+		shifted[i] = v + offsets[i]
+	}
+	return withChild(children, Tag{id: tagIntArray, name: name, payload: shifted})
+}
+
+// editElements returns a copy of the children with edit applied to the children of every compound element of the
+// tagList child of the name, if present.
+func editElements(children []Tag, name string, edit func([]Tag) []Tag) []Tag {
+	list, ok := compoundChild(Tag{payload: children}, name)
+	elements, isList := list.payload.([]any)
+	if !ok || !isList || list.id != tagList {
+		return children
+	}
+
+	edited := make([]any, len(elements))
+	for i, element := range elements {
+		if compound, ok := element.([]Tag); ok {
+			element = edit(compound)
+		}
+		edited[i] = element
+	}
+	list.payload = edited
+	return withChild(children, list)
+}
@@ -0,0 +1,103 @@
+package nbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRelocateChunk(t *testing.T) {
+	entity := func(x, z float64, passengers ...any) []Tag {
+		children := []Tag{
+			{id: tagString, name: "id", payload: "minecraft:pig"},
+			{id: tagList, elementID: tagDouble, name: "Pos", payload: []any{x, 64.0, z}},
+		}
+		if passengers != nil {
+			children = append(children, Tag{id: tagList, elementID: tagCompound, name: "Passengers", payload: passengers})
+		}
+		return children
+	}
+	painting := []Tag{
+		{id: tagString, name: "id", payload: "minecraft:painting"},
+		{id: tagInt, name: "TileX", payload: int32(3)},
+		{id: tagInt, name: "TileZ", payload: int32(-4)},
+	}
+	chest := []Tag{{id: tagInt, name: "x", payload: int32(1)}, {id: tagInt, name: "y", payload: int32(70)},
+		{id: tagInt, name: "z", payload: int32(2)}}
+	tick := []Tag{
+		{id: tagString, name: "i", payload: "minecraft:water"},
+		{id: tagInt, name: "x", payload: int32(5)},
+		{id: tagInt, name: "y", payload: int32(60)},
+		{id: tagInt, name: "z", payload: int32(6)},
+		{id: tagInt, name: "t", payload: int32(1)},
+		{id: tagInt, name: "p", payload: int32(0)},
+	}
+	modern := Tag{id: tagCompound, payload: []Tag{
+		{id: tagInt, name: "xPos", payload: int32(0)},
+		{id: tagInt, name: "zPos", payload: int32(0)},
+		{id: tagList, elementID: tagCompound, name: "block_entities", payload: []any{chest}},
+		{id: tagList, elementID: tagCompound, name: "fluid_ticks", payload: []any{tick}},
+	}}
+	legacy := Tag{id: tagCompound, payload: []Tag{{id: tagCompound, name: "Level", payload: []Tag{
+		{id: tagInt, name: "xPos", payload: int32(0)},
+		{id: tagInt, name: "zPos", payload: int32(0)},
+		{id: tagList, elementID: tagCompound, name: "TileEntities", payload: []any{chest}},
+		{id: tagList, elementID: tagCompound, name: "Entities", payload: []any{entity(1.5, 2.5)}},
+	}}}}
+	entities := Tag{id: tagCompound, payload: []Tag{
+		{id: tagIntArray, name: "Position", payload: []int32{0, 0}},
+		{id: tagList, elementID: tagCompound, name: "Entities", payload: []any{
+			entity(1.5, 2.5, entity(1.5, 2.5)), painting,
+		}},
+	}}
+	poi := Tag{id: tagCompound, payload: []Tag{{id: tagCompound, name: "Sections", payload: []Tag{
+		{id: tagCompound, name: "4", payload: []Tag{{id: tagList, elementID: tagCompound, name: "Records",
+			payload: []any{[]Tag{{id: tagIntArray, name: "pos", payload: []int32{7, 70, 8}}}}}}},
+	}}}}
+
+	successCases := []struct {
+		name  string
+		chunk Tag
+		want  map[string]any
+	}{
+		{"modern chunk", modern, map[string]any{
+			"xPos": int32(2), "zPos": int32(-1),
+			"block_entities[0].x": int32(33), "block_entities[0].y": int32(70), "block_entities[0].z": int32(-14),
+			"fluid_ticks[0].x": int32(37), "fluid_ticks[0].z": int32(-10),
+		}},
+		{"legacy chunk", legacy, map[string]any{
+			"Level.xPos": int32(2), "Level.zPos": int32(-1), "Level.TileEntities[0].x": int32(33),
+			"Level.Entities[0].Pos": []any{33.5, 64.0, -13.5},
+		}},
+		{"entity chunk", entities, map[string]any{
+			"Position":                      []int32{2, -1},
+			"Entities[0].Pos":               []any{33.5, 64.0, -13.5},
+			"Entities[0].Passengers[0].Pos": []any{33.5, 64.0, -13.5},
+			"Entities[1].TileX":             int32(35),
+			"Entities[1].TileZ":             int32(-20),
+		}},
+		{"point of interest chunk", poi, map[string]any{`Sections."4".Records[0].pos`: []int32{39, 70, -8}}},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			original := successCase.chunk.Clone()
+			got, err := RelocateChunk(successCase.chunk, 2, -1)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for path, want := range successCase.want {
+				if v, err := Get[any](&got, path); err != nil || !reflect.DeepEqual(v, want) {
+					t.Errorf("%v: got %v %v, want %v", path, v, err, want)
+				}
+			}
+			if !Equal(&successCase.chunk, &original) {
+				t.Errorf("got the given chunk modified")
+			}
+		})
+	}
+
+	t.Run("Test failure case: not a compound", func(t *testing.T) {
+		if _, err := RelocateChunk(Tag{id: tagInt, payload: int32(1)}, 1, 1); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+}
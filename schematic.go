@@ -0,0 +1,76 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import "fmt"
+
+// SchematicFormat is a file format for storing a region of blocks.
+type SchematicFormat int
+
+// SchematicFormat values.
+const (
+	// SchematicUnknown is a tree that matches no known format.
+	SchematicUnknown SchematicFormat = iota
+	// SchematicSponge is a Sponge schematic (.schem) of any version.
+	SchematicSponge
+	// SchematicMCEdit is an MCEdit schematic (.schematic), with numeric block IDs.
+	SchematicMCEdit
+	// SchematicLitematic is a Litematica schematic (.litematic).
+	SchematicLitematic
+	// SchematicStructure is a Java edition structure template (.nbt).
+	SchematicStructure
+	// SchematicMCStructure is a Bedrock edition structure (.mcstructure).
+	SchematicMCStructure
+)
+
+// String returns the file extension of the format.
+func (f SchematicFormat) String() string {
+	switch f {
+	case SchematicUnknown:
+		return "unknown"
+	case SchematicSponge:
+		return ".schem"
+	case SchematicMCEdit:
+		return ".schematic"
+	case SchematicLitematic:
+		return ".litematic"
+	case SchematicStructure:
+		return ".nbt"
+	case SchematicMCStructure:
+		return ".mcstructure"
+	default:
+		return fmt.Sprintf("SchematicFormat(%d)", int(f))
+	}
+}
+
+// DetectSchematic returns the format of a decoded schematic by the children of its root compound, whatever its file
+// name. Sponge version 3 schematics hold their content in a Schematic compound below the root.
+func DetectSchematic(t Tag) SchematicFormat {
+	has := func(t Tag, names ...string) bool {
+		for _, name := range names {
+			if _, ok := compoundChild(t, name); !ok {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch {
+	case t.id != tagCompound:
+		return SchematicUnknown
+	case has(t, "format_version", "size", "structure"):
+		return SchematicMCStructure
+	case has(t, "Regions", "Metadata"):
+		return SchematicLitematic
+	case has(t, "Blocks", "Data", "Materials"):
+		return SchematicMCEdit
+	case has(t, "Version", "Width", "Height", "Length"):
+		return SchematicSponge
+	case has(t, "Schematic"):
+		if schematic, _ := compoundChild(t, "Schematic"); has(schematic, "Version", "Width", "Height", "Length") {
+			return SchematicSponge
+		}
+	case has(t, "size", "blocks") && (has(t, "palette") || has(t, "palettes")):
+		return SchematicStructure
+	}
+	return SchematicUnknown
+}
@@ -0,0 +1,47 @@
+package nbt
+
+import "testing"
+
+func TestDetectSchematic(t *testing.T) {
+	compound := func(names ...string) Tag {
+		var children []Tag
+		for _, name := range names {
+			children = append(children, Tag{id: tagInt, name: name, payload: int32(0)})
+		}
+		return Tag{id: tagCompound, payload: children}
+	}
+	sponge := compound("Version", "Width", "Height", "Length", "Palette")
+	template, _ := StructureTemplate([][][]BlockState{{{{Name: "minecraft:stone"}}}}, nil, 3465)
+
+	tests := []struct {
+		name  string
+		input Tag
+		want  SchematicFormat
+	}{
+		{"Test success case: Sponge", sponge, SchematicSponge},
+		{"Test success case: Sponge version 3", Tag{id: tagCompound, payload: []Tag{withName(sponge, "Schematic")}},
+			SchematicSponge},
+		{"Test success case: MCEdit", compound("Width", "Height", "Length", "Blocks", "Data", "Materials"),
+			SchematicMCEdit},
+		{"Test success case: Litematica", compound("Version", "Regions", "Metadata"), SchematicLitematic},
+		{"Test success case: structure template", template, SchematicStructure},
+		{"Test success case: several palettes", compound("size", "palettes", "blocks"), SchematicStructure},
+		{"Test success case: Bedrock structure", compound("format_version", "size", "structure",
+			"structure_world_origin"), SchematicMCStructure},
+		{"Test failure case: level.dat", compound("Data"), SchematicUnknown},
+		{"Test failure case: empty Schematic", compound("Schematic"), SchematicUnknown},
+		{"Test failure case: not a compound", Tag{id: tagList}, SchematicUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectSchematic(tt.input); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := SchematicFormat(99).String(); got != "SchematicFormat(99)" {
+		t.Errorf("got %v, want SchematicFormat(99)", got)
+	}
+}
@@ -0,0 +1,164 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"fmt"
+	"slices"
+)
+
+// SignTextDataVersion is the DataVersion of Java edition 1.20, from which sign block entities store "front_text" and
+// "back_text" compounds rather than the legacy Text1 to Text4, Color and GlowingText children.
+const SignTextDataVersion = 3463
+
+// blankSignLine is the JSON text component of an empty sign line.
+const blankSignLine = `""`
+
+// SignText is the text of one side of a sign.
+type SignText struct {
+	// Messages are the four lines as JSON text components. An empty message is written as a blank line.
+	Messages [4]string
+	// Color is the dye colour of the text, such as "black".
+	Color   string
+	Glowing bool
+}
+
+// SetLines sets the lines of plain text from the first line onwards, blanking any lines not given. Extra lines are
+// ignored.
+func (s *SignText) SetLines(lines ...string) {
+	for i := range s.Messages {
+		s.Messages[i] = blankSignLine
+		if i < len(lines) {
+			s.Messages[i] = textComponent(lines[i])
+		}
+	}
+}
+
+// Sign is the text of both sides of a sign block entity. Signs before 1.20 only have a front.
+type Sign struct {
+	Front, Back SignText
+	Waxed       bool
+}
+
+// signLegacyNames are the children of a sign block entity before 1.20.
+var signLegacyNames = []string{"Text1", "Text2", "Text3", "Text4", "Color", "GlowingText"}
+
+// signNames are the children of a sign block entity from 1.20.
+var signNames = []string{"front_text", "back_text", "is_waxed"}
+
+// SignFromTag reads the text of a sign block entity of any DataVersion.
+func SignFromTag(t Tag) (s Sign, err error) {
+	if t.id != tagCompound {
+		return Sign{}, fmt.Errorf("Unable to read sign: tag ID %v is not a tagCompound", t.id)
+	}
+
+	if front, ok := compoundChild(t, "front_text"); ok {
+		s.Front, err = signTextFromTag(front)
+		if err == nil {
+			back, _ := compoundChild(t, "back_text")
+			s.Back, err = signTextFromTag(back)
+		}
+		waxed, _ := childPayload[byte](t, "is_waxed")
+		s.Waxed = waxed != 0
+	} else {
+		for i := range s.Front.Messages {
+			s.Front.Messages[i], _ = childPayload[string](t, signLegacyNames[i])
+		}
+		s.Front.Color, _ = childPayload[string](t, "Color")
+		glowing, _ := childPayload[byte](t, "GlowingText")
+		s.Front.Glowing = glowing != 0
+	}
+	if err != nil {
+		return Sign{}, fmt.Errorf("Unable to read sign: %w", err)
+	}
+	return s, nil
+}
+
+// signTextFromTag reads a front_text or back_text compound. A missing side is blank.
+func signTextFromTag(t Tag) (s SignText, err error) {
+	messages, _ := compoundChild(t, "messages")
+	if messages.id != tagEnd {
+		lines, err := stringList(messages)
+		if err != nil {
+			return SignText{}, err
+		}
+		if len(lines) > len(s.Messages) {
+			return SignText{}, fmt.Errorf("\"messages\" has %v lines, more than %v", len(lines), len(s.Messages))
+		}
+		copy(s.Messages[:], lines)
+	}
+	s.Color, _ = childPayload[string](t, "color")
+	glowing, _ := childPayload[byte](t, "has_glowing_text")
+	s.Glowing = glowing != 0
+	return s, nil
+}
+
+// SetSign returns a copy of the sign block entity holding the sign text in the layout of the DataVersion, see
+// SignTextDataVersion. Sign children of either layout are replaced, all other children are kept. The given entity is
+// not modified. Before 1.20 a sign has no back, so the back must be blank and unwaxed.
+func SetSign(t Tag, s Sign, dataVersion int32) (Tag, error) {
+	if t.id != tagCompound {
+		return Tag{}, fmt.Errorf("Unable to set sign: tag ID %v is not a tagCompound", t.id)
+	}
+
+	children, _ := t.payload.([]Tag)
+	for _, name := range slices.Concat(signLegacyNames, signNames) {
+		children, _ = withoutChild(children, name)
+	}
+
+	if dataVersion >= SignTextDataVersion {
+		children = append(children, s.Front.tag("front_text"), s.Back.tag("back_text"),
+			Tag{id: tagByte, name: "is_waxed", payload: boolByte(s.Waxed)})
+	} else {
+		if s.Waxed || !s.Back.blank() {
+			return Tag{}, fmt.Errorf("Unable to set sign: signs before DataVersion %v have no back or wax",
+				SignTextDataVersion)
+		}
+		for i, message := range s.Front.Messages {
+			children = append(children, Tag{id: tagString, name: signLegacyNames[i], payload: signLine(message)})
+		}
+		children = append(children, Tag{id: tagString, name: "Color", payload: signColor(s.Front.Color)},
+			Tag{id: tagByte, name: "GlowingText", payload: boolByte(s.Front.Glowing)})
+	}
+
+	t.payload = children
+	return t, nil
+}
+
+// tag returns the side as a named front_text or back_text compound.
+func (s SignText) tag(name string) Tag {
+	messages := make([]any, len(s.Messages))
+	for i, message := range s.Messages {
+		messages[i] = signLine(message)
+	}
+	return Tag{id: tagCompound, name: name, payload: []Tag{
+		{id: tagList, elementID: tagString, name: "messages", payload: messages},
+		{id: tagString, name: "color", payload: signColor(s.Color)},
+		{id: tagByte, name: "has_glowing_text", payload: boolByte(s.Glowing)},
+	}}
+}
+
+// blank reports whether the side has no text and the default style.
+func (s SignText) blank() bool {
+	for _, message := range s.Messages {
+		if signLine(message) != blankSignLine {
+			return false
+		}
+	}
+	return !s.Glowing && signColor(s.Color) == "black"
+}
+
+// signLine returns the message, or a blank line for an empty message.
+func signLine(message string) string {
+	if message == "" {
+		return blankSignLine
+	}
+	return message
+}
+
+// signColor returns the colour, or the default black for an empty colour.
+func signColor(color string) string {
+	if color == "" {
+		return "black"
+	}
+	return color
+}
@@ -0,0 +1,124 @@
+package nbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	var sign Sign
+	sign.Front.SetLines("Welcome", "home")
+	sign.Front.Color = "blue"
+	sign.Front.Glowing = true
+
+	entity := Tag{id: tagCompound, payload: []Tag{
+		{id: tagString, name: "id", payload: "minecraft:sign"},
+		{id: tagString, name: "Text1", payload: `{"text":"old"}`},
+	}}
+
+	tests := []struct {
+		name        string
+		sign        Sign
+		dataVersion int32
+		wantLen     int
+		wantErr     bool
+	}{
+		{
+			name:        "Test success case: modern layout",
+			sign:        Sign{Front: sign.Front, Back: SignText{Messages: [4]string{`{"text":"back"}`}}, Waxed: true},
+			dataVersion: SignTextDataVersion,
+			wantLen:     4,
+		},
+		{
+			name:        "Test success case: legacy layout",
+			sign:        sign,
+			dataVersion: SignTextDataVersion - 1,
+			wantLen:     7,
+		},
+		{
+			name:        "Test failure case: legacy back",
+			sign:        Sign{Back: SignText{Messages: [4]string{`{"text":"back"}`}}},
+			dataVersion: SignTextDataVersion - 1,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SetSign(entity, tt.sign, tt.dataVersion)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if children := got.payload.([]Tag); len(children) != tt.wantLen || children[0].name != "id" {
+				t.Errorf("got %+v, want %v children keeping the id", children, tt.wantLen)
+			}
+
+			reread, err := SignFromTag(got)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			want := tt.sign
+			for i := range want.Back.Messages {
+				want.Back.Messages[i] = signLine(want.Back.Messages[i])
+			}
+			if tt.dataVersion >= SignTextDataVersion {
+				want.Back.Color = "black"
+			} else {
+				want.Back = SignText{}
+			}
+			if !reflect.DeepEqual(reread, want) {
+				t.Errorf("got %+v, want %+v", reread, want)
+			}
+		})
+	}
+
+	if text1, _ := childPayload[string](entity, "Text1"); text1 != `{"text":"old"}` {
+		t.Errorf("got Text1 %v in the original, want it unchanged", text1)
+	}
+}
+
+func TestSignFromTag(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   Tag
+		want    Sign
+		wantErr bool
+	}{
+		{
+			name: "Test success case: legacy without glowing text",
+			input: Tag{id: tagCompound, payload: []Tag{
+				{id: tagString, name: "Text2", payload: `{"text":"hi"}`},
+			}},
+			want: Sign{Front: SignText{Messages: [4]string{"", `{"text":"hi"}`}}},
+		},
+		{
+			name: "Test failure case: too many lines",
+			input: Tag{id: tagCompound, payload: []Tag{
+				{id: tagCompound, name: "front_text", payload: []Tag{
+					{id: tagList, elementID: tagString, name: "messages", payload: []any{"1", "2", "3", "4", "5"}},
+				}},
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "Test failure case: not a compound",
+			input:   Tag{id: tagString, payload: "sign"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SignFromTag(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
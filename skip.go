@@ -0,0 +1,343 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Skip advances past a payload without decoding it, using the sizes and lengths it declares, so a caller after one
+// child of a huge compound does not pay to decode the arrays and strings around it. After Token returns a TagHeader,
+// or within a tagList with elements left, Skip skips the next payload, the whole tag for a compound or list. Otherwise,
+// after a CompoundStart or the payload of a compound's child, it skips the rest of the innermost compound, and after
+// the last element of a list the rest of the list, including the End. Limits are checked as when reading.
+func (d *Decoder) Skip() (err error) {
+	defer d.clearDeadline()
+	if err = d.open(); err != nil {
+		return fmt.Errorf("Unable to skip: %w", err)
+	}
+
+	if d.next == tagEnd && len(d.stack) > 0 {
+		top := &d.stack[len(d.stack)-1]
+		if top.list && top.remaining > 0 {
+			top.remaining--
+			d.next = top.elementID
+		}
+	}
+
+	switch {
+	case d.next != tagEnd:
+		id := d.next
+		d.next = tagEnd
+		err = skipPayload(d.input, d.tokenOptions(), id)
+	case len(d.stack) == 0:
+		return fmt.Errorf("Unable to skip: no tag is part read by Token")
+	default:
+		err = d.skipContainer()
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to skip: %w", err)
+	}
+	return nil
+}
+
+// skipContainer skips the rest of the innermost compound or list, and leaves it.
+func (d *Decoder) skipContainer() error {
+	o := d.tokenOptions()
+	top := d.stack[len(d.stack)-1]
+	d.stack = d.stack[:len(d.stack)-1]
+	if top.list {
+		return skipListElements(d.input, o, top.elementID, top.remaining)
+	}
+	return skipCompoundChildren(d.input, o)
+}
+
+// DecodePath reads the next tag of the stream, returning only the tag at the path below it, in the text form of a
+// Path such as "Data.Player.Inventory" or "Data.Player.Pos[1]". Every tag before and after it is skipped as by Skip, so
+// pulling one value, such as the seed, from level.dat decodes little more than that value. The boolean is false if
+// the tag has no tag at the path. List elements are returned as unnamed tags of the listed type. DecodePath reads
+// whole tags, so must not be called part way through the tokens of a tag read with Token. At the end of the input,
+// DecodePath returns io.EOF.
+func (d *Decoder) DecodePath(path string) (t Tag, ok bool, err error) {
+	p, err := ParsePath(path)
+	if err != nil {
+		return Tag{}, false, fmt.Errorf("Unable to decode path: %w", err)
+	}
+	defer d.clearDeadline()
+	if err = d.open(); err != nil {
+		return Tag{}, false, fmt.Errorf("Unable to decode path %v: %w", p, err)
+	}
+	if d.next != tagEnd || len(d.stack) > 0 {
+		return Tag{}, false, fmt.Errorf("Unable to decode path %v: the tokens of a tag are part read by Token", p)
+	}
+
+	t, ok, err = d.decodePath(p)
+	if err == io.EOF {
+		return Tag{}, false, io.EOF
+	}
+	if err == nil && !ok {
+		err = d.skipTag()
+	}
+	if err != nil {
+		return Tag{}, false, fmt.Errorf("Unable to decode path %v: %w", p, err)
+	}
+	return t, ok, nil
+}
+
+// decodePath descends the next tag of the stream along the path, skipping the tags before each step, and reads the tag
+// at the path whole. Once the tag is found, the rest of the tag is skipped. If it is not found, the decoder is left
+// within the tag at the last step found.
+func (d *Decoder) decodePath(p Path) (t Tag, ok bool, err error) {
+	o := d.tokenOptions()
+	offset := d.input.read
+	token, err := d.headerToken()
+	if err != nil {
+		return Tag{}, false, err
+	}
+	header := token.(TagHeader)
+	t.id, t.name = header.ID, header.Name
+
+	for _, element := range p {
+		switch e := element.(type) {
+		case string:
+			if t.id != tagCompound {
+				return Tag{}, false, nil
+			}
+			if _, err = d.payloadToken(d.next); err != nil {
+				return Tag{}, false, err
+			}
+			d.next = tagEnd
+			for {
+				o = d.tokenOptions()
+				offset = d.input.read
+				token, err = d.headerToken()
+				if err != nil {
+					return Tag{}, false, err
+				}
+				header, isHeader := token.(TagHeader)
+				if !isHeader {
+					return Tag{}, false, nil
+				}
+				if header.Name == e {
+					t = Tag{id: header.ID, name: header.Name}
+					break
+				}
+				d.next = tagEnd
+				if err = skipPayload(d.input, o, header.ID); err != nil {
+					return Tag{}, false, err
+				}
+			}
+		case int:
+			if t.id != tagList {
+				return Tag{}, false, nil
+			}
+			token, err = d.payloadToken(d.next)
+			if err != nil {
+				return Tag{}, false, err
+			}
+			d.next = tagEnd
+			list := token.(ListStart)
+			if e >= list.Len {
+				return Tag{}, false, nil
+			}
+			if err = skipListElements(d.input, d.tokenOptions(), list.ElementID, e); err != nil {
+				return Tag{}, false, err
+			}
+			d.stack[len(d.stack)-1].remaining -= e + 1
+			d.next = list.ElementID
+			t, offset = Tag{id: list.ElementID}, -1
+		}
+	}
+
+	o = d.tokenOptions().withAllocation()
+	d.next = tagEnd
+	if t.id == tagList {
+		t.elementID, t.payload, err = readTagListPayload(d.input, o)
+	} else {
+		t.payload, err = readTagPayload(d.input, o, t.id)
+	}
+	if err != nil {
+		return Tag{}, false, err
+	}
+	if o.Provenance && offset >= 0 {
+		t.source = &Source{File: o.SourceFile, Offset: offset}
+	}
+	return t, true, d.skipTag()
+}
+
+// skipTag skips the rest of the tag part read, leaving the decoder between tags.
+func (d *Decoder) skipTag() (err error) {
+	if d.next != tagEnd {
+		id := d.next
+		d.next = tagEnd
+		if err = skipPayload(d.input, d.tokenOptions(), id); err != nil {
+			return err
+		}
+	}
+	for len(d.stack) > 0 {
+		if err = d.skipContainer(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipPayload reads past the payload of a tag of the ID without decoding it. Numbers, arrays and strings are
+// discarded by their sizes, and compounds and lists skipped child by child.
+func skipPayload(buffer io.Reader, o Options, id uint8) (err error) {
+	if size := fixedPayloadSize(o, id); size > 0 {
+		return discard(buffer, size)
+	}
+
+	switch id {
+	case tagEnd:
+		return fmt.Errorf("Not expecting to skip a tagEnd in the payload")
+	case tagInt:
+		_, err = readInt32(buffer, o)
+	case tagLong:
+		_, err = readInt64(buffer, o)
+	case tagByteArray:
+		err = skipArray(buffer, o, "tagByteArray", 1)
+	case tagIntArray:
+		err = skipArray(buffer, o, "tagIntArray", 4)
+	case tagLongArray:
+		err = skipArray(buffer, o, "tagLongArray", 8)
+	case tagString:
+		var length int
+		length, err = readStringLength(buffer, o)
+		if err == nil {
+			err = checkLength(length, o.Limits.MaxStringLength)
+		}
+		if err == nil {
+			err = discard(buffer, int64(length))
+		}
+	case tagList:
+		elementID, length, err := readTagListHeader(buffer, o)
+		if err != nil {
+			return err
+		}
+		o.depth++
+		if err = checkLength(o.depth, o.Limits.MaxDepth); err != nil {
+			return fmt.Errorf("Unable to skip tagList depth: %w", err)
+		}
+		return skipListElements(buffer, o, elementID, int(length))
+	case tagCompound:
+		o.depth++
+		if err = checkLength(o.depth, o.Limits.MaxDepth); err != nil {
+			return fmt.Errorf("Unable to skip tagCompound depth: %w", err)
+		}
+		return skipCompoundChildren(buffer, o)
+	default:
+		_, err = readTagPayload(buffer, o, id)
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to skip payload of tag ID %v: %w", id, err)
+	}
+	return nil
+}
+
+// skipArray reads past the payload of a tagByteArray, tagIntArray or tagLongArray of elements of the fixed size.
+func skipArray(buffer io.Reader, o Options, tagType string, size int64) (err error) {
+	length, err := readInt32(buffer, o)
+	if err != nil {
+		return fmt.Errorf("Unable to skip %v payload size: %w", tagType, err)
+	}
+	if length < 0 {
+		return fmt.Errorf("Unable to skip %v payload size: size %v is negative", tagType, length)
+	}
+	if err = checkLength(int(length), o.Limits.MaxArrayLength); err != nil {
+		return fmt.Errorf("Unable to skip %v payload size: %w", tagType, err)
+	}
+
+	// The bytes of a tagByteArray are never VarInts.
+	if !o.VarInt || size == 1 {
+		return discard(buffer, int64(length)*size)
+	}
+	maxLen := maxVarIntLen32
+	if size == 8 {
+		maxLen = maxVarIntLen64
+	}
+	for i := range length {
+		if _, err = readUvarint(buffer, maxLen); err != nil {
+			return fmt.Errorf("Unable to skip %v payload element %v: %w", tagType, i, err)
+		}
+	}
+	return nil
+}
+
+// skipListElements reads past the length elements of a tagList with the element ID, at once if their size is fixed.
+func skipListElements(buffer io.Reader, o Options, elementID uint8, length int) (err error) {
+	if length <= 0 {
+		return nil
+	}
+	if size := fixedPayloadSize(o, elementID); size > 0 {
+		return discard(buffer, int64(length)*size)
+	}
+
+	for i := range length {
+		if err = skipPayload(buffer, o, elementID); err != nil {
+			return fmt.Errorf("Unable to skip tagList payload element %v: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// skipCompoundChildren reads past the children of a compound, and the tagEnd ending it.
+func skipCompoundChildren(buffer io.Reader, o Options) (err error) {
+	for i := 0; ; i++ {
+		id, err := readTagID(buffer, o)
+		if err != nil {
+			return fmt.Errorf("Unable to skip tagCompound payload element %v: %w", i, err)
+		}
+		if id == tagEnd {
+			return nil
+		}
+
+		length, err := readStringLength(buffer, o)
+		if err == nil {
+			err = discard(buffer, int64(length))
+		}
+		if err == nil {
+			err = skipPayload(buffer, o, id)
+		}
+		if err != nil {
+			return fmt.Errorf("Unable to skip tagCompound payload element %v: %w", i, err)
+		}
+	}
+}
+
+// fixedPayloadSize returns the size in bytes of every payload of the tag ID, or 0 if payloads of the ID vary in size.
+func fixedPayloadSize(o Options, id uint8) int64 {
+	switch id {
+	case tagByte:
+		return 1
+	case tagShort:
+		return 2
+	case tagFloat:
+		return 4
+	case tagDouble:
+		return 8
+	case tagInt:
+		if !o.VarInt {
+			return 4
+		}
+	case tagLong:
+		if !o.VarInt {
+			return 8
+		}
+	}
+	return 0
+}
+
+// discard reads and drops n bytes, checking first that they fit in the bytes remaining in the buffer.
+func discard(buffer io.Reader, n int64) (err error) {
+	if err = checkRemaining(buffer, n, 1); err != nil {
+		return err
+	}
+	_, err = io.CopyN(io.Discard, buffer, n)
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
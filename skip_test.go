@@ -0,0 +1,228 @@
+package nbt
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestSkipPayload(t *testing.T) {
+	root := tokenTestTag(t)
+	nested, err := NewList("nested", tagList, Tag{id: tagList, payload: []any{"a", "b"}})
+	if err != nil {
+		t.Fatalf("Unable to create test tag: %v", err)
+	}
+	tags := []Tag{
+		NewByte("byte", 1), NewShort("short", 2), NewInt("int", -3), NewLong("long", 4), NewFloat("float", 5),
+		NewDouble("double", 6), NewByteArray("bytes", []byte{7, 8}), NewString("string", "nine"),
+		NewIntArray("ints", []int32{10, -11}), NewLongArray("longs", []int64{12, -13}), nested, root,
+	}
+
+	for _, edition := range []struct {
+		name string
+		opts []Option
+	}{{name: "Java edition", opts: []Option{JavaEdition}}, {name: "Bedrock network", opts: []Option{BedrockNetwork}}} {
+		for _, tag := range tags {
+			t.Run("Test success case: "+edition.name+" "+tag.name, func(t *testing.T) {
+				var b bytes.Buffer
+				if err := WriteTag(&b, tag, edition.opts...); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				_ = WriteTag(&b, NewByte("after", 1), edition.opts...)
+
+				o := newOptions(edition.opts)
+				input := newInputReader(&b, true)
+				if _, err := readTagID(input, o); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if _, err := readTagName(input, o); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if err := skipPayload(input, o, tag.id); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				after, err := readTag(input, o)
+				if err != nil || after.name != "after" {
+					t.Errorf("got %+v %v after skipping, want the next tag", after, err)
+				}
+			})
+		}
+	}
+
+	t.Run("Test failure case: array longer than the input", func(t *testing.T) {
+		input := newInputReader(bytes.NewReader([]byte{0, 0, 0, 9, 1, 2}), true)
+		if err := skipPayload(input, newOptions(nil), tagByteArray); err == nil {
+			t.Errorf("Expected an error, got nil")
+		}
+	})
+
+	t.Run("Test failure case: truncated input of unknown size", func(t *testing.T) {
+		input := newInputReader(bytes.NewReader([]byte{0, 0, 0, 2, 1, 2, 3}), false)
+		if err := skipPayload(input, newOptions(nil), tagIntArray); err == nil {
+			t.Errorf("Expected an error, got nil")
+		}
+	})
+
+	t.Run("Test failure case: depth over the limit", func(t *testing.T) {
+		var b bytes.Buffer
+		_ = writeTagPayload(&b, newOptions(nil), tagCompound, root.payload)
+		o := newOptions([]Option{WithLimits(Limits{MaxDepth: 2})})
+		if err := skipPayload(newInputReader(&b, true), o, tagCompound); err == nil {
+			t.Errorf("Expected an error, got nil")
+		}
+	})
+}
+
+func TestDecoderSkip(t *testing.T) {
+	root := tokenTestTag(t)
+	var input bytes.Buffer
+	if err := WriteTag(&input, root); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		// skipAfter skips after the token at each index read.
+		skipAfter map[int]bool
+		want      []Token
+	}{
+		{name: "Test success case: skip the root payload", skipAfter: map[int]bool{0: true},
+			want: []Token{TagHeader{ID: tagCompound, Name: "root"}}},
+		{name: "Test success case: skip the rest of the root", skipAfter: map[int]bool{3: true},
+			want: tokenTestWant[:4]},
+		{name: "Test success case: skip an array and a list", skipAfter: map[int]bool{4: true, 5: true},
+			want: append(append([]Token{}, tokenTestWant[:5]...), TagHeader{ID: tagList, Name: "Items"},
+				TagHeader{ID: tagList, Name: "Empty"}, ListStart{ElementID: tagInt}, End{}, End{})},
+		{name: "Test success case: skip a list element", skipAfter: map[int]bool{7: true},
+			want: append(append([]Token{}, tokenTestWant[:8]...), tokenTestWant[12:]...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(bytes.NewReader(input.Bytes()))
+			var got []Token
+			for {
+				token, err := d.Token()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				got = append(got, token)
+				if tt.skipAfter[len(got)-1] {
+					if err = d.Skip(); err != nil {
+						t.Fatalf("Unexpected error: %v", err)
+					}
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("Test failure case: nothing to skip", func(t *testing.T) {
+		if err := NewDecoder(bytes.NewReader(input.Bytes())).Skip(); err == nil {
+			t.Errorf("Expected an error, got nil")
+		}
+	})
+}
+
+// levelTestTag returns a tag shaped like level.dat, with data around the values looked up.
+func levelTestTag(t *testing.T) Tag {
+	t.Helper()
+	item, _ := NewCompound("", NewByte("Count", 1), NewString("id", "minecraft:stone"))
+	inventory, _ := NewList("Inventory", tagCompound, item)
+	pos, _ := NewList("Pos", tagDouble, NewDouble("", 1.5), NewDouble("", 64), NewDouble("", -2.5))
+	player, _ := NewCompound("Player", NewByteArray("Padding", make([]byte, 1024)), inventory, pos)
+	settings, _ := NewCompound("WorldGenSettings", NewLong("seed", 42))
+	data, err := NewCompound("Data", NewLongArray("Heights", make([]int64, 256)), settings, player)
+	if err != nil {
+		t.Fatalf("Unable to create test tag: %v", err)
+	}
+	root, err := NewCompound("", data)
+	if err != nil {
+		t.Fatalf("Unable to create test tag: %v", err)
+	}
+	return root
+}
+
+func TestDecoderDecodePath(t *testing.T) {
+	level := levelTestTag(t)
+	data, _ := compoundChild(level, "Data")
+	var input bytes.Buffer
+	if err := WriteTag(&input, level); err != nil {
+		t.Fatalf("Unable to write test tag: %v", err)
+	}
+	_ = WriteTag(&input, NewInt("next", 5))
+
+	tests := []struct {
+		name    string
+		path    string
+		want    Tag
+		wantOK  bool
+		wantErr bool
+	}{
+		{name: "Test success case: nested long", path: "Data.WorldGenSettings.seed", want: NewLong("seed", 42),
+			wantOK: true},
+		{name: "Test success case: list element", path: "Data.Player.Pos[1]", want: Tag{id: tagDouble,
+			payload: float64(64)}, wantOK: true},
+		{name: "Test success case: child of a list element", path: "Data.Player.Inventory[0].id",
+			want: NewString("id", "minecraft:stone"), wantOK: true},
+		{name: "Test success case: whole compound", path: "Data", want: data, wantOK: true},
+		{name: "Test success case: root", path: "", want: level, wantOK: true},
+		{name: "Test success case: missing child", path: "Data.Missing.seed"},
+		{name: "Test success case: index out of range", path: "Data.Player.Pos[3]"},
+		{name: "Test success case: name below an array", path: "Data.Heights.x"},
+		{name: "Test success case: index of a compound", path: "Data[0]"},
+		{name: "Test failure case: invalid path", path: "Data[", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(bytes.NewReader(input.Bytes()))
+			got, ok, err := d.DecodePath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if ok != tt.wantOK || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v %v, want %v %v", got, ok, tt.want, tt.wantOK)
+			}
+
+			next, ok, err := d.DecodePath("")
+			if err != nil || !ok || next.name != "next" {
+				t.Errorf("got %v %v %v after the path, want the next tag", next, ok, err)
+			}
+			if _, _, err = d.DecodePath(""); err != io.EOF {
+				t.Errorf("got %v, want io.EOF", err)
+			}
+		})
+	}
+
+	t.Run("Test failure case: truncated input", func(t *testing.T) {
+		d := NewDecoder(bytes.NewReader(input.Bytes()[:100]))
+		if _, _, err := d.DecodePath("Data.Player.Pos[0]"); err == nil {
+			t.Errorf("Expected an error, got nil")
+		}
+	})
+
+	t.Run("Test failure case: allocation exceeds limit", func(t *testing.T) {
+		d := NewDecoder(bytes.NewReader(input.Bytes()), WithLimits(Limits{MaxAllocation: 64}))
+		if _, _, err := d.DecodePath("Data"); err == nil {
+			t.Errorf("Expected an error, got nil")
+		}
+	})
+
+	t.Run("Test failure case: part way through a tag read by Token", func(t *testing.T) {
+		d := NewDecoder(bytes.NewReader(input.Bytes()))
+		_, _ = d.Token()
+		if _, _, err := d.DecodePath("Data"); err == nil {
+			t.Errorf("Expected an error, got nil")
+		}
+	})
+}
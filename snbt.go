@@ -0,0 +1,465 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// String returns the payload of the tag as compact SNBT (stringified NBT), as accepted by Minecraft commands, such as
+// {Count:1b,id:"minecraft:stone"}. The tag's own name is not included. See SNBT for indented output.
+func (t Tag) String() string {
+	return SNBT(t, "")
+}
+
+// SNBT returns the payload of the tag as SNBT. Numbers carry their type suffix (b, s, L, f, d, none for tagInt), arrays
+// their type prefix ([B;], [I;], [L;]), and strings are quoted, escaping quotes and backslashes. Compound keys are
+// quoted only when they contain characters other than letters, digits and _-.+. With a non-empty indent, compounds and
+// lists are spread over lines, each level indented by another indent; arrays stay on one line. A RawPayload, having no
+// SNBT form, is written as a byte array. SNBT has no form for non-finite floats, so ±Inf is written as a number too
+// large for its type, such as 1e39f, which both Minecraft and ParseSNBT read back as ±Inf, while NaN is lost, written
+// as 0f or 0d.
+func SNBT(t Tag, indent string) string {
+	var b strings.Builder
+	writeSNBT(&b, payloadOrEmpty(t), indent, 0)
+	return b.String()
+}
+
+// payloadOrEmpty returns the payload of the tag, or an empty payload of the tag's type for an array, list or compound
+// built without one.
+func payloadOrEmpty(t Tag) any {
+	if t.payload != nil {
+		return t.payload
+	}
+	return map[uint8]any{tagByteArray: []byte(nil), tagList: []any(nil), tagCompound: []Tag(nil),
+		tagIntArray: []int32(nil), tagLongArray: []int64(nil)}[t.id]
+}
+
+// writeSNBT writes a payload as SNBT at the depth of nesting.
+func writeSNBT(b *strings.Builder, payload any, indent string, depth int) {
+	switch p := payload.(type) {
+	case byte:
+		b.WriteString(snbtByte(p))
+	case int16:
+		b.WriteString(strconv.Itoa(int(p)) + "s")
+	case int32:
+		b.WriteString(strconv.Itoa(int(p)))
+	case int64:
+		b.WriteString(strconv.FormatInt(p, 10) + "L")
+	case float32:
+		b.WriteString(snbtFloat(float64(p), 32, "1e39") + "f")
+	case float64:
+		b.WriteString(snbtFloat(p, 64, "1e309") + "d")
+	case string:
+		b.WriteString(quoteSNBT(p))
+	case []byte:
+		writeSNBTArray(b, "B", p, snbtByte, indent)
+	case RawPayload:
+		writeSNBTArray(b, "B", p, snbtByte, indent)
+	case []int32:
+		writeSNBTArray(b, "I", p, func(e int32) string { return strconv.Itoa(int(e)) }, indent)
+	case []int64:
+		writeSNBTArray(b, "L", p, func(e int64) string { return strconv.FormatInt(e, 10) + "L" }, indent)
+	case []any:
+		writeSNBTContainer(b, "[", "]", len(p), indent, depth, func(i int) {
+			writeSNBT(b, p[i], indent, depth+1)
+		})
+	case []Tag:
+		writeSNBTContainer(b, "{", "}", len(p), indent, depth, func(i int) {
+			b.WriteString(snbtKey(p[i].name))
+			b.WriteString(":")
+			if indent != "" {
+				b.WriteString(" ")
+			}
+			writeSNBT(b, p[i].payload, indent, depth+1)
+		})
+	}
+}
+
+// snbtFloat returns a float of the bit size without its suffix, writing ±Inf as the overflowing number, signed, and NaN
+// as 0.
+func snbtFloat(f float64, bitSize int, overflow string) string {
+	switch {
+	case math.IsNaN(f):
+		return "0"
+	case math.IsInf(f, 1):
+		return overflow
+	case math.IsInf(f, -1):
+		return "-" + overflow
+	default:
+		return strconv.FormatFloat(f, 'g', -1, bitSize)
+	}
+}
+
+// snbtByte returns a signed tagByte payload with its suffix.
+func snbtByte(p byte) string {
+	return strconv.Itoa(int(int8(p))) + "b" // #nosec G115 -- tagByte is signed
+}
+
+// writeSNBTArray writes an array payload with its type prefix, formatting each element.
+func writeSNBTArray[E byte | int32 | int64](b *strings.Builder, prefix string, elements []E, format func(E) string,
+	indent string) {
+	separator := ","
+	if indent != "" {
+		separator = ", "
+	}
+
+	b.WriteString("[" + prefix + ";")
+	for i, e := range elements {
+		if i == 0 && indent != "" {
+			b.WriteString(" ")
+		} else if i > 0 {
+			b.WriteString(separator)
+		}
+		b.WriteString(format(e))
+	}
+	b.WriteString("]")
+}
+
+// writeSNBTContainer writes a compound or list of n entries between the open and close brackets, writing each entry
+// with write, on its own line when indenting.
+func writeSNBTContainer(b *strings.Builder, open, close string, n int, indent string, depth int, write func(i int)) {
+	b.WriteString(open)
+	for i := range n {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		if indent != "" {
+			b.WriteString("\n" + strings.Repeat(indent, depth+1))
+		}
+		write(i)
+	}
+	if indent != "" && n > 0 {
+		b.WriteString("\n" + strings.Repeat(indent, depth))
+	}
+	b.WriteString(close)
+}
+
+// snbtKey returns a compound key, quoted if it contains characters not allowed in an unquoted key.
+func snbtKey(key string) string {
+	if key != "" && strings.IndexFunc(key, func(r rune) bool { return !isUnquotedSNBT(r) }) < 0 {
+		return key
+	}
+	return quoteSNBT(key)
+}
+
+// isUnquotedSNBT reports whether the rune may appear in an unquoted SNBT string.
+func isUnquotedSNBT(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_-.+", r)
+}
+
+// quoteSNBT quotes a string in double quotes, or in single quotes if it contains double quotes but no single quotes,
+// escaping backslashes and the quote used.
+func quoteSNBT(s string) string {
+	quote := `"`
+	if strings.Contains(s, `"`) && !strings.Contains(s, "'") {
+		quote = "'"
+	}
+	return quote + strings.NewReplacer(`\`, `\\`, quote, `\`+quote).Replace(s) + quote
+}
+
+// SNBT number patterns, as matched by Minecraft's parser against unquoted strings, case insensitively. An unquoted
+// string matching none, or an integer out of range of its type, is a tagString.
+var (
+	snbtDouble         = regexp.MustCompile(`(?i)^[-+]?(?:[0-9]+\.|[0-9]*\.[0-9]+)(?:e[-+]?[0-9]+)?$`)
+	snbtFloatSuffixed  = regexp.MustCompile(`(?i)^[-+]?(?:[0-9]+\.?|[0-9]*\.[0-9]+)(?:e[-+]?[0-9]+)?[df]$`)
+	snbtInteger        = regexp.MustCompile(`(?i)^[-+]?(?:0|[1-9][0-9]*)[bsl]?$`)
+	snbtArrayPrefixIDs = map[byte]uint8{'B': tagByteArray, 'I': tagIntArray, 'L': tagLongArray}
+)
+
+// ParseSNBT parses SNBT, as written by SNBT and accepted by Minecraft commands, into an unnamed tag. Numbers take their
+// type from their suffix, or are a tagInt or, with a decimal point or exponent, a tagDouble. true and false are the
+// tagBytes 1 and 0, and other unquoted strings are tagStrings. A float too large for its type is ±Inf. Elements of a
+// list must all have the same type.
+func ParseSNBT(s string) (t Tag, err error) {
+	p := snbtParser{s: s}
+	t, err = p.value()
+	if err == nil {
+		p.skipSpace()
+		if p.i < len(s) {
+			err = p.errorf("unexpected %q after the value", s[p.i])
+		}
+	}
+	if err != nil {
+		return Tag{}, fmt.Errorf("Unable to parse SNBT: %w", err)
+	}
+	return t, nil
+}
+
+// snbtParser parses SNBT from s, at byte offset i.
+type snbtParser struct {
+	s string
+	i int
+}
+
+// errorf returns an error at the parser's offset.
+func (p *snbtParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%v at offset %v", fmt.Sprintf(format, args...), p.i)
+}
+
+// skipSpace skips whitespace.
+func (p *snbtParser) skipSpace() {
+	for p.i < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.i]) >= 0 {
+		p.i++
+	}
+}
+
+// expect skips whitespace then the byte c, or returns an error if the next byte is another.
+func (p *snbtParser) expect(c byte) error {
+	p.skipSpace()
+	if p.i >= len(p.s) {
+		return p.errorf("expected %q, found the end", c)
+	}
+	if p.s[p.i] != c {
+		return p.errorf("expected %q, found %q", c, p.s[p.i])
+	}
+	p.i++
+	return nil
+}
+
+// peek skips whitespace and reports whether the next byte is c.
+func (p *snbtParser) peek(c byte) bool {
+	p.skipSpace()
+	return p.i < len(p.s) && p.s[p.i] == c
+}
+
+// value parses the next value.
+func (p *snbtParser) value() (t Tag, err error) {
+	p.skipSpace()
+	if p.i >= len(p.s) {
+		return Tag{}, p.errorf("expected a value, found the end")
+	}
+
+	switch p.s[p.i] {
+	case '{':
+		return p.compound()
+	case '[':
+		if p.i+2 < len(p.s) && p.s[p.i+2] == ';' {
+			if id, ok := snbtArrayPrefixIDs[p.s[p.i+1]]; ok {
+				return p.array(id)
+			}
+		}
+		return p.list()
+	case '"', '\'':
+		s, err := p.quoted()
+		return NewString("", s), err
+	default:
+		s, err := p.unquoted()
+		if err != nil {
+			return Tag{}, err
+		}
+		return snbtScalar(s), nil
+	}
+}
+
+// quoted parses a string quoted in double or single quotes, where a backslash escapes a backslash or the quote.
+func (p *snbtParser) quoted() (s string, err error) {
+	quote := p.s[p.i]
+	var b strings.Builder
+	for i := p.i + 1; i < len(p.s); i++ {
+		switch p.s[i] {
+		case '\\':
+			if i+1 == len(p.s) || (p.s[i+1] != '\\' && p.s[i+1] != quote) {
+				p.i = i
+				return "", p.errorf("invalid escape")
+			}
+			i++
+			b.WriteByte(p.s[i])
+		case quote:
+			p.i = i + 1
+			return b.String(), nil
+		default:
+			b.WriteByte(p.s[i])
+		}
+	}
+	return "", p.errorf("unterminated quoted string")
+}
+
+// unquoted parses an unquoted string of the characters allowed by isUnquotedSNBT.
+func (p *snbtParser) unquoted() (s string, err error) {
+	start := p.i
+	for p.i < len(p.s) && isUnquotedSNBT(rune(p.s[p.i])) {
+		p.i++
+	}
+	if p.i == start {
+		return "", p.errorf("unexpected %q", p.s[p.i])
+	}
+	return p.s[start:p.i], nil
+}
+
+// key parses a compound key, quoted or unquoted.
+func (p *snbtParser) key() (key string, err error) {
+	p.skipSpace()
+	if p.i < len(p.s) && (p.s[p.i] == '"' || p.s[p.i] == '\'') {
+		return p.quoted()
+	}
+	if p.i >= len(p.s) {
+		return "", p.errorf("expected a key, found the end")
+	}
+	return p.unquoted()
+}
+
+// compound parses a compound, whose keys must be unique.
+func (p *snbtParser) compound() (t Tag, err error) {
+	p.i++
+	var children []Tag
+	for !p.peek('}') {
+		key, err := p.key()
+		if err != nil {
+			return Tag{}, err
+		}
+		if err = p.expect(':'); err != nil {
+			return Tag{}, err
+		}
+		child, err := p.value()
+		if err != nil {
+			return Tag{}, err
+		}
+		child.name = key
+		children = append(children, child)
+		if !p.peek(',') {
+			break
+		}
+		p.i++
+	}
+	if err = p.expect('}'); err != nil {
+		return Tag{}, err
+	}
+
+	t, err = NewCompound("", children...)
+	if err != nil {
+		return Tag{}, p.errorf("%v", err)
+	}
+	return t, nil
+}
+
+// list parses a list, whose elements must all have the same type.
+func (p *snbtParser) list() (t Tag, err error) {
+	p.i++
+	var elements []Tag
+	for !p.peek(']') {
+		element, err := p.value()
+		if err != nil {
+			return Tag{}, err
+		}
+		if len(elements) > 0 && element.id != elements[0].id {
+			return Tag{}, p.errorf("list element of tag ID %v among elements of tag ID %v", element.id,
+				elements[0].id)
+		}
+		elements = append(elements, element)
+		if !p.peek(',') {
+			break
+		}
+		p.i++
+	}
+	if err = p.expect(']'); err != nil {
+		return Tag{}, err
+	}
+
+	var elementID uint8
+	if len(elements) > 0 {
+		elementID = elements[0].id
+	}
+	return NewList("", elementID, elements...)
+}
+
+// array parses a byte, int or long array, with its type prefix, whose elements must have the type of the array.
+func (p *snbtParser) array(id uint8) (t Tag, err error) {
+	p.i += 3
+	elementID := map[uint8]uint8{tagByteArray: tagByte, tagIntArray: tagInt, tagLongArray: tagLong}[id]
+	var bytes []byte
+	var ints []int32
+	var longs []int64
+	for !p.peek(']') {
+		element, err := p.value()
+		if err != nil {
+			return Tag{}, err
+		}
+		if element.id != elementID {
+			return Tag{}, p.errorf("array element of tag ID %v in an array of tag ID %v", element.id, elementID)
+		}
+		switch e := element.payload.(type) {
+		case byte:
+			bytes = append(bytes, e)
+		case int32:
+			ints = append(ints, e)
+		case int64:
+			longs = append(longs, e)
+		}
+		if !p.peek(',') {
+			break
+		}
+		p.i++
+	}
+	if err = p.expect(']'); err != nil {
+		return Tag{}, err
+	}
+
+	switch id {
+	case tagByteArray:
+		return NewByteArray("", bytes), nil
+	case tagIntArray:
+		return NewIntArray("", ints), nil
+	default:
+		return NewLongArray("", longs), nil
+	}
+}
+
+// snbtScalar returns the number or string an unquoted string stands for.
+func snbtScalar(s string) Tag {
+	switch {
+	case strings.EqualFold(s, "true"):
+		return NewByte("", 1)
+	case strings.EqualFold(s, "false"):
+		return NewByte("", 0)
+	case snbtFloatSuffixed.MatchString(s):
+		number, suffix := s[:len(s)-1], s[len(s)-1]|0x20
+		if suffix == 'f' {
+			if f, ok := parseSNBTFloat(number, 32); ok {
+				return NewFloat("", float32(f))
+			}
+		} else if f, ok := parseSNBTFloat(number, 64); ok {
+			return NewDouble("", f)
+		}
+	case snbtDouble.MatchString(s):
+		if f, ok := parseSNBTFloat(s, 64); ok {
+			return NewDouble("", f)
+		}
+	case snbtInteger.MatchString(s):
+		number, bitSize := s, 32
+		switch s[len(s)-1] | 0x20 {
+		case 'b':
+			number, bitSize = s[:len(s)-1], 8
+		case 's':
+			number, bitSize = s[:len(s)-1], 16
+		case 'l':
+			number, bitSize = s[:len(s)-1], 64
+		}
+		i, err := strconv.ParseInt(number, 10, bitSize)
+		if err != nil {
+			break
+		}
+		switch bitSize {
+		case 8:
+			return NewByte("", byte(int8(i))) // #nosec G115 -- tagByte is signed, parsed to 8 bits
+		case 16:
+			return NewShort("", int16(i)) // #nosec G115 -- parsed to 16 bits
+		case 64:
+			return NewLong("", i)
+		default:
+			return NewInt("", int32(i)) // #nosec G115 -- parsed to 32 bits
+		}
+	}
+	return NewString("", s)
+}
+
+// parseSNBTFloat parses a float of the bit size. A number too large for the bit size is ±Inf, as Minecraft reads it.
+// The boolean is false if the number does not parse.
+func parseSNBTFloat(s string, bitSize int) (f float64, ok bool) {
+	f, err := strconv.ParseFloat(s, bitSize)
+	return f, err == nil || errors.Is(err, strconv.ErrRange)
+}
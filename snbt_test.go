@@ -0,0 +1,151 @@
+package nbt
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestSNBT(t *testing.T) {
+	item, _ := NewCompound("", NewByte("Count", 0xFF), NewString("id", "minecraft:stone"))
+	list, _ := NewList("", IDCompound, item)
+	root, _ := NewCompound("root", list, NewShort("air key", 300), NewLong("L", -5), NewFloat("f", 1.5),
+		NewDouble("d", 2), NewIntArray("a", []int32{1, -2}), NewByteArray("ba", nil), NewLongArray("la", []int64{3}),
+		NewString("quote", `say "hi"`), NewString("both", `it's "x" \`))
+
+	successCases := []struct {
+		name   string
+		want   string
+		t      Tag
+		indent string
+	}{
+		{"int", "7", NewInt("n", 7), ""},
+		{"signed byte", "-1b", NewByte("", 0xFF), ""},
+		{"float NaN", "0f", NewFloat("", float32(math.NaN())), ""},
+		{"float infinity", "1e39f", NewFloat("", float32(math.Inf(1))), ""},
+		{"double negative infinity", "-1e309d", NewDouble("", math.Inf(-1)), ""},
+		{"large double", "1e+100d", NewDouble("", 1e100), ""},
+		{"empty compound", "{}", Tag{id: tagCompound}, "  "},
+		{"empty list", "[]", Tag{id: tagList, payload: []any(nil)}, "  "},
+		{"compact", `{"":[{Count:-1b,id:"minecraft:stone"}],"air key":300s,L:-5L,f:1.5f,d:2d,a:[I;1,-2],ba:[B;],` +
+			`la:[L;3L],quote:'say "hi"',both:"it's \"x\" \\"}`, root, ""},
+		{"indented", "{\n  Count: -1b,\n  id: \"minecraft:stone\",\n  tags: [\n    [I; 1, 2]\n  ]\n}",
+			Tag{id: tagCompound, payload: []Tag{item.payload.([]Tag)[0], item.payload.([]Tag)[1],
+				{id: tagList, name: "tags", payload: []any{[]int32{1, 2}}}}}, "  "},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			if got := SNBT(successCase.t, successCase.indent); got != successCase.want {
+				t.Errorf("got %v, want %v", got, successCase.want)
+			}
+		})
+	}
+
+	t.Run("Test success case: String is compact SNBT", func(t *testing.T) {
+		if got := fmt.Sprint(item); got != `{Count:-1b,id:"minecraft:stone"}` {
+			t.Errorf("got %v", got)
+		}
+	})
+}
+
+func TestParseSNBT(t *testing.T) {
+	item, _ := NewCompound("", NewByte("Count", 0xFF), NewString("id", "minecraft:stone"))
+	items, _ := NewList("", IDCompound, item)
+	empty, _ := NewList("", IDEnd)
+	nested, _ := NewList("", IDList, Tag{id: tagList, payload: []any{int32(1)}, elementID: tagInt})
+
+	successCases := []struct {
+		name  string
+		input string
+		want  Tag
+	}{
+		{"int", "7", NewInt("", 7)},
+		{"signed byte", "-1b", NewByte("", 0xFF)},
+		{"short", "300S", NewShort("", 300)},
+		{"long", "-5L", NewLong("", -5)},
+		{"float", "1.5f", NewFloat("", 1.5)},
+		{"double with suffix", "2d", NewDouble("", 2)},
+		{"double with point", "-.5e2", NewDouble("", -50)},
+		{"boolean", "true", NewByte("", 1)},
+		{"unquoted string", "stone_bricks", NewString("", "stone_bricks")},
+		{"int out of range", "3000000000", NewString("", "3000000000")},
+		{"quoted strings", `'say "hi"'`, NewString("", `say "hi"`)},
+		{"escapes", `"a\"b\\c"`, NewString("", `a"b\c`)},
+		{"arrays", "[I; 1, -2]", NewIntArray("", []int32{1, -2})},
+		{"empty array", "[B;]", NewByteArray("", nil)},
+		{"long array", "[L;3L]", NewLongArray("", []int64{3})},
+		{"list of compounds", `[ {Count: -1b, "id": "minecraft:stone"}, ]`, items},
+		{"empty list", "[]", empty},
+		{"nested list", "[[1]]", nested},
+		{"compound", `{"":[{Count:-1b,id:"minecraft:stone"}]}`, Tag{id: tagCompound,
+			payload: []Tag{{id: tagList, elementID: tagCompound, payload: items.payload}}}},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			got, err := ParseSNBT(successCase.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got.id != successCase.want.id || got.ElementID() != successCase.want.ElementID() ||
+				!payloadsEqual(got.payload, successCase.want.payload) {
+				t.Errorf("got %v, want %v", got, successCase.want)
+			}
+		})
+	}
+
+	t.Run("Test success case: SNBT round trips", func(t *testing.T) {
+		root, _ := NewCompound("", items, NewShort("air key", 300), NewFloat("f", 1.5), NewLongArray("la", nil),
+			NewString("both", `it's "x" \`))
+		got, err := ParseSNBT(SNBT(root, "  "))
+		if err != nil || !payloadsEqual(got.payload, root.payload) {
+			t.Errorf("got %v %v, want %v", got, err, root)
+		}
+	})
+
+	nonFinite := []struct {
+		name string
+		t    Tag
+		want Tag
+		one  Tag
+	}{
+		{"NaN float is lost", NewFloat("", float32(math.NaN())), NewFloat("", 0), NewFloat("", 1)},
+		{"infinite float", NewFloat("", float32(math.Inf(1))), NewFloat("", float32(math.Inf(1))), NewFloat("", 1)},
+		{"negative infinite float", NewFloat("", float32(math.Inf(-1))), NewFloat("", float32(math.Inf(-1))),
+			NewFloat("", 1)},
+		{"NaN double is lost", NewDouble("", math.NaN()), NewDouble("", 0), NewDouble("", 1)},
+		{"infinite double", NewDouble("", math.Inf(1)), NewDouble("", math.Inf(1)), NewDouble("", 1)},
+		{"negative infinite double", NewDouble("", math.Inf(-1)), NewDouble("", math.Inf(-1)), NewDouble("", 1)},
+	}
+	for _, nonFiniteCase := range nonFinite {
+		t.Run("Test success case: SNBT round trips "+nonFiniteCase.name+" in a list", func(t *testing.T) {
+			list, _ := NewList("", nonFiniteCase.t.id, nonFiniteCase.t, nonFiniteCase.one)
+			want, _ := NewList("", nonFiniteCase.t.id, nonFiniteCase.want, nonFiniteCase.one)
+			got, err := ParseSNBT(SNBT(list, ""))
+			if err != nil || got.ElementID() != want.ElementID() || !payloadsEqual(got.payload, want.payload) {
+				t.Errorf("got %v %v, want %v", got, err, want)
+			}
+		})
+	}
+
+	failureCases := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"trailing data", "1 2"},
+		{"unterminated compound", "{a:1"},
+		{"missing colon", "{a 1}"},
+		{"duplicate key", "{a:1,a:2}"},
+		{"mixed list", "[1,2b]"},
+		{"mixed array", "[I;1,2L]"},
+		{"unterminated string", `"abc`},
+		{"invalid escape", `"a\nb"`},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			if _, err := ParseSNBT(failureCase.input); err == nil {
+				t.Errorf("got nil, want non-nil")
+			}
+		})
+	}
+}
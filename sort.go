@@ -0,0 +1,114 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SortList returns a copy of t in which the tagList of compounds at the path is stably sorted by the child named key of
+// each compound, such as sorting Inventory by Slot, compared with CompareTags. Compounds without the key sort last, in
+// their original order. The original tree is not modified.
+func SortList(t Tag, path Path, key string) (Tag, error) {
+	return SortListFunc(t, path, func(a, b Tag) int {
+		aKey, aOK := compoundChild(a, key)
+		bKey, bOK := compoundChild(b, key)
+		if !aOK || !bOK {
+			return compareBool(aOK, bOK)
+		}
+		return CompareTags(aKey, bKey)
+	})
+}
+
+// compareBool orders true before false, so present keys sort before missing ones.
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// SortListFunc returns a copy of t in which the tagList of compounds at the path is stably sorted by cmp, which is
+// given each compound as an unnamed tagCompound. The original tree is not modified.
+func SortListFunc(t Tag, path Path, cmp func(a, b Tag) int) (Tag, error) {
+	t, err := editPath(t, path, func(list Tag) (Tag, error) {
+		if list.id != tagList {
+			return Tag{}, fmt.Errorf("tag ID %v is not a tagList", list.id)
+		}
+		elements, _ := list.payload.([]any)
+		if len(elements) == 0 {
+			return list, nil
+		}
+		if id := list.ElementID(); id != tagCompound {
+			return Tag{}, fmt.Errorf("elements are tag ID %v, not tagCompound", id)
+		}
+
+		sorted := slices.Clone(elements)
+		slices.SortStableFunc(sorted, func(a, b any) int {
+			return cmp(Tag{id: tagCompound, payload: a}, Tag{id: tagCompound, payload: b})
+		})
+		list.payload = sorted
+		return list, nil
+	})
+	if err != nil {
+		return Tag{}, fmt.Errorf("Unable to sort list %v: %w", path, err)
+	}
+	return t, nil
+}
+
+// CompareTags compares two tags by tag ID, then by payload, returning -1, 0 or +1. Numbers compare by value, with a
+// tagByte as signed, and floats as cmp.Compare orders them, with NaN first. Strings compare by bytes, and arrays
+// element by element, as do the bytes of a RawPayload. Compounds and lists compare as equal.
+func CompareTags(a, b Tag) int {
+	if a.id != b.id {
+		return cmp.Compare(a.id, b.id)
+	}
+
+	switch p := a.payload.(type) {
+	case byte:
+		q, _ := b.payload.(byte)
+		return cmp.Compare(int8(p), int8(q)) // #nosec G115 -- tagByte is signed
+	case int16:
+		return comparePayloads(p, b.payload)
+	case int32:
+		return comparePayloads(p, b.payload)
+	case int64:
+		return comparePayloads(p, b.payload)
+	case float32:
+		return comparePayloads(p, b.payload)
+	case float64:
+		return comparePayloads(p, b.payload)
+	case string:
+		q, _ := b.payload.(string)
+		return strings.Compare(p, q)
+	case []byte:
+		q, _ := b.payload.([]byte)
+		return slices.CompareFunc(p, q, func(x, y byte) int {
+			return cmp.Compare(int8(x), int8(y)) // #nosec G115 -- tagByteArray elements are signed
+		})
+	case []int32:
+		q, _ := b.payload.([]int32)
+		return slices.Compare(p, q)
+	case []int64:
+		q, _ := b.payload.([]int64)
+		return slices.Compare(p, q)
+	case RawPayload:
+		q, _ := b.payload.(RawPayload)
+		return bytes.Compare(p, q)
+	default:
+		return 0
+	}
+}
+
+// comparePayloads compares an ordered payload with another payload of the same type.
+func comparePayloads[P cmp.Ordered](a P, b any) int {
+	q, _ := b.(P)
+	return cmp.Compare(a, q)
+}
@@ -0,0 +1,103 @@
+package nbt
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSortList(t *testing.T) {
+	item := func(slot byte, id string) []Tag {
+		return []Tag{{id: tagByte, name: "Slot", payload: slot}, {id: tagString, name: "id", payload: id}}
+	}
+	player := Tag{id: tagCompound, payload: []Tag{{id: tagList, name: "Inventory", elementID: tagCompound, payload: []any{
+		item(3, "a"), []Tag{{id: tagString, name: "id", payload: "none"}}, item(0xFF, "b"), item(1, "c"), item(1, "d"),
+	}}}}
+
+	successCases := []struct {
+		name string
+		want []any
+		path Path
+		key  string
+	}{
+		{"by signed byte slot, stable, missing last", []any{
+			item(0xFF, "b"), item(1, "c"), item(1, "d"), item(3, "a"), []Tag{{id: tagString, name: "id", payload: "none"}},
+		}, Path{"Inventory"}, "Slot"},
+		{"by string", []any{
+			item(3, "a"), item(0xFF, "b"), item(1, "c"), item(1, "d"), []Tag{{id: tagString, name: "id", payload: "none"}},
+		}, Path{"Inventory"}, "id"},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			got, err := SortList(player, successCase.path, successCase.key)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			list, _ := compoundChild(got, "Inventory")
+			if !reflect.DeepEqual(list.payload, successCase.want) {
+				t.Errorf("got %v, want %v", list.payload, successCase.want)
+			}
+			if original, _ := compoundChild(player, "Inventory"); original.payload.([]any)[0].([]Tag)[1].payload != "a" {
+				t.Errorf("original modified: %v", original.payload)
+			}
+		})
+	}
+
+	t.Run("Test success case: empty list", func(t *testing.T) {
+		empty := Tag{id: tagCompound, payload: []Tag{{id: tagList, name: "l", payload: []any(nil)}}}
+		if _, err := SortList(empty, Path{"l"}, "Slot"); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	failureCases := []struct {
+		name string
+		path Path
+	}{
+		{"missing path", Path{"Items"}},
+		{"not a list", Path{}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			if _, err := SortList(player, failureCase.path, "Slot"); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+
+	t.Run("Test failure case: list of ints", func(t *testing.T) {
+		ints := Tag{id: tagCompound, payload: []Tag{{id: tagList, name: "l", payload: []any{int32(2), int32(1)}}}}
+		if _, err := SortList(ints, Path{"l"}, "Slot"); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+}
+
+func TestCompareTags(t *testing.T) {
+	successCases := []struct {
+		name string
+		want int
+		a, b Tag
+	}{
+		{"different IDs", -1, Tag{id: tagByte, payload: byte(9)}, Tag{id: tagShort, payload: int16(0)}},
+		{"signed bytes", -1, Tag{id: tagByte, payload: byte(0xFF)}, Tag{id: tagByte, payload: byte(0)}},
+		{"ints", 1, Tag{id: tagInt, payload: int32(2)}, Tag{id: tagInt, payload: int32(1)}},
+		{"NaN first", -1, Tag{id: tagDouble, payload: math.NaN()}, Tag{id: tagDouble, payload: math.Inf(-1)}},
+		{"strings", -1, Tag{id: tagString, payload: "a"}, Tag{id: tagString, payload: "b"}},
+		{"int arrays as UUIDs", 1, Tag{id: tagIntArray, payload: []int32{1, 2}}, Tag{id: tagIntArray,
+			payload: []int32{1, 1, 5}}},
+		{"array prefix first", -1, Tag{id: tagLongArray, payload: []int64(nil)}, Tag{id: tagLongArray,
+			payload: []int64{0}}},
+		{"signed byte arrays", -1, Tag{id: tagByteArray, payload: []byte{0x80}}, Tag{id: tagByteArray,
+			payload: []byte{0x7F}}},
+		{"compounds equal", 0, Tag{id: tagCompound, payload: []Tag{{id: tagByte, payload: byte(1)}}},
+			Tag{id: tagCompound, payload: []Tag(nil)}},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			if got := CompareTags(successCase.a, successCase.b); got != successCase.want {
+				t.Errorf("got %v, want %v", got, successCase.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import "fmt"
+
+// SpawnDataDataVersion is the DataVersion of Java edition 1.18, from which spawners wrap each entity in an "entity"
+// compound and SpawnPotentials use lower case "weight" and "data" rather than "Weight" and "Entity".
+const SpawnDataDataVersion = 2860
+
+// SpawnPotential is a weighted entity a spawner may choose as its next SpawnData.
+type SpawnPotential struct {
+	// Entity is the unnamed entity compound, holding at least a tagString "id".
+	Entity Tag
+	Weight int32
+}
+
+// Spawner is the configuration of a mob spawner block entity. Delays are in ticks and ranges in blocks.
+type Spawner struct {
+	// SpawnData is the unnamed entity compound spawned next, holding at least a tagString "id".
+	SpawnData       Tag
+	SpawnPotentials []SpawnPotential
+
+	Delay               int16
+	MinSpawnDelay       int16
+	MaxSpawnDelay       int16
+	SpawnCount          int16
+	MaxNearbyEntities   int16
+	RequiredPlayerRange int16
+	SpawnRange          int16
+}
+
+// fields returns pointers to the short fields of the spawner, keyed by their names.
+func (s *Spawner) fields() map[string]*int16 {
+	return map[string]*int16{"Delay": &s.Delay, "MinSpawnDelay": &s.MinSpawnDelay, "MaxSpawnDelay": &s.MaxSpawnDelay,
+		"SpawnCount": &s.SpawnCount, "MaxNearbyEntities": &s.MaxNearbyEntities,
+		"RequiredPlayerRange": &s.RequiredPlayerRange, "SpawnRange": &s.SpawnRange}
+}
+
+// spawnerFieldNames are the names of the short fields in the order they are written.
+var spawnerFieldNames = []string{"Delay", "MinSpawnDelay", "MaxSpawnDelay", "SpawnCount", "MaxNearbyEntities",
+	"RequiredPlayerRange", "SpawnRange"}
+
+// NewSpawner returns a spawner of the namespaced entity ID with the game's default delays and ranges.
+func NewSpawner(entityID string) Spawner {
+	return Spawner{
+		SpawnData:           Tag{id: tagCompound, payload: []Tag{{id: tagString, name: "id", payload: entityID}}},
+		Delay:               20,
+		MinSpawnDelay:       200,
+		MaxSpawnDelay:       800,
+		SpawnCount:          4,
+		MaxNearbyEntities:   6,
+		RequiredPlayerRange: 16,
+		SpawnRange:          4,
+	}
+}
+
+// SpawnerFromTag reads a mob spawner block entity of any DataVersion. Missing short fields are zero.
+func SpawnerFromTag(t Tag) (s Spawner, err error) {
+	if t.id != tagCompound {
+		return Spawner{}, fmt.Errorf("Unable to read spawner: tag ID %v is not a tagCompound", t.id)
+	}
+
+	fields := s.fields()
+	for _, name := range spawnerFieldNames {
+		*fields[name], _ = childPayload[int16](t, name)
+	}
+
+	if data, ok := compoundChild(t, "SpawnData"); ok {
+		s.SpawnData = withName(data, "")
+		if entity, ok := compoundChild(data, "entity"); ok {
+			s.SpawnData = withName(entity, "")
+		}
+	}
+
+	potentials, _ := childPayload[[]any](t, "SpawnPotentials")
+	for i, element := range potentials {
+		potential := Tag{id: tagCompound, payload: element}
+		data, modern := compoundChild(potential, "data")
+		var p SpawnPotential
+		if modern {
+			p.Weight, _ = childPayload[int32](potential, "weight")
+			p.Entity, modern = compoundChild(data, "entity")
+		} else {
+			p.Weight, _ = childPayload[int32](potential, "Weight")
+			p.Entity, modern = compoundChild(potential, "Entity")
+		}
+		if !modern {
+			return Spawner{}, fmt.Errorf("Unable to read spawner: spawn potential %v has no entity", i)
+		}
+		p.Entity.name = ""
+		s.SpawnPotentials = append(s.SpawnPotentials, p)
+	}
+	return s, nil
+}
+
+// SetSpawner returns a copy of the block entity compound holding the spawner in the layout of the DataVersion, see
+// SpawnDataDataVersion. Spawner children are replaced, all other children are kept. The given block entity is not
+// modified.
+func SetSpawner(t Tag, s Spawner, dataVersion int32) (Tag, error) {
+	if t.id != tagCompound {
+		return Tag{}, fmt.Errorf("Unable to set spawner: tag ID %v is not a tagCompound", t.id)
+	}
+	if _, ok := childPayload[string](s.SpawnData, "id"); !ok || s.SpawnData.id != tagCompound {
+		return Tag{}, fmt.Errorf("Unable to set spawner: SpawnData is not an entity compound with an \"id\"")
+	}
+
+	modern := dataVersion >= SpawnDataDataVersion
+	children, _ := t.payload.([]Tag)
+	fields := s.fields()
+	for _, name := range spawnerFieldNames {
+		children = withChild(children, Tag{id: tagShort, name: name, payload: *fields[name]})
+	}
+
+	data := withName(s.SpawnData, "SpawnData")
+	if modern {
+		data.payload = []Tag{withName(s.SpawnData, "entity")}
+	}
+	children = withChild(children, data)
+
+	var potentials []any
+	for i, p := range s.SpawnPotentials {
+		if _, ok := childPayload[string](p.Entity, "id"); !ok || p.Entity.id != tagCompound {
+			return Tag{}, fmt.Errorf("Unable to set spawner: spawn potential %v is not an entity compound with an "+
+				"\"id\"", i)
+		}
+		if modern {
+			potentials = append(potentials, []Tag{
+				{id: tagInt, name: "weight", payload: p.Weight},
+				{id: tagCompound, name: "data", payload: []Tag{withName(p.Entity, "entity")}},
+			})
+		} else {
+			potentials = append(potentials, []Tag{
+				withName(p.Entity, "Entity"),
+				{id: tagInt, name: "Weight", payload: p.Weight},
+			})
+		}
+	}
+	children = withChild(children, Tag{id: tagList, elementID: tagCompound, name: "SpawnPotentials",
+		payload: potentials})
+
+	t.payload = children
+	return t, nil
+}
+
+// BlockEntity returns the spawner as a mob spawner block entity at the block position.
+func (s Spawner) BlockEntity(x, y, z int32, dataVersion int32) (Tag, error) {
+	return SetSpawner(Tag{id: tagCompound, payload: []Tag{
+		{id: tagString, name: "id", payload: "minecraft:mob_spawner"},
+		{id: tagInt, name: "x", payload: x},
+		{id: tagInt, name: "y", payload: y},
+		{id: tagInt, name: "z", payload: z},
+	}}, s, dataVersion)
+}
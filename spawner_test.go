@@ -0,0 +1,79 @@
+package nbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSpawner(t *testing.T) {
+	spawner := NewSpawner("minecraft:zombie")
+	spawner.SpawnPotentials = []SpawnPotential{
+		{Entity: spawner.SpawnData, Weight: 3},
+		{Entity: Tag{id: tagCompound, payload: []Tag{
+			{id: tagString, name: "id", payload: "minecraft:skeleton"},
+			{id: tagByte, name: "PersistenceRequired", payload: byte(1)},
+		}}, Weight: 1},
+	}
+
+	tests := []struct {
+		name        string
+		dataVersion int32
+		wantPath    Path
+	}{
+		{
+			name:        "Test success case: entity wrapped",
+			dataVersion: SpawnDataDataVersion,
+			wantPath:    Path{"SpawnPotentials", 1, "data", "entity", "PersistenceRequired"},
+		},
+		{
+			name:        "Test success case: legacy",
+			dataVersion: SpawnDataDataVersion - 1,
+			wantPath:    Path{"SpawnPotentials", 1, "Entity", "PersistenceRequired"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := spawner.BlockEntity(1, 64, -3, tt.dataVersion)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if _, err := lookup(got, tt.wantPath); err != nil {
+				t.Errorf("got %+v, want %v: %v", got, tt.wantPath, err)
+			}
+			if y, _ := childPayload[int32](got, "y"); y != 64 {
+				t.Errorf("got y %v, want 64", y)
+			}
+
+			reread, err := SpawnerFromTag(got)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(reread, spawner) {
+				t.Errorf("got %+v, want %+v", reread, spawner)
+			}
+		})
+	}
+
+	t.Run("Test failure case: no entity id", func(t *testing.T) {
+		if _, err := (Spawner{}).BlockEntity(0, 0, 0, SpawnDataDataVersion); err == nil {
+			t.Errorf("got no error, want error")
+		}
+		bad := NewSpawner("minecraft:pig")
+		bad.SpawnPotentials = []SpawnPotential{{Entity: Tag{id: tagCompound}, Weight: 1}}
+		if _, err := bad.BlockEntity(0, 0, 0, SpawnDataDataVersion); err == nil {
+			t.Errorf("got no error, want error")
+		}
+	})
+
+	t.Run("Test failure case: potential without entity", func(t *testing.T) {
+		bad := Tag{id: tagCompound, payload: []Tag{{id: tagList, elementID: tagCompound, name: "SpawnPotentials",
+			payload: []any{[]Tag{{id: tagInt, name: "weight", payload: int32(1)}}}}}}
+		if _, err := SpawnerFromTag(bad); err == nil {
+			t.Errorf("got no error, want error")
+		}
+		if _, err := SpawnerFromTag(Tag{id: tagString}); err == nil {
+			t.Errorf("got no error, want error")
+		}
+	})
+}
@@ -0,0 +1,163 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Stats summarises the shape of one or more tag trees, to help find what is bloating a file or a world.
+type Stats struct {
+	// Counts is the number of tags of each type, keyed by type name ("tagInt", "tagCompound", ...). List elements are
+	// counted as tags of the listed type.
+	Counts map[string]int
+	// Bytes is the encoded size of the tags at each path prefix, keyed by the text form of the path. Prefixes follow
+	// compound children only, down to the prefix depth given to Analyze. The root has the empty path "".
+	Bytes map[string]int64
+	// Largest are the longest array and list tags found, longest first.
+	Largest []ArrayStats
+	// Depth is the deepest nesting of compounds and lists, a lone root tag has depth 0.
+	Depth int
+	// DeepestPath is the path of the first tag found at the deepest nesting.
+	DeepestPath Path
+}
+
+// ArrayStats describes an array or list tag.
+type ArrayStats struct {
+	Path   Path
+	Type   string
+	Length int
+	Bytes  int64
+}
+
+// Analyze returns the statistics of a tag tree. Encoded sizes are recorded for path prefixes up to prefixDepth compound
+// names deep, and the top largest arrays and lists are kept. Use Merge to combine the statistics of every file in a
+// world.
+func Analyze(t Tag, prefixDepth, top int) Stats {
+	s := Stats{Counts: map[string]int{}, Bytes: map[string]int64{}}
+	s.analyze(t, Path{}, true, prefixDepth, top)
+	return s
+}
+
+// Merge adds the statistics of other to s, keeping the largest of both sets of arrays up to the larger of their
+// lengths.
+func (s *Stats) Merge(other Stats) {
+	if s.Counts == nil {
+		s.Counts = map[string]int{}
+	}
+	if s.Bytes == nil {
+		s.Bytes = map[string]int64{}
+	}
+
+	for tagType, count := range other.Counts {
+		s.Counts[tagType] += count
+	}
+	for prefix, size := range other.Bytes {
+		s.Bytes[prefix] += size
+	}
+
+	top := max(len(s.Largest), len(other.Largest))
+	s.Largest = append(s.Largest, other.Largest...)
+	s.sortLargest(top)
+
+	if other.Depth > s.Depth {
+		s.Depth, s.DeepestPath = other.Depth, other.DeepestPath
+	}
+}
+
+// analyze records t and its descendants. Prefix is false once the path has passed through a list, after which sizes
+// are no longer recorded by path.
+func (s *Stats) analyze(t Tag, path Path, prefix bool, prefixDepth, top int) {
+	tagType, _ := t.tagType()
+	s.Counts[tagType]++
+
+	if prefix && len(path) <= prefixDepth {
+		s.Bytes[path.String()] += encodedSize(t)
+	}
+
+	if len(path) > s.Depth {
+		s.Depth, s.DeepestPath = len(path), path
+	}
+
+	switch p := t.payload.(type) {
+	case []Tag:
+		for _, child := range p {
+			s.analyze(child, appendPath(path, child.name), prefix, prefixDepth, top)
+		}
+	case []any:
+		s.addLargest(ArrayStats{Path: path, Type: tagType, Length: len(p), Bytes: encodedPayloadSize(p)}, top)
+		for i, element := range p {
+			id, err := payloadID(element)
+			if err != nil {
+				continue
+			}
+			s.analyze(Tag{id: id, payload: element}, appendPath(path, i), false, prefixDepth, top)
+		}
+	case []byte:
+		s.addLargest(ArrayStats{Path: path, Type: tagType, Length: len(p), Bytes: encodedPayloadSize(p)}, top)
+	case []int32:
+		s.addLargest(ArrayStats{Path: path, Type: tagType, Length: len(p), Bytes: encodedPayloadSize(p)}, top)
+	case []int64:
+		s.addLargest(ArrayStats{Path: path, Type: tagType, Length: len(p), Bytes: encodedPayloadSize(p)}, top)
+	}
+}
+
+// addLargest records an array, keeping only the top largest.
+func (s *Stats) addLargest(a ArrayStats, top int) {
+	if top <= 0 {
+		return
+	}
+	s.Largest = append(s.Largest, a)
+	s.sortLargest(top)
+}
+
+// sortLargest orders the largest arrays longest first, then by size, and drops all but the top.
+func (s *Stats) sortLargest(top int) {
+	slices.SortStableFunc(s.Largest, func(a, b ArrayStats) int {
+		return cmp.Or(cmp.Compare(b.Length, a.Length), cmp.Compare(b.Bytes, a.Bytes))
+	})
+	if len(s.Largest) > top {
+		s.Largest = s.Largest[:top]
+	}
+}
+
+// encodedSize returns the number of bytes t occupies when encoded as a named tag.
+func encodedSize(t Tag) int64 {
+	if t.id == tagEnd {
+		return 1
+	}
+	return 1 + 2 + int64(len(t.name)) + encodedPayloadSize(t.payload)
+}
+
+// encodedPayloadSize returns the number of bytes a payload occupies when encoded.
+func encodedPayloadSize(payload any) (size int64) {
+	switch p := payload.(type) {
+	case byte:
+		size = 1
+	case int16:
+		size = 2
+	case int32, float32:
+		size = 4
+	case int64, float64:
+		size = 8
+	case []byte:
+		size = 4 + int64(len(p))
+	case string:
+		size = 2 + int64(len(p))
+	case []any:
+		size = 1 + 4
+		for _, element := range p {
+			size += encodedPayloadSize(element)
+		}
+	case []Tag:
+		size = 1
+		for _, child := range p {
+			size += encodedSize(child)
+		}
+	case []int32:
+		size = 4 + 4*int64(len(p))
+	case []int64:
+		size = 4 + 8*int64(len(p))
+	}
+	return size
+}
@@ -0,0 +1,105 @@
+package nbt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func TestEncodedSize(t *testing.T) {
+	successCases := []struct {
+		name  string
+		input []byte
+	}{
+		{"tagEnd", []byte{0x00}},
+		{"tagByte", []byte{0x01, 0x01, 0x00, 0x61, 0x7B}},
+		{"tagDouble", []byte{0x06, 0x00, 0x00, 0x38, 0x32, 0x8F, 0xFC, 0xC1, 0xC0, 0xF3, 0x3F}},
+		{"tagLongArray", []byte{0x0C, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x14, 0x1A, 0x99, 0xBE, 0x1C, 0x00, 0x00,
+			0x00}},
+		{"compound with string, int and list", []byte{0x0A, 0x00, 0x00,
+			0x08, 0x04, 0x00, 0x4E, 0x61, 0x6D, 0x65, 0x05, 0x00, 0x53, 0x74, 0x65, 0x76, 0x65,
+			0x03, 0x05, 0x00, 0x53, 0x63, 0x6F, 0x72, 0x65, 0x0A, 0x00, 0x00, 0x00,
+			0x09, 0x03, 0x00, 0x50, 0x6F, 0x73, 0x05, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0xC0, 0x3F, 0x00, 0x00, 0x80,
+			0x42, 0x00}},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			tag, err := ReadTag(bytes.NewBuffer(successCase.input), WithByteOrder(binary.LittleEndian))
+			if err != nil {
+				t.Fatalf("Unable to read input: %v", err)
+			}
+			gotSize := encodedSize(tag)
+			if gotSize != int64(len(successCase.input)) {
+				t.Errorf("got %v, want %v", gotSize, len(successCase.input))
+			}
+		})
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	level := Tag{id: tagCompound, payload: []Tag{
+		{id: tagCompound, name: "Data", payload: []Tag{
+			{id: tagString, name: "LevelName", payload: "abc"},
+			{id: tagList, name: "Pos", payload: []any{float64(1), float64(2), float64(3)}},
+			{id: tagByteArray, name: "Blocks", payload: []byte{1, 2, 3, 4}},
+			{id: tagList, name: "Items", payload: []any{[]Tag{
+				{id: tagIntArray, name: "UUID", payload: []int32{1, 2, 3, 4, 5}},
+			}}},
+		}},
+	}}
+
+	t.Run("Test success case: counts", func(t *testing.T) {
+		got := Analyze(level, 1, 3).Counts
+		want := map[string]int{"tagCompound": 3, "tagString": 1, "tagList": 2, "tagDouble": 3, "tagByteArray": 1,
+			"tagIntArray": 1}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Test success case: bytes by prefix", func(t *testing.T) {
+		got := Analyze(level, 1, 3).Bytes
+		want := map[string]int64{"": encodedSize(level), "Data": encodedSize(level.payload.([]Tag)[0])}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Test success case: largest arrays", func(t *testing.T) {
+		got := Analyze(level, 0, 2).Largest
+		want := []ArrayStats{
+			{Path{"Data", "Items", 0, "UUID"}, "tagIntArray", 5, 24},
+			{Path{"Data", "Blocks"}, "tagByteArray", 4, 8},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Test success case: deepest nesting", func(t *testing.T) {
+		s := Analyze(level, 0, 0)
+		if s.Depth != 4 || s.DeepestPath.String() != "Data.Items[0].UUID" {
+			t.Errorf("got %v at %v, want 4 at Data.Items[0].UUID", s.Depth, s.DeepestPath)
+		}
+		if s.Largest != nil {
+			t.Errorf("got %v, want nil", s.Largest)
+		}
+	})
+
+	t.Run("Test success case: merge", func(t *testing.T) {
+		var s Stats
+		s.Merge(Analyze(level, 1, 1))
+		big := Tag{id: tagCompound, payload: []Tag{{id: tagLongArray, name: "Big", payload: make([]int64, 10)}}}
+		s.Merge(Analyze(big, 1, 1))
+		if s.Counts["tagCompound"] != 4 {
+			t.Errorf("got %v, want 4", s.Counts["tagCompound"])
+		}
+		if len(s.Largest) != 1 || s.Largest[0].Length != 10 {
+			t.Errorf("got %v, want the 10 element array", s.Largest)
+		}
+		if s.Depth != 4 {
+			t.Errorf("got %v, want 4", s.Depth)
+		}
+	})
+}
@@ -0,0 +1,188 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// Decoder reads a stream of tags from an input, configured once by its options.
+type Decoder struct {
+	r    io.Reader
+	opts []Option
+	// input is the decompressed input, opened on the first Decode or Token so a compressed stream is decompressed
+	// once, and o the options it is read with.
+	input *inputReader
+	o     Options
+	// deadline enforces the ReadTimeout and Timeout of the options on the input, before decompression, or is nil
+	// without them.
+	deadline *deadlineReader
+	// stack holds the compounds and lists the tokens read by Token are within, innermost last, and next is the ID of the
+	// payload Token reads next, or tagEnd if the next token is a TagHeader or End.
+	stack []tokenFrame
+	next  uint8
+}
+
+// NewDecoder returns a Decoder reading from r with the options, as ReadTag does. A compressed input is decompressed as
+// a whole, so the tags follow each other inside the compression.
+func NewDecoder(r io.Reader, opts ...Option) *Decoder {
+	return &Decoder{r: r, opts: opts}
+}
+
+// Decode reads the next tag and stores it in the value pointed to by v, see UnmarshalTag. A *Tag receives the tag as
+// read, name included, and is the only value Decode stores in when built with the nbt_noreflect tag. At the end of
+// the input, Decode returns io.EOF. Decode reads whole tags, so must not be called part way through the tokens of a tag
+// read with Token.
+func (d *Decoder) Decode(v any) error {
+	defer d.clearDeadline()
+	if err := d.open(); err != nil {
+		return fmt.Errorf("Unable to decode: %w", err)
+	}
+	if d.next != tagEnd || len(d.stack) > 0 {
+		return fmt.Errorf("Unable to decode: the tokens of a tag are part read by Token")
+	}
+
+	start := d.input.read
+	t, err := ReadTag(d.input, append(slices.Clip(d.opts), WithCompression(CompressionNone), allowTrailingData,
+		decoderTimeouts)...)
+	if errors.Is(err, io.EOF) && d.input.read == start {
+		return io.EOF
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to decode: %w", err)
+	}
+	return unmarshalAny(t, v)
+}
+
+// allowTrailingData is an Option ignoring the bytes after each tag Decode reads, which are the tags following it.
+func allowTrailingData(o *Options) {
+	o.RejectTrailingData = false
+}
+
+// decoderTimeouts is an Option leaving the timeouts to the Decoder, which applies them to its input as a whole.
+func decoderTimeouts(o *Options) {
+	o.ReadTimeout, o.Timeout = 0, 0
+}
+
+// open decompresses the input, if not already opened, with the timeouts of the options applied to the input as read.
+// Between tags, it starts the Timeout of the next tag.
+func (d *Decoder) open() error {
+	if d.input == nil {
+		d.o = newOptions(d.opts)
+		r := d.r
+		if d.o.ReadTimeout > 0 || d.o.Timeout > 0 {
+			d.deadline = newDeadlineReader(d.r, d.o)
+			r = d.deadline
+		}
+		decompressed, err := decompress(r, d.o.Compression)
+		if err != nil {
+			return err
+		}
+		d.input = newInputReader(decompressed, d.o.Compression == CompressionNone)
+	}
+
+	if d.deadline != nil && d.next == tagEnd && len(d.stack) == 0 {
+		d.deadline.restart(d.o.Timeout)
+	}
+	return nil
+}
+
+// clearDeadline removes the read deadline the decoder set on its input, so reads by the caller between calls are not
+// cut short.
+func (d *Decoder) clearDeadline() {
+	if d.deadline != nil {
+		d.deadline.clear()
+	}
+}
+
+// Encoder writes a stream of tags to an output, configured once by its options.
+type Encoder struct {
+	w    io.Writer
+	opts []Option
+	// output is the compressed output, opened on the first Encode or WriteToken so the stream is compressed as a whole,
+	// and o the options it is written with.
+	output io.WriteCloser
+	o      Options
+	// stack holds the compounds and lists the tokens written by WriteToken are within, innermost last, and next is the
+	// ID of the payload WriteToken expects next, or tagEnd if a TagHeader or End is expected.
+	stack []tokenFrame
+	next  uint8
+}
+
+// NewEncoder returns an Encoder writing to w with the options, as WriteTag does. A compressed output is compressed as a
+// whole, so the tags follow each other inside the compression, and Close must be called to finish it.
+func NewEncoder(w io.Writer, opts ...Option) *Encoder {
+	return &Encoder{w: w, opts: opts}
+}
+
+// Encode writes v as the next tag, see MarshalTag. A Tag or *Tag is written as is, name included, and is the only value
+// Encode writes when built with the nbt_noreflect tag. Encode writes whole tags, so must not be called part way through
+// the tokens of a tag written with WriteToken.
+func (e *Encoder) Encode(v any) (err error) {
+	if err = e.open(); err != nil {
+		return fmt.Errorf("Unable to encode: %w", err)
+	}
+	if e.next != tagEnd || len(e.stack) > 0 {
+		return fmt.Errorf("Unable to encode: the tokens of a tag are part written by WriteToken")
+	}
+
+	var t Tag
+	switch p := v.(type) {
+	case Tag:
+		t = p
+	case *Tag:
+		t = *p
+	default:
+		t, err = marshalAny(v)
+		if err != nil {
+			return fmt.Errorf("Unable to encode: %w", err)
+		}
+	}
+
+	// The output is compressed and checksummed as a whole, not per tag.
+	err = WriteTag(e.output, t, append(slices.Clip(e.opts), WithCompression(CompressionNone), WithChecksum(nil))...)
+	if err != nil {
+		return fmt.Errorf("Unable to encode: %w", err)
+	}
+	return nil
+}
+
+// open opens the compressed output, if not already opened.
+func (e *Encoder) open() (err error) {
+	if e.output != nil {
+		return nil
+	}
+
+	e.o = newOptions(e.opts)
+	e.output, err = compress(checksumWriter(e.w, e.o), e.o.Compression, e.o.CompressionLevel)
+	return err
+}
+
+// Sum appends the digest of the output written so far to b, from the Checksum set by WithChecksum, or returns nil
+// without one. The digest of a compressed output is only complete once the Encoder is closed, flushing the compression.
+func (e *Encoder) Sum(b []byte) []byte {
+	checksum := newOptions(e.opts).Checksum
+	if checksum == nil {
+		return nil
+	}
+	return checksum.Sum(b)
+}
+
+// Close finishes a compressed output, flushing the compression. It does not close the underlying writer. An
+// uncompressed output needs no Close. A tag whose tokens are part written by WriteToken is an error.
+func (e *Encoder) Close() error {
+	if e.next != tagEnd || len(e.stack) > 0 {
+		return fmt.Errorf("Unable to close encoder: the tokens of a tag are part written by WriteToken")
+	}
+	if e.output == nil {
+		return nil
+	}
+
+	err := e.output.Close()
+	if err != nil {
+		return fmt.Errorf("Unable to close encoder: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+//go:build !nbt_noreflect
+
+package nbt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"hash/crc32"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestEncoderDecoder(t *testing.T) {
+	type point struct{ X, Y int32 }
+	named := NewString("name", "value")
+
+	t.Run("Test success case: stream of values and tags", func(t *testing.T) {
+		var b bytes.Buffer
+		e := NewEncoder(&b, BedrockEdition)
+		for _, v := range []any{point{1, 2}, &point{3, 4}, named} {
+			if err := e.Encode(v); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+
+		d := NewDecoder(&b, BedrockEdition)
+		var p1, p2 point
+		var got Tag
+		for _, v := range []any{&p1, &p2, &got} {
+			if err := d.Decode(v); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		if p1 != (point{1, 2}) || p2 != (point{3, 4}) || got.name != "name" || got.payload != "value" {
+			t.Errorf("got %v %v %+v", p1, p2, got)
+		}
+		if err := d.Decode(&got); err != io.EOF {
+			t.Errorf("got %v, want io.EOF", err)
+		}
+	})
+
+	t.Run("Test success case: gzip stream decompressed once", func(t *testing.T) {
+		var raw bytes.Buffer
+		e := NewEncoder(&raw)
+		_ = e.Encode(point{1, 2})
+		_ = e.Encode(point{3, 4})
+		var compressed bytes.Buffer
+		w := gzip.NewWriter(&compressed)
+		_, _ = w.Write(raw.Bytes())
+		_ = w.Close()
+
+		d := NewDecoder(&compressed, WithCompression(CompressionGzip))
+		var p point
+		for _, want := range []point{{1, 2}, {3, 4}} {
+			if err := d.Decode(&p); err != nil || p != want {
+				t.Errorf("got %v %v, want %v", p, err, want)
+			}
+		}
+		if err := d.Decode(&p); err != io.EOF {
+			t.Errorf("got %v, want io.EOF", err)
+		}
+	})
+
+	t.Run("Test success case: zlib output compressed as a whole", func(t *testing.T) {
+		var b bytes.Buffer
+		e := NewEncoder(&b, WithCompression(CompressionZlib))
+		_ = e.Encode(point{1, 2})
+		_ = e.Encode(point{3, 4})
+		if err := e.Close(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		d := NewDecoder(&b, WithCompression(CompressionZlib))
+		var p point
+		for _, want := range []point{{1, 2}, {3, 4}} {
+			if err := d.Decode(&p); err != nil || p != want {
+				t.Errorf("got %v %v, want %v", p, err, want)
+			}
+		}
+	})
+
+	t.Run("Test failure case: truncated tag is not io.EOF", func(t *testing.T) {
+		d := NewDecoder(bytes.NewReader([]byte{tagCompound, 0}))
+		var got Tag
+		if err := d.Decode(&got); err == nil || err == io.EOF {
+			t.Errorf("got %v, want an error other than io.EOF", err)
+		}
+	})
+
+	t.Run("Test failure case: corrupt gzip", func(t *testing.T) {
+		d := NewDecoder(bytes.NewReader([]byte{1, 2, 3}), WithCompression(CompressionGzip))
+		if err := d.Decode(new(Tag)); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+
+	t.Run("Test failure case: decode into wrong type", func(t *testing.T) {
+		d := NewDecoder(bytes.NewReader([]byte{tagInt, 0, 0, 0, 0, 0, 1}))
+		if err := d.Decode(new(string)); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+
+	failureCases := []struct {
+		name string
+		v    any
+		opts []Option
+	}{
+		{"unsupported value", make(chan int), nil},
+		{"invalid compression level", point{}, []Option{WithCompression(CompressionGzip), WithCompressionLevel(42)}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			if err := NewEncoder(&bytes.Buffer{}, failureCase.opts...).Encode(failureCase.v); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestNetworkFormat(t *testing.T) {
+	tag, _ := NewCompound("root", Tag{id: tagCompound, name: "c", payload: []Tag{NewByte("b", 1)}})
+	encoded := []byte{tagCompound, tagCompound, 0, 1, 'c', tagByte, 0, 1, 'b', 1, tagEnd, tagEnd}
+
+	t.Run("Test success case: encode drops the root name", func(t *testing.T) {
+		var b bytes.Buffer
+		if err := NewEncoder(&b, WithNetworkFormat(true)).Encode(tag); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !bytes.Equal(b.Bytes(), encoded) {
+			t.Errorf("got % X, want % X", b.Bytes(), encoded)
+		}
+	})
+
+	t.Run("Test success case: decode stream of nameless roots", func(t *testing.T) {
+		d := NewDecoder(bytes.NewReader(append(slices.Clone(encoded), encoded...)), WithNetworkFormat(true))
+		for range 2 {
+			var got Tag
+			if err := d.Decode(&got); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got.name != "" || !payloadsEqual(got.payload, tag.payload) {
+				t.Errorf("got %v, want nameless %v", got, tag)
+			}
+		}
+		if err := d.Decode(&Tag{}); err != io.EOF {
+			t.Errorf("got %v, want io.EOF", err)
+		}
+	})
+
+	t.Run("Test failure case: nameless root read with a name", func(t *testing.T) {
+		if _, err := ReadTag(bytes.NewReader(encoded)); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+}
+
+func TestEncoderChecksum(t *testing.T) {
+	tag := NewString("name", "value")
+
+	for _, compression := range []Compression{CompressionNone, CompressionGzip} {
+		t.Run("Test success case: digest of the output, "+compression.String(), func(t *testing.T) {
+			var b bytes.Buffer
+			checksum := sha256.New()
+			e := NewEncoder(&b, WithCompression(compression), WithChecksum(checksum))
+			for range 2 {
+				if err := e.Encode(tag); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+			if err := e.Close(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			want := sha256.Sum256(b.Bytes())
+			if got := e.Sum(nil); !bytes.Equal(got, want[:]) {
+				t.Errorf("got %x, want %x", got, want)
+			}
+		})
+	}
+
+	t.Run("Test success case: tokens are checksummed", func(t *testing.T) {
+		var b bytes.Buffer
+		checksum := crc32.NewIEEE()
+		e := NewEncoder(&b, WithChecksum(checksum))
+		if err := e.WriteToken(TagHeader{ID: IDInt, Name: "i"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := e.WriteToken(ScalarValue{ID: IDInt, Value: int32(7)}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got, want := checksum.Sum32(), crc32.ChecksumIEEE(b.Bytes()); got != want {
+			t.Errorf("got %x, want %x", got, want)
+		}
+	})
+
+	t.Run("Test success case: no checksum", func(t *testing.T) {
+		e := NewEncoder(&bytes.Buffer{})
+		if err := e.Encode(tag); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := e.Sum(nil); got != nil {
+			t.Errorf("got %x, want nil", got)
+		}
+	})
+}
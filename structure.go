@@ -0,0 +1,140 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// BlockState is a block and its state properties, an entry of a block state palette.
+type BlockState struct {
+	// Name is the namespaced block ID, such as "minecraft:oak_stairs".
+	Name string
+	// Properties are the block state properties, such as facing=east. Nil and empty are the same.
+	Properties map[string]string
+}
+
+// String returns the block state in the form used by commands, such as minecraft:oak_stairs[facing=east,half=top],
+// with properties sorted by name.
+func (b BlockState) String() string {
+	if len(b.Properties) == 0 {
+		return b.Name
+	}
+
+	properties := make([]string, 0, len(b.Properties))
+	for _, name := range slices.Sorted(maps.Keys(b.Properties)) {
+		properties = append(properties, name+"="+b.Properties[name])
+	}
+	return b.Name + "[" + strings.Join(properties, ",") + "]"
+}
+
+// Tag returns the block state as an unnamed palette entry compound, with properties sorted by name.
+func (b BlockState) Tag() Tag {
+	children := []Tag{{id: tagString, name: "Name", payload: b.Name}}
+	if len(b.Properties) > 0 {
+		var properties []Tag
+		for _, name := range slices.Sorted(maps.Keys(b.Properties)) {
+			properties = append(properties, Tag{id: tagString, name: name, payload: b.Properties[name]})
+		}
+		children = append(children, Tag{id: tagCompound, name: "Properties", payload: properties})
+	}
+	return Tag{id: tagCompound, payload: children}
+}
+
+// BlockStateFromTag reads a palette entry compound.
+func BlockStateFromTag(t Tag) (b BlockState, err error) {
+	var ok bool
+	b.Name, ok = childPayload[string](t, "Name")
+	if t.id != tagCompound || !ok {
+		return BlockState{}, fmt.Errorf("Unable to read block state: no tagString named \"Name\"")
+	}
+
+	if properties, ok := compoundChild(t, "Properties"); ok {
+		b.Properties, err = stringMap(properties)
+		if err != nil {
+			return BlockState{}, fmt.Errorf("Unable to read block state %v: %w", b.Name, err)
+		}
+	}
+	return b, nil
+}
+
+// BlockPos is the position of a block.
+type BlockPos struct {
+	X, Y, Z int32
+}
+
+// elements returns the position as a tagList payload of its coordinates.
+func (p BlockPos) elements() []any {
+	return []any{p.X, p.Y, p.Z}
+}
+
+// StructureTemplate returns the root compound of a structure template, as saved by structure blocks, holding the
+// blocks indexed [x][y][z]. The blocks must form a cuboid, and a block with an empty Name is structure void, which is
+// not stored. The palette holds each distinct block state once, in the order first used. Block entities, keyed by
+// the position of their block within the structure, are stored as the nbt of that block. The template has no
+// entities.
+func StructureTemplate(blocks [][][]BlockState, blockEntities map[BlockPos]Tag, dataVersion int32) (Tag, error) {
+	size := BlockPos{X: int32(len(blocks))} // #nosec G115 -- a structure is far smaller than the int32 range
+	if len(blocks) > 0 {
+		size.Y = int32(len(blocks[0])) // #nosec G115 -- as above
+		if len(blocks[0]) > 0 {
+			size.Z = int32(len(blocks[0][0])) // #nosec G115 -- as above
+		}
+	}
+
+	var palette, elements []any
+	indices := map[string]int32{}
+	placed := map[BlockPos]bool{}
+	for x, plane := range blocks {
+		for y, row := range plane {
+			if len(plane) != int(size.Y) || len(row) != int(size.Z) {
+				return Tag{}, fmt.Errorf("Unable to build structure template: blocks are not a %v by %v by %v cuboid",
+					size.X, size.Y, size.Z)
+			}
+			for z, block := range row {
+				if block.Name == "" {
+					continue
+				}
+
+				key := block.String()
+				index, ok := indices[key]
+				if !ok {
+					index = int32(len(palette)) // #nosec G115 -- as above
+					indices[key] = index
+					palette = append(palette, block.Tag().payload)
+				}
+
+				pos := BlockPos{X: int32(x), Y: int32(y), Z: int32(z)} // #nosec G115 -- as above
+				placed[pos] = true
+				element := []Tag{
+					{id: tagInt, name: "state", payload: index},
+					{id: tagList, elementID: tagInt, name: "pos", payload: pos.elements()},
+				}
+				if entity, ok := blockEntities[pos]; ok {
+					if entity.id != tagCompound {
+						return Tag{}, fmt.Errorf("Unable to build structure template: block entity at %v is not a "+
+							"tagCompound", pos)
+					}
+					element = append(element, withName(entity, "nbt"))
+				}
+				elements = append(elements, element)
+			}
+		}
+	}
+
+	for pos := range blockEntities {
+		if !placed[pos] {
+			return Tag{}, fmt.Errorf("Unable to build structure template: block entity at %v has no block", pos)
+		}
+	}
+
+	return Tag{id: tagCompound, payload: []Tag{
+		{id: tagInt, name: "DataVersion", payload: dataVersion},
+		{id: tagList, elementID: tagInt, name: "size", payload: size.elements()},
+		{id: tagList, elementID: tagCompound, name: "palette", payload: palette},
+		{id: tagList, elementID: tagCompound, name: "blocks", payload: elements},
+		{id: tagList, elementID: tagCompound, name: "entities"},
+	}}, nil
+}
@@ -0,0 +1,110 @@
+package nbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBlockState(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      BlockState
+		wantString string
+	}{
+		{
+			name:       "Test success case: no properties",
+			input:      BlockState{Name: "minecraft:stone"},
+			wantString: "minecraft:stone",
+		},
+		{
+			name: "Test success case: sorted properties",
+			input: BlockState{Name: "minecraft:oak_stairs",
+				Properties: map[string]string{"half": "top", "facing": "east"}},
+			wantString: "minecraft:oak_stairs[facing=east,half=top]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.input.String(); got != tt.wantString {
+				t.Errorf("got %v, want %v", got, tt.wantString)
+			}
+			got, err := BlockStateFromTag(tt.input.Tag())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.input) {
+				t.Errorf("got %+v, want %+v", got, tt.input)
+			}
+		})
+	}
+
+	t.Run("Test failure case: no name", func(t *testing.T) {
+		if _, err := BlockStateFromTag(Tag{id: tagCompound}); err == nil {
+			t.Errorf("got no error, want error")
+		}
+	})
+
+	t.Run("Test failure case: property not a string", func(t *testing.T) {
+		input := Tag{id: tagCompound, payload: []Tag{
+			{id: tagString, name: "Name", payload: "minecraft:lever"},
+			{id: tagCompound, name: "Properties", payload: []Tag{{id: tagByte, name: "powered", payload: byte(1)}}},
+		}}
+		if _, err := BlockStateFromTag(input); err == nil {
+			t.Errorf("got no error, want error")
+		}
+	})
+}
+
+func TestStructureTemplate(t *testing.T) {
+	stone := BlockState{Name: "minecraft:stone"}
+	chest := BlockState{Name: "minecraft:chest", Properties: map[string]string{"facing": "north"}}
+	blocks := [][][]BlockState{
+		{{stone, {}}, {chest, stone}},
+		{{stone, stone}, {{}, {}}},
+	}
+	items := Tag{id: tagCompound, name: "ignored", payload: []Tag{{id: tagString, name: "id",
+		payload: "minecraft:chest"}}}
+
+	got, err := StructureTemplate(blocks, map[BlockPos]Tag{{X: 0, Y: 1, Z: 0}: items}, 3465)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if size, _ := childPayload[[]any](got, "size"); !reflect.DeepEqual(size, []any{int32(2), int32(2), int32(2)}) {
+		t.Errorf("got size %v, want 2 by 2 by 2", size)
+	}
+	if palette, _ := childPayload[[]any](got, "palette"); len(palette) != 2 {
+		t.Errorf("got palette %+v, want stone and chest", palette)
+	}
+	placed, _ := childPayload[[]any](got, "blocks")
+	if len(placed) != 5 {
+		t.Fatalf("got %v blocks, want 5 without structure void", len(placed))
+	}
+	want := []Tag{
+		{id: tagInt, name: "state", payload: int32(1)},
+		{id: tagList, elementID: tagInt, name: "pos", payload: []any{int32(0), int32(1), int32(0)}},
+		withName(items, "nbt"),
+	}
+	if !reflect.DeepEqual(placed[1], want) {
+		t.Errorf("got %+v, want %+v", placed[1], want)
+	}
+
+	failureCases := []struct {
+		name          string
+		blocks        [][][]BlockState
+		blockEntities map[BlockPos]Tag
+	}{
+		{"not a cuboid", [][][]BlockState{{{stone}}, {{stone, stone}}}, nil},
+		{"block entity on void", [][][]BlockState{{{{}}}}, map[BlockPos]Tag{{}: items}},
+		{"block entity outside", [][][]BlockState{{{stone}}}, map[BlockPos]Tag{{X: 5}: items}},
+		{"block entity not a compound", [][][]BlockState{{{stone}}}, map[BlockPos]Tag{{}: {id: tagInt}}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			if _, err := StructureTemplate(failureCase.blocks, failureCase.blockEntities, 3465); err == nil {
+				t.Errorf("got no error, want error")
+			}
+		})
+	}
+}
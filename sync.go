@@ -0,0 +1,67 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SyncCompound guards a tagCompound with a read/write mutex so many goroutines can read it while others occasionally
+// update it, such as shared plugin state on a server. Updates replace the compound's children rather than modifying
+// them in place, so a tag returned by Get or Tag stays consistent after later updates. Returned tags are shared and
+// must not be modified directly, use Set instead.
+type SyncCompound struct {
+	mu   sync.RWMutex
+	root Tag
+}
+
+// NewSyncCompound takes ownership of a tagCompound, returning a SyncCompound guarding it.
+func NewSyncCompound(t Tag) (s *SyncCompound, err error) {
+	if t.id != tagCompound {
+		return nil, fmt.Errorf("Unable to create sync compound: tag ID %v is not a tagCompound", t.id)
+	}
+
+	return &SyncCompound{root: t}, nil
+}
+
+// Get returns the named child of the compound. The boolean is false if there is no child with that name.
+func (s *SyncCompound) Get(name string) (Tag, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return compoundChild(s.root, name)
+}
+
+// Set adds t to the compound, replacing any existing child of the same name.
+func (s *SyncCompound) Set(t Tag) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.root.payload = withChild(s.root.payload.([]Tag), t)
+}
+
+// Delete removes the named child from the compound. The boolean is false if there was no child with that name.
+func (s *SyncCompound) Delete(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	children, ok := withoutChild(s.root.payload.([]Tag), name)
+	s.root.payload = children
+	return ok
+}
+
+// Len returns the number of children in the compound.
+func (s *SyncCompound) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.root.payload.([]Tag))
+}
+
+// Tag returns a snapshot of the compound. Later calls to Set and Delete do not affect the snapshot.
+func (s *SyncCompound) Tag() Tag {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.root
+}
@@ -0,0 +1,90 @@
+package nbt
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestSyncCompound(t *testing.T) {
+	newState := func() Tag {
+		return Tag{id: tagCompound, payload: []Tag{
+			{id: tagInt, name: "Score", payload: int32(10)},
+			{id: tagString, name: "Owner", payload: "Steve"},
+		}}
+	}
+
+	t.Run("Test success case: get", func(t *testing.T) {
+		s, _ := NewSyncCompound(newState())
+		got, ok := s.Get("Score")
+		if !ok || got.payload != int32(10) {
+			t.Errorf("got %v %v, want 10 true", got.payload, ok)
+		}
+	})
+
+	t.Run("Test success case: set replaces and appends", func(t *testing.T) {
+		s, _ := NewSyncCompound(newState())
+		s.Set(Tag{id: tagInt, name: "Score", payload: int32(20)})
+		s.Set(Tag{id: tagByte, name: "Online", payload: byte(1)})
+
+		got, _ := s.Get("Score")
+		if got.payload != int32(20) {
+			t.Errorf("got %v, want 20", got.payload)
+		}
+		if s.Len() != 3 {
+			t.Errorf("got %v, want 3", s.Len())
+		}
+	})
+
+	t.Run("Test success case: delete", func(t *testing.T) {
+		s, _ := NewSyncCompound(newState())
+		if !s.Delete("Owner") {
+			t.Errorf("got false, want true")
+		}
+		if s.Delete("Owner") {
+			t.Errorf("got true, want false")
+		}
+		if _, ok := s.Get("Owner"); ok {
+			t.Errorf("got true, want false")
+		}
+	})
+
+	t.Run("Test success case: snapshot unaffected by later updates", func(t *testing.T) {
+		s, _ := NewSyncCompound(newState())
+		snapshot := s.Tag()
+		s.Set(Tag{id: tagInt, name: "Score", payload: int32(30)})
+
+		got, _ := compoundChild(snapshot, "Score")
+		if got.payload != int32(10) {
+			t.Errorf("got %v, want 10", got.payload)
+		}
+	})
+
+	t.Run("Test success case: concurrent readers and writers", func(t *testing.T) {
+		s, _ := NewSyncCompound(newState())
+		var wg sync.WaitGroup
+		for i := range 8 {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				s.Set(Tag{id: tagInt, name: "Writer" + strconv.Itoa(i), payload: int32(i)})
+			}()
+			go func() {
+				defer wg.Done()
+				_, _ = s.Get("Score")
+			}()
+		}
+		wg.Wait()
+
+		if s.Len() != 10 {
+			t.Errorf("got %v, want 10", s.Len())
+		}
+	})
+
+	t.Run("Test failure case: new from non-compound", func(t *testing.T) {
+		_, gotErr := NewSyncCompound(Tag{id: tagString, payload: "not a compound"})
+		if gotErr == nil {
+			t.Errorf("got nil, want non-nil")
+		}
+	})
+}
@@ -0,0 +1,123 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Layout of a synthetic chunk, matching Java edition 1.18 and later.
+const (
+	// syntheticMinSection and syntheticSections are the Y of the lowest section and the number of sections, covering
+	// blocks -64 to 319.
+	syntheticMinSection = -4
+	syntheticSections   = 24
+	// syntheticDataVersion is the DataVersion of Java edition 1.20.1, used when ChunkGenerator.DataVersion is zero.
+	syntheticDataVersion = 3465
+)
+
+// syntheticBlocks are the blocks filled sections are made of, the first being the most common.
+var syntheticBlocks = []string{"minecraft:stone", "minecraft:deepslate", "minecraft:dirt", "minecraft:granite",
+	"minecraft:diorite", "minecraft:andesite", "minecraft:gravel", "minecraft:coal_ore", "minecraft:iron_ore",
+	"minecraft:water"}
+
+// syntheticEntities are the entity IDs placed in entity chunks.
+var syntheticEntities = []string{"minecraft:cow", "minecraft:sheep", "minecraft:pig", "minecraft:chicken",
+	"minecraft:zombie", "minecraft:skeleton", "minecraft:item"}
+
+// ChunkGenerator produces realistic but synthetic Java edition chunks and entity chunks, so pipelines can be load
+// tested at scale without shipping real world files. Chunks hold every section from Y -64 to 319 with packed block
+// states and biomes, the same shape and size as chunks saved by the game.
+type ChunkGenerator struct {
+	// Fill is the fraction of sections, from the bottom, filled with a random mix of blocks. The sections above are
+	// air. It is clamped to between 0 and 1.
+	Fill float64
+	// Entities is the number of entities in each entity chunk.
+	Entities int
+	// DataVersion is stored in each chunk, defaulting to that of Java edition 1.20.1.
+	DataVersion int32
+}
+
+// Chunk generates the chunk at chunk coordinates x and z, as stored in a region file.
+func (g ChunkGenerator) Chunk(r *rand.Rand, x, z int32) Tag {
+	filled := int(math.Round(min(max(g.Fill, 0), 1) * syntheticSections))
+	sections := make([]any, syntheticSections)
+	for i := range sections {
+		sections[i] = g.section(r, int8(syntheticMinSection+i), i < filled) // #nosec G115 -- section Y fits int8
+	}
+
+	return Tag{id: tagCompound, payload: []Tag{
+		{id: tagInt, name: "DataVersion", payload: g.dataVersion()},
+		{id: tagInt, name: "xPos", payload: x},
+		{id: tagInt, name: "zPos", payload: z},
+		{id: tagInt, name: "yPos", payload: int32(syntheticMinSection)},
+		{id: tagString, name: "Status", payload: "minecraft:full"},
+		{id: tagLong, name: "LastUpdate", payload: r.Int63n(1 << 24)},
+		{id: tagLong, name: "InhabitedTime", payload: r.Int63n(1 << 16)},
+		{id: tagList, elementID: tagCompound, name: "sections", payload: sections},
+	}}
+}
+
+// EntityChunk generates the entity chunk at chunk coordinates x and z, as stored in the entities region files.
+func (g ChunkGenerator) EntityChunk(r *rand.Rand, x, z int32) Tag {
+	var entities []any
+	for range g.Entities {
+		entities = append(entities, []Tag{
+			{id: tagString, name: "id", payload: syntheticEntities[r.Intn(len(syntheticEntities))]},
+			{id: tagList, elementID: tagDouble, name: "Pos", payload: []any{
+				float64(x)*16 + r.Float64()*16, float64(r.Intn(128)), float64(z)*16 + r.Float64()*16}},
+			{id: tagList, elementID: tagDouble, name: "Motion", payload: []any{float64(0), float64(0), float64(0)}},
+			{id: tagList, elementID: tagFloat, name: "Rotation", payload: []any{r.Float32() * 360, float32(0)}},
+			{id: tagFloat, name: "Health", payload: float32(10)},
+			{id: tagIntArray, name: "UUID", payload: []int32{r.Int31(), r.Int31(), r.Int31(), r.Int31()}},
+		})
+	}
+
+	return Tag{id: tagCompound, payload: []Tag{
+		{id: tagInt, name: "DataVersion", payload: g.dataVersion()},
+		{id: tagIntArray, name: "Position", payload: []int32{x, z}},
+		{id: tagList, elementID: tagCompound, name: "Entities", payload: entities},
+	}}
+}
+
+// dataVersion returns the DataVersion of generated chunks.
+func (g ChunkGenerator) dataVersion() int32 {
+	if g.DataVersion == 0 {
+		return syntheticDataVersion
+	}
+	return g.DataVersion
+}
+
+// section generates a chunk section, either filled with a random mix of blocks or all air.
+func (g ChunkGenerator) section(r *rand.Rand, y int8, filled bool) []Tag {
+	palette := []any{[]Tag{{id: tagString, name: "Name", payload: "minecraft:air"}}}
+	var data []int64
+	if filled {
+		palette = palette[:0]
+		for _, block := range syntheticBlocks[:1+r.Intn(len(syntheticBlocks))] {
+			palette = append(palette, []Tag{{id: tagString, name: "Name", payload: block}})
+		}
+
+		// Most blocks are the first in the palette, as stone dominates real terrain.
+		indices := make([]int, sectionBlocks)
+		for i := range indices {
+			if r.Intn(4) == 0 {
+				indices[i] = r.Intn(len(palette))
+			}
+		}
+		data = packIndices(indices, blockStateBits(len(palette)))
+	}
+
+	blockStates := []Tag{{id: tagList, elementID: tagCompound, name: "palette", payload: palette}}
+	if data != nil {
+		blockStates = append(blockStates, Tag{id: tagLongArray, name: "data", payload: data})
+	}
+
+	return []Tag{
+		{id: tagByte, name: "Y", payload: byte(y)}, // #nosec G115 -- reinterpreting as the stored signed byte
+		{id: tagCompound, name: "block_states", payload: blockStates},
+		{id: tagCompound, name: "biomes", payload: []Tag{
+			{id: tagList, elementID: tagString, name: "palette", payload: []any{"minecraft:plains"}},
+		}},
+	}
+}
@@ -0,0 +1,76 @@
+package nbt
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestChunkGenerator(t *testing.T) {
+	successCases := []struct {
+		name       string
+		g          ChunkGenerator
+		wantFilled int
+	}{
+		{"empty", ChunkGenerator{}, 0},
+		{"half filled", ChunkGenerator{Fill: 0.5, Entities: 3}, 12},
+		{"over filled", ChunkGenerator{Fill: 2, DataVersion: 100}, syntheticSections},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1)) // #nosec G404 -- deterministic test data
+			chunk := successCase.g.Chunk(r, -3, 7)
+
+			x, _ := compoundChild(chunk, "xPos")
+			z, _ := compoundChild(chunk, "zPos")
+			if x.payload != int32(-3) || z.payload != int32(7) {
+				t.Errorf("got position %v %v, want -3 7", x.payload, z.payload)
+			}
+			version, _ := compoundChild(chunk, "DataVersion")
+			if want := successCase.g.dataVersion(); version.payload != want {
+				t.Errorf("got DataVersion %v, want %v", version.payload, want)
+			}
+
+			sections, _ := compoundChild(chunk, "sections")
+			elements := sections.payload.([]any)
+			if len(elements) != syntheticSections {
+				t.Fatalf("got %v sections, want %v", len(elements), syntheticSections)
+			}
+			filled := 0
+			for _, element := range elements {
+				section := Tag{id: tagCompound, payload: element}
+				blockStates, _ := compoundChild(section, "block_states")
+				palette, _ := compoundChild(blockStates, "palette")
+				paletteLen := len(palette.payload.([]any))
+				first, _ := compoundChild(Tag{id: tagCompound, payload: palette.payload.([]any)[0]}, "Name")
+				if first.payload != "minecraft:air" {
+					filled++
+				}
+				data, ok := compoundChild(blockStates, "data")
+				if !ok {
+					continue
+				}
+				indices, err := unpackIndices(data.payload.([]int64), blockStateBits(paletteLen), sectionBlocks)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				for _, index := range indices {
+					if index >= paletteLen {
+						t.Fatalf("got index %v, want less than palette length %v", index, paletteLen)
+					}
+				}
+			}
+			if filled != successCase.wantFilled {
+				t.Errorf("got %v filled sections, want %v", filled, successCase.wantFilled)
+			}
+
+			entityChunk := successCase.g.EntityChunk(r, -3, 7)
+			entities, _ := compoundChild(entityChunk, "Entities")
+			if got := len(entities.payload.([]any)); got != successCase.g.Entities {
+				t.Errorf("got %v entities, want %v", got, successCase.g.Entities)
+			}
+			if entities.ElementID() != tagCompound {
+				t.Errorf("got element ID %v, want %v", entities.ElementID(), tagCompound)
+			}
+		})
+	}
+}
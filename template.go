@@ -0,0 +1,141 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Substitute returns a copy of the tree with the placeholders in tagString payloads replaced from values, so kits and
+// structures can be generated from parameterised templates. A placeholder is "${key}", and "$${" is a literal "${".
+//
+// Values are payloads: byte, int16, int32, int64, float32, float64, []byte, string, []int32 or []int64. A tagString
+// that is exactly one placeholder takes the type of its value, so "${count}" with an int32 value becomes a tagInt. All
+// other placeholders, including those in list elements which must keep the listed type, are replaced by the value as
+// text. An unknown key or unsupported value is an error. The given tree is not modified.
+func Substitute(t Tag, values map[string]any) (Tag, error) {
+	substituted, err := substituteTag(t, values)
+	if err != nil {
+		return Tag{}, fmt.Errorf("Unable to substitute placeholders: %w", err)
+	}
+	return substituted, nil
+}
+
+// substituteTag substitutes the placeholders in t, changing its type if its tagString payload is a single placeholder.
+func substituteTag(t Tag, values map[string]any) (Tag, error) {
+	if s, ok := t.payload.(string); ok {
+		key, whole := wholePlaceholder(s)
+		if !whole {
+			text, err := substituteText(s, values)
+			t.payload = text
+			return t, err
+		}
+
+		value, ok := values[key]
+		if !ok {
+			return Tag{}, fmt.Errorf("tag \"%v\" has unknown placeholder %q", t.name, key)
+		}
+		id, err := payloadID(value)
+		if err != nil || id == tagList || id == tagCompound {
+			return Tag{}, fmt.Errorf("placeholder %q has unsupported value type %T", key, value)
+		}
+		t.id, t.payload = id, value
+		return t, nil
+	}
+
+	payload, err := substitutePayload(t.payload, values)
+	if err != nil {
+		return Tag{}, fmt.Errorf("tag \"%v\": %w", t.name, err)
+	}
+	t.payload = payload
+	return t, nil
+}
+
+// substitutePayload substitutes the placeholders in a payload as text, copying compounds and lists.
+func substitutePayload(payload any, values map[string]any) (any, error) {
+	switch p := payload.(type) {
+	case string:
+		return substituteText(p, values)
+	case []Tag:
+		if p == nil {
+			return p, nil
+		}
+		children := make([]Tag, len(p))
+		for i, child := range p {
+			var err error
+			children[i], err = substituteTag(child, values)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return children, nil
+	case []any:
+		if p == nil {
+			return p, nil
+		}
+		elements := make([]any, len(p))
+		for i, element := range p {
+			var err error
+			elements[i], err = substitutePayload(element, values)
+			if err != nil {
+				return nil, fmt.Errorf("element %v: %w", i, err)
+			}
+		}
+		return elements, nil
+	default:
+		return payload, nil
+	}
+}
+
+// wholePlaceholder returns the key of s if s is exactly one placeholder.
+func wholePlaceholder(s string) (key string, ok bool) {
+	if !strings.HasPrefix(s, "${") || !strings.HasSuffix(s, "}") {
+		return "", false
+	}
+	key = s[2 : len(s)-1]
+	return key, !strings.ContainsAny(key, "{}")
+}
+
+// substituteText replaces each placeholder in s with its value as text.
+func substituteText(s string, values map[string]any) (string, error) {
+	var b strings.Builder
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+
+		if i > 0 && s[i-1] == '$' {
+			b.WriteString(s[:i-1] + "${")
+			s = s[i+2:]
+			continue
+		}
+		b.WriteString(s[:i])
+
+		end := strings.IndexByte(s[i:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated placeholder in %q", s)
+		}
+		key := s[i+2 : i+end]
+		value, ok := values[key]
+		if !ok {
+			return "", fmt.Errorf("unknown placeholder %q", key)
+		}
+		b.WriteString(placeholderText(value))
+		s = s[i+end+1:]
+	}
+}
+
+// placeholderText formats a placeholder value as text, with floating point values in their shortest form.
+func placeholderText(value any) string {
+	switch v := value.(type) {
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
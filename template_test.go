@@ -0,0 +1,65 @@
+package nbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSubstitute(t *testing.T) {
+	values := map[string]any{"player": "Steve", "count": int32(64), "speed": float32(0.1), "uuid": []int32{1, 2}}
+	template := Tag{id: tagCompound, payload: []Tag{
+		{id: tagString, name: "Owner", payload: "${player}"},
+		{id: tagString, name: "Count", payload: "${count}"},
+		{id: tagString, name: "Lore", payload: "Given to ${player} at ${speed} speed"},
+		{id: tagString, name: "UUID", payload: "${uuid}"},
+		{id: tagString, name: "Escaped", payload: "$${player} costs $5"},
+		{id: tagList, name: "Pages", payload: []any{"${count}", "page"}},
+		{id: tagList, name: "Items", payload: []any{[]Tag{{id: tagString, name: "id", payload: "${count}"}}}},
+		{id: tagInt, name: "Plain", payload: int32(1)},
+	}}
+
+	got, err := Substitute(template, values)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := Tag{id: tagCompound, payload: []Tag{
+		{id: tagString, name: "Owner", payload: "Steve"},
+		{id: tagInt, name: "Count", payload: int32(64)},
+		{id: tagString, name: "Lore", payload: "Given to Steve at 0.1 speed"},
+		{id: tagIntArray, name: "UUID", payload: []int32{1, 2}},
+		{id: tagString, name: "Escaped", payload: "${player} costs $5"},
+		{id: tagList, name: "Pages", payload: []any{"64", "page"}},
+		{id: tagList, name: "Items", payload: []any{[]Tag{{id: tagInt, name: "id", payload: int32(64)}}}},
+		{id: tagInt, name: "Plain", payload: int32(1)},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	t.Run("Test success case: template unmodified", func(t *testing.T) {
+		owner, _ := compoundChild(template, "Owner")
+		if owner.payload != "${player}" {
+			t.Errorf("got %v, want ${player}", owner.payload)
+		}
+	})
+
+	failureCases := []struct {
+		name     string
+		template Tag
+	}{
+		{"unknown whole placeholder", Tag{id: tagString, name: "a", payload: "${missing}"}},
+		{"unknown embedded placeholder", Tag{id: tagString, name: "a", payload: "x ${missing}"}},
+		{"unterminated placeholder", Tag{id: tagString, name: "a", payload: "x ${player"}},
+		{"unsupported value", Tag{id: tagString, name: "a", payload: "${list}"}},
+		{"unknown placeholder in list", Tag{id: tagList, name: "a", payload: []any{"${missing}"}}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			_, err := Substitute(failureCase.template, map[string]any{"player": "Steve", "list": []any{}})
+			if err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
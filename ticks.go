@@ -0,0 +1,170 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Tick is a scheduled block or fluid tick of a chunk, as stored in block_ticks and fluid_ticks since Java edition
+// 1.18, or in Level.TileTicks and Level.LiquidTicks before.
+type Tick struct {
+	// ID is the namespaced block or fluid ID to tick, such as "minecraft:water".
+	ID string
+	// X, Y and Z are the world position of the block.
+	X, Y, Z int32
+	// Delay is the number of ticks until the tick runs, and Priority orders ticks due at the same time, lowest first.
+	Delay    int32
+	Priority int32
+}
+
+// tickLists are the names of the block and fluid tick lists since 1.18, and before it in the Level compound.
+var tickLists = [2][2]string{{"block_ticks", "TileTicks"}, {"fluid_ticks", "LiquidTicks"}}
+
+// BlockTicks returns the scheduled block ticks of a chunk in either layout.
+func BlockTicks(chunk Tag) ([]Tick, error) {
+	return chunkTicks(chunk, tickLists[0])
+}
+
+// FluidTicks returns the scheduled fluid ticks of a chunk in either layout.
+func FluidTicks(chunk Tag) ([]Tick, error) {
+	return chunkTicks(chunk, tickLists[1])
+}
+
+// SetBlockTicks returns a copy of the chunk with its scheduled block ticks replaced, in the layout of the chunk. The
+// given chunk is not modified.
+func SetBlockTicks(chunk Tag, ticks []Tick) (Tag, error) {
+	return setChunkTicks(chunk, tickLists[0], ticks)
+}
+
+// SetFluidTicks returns a copy of the chunk with its scheduled fluid ticks replaced, in the layout of the chunk. The
+// given chunk is not modified.
+func SetFluidTicks(chunk Tag, ticks []Tick) (Tag, error) {
+	return setChunkTicks(chunk, tickLists[1], ticks)
+}
+
+// ClearTicks returns a copy of the chunk without the block and fluid ticks that match, such as those of a removed
+// modded block. The given chunk is not modified.
+func ClearTicks(chunk Tag, match func(Tick) bool) (Tag, error) {
+	return editTicks(chunk, func(ticks []Tick) []Tick {
+		return slices.DeleteFunc(ticks, match)
+	})
+}
+
+// RetargetTicks returns a copy of the chunk with the block and fluid ticks of ID from changed to ID to, such as when
+// replacing a modded block with a vanilla one. The given chunk is not modified.
+func RetargetTicks(chunk Tag, from, to string) (Tag, error) {
+	return editTicks(chunk, func(ticks []Tick) []Tick {
+		for i := range ticks {
+			if ticks[i].ID == from {
+				ticks[i].ID = to
+			}
+		}
+		return ticks
+	})
+}
+
+// editTicks returns a copy of the chunk with edit applied to both its block and fluid ticks. Lists that are absent
+// stay absent.
+func editTicks(chunk Tag, edit func([]Tick) []Tick) (Tag, error) {
+	for _, names := range tickLists {
+		if _, ok := tickParent(chunk, names); !ok {
+			continue
+		}
+		ticks, err := chunkTicks(chunk, names)
+		if err != nil {
+			return Tag{}, err
+		}
+		chunk, err = setChunkTicks(chunk, names, edit(ticks))
+		if err != nil {
+			return Tag{}, err
+		}
+	}
+	return chunk, nil
+}
+
+// tickParent returns the path of the compound holding the named tick list: the root since 1.18, or the Level compound
+// before. The boolean is false if the list is absent.
+func tickParent(chunk Tag, names [2]string) (Path, bool) {
+	if level, ok := compoundChild(chunk, "Level"); ok {
+		_, ok = compoundChild(level, names[1])
+		return Path{"Level"}, ok
+	}
+	_, ok := compoundChild(chunk, names[0])
+	return Path{}, ok
+}
+
+// chunkTicks reads the named tick list of a chunk. An absent list has no ticks.
+func chunkTicks(chunk Tag, names [2]string) (ticks []Tick, err error) {
+	if chunk.id != tagCompound {
+		return nil, fmt.Errorf("Unable to read %v: tag ID %v is not a tagCompound", names[0], chunk.id)
+	}
+
+	parent, ok := tickParent(chunk, names)
+	if !ok {
+		return nil, nil
+	}
+	name := names[0]
+	if len(parent) > 0 {
+		name = names[1]
+	}
+	list, err := lookup(chunk, append(parent, name))
+	if err != nil {
+		return nil, err
+	}
+	elements, ok := list.payload.([]any)
+	if list.id != tagList || (!ok && list.payload != nil) {
+		return nil, fmt.Errorf("Unable to read %v: tag ID %v is not a tagList", name, list.id)
+	}
+
+	for i, element := range elements {
+		tick := Tag{id: tagCompound, payload: element}
+		var t Tick
+		var complete bool
+		t.ID, complete = childPayload[string](tick, "i")
+		fields := []*int32{&t.X, &t.Y, &t.Z, &t.Delay, &t.Priority}
+		for j, field := range []string{"x", "y", "z", "t", "p"} {
+			var fieldOK bool
+			*fields[j], fieldOK = childPayload[int32](tick, field)
+			complete = complete && fieldOK
+		}
+		if !complete {
+			return nil, fmt.Errorf("Unable to read %v tick %v: missing tagString \"i\" or a tagInt position, \"t\" "+
+				"or \"p\"", name, i)
+		}
+		ticks = append(ticks, t)
+	}
+	return ticks, nil
+}
+
+// setChunkTicks returns a copy of the chunk with the named tick list replaced, in the Level compound if the chunk has
+// one.
+func setChunkTicks(chunk Tag, names [2]string, ticks []Tick) (Tag, error) {
+	if chunk.id != tagCompound {
+		return Tag{}, fmt.Errorf("Unable to set %v: tag ID %v is not a tagCompound", names[0], chunk.id)
+	}
+
+	var elements []any
+	for _, t := range ticks {
+		elements = append(elements, []Tag{
+			{id: tagString, name: "i", payload: t.ID},
+			{id: tagInt, name: "x", payload: t.X},
+			{id: tagInt, name: "y", payload: t.Y},
+			{id: tagInt, name: "z", payload: t.Z},
+			{id: tagInt, name: "t", payload: t.Delay},
+			{id: tagInt, name: "p", payload: t.Priority},
+		})
+	}
+
+	parent, name := Path{}, names[0]
+	if _, ok := compoundChild(chunk, "Level"); ok {
+		parent, name = Path{"Level"}, names[1]
+	}
+	chunk, err := editPath(chunk, parent, compoundEdit(func(children []Tag) ([]Tag, error) {
+		return withChild(children, Tag{id: tagList, elementID: tagCompound, name: name, payload: elements}), nil
+	}))
+	if err != nil {
+		return Tag{}, fmt.Errorf("Unable to set %v: %w", name, err)
+	}
+	return chunk, nil
+}
@@ -0,0 +1,101 @@
+package nbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTicks(t *testing.T) {
+	blockTicks := []Tick{
+		{ID: "modded:machine", X: 1, Y: 64, Z: 2, Delay: 5},
+		{ID: "minecraft:repeater", X: 3, Y: 64, Z: 4, Delay: 2, Priority: -1},
+	}
+	fluidTicks := []Tick{{ID: "minecraft:water", X: 0, Y: 62, Z: 0, Delay: 5}}
+
+	tests := []struct {
+		name     string
+		chunk    Tag
+		wantPath Path
+	}{
+		{
+			name:     "Test success case: 1.18 layout",
+			chunk:    Tag{id: tagCompound, payload: []Tag{{id: tagInt, name: "DataVersion", payload: int32(3465)}}},
+			wantPath: Path{"fluid_ticks", 0, "i"},
+		},
+		{
+			name:     "Test success case: legacy layout",
+			chunk:    Tag{id: tagCompound, payload: []Tag{{id: tagCompound, name: "Level"}}},
+			wantPath: Path{"Level", "LiquidTicks", 0, "i"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunk, err := SetBlockTicks(tt.chunk, blockTicks)
+			if err == nil {
+				chunk, err = SetFluidTicks(chunk, fluidTicks)
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if _, err := lookup(chunk, tt.wantPath); err != nil {
+				t.Errorf("got %+v, want %v: %v", chunk, tt.wantPath, err)
+			}
+
+			gotBlocks, err := BlockTicks(chunk)
+			if err != nil || !reflect.DeepEqual(gotBlocks, blockTicks) {
+				t.Errorf("got %+v %v, want %+v", gotBlocks, err, blockTicks)
+			}
+			gotFluids, err := FluidTicks(chunk)
+			if err != nil || !reflect.DeepEqual(gotFluids, fluidTicks) {
+				t.Errorf("got %+v %v, want %+v", gotFluids, err, fluidTicks)
+			}
+
+			retargeted, err := RetargetTicks(chunk, "modded:machine", "minecraft:air")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got, _ := BlockTicks(retargeted); got[0].ID != "minecraft:air" {
+				t.Errorf("got %+v, want the machine retargeted to air", got[0])
+			}
+			if got, _ := BlockTicks(chunk); got[0].ID != "modded:machine" {
+				t.Errorf("got %+v in the original, want it unchanged", got[0])
+			}
+
+			cleared, err := ClearTicks(chunk, func(tick Tick) bool { return tick.Delay == 5 })
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			gotBlocks, _ = BlockTicks(cleared)
+			gotFluids, _ = FluidTicks(cleared)
+			if len(gotBlocks) != 1 || gotBlocks[0].ID != "minecraft:repeater" || len(gotFluids) != 0 {
+				t.Errorf("got %+v and %+v, want only the repeater", gotBlocks, gotFluids)
+			}
+		})
+	}
+
+	t.Run("Test success case: absent lists stay absent", func(t *testing.T) {
+		chunk := Tag{id: tagCompound, payload: []Tag{{id: tagInt, name: "DataVersion", payload: int32(3465)}}}
+		got, err := ClearTicks(chunk, func(Tick) bool { return true })
+		if err != nil || !reflect.DeepEqual(got, chunk) {
+			t.Errorf("got %+v %v, want the chunk unchanged", got, err)
+		}
+	})
+
+	failureCases := []struct {
+		name  string
+		input Tag
+	}{
+		{"not a compound", Tag{id: tagList}},
+		{"not a list", Tag{id: tagCompound, payload: []Tag{{id: tagInt, name: "block_ticks", payload: int32(1)}}}},
+		{"missing field", Tag{id: tagCompound, payload: []Tag{{id: tagList, elementID: tagCompound,
+			name: "block_ticks", payload: []any{[]Tag{{id: tagString, name: "i", payload: "minecraft:stone"}}}}}}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			if _, err := BlockTicks(failureCase.input); err == nil {
+				t.Errorf("got no error, want error")
+			}
+		})
+	}
+}
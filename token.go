@@ -0,0 +1,271 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Token is a part of a tag read by Decoder.Token: a TagHeader, CompoundStart, ListStart, ScalarValue or End. A tag is
+// its TagHeader then the tokens of its payload. The payload of a tagCompound is a CompoundStart, the TagHeader and
+// payload of each child, then an End. The payload of a tagList is a ListStart, the payload of each element, which have
+// no headers, then an End. Every other payload is a ScalarValue.
+type Token any
+
+// TagHeader is the tag ID and name of the root tag or a child of a compound, read before its payload. The root tag of
+// the network format has an empty name, see WithNetworkFormat.
+type TagHeader struct {
+	ID   uint8
+	Name string
+}
+
+// CompoundStart starts the payload of a tagCompound.
+type CompoundStart struct{}
+
+// ListStart starts the payload of a tagList of Len elements with the element tag ID. A negative length read is zero.
+type ListStart struct {
+	ElementID uint8
+	Len       int
+}
+
+// ScalarValue is the payload of a tag of the ID other than a tagCompound or tagList, with the Go type of the payload of
+// a Tag of the ID, see Tag. Arrays are read whole.
+type ScalarValue struct {
+	ID    uint8
+	Value any
+}
+
+// End ends the payload of a tagCompound or tagList.
+type End struct{}
+
+// tokenFrame is a compound or list whose tokens are being read, with the element ID and the number of elements left to
+// read of a list.
+type tokenFrame struct {
+	list      bool
+	elementID uint8
+	remaining int
+}
+
+// Token reads the next token of the stream of tags, so tags are processed a part at a time without holding a whole
+// tag, such as a huge compound, in memory. Tags are checked as they are read, with the limits of the options, so an
+// error may follow tokens of a tag already returned. Between tags, Decode may be used to read the next tag whole. At
+// the end of the input, Token returns io.EOF. The Timeout of the options runs from the first token of each tag.
+func (d *Decoder) Token() (token Token, err error) {
+	defer d.clearDeadline()
+	if err = d.open(); err != nil {
+		return nil, fmt.Errorf("Unable to read token: %w", err)
+	}
+
+	token, err = d.token()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read token: %w", err)
+	}
+	return token, nil
+}
+
+// token reads the next token, returning a bare io.EOF only at the end of the input between tags.
+func (d *Decoder) token() (token Token, err error) {
+	if d.next == tagEnd && len(d.stack) > 0 {
+		top := &d.stack[len(d.stack)-1]
+		if top.list {
+			if top.remaining == 0 {
+				d.stack = d.stack[:len(d.stack)-1]
+				return End{}, nil
+			}
+			top.remaining--
+			d.next = top.elementID
+		}
+	}
+
+	if d.next != tagEnd {
+		id := d.next
+		d.next = tagEnd
+		return d.payloadToken(id)
+	}
+	return d.headerToken()
+}
+
+// headerToken reads the header of the next tag, or the tagEnd ending the innermost compound.
+func (d *Decoder) headerToken() (token Token, err error) {
+	o := d.tokenOptions()
+	start := d.input.read
+	id, err := readTagID(d.input, o)
+	if err != nil {
+		if len(d.stack) == 0 && errors.Is(err, io.EOF) && d.input.read == start {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+
+	if id == tagEnd {
+		if len(d.stack) == 0 {
+			return nil, fmt.Errorf("tagEnd outside a tagCompound")
+		}
+		d.stack = d.stack[:len(d.stack)-1]
+		return End{}, nil
+	}
+
+	// The root tag of the network format has no name.
+	var name string
+	if len(d.stack) > 0 || !o.NetworkFormat {
+		name, err = readTagName(d.input, o)
+		if err != nil {
+			return nil, err
+		}
+	}
+	d.next = id
+	return TagHeader{ID: id, Name: name}, nil
+}
+
+// payloadToken reads the payload of a tag of the ID, or the start of it for a compound or list.
+func (d *Decoder) payloadToken(id uint8) (token Token, err error) {
+	o := d.tokenOptions()
+	switch id {
+	case tagCompound:
+		if err = checkLength(len(d.stack)+1, o.Limits.MaxDepth); err != nil {
+			return nil, fmt.Errorf("Unable to read tagCompound depth: %w", err)
+		}
+		d.stack = append(d.stack, tokenFrame{})
+		return CompoundStart{}, nil
+	case tagList:
+		elementID, length, err := readTagListHeader(d.input, o)
+		if err != nil {
+			return nil, err
+		}
+		if err = checkLength(len(d.stack)+1, o.Limits.MaxDepth); err != nil {
+			return nil, fmt.Errorf("Unable to read tagList depth: %w", err)
+		}
+		n := max(int(length), 0)
+		d.stack = append(d.stack, tokenFrame{list: true, elementID: elementID, remaining: n})
+		return ListStart{ElementID: elementID, Len: n}, nil
+	default:
+		value, err := readTagPayload(d.input, o, id)
+		if err != nil {
+			return nil, err
+		}
+		return ScalarValue{ID: id, Value: value}, nil
+	}
+}
+
+// tokenOptions returns the options of the decoder at the depth of the innermost compound or list.
+func (d *Decoder) tokenOptions() Options {
+	o := d.o
+	o.depth = len(d.stack)
+	return o
+}
+
+// WriteToken writes the next token of the stream of tags, the tokens read by Decoder.Token, so tags are written a part
+// at a time without holding a whole tag in memory, as in a pipeline reading, transforming and writing tags. Each token
+// must be one the tags expect next, and a ScalarValue must have the Go type of its ID, so every stream of tokens
+// written forms valid tags. After an error the output holds part of a tag.
+func (e *Encoder) WriteToken(token Token) (err error) {
+	if err = e.open(); err != nil {
+		return fmt.Errorf("Unable to write token: %w", err)
+	}
+	if err = e.writeToken(token); err != nil {
+		return fmt.Errorf("Unable to write token: %w", err)
+	}
+	return nil
+}
+
+// writeToken checks the token is one expected next and writes it.
+func (e *Encoder) writeToken(token Token) (err error) {
+	if e.next == tagEnd && len(e.stack) > 0 {
+		top := &e.stack[len(e.stack)-1]
+		if top.list {
+			if top.remaining == 0 {
+				if _, ok := token.(End); !ok {
+					return fmt.Errorf("%T after the last element of a tagList, not End", token)
+				}
+				e.stack = e.stack[:len(e.stack)-1]
+				return nil
+			}
+			if _, ok := token.(End); ok {
+				return fmt.Errorf("End with %v elements of a tagList left", top.remaining)
+			}
+			top.remaining--
+			e.next = top.elementID
+		}
+	}
+
+	if e.next != tagEnd {
+		id := e.next
+		e.next = tagEnd
+		return e.writePayloadToken(id, token)
+	}
+
+	o := e.tokenOptions()
+	switch t := token.(type) {
+	case TagHeader:
+		if t.ID == tagEnd {
+			return fmt.Errorf("TagHeader of a tagEnd, write End to end a compound")
+		}
+		if err = writeTagID(e.output, o, t.ID); err != nil {
+			return err
+		}
+		// The root tag of the network format has no name.
+		if len(e.stack) > 0 || !o.NetworkFormat {
+			if err = writeTagName(e.output, o, t.Name); err != nil {
+				return err
+			}
+		}
+		e.next = t.ID
+		return nil
+	case End:
+		if len(e.stack) == 0 {
+			return fmt.Errorf("End outside a tagCompound")
+		}
+		e.stack = e.stack[:len(e.stack)-1]
+		return writeTagID(e.output, o, tagEnd)
+	default:
+		return fmt.Errorf("%T where a TagHeader or End is expected", token)
+	}
+}
+
+// writePayloadToken writes the token as the payload of a tag of the ID, or the start of it for a compound or list.
+func (e *Encoder) writePayloadToken(id uint8, token Token) (err error) {
+	o := e.tokenOptions()
+	switch t := token.(type) {
+	case CompoundStart:
+		if id != tagCompound {
+			return fmt.Errorf("CompoundStart for the payload of a tag ID %v", id)
+		}
+		e.stack = append(e.stack, tokenFrame{})
+		return nil
+	case ListStart:
+		if id != tagList {
+			return fmt.Errorf("ListStart for the payload of a tag ID %v", id)
+		}
+		if t.Len < 0 || t.Len > math.MaxInt32 || (t.ElementID == tagEnd && t.Len > 0) {
+			return fmt.Errorf("ListStart of %v elements of tag ID %v", t.Len, t.ElementID)
+		}
+		if err = binary.Write(e.output, o.ByteOrder, t.ElementID); err != nil {
+			return fmt.Errorf("Unable to write tagList type: %w", err)
+		}
+		if err = writeInt32(e.output, o, int32(t.Len)); err != nil { // #nosec G115 -- checked above
+			return fmt.Errorf("Unable to write tagList length: %w", err)
+		}
+		e.stack = append(e.stack, tokenFrame{list: true, elementID: t.ElementID, remaining: t.Len})
+		return nil
+	case ScalarValue:
+		if t.ID != id || id == tagCompound || id == tagList {
+			return fmt.Errorf("ScalarValue of tag ID %v for the payload of a tag ID %v", t.ID, id)
+		}
+		return writeTagPayload(e.output, o, id, t.Value)
+	default:
+		return fmt.Errorf("%T where the payload of a tag ID %v is expected", token, id)
+	}
+}
+
+// tokenOptions returns the options of the encoder at the depth of the innermost compound or list.
+func (e *Encoder) tokenOptions() Options {
+	o := e.o
+	o.depth = len(e.stack)
+	return o
+}
@@ -0,0 +1,240 @@
+package nbt
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// tokenTestTag returns a compound holding a scalar, an array, a list of compounds and an empty list.
+func tokenTestTag(t *testing.T) Tag {
+	t.Helper()
+	element, err := NewCompound("", NewString("id", "minecraft:stone"))
+	if err != nil {
+		t.Fatalf("Unable to create test tag: %v", err)
+	}
+	items, err := NewList("Items", tagCompound, element)
+	if err != nil {
+		t.Fatalf("Unable to create test tag: %v", err)
+	}
+	empty, err := NewList("Empty", tagInt)
+	if err != nil {
+		t.Fatalf("Unable to create test tag: %v", err)
+	}
+	root, err := NewCompound("root", NewInt("a", 1), NewLongArray("b", []int64{2, 3}), items, empty)
+	if err != nil {
+		t.Fatalf("Unable to create test tag: %v", err)
+	}
+	return root
+}
+
+// tokenTestWant is the tokens of tokenTestTag.
+var tokenTestWant = []Token{
+	TagHeader{ID: tagCompound, Name: "root"}, CompoundStart{},
+	TagHeader{ID: tagInt, Name: "a"}, ScalarValue{ID: tagInt, Value: int32(1)},
+	TagHeader{ID: tagLongArray, Name: "b"}, ScalarValue{ID: tagLongArray, Value: []int64{2, 3}},
+	TagHeader{ID: tagList, Name: "Items"}, ListStart{ElementID: tagCompound, Len: 1},
+	CompoundStart{}, TagHeader{ID: tagString, Name: "id"}, ScalarValue{ID: tagString, Value: "minecraft:stone"}, End{},
+	End{},
+	TagHeader{ID: tagList, Name: "Empty"}, ListStart{ElementID: tagInt}, End{},
+	End{},
+}
+
+// readTokens reads tokens from the decoder until io.EOF or an error.
+func readTokens(d *Decoder) (tokens []Token, err error) {
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			return tokens, nil
+		}
+		if err != nil {
+			return tokens, err
+		}
+		tokens = append(tokens, token)
+	}
+}
+
+func TestDecoderToken(t *testing.T) {
+	root := tokenTestTag(t)
+
+	t.Run("Test success case: tokens of a stream of tags", func(t *testing.T) {
+		var b bytes.Buffer
+		for range 2 {
+			if err := WriteTag(&b, root); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+
+		got, err := readTokens(NewDecoder(&b))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want := append(append([]Token{}, tokenTestWant...), tokenTestWant...)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Test success case: nameless network root", func(t *testing.T) {
+		var b bytes.Buffer
+		if err := WriteTag(&b, NewInt("ignored", 7), WithNetworkFormat(true)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		got, err := readTokens(NewDecoder(&b, WithNetworkFormat(true)))
+		want := []Token{TagHeader{ID: tagInt}, ScalarValue{ID: tagInt, Value: int32(7)}}
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v %v, want %v", got, err, want)
+		}
+	})
+
+	t.Run("Test success case: decode a whole tag between tokens", func(t *testing.T) {
+		var b bytes.Buffer
+		_ = WriteTag(&b, NewInt("first", 1))
+		_ = WriteTag(&b, NewInt("second", 2))
+
+		d := NewDecoder(&b)
+		if _, err := d.Token(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var skipped Tag
+		if err := d.Decode(&skipped); err == nil {
+			t.Errorf("Decode() part way through a tag, want an error")
+		}
+		if _, err := d.Token(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var got Tag
+		if err := d.Decode(&got); err != nil || got.name != "second" {
+			t.Errorf("got %+v %v, want the second tag", got, err)
+		}
+	})
+
+	tests := []struct {
+		name  string
+		input func() []byte
+		opts  []Option
+	}{
+		{name: "Test failure case: truncated compound", input: func() []byte {
+			var b bytes.Buffer
+			_ = WriteTag(&b, root)
+			return b.Bytes()[:b.Len()-3]
+		}},
+		{name: "Test failure case: tagEnd outside a compound", input: func() []byte { return []byte{tagEnd} }},
+		{name: "Test failure case: depth over the limit", input: func() []byte {
+			var b bytes.Buffer
+			_ = WriteTag(&b, root)
+			return b.Bytes()
+		}, opts: []Option{WithLimits(Limits{MaxDepth: 2})}},
+		{name: "Test failure case: unknown tag ID", input: func() []byte { return []byte{13, 0, 0} }},
+		{name: "Test failure case: list of tagEnd elements", input: func() []byte {
+			return []byte{tagList, 0, 0, tagEnd, 0, 0, 0, 2}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readTokens(NewDecoder(bytes.NewReader(tt.input()), tt.opts...))
+			if err == nil {
+				t.Errorf("Expected an error, got nil")
+			}
+		})
+	}
+}
+
+func TestEncoderWriteToken(t *testing.T) {
+	root := tokenTestTag(t)
+	var want bytes.Buffer
+	if err := WriteTag(&want, root); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Run("Test success case: tokens written as the tag", func(t *testing.T) {
+		var b bytes.Buffer
+		e := NewEncoder(&b)
+		for _, token := range tokenTestWant {
+			if err := e.WriteToken(token); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		if err := e.Close(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !bytes.Equal(b.Bytes(), want.Bytes()) {
+			t.Errorf("got % x, want % x", b.Bytes(), want.Bytes())
+		}
+	})
+
+	t.Run("Test success case: tokens piped from a decoder and compressed", func(t *testing.T) {
+		var b bytes.Buffer
+		d := NewDecoder(bytes.NewReader(want.Bytes()))
+		e := NewEncoder(&b, WithCompression(CompressionGzip))
+		for {
+			token, err := d.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if err = e.WriteToken(token); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		if err := e.Close(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		got, err := ReadTag(&b, WithCompression(CompressionGzip))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var written bytes.Buffer
+		if err = WriteTag(&written, got); err != nil || !bytes.Equal(written.Bytes(), want.Bytes()) {
+			t.Errorf("got %v %v, want %v", got, err, root)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		tokens []Token
+	}{
+		{name: "Test failure case: payload before a header", tokens: []Token{CompoundStart{}}},
+		{name: "Test failure case: End outside a compound", tokens: []Token{End{}}},
+		{name: "Test failure case: header of a tagEnd", tokens: []Token{TagHeader{ID: tagEnd}}},
+		{name: "Test failure case: payload of another ID", tokens: []Token{TagHeader{ID: tagInt},
+			ScalarValue{ID: tagLong, Value: int64(1)}}},
+		{name: "Test failure case: value of the wrong type", tokens: []Token{TagHeader{ID: tagInt},
+			ScalarValue{ID: tagInt, Value: 1}}},
+		{name: "Test failure case: scalar compound payload", tokens: []Token{TagHeader{ID: tagCompound},
+			ScalarValue{ID: tagCompound, Value: []Tag{}}}},
+		{name: "Test failure case: list ended early", tokens: []Token{TagHeader{ID: tagList},
+			ListStart{ElementID: tagInt, Len: 2}, ScalarValue{ID: tagInt, Value: int32(1)}, End{}}},
+		{name: "Test failure case: list element past its length", tokens: []Token{TagHeader{ID: tagList},
+			ListStart{ElementID: tagInt, Len: 1}, ScalarValue{ID: tagInt, Value: int32(1)},
+			ScalarValue{ID: tagInt, Value: int32(2)}}},
+		{name: "Test failure case: negative list length", tokens: []Token{TagHeader{ID: tagList},
+			ListStart{ElementID: tagInt, Len: -1}}},
+		{name: "Test failure case: closed within a compound", tokens: []Token{TagHeader{ID: tagCompound},
+			CompoundStart{}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEncoder(io.Discard)
+			var err error
+			for _, token := range tt.tokens {
+				if err = e.WriteToken(token); err != nil {
+					break
+				}
+			}
+			if err == nil {
+				err = e.Close()
+			}
+			if err == nil {
+				t.Errorf("Expected an error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Trade is one villager or wandering trader offer, a compound of the Offers.Recipes list. Buy, BuyB and Sell are item
+// stack compounds, see ItemStack. BuyB is a zero Tag when the trade takes a single item.
+type Trade struct {
+	Buy, BuyB, Sell Tag
+	// Uses counts the trades made since the last restock, MaxUses the trades allowed before it is locked.
+	Uses, MaxUses int32
+	// XP is the experience the villager gains from the trade, RewardXP whether the player gains experience too.
+	XP       int32
+	RewardXP bool
+	// PriceMultiplier, SpecialPrice and Demand adjust the price of the first item bought.
+	PriceMultiplier float32
+	SpecialPrice    int32
+	Demand          int32
+
+	// extra holds any children of the recipe compound not modelled above, so they are kept by SetTrades.
+	extra []Tag
+}
+
+// tradeFields are the names of the recipe compound children modelled by Trade.
+var tradeFields = []string{"buy", "buyB", "sell", "uses", "maxUses", "xp", "rewardExp", "priceMultiplier",
+	"specialPrice", "demand"}
+
+// Trades returns the trades offered by a villager or wandering trader entity compound, in order. An entity without
+// Offers has no trades.
+func Trades(entity Tag) (trades []Trade, err error) {
+	if entity.id != tagCompound {
+		return nil, fmt.Errorf("Unable to read trades: tag ID %v is not a tagCompound", entity.id)
+	}
+
+	offers, ok := compoundChild(entity, "Offers")
+	if !ok {
+		return nil, nil
+	}
+	recipes, ok := compoundChild(offers, "Recipes")
+	if !ok || recipes.id != tagList {
+		return nil, fmt.Errorf("Unable to read trades: Offers has no tagList named \"Recipes\"")
+	}
+
+	elements, _ := recipes.payload.([]any)
+	for i, element := range elements {
+		recipe, ok := element.([]Tag)
+		if !ok {
+			return nil, fmt.Errorf("Unable to read trade %v: not a tagCompound", i)
+		}
+		trade, err := tradeFromRecipe(Tag{id: tagCompound, payload: recipe})
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read trade %v: %w", i, err)
+		}
+		trades = append(trades, trade)
+	}
+	return trades, nil
+}
+
+// tradeFromRecipe reads a Trade from a recipe compound.
+func tradeFromRecipe(recipe Tag) (trade Trade, err error) {
+	for _, child := range recipe.payload.([]Tag) {
+		switch child.name {
+		case "buy":
+			trade.Buy, err = tradeItem(child)
+		case "buyB":
+			trade.BuyB, err = tradeItem(child)
+		case "sell":
+			trade.Sell, err = tradeItem(child)
+		case "uses":
+			trade.Uses, err = tradeField[int32](child)
+		case "maxUses":
+			trade.MaxUses, err = tradeField[int32](child)
+		case "xp":
+			trade.XP, err = tradeField[int32](child)
+		case "rewardExp":
+			var rewardXP byte
+			rewardXP, err = tradeField[byte](child)
+			trade.RewardXP = rewardXP != 0
+		case "priceMultiplier":
+			trade.PriceMultiplier, err = tradeField[float32](child)
+		case "specialPrice":
+			trade.SpecialPrice, err = tradeField[int32](child)
+		case "demand":
+			trade.Demand, err = tradeField[int32](child)
+		default:
+			trade.extra = append(trade.extra, child)
+		}
+		if err != nil {
+			return Trade{}, err
+		}
+	}
+	return trade, nil
+}
+
+// tradeItem checks that a recipe child is an item stack compound.
+func tradeItem(child Tag) (Tag, error) {
+	if child.id != tagCompound {
+		return Tag{}, fmt.Errorf("\"%v\" is tag ID %v, not a tagCompound", child.name, child.id)
+	}
+	return child, nil
+}
+
+// tradeField returns the payload of a recipe child, which must be of type P.
+func tradeField[P any](child Tag) (P, error) {
+	p, ok := child.payload.(P)
+	if !ok {
+		return p, fmt.Errorf("\"%v\" has payload type %T, not %T", child.name, child.payload, p)
+	}
+	return p, nil
+}
+
+// SetTrades returns a copy of the entity compound offering the trades, replacing Offers.Recipes. Other children of
+// Offers and of the entity are kept. The given entity is not modified.
+func SetTrades(entity Tag, trades []Trade) (Tag, error) {
+	if entity.id != tagCompound {
+		return Tag{}, fmt.Errorf("Unable to set trades: tag ID %v is not a tagCompound", entity.id)
+	}
+
+	recipes := make([]any, len(trades))
+	for i, trade := range trades {
+		if trade.Buy.id != tagCompound || trade.Sell.id != tagCompound {
+			return Tag{}, fmt.Errorf("Unable to set trade %v: Buy and Sell must be item stack compounds", i)
+		}
+		recipes[i] = trade.recipe()
+	}
+
+	offers, ok := compoundChild(entity, "Offers")
+	if !ok {
+		offers = Tag{id: tagCompound, name: "Offers"}
+	}
+	if offers.id != tagCompound {
+		return Tag{}, fmt.Errorf("Unable to set trades: Offers is tag ID %v, not a tagCompound", offers.id)
+	}
+	offersChildren, _ := offers.payload.([]Tag)
+	offers.payload = withChild(offersChildren, Tag{id: tagList, elementID: tagCompound, name: "Recipes",
+		payload: recipes})
+
+	children, _ := entity.payload.([]Tag)
+	entity.payload = withChild(children, offers)
+	return entity, nil
+}
+
+// AddTrade returns a copy of the entity compound with the trade appended to its offers.
+func AddTrade(entity Tag, trade Trade) (Tag, error) {
+	trades, err := Trades(entity)
+	if err != nil {
+		return Tag{}, err
+	}
+	return SetTrades(entity, append(trades, trade))
+}
+
+// RemoveTrade returns a copy of the entity compound without the i'th trade of its offers.
+func RemoveTrade(entity Tag, i int) (Tag, error) {
+	trades, err := Trades(entity)
+	if err != nil {
+		return Tag{}, err
+	}
+	if i < 0 || i >= len(trades) {
+		return Tag{}, fmt.Errorf("Unable to remove trade %v: index out of range of length %v", i, len(trades))
+	}
+	return SetTrades(entity, slices.Delete(trades, i, i+1))
+}
+
+// recipe returns the trade as a recipe compound payload, with the modelled children first.
+func (trade Trade) recipe() []Tag {
+	recipe := []Tag{withName(trade.Buy, "buy")}
+	if trade.BuyB.id == tagCompound {
+		recipe = append(recipe, withName(trade.BuyB, "buyB"))
+	}
+	recipe = append(recipe,
+		withName(trade.Sell, "sell"),
+		Tag{id: tagInt, name: "uses", payload: trade.Uses},
+		Tag{id: tagInt, name: "maxUses", payload: trade.MaxUses},
+		Tag{id: tagInt, name: "xp", payload: trade.XP},
+		Tag{id: tagByte, name: "rewardExp", payload: boolByte(trade.RewardXP)},
+		Tag{id: tagFloat, name: "priceMultiplier", payload: trade.PriceMultiplier},
+		Tag{id: tagInt, name: "specialPrice", payload: trade.SpecialPrice},
+		Tag{id: tagInt, name: "demand", payload: trade.Demand},
+	)
+
+	for _, child := range trade.extra {
+		if !slices.Contains(tradeFields, child.name) {
+			recipe = append(recipe, child)
+		}
+	}
+	return recipe
+}
+
+// withName returns t renamed.
+func withName(t Tag, name string) Tag {
+	t.name = name
+	return t
+}
+
+// boolByte returns a boolean as a tagByte payload, 1 for true and 0 for false.
+func boolByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,136 @@
+package nbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testItem returns an item stack compound with the given name.
+func testItem(name, id string, count byte) Tag {
+	return Tag{id: tagCompound, name: name, payload: []Tag{
+		{id: tagString, name: "id", payload: id},
+		{id: tagByte, name: "Count", payload: count},
+	}}
+}
+
+func TestTrades(t *testing.T) {
+	villager := Tag{id: tagCompound, payload: []Tag{
+		{id: tagString, name: "id", payload: "minecraft:villager"},
+		{id: tagCompound, name: "Offers", payload: []Tag{
+			{id: tagList, elementID: tagCompound, name: "Recipes", payload: []any{
+				[]Tag{
+					testItem("buy", "minecraft:emerald", 1),
+					testItem("sell", "minecraft:bread", 6),
+					{id: tagInt, name: "uses", payload: int32(2)},
+					{id: tagInt, name: "maxUses", payload: int32(16)},
+					{id: tagByte, name: "rewardExp", payload: byte(1)},
+					{id: tagString, name: "modded", payload: "kept"},
+				},
+				[]Tag{
+					testItem("buy", "minecraft:emerald", 10),
+					testItem("buyB", "minecraft:book", 1),
+					testItem("sell", "minecraft:enchanted_book", 1),
+					{id: tagInt, name: "xp", payload: int32(5)},
+					{id: tagFloat, name: "priceMultiplier", payload: float32(0.2)},
+				},
+			}},
+		}},
+	}}
+
+	got, err := Trades(villager)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %v trades, want 2", len(got))
+	}
+	if got[0].Uses != 2 || got[0].MaxUses != 16 || !got[0].RewardXP || got[0].BuyB.id != tagEnd {
+		t.Errorf("got %+v, want 2 of 16 uses rewarding experience", got[0])
+	}
+	if got[1].XP != 5 || got[1].PriceMultiplier != 0.2 || got[1].BuyB.id != tagCompound {
+		t.Errorf("got %+v, want 5 experience at 0.2 with a second item", got[1])
+	}
+
+	t.Run("Test success case: set trades round trip", func(t *testing.T) {
+		trades, _ := Trades(villager)
+		trades[0].Uses = 0
+		updated, err := SetTrades(villager, trades)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		reread, err := Trades(updated)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if reread[0].Uses != 0 || !reflect.DeepEqual(reread[0].extra, got[0].extra) {
+			t.Errorf("got %+v, want no uses and the modded child kept", reread[0])
+		}
+		if id, _ := childPayload[string](updated, "id"); id != "minecraft:villager" {
+			t.Errorf("got id %v, want minecraft:villager", id)
+		}
+		if again, _ := Trades(villager); again[0].Uses != 2 {
+			t.Errorf("got %v uses in the original, want 2", again[0].Uses)
+		}
+	})
+
+	t.Run("Test success case: add and remove", func(t *testing.T) {
+		added, err := AddTrade(Tag{id: tagCompound}, got[1])
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		trades, _ := Trades(added)
+		if len(trades) != 1 || trades[0].XP != 5 {
+			t.Errorf("got %+v, want the added trade", trades)
+		}
+
+		removed, err := RemoveTrade(villager, 0)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		trades, _ = Trades(removed)
+		if len(trades) != 1 || trades[0].XP != 5 {
+			t.Errorf("got %+v, want only the second trade", trades)
+		}
+	})
+
+	failureCases := []struct {
+		name   string
+		entity Tag
+	}{
+		{"not a compound", Tag{id: tagInt, payload: int32(1)}},
+		{"recipes not a list", Tag{id: tagCompound, payload: []Tag{{id: tagCompound, name: "Offers", payload: []Tag{
+			{id: tagInt, name: "Recipes", payload: int32(1)}}}}}},
+		{"recipe not a compound", Tag{id: tagCompound, payload: []Tag{{id: tagCompound, name: "Offers", payload: []Tag{
+			{id: tagList, name: "Recipes", payload: []any{int32(1)}}}}}}},
+		{"wrong field type", Tag{id: tagCompound, payload: []Tag{{id: tagCompound, name: "Offers", payload: []Tag{
+			{id: tagList, name: "Recipes", payload: []any{[]Tag{{id: tagShort, name: "uses", payload: int16(1)}}}}}}}}},
+		{"item not a compound", Tag{id: tagCompound, payload: []Tag{{id: tagCompound, name: "Offers", payload: []Tag{
+			{id: tagList, name: "Recipes", payload: []any{[]Tag{{id: tagString, name: "buy", payload: "x"}}}}}}}}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			if _, err := Trades(failureCase.entity); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+
+	t.Run("Test failure case: set trade without items", func(t *testing.T) {
+		if _, err := SetTrades(villager, []Trade{{Uses: 1}}); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+
+	t.Run("Test failure case: set trades on a non-compound", func(t *testing.T) {
+		if _, err := SetTrades(Tag{id: tagInt}, nil); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+
+	t.Run("Test failure case: remove out of range", func(t *testing.T) {
+		if _, err := RemoveTrade(villager, 2); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+}
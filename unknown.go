@@ -0,0 +1,64 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// RawPayload is the payload of a tag whose ID is above tagLongArray, as added by future editions or mods, holding the
+// exact bytes read by an UnknownTagReader so the tag can be written back verbatim.
+type RawPayload []byte
+
+// UnknownTagReader reads the payload of a tag with an unknown ID from the buffer, in the byte order of the input,
+// returning its bytes. It is set with WithUnknownTags, and must read exactly the payload so decoding can continue.
+type UnknownTagReader func(id uint8, buffer io.Reader, order binary.ByteOrder) (payload []byte, err error)
+
+// WithUnknownTags reads tags with IDs above tagLongArray as RawPayload using the reader, rather than failing.
+func WithUnknownTags(read UnknownTagReader) Option {
+	return func(o *Options) {
+		o.UnknownTags = read
+	}
+}
+
+// FixedSizeTags returns an UnknownTagReader for tags whose payloads have a fixed size in bytes, by ID. An ID missing
+// from the sizes is an error.
+func FixedSizeTags(sizes map[uint8]int) UnknownTagReader {
+	return func(id uint8, buffer io.Reader, _ binary.ByteOrder) (payload []byte, err error) {
+		size, ok := sizes[id]
+		if !ok {
+			return nil, fmt.Errorf("no size for tag ID %v", id)
+		}
+		if err = checkRemaining(buffer, int64(size), 1); err != nil {
+			return nil, err
+		}
+
+		payload = make([]byte, size)
+		if _, err = io.ReadFull(buffer, payload); err != nil {
+			return nil, fmt.Errorf("unable to read tag ID %v payload: %w", id, err)
+		}
+		return payload, nil
+	}
+}
+
+// DelimitedTags returns an UnknownTagReader for tags whose payloads end with the delimiter, as a heuristic for tags of
+// unknown layout. The payload read includes the delimiter. A delimiter that also occurs within a payload cuts it short,
+// so the bytes after are misread, and no delimiter before the end of the input is an error.
+func DelimitedTags(delimiter []byte) UnknownTagReader {
+	return func(id uint8, buffer io.Reader, _ binary.ByteOrder) (payload []byte, err error) {
+		if len(delimiter) == 0 {
+			return nil, fmt.Errorf("empty delimiter for tag ID %v", id)
+		}
+
+		b := make([]byte, 1)
+		for !bytes.HasSuffix(payload, delimiter) {
+			if _, err = io.ReadFull(buffer, b); err != nil {
+				return nil, fmt.Errorf("unable to read tag ID %v payload to its delimiter: %w", id, err)
+			}
+			payload = append(payload, b[0])
+		}
+		return payload, nil
+	}
+}
@@ -0,0 +1,77 @@
+package nbt
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestUnknownTags(t *testing.T) {
+	// A compound holding tag ID 13 named "u" with a 3 byte payload, then a tagByte, and a list of two ID 13 elements.
+	input := []byte{tagCompound, 0, 0,
+		13, 0, 1, 'u', 1, 2, 3,
+		tagByte, 0, 1, 'b', 7,
+		tagList, 0, 1, 'l', 13, 0, 0, 0, 2, 4, 5, 6, 7, 8, 9,
+		tagEnd}
+	want := Tag{id: tagCompound, payload: []Tag{
+		{id: 13, name: "u", payload: RawPayload{1, 2, 3}},
+		{id: tagByte, name: "b", payload: byte(7)},
+		{id: tagList, elementID: 13, name: "l", payload: []any{RawPayload{4, 5, 6}, RawPayload{7, 8, 9}}},
+	}}
+
+	delimited := []byte{tagCompound, 0, 0, 13, 0, 1, 'u', 1, 2, 0xFF, tagByte, 0, 1, 'b', 7, tagEnd}
+
+	successCases := []struct {
+		name   string
+		want   Tag
+		reader UnknownTagReader
+		input  []byte
+	}{
+		{"fixed size", want, FixedSizeTags(map[uint8]int{13: 3}), input},
+		{"delimited", Tag{id: tagCompound, payload: []Tag{
+			{id: 13, name: "u", payload: RawPayload{1, 2, 0xFF}}, {id: tagByte, name: "b", payload: byte(7)},
+		}}, DelimitedTags([]byte{0xFF}), delimited},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			got, err := ReadTag(bytes.NewReader(successCase.input), WithUnknownTags(successCase.reader))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, successCase.want) {
+				t.Errorf("got %+v, want %+v", got, successCase.want)
+			}
+		})
+	}
+
+	t.Run("Test success case: list elements viewed", func(t *testing.T) {
+		got, err := ReadTag(bytes.NewReader(input), WithUnknownTags(FixedSizeTags(map[uint8]int{13: 3})))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		list, _ := NewView(got).Child("l")
+		element, err := list.Element(1)
+		if err != nil || element.ID() != 13 || !reflect.DeepEqual(element.Payload(), RawPayload{7, 8, 9}) {
+			t.Errorf("got element %v %v %v, want ID 13 and [7 8 9]", element.ID(), element.Payload(), err)
+		}
+	})
+
+	failureCases := []struct {
+		name  string
+		input []byte
+		opts  []Option
+	}{
+		{"unknown tags not enabled", input, nil},
+		{"no size for the ID", input, []Option{WithUnknownTags(FixedSizeTags(map[uint8]int{14: 3}))}},
+		{"truncated fixed size payload", input[:8], []Option{WithUnknownTags(FixedSizeTags(map[uint8]int{13: 3}))}},
+		{"no delimiter", delimited[:9], []Option{WithUnknownTags(DelimitedTags([]byte{0xFF}))}},
+		{"empty delimiter", input, []Option{WithUnknownTags(DelimitedTags(nil))}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			if _, err := ReadTag(bytes.NewReader(failureCase.input), failureCase.opts...); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
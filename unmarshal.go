@@ -0,0 +1,256 @@
+//go:build !nbt_noreflect
+
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Unmarshal reads a tag from the data with the options, as by ReadTag, and stores it in the value pointed to by v, see
+// UnmarshalTag.
+func Unmarshal(data []byte, v any, opts ...Option) error {
+	t, err := ReadTag(bytes.NewReader(data), opts...)
+	if err != nil {
+		return fmt.Errorf("Unable to unmarshal into %T: %w", v, err)
+	}
+	return UnmarshalTag(t, v)
+}
+
+// UnmarshalTag stores the tag in the value pointed to by v, the reverse of MarshalTag. Compound children are matched to
+// struct fields by name, and children without a field are ignored, as are fields without a child. Numbers convert to
+// any Go number type that holds the value, a tagByte converts to bool, and arrays and lists both convert to slices and
+// arrays. An empty interface is set to the tag's payload, with compounds as map[string]any and lists as []any. Nil
+// pointers, slices and maps are allocated as needed.
+func UnmarshalTag(t Tag, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("Unable to unmarshal into %T: not a non-nil pointer", v)
+	}
+
+	err := unmarshalValue(t, rv.Elem())
+	if err != nil {
+		return fmt.Errorf("Unable to unmarshal into %T: %w", v, err)
+	}
+	return nil
+}
+
+// unmarshalAny stores the tag in the value pointed to by v, by UnmarshalTag. It is how the rest of the package stores
+// tags in Go values, as builds with the nbt_noreflect tag have no UnmarshalTag.
+func unmarshalAny(t Tag, v any) error {
+	return UnmarshalTag(t, v)
+}
+
+// unmarshalValue stores the tag in the settable value.
+func unmarshalValue(t Tag, v reflect.Value) (err error) {
+	if v.Type() == tagReflectType {
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return unmarshalValue(t, v.Elem())
+	case reflect.Interface:
+		if v.NumMethod() > 0 {
+			return fmt.Errorf("unsupported type %v", v.Type())
+		}
+		if t.payload == nil {
+			v.SetZero()
+			return nil
+		}
+		v.Set(reflect.ValueOf(exportValue(t.payload)))
+		return nil
+	case reflect.Bool:
+		i, ok := integerPayload(t.payload)
+		if !ok || t.id != tagByte {
+			return unmarshalTypeError(t, v)
+		}
+		v.SetBool(i != 0)
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		i, ok := integerPayload(t.payload)
+		if !ok || v.OverflowInt(i) {
+			return unmarshalTypeError(t, v)
+		}
+		v.SetInt(i)
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+		u, ok := unsignedPayload(t.payload)
+		if !ok || v.OverflowUint(u) {
+			return unmarshalTypeError(t, v)
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, ok := floatPayload(t.payload)
+		if !ok {
+			return unmarshalTypeError(t, v)
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		s, ok := t.payload.(string)
+		if !ok {
+			return unmarshalTypeError(t, v)
+		}
+		v.SetString(s)
+	case reflect.Slice, reflect.Array:
+		return unmarshalSequence(t, v)
+	case reflect.Map:
+		return unmarshalMap(t, v)
+	case reflect.Struct:
+		return unmarshalStruct(t, v)
+	default:
+		return fmt.Errorf("unsupported type %v", v.Type())
+	}
+	return nil
+}
+
+// unmarshalTypeError returns the error for a tag that cannot be stored in the value.
+func unmarshalTypeError(t Tag, v reflect.Value) error {
+	tagType, err := t.tagType()
+	if err != nil {
+		tagType = fmt.Sprintf("tag ID %v", t.id)
+	}
+	return fmt.Errorf("cannot store %v %v in %v", tagType, t.payload, v.Type())
+}
+
+// unsignedPayload returns the bits of an integer payload as an unsigned integer of the payload's size, the reverse of
+// marshalling an unsigned integer.
+func unsignedPayload(payload any) (u uint64, ok bool) {
+	switch p := payload.(type) {
+	case byte:
+		return uint64(p), true
+	case int16:
+		return uint64(uint16(p)), true // #nosec G115 -- the bits of the payload
+	case int32:
+		return uint64(uint32(p)), true // #nosec G115 -- the bits of the payload
+	case int64:
+		return uint64(p), true // #nosec G115 -- the bits of the payload
+	default:
+		return 0, false
+	}
+}
+
+// floatPayload returns a float or integer payload as a float64.
+func floatPayload(payload any) (f float64, ok bool) {
+	switch p := payload.(type) {
+	case float32:
+		return float64(p), true
+	case float64:
+		return p, true
+	default:
+		i, ok := integerPayload(payload)
+		return float64(i), ok && math.Abs(float64(i)) <= 1<<53
+	}
+}
+
+// unmarshalSequence stores the elements of an array tag or tagList in a slice or array. An array must have room for
+// every element, and any elements beyond those of the tag are zeroed.
+func unmarshalSequence(t Tag, v reflect.Value) (err error) {
+	var elements []Tag
+	switch p := t.payload.(type) {
+	case []byte:
+		elements = arrayElements(tagByte, p)
+	case []int32:
+		elements = arrayElements(tagInt, p)
+	case []int64:
+		elements = arrayElements(tagLong, p)
+	case []any:
+		elementID := t.ElementID()
+		for _, element := range p {
+			elements = append(elements, Tag{id: elementID, payload: element})
+		}
+	default:
+		return unmarshalTypeError(t, v)
+	}
+
+	if v.Kind() == reflect.Slice {
+		v.Set(reflect.MakeSlice(v.Type(), len(elements), len(elements)))
+	} else if len(elements) > v.Len() {
+		return fmt.Errorf("%v elements do not fit in %v", len(elements), v.Type())
+	}
+
+	for i := range v.Len() {
+		if i >= len(elements) {
+			v.Index(i).SetZero()
+			continue
+		}
+		err = unmarshalValue(elements[i], v.Index(i))
+		if err != nil {
+			return fmt.Errorf("element %v: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// arrayElements returns the elements of an array payload as unnamed tags of the ID.
+func arrayElements[P byte | int32 | int64](id uint8, payload []P) (elements []Tag) {
+	for _, element := range payload {
+		elements = append(elements, Tag{id: id, payload: element})
+	}
+	return elements
+}
+
+// unmarshalMap stores the children of a tagCompound in a map with string keys.
+func unmarshalMap(t Tag, v reflect.Value) (err error) {
+	children, ok := t.payload.([]Tag)
+	if t.id != tagCompound || (!ok && t.payload != nil) || v.Type().Key().Kind() != reflect.String {
+		return unmarshalTypeError(t, v)
+	}
+
+	if v.IsNil() {
+		v.Set(reflect.MakeMapWithSize(v.Type(), len(children)))
+	}
+	for _, child := range children {
+		value := reflect.New(v.Type().Elem()).Elem()
+		err = unmarshalValue(child, value)
+		if err != nil {
+			return fmt.Errorf("key \"%v\": %w", child.name, err)
+		}
+		v.SetMapIndex(reflect.ValueOf(child.name).Convert(v.Type().Key()), value)
+	}
+	return nil
+}
+
+// unmarshalStruct stores the children of a tagCompound in the struct fields of the same name.
+func unmarshalStruct(t Tag, v reflect.Value) (err error) {
+	children, ok := t.payload.([]Tag)
+	if t.id != tagCompound || (!ok && t.payload != nil) {
+		return unmarshalTypeError(t, v)
+	}
+
+	fields := map[string]structField{}
+	for _, f := range structFields(v.Type()) {
+		fields[f.name] = f
+	}
+	for _, child := range children {
+		f, ok := fields[child.name]
+		if !ok {
+			continue
+		}
+
+		fieldValue := v
+		for _, i := range f.index {
+			if fieldValue.Kind() == reflect.Pointer {
+				if fieldValue.IsNil() {
+					if !fieldValue.CanSet() {
+						return fmt.Errorf("field %v: embedded pointer to unexported struct is nil", f.name)
+					}
+					fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
+				}
+				fieldValue = fieldValue.Elem()
+			}
+			fieldValue = fieldValue.Field(i)
+		}
+
+		err = unmarshalValue(child, fieldValue)
+		if err != nil {
+			return fmt.Errorf("field %v: %w", f.name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+//go:build !nbt_noreflect
+
+package nbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalTag(t *testing.T) {
+	list, _ := NewList("", IDShort, NewShort("", 1), NewShort("", 2))
+	compound, _ := NewCompound("", NewInt("a", 1), list)
+
+	successCases := []struct {
+		name string
+		t    Tag
+		new  func() any
+		want any
+	}{
+		{"byte as bool", NewByte("", 1), func() any { return new(bool) }, true},
+		{"signed byte widened", NewByte("", 0xFF), func() any { return new(int) }, -1},
+		{"unsigned keeps bits", NewShort("", -1), func() any { return new(uint16) }, uint16(0xFFFF)},
+		{"int as float", NewInt("", 7), func() any { return new(float32) }, float32(7)},
+		{"list as slice", list, func() any { return new([]int64) }, []int64{1, 2}},
+		{"array as array", NewIntArray("", []int32{1}), func() any { return new([2]int8) }, [2]int8{1, 0}},
+		{"compound as map", compound, func() any { return new(map[string]any) },
+			map[string]any{"a": int32(1), "": []any{int16(1), int16(2)}}},
+		{"compound as interface", compound, func() any { return new(any) },
+			any(map[string]any{"a": int32(1), "": []any{int16(1), int16(2)}})},
+		{"pointer allocated", NewString("", "s"), func() any { return new(*string) }, func() *string {
+			s := "s"
+			return &s
+		}()},
+		{"unknown children ignored", compound, func() any { return new(struct{ A int32 }) }, struct{ A int32 }{}},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			v := successCase.new()
+			if err := UnmarshalTag(successCase.t, v); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := reflect.ValueOf(v).Elem().Interface(); !reflect.DeepEqual(got, successCase.want) {
+				t.Errorf("got %#v, want %#v", got, successCase.want)
+			}
+		})
+	}
+
+	failureCases := []struct {
+		name string
+		t    Tag
+		v    any
+	}{
+		{"not a pointer", NewInt("", 1), 1},
+		{"nil pointer", NewInt("", 1), (*int)(nil)},
+		{"overflow", NewInt("", 300), new(int8)},
+		{"float into int", NewDouble("", 1), new(int)},
+		{"string into int", NewString("", "1"), new(int)},
+		{"short into bool", NewShort("", 1), new(bool)},
+		{"array too short", NewIntArray("", []int32{1, 2}), new([1]int32)},
+		{"compound into slice", compound, new([]int32)},
+		{"int into struct", NewInt("", 1), new(struct{})},
+		{"field type mismatch", compound, new(struct {
+			A string `nbt:"a"`
+		})},
+		{"non-empty interface", NewInt("", 1), new(error)},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			if err := UnmarshalTag(failureCase.t, failureCase.v); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
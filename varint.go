@@ -0,0 +1,153 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Bedrock edition's network serialisation of NBT, used in game packets, encodes the payloads of tagInt and tagLong,
+// the elements of tagIntArray and tagLongArray, and the sizes of arrays and lists as ZigZag encoded VarInts, and the
+// lengths of names and strings as unsigned VarInts. A VarInt is little-endian groups of seven bits, with the high bit
+// of each byte set if another follows. Every other number keeps its fixed width in the byte order.
+
+// maxVarIntLen32 and maxVarIntLen64 are the most bytes a 32 and 64 bit VarInt can be encoded in.
+const (
+	maxVarIntLen32 = 5
+	maxVarIntLen64 = 10
+)
+
+// BedrockNetwork is an Option preset selecting the conventions of Bedrock edition network NBT: little-endian with
+// UTF-8 strings and VarInt numbers and lengths. It is passed as is, as in ReadTag(r, BedrockNetwork), and later options
+// override it.
+func BedrockNetwork(o *Options) {
+	BedrockEdition(o)
+	o.VarInt = true
+}
+
+// WithVarInt sets whether numbers and lengths are VarInts, as in Bedrock edition network NBT.
+func WithVarInt(varInt bool) Option {
+	return func(o *Options) {
+		o.VarInt = varInt
+	}
+}
+
+// readUvarint reads an unsigned VarInt of at most maxLen bytes.
+func readUvarint(buffer io.Reader, maxLen int) (v uint64, err error) {
+	b := make([]byte, 1)
+	for i := 0; i < maxLen; i++ {
+		_, err = io.ReadFull(buffer, b)
+		if err != nil {
+			return 0, err
+		}
+		v |= uint64(b[0]&0x7F) << (7 * i)
+		if b[0]&0x80 == 0 {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("VarInt longer than %v bytes", maxLen)
+}
+
+// writeUvarint writes an unsigned VarInt.
+func writeUvarint(buffer io.Writer, v uint64) (err error) {
+	_, err = buffer.Write(binary.AppendUvarint(nil, v))
+	return err
+}
+
+// readInt32 reads a signed 32 bit integer, a ZigZag VarInt if the Options select VarInts.
+func readInt32(buffer io.Reader, o Options) (v int32, err error) {
+	if !o.VarInt {
+		err = binary.Read(buffer, o.ByteOrder, &v)
+		return v, err
+	}
+
+	u, err := readUvarint(buffer, maxVarIntLen32)
+	if err != nil {
+		return 0, err
+	}
+	if u > 0xFFFFFFFF {
+		return 0, fmt.Errorf("VarInt %v overflows 32 bits", u)
+	}
+	return int32(u>>1) ^ -int32(u&1), nil // #nosec G115 -- checked above
+}
+
+// readInt64 reads a signed 64 bit integer, a ZigZag VarInt if the Options select VarInts.
+func readInt64(buffer io.Reader, o Options) (v int64, err error) {
+	if !o.VarInt {
+		err = binary.Read(buffer, o.ByteOrder, &v)
+		return v, err
+	}
+
+	u, err := readUvarint(buffer, maxVarIntLen64)
+	if err != nil {
+		return 0, err
+	}
+	return int64(u>>1) ^ -int64(u&1), nil // #nosec G115 -- ZigZag decoding
+}
+
+// writeInt32 writes a signed 32 bit integer, a ZigZag VarInt if the Options select VarInts.
+func writeInt32(buffer io.Writer, o Options, v int32) (err error) {
+	if !o.VarInt {
+		return binary.Write(buffer, o.ByteOrder, v)
+	}
+	return writeUvarint(buffer, uint64(uint32(v<<1)^uint32(v>>31))) // #nosec G115 -- ZigZag encoding
+}
+
+// writeInt64 writes a signed 64 bit integer, a ZigZag VarInt if the Options select VarInts.
+func writeInt64(buffer io.Writer, o Options, v int64) (err error) {
+	if !o.VarInt {
+		return binary.Write(buffer, o.ByteOrder, v)
+	}
+	return writeUvarint(buffer, uint64(v<<1)^uint64(v>>63)) // #nosec G115 -- ZigZag encoding
+}
+
+// readStringLength reads the length of a name or string: an unsigned short, or an unsigned VarInt if the Options select
+// VarInts.
+func readStringLength(buffer io.Reader, o Options) (length int, err error) {
+	if !o.VarInt {
+		var l uint16
+		err = binary.Read(buffer, o.ByteOrder, &l)
+		return int(l), err
+	}
+
+	u, err := readUvarint(buffer, maxVarIntLen32)
+	if err != nil {
+		return 0, err
+	}
+	if u > 0xFFFF {
+		return 0, fmt.Errorf("length %v exceeds the maximum of %v", u, 0xFFFF)
+	}
+	return int(u), nil
+}
+
+// writeStringLength writes the length of a name or string, already checked against its maximum: an unsigned short, or
+// an unsigned VarInt if the Options select VarInts.
+func writeStringLength(buffer io.Writer, o Options, length int) (err error) {
+	if !o.VarInt {
+		return binary.Write(buffer, o.ByteOrder, uint16(length)) // #nosec G115 -- checked by the caller
+	}
+	return writeUvarint(buffer, uint64(length)) // #nosec G115 -- checked by the caller
+}
+
+// minVarIntPayloadSize returns the fewest bytes a payload of the tag ID can be encoded in with VarInt numbers and
+// lengths, used to check list lengths.
+func minVarIntPayloadSize(id uint8) int64 {
+	switch id {
+	case tagInt, tagLong, tagString, tagByteArray, tagIntArray, tagLongArray:
+		return 1
+	case tagList:
+		return 2
+	default:
+		return minPayloadSize(id)
+	}
+}
+
+// minArrayElementSize returns the fewest bytes an element of a tagIntArray or tagLongArray of the fixed size can be
+// encoded in.
+func minArrayElementSize(o Options, size int64) int64 {
+	if o.VarInt {
+		return 1
+	}
+	return size
+}
@@ -0,0 +1,86 @@
+package nbt
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestBedrockNetwork(t *testing.T) {
+	tag, _ := NewCompound("",
+		NewInt("a", -1),
+		NewLong("b", 150),
+		NewString("s", "hi"),
+		NewIntArray("i", []int32{1, -2}),
+		Tag{id: tagList, name: "l", elementID: tagInt, payload: []any{int32(0)}},
+		NewShort("h", 1),
+	)
+	encoded := []byte{
+		tagCompound, 0,
+		tagInt, 1, 'a', 0x01,
+		tagLong, 1, 'b', 0xAC, 0x02,
+		tagString, 1, 's', 2, 'h', 'i',
+		tagIntArray, 1, 'i', 0x04, 0x02, 0x03,
+		tagList, 1, 'l', tagInt, 0x02, 0x00,
+		tagShort, 1, 'h', 1, 0,
+		tagEnd,
+	}
+
+	t.Run("Test success case: write", func(t *testing.T) {
+		var buffer bytes.Buffer
+		if err := WriteTag(&buffer, tag, BedrockNetwork); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !bytes.Equal(buffer.Bytes(), encoded) {
+			t.Errorf("got % X, want % X", buffer.Bytes(), encoded)
+		}
+	})
+
+	t.Run("Test success case: read", func(t *testing.T) {
+		got, err := ReadTag(bytes.NewReader(encoded), BedrockNetwork)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !payloadsEqual(got.payload, tag.payload) {
+			t.Errorf("got %v, want %v", got, tag)
+		}
+	})
+
+	t.Run("Test success case: extremes round trip", func(t *testing.T) {
+		extremes, _ := NewCompound("",
+			NewInt("min", math.MinInt32), NewInt("max", math.MaxInt32),
+			NewLong("min", math.MinInt64), NewLong("max", math.MaxInt64),
+			NewLongArray("a", []int64{math.MinInt64, 0, math.MaxInt64}),
+		)
+		var buffer bytes.Buffer
+		if err := WriteTag(&buffer, extremes, BedrockNetwork); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got, err := ReadTag(&buffer, BedrockNetwork)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !payloadsEqual(got.payload, extremes.payload) {
+			t.Errorf("got %v, want %v", got, extremes)
+		}
+	})
+
+	failureCases := []struct {
+		name  string
+		input []byte
+	}{
+		{"VarInt too long", []byte{tagInt, 0, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01}},
+		{"VarInt overflows 32 bits", []byte{tagInt, 0, 0xFF, 0xFF, 0xFF, 0xFF, 0x1F}},
+		{"truncated VarInt", []byte{tagLong, 0, 0x80}},
+		{"string length too long", []byte{tagString, 0, 0x80, 0x80, 0x04}},
+		{"name length too long", []byte{tagString, 0x80, 0x80, 0x02}},
+		{"list length exceeds input", []byte{tagList, 0, tagInt, 0x08, 0x00}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			if _, err := ReadTag(bytes.NewReader(failureCase.input), BedrockNetwork); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
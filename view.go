@@ -0,0 +1,132 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"fmt"
+	"slices"
+)
+
+// View is a read-only window onto a Tag. It exposes the read side of a tag tree with no way to modify it, so a single
+// decoded tree (such as a cached chunk template) can be shared between goroutines without copying it first. Array
+// payloads are copied on the way out so callers can never write through to the shared backing slices.
+type View struct {
+	tag Tag
+}
+
+// NewView wraps a tag in a read-only View. The tag tree is not copied, so the caller must not modify the tree through
+// any other reference once it has been shared as a View.
+func NewView(t Tag) View {
+	return View{tag: t}
+}
+
+// ID returns the tag ID of the viewed tag.
+func (v View) ID() uint8 {
+	return v.tag.id
+}
+
+// Name returns the name of the viewed tag. List elements are always unnamed.
+func (v View) Name() string {
+	return v.tag.name
+}
+
+// Type returns the type name associated with the tag ID of the viewed tag.
+func (v View) Type() (string, error) {
+	return v.tag.tagType()
+}
+
+// ElementID returns the tag ID of the elements of a viewed tagList, see Tag.ElementID.
+func (v View) ElementID() uint8 {
+	return v.tag.ElementID()
+}
+
+// Len returns the number of children of a tagCompound, elements of a tagList or array tag, or bytes of a tagString.
+// All other tag types have a length of 0.
+func (v View) Len() int {
+	switch p := v.tag.payload.(type) {
+	case []Tag:
+		return len(p)
+	case []any:
+		return len(p)
+	case []byte:
+		return len(p)
+	case RawPayload:
+		return len(p)
+	case []int32:
+		return len(p)
+	case []int64:
+		return len(p)
+	case string:
+		return len(p)
+	default:
+		return 0
+	}
+}
+
+// Payload returns the payload of a scalar, string or array tag. Array payloads are copies, modifying them does not
+// modify the viewed tag. The payloads of tagCompound and tagList are not returned, use Child and Element to walk them.
+func (v View) Payload() any {
+	switch p := v.tag.payload.(type) {
+	case []byte:
+		return slices.Clone(p)
+	case RawPayload:
+		return slices.Clone(p)
+	case []int32:
+		return slices.Clone(p)
+	case []int64:
+		return slices.Clone(p)
+	case []Tag, []any:
+		return nil
+	default:
+		return p
+	}
+}
+
+// Child returns a View of the named child of a tagCompound. The boolean is false if the viewed tag is not a
+// tagCompound or has no child with that name.
+func (v View) Child(name string) (View, bool) {
+	child, ok := compoundChild(v.tag, name)
+	if !ok {
+		return View{}, false
+	}
+
+	return View{tag: child}, true
+}
+
+// Children returns a View of each child of a tagCompound, in the order they were read. A tag that is not a
+// tagCompound has no children.
+func (v View) Children() []View {
+	children, ok := v.tag.payload.([]Tag)
+	if !ok {
+		return nil
+	}
+
+	views := make([]View, len(children))
+	for i, child := range children {
+		views[i] = View{tag: child}
+	}
+
+	return views
+}
+
+// Element returns a View of the i'th element of a tagList. The element is presented as an unnamed tag of the listed
+// type.
+func (v View) Element(i int) (View, error) {
+	elements, ok := v.tag.payload.([]any)
+	if !ok {
+		return View{}, fmt.Errorf("Unable to view element %v: tag ID %v is not a tagList", i, v.tag.id)
+	}
+
+	if i < 0 || i >= len(elements) {
+		return View{}, fmt.Errorf("Unable to view element %v: index out of range of length %v", i, len(elements))
+	}
+
+	id, err := payloadID(elements[i])
+	if _, raw := elements[i].(RawPayload); raw {
+		id, err = v.tag.elementID, nil
+	}
+	if err != nil {
+		return View{}, fmt.Errorf("Unable to view element %v: %w", i, err)
+	}
+
+	return View{tag: Tag{id: id, payload: elements[i]}}, nil
+}
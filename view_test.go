@@ -0,0 +1,136 @@
+package nbt
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestView(t *testing.T) {
+	level := Tag{id: tagCompound, payload: []Tag{
+		{id: tagString, name: "LevelName", payload: "My World"},
+		{id: tagInt, name: "SpawnY", payload: int32(64)},
+		{id: tagIntArray, name: "Ids", payload: []int32{1, 2, 3}},
+		{id: tagList, name: "Pos", payload: []any{float64(1.5), float64(64), float64(-3.25)}},
+	}}
+
+	t.Run("Test success case: tag header", func(t *testing.T) {
+		v := NewView(level)
+		if v.ID() != tagCompound {
+			t.Errorf("got %v, want %v", v.ID(), tagCompound)
+		}
+		if v.Name() != "" {
+			t.Errorf("got %v, want empty name", v.Name())
+		}
+		gotType, gotErr := v.Type()
+		if gotType != "tagCompound" {
+			t.Errorf("got %v, want tagCompound", gotType)
+		}
+		if gotErr != nil {
+			t.Errorf("got %v, want nil", gotErr)
+		}
+	})
+
+	lenCases := []struct {
+		name    string
+		wantLen int
+		t       Tag
+	}{
+		{"tagCompound", 4, level},
+		{"tagList", 3, level.payload.([]Tag)[3]},
+		{"tagIntArray", 3, level.payload.([]Tag)[2]},
+		{"tagString", 8, level.payload.([]Tag)[0]},
+		{"tagInt", 0, level.payload.([]Tag)[1]},
+	}
+	for _, lenCase := range lenCases {
+		t.Run("Test success case: length of "+lenCase.name, func(t *testing.T) {
+			gotLen := NewView(lenCase.t).Len()
+			if gotLen != lenCase.wantLen {
+				t.Errorf("got %v, want %v", gotLen, lenCase.wantLen)
+			}
+		})
+	}
+
+	t.Run("Test success case: child lookup", func(t *testing.T) {
+		child, ok := NewView(level).Child("SpawnY")
+		if !ok {
+			t.Fatalf("got false, want true")
+		}
+		if child.Payload() != int32(64) {
+			t.Errorf("got %v, want 64", child.Payload())
+		}
+		if len(NewView(level).Children()) != 4 {
+			t.Errorf("got %v children, want 4", len(NewView(level).Children()))
+		}
+	})
+
+	t.Run("Test success case: array payload is a copy", func(t *testing.T) {
+		child, _ := NewView(level).Child("Ids")
+		ids := child.Payload().([]int32)
+		ids[0] = 99
+		if level.payload.([]Tag)[2].payload.([]int32)[0] != 1 {
+			t.Errorf("got modified backing array, want unmodified")
+		}
+	})
+
+	t.Run("Test success case: list element", func(t *testing.T) {
+		pos, _ := NewView(level).Child("Pos")
+		element, gotErr := pos.Element(2)
+		if element.ID() != tagDouble {
+			t.Errorf("got %v, want %v", element.ID(), tagDouble)
+		}
+		if element.Payload() != float64(-3.25) {
+			t.Errorf("got %v, want -3.25", element.Payload())
+		}
+		if gotErr != nil {
+			t.Errorf("got %v, want nil", gotErr)
+		}
+	})
+
+	t.Run("Test success case: concurrent readers", func(t *testing.T) {
+		v := NewView(level)
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for _, child := range v.Children() {
+					_ = child.Payload()
+				}
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("Test failure case: missing child", func(t *testing.T) {
+		_, ok := NewView(level).Child("Missing")
+		if ok {
+			t.Errorf("got true, want false")
+		}
+	})
+
+	t.Run("Test failure case: child of non-compound", func(t *testing.T) {
+		child, _ := NewView(level).Child("SpawnY")
+		_, ok := child.Child("SpawnY")
+		if ok {
+			t.Errorf("got true, want false")
+		}
+	})
+
+	elementFailureCases := []struct {
+		name  string
+		index int
+		t     Tag
+	}{
+		{"element of non-list", 0, level},
+		{"negative index", -1, level.payload.([]Tag)[3]},
+		{"index out of range", 3, level.payload.([]Tag)[3]},
+	}
+	for _, failureCase := range elementFailureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			_, gotErr := NewView(failureCase.t).Element(failureCase.index)
+			if gotErr == nil {
+				t.Errorf("got nil, want non-nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// SkipChildren is returned by a WalkFunc to skip the children of the tag it was called with. Returned for a tag
+// without children, it is ignored.
+var SkipChildren = errors.New("skip the children of this tag")
+
+// SkipAll is returned by a WalkFunc to stop the walk, which Walk then reports as success.
+var SkipAll = errors.New("skip every remaining tag")
+
+// WalkFunc is called by Walk for each tag with the Path to it from the root. It may modify the tag through the pointer,
+// and a compound or list it modifies is walked as modified. Any error but SkipChildren or SkipAll stops the walk and is
+// returned by Walk.
+type WalkFunc func(path Path, t *Tag) error
+
+// Walk calls fn for t and every tag below it, depth first with each compound or list before its children. The children
+// of compounds and the elements of lists are visited, not those of arrays. Compounds and lists below t are copied as
+// they are walked, so modifications are seen only through t and never by trees sharing its payloads. An element of a
+// list must keep the listed ID.
+func Walk(t *Tag, fn WalkFunc) (err error) {
+	err = walkTag(Path{}, t, fn)
+	if errors.Is(err, SkipAll) {
+		return nil
+	}
+	return err
+}
+
+// walkTag calls fn for t at the path then walks the tags below it, storing any modified children in t.
+func walkTag(path Path, t *Tag, fn WalkFunc) (err error) {
+	err = fn(path, t)
+	if errors.Is(err, SkipChildren) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	switch payload := t.payload.(type) {
+	case []Tag:
+		children := slices.Clone(payload)
+		t.payload = children
+		for i := range children {
+			if err = walkTag(appendPath(path, children[i].name), &children[i], fn); err != nil {
+				return err
+			}
+		}
+	case []any:
+		elements := slices.Clone(payload)
+		t.payload = elements
+		for i := range elements {
+			element := collectionElement(*t, i)
+			err = walkTag(appendPath(path, i), &element, fn)
+			if element.id != t.elementID {
+				return fmt.Errorf("Unable to walk %v: element changed from ID %v to %v", appendPath(path, i),
+					t.elementID, element.id)
+			}
+			elements[i] = element.payload
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package nbt
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	pos, _ := NewList("Pos", IDDouble, NewDouble("", 1), NewDouble("", 2))
+	data, _ := NewCompound("Data", NewString("LevelName", "World"), pos)
+	root, _ := NewCompound("", data, NewInt("version", 19133))
+
+	t.Run("Test success case: every path depth first", func(t *testing.T) {
+		var got []string
+		gotErr := Walk(&root, func(path Path, t *Tag) error {
+			got = append(got, path.String())
+			return nil
+		})
+		want := []string{"", "Data", "Data.LevelName", "Data.Pos", "Data.Pos[0]", "Data.Pos[1]", "version"}
+		if !reflect.DeepEqual(got, want) || gotErr != nil {
+			t.Errorf("got %v %v, want %v", got, gotErr, want)
+		}
+	})
+
+	t.Run("Test success case: skip children", func(t *testing.T) {
+		var got []string
+		gotErr := Walk(&root, func(path Path, t *Tag) error {
+			got = append(got, path.String())
+			if t.name == "Data" {
+				return SkipChildren
+			}
+			return nil
+		})
+		if want := []string{"", "Data", "version"}; !reflect.DeepEqual(got, want) || gotErr != nil {
+			t.Errorf("got %v %v, want %v", got, gotErr, want)
+		}
+	})
+
+	t.Run("Test success case: skip all", func(t *testing.T) {
+		var got []string
+		gotErr := Walk(&root, func(path Path, t *Tag) error {
+			got = append(got, path.String())
+			if t.name == "LevelName" {
+				return SkipAll
+			}
+			return nil
+		})
+		if want := []string{"", "Data", "Data.LevelName"}; !reflect.DeepEqual(got, want) || gotErr != nil {
+			t.Errorf("got %v %v, want %v", got, gotErr, want)
+		}
+	})
+
+	t.Run("Test success case: modify without aliasing", func(t *testing.T) {
+		edited := root
+		gotErr := Walk(&edited, func(path Path, t *Tag) error {
+			switch p := t.payload.(type) {
+			case float64:
+				t.payload = p * 10
+			case string:
+				t.payload = "Edited"
+			}
+			return nil
+		})
+		if gotErr != nil {
+			t.Fatalf("Unexpected error: %v", gotErr)
+		}
+		if got := edited.String(); got != `{Data:{LevelName:"Edited",Pos:[10d,20d]},version:19133}` {
+			t.Errorf("got %v", got)
+		}
+		if got := root.String(); got != `{Data:{LevelName:"World",Pos:[1d,2d]},version:19133}` {
+			t.Errorf("original modified: %v", got)
+		}
+	})
+
+	t.Run("Test failure case: callback error", func(t *testing.T) {
+		wantErr := errors.New("stop")
+		gotErr := Walk(&root, func(path Path, t *Tag) error {
+			if t.name == "Pos" {
+				return wantErr
+			}
+			return nil
+		})
+		if gotErr != wantErr {
+			t.Errorf("got %v, want %v", gotErr, wantErr)
+		}
+	})
+
+	t.Run("Test failure case: element changes ID", func(t *testing.T) {
+		edited := root
+		gotErr := Walk(&edited, func(path Path, t *Tag) error {
+			if _, ok := t.payload.(float64); ok {
+				*t = NewInt("", 1)
+			}
+			return nil
+		})
+		if gotErr == nil {
+			t.Errorf("got nil, want non-nil")
+		}
+	})
+}
@@ -0,0 +1,65 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// WatchEvent reports a watched file that was decoded after it changed, or the error that stopped it being decoded.
+type WatchEvent struct {
+	Path string
+	Tag  Tag
+	Err  error
+}
+
+// Watch polls NBT files such as level.dat and playerdata/*.dat, calling onChange with the freshly decoded tag each time
+// a file's modification time or size changes, so dashboards can follow a running server's world state. Every file is
+// decoded once when watching starts. Gzip compressed files are decompressed before decoding. A file that cannot be
+// read or decoded is reported with Err set, and is tried again when it next changes. The options configure decoding.
+//
+// Watch blocks until the context is done, then returns the context's error. Region files are not supported.
+func Watch(ctx context.Context, interval time.Duration, paths []string, onChange func(WatchEvent),
+	opts ...Option) error {
+	type state struct {
+		modTime time.Time
+		size    int64
+		missing bool
+	}
+	seen := map[string]state{}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, path := range paths {
+			info, err := os.Stat(path)
+			current := state{missing: err != nil}
+			if err == nil {
+				current.modTime, current.size = info.ModTime(), info.Size()
+			}
+
+			previous, ok := seen[path]
+			if ok && previous == current {
+				continue
+			}
+			seen[path] = current
+
+			if err != nil {
+				onChange(WatchEvent{Path: path, Err: fmt.Errorf("Unable to watch file: %w", err)})
+				continue
+			}
+
+			t, err := ReadFile(path, opts...)
+			onChange(WatchEvent{Path: path, Tag: t, Err: err})
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
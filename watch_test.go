@@ -0,0 +1,91 @@
+package nbt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeGzipFile writes the gzip compressed input to path, setting the file modification time.
+func writeGzipFile(t *testing.T, path string, input []byte, modTime time.Time) {
+	t.Helper()
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	_, _ = w.Write(input)
+	_ = w.Close()
+
+	if err := os.WriteFile(path, b.Bytes(), 0o600); err != nil {
+		t.Fatalf("Unable to write test file: %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Unable to set test file time: %v", err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	level := filepath.Join(dir, "level.dat")
+	raw := filepath.Join(dir, "raw.dat")
+	missing := filepath.Join(dir, "missing.dat")
+
+	start := time.Now().Add(-time.Hour)
+	writeGzipFile(t, level, []byte{0x03, 0x00, 0x01, 0x61, 0x00, 0x00, 0x00, 0x01}, start)
+	if err := os.WriteFile(raw, []byte{0x01, 0x00, 0x01, 0x62, 0x07}, 0o600); err != nil {
+		t.Fatalf("Unable to write test file: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan WatchEvent, 10)
+	done := make(chan error)
+	go func() {
+		done <- Watch(ctx, time.Millisecond, []string{level, raw, missing}, func(e WatchEvent) { events <- e })
+	}()
+
+	t.Run("Test success case: initial decode", func(t *testing.T) {
+		got := map[string]WatchEvent{}
+		for range 3 {
+			e := <-events
+			got[e.Path] = e
+		}
+		if got[level].Tag.payload != int32(1) || got[level].Err != nil {
+			t.Errorf("got %v, want payload 1", got[level])
+		}
+		if got[raw].Tag.payload != byte(7) || got[raw].Err != nil {
+			t.Errorf("got %v, want payload 7", got[raw])
+		}
+		if got[missing].Err == nil {
+			t.Errorf("got nil, want non-nil")
+		}
+	})
+
+	t.Run("Test success case: change is decoded", func(t *testing.T) {
+		writeGzipFile(t, level, []byte{0x03, 0x00, 0x01, 0x61, 0x00, 0x00, 0x00, 0x02}, start.Add(time.Minute))
+		e := <-events
+		if e.Path != level || e.Tag.payload != int32(2) {
+			t.Errorf("got %v, want payload 2 from %v", e, level)
+		}
+	})
+
+	t.Run("Test failure case: corrupt change is reported", func(t *testing.T) {
+		writeGzipFile(t, level, []byte{0x03, 0x00}, start.Add(2*time.Minute))
+		e := <-events
+		if e.Path != level || e.Err == nil {
+			t.Errorf("got %v, want error from %v", e, level)
+		}
+	})
+
+	t.Run("Test success case: cancelled context stops watching", func(t *testing.T) {
+		cancel()
+		gotErr := <-done
+		if !errors.Is(gotErr, context.Canceled) {
+			t.Errorf("got %v, want %v", gotErr, context.Canceled)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package world
+
+import (
+	"errors"
+	"fmt"
+
+	"PudFish/nbt"
+	"PudFish/nbt/coords"
+	"PudFish/nbt/region"
+)
+
+// ChunkTransform returns the chunk at chunk coordinates x and z transformed, and true if it changed and must be
+// written back.
+type ChunkTransform func(chunkX, chunkZ int, chunk nbt.Tag) (nbt.Tag, bool, error)
+
+// TransformChunks applies the transform to every chunk of the region files of the kind in the dimension, writing back
+// each chunk it changes, such as to replace a block everywhere. Region files are transformed on at most workers
+// goroutines at a time, or one per CPU if workers is not positive, each file by one goroutine reading and writing one
+// chunk at a time. Changed chunks keep their compression and are timestamped now.
+//
+// A chunk that fails to decode, transform or write does not stop the rest, and the returned error is an nbt.BatchError
+// of the region files, named by their path within the world folder, holding chunks that failed. TransformChunks holds
+// the world's session lock throughout, so the world must have been opened with Open and not be open in the game. The
+// options configure decoding and encoding.
+func (w *World) TransformChunks(d Dimension, kind RegionKind, workers int, transform ChunkTransform,
+	opts ...nbt.Option) (err error) {
+	lock, err := w.lock()
+	if err != nil {
+		return fmt.Errorf("Unable to transform %v %v chunks: %w", d, kind, err)
+	}
+	defer func() {
+		err = errors.Join(err, lock.Unlock())
+	}()
+
+	regions, err := w.Regions(d, kind)
+	if err != nil {
+		return fmt.Errorf("Unable to transform %v %v chunks: %w", d, kind, err)
+	}
+	names := make([]string, len(regions))
+	positions := map[string]RegionPos{}
+	for i, pos := range regions {
+		names[i] = RegionPath(d, kind, coords.RegionToChunk(pos.X), coords.RegionToChunk(pos.Z))
+		positions[names[i]] = pos
+	}
+
+	return nbt.RunBatch(names, workers, func(name string) error {
+		return w.transformRegion(d, kind, positions[name], transform, opts)
+	})
+}
+
+// transformRegion applies the transform to every chunk of the region file of the kind in the dimension at pos,
+// returning the errors of the chunks that failed joined.
+func (w *World) transformRegion(d Dimension, kind RegionKind, pos RegionPos, transform ChunkTransform,
+	opts []nbt.Option) (err error) {
+	r, f, err := w.openWritable(d, kind, coords.RegionToChunk(pos.X), coords.RegionToChunk(pos.Z), false)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, f.Close())
+	}()
+
+	var errs []error
+	for chunk, err := range r.Chunks(opts...) {
+		chunkX, chunkZ := chunk.ChunkPos(pos.X, pos.Z)
+		if err == nil {
+			err = transformChunk(r, chunk, chunkX, chunkZ, transform, opts)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("chunk %v,%v: %w", chunkX, chunkZ, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// transformChunk applies the transform to the chunk at chunk coordinates x and z of the region, writing it back if it
+// changed.
+func transformChunk(r *region.Region, chunk region.Chunk, chunkX, chunkZ int, transform ChunkTransform,
+	opts []nbt.Option) error {
+	t, changed, err := transform(chunkX, chunkZ, chunk.Tag)
+	if err != nil || !changed {
+		return err
+	}
+	return r.WriteChunk(region.Chunk{ChunkHeader: region.ChunkHeader{X: chunk.X, Z: chunk.Z}, Tag: t,
+		Compression: chunk.Compression}, opts...)
+}
@@ -0,0 +1,54 @@
+package world
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"PudFish/nbt"
+)
+
+func TestTransformChunks(t *testing.T) {
+	dir := t.TempDir()
+	writeTestRegion(t, dir, "region/r.0.0.mca", testChunk(0, 0, 1), testChunk(1, 0, 5))
+	writeTestRegion(t, dir, "region/r.-1.-1.mca", testChunk(-1, -1, 1))
+	if err := os.WriteFile(filepath.Join(dir, "region", "r.1.0.mca"), []byte("corrupt"), 0o600); err != nil {
+		t.Fatalf("Unable to create test world: %v", err)
+	}
+	w := Open(dir)
+
+	// inhabit sets the InhabitedTime of chunks inhabited for under 5 ticks to 100.
+	inhabit := func(chunkX, chunkZ int, chunk nbt.Tag) (nbt.Tag, bool, error) {
+		if !InhabitedTimeBelow(5)(chunkX, chunkZ, chunk) {
+			return chunk, false, nil
+		}
+		return testChunk(chunkX, chunkZ, 100), true, nil
+	}
+	err := w.TransformChunks(Overworld, Chunks, 2, inhabit)
+
+	t.Run("Test success case: changed chunks written", func(t *testing.T) {
+		for _, pos := range []ChunkPos{{0, 0}, {-1, -1}} {
+			chunk, ok, err := w.Chunk(Overworld, Chunks, pos.X, pos.Z)
+			if err != nil || !ok || InhabitedTimeBelow(100)(pos.X, pos.Z, chunk) {
+				t.Errorf("%v: got %v %v %v, want the chunk transformed", pos, chunk, ok, err)
+			}
+		}
+		if chunk, _, _ := w.Chunk(Overworld, Chunks, 1, 0); !InhabitedTimeBelow(100)(1, 0, chunk) {
+			t.Errorf("got %v, want chunk 1,0 unchanged", chunk)
+		}
+	})
+
+	t.Run("Test failure case: corrupt region reported", func(t *testing.T) {
+		var batch nbt.BatchError
+		if !errors.As(err, &batch) || len(batch) != 1 || batch[0].Path != "region/r.1.0.mca" {
+			t.Errorf("got %v, want the error of region/r.1.0.mca", err)
+		}
+	})
+
+	t.Run("Test failure case: world not opened from a directory", func(t *testing.T) {
+		if err := New(testFS(t)).TransformChunks(Overworld, Chunks, 1, inhabit); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+}
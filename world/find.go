@@ -0,0 +1,157 @@
+package world
+
+import (
+	"errors"
+	"io/fs"
+	"iter"
+	"math"
+
+	"PudFish/nbt"
+	"PudFish/nbt/coords"
+	"PudFish/nbt/region"
+)
+
+// Bounds is the box of blocks from Min to Max, inclusive, in block coordinates.
+type Bounds struct {
+	Min, Max nbt.BlockPos
+}
+
+// Everywhere is the Bounds holding every block of a world.
+var Everywhere = Bounds{
+	Min: nbt.BlockPos{X: math.MinInt32, Y: math.MinInt32, Z: math.MinInt32},
+	Max: nbt.BlockPos{X: math.MaxInt32, Y: math.MaxInt32, Z: math.MaxInt32},
+}
+
+// Contains reports whether the block at pos is within the bounds.
+func (b Bounds) Contains(pos nbt.BlockPos) bool {
+	return b.Min.X <= pos.X && pos.X <= b.Max.X && b.Min.Y <= pos.Y && pos.Y <= b.Max.Y && b.Min.Z <= pos.Z &&
+		pos.Z <= b.Max.Z
+}
+
+// overlapsChunk reports whether any block column of the chunk at chunk coordinates x and z is within the bounds.
+func (b Bounds) overlapsChunk(chunkX, chunkZ int) bool {
+	return int(b.Min.X) <= coords.ChunkToBlock(chunkX+1)-1 && coords.ChunkToBlock(chunkX) <= int(b.Max.X) &&
+		int(b.Min.Z) <= coords.ChunkToBlock(chunkZ+1)-1 && coords.ChunkToBlock(chunkZ) <= int(b.Max.Z)
+}
+
+// overlapsRegion reports whether any block column of the region at region coordinates x and z is within the bounds.
+func (b Bounds) overlapsRegion(pos RegionPos) bool {
+	return coords.BlockToRegion(int(b.Min.X)) <= pos.X && pos.X <= coords.BlockToRegion(int(b.Max.X)) &&
+		coords.BlockToRegion(int(b.Min.Z)) <= pos.Z && pos.Z <= coords.BlockToRegion(int(b.Max.Z))
+}
+
+// FindBlocks returns an iterator over the positions of the blocks of the dimension within the bounds whose state
+// matches, such as every command block of a world, region by region in the order of Regions. Only the region files
+// and chunks overlapping the bounds are read, one chunk at a time, and sections whose palette has no matching entry
+// are skipped without unpacking their blocks, see nbt.FindBlocks. A region, chunk or section that fails to read is
+// yielded with its error, and the search continues. The options configure decoding.
+func (w *World) FindBlocks(d Dimension, match func(nbt.BlockState) bool, bounds Bounds,
+	opts ...nbt.Option) iter.Seq2[nbt.BlockPos, error] {
+	return func(yield func(nbt.BlockPos, error) bool) {
+		regions, err := w.Regions(d, Chunks)
+		if err != nil {
+			yield(nbt.BlockPos{}, err)
+			return
+		}
+
+		for _, pos := range regions {
+			if !bounds.overlapsRegion(pos) {
+				continue
+			}
+			if !w.findRegionBlocks(d, pos, match, bounds, opts, yield) {
+				return
+			}
+		}
+	}
+}
+
+// findRegionBlocks yields the matching blocks within the bounds of the region file at pos, returning false if yield
+// did.
+func (w *World) findRegionBlocks(d Dimension, pos RegionPos, match func(nbt.BlockState) bool, bounds Bounds,
+	opts []nbt.Option, yield func(nbt.BlockPos, error) bool) bool {
+	r, err := w.Region(d, Chunks, coords.RegionToChunk(pos.X), coords.RegionToChunk(pos.Z))
+	if errors.Is(err, fs.ErrNotExist) {
+		return true
+	}
+	if err != nil {
+		return yield(nbt.BlockPos{}, err)
+	}
+	defer r.Close()
+
+	for h := range r.Headers() {
+		chunkX, chunkZ := h.ChunkPos(pos.X, pos.Z)
+		if !bounds.overlapsChunk(chunkX, chunkZ) {
+			continue
+		}
+		chunk, _, err := r.Chunk(chunkX, chunkZ, opts...)
+		if err != nil {
+			if !yield(nbt.BlockPos{}, err) {
+				return false
+			}
+			continue
+		}
+		for block, err := range nbt.FindBlocks(chunk, match) {
+			if (err != nil || bounds.Contains(block)) && !yield(block, err) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// EntityMatch is an entity found by World.FindEntities, with the kind of region file and the position of the chunk
+// holding it, so it can be removed or modified with nbt.EditEntities and written back, such as with a Tx.
+type EntityMatch struct {
+	region.EntityMatch
+	Kind  RegionKind
+	Chunk ChunkPos
+}
+
+// FindEntities returns an iterator over the entities of the dimension that match, such as those of a type or UUID,
+// region by region in the order of Regions, reading one chunk at a time. Entities are read from the entity region
+// files of Java edition 1.17 and later, or from the Entities lists of the chunks of region files if the dimension has
+// no entity region files, as before 1.17. A region or chunk that fails to read is yielded with its error, and the
+// search continues. The options configure decoding.
+func (w *World) FindEntities(d Dimension, match func(entity nbt.Tag) bool,
+	opts ...nbt.Option) iter.Seq2[EntityMatch, error] {
+	return func(yield func(EntityMatch, error) bool) {
+		kind := Entities
+		regions, err := w.Regions(d, kind)
+		if err == nil && len(regions) == 0 {
+			kind = Chunks
+			regions, err = w.Regions(d, kind)
+		}
+		if err != nil {
+			yield(EntityMatch{}, err)
+			return
+		}
+
+		for _, pos := range regions {
+			if !w.findRegionEntities(d, kind, pos, match, opts, yield) {
+				return
+			}
+		}
+	}
+}
+
+// findRegionEntities yields the matching entities of the region file of the kind at pos, returning false if yield
+// did.
+func (w *World) findRegionEntities(d Dimension, kind RegionKind, pos RegionPos, match func(entity nbt.Tag) bool,
+	opts []nbt.Option, yield func(EntityMatch, error) bool) bool {
+	r, err := w.Region(d, kind, coords.RegionToChunk(pos.X), coords.RegionToChunk(pos.Z))
+	if errors.Is(err, fs.ErrNotExist) {
+		return true
+	}
+	if err != nil {
+		return yield(EntityMatch{Kind: kind}, err)
+	}
+	defer r.Close()
+
+	for found, err := range r.FindEntities(match, opts...) {
+		chunkX, chunkZ := found.ChunkPos(pos.X, pos.Z)
+		if !yield(EntityMatch{EntityMatch: found, Kind: kind, Chunk: ChunkPos{X: chunkX, Z: chunkZ}}, err) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,156 @@
+package world
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"PudFish/nbt"
+	"PudFish/nbt/region"
+)
+
+// blockChunk returns a chunk at chunk coordinates x and z whose section 0 is filled with the block.
+func blockChunk(x, z int, block string) nbt.Tag {
+	palette, _ := nbt.NewList("palette", nbt.IDCompound, nbt.BlockState{Name: block}.Tag())
+	states, _ := nbt.NewCompound("block_states", palette)
+	section, _ := nbt.NewCompound("", nbt.NewByte("Y", 0), states)
+	sections, _ := nbt.NewList("sections", nbt.IDCompound, section)
+	tag, _ := nbt.NewCompound("", nbt.NewInt("xPos", int32(x)), nbt.NewInt("zPos", int32(z)), sections)
+	return tag
+}
+
+func TestWorldFindBlocks(t *testing.T) {
+	dir := t.TempDir()
+	writeTestRegion(t, dir, "region/r.0.0.mca", blockChunk(0, 0, "minecraft:ancient_debris"),
+		blockChunk(1, 0, "minecraft:stone"))
+	writeTestRegion(t, dir, "region/r.-1.0.mca", blockChunk(-1, 0, "minecraft:ancient_debris"))
+	if err := os.WriteFile(filepath.Join(dir, "region", "r.5.5.mca"), []byte("corrupt"), 0o600); err != nil {
+		t.Fatalf("Unable to create test world: %v", err)
+	}
+	w := Open(dir)
+	debris := func(state nbt.BlockState) bool {
+		return state.Name == "minecraft:ancient_debris"
+	}
+
+	successCases := []struct {
+		name       string
+		bounds     Bounds
+		want       int
+		wantErrors int
+	}{
+		{"box within a chunk", Bounds{Min: nbt.BlockPos{}, Max: nbt.BlockPos{X: 1, Y: 1, Z: 1}}, 8, 0},
+		{"row across regions", Bounds{Min: nbt.BlockPos{X: -16}, Max: nbt.BlockPos{X: 31}}, 32, 0},
+		{"outside every chunk", Bounds{Min: nbt.BlockPos{X: 100, Z: 100}, Max: nbt.BlockPos{X: 200, Z: 200}}, 0, 0},
+		{"everywhere", Everywhere, 2 * 16 * 16 * 16, 1},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			found, errs := 0, 0
+			for pos, err := range w.FindBlocks(Overworld, debris, successCase.bounds) {
+				if err != nil {
+					errs++
+					continue
+				}
+				if !successCase.bounds.Contains(pos) {
+					t.Errorf("got %v, want it within %v", pos, successCase.bounds)
+				}
+				found++
+			}
+			if found != successCase.want || errs != successCase.wantErrors {
+				t.Errorf("got %v blocks and %v errors, want %v and %v", found, errs, successCase.want,
+					successCase.wantErrors)
+			}
+		})
+	}
+
+	t.Run("Test success case: stops when the caller does", func(t *testing.T) {
+		found := 0
+		for range w.FindBlocks(Overworld, debris, Everywhere) {
+			found++
+			break
+		}
+		if found != 1 {
+			t.Errorf("got %v blocks, want 1", found)
+		}
+	})
+}
+
+// entityChunk returns a chunk at chunk coordinates x and z holding an entity of each of the IDs.
+func entityChunk(x, z int, ids ...string) nbt.Tag {
+	var entities []nbt.Tag
+	for _, id := range ids {
+		entity, _ := nbt.NewCompound("", nbt.NewString("id", id))
+		entities = append(entities, entity)
+	}
+	list, _ := nbt.NewList("Entities", nbt.IDCompound, entities...)
+	tag, _ := nbt.NewCompound("", nbt.NewInt("xPos", int32(x)), nbt.NewInt("zPos", int32(z)), list)
+	return tag
+}
+
+func TestWorldFindEntities(t *testing.T) {
+	modern := t.TempDir()
+	writeTestRegion(t, modern, "entities/r.0.0.mca", entityChunk(2, 3, "minecraft:cow", "minecraft:zombie"))
+	writeTestRegion(t, modern, "entities/r.-1.0.mca", entityChunk(-1, 0, "minecraft:zombie"))
+	writeTestRegion(t, modern, "region/r.0.0.mca", entityChunk(0, 0, "minecraft:zombie"))
+	legacy := t.TempDir()
+	writeTestRegion(t, legacy, "region/r.0.0.mca", entityChunk(4, 5, "minecraft:zombie"))
+	zombies := func(entity nbt.Tag) bool {
+		id, _ := nbt.NewView(entity).Child("id")
+		return id.Payload() == "minecraft:zombie"
+	}
+
+	successCases := []struct {
+		name string
+		dir  string
+		want []EntityMatch
+	}{
+		{"entity region files", modern, []EntityMatch{
+			{Kind: Entities, Chunk: ChunkPos{X: -1, Z: 0}},
+			{EntityMatch: region.EntityMatch{Index: 1}, Kind: Entities, Chunk: ChunkPos{X: 2, Z: 3}},
+		}},
+		{"chunk entities before 1.17", legacy, []EntityMatch{{Kind: Chunks, Chunk: ChunkPos{X: 4, Z: 5}}}},
+		{"no regions", t.TempDir(), nil},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			var got []EntityMatch
+			for found, err := range Open(successCase.dir).FindEntities(Overworld, zombies) {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if !zombies(found.Entity) {
+					t.Errorf("got %v, want a zombie", found.Entity)
+				}
+				got = append(got, found)
+			}
+			if len(got) != len(successCase.want) {
+				t.Fatalf("got %v matches, want %v", len(got), len(successCase.want))
+			}
+			for i, want := range successCase.want {
+				if got[i].Kind != want.Kind || got[i].Chunk != want.Chunk || got[i].Index != want.Index {
+					t.Errorf("got %v %v entity %v, want %v %v entity %v", got[i].Kind, got[i].Chunk, got[i].Index,
+						want.Kind, want.Chunk, want.Index)
+				}
+			}
+		})
+	}
+
+	t.Run("Test failure case: corrupt region", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTestRegion(t, dir, "entities/r.0.0.mca", entityChunk(0, 0, "minecraft:zombie"))
+		if err := os.WriteFile(filepath.Join(dir, "entities", "r.1.0.mca"), []byte("corrupt"), 0o600); err != nil {
+			t.Fatalf("Unable to create test world: %v", err)
+		}
+		found, errs := 0, 0
+		for _, err := range Open(dir).FindEntities(Overworld, zombies) {
+			if err != nil {
+				errs++
+				continue
+			}
+			found++
+		}
+		if found != 1 || errs != 1 {
+			t.Errorf("got %v matches and %v errors, want 1 and 1", found, errs)
+		}
+	})
+}
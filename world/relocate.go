@@ -0,0 +1,167 @@
+package world
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"PudFish/nbt"
+	"PudFish/nbt/coords"
+	"PudFish/nbt/region"
+)
+
+// regionKinds are the kinds of region file storing parts of a chunk.
+var regionKinds = []RegionKind{Chunks, Entities, POI}
+
+// CopyChunk copies the chunk at chunk coordinates x and z of the dimension, with its entities and points of interest,
+// to chunk coordinates toX and toZ of dimension toD of the world to, which may be w. The coordinates stored in the
+// copies are rewritten for their new position, see nbt.RelocateChunk, and each copy keeps its compression and
+// timestamp. Entities and points of interest stored at the destination are deleted if the chunk has none.
+//
+// The destination holds its world's session lock while it is written, so it must have been opened with Open and not be
+// open in the game. Each part of the chunk is written as by region.WriteChunk, so a failed copy leaves every part at
+// the destination either as it was or copied. The error wraps fs.ErrNotExist if there is no chunk to copy. The options
+// configure decoding and encoding.
+func (w *World) CopyChunk(d Dimension, x, z int, to *World, toD Dimension, toX, toZ int, opts ...nbt.Option) (
+	err error) {
+	lock, err := to.lock()
+	if err != nil {
+		return fmt.Errorf("Unable to copy chunk %v,%v: %w", x, z, err)
+	}
+	defer func() {
+		err = errors.Join(err, lock.Unlock())
+	}()
+
+	if err = w.copyChunk(d, x, z, to, toD, toX, toZ, opts); err != nil {
+		return fmt.Errorf("Unable to copy chunk %v,%v: %w", x, z, err)
+	}
+	return nil
+}
+
+// MoveChunk moves the chunk at chunk coordinates x and z of the dimension, with its entities and points of interest,
+// to chunk coordinates toX and toZ, copying it as CopyChunk does then deleting it. The world holds its session lock
+// throughout, so it must have been opened with Open and not be open in the game. The options configure decoding and
+// encoding.
+func (w *World) MoveChunk(d Dimension, x, z, toX, toZ int, opts ...nbt.Option) (err error) {
+	if x == toX && z == toZ {
+		return nil
+	}
+	lock, err := w.lock()
+	if err != nil {
+		return fmt.Errorf("Unable to move chunk %v,%v: %w", x, z, err)
+	}
+	defer func() {
+		err = errors.Join(err, lock.Unlock())
+	}()
+
+	err = w.copyChunk(d, x, z, w, d, toX, toZ, opts)
+	for _, kind := range regionKinds {
+		if err == nil {
+			err = w.deleteChunk(d, kind, x, z)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to move chunk %v,%v: %w", x, z, err)
+	}
+	return nil
+}
+
+// copyChunk copies each part of the chunk to the destination, relocated, without taking any lock.
+func (w *World) copyChunk(d Dimension, x, z int, to *World, toD Dimension, toX, toZ int, opts []nbt.Option) error {
+	for _, kind := range regionKinds {
+		chunk, ok, err := w.readChunk(d, kind, x, z, opts)
+		if err != nil {
+			return err
+		}
+		if !ok && kind == Chunks {
+			return fmt.Errorf("%v has no chunk %v,%v: %w", d, x, z, fs.ErrNotExist)
+		}
+		if !ok {
+			if err = to.deleteChunk(toD, kind, toX, toZ); err != nil {
+				return err
+			}
+			continue
+		}
+
+		dx, dz := int32(toX-x), int32(toZ-z) // #nosec G115 -- chunk coordinates fit an int32
+		if chunk.Tag, err = nbt.RelocateChunk(chunk.Tag, dx, dz); err != nil {
+			return err
+		}
+		chunk.X, chunk.Z = toX, toZ
+		r, f, err := to.openWritable(toD, kind, toX, toZ, true)
+		if err != nil {
+			return err
+		}
+		err = errors.Join(r.WriteChunk(chunk, opts...), f.Close())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readChunk reads the chunk of the kind in the dimension at chunk coordinates x and z, with its header and
+// compression. The boolean is false if the chunk, or its whole region, is not present.
+func (w *World) readChunk(d Dimension, kind RegionKind, x, z int, opts []nbt.Option) (chunk region.Chunk, ok bool,
+	err error) {
+	r, err := w.Region(d, kind, x, z)
+	if errors.Is(err, fs.ErrNotExist) {
+		return region.Chunk{}, false, nil
+	}
+	if err != nil {
+		return region.Chunk{}, false, err
+	}
+	defer r.Close()
+
+	return r.ReadChunk(x, z, opts...)
+}
+
+// deleteChunk deletes the chunk of the kind in the dimension at chunk coordinates x and z, if its region has a file.
+func (w *World) deleteChunk(d Dimension, kind RegionKind, x, z int) error {
+	r, f, err := w.openWritable(d, kind, x, z, false)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return errors.Join(r.DeleteChunk(x, z), f.Close())
+}
+
+// openWritable opens the region file of the kind in the dimension holding the chunk at chunk coordinates x and z for
+// reading and writing. If create is set, a missing file is created, with its directory. The error wraps
+// fs.ErrNotExist if the region has no file and create is not set. The file must be closed once written.
+func (w *World) openWritable(d Dimension, kind RegionKind, chunkX, chunkZ int, create bool) (r *region.Region,
+	f *os.File, err error) {
+	if w.dir == "" {
+		return nil, nil, fmt.Errorf("world was not opened from a directory, see Open")
+	}
+	name := RegionPath(d, kind, chunkX, chunkZ)
+	filePath := w.filePath(name)
+	flag := os.O_RDWR
+	if create {
+		flag |= os.O_CREATE
+		if err = os.MkdirAll(filepath.Dir(filePath), 0o750); err != nil {
+			return nil, nil, fmt.Errorf("Unable to open region %v: %w", name, err)
+		}
+	}
+	f, err = os.OpenFile(filePath, flag, 0o600) // #nosec G304 -- the path is built from the world's directory
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to open region %v: %w", name, err)
+	}
+
+	info, err := f.Stat()
+	if err == nil {
+		r, err = region.Open(f, info.Size())
+	}
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, fmt.Errorf("Unable to open region %v: %w", name, err)
+	}
+	r.SetExternalDir(filepath.Join(w.dir, filepath.FromSlash(path.Dir(name))), coords.ChunkToRegion(chunkX),
+		coords.ChunkToRegion(chunkZ))
+	return r, f, nil
+}
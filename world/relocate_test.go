@@ -0,0 +1,79 @@
+package world
+
+import (
+	"errors"
+	"io/fs"
+	"reflect"
+	"testing"
+
+	"PudFish/nbt"
+)
+
+// chunkXZ returns the xPos and zPos of the chunk of the kind at chunk coordinates x and z, or false if it is absent.
+func chunkXZ(t *testing.T, w *World, d Dimension, kind RegionKind, x, z int) (xz []any, ok bool) {
+	t.Helper()
+	chunk, ok, err := w.Chunk(d, kind, x, z)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, name := range []string{"xPos", "zPos"} {
+		child, _ := nbt.NewView(chunk).Child(name)
+		xz = append(xz, child.Payload())
+	}
+	return xz, ok
+}
+
+func TestCopyChunk(t *testing.T) {
+	newWorld := func(t *testing.T) *World {
+		dir := t.TempDir()
+		writeTestRegion(t, dir, "region/r.0.0.mca", testChunk(0, 0, 7), testChunk(1, 0, 0))
+		writeTestRegion(t, dir, "entities/r.0.0.mca", testChunk(0, 0, 0), testChunk(2, 0, 0))
+		return Open(dir)
+	}
+
+	t.Run("Test success case: copy to another world and dimension", func(t *testing.T) {
+		from, to := newWorld(t), Open(t.TempDir())
+		if err := from.CopyChunk(Overworld, 0, 0, to, Nether, 40, -3); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, kind := range []RegionKind{Chunks, Entities} {
+			if got, ok := chunkXZ(t, to, Nether, kind, 40, -3); !ok || !reflect.DeepEqual(got, []any{int32(40),
+				int32(-3)}) {
+				t.Errorf("%v: got %v %v, want the chunk at 40,-3", kind, got, ok)
+			}
+		}
+		if _, ok := chunkXZ(t, from, Overworld, Chunks, 0, 0); !ok {
+			t.Errorf("got the source chunk removed, want it kept")
+		}
+	})
+
+	t.Run("Test success case: move within a world", func(t *testing.T) {
+		w := newWorld(t)
+		// Chunk 1,0 has no entities, so the entities stored at 2,0 are stale once it moves there.
+		if err := w.MoveChunk(Overworld, 1, 0, 2, 0); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got, ok := chunkXZ(t, w, Overworld, Chunks, 2, 0); !ok || got[0] != int32(2) {
+			t.Errorf("got %v %v, want the chunk moved to 2,0", got, ok)
+		}
+		if _, ok := chunkXZ(t, w, Overworld, Chunks, 1, 0); ok {
+			t.Errorf("got the chunk left at 1,0, want it removed")
+		}
+		if _, ok := chunkXZ(t, w, Overworld, Entities, 2, 0); ok {
+			t.Errorf("got stale entities at 2,0, want them removed")
+		}
+	})
+
+	t.Run("Test failure case: missing chunk", func(t *testing.T) {
+		w := newWorld(t)
+		if err := w.CopyChunk(Overworld, 5, 5, w, Overworld, 6, 6); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("got %v, want fs.ErrNotExist", err)
+		}
+	})
+
+	t.Run("Test failure case: destination not opened from a directory", func(t *testing.T) {
+		if err := newWorld(t).CopyChunk(Overworld, 0, 0, New(testFS(t)), Overworld, 1, 1); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+}
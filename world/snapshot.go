@@ -0,0 +1,105 @@
+package world
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"PudFish/nbt"
+	"PudFish/nbt/coords"
+	"PudFish/nbt/region"
+)
+
+// sessionLockName is the world's lock file, held by the game while the world is open and never archived.
+const sessionLockName = "session.lock"
+
+// Snapshot writes a tar archive of every file of the world folder but session.lock, such as level.dat, player data and
+// region files, with paths relative to the folder so the archive restores by extracting it into an empty directory.
+// Files are archived in lexical path order, each read whole before it is written.
+//
+// Each file is verified as it is archived: .dat files must decode, and every chunk of a region file must read and
+// decode, as Region.Chunks reads them. A file that fails verification is still archived, and the returned error joins
+// the verification errors of every such file, so a damaged world still produces the most complete archive possible.
+// An error reading or archiving a file stops the snapshot. Chunks stored in external .mcc files are verified only for a
+// world opened with Open. The options configure decoding.
+//
+// The world must not be saved while it is archived, as by a server with saving turned off, or the archive may hold
+// files from before and after a save.
+func (w *World) Snapshot(out io.Writer, opts ...nbt.Option) (err error) {
+	archive := tar.NewWriter(out)
+	var verifyErrs []error
+	err = fs.WalkDir(w.fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || name == sessionLockName {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		data, err := fs.ReadFile(w.fsys, name)
+		if err != nil {
+			return err
+		}
+		if err = w.verify(name, data, opts); err != nil {
+			verifyErrs = append(verifyErrs, fmt.Errorf("Unable to verify %v: %w", name, err))
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name, header.Size = name, int64(len(data))
+		if err = archive.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err = archive.Write(data)
+		return err
+	})
+	if err == nil {
+		err = archive.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to snapshot world: %w", err)
+	}
+	return errors.Join(verifyErrs...)
+}
+
+// verify decodes the .dat file or every chunk of the region file with the name and content, returning the errors
+// found. Other files are not verified.
+func (w *World) verify(name string, data []byte, opts []nbt.Option) error {
+	base := path.Base(name)
+	if strings.HasSuffix(base, datExt) {
+		_, _, err := nbt.ReadCompressed(bytes.NewReader(data), opts...)
+		return err
+	}
+
+	regionX, regionZ, ok := coords.ParseRegionFileName(base)
+	if !ok {
+		return nil
+	}
+	r, err := region.Open(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	if w.dir != "" {
+		r.SetExternalDir(filepath.Join(w.dir, filepath.FromSlash(path.Dir(name))), regionX, regionZ)
+	}
+
+	var errs []error
+	for _, err := range r.Chunks(opts...) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,106 @@
+package world
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// untar returns the content of every file of a tar archive by name, in archive order.
+func untar(t *testing.T, archive []byte) (names []string, files map[string][]byte) {
+	t.Helper()
+	files = map[string][]byte{}
+	r := tar.NewReader(bytes.NewReader(archive))
+	for {
+		header, err := r.Next()
+		if err == io.EOF {
+			return names, files
+		}
+		if err != nil {
+			t.Fatalf("Unable to read archive: %v", err)
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("Unable to read archive: %v", err)
+		}
+		names = append(names, header.Name)
+		files[header.Name] = data
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	t.Run("Test success case: world archived", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"level.dat":               {Data: encodeTestFile(t, "level")},
+			"session.lock":            {Data: []byte("☃")},
+			"playerdata/a.dat":        {Data: encodeTestFile(t, "player")},
+			"region/r.0.0.mca":        {Data: encodeTestRegion(t, [2]int{0, 0}, [2]int{31, 31})},
+			"icon.png":                {Data: []byte("png")},
+			"DIM1/data/raids_end.dat": {Data: encodeTestFile(t, "raids")},
+		}
+
+		var b bytes.Buffer
+		if err := New(fsys).Snapshot(&b); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		names, files := untar(t, b.Bytes())
+		want := []string{"DIM1/data/raids_end.dat", "icon.png", "level.dat", "playerdata/a.dat", "region/r.0.0.mca"}
+		if !reflect.DeepEqual(names, want) {
+			t.Errorf("got %v, want %v", names, want)
+		}
+		for _, name := range want {
+			if !bytes.Equal(files[name], fsys[name].Data) {
+				t.Errorf("%v: archived content differs from the file", name)
+			}
+		}
+	})
+
+	t.Run("Test failure case: damaged files archived and reported", func(t *testing.T) {
+		damaged := encodeTestRegion(t, [2]int{0, 0}, [2]int{1, 0})
+		// Corrupt the compressed data of the second chunk, in the fourth sector.
+		copy(damaged[3*4096+5:], "garbage")
+		fsys := testFS(t)
+		fsys["region/r.0.0.mca"] = &fstest.MapFile{Data: damaged}
+
+		var b bytes.Buffer
+		err := New(fsys).Snapshot(&b)
+		if err == nil {
+			t.Fatalf("Expected error, got nil")
+		}
+		for _, name := range []string{"level.dat:", "region/r.0.0.mca:", "chunk 1,0"} {
+			if !strings.Contains(err.Error(), name) {
+				t.Errorf("got %v, want an error for %v", err, name)
+			}
+		}
+		if strings.Contains(err.Error(), "r.-1.0.mca") || strings.Contains(err.Error(), "chunk 0,0") {
+			t.Errorf("got %v, want errors only for the damaged files", err)
+		}
+
+		names, _ := untar(t, b.Bytes())
+		if len(names) != len(fsys) {
+			t.Errorf("got %v files archived, want %v", len(names), len(fsys))
+		}
+	})
+
+	t.Run("Test failure case: write error", func(t *testing.T) {
+		err := New(testFS(t)).Snapshot(failingWriter{})
+		if !errors.Is(err, errWrite) {
+			t.Errorf("got %v, want %v", err, errWrite)
+		}
+	})
+}
+
+// errWrite is returned by every write to a failingWriter.
+var errWrite = errors.New("write failed")
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errWrite
+}
@@ -0,0 +1,178 @@
+package world
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"PudFish/nbt"
+	"PudFish/nbt/coords"
+	"PudFish/nbt/region"
+)
+
+// TrimFunc reports whether Trim removes the chunk at chunk coordinates x and z.
+type TrimFunc func(chunkX, chunkZ int, chunk nbt.Tag) bool
+
+// InhabitedTimeBelow returns a TrimFunc removing the chunks players have spent fewer than ticks game ticks in, read
+// from InhabitedTime at the root of the chunk, or in its Level compound before Java edition 1.18. Chunks without an
+// InhabitedTime are kept.
+func InhabitedTimeBelow(ticks int64) TrimFunc {
+	return func(_, _ int, chunk nbt.Tag) bool {
+		v := nbt.NewView(chunk)
+		if level, ok := v.Child("Level"); ok {
+			v = level
+		}
+		inhabited, ok := v.Child("InhabitedTime")
+		ticksIn, isLong := inhabited.Payload().(int64)
+		return ok && isLong && ticksIn < ticks
+	}
+}
+
+// OutsideRadius returns a TrimFunc removing the chunks further than radius chunks from the chunk at chunk coordinates
+// x and z, measured between chunk positions.
+func OutsideRadius(centerX, centerZ, radius int) TrimFunc {
+	return func(chunkX, chunkZ int, _ nbt.Tag) bool {
+		dx, dz := chunkX-centerX, chunkZ-centerZ
+		return dx*dx+dz*dz > radius*radius
+	}
+}
+
+// Trim removes every chunk of the dimension that remove returns true for, with the entities and points of interest
+// stored for the same chunks, and returns the positions of the chunks removed in region then header order. The game
+// generates a removed chunk afresh when it is next loaded.
+//
+// Each region file holding a removed chunk is rewritten with only the chunks kept, packed into consecutive sectors so
+// the file shrinks, through a temporary file renamed over it so a failed trim leaves every region file whole. A region
+// file left with no chunks is deleted. Trim holds the world's session lock throughout, so the world must have been
+// opened with Open and not be open in the game. A chunk that fails to read stops the trim, leaving the regions already
+// trimmed trimmed. The options configure decoding and encoding.
+func (w *World) Trim(d Dimension, remove TrimFunc, opts ...nbt.Option) (removed []ChunkPos, err error) {
+	lock, err := w.lock()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to trim %v: %w", d, err)
+	}
+	defer func() {
+		err = errors.Join(err, lock.Unlock())
+	}()
+
+	regions, err := w.Regions(d, Chunks)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to trim %v: %w", d, err)
+	}
+	for _, pos := range regions {
+		chunkX, chunkZ := coords.RegionToChunk(pos.X), coords.RegionToChunk(pos.Z)
+		trimmed, err := w.compact(RegionPath(d, Chunks, chunkX, chunkZ), pos, remove, opts)
+		if err != nil {
+			return removed, fmt.Errorf("Unable to trim %v: %w", d, err)
+		}
+		if len(trimmed) == 0 {
+			continue
+		}
+
+		removeStored := func(chunkX, chunkZ int, _ nbt.Tag) bool {
+			return slices.Contains(trimmed, ChunkPos{X: chunkX, Z: chunkZ})
+		}
+		for _, kind := range []RegionKind{Entities, POI} {
+			_, err = w.compact(RegionPath(d, kind, chunkX, chunkZ), pos, removeStored, opts)
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return removed, fmt.Errorf("Unable to trim %v: %w", d, err)
+			}
+		}
+		removed = append(removed, trimmed...)
+	}
+	return removed, nil
+}
+
+// compact rewrites the region file at the path within the world folder, of the region at pos, holding only the chunks
+// remove returns false for, and returns the positions of the chunks removed. The chunks kept are written in header
+// order from the first sector after the header, keeping their compression and timestamp, to a temporary file renamed
+// over the region file. The file is left as is if no chunk is removed, and deleted if every chunk is. The external .mcc
+// files of removed chunks are deleted. The error wraps fs.ErrNotExist if the region has no file.
+func (w *World) compact(name string, pos RegionPos, remove TrimFunc, opts []nbt.Option) (removed []ChunkPos,
+	err error) {
+	filePath := w.filePath(name)
+	dir := filepath.Dir(filePath)
+	f, err := os.Open(filePath) // #nosec G304 -- the path is built from the world's directory
+	if err != nil {
+		return nil, fmt.Errorf("Unable to compact region %v: %w", name, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to compact region %v: %w", name, err)
+	}
+	old, err := region.Open(f, info.Size())
+	if err != nil {
+		return nil, fmt.Errorf("Unable to compact region %v: %w", name, err)
+	}
+	old.SetExternalDir(dir, pos.X, pos.Z)
+
+	temp, err := os.CreateTemp(dir, "."+filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("Unable to compact region %v: %w", name, err)
+	}
+	renamed := false
+	defer func() {
+		if !renamed {
+			_ = temp.Close()
+			_ = os.Remove(temp.Name())
+		}
+	}()
+	compacted, err := region.Create(temp)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to compact region %v: %w", name, err)
+	}
+	compacted.SetExternalDir(dir, pos.X, pos.Z)
+
+	kept := 0
+	for chunk, err := range old.Chunks(opts...) {
+		if err != nil {
+			return nil, fmt.Errorf("Unable to compact region %v: %w", name, err)
+		}
+		chunkX, chunkZ := chunk.ChunkPos(pos.X, pos.Z)
+		if remove(chunkX, chunkZ, chunk.Tag) {
+			removed = append(removed, ChunkPos{X: chunkX, Z: chunkZ})
+			continue
+		}
+		if err = compacted.WriteChunk(chunk, opts...); err != nil {
+			return nil, fmt.Errorf("Unable to compact region %v: %w", name, err)
+		}
+		kept++
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+
+	_ = f.Close()
+	if kept == 0 {
+		err = os.Remove(filePath)
+	} else {
+		err = temp.Chmod(info.Mode().Perm())
+		if err == nil {
+			err = temp.Sync()
+		}
+		if err == nil {
+			err = temp.Close()
+		}
+		if err == nil {
+			err = os.Rename(temp.Name(), filePath)
+			renamed = err == nil
+		}
+	}
+	for _, chunk := range removed {
+		if err == nil {
+			err = os.Remove(filepath.Join(dir, coords.ExternalChunkFileName(chunk.X, chunk.Z)))
+			if errors.Is(err, fs.ErrNotExist) {
+				err = nil
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to compact region %v: %w", name, err)
+	}
+	return removed, nil
+}
@@ -0,0 +1,111 @@
+package world
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"PudFish/nbt"
+	"PudFish/nbt/region"
+)
+
+// testChunk returns a chunk at chunk coordinates x and z that players have spent the ticks in.
+func testChunk(x, z int, inhabited int64) nbt.Tag {
+	tag, _ := nbt.NewCompound("", nbt.NewInt("xPos", int32(x)), nbt.NewInt("zPos", int32(z)),
+		nbt.NewLong("InhabitedTime", inhabited))
+	return tag
+}
+
+// writeTestRegion writes a region file at the path within the world directory, holding each chunk at the chunk
+// coordinates of its xPos and zPos.
+func writeTestRegion(t *testing.T, dir, name string, chunks ...nbt.Tag) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatalf("Unable to create test region: %v", err)
+	}
+	f, err := os.Create(path) // #nosec G304 -- test file
+	if err != nil {
+		t.Fatalf("Unable to create test region: %v", err)
+	}
+	defer f.Close()
+
+	r, err := region.Create(f)
+	if err != nil {
+		t.Fatalf("Unable to create test region: %v", err)
+	}
+	for _, tag := range chunks {
+		x, _ := nbt.NewView(tag).Child("xPos")
+		z, _ := nbt.NewView(tag).Child("zPos")
+		chunk := region.Chunk{ChunkHeader: region.ChunkHeader{X: int(x.Payload().(int32)), Z: int(z.Payload().(int32))},
+			Tag: tag, Compression: nbt.CompressionZlib}
+		if err = r.WriteChunk(chunk); err != nil {
+			t.Fatalf("Unable to write test chunk: %v", err)
+		}
+	}
+}
+
+// chunkPositions returns the coordinates within the region of the chunks present in the region file of the kind
+// holding the chunk.
+func chunkPositions(t *testing.T, w *World, kind RegionKind, chunkX, chunkZ int) (positions []ChunkPos) {
+	t.Helper()
+	r, err := w.Region(Overworld, kind, chunkX, chunkZ)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer r.Close()
+	for h := range r.Headers() {
+		positions = append(positions, ChunkPos{X: h.X, Z: h.Z})
+	}
+	return positions
+}
+
+func TestTrim(t *testing.T) {
+	t.Run("Test success case: inhabited time", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTestRegion(t, dir, "region/r.0.0.mca", testChunk(0, 0, 0), testChunk(1, 0, 100), testChunk(2, 0, 10))
+		writeTestRegion(t, dir, "region/r.1.0.mca", testChunk(40, 0, 0))
+		writeTestRegion(t, dir, "region/r.-1.0.mca", testChunk(-1, 0, 500))
+		writeTestRegion(t, dir, "entities/r.0.0.mca", testChunk(0, 0, 0), testChunk(1, 0, 0))
+		w := Open(dir)
+
+		removed, err := w.Trim(Overworld, InhabitedTimeBelow(50))
+		want := []ChunkPos{{0, 0}, {2, 0}, {40, 0}}
+		if err != nil || !reflect.DeepEqual(removed, want) {
+			t.Fatalf("got %v %v, want %v", removed, err, want)
+		}
+
+		if got := chunkPositions(t, w, Chunks, 0, 0); !reflect.DeepEqual(got, []ChunkPos{{1, 0}}) {
+			t.Errorf("got chunks %v, want 1,0", got)
+		}
+		if got := chunkPositions(t, w, Entities, 0, 0); !reflect.DeepEqual(got, []ChunkPos{{1, 0}}) {
+			t.Errorf("got entity chunks %v, want 1,0", got)
+		}
+		info, err := os.Stat(filepath.Join(dir, "region", "r.0.0.mca"))
+		if err != nil || info.Size() != 3*region.SectorSize {
+			t.Errorf("got %v %v, want a region compacted to 3 sectors", info.Size(), err)
+		}
+		if _, err = os.Stat(filepath.Join(dir, "region", "r.1.0.mca")); !os.IsNotExist(err) {
+			t.Errorf("got %v, want the emptied region deleted", err)
+		}
+		if got := chunkPositions(t, w, Chunks, -1, 0); !reflect.DeepEqual(got, []ChunkPos{{31, 0}}) {
+			t.Errorf("got chunks %v, want the untrimmed region kept", got)
+		}
+	})
+
+	t.Run("Test success case: outside radius", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTestRegion(t, dir, "region/r.0.0.mca", testChunk(0, 0, 0), testChunk(3, 4, 0), testChunk(4, 4, 0))
+		removed, err := Open(dir).Trim(Overworld, OutsideRadius(0, 0, 5))
+		if err != nil || !reflect.DeepEqual(removed, []ChunkPos{{4, 4}}) {
+			t.Errorf("got %v %v, want 4,4", removed, err)
+		}
+	})
+
+	t.Run("Test failure case: world not opened from a directory", func(t *testing.T) {
+		if _, err := New(testFS(t)).Trim(Overworld, OutsideRadius(0, 0, 5)); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,345 @@
+package world
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+
+	"PudFish/nbt"
+	"PudFish/nbt/coords"
+	"PudFish/nbt/region"
+)
+
+// Tx is a transaction staging changes to the chunks and level.dat of a world in memory, to be applied together by
+// Commit or discarded by Rollback. It holds the world's session lock from Begin until it ends, so nothing else writes
+// the world meanwhile. A Tx is not safe for concurrent use.
+type Tx struct {
+	w    *World
+	lock *nbt.SessionLock
+	// chunks are the staged chunks, nil for a chunk to delete.
+	chunks map[txChunk]*nbt.Tag
+	// level is the staged level.dat, nil if unchanged.
+	level *nbt.Tag
+	done  bool
+}
+
+// txChunk locates a chunk staged by a Tx.
+type txChunk struct {
+	d    Dimension
+	kind RegionKind
+	x, z int
+}
+
+// Begin takes the world's session lock and returns a transaction staging changes to it. The world must have been
+// opened with Open and not be open in the game. The transaction must be ended with Commit or Rollback.
+func (w *World) Begin() (*Tx, error) {
+	lock, err := w.lock()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to begin transaction: %w", err)
+	}
+	return &Tx{w: w, lock: lock, chunks: map[txChunk]*nbt.Tag{}}, nil
+}
+
+// SetChunk stages the chunk of the kind in the dimension at chunk coordinates x and z to be written as t.
+func (tx *Tx) SetChunk(d Dimension, kind RegionKind, chunkX, chunkZ int, t nbt.Tag) {
+	tx.chunks[txChunk{d: d, kind: kind, x: chunkX, z: chunkZ}] = &t
+}
+
+// DeleteChunk stages the chunk of the kind in the dimension at chunk coordinates x and z to be deleted.
+func (tx *Tx) DeleteChunk(d Dimension, kind RegionKind, chunkX, chunkZ int) {
+	tx.chunks[txChunk{d: d, kind: kind, x: chunkX, z: chunkZ}] = nil
+}
+
+// SetLevel stages level.dat to be written as t.
+func (tx *Tx) SetLevel(t nbt.Tag) {
+	tx.level = &t
+}
+
+// Chunk returns the chunk of the kind in the dimension at chunk coordinates x and z as the transaction would commit
+// it: the chunk staged, or else the chunk in the world, read as World.Chunk does. The boolean is false if the chunk is
+// staged to be deleted or not present.
+func (tx *Tx) Chunk(d Dimension, kind RegionKind, chunkX, chunkZ int, opts ...nbt.Option) (t nbt.Tag, ok bool,
+	err error) {
+	if staged, ok := tx.chunks[txChunk{d: d, kind: kind, x: chunkX, z: chunkZ}]; ok {
+		if staged == nil {
+			return nbt.Tag{}, false, nil
+		}
+		return *staged, true, nil
+	}
+	return tx.w.Chunk(d, kind, chunkX, chunkZ, opts...)
+}
+
+// Rollback discards the staged changes and releases the world's session lock, ending the transaction.
+func (tx *Tx) Rollback() error {
+	if tx.done {
+		return fmt.Errorf("Unable to roll back transaction: transaction has ended")
+	}
+	tx.done = true
+	return tx.lock.Unlock()
+}
+
+// Commit applies the staged changes and releases the world's session lock, ending the transaction whether or not it
+// succeeds. Every file changed is written in full to a temporary file beside it and synced before any is renamed over
+// the file it replaces, and the directories holding them are synced once renamed, so a commit failing before the
+// renames changes nothing and an interrupted one leaves each file whole, either as it was or as committed. The previous
+// level.dat is kept as level.dat_old, as the game does.
+//
+// Region files are copied with the staged chunks written and deleted, each new chunk keeping the compression of the
+// chunk it replaces, or zlib compressed as the game writes them. The external .mcc files of chunks too large for a
+// region file are staged in a temporary directory beside it and renamed before the region files, and those of chunks
+// deleted or now held in the region file are removed once every file is renamed. level.dat keeps its compression, or is
+// gzip compressed if new. The options configure encoding.
+func (tx *Tx) Commit(opts ...nbt.Option) (err error) {
+	if tx.done {
+		return fmt.Errorf("Unable to commit transaction: transaction has ended")
+	}
+	tx.done = true
+	defer func() {
+		err = errors.Join(err, tx.lock.Unlock())
+	}()
+
+	// renames maps each temporary file written to the file it replaces, and external each staged .mcc file to the file
+	// it replaces. remove are the .mcc files to remove once every file is renamed.
+	renames, external := map[string]string{}, map[string]string{}
+	var staging, remove []string
+	defer func() {
+		for temp := range renames {
+			_ = os.Remove(temp)
+		}
+		for _, dir := range staging {
+			_ = os.RemoveAll(dir)
+		}
+	}()
+
+	regions := map[string][]txChunk{}
+	for c := range tx.chunks {
+		name := RegionPath(c.d, c.kind, c.x, c.z)
+		regions[name] = append(regions[name], c)
+	}
+	for _, name := range slices.Sorted(maps.Keys(regions)) {
+		staged, err := tx.stageRegion(name, regions[name], opts)
+		if err != nil {
+			return fmt.Errorf("Unable to commit transaction: %w", err)
+		}
+		staging = append(staging, staged.staging)
+		renames[staged.temp] = tx.w.filePath(name)
+		maps.Copy(external, staged.external)
+		remove = append(remove, staged.remove...)
+	}
+	if tx.level != nil {
+		temp, err := tx.stageLevel(opts)
+		if err != nil {
+			return fmt.Errorf("Unable to commit transaction: %w", err)
+		}
+		renames[temp] = tx.w.filePath(levelName)
+	}
+
+	// External chunks are renamed first, so each region file renamed next finds those it refers to in place.
+	dirs := map[string]bool{}
+	for _, temp := range slices.Sorted(maps.Keys(external)) {
+		if err = os.Rename(temp, external[temp]); err != nil {
+			return fmt.Errorf("Unable to commit transaction: %w", err)
+		}
+		dirs[filepath.Dir(external[temp])] = true
+	}
+	for _, temp := range slices.Sorted(maps.Keys(renames)) {
+		target := renames[temp]
+		if target == tx.w.filePath(levelName) {
+			err = os.Rename(target, tx.w.filePath(levelOldName))
+			if errors.Is(err, os.ErrNotExist) {
+				err = nil
+			}
+		}
+		if err == nil {
+			err = os.Rename(temp, target)
+		}
+		if err != nil {
+			return fmt.Errorf("Unable to commit transaction: %w", err)
+		}
+		delete(renames, temp)
+		dirs[filepath.Dir(target)] = true
+	}
+	for _, path := range remove {
+		if err = os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("Unable to commit transaction: %w", err)
+		}
+		dirs[filepath.Dir(path)] = true
+	}
+
+	for _, dir := range slices.Sorted(maps.Keys(dirs)) {
+		if err = syncDir(dir); err != nil {
+			return fmt.Errorf("Unable to commit transaction: %w", err)
+		}
+	}
+	return nil
+}
+
+// stagedRegion is a region file staged by stageRegion.
+type stagedRegion struct {
+	// temp is the temporary copy of the region file with the staged chunks applied.
+	temp string
+	// staging is the temporary directory of the external .mcc files written for the copy, and external maps each of
+	// them to the file it replaces.
+	staging  string
+	external map[string]string
+	// remove are the external .mcc files of the chunks the copy deletes or holds in the region file.
+	remove []string
+}
+
+// stageRegion writes a copy of the region file at the path within the world folder, or a new region file if it has
+// none, with the staged chunks applied, to a synced temporary file beside it. The external .mcc files of the copy are
+// written to a temporary directory beside it, leaving those of the world as they are.
+func (tx *Tx) stageRegion(name string, staged []txChunk, opts []nbt.Option) (s stagedRegion, err error) {
+	filePath := tx.w.filePath(name)
+	dir := filepath.Dir(filePath)
+	if err = os.MkdirAll(dir, 0o750); err != nil {
+		return stagedRegion{}, fmt.Errorf("Unable to stage region %v: %w", name, err)
+	}
+	staging, err := os.MkdirTemp(dir, "."+filepath.Base(filePath)+".*.mcc.tmp")
+	if err != nil {
+		return stagedRegion{}, fmt.Errorf("Unable to stage region %v: %w", name, err)
+	}
+	f, err := os.CreateTemp(dir, "."+filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		_ = os.RemoveAll(staging)
+		return stagedRegion{}, fmt.Errorf("Unable to stage region %v: %w", name, err)
+	}
+	defer func() {
+		err = errors.Join(err, f.Close())
+		if err != nil {
+			_ = os.Remove(f.Name())
+			_ = os.RemoveAll(staging)
+			err = fmt.Errorf("Unable to stage region %v: %w", name, err)
+		}
+	}()
+
+	size, err := copyFile(f, filePath)
+	var r *region.Region
+	if errors.Is(err, os.ErrNotExist) {
+		r, err = region.Create(f)
+	} else if err == nil {
+		r, err = region.Open(f, size)
+	}
+	if err != nil {
+		return stagedRegion{}, err
+	}
+
+	// The chunks replaced are read with the world's external chunks, and the copy writes to the staging directory.
+	regionX, regionZ := coords.ChunkToRegion(staged[0].x), coords.ChunkToRegion(staged[0].z)
+	r.SetExternalDir(dir, regionX, regionZ)
+	compressions := map[txChunk]nbt.Compression{}
+	for _, c := range staged {
+		compressions[c] = chunkCompression(r, c)
+	}
+	r.SetExternalDir(staging, regionX, regionZ)
+
+	s = stagedRegion{temp: f.Name(), staging: staging, external: map[string]string{}}
+	for _, c := range staged {
+		if t := tx.chunks[c]; t == nil {
+			err = r.DeleteChunk(c.x, c.z)
+		} else {
+			chunk := region.Chunk{ChunkHeader: region.ChunkHeader{X: c.x, Z: c.z}, Tag: *t, Compression: compressions[c]}
+			err = r.WriteChunk(chunk, opts...)
+		}
+		if err != nil {
+			return stagedRegion{}, err
+		}
+
+		mcc := coords.ExternalChunkFileName(c.x, c.z)
+		if _, err := os.Stat(filepath.Join(staging, mcc)); err == nil {
+			s.external[filepath.Join(staging, mcc)] = filepath.Join(dir, mcc)
+		} else {
+			s.remove = append(s.remove, filepath.Join(dir, mcc))
+		}
+	}
+	return s, f.Sync()
+}
+
+// chunkCompression returns the compression of the chunk the staged chunk replaces, or zlib if there is none.
+func chunkCompression(r *region.Region, c txChunk) nbt.Compression {
+	if _, ok := r.Header(c.x, c.z); ok {
+		if old, _, err := r.ReadChunk(c.x, c.z); err == nil {
+			return old.Compression
+		}
+	}
+	return nbt.CompressionZlib
+}
+
+// stageLevel writes the staged level.dat to a synced temporary file beside it, keeping the compression of the current
+// level.dat, and returns the temporary file's path.
+func (tx *Tx) stageLevel(opts []nbt.Option) (temp string, err error) {
+	filePath := tx.w.filePath(levelName)
+	compression, mode := nbt.CompressionGzip, os.FileMode(0o644)
+	if current, err := os.Open(filePath); err == nil { // #nosec G304 -- the path is built from the world's directory
+		if info, err := current.Stat(); err == nil {
+			mode = info.Mode().Perm()
+		}
+		if _, detected, err := nbt.ReadCompressed(current); err == nil {
+			compression = detected
+		}
+		_ = current.Close()
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(filePath), "."+levelName+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("Unable to stage %v: %w", levelName, err)
+	}
+	defer func() {
+		err = errors.Join(err, f.Close())
+		if err != nil {
+			_ = os.Remove(f.Name())
+			err = fmt.Errorf("Unable to stage %v: %w", levelName, err)
+		}
+	}()
+
+	if err = f.Chmod(mode); err != nil {
+		return "", err
+	}
+	buffered := bufio.NewWriter(f)
+	opts = append([]nbt.Option{nbt.WithCompression(compression)}, opts...)
+	if err = nbt.WriteTag(buffered, *tx.level, opts...); err != nil {
+		return "", err
+	}
+	if err = buffered.Flush(); err != nil {
+		return "", err
+	}
+	return f.Name(), f.Sync()
+}
+
+// copyFile copies the file at path, and its permissions, to the start of f, returning the number of bytes copied. The
+// error wraps os.ErrNotExist if there is no file at path.
+func copyFile(f *os.File, path string) (n int64, err error) {
+	src, err := os.Open(path) // #nosec G304 -- the path is built from the world's directory
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err == nil {
+		err = f.Chmod(info.Mode().Perm())
+	}
+	if err != nil {
+		return 0, err
+	}
+	return io.Copy(f, src)
+}
+
+// syncDir syncs the directory, so the renames and removals within it are durable. Windows cannot sync a directory, so
+// it is skipped there.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir) // #nosec G304 -- the path is built from the world's directory
+	if err != nil {
+		return err
+	}
+	return errors.Join(d.Sync(), d.Close())
+}
@@ -0,0 +1,201 @@
+package world
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"PudFish/nbt"
+	"PudFish/nbt/region"
+)
+
+func TestTx(t *testing.T) {
+	// newWorld returns a world holding chunks 0,0 and 1,0 and a level.dat.
+	newWorld := func(t *testing.T) (w *World, dir string) {
+		dir = t.TempDir()
+		writeTestRegion(t, dir, "region/r.0.0.mca", testChunk(0, 0, 0), testChunk(1, 0, 0))
+		if err := os.WriteFile(filepath.Join(dir, levelName), encodeTestFile(t, "level"), 0o600); err != nil {
+			t.Fatalf("Unable to create test world: %v", err)
+		}
+		return Open(dir), dir
+	}
+
+	t.Run("Test success case: commit", func(t *testing.T) {
+		w, dir := newWorld(t)
+		tx, err := w.Begin()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		tx.SetChunk(Overworld, Chunks, 0, 0, testChunk(0, 0, 99))
+		tx.DeleteChunk(Overworld, Chunks, 1, 0)
+		tx.SetChunk(Nether, Chunks, -40, 3, testChunk(-40, 3, 0))
+		level, _ := nbt.NewCompound("", nbt.NewString("name", "new level"))
+		tx.SetLevel(level)
+
+		if got, ok, err := tx.Chunk(Overworld, Chunks, 1, 0); ok || err != nil {
+			t.Errorf("got %v %v %v, want the staged deletion", got, ok, err)
+		}
+		if _, ok, _ := w.Chunk(Overworld, Chunks, 1, 0); !ok {
+			t.Errorf("got the chunk deleted before commit, want it kept")
+		}
+		if err = tx.Commit(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if got, ok, err := w.Chunk(Overworld, Chunks, 0, 0); err != nil || !ok || !InhabitedTimeBelow(100)(0, 0, got) ||
+			InhabitedTimeBelow(99)(0, 0, got) {
+			t.Errorf("got %v %v %v, want the chunk staged", got, ok, err)
+		}
+		if _, ok, _ := w.Chunk(Overworld, Chunks, 1, 0); ok {
+			t.Errorf("got chunk 1,0, want it deleted")
+		}
+		if _, ok, err := w.Chunk(Nether, Chunks, -40, 3); !ok || err != nil {
+			t.Errorf("got %v %v, want the new nether region", ok, err)
+		}
+		got, usedFallback, err := w.Level(false)
+		if err != nil || usedFallback || name(got) != "new level" {
+			t.Errorf("got %v %v %v, want the new level", name(got), usedFallback, err)
+		}
+		old, err := nbt.ReadFile(filepath.Join(dir, levelOldName))
+		if err != nil || name(old) != "level" {
+			t.Errorf("got %v %v, want the previous level kept", name(old), err)
+		}
+		entries, _ := os.ReadDir(filepath.Join(dir, "region"))
+		if len(entries) != 1 {
+			t.Errorf("got %v files, want the region file without temporary files", len(entries))
+		}
+	})
+
+	t.Run("Test success case: oversized chunk stored externally", func(t *testing.T) {
+		w, dir := newWorld(t)
+		mcc := filepath.Join(dir, "region", "c.0.0.mcc")
+		commitChunk(t, w, hugeChunk(0, 0))
+		if _, ok, err := w.Chunk(Overworld, Chunks, 0, 0); !ok || err != nil {
+			t.Errorf("got %v %v, want the oversized chunk", ok, err)
+		}
+		if _, err := os.Stat(mcc); err != nil {
+			t.Errorf("got %v, want the external file", err)
+		}
+
+		commitChunk(t, w, testChunk(0, 0, 0))
+		if _, err := os.Stat(mcc); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("got %v, want the external file removed", err)
+		}
+		entries, _ := os.ReadDir(filepath.Join(dir, "region"))
+		if len(entries) != 1 {
+			t.Errorf("got %v files, want the region file without temporary files", len(entries))
+		}
+	})
+
+	t.Run("Test success case: rollback", func(t *testing.T) {
+		w, _ := newWorld(t)
+		tx, err := w.Begin()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		tx.DeleteChunk(Overworld, Chunks, 0, 0)
+		if err = tx.Rollback(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, ok, _ := w.Chunk(Overworld, Chunks, 0, 0); !ok {
+			t.Errorf("got the chunk deleted, want it kept")
+		}
+		if err = tx.Commit(); err == nil {
+			t.Errorf("Expected error committing an ended transaction, got nil")
+		}
+	})
+
+	t.Run("Test failure case: corrupt region changes nothing", func(t *testing.T) {
+		w, dir := newWorld(t)
+		if err := os.WriteFile(filepath.Join(dir, "region", "r.1.0.mca"), []byte("corrupt"), 0o600); err != nil {
+			t.Fatalf("Unable to create test world: %v", err)
+		}
+		tx, err := w.Begin()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		tx.DeleteChunk(Overworld, Chunks, 0, 0)
+		tx.SetChunk(Overworld, Chunks, 32, 0, testChunk(32, 0, 0))
+		if err = tx.Commit(); err == nil {
+			t.Fatalf("Expected error, got nil")
+		}
+		if _, ok, _ := w.Chunk(Overworld, Chunks, 0, 0); !ok {
+			t.Errorf("got the chunk deleted, want it kept")
+		}
+		entries, _ := os.ReadDir(filepath.Join(dir, "region"))
+		if len(entries) != 2 {
+			t.Errorf("got %v files, want no temporary files left", len(entries))
+		}
+	})
+
+	t.Run("Test failure case: corrupt region keeps external chunks", func(t *testing.T) {
+		w, dir := newWorld(t)
+		mcc := filepath.Join(dir, "region", "c.0.0.mcc")
+		commitChunk(t, w, hugeChunk(0, 0))
+		before, _ := os.ReadFile(mcc) // #nosec G304 -- test file
+		if err := os.WriteFile(filepath.Join(dir, "region", "r.1.0.mca"), []byte("corrupt"), 0o600); err != nil {
+			t.Fatalf("Unable to create test world: %v", err)
+		}
+		tx, err := w.Begin()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		tx.SetChunk(Overworld, Chunks, 0, 0, testChunk(0, 0, 0))
+		tx.SetChunk(Overworld, Chunks, 32, 0, testChunk(32, 0, 0))
+		if err = tx.Commit(); err == nil {
+			t.Fatalf("Expected error, got nil")
+		}
+		if after, _ := os.ReadFile(mcc); len(before) == 0 || !bytes.Equal(before, after) { // #nosec G304 -- test file
+			t.Errorf("got the external file changed, want it kept")
+		}
+		if _, ok, err := w.Chunk(Overworld, Chunks, 0, 0); !ok || err != nil {
+			t.Errorf("got %v %v, want the oversized chunk kept", ok, err)
+		}
+	})
+
+	t.Run("Test failure case: second transaction in the same process", func(t *testing.T) {
+		w, dir := newWorld(t)
+		tx, err := w.Begin()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer func() { _ = tx.Rollback() }()
+		if _, err = Open(dir).Begin(); !errors.Is(err, nbt.ErrWorldLocked) {
+			t.Errorf("got %v, want %v", err, nbt.ErrWorldLocked)
+		}
+	})
+
+	t.Run("Test failure case: world not opened from a directory", func(t *testing.T) {
+		if _, err := New(testFS(t)).Begin(); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+}
+
+// hugeChunk returns a chunk at chunk coordinates x and z too large for a region file even when compressed, so it is
+// stored in an external .mcc file.
+func hugeChunk(x, z int) nbt.Tag {
+	data := make([]byte, 1<<20+region.SectorSize)
+	rand.New(rand.NewSource(1)).Read(data) // #nosec G404 -- test data
+	tag, _ := nbt.NewCompound("", nbt.NewInt("xPos", int32(x)), nbt.NewInt("zPos", int32(z)),
+		nbt.NewByteArray("data", data))
+	return tag
+}
+
+// commitChunk commits a transaction writing the overworld chunk at the coordinates of its xPos and zPos.
+func commitChunk(t *testing.T, w *World, chunk nbt.Tag) {
+	t.Helper()
+	x, _ := nbt.NewView(chunk).Child("xPos")
+	z, _ := nbt.NewView(chunk).Child("zPos")
+	tx, err := w.Begin()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tx.SetChunk(Overworld, Chunks, int(x.Payload().(int32)), int(z.Payload().(int32)), chunk)
+	if err = tx.Commit(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
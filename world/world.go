@@ -0,0 +1,271 @@
+// Package world reads the files of a Java edition world folder, resolving the file each part of the world is stored
+// in: level.dat, player data, the region files of chunks, entities and points of interest of each dimension, and the
+// saved data of each dimension.
+package world
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"PudFish/nbt"
+	"PudFish/nbt/coords"
+	"PudFish/nbt/region"
+)
+
+// Names of the files and directories of a world folder.
+const (
+	levelName     = "level.dat"
+	levelOldName  = "level.dat_old"
+	playerDataDir = "playerdata"
+	dataDir       = "data"
+	datExt        = ".dat"
+)
+
+// Dimension is a dimension of a world, named by the directory of its files within the world folder.
+type Dimension string
+
+// Dimensions of every world. Datapacks add others, stored under dimensions/<namespace>/<name>.
+const (
+	Overworld Dimension = "."
+	Nether    Dimension = "DIM-1"
+	End       Dimension = "DIM1"
+)
+
+// RegionKind is the kind of region files, named by their directory within a dimension.
+type RegionKind string
+
+// Kinds of region files. Entities are stored apart from chunks since Java edition 1.17.
+const (
+	Chunks   RegionKind = "region"
+	Entities RegionKind = "entities"
+	POI      RegionKind = "poi"
+)
+
+// World is a world folder.
+type World struct {
+	fsys fs.FS
+	// dir is the directory of the world on disk, empty if the world was not opened from a directory.
+	dir string
+}
+
+// New returns the World whose folder is the root of the file system.
+func New(fsys fs.FS) *World {
+	return &World{fsys: fsys}
+}
+
+// Open returns the World whose folder is the directory. Unlike New, chunks stored in external .mcc files can be read
+// from the world's region files.
+func Open(dir string) *World {
+	return &World{fsys: os.DirFS(dir), dir: dir}
+}
+
+// Level decodes level.dat, or level.dat_old if it fails to decode and fallback is true, see nbt.LoadLevelFS.
+func (w *World) Level(fallback bool, opts ...nbt.Option) (t nbt.Tag, usedFallback bool, err error) {
+	return nbt.LoadLevelFS(w.fsys, fallback, opts...)
+}
+
+// PlayerPath returns the path within the world folder of the data file of the player with the UUID, in its hyphenated
+// form.
+func PlayerPath(uuid string) string {
+	return path.Join(playerDataDir, uuid+datExt)
+}
+
+// Players returns the UUIDs of the players with data files, sorted.
+func (w *World) Players() (uuids []string, err error) {
+	uuids, err = w.datNames(playerDataDir)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to list players: %w", err)
+	}
+	return uuids, nil
+}
+
+// Player decodes the data file of the player with the UUID. The options configure decoding.
+func (w *World) Player(uuid string, opts ...nbt.Option) (t nbt.Tag, err error) {
+	t, err = w.readFile(PlayerPath(uuid), opts)
+	if err != nil {
+		return nbt.Tag{}, fmt.Errorf("Unable to load player %v: %w", uuid, err)
+	}
+	return t, nil
+}
+
+// DataPath returns the path within the world folder of the saved data file of the name in the dimension, such as
+// "data/raids.dat" or "DIM1/data/raids_end.dat".
+func DataPath(d Dimension, name string) string {
+	return path.Join(string(d), dataDir, name+datExt)
+}
+
+// DataNames returns the names of the saved data files of the dimension, sorted.
+func (w *World) DataNames(d Dimension) (names []string, err error) {
+	names, err = w.datNames(path.Join(string(d), dataDir))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to list %v data: %w", d, err)
+	}
+	return names, nil
+}
+
+// Data decodes the saved data file of the name in the dimension. The options configure decoding.
+func (w *World) Data(d Dimension, name string, opts ...nbt.Option) (t nbt.Tag, err error) {
+	t, err = w.readFile(DataPath(d, name), opts)
+	if err != nil {
+		return nbt.Tag{}, fmt.Errorf("Unable to load %v data %v: %w", d, name, err)
+	}
+	return t, nil
+}
+
+// RegionPath returns the path within the world folder of the region file of the kind holding the chunk at chunk
+// coordinates x and z in the dimension, such as "region/r.-1.0.mca".
+func RegionPath(d Dimension, kind RegionKind, chunkX, chunkZ int) string {
+	name := coords.RegionFileName(coords.ChunkToRegion(chunkX), coords.ChunkToRegion(chunkZ))
+	return path.Join(string(d), string(kind), name)
+}
+
+// RegionPos is the position of a region in region coordinates.
+type RegionPos struct {
+	X, Z int
+}
+
+// ChunkPos is the position of a chunk in chunk coordinates.
+type ChunkPos struct {
+	X, Z int
+}
+
+// Regions returns the positions of the region files of the kind in the dimension, sorted by X then Z. A dimension
+// without a directory of the kind has none.
+func (w *World) Regions(d Dimension, kind RegionKind) (regions []RegionPos, err error) {
+	entries, err := fs.ReadDir(w.fsys, path.Join(string(d), string(kind)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to list %v %v regions: %w", d, kind, err)
+	}
+
+	for _, entry := range entries {
+		x, z, ok := coords.ParseRegionFileName(entry.Name())
+		if !entry.IsDir() && ok {
+			regions = append(regions, RegionPos{X: x, Z: z})
+		}
+	}
+	slices.SortFunc(regions, func(a, b RegionPos) int {
+		return cmp.Or(cmp.Compare(a.X, b.X), cmp.Compare(a.Z, b.Z))
+	})
+	return regions, nil
+}
+
+// Region is an open region file of a world. It holds the file open until closed.
+type Region struct {
+	*region.Region
+	file fs.File
+}
+
+// Close closes the region file.
+func (r *Region) Close() error {
+	return r.file.Close()
+}
+
+// Region opens the region file of the kind in the dimension holding the chunk at chunk coordinates x and z. The error
+// wraps fs.ErrNotExist if the region has no file. A file that cannot be read at an offset is read into memory whole.
+func (w *World) Region(d Dimension, kind RegionKind, chunkX, chunkZ int) (r *Region, err error) {
+	name := RegionPath(d, kind, chunkX, chunkZ)
+	file, err := w.fsys.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to open region %v: %w", name, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = file.Close()
+		}
+	}()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to open region %v: %w", name, err)
+	}
+	readerAt, ok := file.(io.ReaderAt)
+	if !ok {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to open region %v: %w", name, err)
+		}
+		readerAt = bytes.NewReader(data)
+	}
+
+	opened, err := region.Open(readerAt, info.Size())
+	if err != nil {
+		return nil, fmt.Errorf("Unable to open region %v: %w", name, err)
+	}
+	if w.dir != "" {
+		opened.SetExternalDir(filepath.Join(w.dir, filepath.FromSlash(path.Dir(name))),
+			coords.ChunkToRegion(chunkX), coords.ChunkToRegion(chunkZ))
+	}
+	return &Region{Region: opened, file: file}, nil
+}
+
+// Chunk decodes the chunk of the kind in the dimension at chunk coordinates x and z, opening its region file. The
+// boolean is false if the chunk, or its whole region, is not present. The options configure decoding.
+func (w *World) Chunk(d Dimension, kind RegionKind, chunkX, chunkZ int, opts ...nbt.Option) (t nbt.Tag, ok bool,
+	err error) {
+	r, err := w.Region(d, kind, chunkX, chunkZ)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nbt.Tag{}, false, nil
+	}
+	if err != nil {
+		return nbt.Tag{}, false, err
+	}
+	defer r.Close()
+
+	return r.Chunk(chunkX, chunkZ, opts...)
+}
+
+// readFile decodes the NBT file at the path within the world folder, detecting its compression.
+func (w *World) readFile(name string, opts []nbt.Option) (t nbt.Tag, err error) {
+	file, err := w.fsys.Open(name)
+	if err != nil {
+		return nbt.Tag{}, err
+	}
+	defer file.Close()
+
+	t, _, err = nbt.ReadCompressed(file, opts...)
+	return t, err
+}
+
+// lock takes the session lock of a world opened with Open, before anything in it is written.
+func (w *World) lock() (*nbt.SessionLock, error) {
+	if w.dir == "" {
+		return nil, fmt.Errorf("world was not opened from a directory, see Open")
+	}
+	return nbt.LockWorld(w.dir)
+}
+
+// filePath returns the path on disk of the path within the world folder of a world opened with Open.
+func (w *World) filePath(name string) string {
+	return filepath.Join(w.dir, filepath.FromSlash(name))
+}
+
+// datNames returns the names, without the extension, of the .dat files of the directory within the world folder,
+// sorted. A missing directory has none.
+func (w *World) datNames(dir string) (names []string, err error) {
+	entries, err := fs.ReadDir(w.fsys, dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), datExt) {
+			names = append(names, strings.TrimSuffix(entry.Name(), datExt))
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,214 @@
+package world
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"PudFish/nbt"
+	"PudFish/nbt/region"
+)
+
+// encodeTestFile returns a gzip compressed file of a root compound holding a tagString "name".
+func encodeTestFile(t *testing.T, name string) []byte {
+	t.Helper()
+	tag, _ := nbt.NewCompound("", nbt.NewString("name", name))
+	var b bytes.Buffer
+	if err := nbt.WriteTag(&b, tag, nbt.WithCompression(nbt.CompressionGzip)); err != nil {
+		t.Fatalf("Unable to encode test file: %v", err)
+	}
+	return b.Bytes()
+}
+
+// encodeTestRegion returns a region file holding a chunk at each of the chunk coordinates, storing its coordinates.
+func encodeTestRegion(t *testing.T, chunks ...[2]int) []byte {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "r.mca")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Unable to create test region: %v", err)
+	}
+	defer f.Close()
+
+	r, err := region.Create(f)
+	if err != nil {
+		t.Fatalf("Unable to create test region: %v", err)
+	}
+	for _, xz := range chunks {
+		tag, _ := nbt.NewCompound("", nbt.NewInt("xPos", int32(xz[0])), nbt.NewInt("zPos", int32(xz[1])))
+		chunk := region.Chunk{ChunkHeader: region.ChunkHeader{X: xz[0], Z: xz[1]}, Tag: tag,
+			Compression: nbt.CompressionZlib}
+		if err = r.WriteChunk(chunk); err != nil {
+			t.Fatalf("Unable to write test chunk: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path) // #nosec G304 -- test file
+	if err != nil {
+		t.Fatalf("Unable to read test region: %v", err)
+	}
+	return data
+}
+
+// name returns the tagString "name" of a decoded test file.
+func name(t nbt.Tag) any {
+	child, _ := nbt.NewView(t).Child("name")
+	return child.Payload()
+}
+
+// testFS returns a world folder holding each kind of file.
+func testFS(t *testing.T) fstest.MapFS {
+	return fstest.MapFS{
+		"level.dat":     {Data: []byte("corrupt")},
+		"level.dat_old": {Data: encodeTestFile(t, "old level")},
+		"playerdata/069a79f4-44e9-4726-a5be-fca90e38aaf5.dat":     {Data: encodeTestFile(t, "player")},
+		"playerdata/069a79f4-44e9-4726-a5be-fca90e38aaf5.dat_old": {Data: encodeTestFile(t, "old player")},
+		"DIM1/data/raids_end.dat":                                 {Data: encodeTestFile(t, "raids")},
+		"region/r.-1.0.mca":                                       {Data: encodeTestRegion(t, [2]int{-1, 5})},
+		"region/r.0.0.mca":                                        {Data: encodeTestRegion(t)},
+		"region/r.0.0.mca.tmp":                                    {Data: []byte{}},
+		"DIM-1/entities/r.2.-3.mca":                               {Data: encodeTestRegion(t, [2]int{64, -96})},
+	}
+}
+
+func TestWorldFiles(t *testing.T) {
+	w := New(testFS(t))
+
+	t.Run("Test success case: level falls back to level.dat_old", func(t *testing.T) {
+		got, usedFallback, err := w.Level(true)
+		if err != nil || !usedFallback || name(got) != "old level" {
+			t.Errorf("got %v %v %v, want the old level", name(got), usedFallback, err)
+		}
+	})
+
+	t.Run("Test failure case: level without fallback", func(t *testing.T) {
+		if _, _, err := w.Level(false); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+
+	t.Run("Test success case: players", func(t *testing.T) {
+		uuids, err := w.Players()
+		want := []string{"069a79f4-44e9-4726-a5be-fca90e38aaf5"}
+		if err != nil || !reflect.DeepEqual(uuids, want) {
+			t.Fatalf("got %v %v, want %v", uuids, err, want)
+		}
+		got, err := w.Player(uuids[0])
+		if err != nil || name(got) != "player" {
+			t.Errorf("got %v %v, want the player", name(got), err)
+		}
+	})
+
+	t.Run("Test success case: data", func(t *testing.T) {
+		names, err := w.DataNames(End)
+		if err != nil || !reflect.DeepEqual(names, []string{"raids_end"}) {
+			t.Fatalf("got %v %v, want raids_end", names, err)
+		}
+		got, err := w.Data(End, "raids_end")
+		if err != nil || name(got) != "raids" {
+			t.Errorf("got %v %v, want the raids", name(got), err)
+		}
+		if names, err = w.DataNames(Overworld); err != nil || names != nil {
+			t.Errorf("got %v %v, want no overworld data", names, err)
+		}
+	})
+
+	t.Run("Test failure case: missing player", func(t *testing.T) {
+		if _, err := w.Player("missing"); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("got %v, want fs.ErrNotExist", err)
+		}
+	})
+}
+
+func TestWorldPaths(t *testing.T) {
+	successCases := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"player", PlayerPath("abc"), "playerdata/abc.dat"},
+		{"overworld data", DataPath(Overworld, "raids"), "data/raids.dat"},
+		{"end data", DataPath(End, "raids_end"), "DIM1/data/raids_end.dat"},
+		{"overworld region", RegionPath(Overworld, Chunks, -1, 31), "region/r.-1.0.mca"},
+		{"nether entities", RegionPath(Nether, Entities, 64, -96), "DIM-1/entities/r.2.-3.mca"},
+		{"end poi", RegionPath(End, POI, 32, 0), "DIM1/poi/r.1.0.mca"},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			if successCase.got != successCase.want {
+				t.Errorf("got %v, want %v", successCase.got, successCase.want)
+			}
+		})
+	}
+}
+
+func TestWorldRegions(t *testing.T) {
+	w := New(testFS(t))
+
+	t.Run("Test success case: regions listed", func(t *testing.T) {
+		got, err := w.Regions(Overworld, Chunks)
+		want := []RegionPos{{-1, 0}, {0, 0}}
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v %v, want %v", got, err, want)
+		}
+		if got, err = w.Regions(End, Chunks); err != nil || got != nil {
+			t.Errorf("got %v %v, want none", got, err)
+		}
+	})
+
+	successCases := []struct {
+		name   string
+		d      Dimension
+		kind   RegionKind
+		x, z   int
+		wantOK bool
+	}{
+		{"overworld chunk", Overworld, Chunks, -1, 5, true},
+		{"nether entities", Nether, Entities, 64, -96, true},
+		{"chunk absent from region", Overworld, Chunks, 0, 0, false},
+		{"region absent", End, Chunks, 0, 0, false},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			got, ok, err := w.Chunk(successCase.d, successCase.kind, successCase.x, successCase.z)
+			if err != nil || ok != successCase.wantOK {
+				t.Fatalf("got %v %v, want %v", ok, err, successCase.wantOK)
+			}
+			if x, _ := nbt.NewView(got).Child("xPos"); ok && x.Payload() != int32(successCase.x) {
+				t.Errorf("got xPos %v, want %v", x.Payload(), successCase.x)
+			}
+		})
+	}
+
+	t.Run("Test success case: open from directory", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(dir, "region"), 0o750); err != nil {
+			t.Fatalf("Unable to create test world: %v", err)
+		}
+		err := os.WriteFile(filepath.Join(dir, "region", "r.-1.0.mca"), encodeTestRegion(t, [2]int{-1, 5}), 0o600)
+		if err != nil {
+			t.Fatalf("Unable to create test world: %v", err)
+		}
+
+		r, err := Open(dir).Region(Overworld, Chunks, -1, 5)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer r.Close()
+		if _, ok, err := r.Chunk(-1, 5); !ok || err != nil {
+			t.Errorf("got %v %v, want the chunk", ok, err)
+		}
+	})
+
+	t.Run("Test failure case: corrupt region", func(t *testing.T) {
+		fsys := fstest.MapFS{"region/r.0.0.mca": {Data: []byte("short")}}
+		if _, _, err := New(fsys).Chunk(Overworld, Chunks, 0, 0); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,331 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// WriteTag writes the tag to the buffer in the byte order and compression of the options, which default to big-endian
+// (Java edition) and uncompressed, see Option. Every tag read by ReadTag with the same byte order is written back to
+// the same uncompressed bytes, including the declared element type of empty lists and the payloads of unknown tags read
+// with WithUnknownTags, except that the elements of a list of lists hold no element type, so those that are empty are
+// written as lists of tagEnd.
+func WriteTag(buffer io.Writer, t Tag, opts ...Option) error {
+	o := newOptions(opts)
+	compressed, err := compress(checksumWriter(buffer, o), o.Compression, o.CompressionLevel)
+	if err != nil {
+		return fmt.Errorf("Unable to write tag: %w", err)
+	}
+
+	err = writeTag(compressed, t, o)
+	if err != nil {
+		_ = compressed.Close()
+		return err
+	}
+
+	err = compressed.Close()
+	if err != nil {
+		return fmt.Errorf("Unable to write tag: %w", err)
+	}
+	return nil
+}
+
+// checksumWriter returns w, teed through the Checksum of the options if set.
+func checksumWriter(w io.Writer, o Options) io.Writer {
+	if o.Checksum == nil {
+		return w
+	}
+	return io.MultiWriter(w, o.Checksum)
+}
+
+// writeTag writes a whole tag, its ID, name and payload.
+func writeTag(buffer io.Writer, t Tag, o Options) (err error) {
+	err = writeTagID(buffer, o, t.id)
+	if err != nil {
+		return fmt.Errorf("Unable to write tag: %w", err)
+	}
+
+	// tagEnd has no name or payload.
+	if t.id == tagEnd {
+		return nil
+	}
+
+	// The root tag of the network format has no name.
+	if o.depth > 0 || !o.NetworkFormat {
+		err = writeTagName(buffer, o, t.name)
+		if err != nil {
+			return fmt.Errorf("Unable to write tag: %w", err)
+		}
+	}
+
+	if t.id == tagList {
+		elements, ok := t.payload.([]any)
+		if !ok && t.payload != nil {
+			return fmt.Errorf("Unable to write tag \"%v\": tagList payload has type %T, not []any", t.name, t.payload)
+		}
+		err = writeTagListPayload(buffer, o, t.ElementID(), elements)
+	} else {
+		err = writeTagPayload(buffer, o, t.id, t.payload)
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to write tag \"%v\": %w", t.name, err)
+	}
+	return nil
+}
+
+// writeTagID writes the ID of a tag, the first byte of every tag.
+func writeTagID(buffer io.Writer, o Options, id uint8) (err error) {
+	err = binary.Write(buffer, o.ByteOrder, id)
+	if err != nil {
+		return fmt.Errorf("Unable to write tag ID: %w", err)
+	}
+
+	return nil
+}
+
+// writeTagName writes the name of a tag as a signed short length then the encoded bytes, matching readTagName, so
+// names are limited to 32767 bytes.
+func writeTagName(buffer io.Writer, o Options, name string) (err error) {
+	b := encodeString(name, o)
+	if len(b) > math.MaxInt16 {
+		return fmt.Errorf("Unable to write tag name: %v bytes exceeds the maximum of %v", len(b), math.MaxInt16)
+	}
+
+	err = writeStringLength(buffer, o, len(b))
+	if err != nil {
+		return fmt.Errorf("Unable to write tag name length: %w", err)
+	}
+
+	_, err = buffer.Write(b)
+	if err != nil {
+		return fmt.Errorf("Unable to write tag name: %w", err)
+	}
+
+	return nil
+}
+
+// writeTagPayload writes the payload of a tag of the ID, which must have the Go type listed against the Tag type, or
+// be a RawPayload for an ID above tagLongArray. A nil payload is an empty compound, list or array. The elements of a
+// tagList are written with the ID of the first element.
+func writeTagPayload(buffer io.Writer, o Options, tagID uint8, payload any) (err error) {
+	switch tagID {
+	case tagEnd:
+		err = fmt.Errorf("Not expecting to write a tagEnd in the payload")
+	case tagByte:
+		err = writeTagNumberPayload[byte](buffer, o, "tagByte", payload)
+	case tagShort:
+		err = writeTagNumberPayload[int16](buffer, o, "tagShort", payload)
+	case tagInt:
+		err = writeTagNumberPayload[int32](buffer, o, "tagInt", payload)
+	case tagLong:
+		err = writeTagNumberPayload[int64](buffer, o, "tagLong", payload)
+	case tagFloat:
+		err = writeTagNumberPayload[float32](buffer, o, "tagFloat", payload)
+	case tagDouble:
+		err = writeTagNumberPayload[float64](buffer, o, "tagDouble", payload)
+	case tagByteArray:
+		err = writeTagArrayPayload[byte](buffer, o, "tagByteArray", payload)
+	case tagString:
+		err = writeTagStringPayload(buffer, o, payload)
+	case tagList:
+		elements, ok := payload.([]any)
+		if !ok && payload != nil {
+			return fmt.Errorf("Unable to write tagList payload: payload has type %T, not []any", payload)
+		}
+		var elementID uint8
+		elementID, err = listElementID(elements)
+		if err == nil {
+			err = writeTagListPayload(buffer, o, elementID, elements)
+		}
+	case tagCompound:
+		err = writeTagCompoundPayload(buffer, o, payload)
+	case tagIntArray:
+		err = writeTagArrayPayload[int32](buffer, o, "tagIntArray", payload)
+	case tagLongArray:
+		err = writeTagArrayPayload[int64](buffer, o, "tagLongArray", payload)
+	default:
+		raw, ok := payload.(RawPayload)
+		if !ok {
+			return fmt.Errorf("tag ID %v not between 0 (tagEnd) and 12 (tagLongArray)", tagID)
+		}
+		_, err = buffer.Write(raw)
+	}
+	return err
+}
+
+// writeTagNumberPayload writes the payload of a tagByte, tagShort, tagInt, tagLong, tagFloat or tagDouble, which must
+// have the Go type P. Floats are written with the exact bits held, so -0.0 and NaN payloads round trip, unless the
+// NonFinite policy of the Options canonicalises or rejects NaN and ±Inf. Integers of tagInt and tagLong are VarInts if
+// the Options select them.
+func writeTagNumberPayload[P byte | int16 | int32 | int64 | float32 | float64](buffer io.Writer, o Options,
+	tagType string, payload any) (err error) {
+	p, ok := payload.(P)
+	if !ok {
+		return fmt.Errorf("Unable to write %v payload: payload has type %T, not %T", tagType, payload, p)
+	}
+
+	switch v := any(p).(type) {
+	case int32:
+		err = writeInt32(buffer, o, v)
+	case int64:
+		err = writeInt64(buffer, o, v)
+	case float32:
+		v, err = nonFinite(o, v, math.Float32frombits(canonicalNaN32))
+		if err == nil {
+			err = binary.Write(buffer, o.ByteOrder, v)
+		}
+	case float64:
+		v, err = nonFinite(o, v, math.Float64frombits(canonicalNaN64))
+		if err == nil {
+			err = binary.Write(buffer, o.ByteOrder, v)
+		}
+	default:
+		err = binary.Write(buffer, o.ByteOrder, p)
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to write %v payload: %w", tagType, err)
+	}
+
+	return nil
+}
+
+// nonFinite applies the NonFinite policy of the Options to a float, returning the canonical NaN in place of a NaN if
+// canonicalising, or an error for a NaN or ±Inf if rejecting them.
+func nonFinite[F float32 | float64](o Options, f, canonicalNaN F) (F, error) {
+	nan, inf := math.IsNaN(float64(f)), math.IsInf(float64(f), 0)
+	switch {
+	case o.NonFinite == NonFiniteCanonical && nan:
+		return canonicalNaN, nil
+	case o.NonFinite == NonFiniteError && (nan || inf):
+		return f, fmt.Errorf("non-finite value %v", f)
+	default:
+		return f, nil
+	}
+}
+
+// writeTagArrayPayload writes the payload of a tagByteArray, tagIntArray or tagLongArray, which must have the Go type
+// []P, as a signed integer size then the elements, each a VarInt in an integer array if the Options select them.
+func writeTagArrayPayload[P byte | int32 | int64](buffer io.Writer, o Options, tagType string,
+	payload any) (err error) {
+	p, ok := payload.([]P)
+	if !ok && payload != nil {
+		return fmt.Errorf("Unable to write %v payload: payload has type %T, not %T", tagType, payload, p)
+	}
+
+	if len(p) > math.MaxInt32 {
+		return fmt.Errorf("Unable to write %v payload size: %v elements exceeds the maximum of %v", tagType, len(p),
+			math.MaxInt32)
+	}
+
+	err = writeInt32(buffer, o, int32(len(p))) // #nosec G115 -- checked above
+	if err != nil {
+		return fmt.Errorf("Unable to write %v payload size: %w", tagType, err)
+	}
+
+	if _, isBytes := payload.([]byte); isBytes || !o.VarInt {
+		err = binary.Write(buffer, o.ByteOrder, p)
+		if err != nil {
+			return fmt.Errorf("Unable to write %v payload: %w", tagType, err)
+		}
+		return nil
+	}
+
+	for i, element := range p {
+		err = writeTagNumberPayload[P](buffer, o, tagType, element)
+		if err != nil {
+			return fmt.Errorf("Unable to write %v payload element %v: %w", tagType, i, err)
+		}
+	}
+
+	return nil
+}
+
+// writeTagStringPayload writes the payload of a tagString as an unsigned short length then the encoded bytes.
+func writeTagStringPayload(buffer io.Writer, o Options, payload any) (err error) {
+	p, ok := payload.(string)
+	if !ok {
+		return fmt.Errorf("Unable to write tagString payload: payload has type %T, not string", payload)
+	}
+
+	b := encodeString(p, o)
+	if len(b) > math.MaxUint16 {
+		return fmt.Errorf("Unable to write tagString payload: %v bytes exceeds the maximum of %v", len(b),
+			math.MaxUint16)
+	}
+
+	err = writeStringLength(buffer, o, len(b))
+	if err != nil {
+		return fmt.Errorf("Unable to write tagString payload length: %w", err)
+	}
+
+	_, err = buffer.Write(b)
+	if err != nil {
+		return fmt.Errorf("Unable to write tagString payload: %w", err)
+	}
+
+	return nil
+}
+
+// writeTagListPayload writes the payload of a tagList as the element tag ID, the signed integer length, then the
+// payload of each element, which must all have the Go type of the element ID. Only an empty list may have tagEnd
+// elements. The elements are checked before any are written, so a list of mixed elements is not part written.
+func writeTagListPayload(buffer io.Writer, o Options, elementID uint8, elements []any) (err error) {
+	if len(elements) > math.MaxInt32 {
+		return fmt.Errorf("Unable to write tagList length: %v elements exceeds the maximum of %v", len(elements),
+			math.MaxInt32)
+	}
+	if elementID == tagEnd && len(elements) > 0 {
+		return fmt.Errorf("Unable to write tagList type: %v elements of tagEnd", len(elements))
+	}
+	for i, element := range elements {
+		// Payloads of no tag type, such as the RawPayload of unknown tags, are checked as they are written.
+		if id, err := payloadID(element); err == nil && id != elementID {
+			return fmt.Errorf("Unable to write tagList payload element %v: tag ID %v is not the element tag ID %v", i,
+				id, elementID)
+		}
+	}
+
+	err = binary.Write(buffer, o.ByteOrder, elementID)
+	if err != nil {
+		return fmt.Errorf("Unable to write tagList type: %w", err)
+	}
+
+	err = writeInt32(buffer, o, int32(len(elements))) // #nosec G115 -- checked above
+	if err != nil {
+		return fmt.Errorf("Unable to write tagList length: %w", err)
+	}
+
+	for i, element := range elements {
+		err = writeTagPayload(buffer, o, elementID, element)
+		if err != nil {
+			return fmt.Errorf("Unable to write tagList payload element %v: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// writeTagCompoundPayload writes the payload of a tagCompound as each child tag, then a tagEnd.
+func writeTagCompoundPayload(buffer io.Writer, o Options, payload any) (err error) {
+	children, ok := payload.([]Tag)
+	if !ok && payload != nil {
+		return fmt.Errorf("Unable to write tagCompound payload: payload has type %T, not []Tag", payload)
+	}
+
+	o.depth++
+
+	for i, child := range children {
+		if child.id == tagEnd {
+			return fmt.Errorf("Unable to write tagCompound payload element %v: tagEnd ends the compound early", i)
+		}
+		err = writeTag(buffer, child, o)
+		if err != nil {
+			return fmt.Errorf("Unable to write tagCompound payload element %v: %w", i, err)
+		}
+	}
+
+	return writeTagID(buffer, o, tagEnd)
+}
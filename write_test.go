@@ -0,0 +1,224 @@
+package nbt
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"math"
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestWriteTag(t *testing.T) {
+	// document holds every tag type, an empty list of compounds, a list of lists and a signalling NaN float.
+	document := []byte{tagCompound, 0, 4, 'r', 'o', 'o', 't',
+		tagByte, 0, 1, 'b', 0xFF,
+		tagShort, 0, 1, 's', 0x12, 0x34,
+		tagInt, 0, 1, 'i', 0, 0, 0, 7,
+		tagLong, 0, 1, 'l', 0, 0, 0, 0, 0, 0, 0, 8,
+		tagFloat, 0, 1, 'f', 0x7F, 0x80, 0, 1,
+		tagDouble, 0, 1, 'd', 0x80, 0, 0, 0, 0, 0, 0, 0,
+		tagByteArray, 0, 2, 'b', 'a', 0, 0, 0, 2, 1, 2,
+		tagString, 0, 3, 's', 't', 'r', 0, 2, 'h', 'i',
+		tagList, 0, 1, 'e', tagCompound, 0, 0, 0, 0,
+		tagList, 0, 2, 'l', 'l', tagList, 0, 0, 0, 2, tagInt, 0, 0, 0, 1, 0, 0, 0, 3, tagEnd, 0, 0, 0, 0,
+		tagCompound, 0, 1, 'c', tagString, 0, 0, 0, 0, tagEnd,
+		tagIntArray, 0, 2, 'i', 'a', 0, 0, 0, 1, 0, 0, 0, 9,
+		tagLongArray, 0, 2, 'l', 'a', 0, 0, 0, 0,
+		tagEnd}
+
+	successCases := []struct {
+		name  string
+		input []byte
+		opts  []Option
+	}{
+		{"every tag type round trips", document, nil},
+		{"little-endian round trips", []byte{tagCompound, 0, 0, tagInt, 1, 0, 'i', 7, 0, 0, 0, tagEnd},
+			[]Option{BedrockEdition}},
+		{"unknown tags round trip", []byte{tagCompound, 0, 0, 13, 0, 1, 'u', 1, 2, tagEnd},
+			[]Option{WithUnknownTags(FixedSizeTags(map[uint8]int{13: 2}))}},
+		{"tagEnd root", []byte{tagEnd}, nil},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			tag, err := ReadTag(bytes.NewReader(successCase.input), successCase.opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var got bytes.Buffer
+			if err = WriteTag(&got, tag, successCase.opts...); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !bytes.Equal(got.Bytes(), successCase.input) {
+				t.Errorf("got % X, want % X", got.Bytes(), successCase.input)
+			}
+		})
+	}
+
+	t.Run("Test success case: quick.Check generated trees round trip", func(t *testing.T) {
+		property := func(root Tag) bool {
+			var b bytes.Buffer
+			if err := WriteTag(&b, root, WithByteOrder(binary.LittleEndian)); err != nil {
+				return false
+			}
+			got, err := ReadTag(&b, WithByteOrder(binary.LittleEndian))
+			return err == nil && payloadsEqual(got.payload, root.payload)
+		}
+		if err := quick.Check(property, &quick.Config{MaxCount: 50}); err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+	})
+
+	t.Run("Test success case: compressed at each level", func(t *testing.T) {
+		tag, _ := NewCompound("", NewString("s", strings.Repeat("a", 1000)))
+		sizes := map[int]int{}
+		for _, level := range []int{flate.NoCompression, flate.BestSpeed, flate.DefaultCompression} {
+			for _, compression := range []Compression{CompressionGzip, CompressionZlib} {
+				var b bytes.Buffer
+				opts := []Option{WithCompression(compression), WithCompressionLevel(level)}
+				if err := WriteTag(&b, tag, opts...); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				sizes[level] = b.Len()
+				if got, err := ReadTag(&b, opts...); err != nil || !payloadsEqual(got.payload, tag.payload) {
+					t.Errorf("got %v %v reading %v level %v, want the tag", got, err, compression, level)
+				}
+			}
+		}
+		if sizes[flate.NoCompression] < 1000 || sizes[flate.BestSpeed] > 100 {
+			t.Errorf("got sizes %v, want the stored output over 1000 bytes and compressed under 100", sizes)
+		}
+	})
+
+	t.Run("Test success case: checksum of the compressed output", func(t *testing.T) {
+		var b bytes.Buffer
+		checksum := crc32.NewIEEE()
+		if err := WriteTag(&b, NewInt("i", 7), WithCompression(CompressionZlib), WithChecksum(checksum)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got, want := checksum.Sum32(), crc32.ChecksumIEEE(b.Bytes()); got != want {
+			t.Errorf("got %x, want %x", got, want)
+		}
+	})
+
+	t.Run("Test success case: nil payloads are empty", func(t *testing.T) {
+		var got bytes.Buffer
+		if err := WriteTag(&got, Tag{id: tagCompound, payload: []Tag{{id: tagList, name: "l"},
+			{id: tagIntArray, name: "a"}}}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want := []byte{tagCompound, 0, 0, tagList, 0, 1, 'l', tagEnd, 0, 0, 0, 0, tagIntArray, 0, 1, 'a', 0, 0, 0, 0,
+			tagEnd}
+		if !bytes.Equal(got.Bytes(), want) {
+			t.Errorf("got % X, want % X", got.Bytes(), want)
+		}
+	})
+
+	failureCases := []struct {
+		name string
+		t    Tag
+	}{
+		{"payload type mismatch", Tag{id: tagInt, payload: int16(1)}},
+		{"mixed list elements", Tag{id: tagList, payload: []any{int32(1), "a"}}},
+		{"list elements not of element type", Tag{id: tagList, elementID: tagString, payload: []any{int32(1)}}},
+		{"mixed nested list elements", Tag{id: tagList, payload: []any{[]any{byte(1), int16(2)}}}},
+		{"unknown list elements", Tag{id: tagList, elementID: 13, payload: []any{int32(1)}}},
+		{"list payload not a slice", Tag{id: tagList, payload: "a"}},
+		{"tagEnd child", Tag{id: tagCompound, payload: []Tag{{id: tagEnd}}}},
+		{"unknown tag ID", Tag{id: 13, payload: int32(1)}},
+		{"name too long", Tag{id: tagByte, name: strings.Repeat("a", 1<<15), payload: byte(0)}},
+		{"string too long", Tag{id: tagString, payload: strings.Repeat("a", 1<<16)}},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			if err := WriteTag(&bytes.Buffer{}, failureCase.t); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+
+	t.Run("Test failure case: mixed list elements are not part written", func(t *testing.T) {
+		var got bytes.Buffer
+		err := writeTagListPayload(&got, newOptions(nil), tagInt, []any{int32(1), "a"})
+		if err == nil || got.Len() != 0 {
+			t.Errorf("got %v and % X written, want an error and nothing written", err, got.Bytes())
+		}
+	})
+
+	t.Run("Test failure case: invalid compression level", func(t *testing.T) {
+		err := WriteTag(&bytes.Buffer{}, Tag{id: tagCompound}, WithCompression(CompressionGzip), WithCompressionLevel(42))
+		if err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+
+	t.Run("Test failure case: unknown compression", func(t *testing.T) {
+		if err := WriteTag(&bytes.Buffer{}, Tag{id: tagCompound}, WithCompression(Compression(9))); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+
+	t.Run("Test failure case: writer fails", func(t *testing.T) {
+		if err := WriteTag(failingWriter{}, Tag{id: tagCompound}); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+}
+
+func TestWriteTagNonFinite(t *testing.T) {
+	signallingNaN := math.Float32frombits(0x7F800001)
+
+	successCases := []struct {
+		name   string
+		t      Tag
+		policy NonFinitePolicy
+		want   []byte
+	}{
+		{"keep NaN bits", NewFloat("", signallingNaN), NonFiniteKeep, []byte{tagFloat, 0, 0, 0x7F, 0x80, 0, 1}},
+		{"canonical float NaN", NewFloat("", signallingNaN), NonFiniteCanonical,
+			[]byte{tagFloat, 0, 0, 0x7F, 0xC0, 0, 0}},
+		{"canonical double NaN", NewDouble("", math.Float64frombits(0xFFF0000000000001)), NonFiniteCanonical,
+			[]byte{tagDouble, 0, 0, 0x7F, 0xF8, 0, 0, 0, 0, 0, 0}},
+		{"canonical keeps infinity", NewFloat("", float32(math.Inf(-1))), NonFiniteCanonical,
+			[]byte{tagFloat, 0, 0, 0xFF, 0x80, 0, 0}},
+		{"error allows finite", NewDouble("", 1), NonFiniteError, []byte{tagDouble, 0, 0, 0x3F, 0xF0, 0, 0, 0, 0, 0, 0}},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			var got bytes.Buffer
+			if err := WriteTag(&got, successCase.t, WithNonFinite(successCase.policy)); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !bytes.Equal(got.Bytes(), successCase.want) {
+				t.Errorf("got % X, want % X", got.Bytes(), successCase.want)
+			}
+		})
+	}
+
+	list, _ := NewList("l", IDDouble, NewDouble("", 1), NewDouble("", math.Inf(1)))
+	failureCases := []struct {
+		name string
+		t    Tag
+	}{
+		{"float NaN", NewFloat("", float32(math.NaN()))},
+		{"double infinity", NewDouble("", math.Inf(1))},
+		{"infinity in a list", list},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			if err := WriteTag(&bytes.Buffer{}, failureCase.t, WithNonFinite(NonFiniteError)); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,675 @@
+// Package nbt enables robust reading and writing of Minecraft named binary tags (NBT) files.
+package nbt
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The YAML written by ToYAML is block style, indented by two spaces, so a tag tree can be edited in a text editor:
+// compounds are mappings, lists are sequences, and scalars and arrays are on the line of their key or dash. Types
+// YAML cannot tell apart are kept by local tags:
+//
+//	Data:
+//	  LevelName: "World"
+//	  version: 19133
+//	  hardcore: !byte 0
+//	  Seed: !long 1234
+//	  SpawnAngle: !float 0.5
+//	  BorderCenterX: 0.0
+//	  Pos: !int_array [1, 2, 3]
+//	  ServerBrands:
+//	    - "vanilla"
+//	  Empty: !list:string []
+//
+// A tagInt, tagDouble, tagString, tagList and tagCompound is untagged, a double always having a "." or exponent.
+// Every other type is tagged by its name: !byte, !short, !long, !float, !byte_array, !int_array and !long_array. An
+// empty list is tagged with its element type, as !list:<type>. Strings are double-quoted, and NaN and infinities are
+// .nan, .inf and -.inf.
+//
+// FromYAML reads this form and the common hand edits of it: comments, unquoted and single-quoted strings, a sequence
+// at the same indent as its key, compact "- key: value" and "- - value" entries within a sequence, and flow sequences
+// of scalars. An untagged whole number is a tagInt, or a tagLong if it overflows 32 bits, true and false are a
+// tagByte, and any other unquoted scalar that is not a number is a tagString. Anchors, aliases, multi-line scalars and
+// flow mappings other than {} are not supported.
+
+// yamlTypeNames are the names of tag types in YAML tags, by tag ID.
+var yamlTypeNames = []string{"end", "byte", "short", "int", "long", "float", "double", "byte_array", "string", "list",
+	"compound", "int_array", "long_array"}
+
+// Patterns of untagged YAML scalars.
+var (
+	yamlInteger = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	yamlFloat   = regexp.MustCompile(`^[-+]?(?:[0-9]+\.[0-9]*|\.[0-9]+|[0-9]+(?:\.[0-9]*)?[eE][-+]?[0-9]+|` +
+		`[0-9]*\.[0-9]+[eE][-+]?[0-9]+)$|^[-+]?\.(?:inf|Inf|INF)$|^\.(?:nan|NaN|NAN)$`)
+	yamlPlainKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// yamlReservedKeys are plain scalars other YAML readers take as booleans or null, so are quoted as keys.
+var yamlReservedKeys = []string{"y", "yes", "n", "no", "true", "false", "on", "off", "null"}
+
+// ToYAML returns the payload of the tag as YAML, in the form described above, so it can be edited by hand and read
+// back with FromYAML. The tag's own name is not included, as with SNBT. Tags with IDs above tagLongArray, having no
+// YAML form, are an error.
+func ToYAML(t Tag) (data []byte, err error) {
+	var b bytes.Buffer
+	payload := payloadOrEmpty(t)
+	switch p := payload.(type) {
+	case []Tag:
+		if len(p) == 0 {
+			b.WriteString("{}\n")
+			break
+		}
+		err = writeYAMLMapping(&b, p, 0)
+	case []any:
+		if len(p) == 0 {
+			b.WriteString(yamlEmptyList(t.ElementID()) + "\n")
+			break
+		}
+		err = writeYAMLSequence(&b, p, 0)
+	default:
+		var s string
+		if s, err = yamlScalar(payload); err == nil {
+			b.WriteString(s + "\n")
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to convert tag \"%v\" to YAML: %w", t.name, err)
+	}
+	return b.Bytes(), nil
+}
+
+// writeYAMLMapping writes the children of a compound as a block mapping at the indent.
+func writeYAMLMapping(b *bytes.Buffer, children []Tag, indent int) (err error) {
+	for _, child := range children {
+		b.WriteString(strings.Repeat(" ", indent))
+		writeYAMLKey(b, child.name)
+		b.WriteByte(':')
+		if err = writeYAMLValue(b, payloadOrEmpty(child), child.ElementID(), indent+2); err != nil {
+			return fmt.Errorf("child \"%v\": %w", child.name, err)
+		}
+	}
+	return nil
+}
+
+// writeYAMLSequence writes the elements of a list as a block sequence at the indent.
+func writeYAMLSequence(b *bytes.Buffer, elements []any, indent int) (err error) {
+	for i, element := range elements {
+		b.WriteString(strings.Repeat(" ", indent) + "-")
+		var elementID uint8
+		if nested, ok := element.([]any); ok {
+			elementID, _ = listElementID(nested)
+		}
+		if err = writeYAMLValue(b, element, elementID, indent+2); err != nil {
+			return fmt.Errorf("element %v: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// writeYAMLValue writes a payload after its key or dash: on the same line for scalars, arrays and empty compounds and
+// lists, otherwise as a block on the following lines at the indent.
+func writeYAMLValue(b *bytes.Buffer, payload any, elementID uint8, indent int) (err error) {
+	switch p := payload.(type) {
+	case []Tag:
+		if len(p) == 0 {
+			b.WriteString(" {}\n")
+			return nil
+		}
+		b.WriteByte('\n')
+		return writeYAMLMapping(b, p, indent)
+	case []any:
+		if len(p) == 0 {
+			b.WriteString(" " + yamlEmptyList(elementID) + "\n")
+			return nil
+		}
+		b.WriteByte('\n')
+		return writeYAMLSequence(b, p, indent)
+	default:
+		s, err := yamlScalar(payload)
+		if err != nil {
+			return err
+		}
+		b.WriteString(" " + s + "\n")
+		return nil
+	}
+}
+
+// writeYAMLKey writes a mapping key, plain if it cannot be mistaken for another scalar and double-quoted otherwise.
+func writeYAMLKey(b *bytes.Buffer, name string) {
+	lower := strings.ToLower(name)
+	for _, reserved := range yamlReservedKeys {
+		if lower == reserved {
+			writeJSONString(b, name)
+			return
+		}
+	}
+	if yamlPlainKey.MatchString(name) {
+		b.WriteString(name)
+		return
+	}
+	writeJSONString(b, name)
+}
+
+// yamlEmptyList returns the YAML of an empty list of the element ID, tagged with its type if it has one.
+func yamlEmptyList(elementID uint8) string {
+	if elementID == tagEnd || int(elementID) >= len(yamlTypeNames) {
+		return "[]"
+	}
+	return "!list:" + yamlTypeNames[elementID] + " []"
+}
+
+// yamlScalar returns a scalar or array payload as YAML, tagged with its type where YAML cannot infer it.
+func yamlScalar(payload any) (s string, err error) {
+	switch p := payload.(type) {
+	case byte:
+		return "!byte " + strconv.Itoa(int(int8(p))), nil // #nosec G115 -- tagByte is signed
+	case int16:
+		return "!short " + strconv.Itoa(int(p)), nil
+	case int32:
+		return strconv.Itoa(int(p)), nil
+	case int64:
+		return "!long " + strconv.FormatInt(p, 10), nil
+	case float32:
+		return "!float " + yamlFloatString(float64(p), 32), nil
+	case float64:
+		s = yamlFloatString(p, 64)
+		if !strings.ContainsAny(s, ".e") {
+			s += ".0"
+		}
+		return s, nil
+	case string:
+		var b bytes.Buffer
+		writeJSONString(&b, p)
+		return b.String(), nil
+	case []byte:
+		return "!byte_array " + yamlArray(p, func(e byte) string {
+			return strconv.Itoa(int(int8(e))) // #nosec G115 -- tagByteArray elements are signed
+		}), nil
+	case []int32:
+		return "!int_array " + yamlArray(p, func(e int32) string { return strconv.Itoa(int(e)) }), nil
+	case []int64:
+		return "!long_array " + yamlArray(p, func(e int64) string { return strconv.FormatInt(e, 10) }), nil
+	default:
+		return "", fmt.Errorf("payload type %T has no YAML form", payload)
+	}
+}
+
+// yamlFloatString returns a float of the bit size as YAML, with NaN and infinities as .nan, .inf and -.inf.
+func yamlFloatString(f float64, bitSize int) string {
+	switch {
+	case math.IsNaN(f):
+		return ".nan"
+	case math.IsInf(f, 1):
+		return ".inf"
+	case math.IsInf(f, -1):
+		return "-.inf"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, bitSize)
+	}
+}
+
+// yamlArray returns the elements of an array as a YAML flow sequence, formatting each element.
+func yamlArray[E byte | int32 | int64](elements []E, format func(E) string) string {
+	formatted := make([]string, len(elements))
+	for i, element := range elements {
+		formatted[i] = format(element)
+	}
+	return "[" + strings.Join(formatted, ", ") + "]"
+}
+
+// yamlLine is a line of YAML holding content, with its indent and line number.
+type yamlLine struct {
+	indent int
+	text   string
+	number int
+}
+
+// yamlParser parses block YAML from its content lines, at line i.
+type yamlParser struct {
+	lines []yamlLine
+	i     int
+}
+
+// FromYAML returns the unnamed tag held by YAML in the form written by ToYAML, allowing the hand edits described
+// above.
+func FromYAML(data []byte) (t Tag, err error) {
+	p := &yamlParser{}
+	if err = p.split(string(data)); err != nil {
+		return Tag{}, fmt.Errorf("Unable to parse YAML: %w", err)
+	}
+	if len(p.lines) == 0 {
+		return Tag{}, fmt.Errorf("Unable to parse YAML: no content")
+	}
+
+	t, err = p.block(0, "")
+	if err == nil && p.i < len(p.lines) {
+		err = p.errorf("unexpected indent")
+	}
+	if err != nil {
+		return Tag{}, fmt.Errorf("Unable to parse YAML: %w", err)
+	}
+	return t, nil
+}
+
+// errorf returns an error at the current line.
+func (p *yamlParser) errorf(format string, a ...any) error {
+	number := 0
+	if p.i < len(p.lines) {
+		number = p.lines[p.i].number
+	} else if len(p.lines) > 0 {
+		number = p.lines[len(p.lines)-1].number
+	}
+	return fmt.Errorf("line %v: %v", number, fmt.Sprintf(format, a...))
+}
+
+// split records the lines of the YAML holding content, without comments, blank lines, directives and document markers.
+func (p *yamlParser) split(data string) error {
+	for number, line := range strings.Split(data, "\n") {
+		line = strings.TrimRight(stripYAMLComment(strings.TrimSuffix(line, "\r")), " \t")
+		text := strings.TrimLeft(line, " ")
+		if text == "" || text == "---" || text == "..." || strings.HasPrefix(line, "%") {
+			continue
+		}
+		if strings.HasPrefix(text, "\t") {
+			return fmt.Errorf("line %v: tabs cannot indent YAML", number+1)
+		}
+		p.lines = append(p.lines, yamlLine{indent: len(line) - len(text), text: text, number: number + 1})
+	}
+	return nil
+}
+
+// stripYAMLComment returns the line without any comment, a # at its start or after a space, outside quotes.
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// block parses the block mapping or sequence starting at the current line, whose indent must be at least minIndent,
+// applying the YAML tag given before it.
+func (p *yamlParser) block(minIndent int, tag string) (t Tag, err error) {
+	line := p.lines[p.i]
+	if line.indent < minIndent {
+		return Tag{}, p.errorf("missing value")
+	}
+
+	if isYAMLDash(line.text) {
+		return p.sequence(line.indent, tag)
+	}
+	if _, _, ok, _ := splitYAMLKey(line.text); ok {
+		if tag != "" && tag != "!compound" {
+			return Tag{}, p.errorf("tag %v cannot apply to a mapping", tag)
+		}
+		return p.mapping(line.indent)
+	}
+	if p.i != 0 || len(p.lines) != 1 {
+		return Tag{}, p.errorf("expected a mapping or sequence")
+	}
+	p.i++
+	return parseYAMLInline(line.text)
+}
+
+// mapping parses a block mapping at the indent into a tagCompound.
+func (p *yamlParser) mapping(indent int) (t Tag, err error) {
+	var children []Tag
+	for p.i < len(p.lines) && p.lines[p.i].indent == indent && !isYAMLDash(p.lines[p.i].text) {
+		key, rest, ok, err := splitYAMLKey(p.lines[p.i].text)
+		if err != nil || !ok {
+			return Tag{}, p.errorf("expected \"key: value\"")
+		}
+
+		child, err := p.value(indent, rest)
+		if err != nil {
+			return Tag{}, fmt.Errorf("%w, in \"%v\"", err, key)
+		}
+		child.name = key
+		children = append(children, child)
+	}
+	return Tag{id: tagCompound, payload: children}, nil
+}
+
+// sequence parses a block sequence at the indent into a tagList, whose element type is given by a !list:<type> tag or
+// inferred from the elements.
+func (p *yamlParser) sequence(indent int, tag string) (t Tag, err error) {
+	var elements []Tag
+	for p.i < len(p.lines) && p.lines[p.i].indent == indent && isYAMLDash(p.lines[p.i].text) {
+		rest := strings.TrimLeft(p.lines[p.i].text[1:], " ")
+		var element Tag
+		if _, _, ok, _ := splitYAMLKey(rest); ok || isYAMLDash(rest) {
+			// A compact mapping or sequence, whose first entry follows the dash.
+			p.lines[p.i].indent += len(p.lines[p.i].text) - len(rest)
+			p.lines[p.i].text = rest
+			element, err = p.block(p.lines[p.i].indent, "")
+		} else {
+			element, err = p.value(indent, rest)
+		}
+		if err != nil {
+			return Tag{}, fmt.Errorf("%w, in element %v", err, len(elements))
+		}
+		elements = append(elements, element)
+	}
+	return yamlList(tag, elements)
+}
+
+// value parses the value following a key or dash at the indent: the rest of the line, or a block on the following
+// lines if the rest is empty or only a tag. The current line is the key or dash.
+func (p *yamlParser) value(indent int, rest string) (t Tag, err error) {
+	tag := ""
+	if strings.HasPrefix(rest, "!") && !strings.Contains(rest, " ") {
+		tag, rest = rest, ""
+	}
+	if rest != "" {
+		t, err = parseYAMLInline(rest)
+		if err != nil {
+			return Tag{}, p.errorf("%v", err)
+		}
+		p.i++
+		return t, nil
+	}
+
+	p.i++
+	if p.i == len(p.lines) {
+		return Tag{}, p.errorf("missing value")
+	}
+	// A sequence may be at the indent of its key.
+	next := p.lines[p.i]
+	if next.indent == indent && isYAMLDash(next.text) && !isYAMLDash(p.lines[p.i-1].text) {
+		return p.sequence(indent, tag)
+	}
+	return p.block(indent+1, tag)
+}
+
+// isYAMLDash reports whether a line is a block sequence entry.
+func isYAMLDash(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitYAMLKey splits a "key: value" mapping entry into its key and the trimmed value. The boolean is false if the text
+// is not a mapping entry.
+func splitYAMLKey(text string) (key, rest string, ok bool, err error) {
+	end := 0
+	if text != "" && (text[0] == '"' || text[0] == '\'') {
+		end = closingYAMLQuote(text)
+		if end < 0 {
+			return "", "", false, fmt.Errorf("unterminated quoted key")
+		}
+		end++
+	} else {
+		end = strings.Index(text, ": ")
+		if end < 0 && strings.HasSuffix(text, ":") {
+			end = len(text) - 1
+		}
+		if end <= 0 || strings.ContainsAny(text[:1], "[{!-") {
+			return "", "", false, nil
+		}
+	}
+	if !strings.HasPrefix(text[end:], ": ") && text[end:] != ":" {
+		return "", "", false, nil
+	}
+
+	key, err = yamlUnquote(text[:end])
+	if err != nil {
+		return "", "", false, err
+	}
+	return key, strings.TrimSpace(text[end+1:]), true, nil
+}
+
+// closingYAMLQuote returns the index of the quote closing the quoted scalar starting the text, or -1 if there is none.
+func closingYAMLQuote(text string) int {
+	quote := text[0]
+	for i := 1; i < len(text); i++ {
+		switch {
+		case quote == '"' && text[i] == '\\':
+			i++
+		case quote == '\'' && text[i] == '\'' && i+1 < len(text) && text[i+1] == '\'':
+			i++
+		case text[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// yamlUnquote returns the value of a plain, single-quoted or double-quoted scalar.
+func yamlUnquote(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		return strconv.Unquote(s)
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return "", fmt.Errorf("unterminated string %v", s)
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	default:
+		return s, nil
+	}
+}
+
+// parseYAMLInline parses a value on one line: a scalar or flow sequence, optionally tagged, or an empty flow mapping.
+func parseYAMLInline(text string) (t Tag, err error) {
+	tag := ""
+	if strings.HasPrefix(text, "!") {
+		tag, text, _ = strings.Cut(text, " ")
+		text = strings.TrimSpace(text)
+	}
+
+	switch {
+	case text == "{}":
+		if tag != "" && tag != "!compound" {
+			return Tag{}, fmt.Errorf("tag %v cannot apply to a mapping", tag)
+		}
+		return Tag{id: tagCompound, payload: []Tag{}}, nil
+	case strings.HasPrefix(text, "{"):
+		return Tag{}, fmt.Errorf("flow mappings are not supported, write %v as a block mapping", text)
+	case strings.HasPrefix(text, "["):
+		return parseYAMLFlowSequence(tag, text)
+	default:
+		return parseYAMLScalar(tag, text)
+	}
+}
+
+// parseYAMLFlowSequence parses a flow sequence of scalars into an array tag of the tag's type, or a tagList.
+func parseYAMLFlowSequence(tag, text string) (t Tag, err error) {
+	if !strings.HasSuffix(text, "]") {
+		return Tag{}, fmt.Errorf("unterminated flow sequence %v", text)
+	}
+	inner := strings.TrimSpace(text[1 : len(text)-1])
+
+	var elements []Tag
+	for inner != "" {
+		end := len(inner)
+		if inner[0] == '"' || inner[0] == '\'' {
+			if end = closingYAMLQuote(inner) + 1; end == 0 {
+				return Tag{}, fmt.Errorf("unterminated string %v", inner)
+			}
+			end += strings.IndexByte(inner[end:]+",", ',')
+		} else if comma := strings.IndexByte(inner, ','); comma >= 0 {
+			end = comma
+		}
+
+		element, err := parseYAMLInline(strings.TrimSpace(inner[:end]))
+		if err != nil {
+			return Tag{}, fmt.Errorf("element %v: %w", len(elements), err)
+		}
+		elements = append(elements, element)
+		inner = strings.TrimSpace(strings.TrimPrefix(inner[end:], ","))
+	}
+
+	switch tag {
+	case "!byte_array":
+		return yamlArrayTag(tagByteArray, elements, math.MinInt8, math.MaxInt8, func(e int64) byte {
+			return byte(e) // #nosec G115 -- checked by yamlArrayTag
+		})
+	case "!int_array":
+		return yamlArrayTag(tagIntArray, elements, math.MinInt32, math.MaxInt32, func(e int64) int32 {
+			return int32(e) // #nosec G115 -- checked by yamlArrayTag
+		})
+	case "!long_array":
+		return yamlArrayTag(tagLongArray, elements, math.MinInt64, math.MaxInt64, func(e int64) int64 { return e })
+	default:
+		return yamlList(tag, elements)
+	}
+}
+
+// yamlArrayTag returns an array tag of the ID from elements that must be whole numbers between low and high.
+func yamlArrayTag[E byte | int32 | int64](id uint8, elements []Tag, low, high int64, convert func(int64) E) (Tag,
+	error) {
+	payload := make([]E, len(elements))
+	for i, element := range elements {
+		var v int64
+		switch p := element.payload.(type) {
+		case int32:
+			v = int64(p)
+		case int64:
+			v = p
+		default:
+			return Tag{}, fmt.Errorf("element %v is not a whole number", i)
+		}
+		if v < low || v > high {
+			return Tag{}, fmt.Errorf("element %v is not between %v and %v", i, low, high)
+		}
+		payload[i] = convert(v)
+	}
+	return Tag{id: id, payload: payload}, nil
+}
+
+// yamlList returns a tagList of the elements, of the element type of a !list:<type> tag or else inferred.
+func yamlList(tag string, elements []Tag) (t Tag, err error) {
+	if tag == "" || tag == "!list" {
+		return inferList(elements)
+	}
+
+	typeName, ok := strings.CutPrefix(tag, "!list:")
+	elementID := uint8(len(yamlTypeNames))
+	for id, name := range yamlTypeNames {
+		if name == typeName && id != int(tagEnd) {
+			elementID = uint8(id) // #nosec G115 -- bounded by yamlTypeNames
+		}
+	}
+	if !ok || int(elementID) == len(yamlTypeNames) {
+		return Tag{}, fmt.Errorf("tag %v cannot apply to a sequence", tag)
+	}
+
+	payload := make([]any, len(elements))
+	for i, element := range elements {
+		if element.id != elementID {
+			return Tag{}, fmt.Errorf("element %v has tag ID %v, not the listed %v", i, element.id, elementID)
+		}
+		payload[i] = element.payload
+	}
+	return Tag{id: tagList, elementID: elementID, payload: payload}, nil
+}
+
+// parseYAMLScalar parses a scalar, of the type of its tag or else inferred.
+func parseYAMLScalar(tag, text string) (t Tag, err error) {
+	quoted := strings.HasPrefix(text, `"`) || strings.HasPrefix(text, "'")
+	if quoted {
+		if end := closingYAMLQuote(text); end != len(text)-1 {
+			return Tag{}, fmt.Errorf("malformed string %v", text)
+		}
+	}
+	s, err := yamlUnquote(text)
+	if err != nil {
+		return Tag{}, err
+	}
+
+	switch tag {
+	case "":
+		if quoted {
+			return Tag{id: tagString, payload: s}, nil
+		}
+		return inferYAMLScalar(s)
+	case "!string", "!str":
+		return Tag{id: tagString, payload: s}, nil
+	case "!byte":
+		i, err := parseYAMLInteger(s, 8)
+		return Tag{id: tagByte, payload: byte(i)}, err // #nosec G115 -- checked by parseYAMLInteger
+	case "!short":
+		i, err := parseYAMLInteger(s, 16)
+		return Tag{id: tagShort, payload: int16(i)}, err // #nosec G115 -- checked by parseYAMLInteger
+	case "!int":
+		i, err := parseYAMLInteger(s, 32)
+		return Tag{id: tagInt, payload: int32(i)}, err // #nosec G115 -- checked by parseYAMLInteger
+	case "!long":
+		i, err := parseYAMLInteger(s, 64)
+		return Tag{id: tagLong, payload: i}, err
+	case "!float":
+		f, err := parseYAMLFloat(s, 32)
+		return Tag{id: tagFloat, payload: float32(f)}, err
+	case "!double":
+		f, err := parseYAMLFloat(s, 64)
+		return Tag{id: tagDouble, payload: f}, err
+	default:
+		return Tag{}, fmt.Errorf("unknown tag %v", tag)
+	}
+}
+
+// inferYAMLScalar returns the tag of an untagged plain scalar.
+func inferYAMLScalar(s string) (t Tag, err error) {
+	switch {
+	case yamlInteger.MatchString(s):
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return Tag{}, fmt.Errorf("%v overflows a tagLong", s)
+		}
+		if i >= math.MinInt32 && i <= math.MaxInt32 {
+			return Tag{id: tagInt, payload: int32(i)}, nil
+		}
+		return Tag{id: tagLong, payload: i}, nil
+	case yamlFloat.MatchString(s):
+		f, err := parseYAMLFloat(s, 64)
+		return Tag{id: tagDouble, payload: f}, err
+	case s == "true":
+		return Tag{id: tagByte, payload: byte(1)}, nil
+	case s == "false":
+		return Tag{id: tagByte, payload: byte(0)}, nil
+	case s == "null" || s == "~":
+		return Tag{}, fmt.Errorf("null has no NBT form")
+	default:
+		return Tag{id: tagString, payload: s}, nil
+	}
+}
+
+// parseYAMLInteger parses a whole number of the bit size.
+func parseYAMLInteger(s string, bitSize int) (i int64, err error) {
+	i, err = strconv.ParseInt(s, 10, bitSize)
+	if err != nil {
+		return 0, fmt.Errorf("%v is not a whole number of %v bits", s, bitSize)
+	}
+	return i, nil
+}
+
+// parseYAMLFloat parses a float of the bit size, with NaN and infinities as .nan, .inf and -.inf.
+func parseYAMLFloat(s string, bitSize int) (f float64, err error) {
+	switch strings.ToLower(strings.TrimPrefix(s, "+")) {
+	case ".nan":
+		return math.NaN(), nil
+	case ".inf":
+		return math.Inf(1), nil
+	case "-.inf":
+		return math.Inf(-1), nil
+	}
+	f, err = strconv.ParseFloat(s, bitSize)
+	if err != nil {
+		return 0, fmt.Errorf("%v is not a number of %v bits", s, bitSize)
+	}
+	return f, nil
+}
@@ -0,0 +1,153 @@
+package nbt
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestToYAML(t *testing.T) {
+	brands, _ := NewList("ServerBrands", IDString, NewString("", "vanilla"))
+	empty, _ := NewList("Empty", IDString)
+	inner, _ := NewList("", IDInt, NewInt("", 1))
+	nested, _ := NewList("Nested", IDList, inner)
+	item, _ := NewCompound("", NewString("id", "minecraft:stone"), NewByte("Count", 1))
+	items, _ := NewList("Items", IDCompound, item)
+	data, _ := NewCompound("Data", NewString("LevelName", "World"), NewInt("version", 19133),
+		NewByte("hardcore", 0xFF), NewShort("s", -2), NewLong("Seed", 1234), NewFloat("SpawnAngle", 0.5),
+		NewDouble("BorderCenterX", 0), NewDouble("inf", math.Inf(1)), NewIntArray("Pos", []int32{1, 2, 3}),
+		NewByteArray("ba", []byte{0x80}), NewLongArray("la", nil), brands, empty, nested, items,
+		NewString("yes", "a: \"b\" # c"), NewString("minecraft:key", ""))
+	root, _ := NewCompound("", data, Tag{id: tagCompound, name: "Empty"})
+
+	want := `Data:
+  LevelName: "World"
+  version: 19133
+  hardcore: !byte -1
+  s: !short -2
+  Seed: !long 1234
+  SpawnAngle: !float 0.5
+  BorderCenterX: 0.0
+  inf: .inf
+  Pos: !int_array [1, 2, 3]
+  ba: !byte_array [-128]
+  la: !long_array []
+  ServerBrands:
+    - "vanilla"
+  Empty: !list:string []
+  Nested:
+    -
+      - 1
+  Items:
+    -
+      id: "minecraft:stone"
+      Count: !byte 1
+  "yes": "a: \"b\" # c"
+  "minecraft:key": ""
+Empty: {}
+`
+
+	t.Run("Test success case: block YAML", func(t *testing.T) {
+		got, gotErr := ToYAML(root)
+		if string(got) != want || gotErr != nil {
+			t.Errorf("got %s %v, want %v", got, gotErr, want)
+		}
+	})
+
+	t.Run("Test success case: round trip is identical", func(t *testing.T) {
+		got, err := FromYAML([]byte(want))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var wantBytes, gotBytes bytes.Buffer
+		if err = WriteTag(&wantBytes, root); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err = WriteTag(&gotBytes, got); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !bytes.Equal(gotBytes.Bytes(), wantBytes.Bytes()) {
+			t.Errorf("got %v, want %v", got, root)
+		}
+	})
+
+	t.Run("Test success case: scalar root", func(t *testing.T) {
+		got, gotErr := ToYAML(NewFloat("f", float32(math.NaN())))
+		if string(got) != "!float .nan\n" || gotErr != nil {
+			t.Errorf("got %s %v", got, gotErr)
+		}
+	})
+
+	t.Run("Test failure case: unknown tag", func(t *testing.T) {
+		if _, err := ToYAML(Tag{id: 20, payload: RawPayload{1}}); err == nil {
+			t.Errorf("got nil, want non-nil")
+		}
+	})
+}
+
+func TestFromYAML(t *testing.T) {
+	successCases := []struct {
+		name string
+		want string
+		data string
+	}{
+		{"hand edits", `{Name:"Steve",Health:20d,Score:5000000000L,Flying:1b,Note:"it's",Motto:"say hi"}`, `---
+# A player
+Name: Steve # the name
+Health: 20.0
+Score: 5000000000
+Flying: true
+Note: 'it''s'
+Motto: "say hi"
+`},
+		{"sequence at the key's indent", "{Pos:[1d,2d],Tags:[]}", "Pos:\n- 1\n- 2.0\nTags: []\n"},
+		{"compact entries", `{Items:[{id:"a",Count:1b},{id:"b"}],Grid:[[1,2],[3]]}`, `Items:
+  - id: a
+    Count: !byte 1
+  - id: "b"
+Grid:
+- - 1
+  - 2
+- - 3
+`},
+		{"flow sequences", `{a:[1,2],b:["x, y","z"],c:[B;1b,-1b],d:[L;1L],e:[]}`,
+			"a: [1, 2]\nb: [\"x, y\", z]\nc: !byte_array [1, -1]\nd: !long_array [1]\ne: !list:int []\n"},
+		{"tagged scalars", `{a:1s,b:2,c:"3",d:0.5d,e:1.5f}`, "a: !short 1\nb: !int 2\nc: !string 3\nd: !double 0.5\n" +
+			"e: !float 1.5\n"},
+		{"tagged block list", "{l:[1b,2b]}", "l: !list:byte\n  - !byte 1\n  - !byte 2\n"},
+		{"scalar root", "7", "7\n"},
+		{"sequence root", `["a","b"]`, "- a\n- b\n"},
+	}
+	for _, successCase := range successCases {
+		t.Run("Test success case: "+successCase.name, func(t *testing.T) {
+			got, gotErr := FromYAML([]byte(successCase.data))
+			if got.String() != successCase.want || gotErr != nil {
+				t.Errorf("got %v %v, want %v", got, gotErr, successCase.want)
+			}
+		})
+	}
+
+	failureCases := []struct {
+		name string
+		data string
+	}{
+		{"empty", "# nothing\n"},
+		{"missing value", "a:\n"},
+		{"null", "a: null\n"},
+		{"bad indent", "a: 1\n  b: 2\n"},
+		{"mixed list", "- 1\n- a\n"},
+		{"byte out of range", "a: !byte 128\n"},
+		{"unknown tag", "a: !number 1\n"},
+		{"listed type mismatch", "a: !list:int [a]\n"},
+		{"flow mapping", "a: {b: 1}\n"},
+		{"unterminated string", "a: \"b\n"},
+		{"tab indent", "a:\n\t- 1\n"},
+	}
+	for _, failureCase := range failureCases {
+		t.Run("Test failure case: "+failureCase.name, func(t *testing.T) {
+			if got, gotErr := FromYAML([]byte(failureCase.data)); gotErr == nil {
+				t.Errorf("got %v nil, want non-nil", got)
+			}
+		})
+	}
+}